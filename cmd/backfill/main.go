@@ -0,0 +1,256 @@
+// backfill は過去の期間を日付ごとのタスクとして再実行する（例: 4月の日次レポートを作り直す）
+//
+//	go run ./cmd/backfill -type report -from 2026-04-01 -to 2026-04-30 -concurrency 4
+//	go run ./cmd/backfill -config config.json -template daily-report -from 2026-04-01 -to 2026-04-30
+//	go run ./cmd/backfill -target http://localhost:8080 -token secret -type report -from 2026-01-01 -to 2026-06-30 -step month -param format=pdf
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+)
+
+func main() {
+	target := flag.String("target", "local", "投入先（local またはプールのWebサーバーのURL）")
+	token := flag.String("token", "", "リモートの管理APIトークン")
+	workers := flag.Int("workers", 0, "ローカルプールのワーカー数（0 の場合はコンテナのCPUの上限から決める）")
+	taskType := flag.String("type", "", "タスクのタイプ（-template を指定しない場合の既定は report）")
+	configPath := flag.String("config", "", "テンプレートを定義した設定ファイル")
+	template := flag.String("template", "", "タスクを作成するテンプレートの名前（-config と併用）")
+	from := flag.String("from", "", "開始日（YYYY-MM-DD、この日を含む）")
+	to := flag.String("to", "", "終了日（YYYY-MM-DD、この日を含む）")
+	step := flag.String("step", string(workerpool.BackfillDaily), "日付の単位（day, week, month）")
+	businessDays := flag.Bool("business-days", false, "営業日（土日・祝日以外）のみ対象にする")
+	holidays := flag.String("holidays", "", "祝日のCSVファイル（-business-days と併用）")
+	firstID := flag.Int("first-id", 1, "最初のタスクID（以降は連番）")
+	name := flag.String("name", "", "タスク名（{date} を日付に置き換える）")
+	params := flag.String("param", "", "ペイロードに追加する値（key=value をカンマ区切り）")
+	labels := flag.String("labels", "", "タスクのラベル（key=value をカンマ区切り）")
+	priority := flag.Int("priority", 0, "優先度（-1: 低, 1: 高。0 の場合はテンプレートの既定値）")
+	timeout := flag.Duration("timeout", 0, "1件あたりのタイムアウト（0 の場合はテンプレート・プールの設定）")
+	maxRetries := flag.Int("max-retries", 0, "最大リトライ回数（0 の場合はテンプレート・タイプのポリシー）")
+	concurrency := flag.Int("concurrency", 4, "同時に実行する日付の数")
+	maxFailures := flag.Int("max-failures", 0, "この件数を失敗したら残りを中止する（0 で中止しない）")
+	interval := flag.Duration("progress", 2*time.Second, "進捗を表示する間隔")
+	dryRun := flag.Bool("dry-run", false, "実行せずに生成するタスクを表示する")
+	flag.Parse()
+
+	config := workerpool.BackfillConfig{
+		Type:        workerpool.TaskType(*taskType),
+		Step:        workerpool.BackfillStep(*step),
+		FirstID:     *firstID,
+		Name:        *name,
+		Labels:      parseLabels(*labels),
+		Priority:    workerpool.Priority(*priority),
+		Timeout:     *timeout,
+		MaxRetries:  *maxRetries,
+		Concurrency: *concurrency,
+		MaxFailures: *maxFailures,
+	}
+	var err error
+	if *template != "" {
+		if config.Template, err = loadTemplate(*configPath, *template); err != nil {
+			exitf(2, "%v", err)
+		}
+	} else if config.Type == "" {
+		config.Type = workerpool.TaskTypeReport
+	}
+	if config.From, err = time.ParseInLocation("2006-01-02", *from, time.Local); err != nil {
+		exitf(2, "-from を YYYY-MM-DD 形式で指定してください: %v", err)
+	}
+	if config.To, err = time.ParseInLocation("2006-01-02", *to, time.Local); err != nil {
+		exitf(2, "-to を YYYY-MM-DD 形式で指定してください: %v", err)
+	}
+	if *params != "" {
+		config.Params = make(map[string]interface{})
+		for key, value := range parseLabels(*params) {
+			config.Params[key] = value
+		}
+	}
+	if *businessDays {
+		var days map[string]string
+		if *holidays != "" {
+			if days, err = workerpool.LoadHolidaysCSV(*holidays); err != nil {
+				exitf(2, "%v", err)
+			}
+		}
+		config.Calendar = workerpool.NewJapaneseCalendar(days)
+	}
+
+	var executor workerpool.TaskExecutor
+	if *target == "local" {
+		if *workers <= 0 {
+			*workers = workerpool.DefaultWorkers()
+		}
+		pool := workerpool.NewWorkerPool(*workers)
+		pool.RegisterProcessor(workerpool.TaskTypeEmail, workerpool.EmailProcessor)
+		pool.RegisterProcessor(workerpool.TaskTypeImage, workerpool.ImageProcessor)
+		pool.RegisterProcessor(workerpool.TaskTypeDatabase, workerpool.DatabaseProcessor)
+		pool.RegisterProcessor(workerpool.TaskTypeReport, workerpool.ReportProcessor)
+		executor = pool
+		if !*dryRun {
+			pool.Start()
+			defer pool.Stop()
+		}
+	} else {
+		executor = newRemoteExecutor(*target, *token)
+	}
+
+	backfill, err := workerpool.NewBackfill(executor, config)
+	if err != nil {
+		exitf(2, "%v", err)
+	}
+	if *dryRun {
+		for _, task := range backfill.Tasks() {
+			fmt.Printf("📝 タスク %d %s (%s) payload=%v\n", task.ID, task.Name, task.Type, task.Payload)
+		}
+		fmt.Printf("🔍 %d 件のタスクを生成します（-dry-run のため実行しません）\n", len(backfill.Tasks()))
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	done := make(chan struct{})
+	go reportProgress(backfill, *interval, done)
+	progress, err := backfill.Run(ctx)
+	close(done)
+
+	for _, failure := range progress.Failures {
+		fmt.Printf("  ❌ %s (タスク %d): %s\n", failure.Date, failure.TaskID, failure.Error)
+	}
+	fmt.Printf("📊 成功: %d, 失敗: %d, 未実行: %d / %d 件 (所要時間: %v)\n",
+		progress.Succeeded, progress.Failed, progress.Skipped, progress.Total, progress.Elapsed.Round(time.Millisecond))
+	if err != nil || progress.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadTemplate は設定ファイルからテンプレートを読み込む
+func loadTemplate(path, name string) (*workerpool.TaskTemplate, error) {
+	if path == "" {
+		return nil, fmt.Errorf("-template を指定する場合は -config を指定してください")
+	}
+	config, err := workerpool.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := workerpool.NewTemplateRegistry(config.Templates...)
+	if err != nil {
+		return nil, err
+	}
+	template, err := templates.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// reportProgress は done が閉じられるまで一定間隔で進捗を表示する
+func reportProgress(backfill *workerpool.Backfill, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p := backfill.Progress()
+			fmt.Printf("⏳ %d/%d (%.0f%%) 成功: %d, 失敗: %d, 実行中: %d, 経過: %v, 残り: 約%v\n",
+				p.Done(), p.Total, p.Percent(), p.Succeeded, p.Failed, p.Running,
+				p.Elapsed.Round(time.Second), p.ETA.Round(time.Second))
+		case <-done:
+			return
+		}
+	}
+}
+
+// remoteExecutor はプールのWebサーバーの POST /tasks?wait=true に投入する
+type remoteExecutor struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newRemoteExecutor(url, token string) *remoteExecutor {
+	return &remoteExecutor{
+		url:    strings.TrimRight(url, "/") + "/tasks?wait=true",
+		token:  token,
+		client: &http.Client{},
+	}
+}
+
+// Execute はタスクを投入して最終結果を待つ（受付拒否や通信エラーは err）
+func (e *remoteExecutor) Execute(ctx context.Context, task workerpool.Task) (workerpool.TaskResult, error) {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return workerpool.TaskResult{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return workerpool.TaskResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return workerpool.TaskResult{}, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success      bool   `json:"success"`
+		Error        string `json:"error"`
+		AttemptCount int    `json:"attempt_count"`
+		WorkerID     int    `json:"worker_id"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK {
+		return workerpool.TaskResult{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode, result.Error)
+	}
+
+	taskResult := workerpool.TaskResult{
+		TaskID:       task.ID,
+		TaskName:     task.Name,
+		TaskType:     task.Type,
+		Success:      result.Success,
+		AttemptCount: result.AttemptCount,
+		WorkerID:     result.WorkerID,
+	}
+	if !result.Success {
+		taskResult.Error = errors.New(result.Error)
+	}
+	return taskResult, nil
+}
+
+// parseLabels は key=value,key=value 形式の値を読み込む
+func parseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && key != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// exitf はエラーを表示して終了する
+func exitf(code int, format string, args ...interface{}) {
+	fmt.Printf("❌ "+format+"\n", args...)
+	os.Exit(code)
+}
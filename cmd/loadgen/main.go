@@ -0,0 +1,220 @@
+// loadgen は合成ワークロードをプールに投入し、レイテンシとスループットを報告する負荷生成ツール
+//
+//	go run ./cmd/loadgen -duration 30s -rate 50 -arrival poisson -mix email=5,image=2,report=1
+//	go run ./cmd/loadgen -target http://localhost:8080 -token secret -rate 20
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+)
+
+// config は負荷生成の設定
+type config struct {
+	target      string
+	token       string
+	workers     int
+	duration    time.Duration
+	rate        float64
+	arrival     string
+	burstSize   int
+	mix         []typeWeight
+	payloadMin  int
+	payloadMax  int
+	concurrency int
+	work        time.Duration
+	failRate    float64
+}
+
+// typeWeight はタスクタイプとその投入比率
+type typeWeight struct {
+	taskType workerpool.TaskType
+	weight   int
+}
+
+func main() {
+	var cfg config
+	var mix string
+	flag.StringVar(&cfg.target, "target", "local", "投入先（local またはプールのWebサーバーのURL）")
+	flag.StringVar(&cfg.token, "token", "", "リモートの管理APIトークン")
+	flag.IntVar(&cfg.workers, "workers", 0, "ローカルプールのワーカー数（0 の場合はコンテナのCPUの上限から決める）")
+	flag.DurationVar(&cfg.duration, "duration", 10*time.Second, "負荷をかける時間")
+	flag.Float64Var(&cfg.rate, "rate", 20, "1秒あたりの平均投入数")
+	flag.StringVar(&cfg.arrival, "arrival", "constant", "到着分布（constant, poisson, burst）")
+	flag.IntVar(&cfg.burstSize, "burst", 10, "burst 分布で一度に投入するタスク数")
+	flag.StringVar(&mix, "mix", "email=1", "タスクタイプの比率（type=weight をカンマ区切り）")
+	flag.IntVar(&cfg.payloadMin, "payload-min", 0, "ペイロードの最小サイズ（バイト）")
+	flag.IntVar(&cfg.payloadMax, "payload-max", 256, "ペイロードの最大サイズ（バイト）")
+	flag.IntVar(&cfg.concurrency, "concurrency", 256, "結果待ちのタスク数の上限")
+	flag.DurationVar(&cfg.work, "work", 20*time.Millisecond, "ローカルプールの平均処理時間")
+	flag.Float64Var(&cfg.failRate, "fail-rate", 0, "ローカルプールの失敗率（0〜1）")
+	flag.Parse()
+
+	var err error
+	if cfg.mix, err = parseMix(mix); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(2)
+	}
+	if cfg.rate <= 0 || cfg.payloadMax < cfg.payloadMin || cfg.concurrency <= 0 {
+		fmt.Println("❌ -rate, -concurrency は正の値、-payload-max は -payload-min 以上で指定してください")
+		os.Exit(2)
+	}
+
+	var t target
+	if cfg.target == "local" {
+		t = newLocalTarget(cfg)
+	} else {
+		t = newRemoteTarget(cfg.target, cfg.token)
+	}
+	defer t.close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("🚦 負荷生成を開始します: 投入先=%s 時間=%v 平均%.1f件/秒 (%s)\n",
+		cfg.target, cfg.duration, cfg.rate, cfg.arrival)
+	report := run(ctx, cfg, t)
+	report.print()
+}
+
+// run は設定された到着分布でタスクを投入し、すべての結果を待って集計する
+func run(ctx context.Context, cfg config, t target) *report {
+	rep := newReport()
+	sem := make(chan struct{}, cfg.concurrency)
+	var wg sync.WaitGroup
+
+	loadCtx, cancel := context.WithTimeout(ctx, cfg.duration)
+	defer cancel()
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	nextID := 0
+	submit := func() {
+		nextID++
+		task := workerpool.Task{
+			ID:      nextID,
+			Name:    fmt.Sprintf("loadgen-%d", nextID),
+			Type:    pickType(rng, cfg.mix),
+			Payload: randomPayload(rng, cfg.payloadMin, cfg.payloadMax),
+		}
+		select {
+		case sem <- struct{}{}:
+		default:
+			// 結果待ちが上限に達している場合は投入を諦めて記録する（負荷生成側が詰まらないように）
+			rep.dropped(task.Type)
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			started := time.Now()
+			success, err := t.submit(ctx, task)
+			rep.record(task.Type, time.Since(started), success, err)
+		}()
+	}
+
+	rep.start()
+	for {
+		batch := 1
+		if cfg.arrival == "burst" {
+			batch = cfg.burstSize
+		}
+		for i := 0; i < batch; i++ {
+			submit()
+		}
+
+		timer := time.NewTimer(nextInterval(rng, cfg, batch))
+		select {
+		case <-timer.C:
+			continue
+		case <-loadCtx.Done():
+			timer.Stop()
+		}
+		break
+	}
+
+	fmt.Println("⏳ 投入を終了しました。結果を待っています...")
+	wg.Wait()
+	rep.stop()
+	return rep
+}
+
+// nextInterval は次の投入までの間隔を到着分布に従って決める
+func nextInterval(rng *rand.Rand, cfg config, batch int) time.Duration {
+	mean := float64(time.Second) / cfg.rate
+	switch cfg.arrival {
+	case "poisson":
+		return time.Duration(rng.ExpFloat64() * mean)
+	case "burst":
+		return time.Duration(mean * float64(batch))
+	default:
+		return time.Duration(mean)
+	}
+}
+
+// pickType は比率に従ってタスクタイプを選ぶ
+func pickType(rng *rand.Rand, mix []typeWeight) workerpool.TaskType {
+	total := 0
+	for _, w := range mix {
+		total += w.weight
+	}
+	n := rng.Intn(total)
+	for _, w := range mix {
+		if n < w.weight {
+			return w.taskType
+		}
+		n -= w.weight
+	}
+	return mix[len(mix)-1].taskType
+}
+
+// randomPayload は指定範囲のサイズのペイロードを作る
+func randomPayload(rng *rand.Rand, min, max int) map[string]string {
+	size := min
+	if max > min {
+		size += rng.Intn(max - min + 1)
+	}
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = letters[rng.Intn(len(letters))]
+	}
+	return map[string]string{"data": string(data)}
+}
+
+// parseMix は type=weight,type=weight 形式の比率を読み込む
+func parseMix(s string) ([]typeWeight, error) {
+	var mix []typeWeight
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, weight, ok := strings.Cut(pair, "=")
+		if !ok {
+			weight = "1"
+		}
+		n, err := strconv.Atoi(weight)
+		if err != nil || n <= 0 || name == "" {
+			return nil, fmt.Errorf("-mix の指定が不正です: %q", pair)
+		}
+		mix = append(mix, typeWeight{taskType: workerpool.TaskType(name), weight: n})
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("-mix にタスクタイプを指定してください")
+	}
+	return mix, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+)
+
+// report はレイテンシとスループットの集計
+type report struct {
+	mu      sync.Mutex
+	started time.Time
+	elapsed time.Duration
+	byType  map[workerpool.TaskType]*typeReport
+	errSeen map[string]int // 通信エラーなどの内訳
+}
+
+// typeReport はタスクタイプごとの集計
+type typeReport struct {
+	latencies []time.Duration // 結果が返ったタスクのレイテンシ
+	succeeded int
+	failed    int
+	rejected  int
+	dropped   int
+	errors    int
+}
+
+func newReport() *report {
+	return &report{
+		byType:  make(map[workerpool.TaskType]*typeReport),
+		errSeen: make(map[string]int),
+	}
+}
+
+func (r *report) start() { r.started = time.Now() }
+
+func (r *report) stop() { r.elapsed = time.Since(r.started) }
+
+func (r *report) typeLocked(taskType workerpool.TaskType) *typeReport {
+	tr, exists := r.byType[taskType]
+	if !exists {
+		tr = &typeReport{}
+		r.byType[taskType] = tr
+	}
+	return tr
+}
+
+// record は1件の結果を記録する
+func (r *report) record(taskType workerpool.TaskType, latency time.Duration, success bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tr := r.typeLocked(taskType)
+	switch {
+	case errors.Is(err, errRejected):
+		tr.rejected++
+	case err != nil:
+		tr.errors++
+		r.errSeen[err.Error()]++
+	default:
+		tr.latencies = append(tr.latencies, latency)
+		if success {
+			tr.succeeded++
+		} else {
+			tr.failed++
+		}
+	}
+}
+
+// dropped は結果待ちの上限で投入しなかったタスクを記録する
+func (r *report) dropped(taskType workerpool.TaskType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.typeLocked(taskType).dropped++
+}
+
+// print はタイプ別と全体の集計を表示する
+func (r *report) print() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	types := make([]workerpool.TaskType, 0, len(r.byType))
+	for taskType := range r.byType {
+		types = append(types, taskType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	total := &typeReport{}
+	fmt.Println("\n" + "==================================================")
+	fmt.Printf("📊 負荷試験レポート (経過時間: %v)\n", r.elapsed.Round(time.Millisecond))
+	fmt.Println("==================================================")
+	for _, taskType := range types {
+		tr := r.byType[taskType]
+		tr.print(string(taskType), r.elapsed)
+		total.latencies = append(total.latencies, tr.latencies...)
+		total.succeeded += tr.succeeded
+		total.failed += tr.failed
+		total.rejected += tr.rejected
+		total.dropped += tr.dropped
+		total.errors += tr.errors
+	}
+	total.print("全体", r.elapsed)
+
+	if len(r.errSeen) > 0 {
+		fmt.Println("\n⚠️ エラーの内訳:")
+		for message, count := range r.errSeen {
+			fmt.Printf("  %d件: %s\n", count, message)
+		}
+	}
+}
+
+func (tr *typeReport) print(name string, elapsed time.Duration) {
+	completed := tr.succeeded + tr.failed
+	throughput := 0.0
+	if elapsed > 0 {
+		throughput = float64(completed) / elapsed.Seconds()
+	}
+
+	fmt.Printf("\n📋 [%s] 完了:%d (成功:%d 失敗:%d) 拒否:%d 未投入:%d エラー:%d スループット:%.1f件/秒\n",
+		name, completed, tr.succeeded, tr.failed, tr.rejected, tr.dropped, tr.errors, throughput)
+	if len(tr.latencies) == 0 {
+		return
+	}
+	sort.Slice(tr.latencies, func(i, j int) bool { return tr.latencies[i] < tr.latencies[j] })
+	fmt.Printf("  ⏱️ レイテンシ p50:%v p90:%v p99:%v 最大:%v\n",
+		percentile(tr.latencies, 0.50), percentile(tr.latencies, 0.90),
+		percentile(tr.latencies, 0.99), tr.latencies[len(tr.latencies)-1].Round(time.Microsecond))
+}
+
+// percentile はソート済みのレイテンシから百分位数を返す
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(float64(len(sorted)-1) * p)
+	return sorted[i].Round(time.Microsecond)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+)
+
+// errRejected はプールが受付を拒否した場合のエラー
+var errRejected = errors.New("受付拒否")
+
+// target はタスクの投入先
+// submit は最終結果まで待ち、成功したかどうかを返す（受付拒否や通信エラーは err）
+type target interface {
+	submit(ctx context.Context, task workerpool.Task) (bool, error)
+	close()
+}
+
+// localTarget は同じプロセス内で作成したプールに投入する
+type localTarget struct {
+	pool *workerpool.WorkerPool
+}
+
+// newLocalTarget は合成プロセッサを登録したローカルプールを作成する
+func newLocalTarget(cfg config) *localTarget {
+	if cfg.workers <= 0 {
+		cfg.workers = workerpool.DefaultWorkers()
+	}
+	pool := workerpool.NewWorkerPool(cfg.workers)
+	for _, w := range cfg.mix {
+		pool.RegisterProcessor(w.taskType, syntheticProcessor(cfg.work, cfg.failRate))
+	}
+	pool.Start()
+	return &localTarget{pool: pool}
+}
+
+// syntheticProcessor は平均 work（±50%）だけ待ち、failRate の確率で失敗するプロセッサ
+func syntheticProcessor(work time.Duration, failRate float64) workerpool.TaskProcessor {
+	return func(ctx context.Context, task workerpool.Task) error {
+		d := time.Duration(float64(work) * (0.5 + rand.Float64()))
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if rand.Float64() < failRate {
+			return errors.New("合成エラー: 負荷試験用の失敗です")
+		}
+		return nil
+	}
+}
+
+func (t *localTarget) submit(ctx context.Context, task workerpool.Task) (bool, error) {
+	task.CreatedAt = time.Now()
+	result, err := t.pool.Execute(ctx, task)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", errRejected, err)
+	}
+	return result.Success, nil
+}
+
+func (t *localTarget) close() {
+	t.pool.Stop()
+}
+
+// remoteTarget はプールのWebサーバーの POST /tasks?wait=true に投入する
+type remoteTarget struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newRemoteTarget(url, token string) *remoteTarget {
+	return &remoteTarget{
+		url:    strings.TrimRight(url, "/") + "/tasks?wait=true",
+		token:  token,
+		client: &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: 256}},
+	}
+}
+
+func (t *remoteTarget) submit(ctx context.Context, task workerpool.Task) (bool, error) {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return result.Success, nil
+	case resp.StatusCode == http.StatusServiceUnavailable:
+		return false, fmt.Errorf("%w: %s", errRejected, result.Error)
+	default:
+		return false, fmt.Errorf("HTTP %d: %s", resp.StatusCode, result.Error)
+	}
+}
+
+func (t *remoteTarget) close() {
+	t.client.CloseIdleConnections()
+}
@@ -0,0 +1,91 @@
+// workeragent はコーディネーター（workerpool -coordinator-addr）に接続してタスクを実行するリモートワーカー
+//
+//	WORKERAGENT_TOKEN=secret go run ./cmd/workeragent -coordinator pool.internal:9090 -tls-ca ca.pem -id agent-1 -capacity 4 -labels region=tokyo,gpu=true
+//
+// 接続は TLS で行い（-tls-ca を省略した場合はシステムの CA で検証する）、-tls-cert・-tls-key でクライアント証明書を提示できる（mTLS）。
+// ローカルでの動作確認など、暗号化しない接続は -insecure を指定した場合に限る
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hizzuu/worker-example/pkg/remote"
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func main() {
+	hostname, _ := os.Hostname()
+	coordinator := flag.String("coordinator", "localhost:9090", "コーディネーターのアドレス")
+	id := flag.String("id", hostname, "エージェントのID")
+	capacity := flag.Int("capacity", 2, "同時に実行するタスク数")
+	labels := flag.String("labels", "", "エージェントのラベル（key=value をカンマ区切り）")
+	token := flag.String("token", os.Getenv("WORKERAGENT_TOKEN"), "コーディネーターの認証トークン（既定値は環境変数 WORKERAGENT_TOKEN）")
+	tlsCA := flag.String("tls-ca", "", "コーディネーターの証明書を検証する CA（PEM、省略した場合はシステムの CA）")
+	tlsCert := flag.String("tls-cert", "", "mTLS で提示するクライアント証明書（PEM）")
+	tlsKey := flag.String("tls-key", "", "クライアント証明書の秘密鍵（PEM）")
+	tlsServerName := flag.String("tls-server-name", "", "証明書で検証するコーディネーターのホスト名（省略した場合はアドレスのホスト名）")
+	plaintext := flag.Bool("insecure", false, "TLS を使わずに接続する（ローカルでの動作確認用）")
+	flag.Parse()
+
+	transport := insecure.NewCredentials()
+	if !*plaintext {
+		config, err := remote.ClientTLSConfig(*tlsCA, *tlsCert, *tlsKey, *tlsServerName)
+		if err != nil {
+			fmt.Printf("❌ TLS の設定が不正です: %v\n", err)
+			os.Exit(2)
+		}
+		transport = credentials.NewTLS(config)
+	} else if *token != "" {
+		fmt.Println("⚠️ 暗号化しない接続で認証トークンを送ります")
+	}
+
+	conn, err := grpc.NewClient(*coordinator, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		fmt.Printf("❌ コーディネーターに接続できません: %v\n", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	agent := remote.NewAgent(conn, remote.AgentConfig{
+		ID:       *id,
+		Labels:   parseLabels(*labels),
+		Capacity: *capacity,
+		Token:    *token,
+		Processors: map[workerpool.TaskType]workerpool.TaskProcessor{
+			workerpool.TaskTypeEmail:    workerpool.EmailProcessor,
+			workerpool.TaskTypeImage:    workerpool.ImageProcessor,
+			workerpool.TaskTypeDatabase: workerpool.DatabaseProcessor,
+			workerpool.TaskTypeReport:   workerpool.ReportProcessor,
+		},
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := agent.Run(ctx); err != nil {
+		fmt.Printf("❌ エージェントが停止しました: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("👋 エージェントを停止しました")
+}
+
+// parseLabels は key=value,key=value 形式のラベルを読み込む
+func parseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && key != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/hizzuu/worker-example/pkg/remote"
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// coordinatorOptions はリモートワーカー（cmd/workeragent）を受け付けるコーディネーターの設定
+//
+//	WORKERPOOL_AGENT_TOKEN=secret go run ./cmd/workerpool -coordinator-addr :9090 -remote-types report \
+//		-coordinator-tls-cert server.pem -coordinator-tls-key server-key.pem
+type coordinatorOptions struct {
+	addr      string // gRPC で待ち受けるアドレス（空の場合は起動しない）
+	taskTypes string // エージェントで実行するタスクタイプ（カンマ区切り）
+	token     string // エージェントの認証トークン
+	tlsCert   string
+	tlsKey    string
+	clientCA  string // 指定した場合はクライアント証明書を検証する（mTLS）
+	plaintext bool   // TLS を使わない（ローカルでの動作確認用）
+}
+
+// startCoordinator はコーディネーターの gRPC サーバーを起動し、指定したタイプのプロセッサをエージェントでの実行に置き換える
+// エージェントはタスクのペイロードを受け取り結果を報告できるため、トークンかクライアント証明書のどちらかを必須とする
+func startCoordinator(pool *workerpool.WorkerPool, opts coordinatorOptions) (stop func(), err error) {
+	if opts.token == "" && opts.clientCA == "" {
+		return nil, errors.New("コーディネーターには -agent-token か -coordinator-client-ca のどちらかを指定してください")
+	}
+
+	var serverOpts []grpc.ServerOption
+	switch {
+	case opts.tlsCert != "" || opts.tlsKey != "":
+		config, err := remote.ServerTLSConfig(opts.tlsCert, opts.tlsKey, opts.clientCA)
+		if err != nil {
+			return nil, err
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(config)))
+	case opts.clientCA != "":
+		return nil, errors.New("-coordinator-client-ca を使うには -coordinator-tls-cert と -coordinator-tls-key を指定してください")
+	case !opts.plaintext:
+		return nil, errors.New("コーディネーターには -coordinator-tls-cert と -coordinator-tls-key を指定してください（暗号化しない場合は -coordinator-insecure）")
+	}
+
+	var taskTypes []workerpool.TaskType
+	for _, taskType := range strings.Split(opts.taskTypes, ",") {
+		if taskType = strings.TrimSpace(taskType); taskType != "" {
+			taskTypes = append(taskTypes, workerpool.TaskType(taskType))
+		}
+	}
+	if len(taskTypes) == 0 {
+		return nil, errors.New("エージェントで実行するタスクタイプを -remote-types で指定してください")
+	}
+
+	listener, err := net.Listen("tcp", opts.addr)
+	if err != nil {
+		return nil, fmt.Errorf("コーディネーターのアドレス %s で待ち受けられません: %w", opts.addr, err)
+	}
+
+	coordinator := remote.NewCoordinator(remote.CoordinatorConfig{Token: opts.token})
+	for _, taskType := range taskTypes {
+		pool.RegisterProcessor(taskType, coordinator.Processor())
+	}
+	server := grpc.NewServer(serverOpts...)
+	coordinator.RegisterService(server)
+	go server.Serve(listener)
+
+	fmt.Printf("🛰️ コーディネーター: %s (エージェントで実行するタイプ: %v)\n", listener.Addr(), taskTypes)
+	if opts.plaintext {
+		fmt.Println("⚠️ コーディネーターは暗号化せずに待ち受けています（ローカルでの動作確認用）")
+	}
+	return func() {
+		server.GracefulStop()
+		coordinator.Stop()
+	}, nil
+}
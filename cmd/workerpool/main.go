@@ -1,15 +1,54 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/hizzuu/worker-example/pkg/workerpool"
 )
 
 func main() {
-	// 3つのワーカーを持つプールを作成
-	pool := workerpool.NewWorkerPool(3)
+	tui := flag.Bool("tui", false, "進捗をターミナルUI（プログレスバー）で表示する")
+	logFormat := flag.String("log-format", "text", "進行状況の出力形式（text / json）")
+	workers := flag.Int("workers", 0, "ワーカー数（0 の場合はコンテナのCPUの上限から決める）")
+	service := flag.String("service", "", "統計・イベント・結果の記録に付けるサービス名")
+	region := flag.String("region", "", "統計・イベント・結果の記録に付けるリージョン")
+	version := flag.String("version", "", "統計・イベント・結果の記録に付けるバージョン")
+	sandbox := flag.Bool("sandbox", false, "デモ・サンドボックスモード（プロセッサをシミュレーターに置き換え、管理APIを読み取り専用にする）")
+	var coordinatorOpts coordinatorOptions
+	flag.StringVar(&coordinatorOpts.addr, "coordinator-addr", "", "リモートワーカー（workeragent）を受け付ける gRPC のアドレス（例: :9090、空の場合は無効）")
+	flag.StringVar(&coordinatorOpts.taskTypes, "remote-types", "", "エージェントで実行するタスクタイプ（カンマ区切り）")
+	flag.StringVar(&coordinatorOpts.token, "agent-token", os.Getenv("WORKERPOOL_AGENT_TOKEN"), "エージェントの認証トークン（既定値は環境変数 WORKERPOOL_AGENT_TOKEN）")
+	flag.StringVar(&coordinatorOpts.tlsCert, "coordinator-tls-cert", "", "コーディネーターのサーバー証明書（PEM）")
+	flag.StringVar(&coordinatorOpts.tlsKey, "coordinator-tls-key", "", "サーバー証明書の秘密鍵（PEM）")
+	flag.StringVar(&coordinatorOpts.clientCA, "coordinator-client-ca", "", "エージェントのクライアント証明書を検証する CA（PEM、指定した場合は mTLS）")
+	flag.BoolVar(&coordinatorOpts.plaintext, "coordinator-insecure", false, "コーディネーターを TLS なしで待ち受ける（ローカルでの動作確認用）")
+	flag.Parse()
+
+	format, err := workerpool.ParseConsoleFormat(*logFormat)
+	if err != nil {
+		fmt.Println("❌", err)
+		os.Exit(2)
+	}
+	workerpool.SetConsoleFormat(format)
+
+	// 複数インスタンスの統計を集約する側で分けられるよう、インスタンス情報を付ける
+	if *service != "" || *region != "" || *version != "" {
+		workerpool.SetInstanceInfo(workerpool.InstanceInfo{Service: *service, Region: *region, Version: *version})
+	}
+
+	// コンテナのCPU・メモリの上限に合わせてワーカー数と負荷制御の閾値を決める
+	limits := workerpool.DetectContainerLimits()
+	if *workers <= 0 {
+		*workers = limits.Workers()
+	}
+	fmt.Printf("🧮 %s → ワーカー %d 個\n", limits, *workers)
+	pool := workerpool.NewWorkerPool(*workers)
+	if policy := limits.ShedPolicy(workerpool.ShedDefer); policy != nil {
+		pool.SetShedPolicy(policy)
+	}
 
 	// プロセッサを登録
 	pool.RegisterProcessor(workerpool.TaskTypeEmail, workerpool.EmailProcessor)
@@ -17,11 +56,36 @@ func main() {
 	pool.RegisterProcessor(workerpool.TaskTypeDatabase, workerpool.DatabaseProcessor)
 	pool.RegisterProcessor(workerpool.TaskTypeReport, workerpool.ReportProcessor)
 
+	// 指定したタイプはコーディネーター経由でリモートのエージェントに実行させる
+	if coordinatorOpts.addr != "" {
+		stopCoordinator, err := startCoordinator(pool, coordinatorOpts)
+		if err != nil {
+			fmt.Println("❌", err)
+			os.Exit(2)
+		}
+		defer stopCoordinator()
+	}
+
 	// タスクタイムアウトを設定
 	pool.SetTaskTimeout(10 * time.Second)
 
 	// 🆕 監視機能を追加
 	monitor := workerpool.NewMonitor(pool)
+
+	// GET /config で起動時の引数を確認できるようにする（既定値のままの引数も含む）
+	flags := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) { flags[f.Name] = f.Value.String() })
+	flags["workers"] = fmt.Sprint(*workers)
+	monitor.AddConfigSource("flags", flags)
+
+	// サンドボックスでは外部に副作用を出さず、管理APIからの変更も受け付けない
+	if *sandbox {
+		if err := workerpool.SimulateProcessors(pool, nil, workerpool.Simulation{FailureRate: 0.1}); err != nil {
+			fmt.Println("❌", err)
+			os.Exit(1)
+		}
+		monitor.SetReadOnly(true)
+	}
 	monitor.Start()
 	defer monitor.Stop()
 
@@ -38,7 +102,9 @@ func main() {
 	// タスクを段階的に投入
 	go func() {
 		for batch := 1; batch <= 5; batch++ {
-			fmt.Printf("\n📦 バッチ %d を投入中...\n", batch)
+			if !*tui {
+				fmt.Printf("\n📦 バッチ %d を投入中...\n", batch)
+			}
 
 			for i := 1; i <= 4; i++ {
 				taskID := (batch-1)*4 + i
@@ -63,33 +129,43 @@ func main() {
 		}
 	}()
 
-	// 🆕 定期的に統計情報を表示
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				monitor.PrintStats()
+	// 🆕 ターミナルUIでは進捗を同じ位置に再描画し、それ以外は定期的に統計情報を表示
+	totalTasks := 20
+	var progress *workerpool.ProgressUI
+	if *tui {
+		progress = workerpool.NewProgressUI(monitor, totalTasks)
+		progress.Start()
+	} else {
+		go func() {
+			ticker := time.NewTicker(5 * time.Second)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					monitor.PrintStats()
+				}
 			}
-		}
-	}()
+		}()
+	}
 
 	// 結果を取得（タスク完了を監視しながら）
 	fmt.Println("\n📊 結果を取得中...")
-	totalTasks := 20
 	results := make([]workerpool.TaskResult, 0, totalTasks)
 
-	for i := 0; i < totalTasks; i++ {
-		result := pool.GetResult()
+	for result := range pool.Results() {
 		results = append(results, result)
 
-		// 🆕 監視機能にタスク結果を通知
-		monitor.OnTaskResult(result)
-
 		// 進捗表示
-		fmt.Printf("📈 進捗: %d/%d 完了\n", len(results), totalTasks)
+		if progress == nil {
+			fmt.Printf("📈 進捗: %d/%d 完了\n", len(results), totalTasks)
+		}
+		if len(results) == totalTasks {
+			break
+		}
+	}
+	if progress != nil {
+		progress.Stop()
 	}
 
 	// 最終統計を表示
@@ -128,12 +204,10 @@ func main() {
 
 	fmt.Println("\n🌐 Web監視画面は http://localhost:8080 で確認できます")
 	fmt.Println("📊 JSONデータは http://localhost:8080/stats で取得できます")
-	fmt.Println("⏰ 10秒後にシステムを停止します...")
-
-	time.Sleep(10 * time.Second)
+	fmt.Println("⏰ 10秒間アイドルが続いたらシステムを停止します...")
 
-	// ワーカープールを停止
-	pool.Stop()
+	// ワーカープールがアイドルになってから停止
+	<-pool.StopWhenIdle(10 * time.Second)
 
 	fmt.Println("🎉 すべての処理が完了しました！")
 }
@@ -0,0 +1,267 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+	"github.com/hizzuu/worker-example/pkg/workerpoolpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// AgentConfig はエージェントの設定
+type AgentConfig struct {
+	ID         string                                           // エージェントの識別子（インスタンスごとに一意）
+	Labels     map[string]string                                // version, region, gpu など
+	Capacity   int                                              // 同時に実行するタスク数（0 の場合は1）
+	Processors map[workerpool.TaskType]workerpool.TaskProcessor // 処理できるタスクタイプとプロセッサ
+	PollWait   time.Duration                                    // タスク取得の待機時間（0 の場合は10秒）
+	Token      string                                           // コーディネーターの認証トークン（CoordinatorConfig.Token と揃える）
+}
+
+// Agent はコーディネーターからタスクを取得して実行するリモートワーカー
+type Agent struct {
+	config AgentConfig
+	client workerpoolpb.AgentServiceClient
+
+	mu      sync.Mutex
+	running map[uint64]context.CancelFunc // 実行中のタスク（中断用）
+	results chan *workerpoolpb.ReportResultRequest
+}
+
+// NewAgent はコーディネーターへの接続を使うエージェントを作成する
+func NewAgent(conn grpc.ClientConnInterface, config AgentConfig) *Agent {
+	if config.Capacity <= 0 {
+		config.Capacity = 1
+	}
+	if config.PollWait <= 0 {
+		config.PollWait = 10 * time.Second
+	}
+	return &Agent{
+		config:  config,
+		client:  workerpoolpb.NewAgentServiceClient(conn),
+		running: make(map[uint64]context.CancelFunc),
+		results: make(chan *workerpoolpb.ReportResultRequest, config.Capacity),
+	}
+}
+
+// Run はコーディネーターに登録し、ctx がキャンセルされるか接続が切れるまでタスクを実行する
+// 戻った時点で実行中だったタスクは中断されている（結果はコーディネーター側でリトライされる）
+func (a *Agent) Run(ctx context.Context) error {
+	ctx = withToken(ctx, a.config.Token)
+	types := make([]string, 0, len(a.config.Processors))
+	for taskType := range a.config.Processors {
+		types = append(types, string(taskType))
+	}
+	resp, err := a.client.Register(ctx, &workerpoolpb.RegisterRequest{
+		AgentId:   a.config.ID,
+		Labels:    a.config.Labels,
+		Capacity:  int32(a.config.Capacity),
+		TaskTypes: types,
+	})
+	if err != nil {
+		return fmt.Errorf("コーディネーターに登録できません: %w", err)
+	}
+	fmt.Printf("🛰️ エージェント %s を登録しました (同時実行数: %d)\n", a.config.ID, a.config.Capacity)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		if err != nil && ctx.Err() == nil {
+			errOnce.Do(func() { firstErr = err })
+		}
+		cancel()
+	}
+
+	reportDone := make(chan struct{})
+	go func() {
+		defer close(reportDone)
+		fail(a.reportResults(ctx))
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fail(a.heartbeat(ctx, resp.GetHeartbeatInterval().AsDuration()))
+	}()
+
+	for i := 0; i < a.config.Capacity; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fail(a.pullLoop(ctx))
+		}()
+	}
+
+	wg.Wait()
+	close(a.results)
+	<-reportDone
+	return firstErr
+}
+
+// pullLoop はタスクを取得して実行する（ctx がキャンセルされるまで繰り返す）
+func (a *Agent) pullLoop(ctx context.Context) error {
+	for ctx.Err() == nil {
+		resp, err := a.client.PullTask(ctx, &workerpoolpb.PullTaskRequest{
+			AgentId: a.config.ID,
+			Wait:    durationpb.New(a.config.PollWait),
+		})
+		if err != nil {
+			return fmt.Errorf("タスクを取得できません: %w", err)
+		}
+		if !resp.GetHasTask() {
+			continue
+		}
+		a.execute(ctx, resp)
+	}
+	return nil
+}
+
+// execute はタスクを実行して結果を送信キューに入れる
+func (a *Agent) execute(ctx context.Context, resp *workerpoolpb.PullTaskResponse) {
+	task := workerpoolpb.ToTask(resp.GetTask())
+
+	taskCtx, cancel := context.WithCancel(ctx)
+	if timeout := resp.GetTimeout().AsDuration(); timeout > 0 {
+		taskCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	a.mu.Lock()
+	a.running[resp.GetAttemptId()] = cancel
+	a.mu.Unlock()
+
+	defer func() {
+		a.mu.Lock()
+		delete(a.running, resp.GetAttemptId())
+		a.mu.Unlock()
+		cancel()
+	}()
+
+	var err error
+	output := &lockedBuffer{}
+	processor, exists := a.config.Processors[task.Type]
+	if !exists {
+		err = fmt.Errorf("タスクタイプ %s のプロセッサが登録されていません", task.Type)
+	} else {
+		fmt.Printf("⚡ エージェント %s がタスク %d (%s:%s) を処理中...\n", a.config.ID, task.ID, task.Type, task.Name)
+		err = processor(workerpool.WithTaskOutput(taskCtx, output), task)
+	}
+
+	result := &workerpoolpb.ReportResultRequest{
+		AgentId:   a.config.ID,
+		AttemptId: resp.GetAttemptId(),
+		Success:   err == nil,
+		Output:    output.String(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorKinds = workerpool.ErrorKinds(err)
+	}
+	select {
+	case a.results <- result:
+	case <-ctx.Done():
+	}
+}
+
+// heartbeat は定期的に生存と実行中のタスクを伝え、中断を指示されたタスクをキャンセルする
+func (a *Agent) heartbeat(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	stream, err := a.client.Heartbeat(ctx)
+	if err != nil {
+		return fmt.Errorf("ハートビートを開始できません: %w", err)
+	}
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			a.mu.Lock()
+			for _, id := range resp.GetCancelAttemptIds() {
+				if cancel, ok := a.running[id]; ok {
+					fmt.Printf("🛑 エージェント %s: コーディネーターの指示で実行 %d を中断します\n", a.config.ID, id)
+					cancel()
+				}
+			}
+			a.mu.Unlock()
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		a.mu.Lock()
+		running := make([]uint64, 0, len(a.running))
+		for id := range a.running {
+			running = append(running, id)
+		}
+		a.mu.Unlock()
+
+		if err := stream.Send(&workerpoolpb.HeartbeatRequest{AgentId: a.config.ID, RunningAttemptIds: running}); err != nil {
+			return fmt.Errorf("ハートビートを送れません: %w", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case err := <-recvErr:
+			return fmt.Errorf("ハートビートが切断されました: %w", err)
+		case <-ctx.Done():
+			stream.CloseSend()
+			return nil
+		}
+	}
+}
+
+// reportResults は実行結果をコーディネーターに送る（結果の送信キューが閉じられるまで）
+func (a *Agent) reportResults(ctx context.Context) error {
+	// 終了処理中も送信中の結果を届けられるよう、ctx とは独立したストリームを使う
+	stream, err := a.client.ReportResults(context.WithoutCancel(ctx))
+	if err != nil {
+		return fmt.Errorf("結果の送信を開始できません: %w", err)
+	}
+	for result := range a.results {
+		if err := stream.Send(result); err != nil {
+			// 送れなかった結果はコーディネーター側で切断として扱われリトライされる
+			for range a.results {
+			}
+			return fmt.Errorf("結果を送れません: %w", err)
+		}
+	}
+	if _, err := stream.CloseAndRecv(); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("結果の送信を終了できません: %w", err)
+	}
+	return nil
+}
+
+// lockedBuffer は複数のゴルーチンから書き込めるバッファ
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
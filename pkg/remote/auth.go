@@ -0,0 +1,94 @@
+package remote
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authorizationKey はエージェントが認証トークンを渡すメタデータのキー（authorization: Bearer <token>）
+const authorizationKey = "authorization"
+
+// withToken は呼び出しのメタデータに認証トークンを付ける（空の場合は何もしない）
+func withToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+token)
+}
+
+// authorize は呼び出し元のエージェントが CoordinatorConfig.Token を持っているか検証する（トークンが空の場合は検証しない）
+func (c *Coordinator) authorize(ctx context.Context) error {
+	if c.config.Token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(authorizationKey) {
+		given, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(given), []byte(c.config.Token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "エージェントの認証トークンが不正です")
+}
+
+// ServerTLSConfig はコーディネーターの gRPC サーバーの TLS 設定を作成する
+// clientCAFile を指定した場合は、その CA が発行したクライアント証明書を持つエージェントだけを受け付ける（mTLS）
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("サーバー証明書を読み込めません: %w", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if clientCAFile != "" {
+		pool, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// ClientTLSConfig はエージェントがコーディネーターに接続する TLS 設定を作成する
+// caFile が空の場合はシステムの CA で検証し、certFile・keyFile を指定した場合はクライアント証明書を提示する（mTLS）
+func ClientTLSConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	config := &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("クライアント証明書を読み込めません: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// loadCertPool は PEM 形式の CA 証明書を読み込む
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("CA 証明書を読み込めません: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA 証明書 %s に PEM の証明書がありません", path)
+	}
+	return pool, nil
+}
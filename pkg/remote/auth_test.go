@@ -0,0 +1,80 @@
+package remote
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+	"github.com/hizzuu/worker-example/pkg/workerpoolpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialCoordinator はメモリ上の接続でコーディネーターの gRPC サーバーを起動し、接続を返す
+func dialCoordinator(t *testing.T, config CoordinatorConfig) (*Coordinator, *grpc.ClientConn) {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	coordinator := NewCoordinator(config)
+	server := grpc.NewServer()
+	coordinator.RegisterService(server)
+	go server.Serve(listener)
+	t.Cleanup(func() {
+		server.Stop()
+		coordinator.Stop()
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return coordinator, conn
+}
+
+// トークンを設定したコーディネーターは、トークンを持たないエージェントの登録・タスクの取得を拒否する
+func TestCoordinatorRejectsAgentWithoutToken(t *testing.T) {
+	_, conn := dialCoordinator(t, CoordinatorConfig{Token: "secret"})
+	client := workerpoolpb.NewAgentServiceClient(conn)
+	ctx := context.Background()
+
+	for name, token := range map[string]string{"トークンなし": "", "不正なトークン": "guess"} {
+		_, err := client.Register(withToken(ctx, token), &workerpoolpb.RegisterRequest{AgentId: "intruder", TaskTypes: []string{"email"}})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("%s: Register の err = %v, want Unauthenticated", name, err)
+		}
+		_, err = client.PullTask(withToken(ctx, token), &workerpoolpb.PullTaskRequest{AgentId: "intruder"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("%s: PullTask の err = %v, want Unauthenticated", name, err)
+		}
+	}
+}
+
+// トークンを持つエージェントはタスクを受け取って実行できる
+func TestAgentWithTokenRunsTask(t *testing.T) {
+	coordinator, conn := dialCoordinator(t, CoordinatorConfig{Token: "secret"})
+	agent := NewAgent(conn, AgentConfig{
+		ID:       "agent-1",
+		Token:    "secret",
+		PollWait: 100 * time.Millisecond,
+		Processors: map[workerpool.TaskType]workerpool.TaskProcessor{
+			"email": func(ctx context.Context, task workerpool.Task) error { return nil },
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go agent.Run(ctx)
+
+	process := coordinator.Processor()
+	taskCtx, taskCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer taskCancel()
+	if err := process(taskCtx, workerpool.Task{ID: 1, Type: "email"}); err != nil {
+		t.Fatalf("リモートでの実行に失敗しました: %v", err)
+	}
+}
@@ -0,0 +1,440 @@
+// Package remote はワーカープールのタスクを別のマシンで動くワーカー（エージェント）に
+// gRPC で実行させるためのコーディネーターとエージェントを提供する
+//
+// コーディネーター側のプールには Coordinator.Processor をリモート実行するタスクタイプの
+// プロセッサとして登録する。プールのワーカーはタスクをエージェントに渡して結果を待つため、
+// リトライ判定・DLQ・監視はこれまでどおりコーディネーターのプールで行われる
+// （プールのワーカー数がリモートで同時に実行できるタスク数の上限になる）
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+	"github.com/hizzuu/worker-example/pkg/workerpoolpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var (
+	// ErrAgentLost は実行中のエージェントが応答しなくなった場合のエラー（RetryableError のためリトライされる）
+	ErrAgentLost error = &workerpool.RetryableError{Err: errors.New("リモートワーカー切断エラー: エージェントが応答しません")}
+	// ErrCoordinatorStopped はコーディネーターが停止している場合のエラー
+	ErrCoordinatorStopped = errors.New("コーディネーターは停止しています")
+)
+
+// AgentInfo は登録されているエージェントの情報
+type AgentInfo struct {
+	ID        string                `json:"id"`
+	Labels    map[string]string     `json:"labels"`
+	Capacity  int                   `json:"capacity"`
+	TaskTypes []workerpool.TaskType `json:"task_types"`
+	Running   int                   `json:"running"` // 実行中のタスク数
+	LastSeen  time.Time             `json:"last_seen"`
+}
+
+// agentState はエージェントの登録情報と最終応答時刻
+type agentState struct {
+	info     AgentInfo
+	types    map[workerpool.TaskType]bool
+	lastSeen time.Time
+}
+
+// attempt はエージェントに渡す1回分の実行
+type attempt struct {
+	id       uint64
+	task     workerpool.Task
+	timeout  time.Duration
+	agentID  string // 割り当て先（未割り当ての場合は空）
+	canceled bool   // 呼び出し元が待つのをやめた（エージェントに中断を伝える）
+	done     chan attemptResult
+}
+
+type attemptResult struct {
+	err    error
+	output string
+}
+
+// CoordinatorConfig はコーディネーターの設定
+// エージェントはタスクのペイロードを受け取り結果を報告できるため、別のマシンから接続させる場合は
+// Token を設定するか、ServerTLSConfig でクライアント証明書を検証する（mTLS）
+type CoordinatorConfig struct {
+	HeartbeatInterval time.Duration // エージェントがハートビートを送る間隔（0 の場合は2秒）
+	AgentTimeout      time.Duration // この時間ハートビートがないエージェントを切断とみなす（0 の場合は間隔の3倍）
+	Token             string        // エージェントの認証トークン（AgentConfig.Token と揃える。空の場合は認証しない）
+}
+
+// Coordinator はエージェントの登録を受け付け、タスクを配布して結果を受け取る gRPC サービス
+type Coordinator struct {
+	workerpoolpb.UnimplementedAgentServiceServer
+
+	config CoordinatorConfig
+
+	mu       sync.Mutex
+	agents   map[string]*agentState
+	pending  []*attempt          // 未割り当ての実行（投入順）
+	assigned map[uint64]*attempt // エージェントに割り当て済みの実行
+	nextID   uint64
+	notify   chan struct{} // 未割り当ての実行が増えたら閉じて差し替える
+	stopped  bool
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewCoordinator は新しいコーディネーターを作成し、切断したエージェントの監視を開始する
+func NewCoordinator(config CoordinatorConfig) *Coordinator {
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = 2 * time.Second
+	}
+	if config.AgentTimeout <= 0 {
+		config.AgentTimeout = 3 * config.HeartbeatInterval
+	}
+
+	c := &Coordinator{
+		config:   config,
+		agents:   make(map[string]*agentState),
+		assigned: make(map[uint64]*attempt),
+		notify:   make(chan struct{}),
+		stopCh:   make(chan struct{}),
+	}
+	c.wg.Add(1)
+	go c.reapAgents()
+	return c
+}
+
+// RegisterService は gRPC サーバーにコーディネーターを登録する
+func (c *Coordinator) RegisterService(server *grpc.Server) {
+	workerpoolpb.RegisterAgentServiceServer(server, c)
+}
+
+// Processor はタスクをエージェントに渡して結果を待つプロセッサを返す
+// エージェントの出力は TaskResult.Output に含まれる
+func (c *Coordinator) Processor() workerpool.TaskProcessor {
+	return func(ctx context.Context, task workerpool.Task) error {
+		var timeout time.Duration
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+
+		a, err := c.submit(task, timeout)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case result := <-a.done:
+			io.WriteString(workerpool.TaskOutput(ctx), result.output)
+			return result.err
+		case <-ctx.Done():
+			c.cancel(a)
+			return ctx.Err()
+		}
+	}
+}
+
+// Agents は登録されているエージェントの一覧を返す
+func (c *Coordinator) Agents() []AgentInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	agents := make([]AgentInfo, 0, len(c.agents))
+	for _, agent := range c.agents {
+		info := agent.info
+		info.LastSeen = agent.lastSeen
+		for _, a := range c.assigned {
+			if a.agentID == info.ID {
+				info.Running++
+			}
+		}
+		agents = append(agents, info)
+	}
+	return agents
+}
+
+// Stop は切断の監視を止め、未完了の実行をすべて失敗させる
+func (c *Coordinator) Stop() {
+	c.mu.Lock()
+	if c.stopped {
+		c.mu.Unlock()
+		return
+	}
+	c.stopped = true
+	pending := c.pending
+	c.pending = nil
+	assigned := c.assigned
+	c.assigned = make(map[uint64]*attempt)
+	close(c.notify)
+	c.mu.Unlock()
+
+	close(c.stopCh)
+	c.wg.Wait()
+
+	for _, a := range pending {
+		a.done <- attemptResult{err: ErrCoordinatorStopped}
+	}
+	for _, a := range assigned {
+		a.done <- attemptResult{err: ErrCoordinatorStopped}
+	}
+}
+
+// submit は実行を未割り当てのキューに追加する
+func (c *Coordinator) submit(task workerpool.Task, timeout time.Duration) (*attempt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return nil, ErrCoordinatorStopped
+	}
+	c.nextID++
+	a := &attempt{
+		id:      c.nextID,
+		task:    task,
+		timeout: timeout,
+		done:    make(chan attemptResult, 1),
+	}
+	c.pending = append(c.pending, a)
+	close(c.notify)
+	c.notify = make(chan struct{})
+	return a, nil
+}
+
+// cancel は呼び出し元が待つのをやめた実行を取り消す
+// 割り当て済みの場合はハートビートでエージェントに中断を伝え、結果は破棄する
+func (c *Coordinator) cancel(a *attempt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, p := range c.pending {
+		if p == a {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return
+		}
+	}
+	a.canceled = true
+}
+
+// Register はエージェントを登録する（同じIDで再登録した場合は情報を更新する）
+func (c *Coordinator) Register(ctx context.Context, req *workerpoolpb.RegisterRequest) (*workerpoolpb.RegisterResponse, error) {
+	if err := c.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if req.GetAgentId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id を指定してください")
+	}
+
+	types := make(map[workerpool.TaskType]bool, len(req.GetTaskTypes()))
+	taskTypes := make([]workerpool.TaskType, 0, len(req.GetTaskTypes()))
+	for _, taskType := range req.GetTaskTypes() {
+		types[workerpool.TaskType(taskType)] = true
+		taskTypes = append(taskTypes, workerpool.TaskType(taskType))
+	}
+
+	c.mu.Lock()
+	c.agents[req.GetAgentId()] = &agentState{
+		info: AgentInfo{
+			ID:        req.GetAgentId(),
+			Labels:    req.GetLabels(),
+			Capacity:  int(req.GetCapacity()),
+			TaskTypes: taskTypes,
+		},
+		types:    types,
+		lastSeen: time.Now(),
+	}
+	c.mu.Unlock()
+
+	fmt.Printf("🛰️ エージェント %s が登録されました (同時実行数: %d, タイプ: %v)\n", req.GetAgentId(), req.GetCapacity(), taskTypes)
+	return &workerpoolpb.RegisterResponse{
+		HeartbeatInterval: durationpb.New(c.config.HeartbeatInterval),
+	}, nil
+}
+
+// PullTask はエージェントが処理できる実行を1件割り当てる
+func (c *Coordinator) PullTask(ctx context.Context, req *workerpoolpb.PullTaskRequest) (*workerpoolpb.PullTaskResponse, error) {
+	if err := c.authorize(ctx); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(req.GetWait().AsDuration())
+	defer timer.Stop()
+
+	for {
+		c.mu.Lock()
+		agent, registered := c.agents[req.GetAgentId()]
+		if !registered {
+			c.mu.Unlock()
+			return nil, status.Error(codes.FailedPrecondition, "エージェントが登録されていません")
+		}
+		if c.stopped {
+			c.mu.Unlock()
+			return nil, status.Error(codes.Unavailable, ErrCoordinatorStopped.Error())
+		}
+		agent.lastSeen = time.Now()
+
+		if a, ok := c.takeLocked(agent); ok {
+			c.mu.Unlock()
+
+			task, err := workerpoolpb.FromTask(a.task)
+			if err != nil {
+				// 送れないタスクはこのエージェントでは実行できないため失敗させる
+				c.finish(agent.info.ID, a.id, attemptResult{err: err})
+				continue
+			}
+			return &workerpoolpb.PullTaskResponse{
+				HasTask:   true,
+				AttemptId: a.id,
+				Task:      task,
+				Timeout:   durationpb.New(a.timeout),
+			}, nil
+		}
+		notify := c.notify
+		c.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return &workerpoolpb.PullTaskResponse{}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// takeLocked は未割り当ての実行からエージェントが処理できるものを取り出して割り当てる（ロックを保持して呼ぶ）
+func (c *Coordinator) takeLocked(agent *agentState) (*attempt, bool) {
+	for i, a := range c.pending {
+		if !agent.types[a.task.Type] || !a.task.MatchesWorker(agent.info.Labels) {
+			continue
+		}
+		c.pending = append(c.pending[:i], c.pending[i+1:]...)
+		a.agentID = agent.info.ID
+		c.assigned[a.id] = a
+		return a, true
+	}
+	return nil, false
+}
+
+// Heartbeat はエージェントの生存を記録し、中断すべき実行を返す
+func (c *Coordinator) Heartbeat(stream workerpoolpb.AgentService_HeartbeatServer) error {
+	if err := c.authorize(stream.Context()); err != nil {
+		return err
+	}
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		c.mu.Lock()
+		agent, registered := c.agents[req.GetAgentId()]
+		if !registered {
+			c.mu.Unlock()
+			return status.Error(codes.FailedPrecondition, "エージェントが登録されていません")
+		}
+		agent.lastSeen = time.Now()
+
+		var canceled []uint64
+		for _, id := range req.GetRunningAttemptIds() {
+			if a, ok := c.assigned[id]; ok && a.canceled {
+				canceled = append(canceled, id)
+			}
+		}
+		c.mu.Unlock()
+
+		if err := stream.Send(&workerpoolpb.HeartbeatResponse{CancelAttemptIds: canceled}); err != nil {
+			return err
+		}
+	}
+}
+
+// ReportResults はエージェントから実行結果を受け取り、待っているプロセッサに渡す
+func (c *Coordinator) ReportResults(stream workerpoolpb.AgentService_ReportResultsServer) error {
+	if err := c.authorize(stream.Context()); err != nil {
+		return err
+	}
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&workerpoolpb.ReportResultsResponse{})
+		}
+		if err != nil {
+			return err
+		}
+
+		result := attemptResult{output: req.GetOutput()}
+		if !req.GetSuccess() {
+			// リトライの判定が型で行えるよう、エージェントが送った分類からエラーを復元する
+			result.err = workerpool.RestoreError(req.GetError(), req.GetErrorKinds())
+		}
+		c.finish(req.GetAgentId(), req.GetAttemptId(), result)
+	}
+}
+
+// finish は割り当て済みの実行を完了させる（取り消し済みの場合は結果を破棄する）
+func (c *Coordinator) finish(agentID string, attemptID uint64, result attemptResult) {
+	c.mu.Lock()
+	a, ok := c.assigned[attemptID]
+	if !ok || a.agentID != agentID {
+		// 切断とみなした後に届いた結果など
+		c.mu.Unlock()
+		return
+	}
+	delete(c.assigned, attemptID)
+	if agent, registered := c.agents[agentID]; registered {
+		agent.lastSeen = time.Now()
+	}
+	c.mu.Unlock()
+
+	if !a.canceled {
+		a.done <- result
+	}
+}
+
+// reapAgents は応答しなくなったエージェントを削除し、割り当てていた実行を失敗させる
+func (c *Coordinator) reapAgents() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.stopCh:
+			return
+		}
+
+		now := time.Now()
+		var lost []*attempt
+		c.mu.Lock()
+		for id, agent := range c.agents {
+			if now.Sub(agent.lastSeen) <= c.config.AgentTimeout {
+				continue
+			}
+			delete(c.agents, id)
+			fmt.Printf("💀 エージェント %s が応答しないため切断しました\n", id)
+			for attemptID, a := range c.assigned {
+				if a.agentID == id {
+					delete(c.assigned, attemptID)
+					lost = append(lost, a)
+				}
+			}
+		}
+		c.mu.Unlock()
+
+		for _, a := range lost {
+			if !a.canceled {
+				a.done <- attemptResult{err: fmt.Errorf("%w (%s)", ErrAgentLost, a.agentID)}
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+	"github.com/hizzuu/worker-example/pkg/workerpoolpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Peer は別のプールの FederationService にタスクを転送する転送先
+// workerpool.ForwardPolicy.Peers に指定して使う
+type Peer struct {
+	name   string
+	client workerpoolpb.FederationServiceClient
+}
+
+// NewPeer はピアのプールへの接続を使う転送先を作成する
+// name はピア側の FederationServer の識別子と揃えると、経由済みのピアに戻さないようにできる
+func NewPeer(name string, conn grpc.ClientConnInterface) *Peer {
+	return &Peer{name: name, client: workerpoolpb.NewFederationServiceClient(conn)}
+}
+
+// Name はピアの名前を返す
+func (p *Peer) Name() string { return p.name }
+
+// Forward はタスクをピアに渡す（ピアが受け付けなかった場合は workerpool.ErrPeerRejected）
+func (p *Peer) Forward(ctx context.Context, task workerpool.Task) error {
+	pb, err := workerpoolpb.FromTask(task)
+	if err != nil {
+		return err
+	}
+	_, err = p.client.Forward(ctx, &workerpoolpb.ForwardRequest{Task: pb})
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.ResourceExhausted, codes.FailedPrecondition, codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", workerpool.ErrPeerRejected, status.Convert(err).Message())
+	default:
+		return err
+	}
+}
+
+// FederationServer はピアから転送されたタスクをプールに投入する gRPC サービス
+// 受け付けたタスクの結果は投入先のプールで通知される
+type FederationServer struct {
+	workerpoolpb.UnimplementedFederationServiceServer
+
+	id   string
+	pool workerpool.Pool
+}
+
+// NewFederationServer は転送されたタスクを pool に投入するサービスを作成する
+// id はこのプールの識別子（ForwardPolicy.ID と揃える）
+func NewFederationServer(id string, pool workerpool.Pool) *FederationServer {
+	return &FederationServer{id: id, pool: pool}
+}
+
+// RegisterService は gRPC サーバーにサービスを登録する
+func (s *FederationServer) RegisterService(server *grpc.Server) {
+	workerpoolpb.RegisterFederationServiceServer(server, s)
+}
+
+// Forward は転送されたタスクをプールに投入する
+// プールが受け付けなかった場合は RESOURCE_EXHAUSTED（停止中は UNAVAILABLE）を返し、転送元は次のピアを試す
+func (s *FederationServer) Forward(ctx context.Context, req *workerpoolpb.ForwardRequest) (*workerpoolpb.ForwardResponse, error) {
+	if req.GetTask() == nil {
+		return nil, status.Error(codes.InvalidArgument, "task を指定してください")
+	}
+	task := workerpoolpb.ToTask(req.GetTask())
+	if task.ID <= 0 || task.Type == "" {
+		return nil, status.Error(codes.InvalidArgument, "task の id と type を指定してください")
+	}
+
+	if err := s.pool.AddTask(task); err != nil {
+		if errors.Is(err, workerpool.ErrPoolStopped) {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return &workerpoolpb.ForwardResponse{AcceptedBy: s.id}, nil
+}
@@ -0,0 +1,121 @@
+package workerpool
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrAdmissionRejected は予測完了時間がSLAを超えるため受付を拒否した場合のエラー
+	ErrAdmissionRejected = errors.New("アドミッション制御: 予測完了時間がSLAを超過しています")
+	// ErrTaskShed は過負荷のため任意タスクを破棄した場合のエラー
+	ErrTaskShed = errors.New("アドミッション制御: 過負荷のためタスクを破棄しました")
+)
+
+// AdmissionMode はSLA超過時の受付動作
+type AdmissionMode int
+
+const (
+	// AdmissionReject はSLA超過時にすべての新規タスクを拒否する
+	AdmissionReject AdmissionMode = iota
+	// AdmissionDegrade はSLA超過時に任意タスク（Sheddable）を破棄し、それ以外は優先度を下げて受け付ける
+	AdmissionDegrade
+)
+
+// DrainEstimator はキューが空になるまでの推定時間を提供する（通常は Monitor）
+type DrainEstimator interface {
+	DrainETA() time.Duration
+}
+
+// AdmissionPolicy は新規タスクの受付ポリシー
+type AdmissionPolicy struct {
+	SLA       time.Duration  // 許容する予測完了時間
+	Mode      AdmissionMode  // SLA超過時の動作
+	Estimator DrainEstimator // 完了予測の算出元
+}
+
+// AdmissionStats はアドミッション制御のカウンタ
+type AdmissionStats struct {
+	Rejected int64 `json:"rejected"` // 拒否したタスク数
+	Shed     int64 `json:"shed"`     // 破棄した任意タスク数
+	Degraded int64 `json:"degraded"` // 優先度を下げて受け付けたタスク数
+	Deferred int64 `json:"deferred"` // 負荷制御で保留したタスク数
+}
+
+// SetAdmissionPolicy はアドミッション制御を設定する（nil で無効化）
+func (wp *WorkerPool) SetAdmissionPolicy(policy *AdmissionPolicy) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.admission = policy
+}
+
+// AdmissionStats はアドミッション制御のカウンタを返す
+func (wp *WorkerPool) AdmissionStats() AdmissionStats {
+	return AdmissionStats{
+		Rejected: atomic.LoadInt64(&wp.admissionStats.Rejected),
+		Shed:     atomic.LoadInt64(&wp.admissionStats.Shed),
+		Degraded: atomic.LoadInt64(&wp.admissionStats.Degraded),
+		Deferred: atomic.LoadInt64(&wp.admissionStats.Deferred),
+	}
+}
+
+// admissionVerdict はアドミッション制御の判定結果
+type admissionVerdict struct {
+	task     Task          // 受け付ける場合のタスク（優先度を下げた場合は更新後）
+	eta      time.Duration // 判定に使った予測完了時間（未判定の場合は負の値）
+	sla      time.Duration
+	degraded bool  // 優先度を下げた
+	err      error // ErrAdmissionRejected または ErrTaskShed
+}
+
+// admit は受付ポリシーに従ってタスクを受け付けるか判定する
+// 優先度を下げて受け付ける場合は更新後のタスクを返す
+func (wp *WorkerPool) admit(task Task) (Task, error) {
+	verdict := wp.evaluateAdmission(task)
+	switch {
+	case errors.Is(verdict.err, ErrTaskShed):
+		atomic.AddInt64(&wp.admissionStats.Shed, 1)
+	case verdict.err != nil:
+		atomic.AddInt64(&wp.admissionStats.Rejected, 1)
+	case verdict.degraded:
+		event("task.degraded").taskOf(task).logf("⬇️ タスク %d の優先度を下げて受け付けます (予測完了: %v, SLA: %v)\n",
+			task.ID, verdict.eta.Round(time.Second), verdict.sla)
+		atomic.AddInt64(&wp.admissionStats.Degraded, 1)
+	}
+	return verdict.task, verdict.err
+}
+
+// evaluateAdmission は受付ポリシーの判定のみを行う（カウンタは更新しない）
+func (wp *WorkerPool) evaluateAdmission(task Task) admissionVerdict {
+	wp.mu.Lock()
+	policy := wp.admission
+	wp.mu.Unlock()
+
+	verdict := admissionVerdict{task: task, eta: -1}
+	if policy == nil || policy.Estimator == nil || policy.SLA <= 0 {
+		return verdict
+	}
+
+	verdict.eta = policy.Estimator.DrainETA()
+	verdict.sla = policy.SLA
+	if verdict.eta < 0 || verdict.eta <= policy.SLA {
+		return verdict
+	}
+
+	switch policy.Mode {
+	case AdmissionDegrade:
+		if task.Sheddable {
+			verdict.err = ErrTaskShed
+			return verdict
+		}
+		if task.Priority > PriorityLow {
+			verdict.task.Priority = PriorityLow
+			verdict.degraded = true
+		}
+	default:
+		verdict.err = ErrAdmissionRejected
+	}
+	return verdict
+}
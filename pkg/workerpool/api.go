@@ -0,0 +1,226 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxTaskRequestBytes は POST /tasks のリクエストボディの上限
+const maxTaskRequestBytes = 1 << 20
+
+// taskRequest は POST /tasks で受け付けるタスク
+type taskRequest struct {
+	ID         int               `json:"id"`
+	Name       string            `json:"name"`
+	Type       TaskType          `json:"type"`
+	Payload    interface{}       `json:"payload"`
+	Labels     map[string]string `json:"labels"`
+	Selector   map[string]string `json:"selector"`
+	Priority   *Priority         `json:"priority"` // 省略した場合は通常（テンプレートの場合はテンプレートの既定値）
+	Sheddable  bool              `json:"sheddable"`
+	Deadline   time.Time         `json:"deadline"`
+	MaxRetries int               `json:"max_retries"`
+	Timeout    time.Duration     `json:"timeout_ns"`
+
+	Template  string                 `json:"template"`  // 指定した場合はテンプレートからタスクを作成する
+	Variables map[string]interface{} `json:"variables"` // テンプレートの変数
+}
+
+// toTask はリクエストをタスクに変換する
+// template を指定した場合はテンプレートから作成し、リクエストで指定した項目（名前・ラベルなど）で上書きする
+func (r *taskRequest) toTask(templates *TemplateRegistry) (Task, error) {
+	if r.ID <= 0 {
+		return Task{}, fmt.Errorf("id は正の整数で指定してください")
+	}
+	if r.Template != "" {
+		return r.fromTemplate(templates)
+	}
+	if r.Type == "" {
+		return Task{}, fmt.Errorf("type を指定してください")
+	}
+
+	if r.Timeout < 0 {
+		return Task{}, fmt.Errorf("timeout_ns に負の値は指定できません")
+	}
+
+	task := Task{
+		ID:         r.ID,
+		Name:       r.Name,
+		Type:       r.Type,
+		Payload:    r.Payload,
+		Labels:     r.Labels,
+		Selector:   r.Selector,
+		Sheddable:  r.Sheddable,
+		Deadline:   r.Deadline,
+		MaxRetries: r.MaxRetries,
+		Timeout:    r.Timeout,
+		CreatedAt:  time.Now(),
+	}
+	if r.Priority != nil {
+		task.Priority = *r.Priority
+	}
+	return task, nil
+}
+
+// fromTemplate はテンプレートからタスクを作成する
+func (r *taskRequest) fromTemplate(templates *TemplateRegistry) (Task, error) {
+	if templates == nil {
+		return Task{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, r.Template)
+	}
+	if r.Payload != nil {
+		return Task{}, fmt.Errorf("template を指定した場合は payload を指定できません（variables を使ってください）")
+	}
+	task, err := templates.Instantiate(r.Template, r.ID, r.Variables)
+	if err != nil {
+		return Task{}, err
+	}
+	if r.Type != "" && r.Type != task.Type {
+		return Task{}, fmt.Errorf("type %s はテンプレート %s のタイプ %s と異なります", r.Type, r.Template, task.Type)
+	}
+
+	if r.Name != "" {
+		task.Name = r.Name
+	}
+	for key, value := range r.Labels {
+		if task.Labels == nil {
+			task.Labels = make(map[string]string)
+		}
+		task.Labels[key] = value
+	}
+	// 優先度・タイムアウト・最大リトライ回数は指定した場合のみテンプレートの既定値を上書きする
+	if r.Priority != nil {
+		task.Priority = *r.Priority
+	}
+	if r.Timeout > 0 {
+		task.Timeout = r.Timeout
+	}
+	if r.MaxRetries != 0 {
+		task.MaxRetries = r.MaxRetries
+	}
+	task.Selector = r.Selector
+	task.Sheddable = r.Sheddable
+	task.Deadline = r.Deadline
+	task.CreatedAt = time.Now()
+	return task, nil
+}
+
+// decodeTaskRequest はリクエストボディを1つのJSONオブジェクトとして読み込む（後続のデータがある場合はエラー）
+func decodeTaskRequest(body io.Reader, req *taskRequest) error {
+	decoder := json.NewDecoder(body)
+	if err := decoder.Decode(req); err != nil {
+		return err
+	}
+	// More は閉じ括弧だけが余った場合（{...}} など）を検出しないため、読み切れることを確かめる
+	if err := decoder.Decode(&json.RawMessage{}); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("JSONオブジェクトの後に余分なデータがあります")
+	}
+	return nil
+}
+
+// taskResultResponse は同期実行時に返すタスク結果
+type taskResultResponse struct {
+	TaskID       int            `json:"task_id"`
+	TaskName     string         `json:"task_name"`
+	TaskType     TaskType       `json:"task_type"`
+	Success      bool           `json:"success"`
+	Canceled     bool           `json:"canceled,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	ErrorCode    string         `json:"error_code,omitempty"`
+	DurationMs   float64        `json:"duration_ms"`
+	AttemptCount int            `json:"attempt_count"`
+	WorkerID     int            `json:"worker_id"`
+	Budget       *LatencyBudget `json:"budget,omitempty"`
+}
+
+// handleSubmitTask は POST /tasks でタスクを受け付ける
+// ?wait=true の場合は最終結果が出るまで待って返し、クライアントが切断するとタスクをキャンセルする
+// ?dry_run=true の場合は実行せずに処理計画を返す
+func (m *Monitor) handleSubmitTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "POST のみ対応しています")
+		return
+	}
+
+	var req taskRequest
+	if err := decodeTaskRequest(http.MaxBytesReader(w, r.Body, maxTaskRequestBytes), &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "リクエストの形式が不正です: "+err.Error())
+		return
+	}
+	m.mutex.RLock()
+	templates := m.templates
+	m.mutex.RUnlock()
+	task, err := req.toTask(templates)
+	if errors.Is(err, ErrTemplateNotFound) {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	task.Hops = forwardedHops(r) // 経路はボディではなくピアが付けるヘッダーからだけ受け取る
+
+	if r.URL.Query().Get("dry_run") == "true" {
+		plan := m.PlanTask(task)
+		writeJSON(w, http.StatusOK, plan)
+		return
+	}
+
+	if r.URL.Query().Get("wait") != "true" {
+		if err := m.pool.AddTask(task); err != nil {
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{
+			"task_id": task.ID,
+			"status":  "queued",
+		})
+		return
+	}
+
+	// クライアントが切断すると r.Context() がキャンセルされ、タスクにも伝播する
+	result, err := m.pool.Execute(r.Context(), task)
+	if err != nil {
+		if r.Context().Err() != nil {
+			event("task.canceled").taskOf(task).failed(r.Context().Err()).logf("🔌 クライアントが切断したためタスク %d をキャンセルしました\n", task.ID)
+			return
+		}
+		writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	resp := taskResultResponse{
+		TaskID:       result.TaskID,
+		TaskName:     result.TaskName,
+		TaskType:     result.TaskType,
+		Success:      result.Success,
+		Canceled:     result.Canceled,
+		DurationMs:   durationToMs(result.TotalDuration),
+		AttemptCount: result.AttemptCount,
+		WorkerID:     result.WorkerID,
+		Budget:       result.Budget,
+	}
+	if result.Error != nil {
+		resp.Error = result.Error.Error()
+		resp.ErrorCode = result.ErrorCode()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeJSON はJSONレスポンスを書き込む
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError はエラーをJSONで返す
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,797 @@
+package workerpool
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+)
+
+// swaggerUIFiles は埋め込んだSwagger UIの配布ファイル（swaggerui/README.md）
+//
+//go:embed swaggerui/swagger-ui-bundle.js swaggerui/swagger-ui.css
+var swaggerUIFiles embed.FS
+
+// handleAPIDocs は /api/docs でSwagger UIによるAPIエクスプローラを返す
+// 仕様はページに埋め込むため、認証済みのブラウザからそのままリクエストを試せる
+func (m *Monitor) handleAPIDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, apiDocsHTML)
+}
+
+// handleSwaggerUIAssets は /api/docs/swagger-ui/ でSwagger UIのスクリプトとスタイルシートを返す
+// 外部のCDNから読み込まないため、オフラインの環境でも使え、管理画面で第三者のスクリプトを実行しない
+func (m *Monitor) handleSwaggerUIAssets(w http.ResponseWriter, r *http.Request) {
+	files, _ := fs.Sub(swaggerUIFiles, "swaggerui")
+	http.StripPrefix("/api/docs/swagger-ui/", http.FileServerFS(files)).ServeHTTP(w, r)
+}
+
+// handleOpenAPISpec は /api/docs/openapi.json でOpenAPI仕様を返す
+func (m *Monitor) handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, openAPISpec)
+}
+
+// openAPISpec は管理・投入APIのOpenAPI仕様
+const openAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Worker Pool API",
+    "version": "1.0.0",
+    "description": "ワーカープールの統計取得とタスク投入のAPI"
+  },
+  "components": {
+    "securitySchemes": {
+      "bearerAuth": {"type": "http", "scheme": "bearer"},
+      "basicAuth": {"type": "http", "scheme": "basic"}
+    },
+    "schemas": {
+      "TaskRequest": {
+        "type": "object",
+        "required": ["id"],
+        "description": "type は template を指定しない場合に必須",
+        "properties": {
+          "id": {"type": "integer", "minimum": 1},
+          "name": {"type": "string"},
+          "type": {"type": "string", "enum": ["email", "image", "database", "report"]},
+          "payload": {"description": "任意のJSON値"},
+          "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+          "selector": {"type": "object", "additionalProperties": {"type": "string"}, "description": "実行できるワーカーのラベル条件（値 * はキーの存在のみ）"},
+          "priority": {"type": "integer", "enum": [-1, 0, 1], "description": "-1: 低, 0: 通常, 1: 高"},
+          "sheddable": {"type": "boolean", "description": "過負荷時に破棄してよいタスク"},
+          "deadline": {"type": "string", "format": "date-time", "description": "呼び出し元の期限"},
+          "max_retries": {"type": "integer", "description": "最大リトライ回数（0: タイプのポリシーに従う、負の値: リトライしない）"},
+          "timeout_ns": {"type": "integer", "description": "実行のタイムアウト（ナノ秒、0: プールの設定に従う）"},
+          "template": {"type": "string", "description": "タスクを作成するテンプレートの名前（payload とは併用できない。priority・timeout_ns・max_retries は指定した場合のみテンプレートの既定値を上書きする）"},
+          "variables": {"type": "object", "description": "テンプレートの変数（既定値より優先）"}
+        }
+      },
+      "TaskTemplate": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "type": {"type": "string"},
+          "task_name": {"type": "string", "description": "タスク名（{変数} を置き換える）"},
+          "payload": {"description": "ペイロードのひな形（文字列中の {変数} を置き換える）"},
+          "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+          "variables": {"type": "object", "description": "変数の既定値"},
+          "priority": {"type": "integer", "enum": [-1, 0, 1], "description": "作成するタスクの既定の優先度"},
+          "timeout_ns": {"type": "integer", "description": "既定のタイムアウト（ナノ秒、0: プールの設定に従う）"},
+          "max_retries": {"type": "integer", "description": "既定の最大リトライ回数"}
+        }
+      },
+      "TaskAccepted": {
+        "type": "object",
+        "properties": {
+          "task_id": {"type": "integer"},
+          "status": {"type": "string", "example": "queued"}
+        }
+      },
+      "DeadLetter": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "integer"},
+          "task": {"type": "object", "description": "タスク（payload を含む）"},
+          "reason": {"type": "string", "enum": ["failed", "shed", "shutdown", "canceled", "corrupt", "untrusted", "rejected"]},
+          "error": {"type": "string"},
+          "history": {"type": "array", "items": {"type": "object", "properties": {
+            "attempt": {"type": "integer"},
+            "error": {"type": "string"},
+            "at": {"type": "string", "format": "date-time"}
+          }}},
+          "added_at": {"type": "string", "format": "date-time"}
+        }
+      },
+      "ConfigChange": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "integer"},
+          "at": {"type": "string", "format": "date-time"},
+          "actor": {"type": "string"},
+          "action": {"type": "string", "enum": ["resize", "pause", "resume", "retry_policy", "task_timeout", "processor_replace", "processor_swap", "processor_unregister", "maintenance_windows"]},
+          "task_type": {"type": "string", "description": "タスクタイプごとの設定の場合"},
+          "before": {"description": "変更前の値"},
+          "after": {"description": "変更後の値（失敗した場合は変更前と同じ）"},
+          "error": {"type": "string", "description": "変更に失敗した場合"}
+        }
+      },
+      "TaskCapability": {
+        "type": "object",
+        "properties": {
+          "task_type": {"type": "string"},
+          "executor": {"type": "object", "properties": {
+            "kind": {"type": "string", "enum": ["in-process", "exec", "docker", "kubernetes", "script", "simulator"]},
+            "detail": {"type": "string", "description": "コマンド・イメージなど"}
+          }},
+          "canary": {"type": "boolean", "description": "カナリアで一部のタスクを別のプロセッサで実行している"},
+          "timeout_ns": {"type": "integer", "description": "タスクに指定がない場合のタイムアウト"},
+          "retry": {"type": "object", "properties": {
+            "max_retries": {"type": "integer"},
+            "initial_delay_ns": {"type": "integer"},
+            "max_delay_ns": {"type": "integer"},
+            "backoff_factor": {"type": "number"},
+            "exponential": {"type": "boolean"},
+            "jitter": {"type": "number"},
+            "jitter_strategy": {"type": "string", "enum": ["", "full", "equal"]},
+            "retry_on": {"type": "array", "items": {"type": "string"}},
+            "class_backoff": {"type": "object", "description": "エラーの種類（timeout / connection / rate_limited / other）ごとの遅延の曲線", "additionalProperties": {"type": "object", "properties": {
+              "initial_delay_ns": {"type": "integer"},
+              "max_delay_ns": {"type": "integer"},
+              "backoff_factor": {"type": "number"},
+              "exponential": {"type": "boolean"}
+            }}}
+          }},
+          "max_workers": {"type": "integer", "description": "このタイプを実行できるワーカー数の上限"},
+          "shared": {"type": "boolean", "description": "ワーカーを他のタイプと共有している"}
+        }
+      },
+      "HistoryEntry": {
+        "type": "object",
+        "properties": {
+          "task_id": {"type": "integer"},
+          "task_name": {"type": "string"},
+          "task_type": {"type": "string"},
+          "status": {"type": "string", "enum": ["succeeded", "failed", "retrying", "canceled"]},
+          "error": {"type": "object", "properties": {
+            "message": {"type": "string"},
+            "code": {"type": "string", "example": "TIMEOUT"},
+            "retryable": {"type": "boolean"}
+          }},
+          "attempt_count": {"type": "integer"},
+          "worker_id": {"type": "integer"},
+          "variant": {"type": "string"},
+          "duration_ns": {"type": "integer"},
+          "end_time": {"type": "string", "format": "date-time"}
+        }
+      },
+      "DownstreamStatus": {
+        "type": "object",
+        "properties": {
+          "task_type": {"type": "string"},
+          "healthy": {"type": "boolean", "description": "false の間はこのタイプのタスクを保留する"},
+          "since": {"type": "string", "format": "date-time", "description": "現在の状態になった日時"},
+          "last_checked": {"type": "string", "format": "date-time"},
+          "next_check": {"type": "string", "format": "date-time"},
+          "last_error": {"type": "string"},
+          "consecutive_failures": {"type": "integer"},
+          "consecutive_successes": {"type": "integer"}
+        }
+      },
+      "TaskHeat": {
+        "type": "object",
+        "properties": {
+          "class": {"type": "string", "enum": ["hot", "cold"]},
+          "since": {"type": "string", "format": "date-time", "description": "現在の分類になった日時"},
+          "tasks": {"type": "integer", "description": "期間内の処理件数"},
+          "share": {"type": "number", "description": "期間内の処理件数の割合"},
+          "rate_per_sec": {"type": "number"},
+          "p99_ns": {"type": "integer", "description": "期間内の処理時間の99パーセンタイル"},
+          "reserved_workers": {"type": "integer", "description": "専用ワーカー数（cold の場合は0）"},
+          "fast_path": {"type": "integer", "description": "専用レーンに投入した件数"},
+          "spilled": {"type": "integer", "description": "専用レーンが満杯のため共有キューに回した件数"}
+        }
+      },
+      "QueueStatus": {
+        "type": "object",
+        "properties": {
+          "size": {"type": "integer", "description": "キューに並べられるタスク数"},
+          "workers": {"type": "integer", "description": "起動中の専用ワーカー数"},
+          "rate_per_sec": {"type": "number", "description": "取り出しのレート上限（無制限の場合は省略）"},
+          "queued": {"type": "integer"},
+          "busy": {"type": "integer", "description": "専用ワーカーが実行中のタスク数"},
+          "routed": {"type": "integer", "description": "振り分けたタスクの累計"},
+          "throttled": {"type": "integer", "description": "レート上限のため取り出しを待った回数"}
+        }
+      },
+      "WorkerCount": {
+        "type": "object",
+        "properties": {
+          "workers": {"type": "integer", "description": "ワーカー数の上限"},
+          "running": {"type": "integer", "description": "起動中のワーカー数（縮小中は超過分が終了するまで上限より多い）"}
+        }
+      },
+      "PoolStateInfo": {
+        "type": "object",
+        "properties": {
+          "state": {"type": "string", "enum": ["created", "running", "paused", "draining", "stopped"]},
+          "since": {"type": "string", "format": "date-time"},
+          "entered_at": {"type": "object", "additionalProperties": {"type": "string", "format": "date-time"}},
+          "history": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "from": {"type": "string"},
+                "to": {"type": "string"},
+                "at": {"type": "string", "format": "date-time"}
+              }
+            }
+          }
+        }
+      },
+      "MaintenanceWindow": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "task_types": {"type": "array", "items": {"type": "string"}, "description": "空の場合はすべてのタイプ"},
+          "start": {"type": "string", "example": "02:00"},
+          "end": {"type": "string", "example": "03:00", "description": "開始より前の場合は日をまたぐ"},
+          "time_zone": {"type": "string", "example": "Asia/Tokyo"},
+          "weekdays": {"type": "array", "items": {"type": "integer", "minimum": 0, "maximum": 6}, "description": "0=日曜、空の場合は毎日"},
+          "active": {"type": "boolean"},
+          "active_until": {"type": "string", "format": "date-time"}
+        }
+      },
+      "BulkRequest": {
+        "type": "object",
+        "properties": {
+          "filter": {
+            "type": "object",
+            "properties": {
+              "types": {"type": "array", "items": {"type": "string"}},
+              "labels": {"type": "object", "additionalProperties": {"type": "string"}, "description": "値 * はキーの存在のみ"},
+              "created_after": {"type": "string", "format": "date-time"},
+              "created_before": {"type": "string", "format": "date-time"}
+            }
+          },
+          "all": {"type": "boolean", "description": "filter を指定せずすべてを対象にする場合に true"},
+          "priority": {"type": "integer", "description": "/bulk/priority で設定する優先度"}
+        }
+      },
+      "DeadLetterIDs": {
+        "type": "object",
+        "required": ["ids"],
+        "properties": {"ids": {"type": "array", "items": {"type": "integer"}}}
+      },
+      "RetryEntry": {
+        "type": "object",
+        "properties": {
+          "task_id": {"type": "integer"},
+          "task_name": {"type": "string"},
+          "task_type": {"type": "string"},
+          "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+          "attempt": {"type": "integer", "description": "次が何回目の試行か"},
+          "last_error": {"type": "string"},
+          "next_retry_at": {"type": "string", "format": "date-time"},
+          "scheduled_at": {"type": "string", "format": "date-time"}
+        }
+      },
+      "InstanceInfo": {
+        "type": "object",
+        "description": "SetInstanceInfo で設定したインスタンス情報（未設定の場合は省略）",
+        "properties": {
+          "id": {"type": "string"},
+          "service": {"type": "string"},
+          "region": {"type": "string"},
+          "version": {"type": "string"},
+          "labels": {"type": "object", "additionalProperties": {"type": "string"}}
+        }
+      },
+      "StatsDelta": {
+        "type": "object",
+        "properties": {
+          "token": {"type": "string", "description": "次回の since に指定するトークン"},
+          "since": {"type": "string", "format": "date-time"},
+          "until": {"type": "string", "format": "date-time"},
+          "interval_ms": {"type": "number"},
+          "reset": {"type": "boolean", "description": "since が不明なためモニター開始からの累計を返した"},
+          "instance": {"$ref": "#/components/schemas/InstanceInfo"},
+          "total_tasks": {"type": "integer"},
+          "completed_tasks": {"type": "integer"},
+          "failed_tasks": {"type": "integer"},
+          "admission": {"type": "object", "properties": {
+            "rejected": {"type": "integer"},
+            "shed": {"type": "integer"},
+            "degraded": {"type": "integer"},
+            "deferred": {"type": "integer"}
+          }},
+          "task_type_stats": {"type": "object", "additionalProperties": {"type": "object"}, "description": "期間内に完了したタスクのタイプ別集計"}
+        }
+      },
+      "SLOStatus": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "type": {"type": "string"},
+          "objective": {"type": "number", "description": "目標とする良い結果の割合"},
+          "threshold_ns": {"type": "integer", "description": "作成から最終結果までの上限（0 の場合は成功のみで判定）"},
+          "window_ns": {"type": "integer"},
+          "total": {"type": "integer"},
+          "good": {"type": "integer"},
+          "bad": {"type": "integer"},
+          "compliance": {"type": "number"},
+          "budget_remaining": {"type": "number", "description": "エラーバジェットの残り（負の値は超過）"},
+          "alerts": {"type": "array", "items": {"type": "object", "properties": {
+            "rule": {"type": "string"},
+            "severity": {"type": "string"},
+            "burn_rate_threshold": {"type": "number"},
+            "long_burn_rate": {"type": "number"},
+            "short_burn_rate": {"type": "number"},
+            "firing": {"type": "boolean"},
+            "since": {"type": "string", "format": "date-time"}
+          }}},
+          "retry_throttled": {"type": "boolean", "description": "エラーバジェットが残り少ないためタイプのリトライを絞っている"}
+        }
+      },
+      "SubmissionPlan": {
+        "type": "object",
+        "properties": {
+          "task_id": {"type": "integer"},
+          "task_type": {"type": "string"},
+          "decision": {"type": "string", "enum": ["queued", "deferred", "held", "shed", "rejected"]},
+          "reason": {"type": "string"},
+          "queue": {"type": "string"},
+          "requested_priority": {"type": "integer"},
+          "priority": {"type": "integer"},
+          "degraded": {"type": "boolean"},
+          "has_processor": {"type": "boolean"},
+          "variant": {"type": "string"},
+          "shadowed": {"type": "boolean"},
+          "queue_position": {"type": "integer"},
+          "available_workers": {"type": "integer"},
+          "drain_eta_ns": {"type": "integer"},
+          "predicted_wait_ns": {"type": "integer"}
+        }
+      },
+      "TaskResult": {
+        "type": "object",
+        "properties": {
+          "task_id": {"type": "integer"},
+          "task_name": {"type": "string"},
+          "task_type": {"type": "string"},
+          "success": {"type": "boolean"},
+          "canceled": {"type": "boolean", "description": "管理操作で取り消された"},
+          "error": {"type": "string"},
+          "error_code": {"type": "string", "enum": ["TIMEOUT", "CANCELED", "DEADLINE_EXCEEDED", "CORRUPT", "UNTRUSTED", "INVALID_PAYLOAD", "FAILED"]},
+          "duration_ms": {"type": "number"},
+          "attempt_count": {"type": "integer"},
+          "worker_id": {"type": "integer"},
+          "budget": {"$ref": "#/components/schemas/LatencyBudget"}
+        }
+      },
+      "LatencyBudget": {
+        "type": "object",
+        "description": "期限・タイムアウトと、キュー待ち・試行・リトライ待ちの時間の内訳",
+        "properties": {
+          "deadline": {"type": "string", "format": "date-time"},
+          "budget_ns": {"type": "integer", "description": "作成から期限までの時間"},
+          "attempt_timeout_ns": {"type": "integer"},
+          "consumed_ns": {"type": "integer"},
+          "queue_ns": {"type": "integer"},
+          "processing_ns": {"type": "integer"},
+          "retry_wait_ns": {"type": "integer"},
+          "phases": {"type": "array", "items": {"type": "object", "properties": {
+            "phase": {"type": "string", "enum": ["queue", "attempt", "retry_wait"]},
+            "attempt": {"type": "integer"},
+            "duration_ns": {"type": "integer"}
+          }}},
+          "exceeded": {"type": "boolean"},
+          "blown_at": {"type": "string", "description": "期限を超えた区間（queue, attempt2, retry_wait3 など）"},
+          "remaining_ns": {"type": "integer"}
+        }
+      },
+      "Error": {
+        "type": "object",
+        "properties": {"error": {"type": "string"}}
+      }
+    }
+  },
+  "paths": {
+    "/stats": {
+      "get": {
+        "summary": "プールの統計を取得",
+        "parameters": [
+          {"name": "group_by", "in": "query", "schema": {"type": "string"}, "description": "type またはラベルキー（例: region）"}
+        ],
+        "responses": {
+          "200": {"description": "統計", "content": {"application/json": {"schema": {"type": "object"}}}}
+        }
+      }
+    },
+    "/stats/delta": {
+      "get": {
+        "summary": "前回のトークン以降に積み上がったカウンタを取得",
+        "parameters": [
+          {"name": "since", "in": "query", "schema": {"type": "string"}, "description": "前回のレスポンスの token（未指定・期限切れの場合はモニター開始からの累計を reset=true で返す）"}
+        ],
+        "responses": {
+          "200": {"description": "カウンタの差分", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/StatsDelta"}}}}
+        }
+      }
+    },
+    "/retries": {
+      "get": {
+        "summary": "リトライ待ちのタスクを予定時刻の早い順に取得",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "responses": {
+          "200": {"description": "リトライ待ちのタスク", "content": {"application/json": {"schema": {"type": "object", "properties": {
+            "count": {"type": "integer"},
+            "retries": {"type": "array", "items": {"$ref": "#/components/schemas/RetryEntry"}}
+          }}}}},
+          "401": {"description": "認証が必要", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/retries/{id}/retry-now": {
+      "post": {
+        "summary": "リトライ待ちのタスクを予定時刻を待たずに再実行",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {
+          "200": {"description": "キューに戻した"},
+          "401": {"description": "認証が必要", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "404": {"description": "リトライ待ちでない", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/retries/{id}": {
+      "delete": {
+        "summary": "リトライを取り消してDLQに送る",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {
+          "200": {"description": "取り消した"},
+          "401": {"description": "認証が必要", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "404": {"description": "リトライ待ちでない", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/dlq": {
+      "get": {
+        "summary": "DLQの一覧を古い順に取得",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "responses": {
+          "200": {"description": "DLQのエントリ", "content": {"application/json": {"schema": {"type": "object", "properties": {
+            "count": {"type": "integer"},
+            "dead_letters": {"type": "array", "items": {"$ref": "#/components/schemas/DeadLetter"}}
+          }}}}},
+          "401": {"description": "認証が必要", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/dlq/{id}": {
+      "get": {
+        "summary": "DLQのエントリの詳細（ペイロードと失敗履歴）",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {
+          "200": {"description": "エントリ", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/DeadLetter"}}}},
+          "404": {"description": "エントリがない", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      },
+      "delete": {
+        "summary": "DLQのエントリを削除",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {
+          "200": {"description": "削除した"},
+          "404": {"description": "エントリがない", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/dlq/{id}/requeue": {
+      "post": {
+        "summary": "DLQのエントリを試行回数をリセットしてキューに戻す",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {
+          "200": {"description": "キューに戻した"},
+          "404": {"description": "エントリがない", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/dlq/requeue": {
+      "post": {
+        "summary": "DLQのエントリを一括でキューに戻す",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/DeadLetterIDs"}}}},
+        "responses": {
+          "200": {"description": "戻した件数", "content": {"application/json": {"schema": {"type": "object", "properties": {"requeued": {"type": "integer"}}}}}}
+        }
+      }
+    },
+    "/dlq/purge": {
+      "post": {
+        "summary": "DLQのエントリを一括で削除",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/DeadLetterIDs"}}}},
+        "responses": {
+          "200": {"description": "削除した件数", "content": {"application/json": {"schema": {"type": "object", "properties": {"purged": {"type": "integer"}}}}}}
+        }
+      }
+    },
+    "/bulk/cancel": {
+      "post": {
+        "summary": "条件に一致するキュー待ち・リトライ待ちのタスクを一括で取り消す（DLQに送る）",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/BulkRequest"}}}},
+        "responses": {
+          "200": {"description": "取り消した件数", "content": {"application/json": {"schema": {"type": "object", "properties": {"canceled": {"type": "integer"}}}}}},
+          "400": {"description": "条件がない（all: true が必要）", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/bulk/requeue": {
+      "post": {
+        "summary": "条件に一致するDLQのエントリを一括でキューに戻す",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/BulkRequest"}}}},
+        "responses": {
+          "200": {"description": "戻した件数", "content": {"application/json": {"schema": {"type": "object", "properties": {"requeued": {"type": "integer"}}}}}},
+          "400": {"description": "条件がない（all: true が必要）", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/bulk/priority": {
+      "post": {
+        "summary": "条件に一致するキュー待ち・リトライ待ちのタスクの優先度を一括で変更",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/BulkRequest"}}}},
+        "responses": {
+          "200": {"description": "変更した件数", "content": {"application/json": {"schema": {"type": "object", "properties": {"updated": {"type": "integer"}}}}}},
+          "400": {"description": "条件または priority がない", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/state": {
+      "get": {
+        "summary": "プールの状態（created / running / paused / draining / stopped）と遷移の日時",
+        "responses": {
+          "200": {"description": "現在の状態", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/PoolStateInfo"}}}}
+        }
+      }
+    },
+    "/pause": {
+      "post": {
+        "summary": "タスクの実行を一時停止する（受付は続ける）",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "responses": {
+          "200": {"description": "一時停止後の状態", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/PoolStateInfo"}}}},
+          "409": {"description": "実行中でない", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/resume": {
+      "post": {
+        "summary": "一時停止したタスクの実行を再開する",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "responses": {
+          "200": {"description": "再開後の状態", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/PoolStateInfo"}}}},
+          "409": {"description": "一時停止中でない", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/workers": {
+      "get": {
+        "summary": "ワーカー数の上限と起動中のワーカー数を取得",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "responses": {
+          "200": {"description": "ワーカー数", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/WorkerCount"}}}},
+          "501": {"description": "ワーカー数を変更できないプール", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      },
+      "post": {
+        "summary": "プールを再起動せずにワーカー数を変更する（減らす場合は超過分のワーカーが処理中のタスクを終えてから終了する）",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"type": "object", "required": ["workers"], "properties": {"workers": {"type": "integer", "minimum": 1}}}}}
+        },
+        "responses": {
+          "200": {"description": "変更後のワーカー数", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/WorkerCount"}}}},
+          "400": {"description": "ワーカー数が不正", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "501": {"description": "ワーカー数を変更できないプール", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/maintenance": {
+      "get": {
+        "summary": "メンテナンスウィンドウの一覧と現在の状態、下流のヘルスチェックの状況（時間帯中・下流の障害中のタスクは保留され、終了・回復後に自動でキューに戻る）",
+        "responses": {
+          "200": {
+            "description": "メンテナンスウィンドウ・下流のヘルスチェックの状況と保留中のタスク数",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {
+                "windows": {"type": "array", "items": {"$ref": "#/components/schemas/MaintenanceWindow"}},
+                "downstream": {"type": "array", "items": {"$ref": "#/components/schemas/DownstreamStatus"}},
+                "held": {"type": "integer"}
+              }
+            }}}
+          }
+        }
+      }
+    },
+    "/slo": {
+      "get": {
+        "summary": "SLOの達成状況・エラーバジェットの残り・バーンレートのアラートを取得",
+        "responses": {
+          "200": {"description": "SLOの達成状況", "content": {"application/json": {"schema": {"type": "object", "properties": {
+            "slos": {"type": "array", "items": {"$ref": "#/components/schemas/SLOStatus"}}
+          }}}}}
+        }
+      }
+    },
+    "/history": {
+      "get": {
+        "summary": "直近のタスク結果の履歴（新しい順、Monitor が既定で1000件まで保持する）",
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "default": 100}, "description": "返す最大件数"},
+          {"name": "type", "in": "query", "schema": {"type": "string"}, "description": "タスクタイプで絞り込む"},
+          {"name": "status", "in": "query", "schema": {"type": "string", "enum": ["succeeded", "failed", "retrying", "canceled"]}, "description": "結果の状態で絞り込む"}
+        ],
+        "responses": {
+          "200": {"description": "条件に一致する履歴", "content": {"application/json": {"schema": {"type": "object", "properties": {
+            "count": {"type": "integer"},
+            "history": {"type": "array", "items": {"$ref": "#/components/schemas/HistoryEntry"}}
+          }}}}},
+          "400": {"description": "limit または status が不正", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/heat": {
+      "get": {
+        "summary": "タスクタイプの hot / cold の分類（hot のタイプは専用レーンと専用ワーカーで処理される）",
+        "responses": {
+          "200": {"description": "タスクタイプごとの分類", "content": {"application/json": {"schema": {"type": "object", "properties": {
+            "task_heat": {"type": "object", "additionalProperties": {"$ref": "#/components/schemas/TaskHeat"}}
+          }}}}}
+        }
+      }
+    },
+    "/queues": {
+      "get": {
+        "summary": "名前付きキューごとの状況（ルーティング規則に一致したタスクはそのキューの専用ワーカーで処理される）",
+        "responses": {
+          "200": {"description": "キューの名前ごとの状況", "content": {"application/json": {"schema": {"type": "object", "properties": {
+            "queues": {"type": "object", "additionalProperties": {"$ref": "#/components/schemas/QueueStatus"}}
+          }}}}}
+        }
+      }
+    },
+    "/capabilities": {
+      "get": {
+        "summary": "タスクタイプごとの実行環境・タイムアウト・リトライポリシー・並行数と、投入レートの上限を取得",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "responses": {
+          "200": {"description": "プールが処理できるタスクタイプの一覧", "content": {"application/json": {"schema": {"type": "object", "properties": {
+            "task_types": {"type": "array", "items": {"$ref": "#/components/schemas/TaskCapability"}},
+            "rate_limit": {"type": "object", "description": "投入レートを制限していない場合は省略", "properties": {
+              "rate_per_sec": {"type": "number"},
+              "burst": {"type": "integer"}
+            }}
+          }}}}}
+        }
+      }
+    },
+    "/audit": {
+      "get": {
+        "summary": "実行時の設定変更（ワーカー数・一時停止・リトライポリシー・タイムアウト・プロセッサの差し替え・メンテナンスウィンドウ）の監査ログを古い順に取得（POST /workers・/pause・/resume の操作者は X-Actor ヘッダー、Basic認証のユーザー名の順に決まる）",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "parameters": [
+          {"name": "since", "in": "query", "schema": {"type": "integer"}, "description": "このIDより後の変更のみ返す"}
+        ],
+        "responses": {
+          "200": {"description": "設定変更の一覧", "content": {"application/json": {"schema": {"type": "object", "properties": {
+            "count": {"type": "integer"},
+            "changes": {"type": "array", "items": {"$ref": "#/components/schemas/ConfigChange"}}
+          }}}}},
+          "400": {"description": "since が不正", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "501": {"description": "監査ログが設定されていない", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/config": {
+      "get": {
+        "summary": "実行中のインスタンスが実際に使っている設定（取り込み・API での変更を反映した値）を取得（パスワード・トークンなどの秘密情報は REDACTED に置き換える）",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "responses": {
+          "200": {"description": "実行中の設定", "content": {"application/json": {"schema": {"type": "object", "properties": {
+            "instance": {"$ref": "#/components/schemas/InstanceInfo"},
+            "state": {"type": "string"},
+            "workers": {"type": "integer", "description": "ワーカー数の上限（変更できないプールでは省略）"},
+            "running_workers": {"type": "integer"},
+            "task_types": {"type": "array", "items": {"$ref": "#/components/schemas/TaskCapability"}},
+            "rate_limit": {"type": "object", "properties": {"rate_per_sec": {"type": "number"}, "burst": {"type": "integer"}}},
+            "maintenance_windows": {"type": "array", "items": {"$ref": "#/components/schemas/MaintenanceWindow"}},
+            "slos": {"type": "array", "items": {"type": "string"}},
+            "templates": {"type": "array", "items": {"type": "string"}},
+            "admin_auth": {"type": "boolean"},
+            "read_only": {"type": "boolean"},
+            "audited": {"type": "boolean", "description": "設定変更を監査ログに記録している"},
+            "sources": {"type": "array", "description": "取り込んだ順の設定の出所（後のものほど優先）", "items": {"type": "object", "properties": {
+              "name": {"type": "string", "example": "file:/etc/workerpool.json"},
+              "values": {"type": "object"}
+            }}}
+          }}}}}
+        }
+      }
+    },
+    "/divergence": {
+      "get": {
+        "summary": "シャドー実行・カナリアの乖離レポートを取得",
+        "responses": {
+          "200": {"description": "乖離レポート", "content": {"application/json": {"schema": {"type": "object"}}}}
+        }
+      }
+    },
+    "/tasks": {
+      "post": {
+        "summary": "タスクを投入",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "parameters": [
+          {"name": "wait", "in": "query", "schema": {"type": "boolean"}, "description": "true の場合は最終結果が出るまで待つ"},
+          {"name": "dry_run", "in": "query", "schema": {"type": "boolean"}, "description": "true の場合は実行せず、受付判定・投入先・優先度・予測待ち時間を返す"},
+          {"name": "X-Workerpool-Hop", "in": "header", "schema": {"type": "array", "items": {"type": "string"}}, "description": "転送してきたプールの識別子（ピアからの転送時に経由順に付ける。転送のループ防止に使う）"}
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/TaskRequest"}}}
+        },
+        "responses": {
+          "200": {"description": "最終結果（wait=true）または処理計画（dry_run=true）", "content": {"application/json": {"schema": {"oneOf": [{"$ref": "#/components/schemas/TaskResult"}, {"$ref": "#/components/schemas/SubmissionPlan"}]}}}},
+          "202": {"description": "受け付け", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/TaskAccepted"}}}},
+          "400": {"description": "リクエストが不正", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "401": {"description": "認証が必要", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "404": {"description": "テンプレートが見つからない", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "503": {"description": "受け付けられない（停止中・過負荷など）", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/templates": {
+      "get": {
+        "summary": "タスクテンプレートの一覧",
+        "security": [{"bearerAuth": []}, {"basicAuth": []}],
+        "responses": {
+          "200": {
+            "description": "登録済みのテンプレート",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {"templates": {"type": "array", "items": {"$ref": "#/components/schemas/TaskTemplate"}}}
+            }}}
+          }
+        }
+      }
+    }
+  }
+}`
+
+// apiDocsHTML はSwagger UIのページ
+const apiDocsHTML = `<!DOCTYPE html>
+<html lang="ja">
+<head>
+    <meta charset="UTF-8">
+    <title>Worker Pool API</title>
+    <link rel="stylesheet" href="/api/docs/swagger-ui/swagger-ui.css">
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="/api/docs/swagger-ui/swagger-ui-bundle.js"></script>
+    <script>
+        window.ui = SwaggerUIBundle({
+            spec: ` + openAPISpec + `,
+            dom_id: '#swagger-ui'
+        });
+    </script>
+</body>
+</html>`
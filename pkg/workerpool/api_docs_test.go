@@ -0,0 +1,42 @@
+package workerpool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// APIエクスプローラは外部のCDNを使わず、埋め込んだSwagger UIを配信する
+func TestAPIDocsServesEmbeddedSwaggerUI(t *testing.T) {
+	m := NewMonitor(NewWorkerPool(1))
+
+	page := httptest.NewRecorder()
+	m.handleAPIDocs(page, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	if body := page.Body.String(); strings.Contains(body, "https://") || strings.Contains(body, "http://") {
+		t.Fatalf("ページが外部のURLを参照しています")
+	}
+
+	for path, contentType := range map[string]string{
+		"/api/docs/swagger-ui/swagger-ui-bundle.js": "javascript",
+		"/api/docs/swagger-ui/swagger-ui.css":       "text/css",
+	} {
+		rec := httptest.NewRecorder()
+		m.handleSwaggerUIAssets(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+			t.Fatalf("%s: status = %d, %d バイト", path, rec.Code, rec.Body.Len())
+		}
+		if got := rec.Header().Get("Content-Type"); !strings.Contains(got, contentType) {
+			t.Fatalf("%s: Content-Type = %q", path, got)
+		}
+		if !strings.Contains(page.Body.String(), `"`+path+`"`) {
+			t.Fatalf("ページが %s を読み込んでいません", path)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	m.handleSwaggerUIAssets(rec, httptest.NewRequest(http.MethodGet, "/api/docs/swagger-ui/LICENSE", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("埋め込んでいないファイル: status = %d, want 404", rec.Code)
+	}
+}
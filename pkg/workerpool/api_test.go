@@ -0,0 +1,137 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// POST /tasks のリクエストボディは任意の入力でもパニックせず、受け付けたタスクは検証を満たす
+func FuzzDecodeTaskRequest(f *testing.F) {
+	seeds := []string{
+		`{"id":1,"type":"email","payload":{"to":"a@example.com"}}`,
+		`{"id":2,"type":"report","priority":3,"labels":{"team":"a"},"selector":{"zone":"x"},"timeout_ns":1000000000}`,
+		`{"id":3,"type":"image","deadline":"2030-01-01T00:00:00Z","max_retries":-1,"sheddable":true}`,
+		`{"id":4,"template":"welcome","variables":{"name":"x"}}`,
+		`{"id":0,"type":"email"}`,
+		`{"id":5,"type":"email","timeout_ns":-1}`,
+		`{"id":6,"type":"email"} {"id":7}`,
+		`{"id":8,"type":"email"}}`,
+		`{"id":9,"type":"email"}]`,
+		`{"id":10,"type":"email"} `,
+		`{"id":"1"}`,
+		`[]`,
+		`null`,
+		``,
+	}
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		var req taskRequest
+		if err := decodeTaskRequest(bytes.NewReader(body), &req); err != nil {
+			return
+		}
+		if !json.Valid(body) {
+			t.Fatalf("1つのJSON値ではないボディを受け付けました: %q", body)
+		}
+		task, err := req.toTask(nil)
+		if err != nil {
+			if req.ID > 0 && req.Template != "" && !errors.Is(err, ErrTemplateNotFound) {
+				t.Fatalf("テンプレートがない場合は ErrTemplateNotFound のはずです: %v", err)
+			}
+			return
+		}
+		if req.Template != "" {
+			t.Fatalf("テンプレートの登録がないのにタスクを作成しました: %+v", task)
+		}
+		if task.ID <= 0 || task.Type == "" || task.Timeout < 0 {
+			t.Fatalf("不正なタスクを受け付けました: %+v", task)
+		}
+		if task.ID != req.ID || task.Type != req.Type || task.MaxRetries != req.MaxRetries {
+			t.Fatalf("リクエストの値がタスクに反映されていません: req=%+v task=%+v", req, task)
+		}
+		if req.Priority != nil && task.Priority != *req.Priority {
+			t.Fatalf("Priority = %v, want %v", task.Priority, *req.Priority)
+		}
+
+		// 受け付けたリクエストは書き出して読み直しても同じタスクになる
+		encoded, err := json.Marshal(req)
+		if err != nil {
+			return
+		}
+		var again taskRequest
+		if err := decodeTaskRequest(bytes.NewReader(encoded), &again); err != nil {
+			t.Fatalf("書き出したリクエストを読み込めません: %v (%s)", err, encoded)
+		}
+		if _, err := again.toTask(nil); err != nil {
+			t.Fatalf("書き出したリクエストが受け付けられません: %v (%s)", err, encoded)
+		}
+	})
+}
+
+// JSONオブジェクトの後に余分なデータ（余った閉じ括弧を含む）があるボディは受け付けない
+func TestDecodeTaskRequestRejectsTrailingData(t *testing.T) {
+	tests := []struct {
+		body  string
+		valid bool
+	}{
+		{`{"id":1,"type":"email"}`, true},
+		{"{\"id\":1,\"type\":\"email\"}\n", true},
+		{`{"id":1,"type":"email"}}`, false},
+		{`{"id":1,"type":"email"}]`, false},
+		{`{"id":1,"type":"email"} {"id":2}`, false},
+		{`{"id":1,"type":"email"} x`, false},
+	}
+	for _, tt := range tests {
+		var req taskRequest
+		err := decodeTaskRequest(strings.NewReader(tt.body), &req)
+		if (err == nil) != tt.valid {
+			t.Errorf("%q: err = %v, valid = %v", tt.body, err, tt.valid)
+		}
+	}
+}
+
+// ?dry_run=true は処理計画をJSONで返すだけで、タスクを投入せずサーバーの標準出力にも書き込まない
+func TestSubmitTaskDryRun(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.RegisterProcessor("email", func(ctx context.Context, task Task) error { return nil })
+	m := NewMonitor(pool)
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	rec := httptest.NewRecorder()
+	m.handleSubmitTask(rec, httptest.NewRequest(http.MethodPost, "/tasks?dry_run=true", strings.NewReader(`{"id":1,"type":"email"}`)))
+	os.Stdout = stdout
+	w.Close()
+	printed, _ := io.ReadAll(r)
+
+	if len(printed) != 0 {
+		t.Fatalf("標準出力に書き込みました: %q", printed)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var plan SubmissionPlan
+	if err := json.Unmarshal(rec.Body.Bytes(), &plan); err != nil {
+		t.Fatal(err)
+	}
+	if plan.TaskID != 1 || !plan.HasProcessor {
+		t.Fatalf("plan = %+v", plan)
+	}
+	if got := pool.queuedTasks(); got != 0 {
+		t.Fatalf("キューのタスク = %d, want 0", got)
+	}
+}
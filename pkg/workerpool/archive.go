@@ -0,0 +1,385 @@
+package workerpool
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStoreNotArchivable はストアがアーカイブに対応していない場合のエラー
+var ErrStoreNotArchivable = errors.New("ストアが履歴のアーカイブに対応していません")
+
+// アーカイブの既定値
+const (
+	defaultArchiveAfter      = 24 * time.Hour
+	defaultArchiveInterval   = time.Hour
+	defaultArchiveMaxRecords = 10000
+	archiveTimeout           = 5 * time.Minute
+)
+
+// ArchivableStore は終了済みの記録を取り出して削除できるストア（履歴のアーカイブ・保持ポリシー用）
+type ArchivableStore interface {
+	TaskStore
+	// LoadFinished は before より前に終了した記録を終了日時の順に返す
+	LoadFinished(before time.Time) ([]TaskRecord, error)
+	// DeleteFinished は読み込んだ時点から変わっていない終了済みの記録を削除し、削除した件数を返す
+	DeleteFinished(records []TaskRecord) (int, error)
+}
+
+// ArchiveObject はアーカイブ先に保存したオブジェクト
+type ArchiveObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// ArchiveStorage は履歴の保存先（S3・GCS・ディレクトリなど）
+type ArchiveStorage interface {
+	// Put はオブジェクトを保存する
+	Put(ctx context.Context, key string, data []byte) error
+	// List は prefix で始まるオブジェクトを返す
+	List(ctx context.Context, prefix string) ([]ArchiveObject, error)
+	// Delete はオブジェクトを削除する
+	Delete(ctx context.Context, key string) error
+}
+
+// ArchiveConfig は履歴のアーカイブの設定
+type ArchiveConfig struct {
+	Prefix     string        `json:"prefix"`       // オブジェクトのキーの接頭辞（例: task-history/）
+	After      time.Duration `json:"after_ns"`     // 終了してからこの時間が経った記録をアーカイブする（0 の場合は24時間）
+	Interval   time.Duration `json:"interval_ns"`  // アーカイブする間隔（0 の場合は1時間）
+	Retention  time.Duration `json:"retention_ns"` // この時間より古いアーカイブを削除する（0 で削除しない）
+	MaxRecords int           `json:"max_records"`  // 1オブジェクトに含める最大件数（0 の場合は10000）
+}
+
+// ArchiveRun は1回のアーカイブの結果
+type ArchiveRun struct {
+	At       time.Time `json:"at"`
+	Archived int       `json:"archived"` // ストアから移した記録の件数
+	Objects  []string  `json:"objects"`  // 保存したオブジェクトのキー
+	Expired  int       `json:"expired"`  // 保持期間を過ぎて削除したオブジェクトの数
+	Error    string    `json:"error,omitempty"`
+}
+
+// Archiver は終了済みのタスクの記録を定期的に gzip 圧縮した JSONL としてアーカイブ先に保存し、ストアから削除する
+// ストアを小さく保ったまま、監査のために履歴を残せる。保持期間を過ぎたアーカイブは削除する
+// アーカイブした記録はストアから消えるため、リースによる重複実行の防止はアーカイブ前の期間に限られる
+type Archiver struct {
+	store   ArchivableStore
+	storage ArchiveStorage
+	config  ArchiveConfig
+
+	mu      sync.Mutex
+	lastRun ArchiveRun
+	seq     int
+
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	started bool
+}
+
+// NewArchiver は履歴のアーカイブを作成する（store は ArchivableStore を実装している必要がある）
+func NewArchiver(store TaskStore, storage ArchiveStorage, config ArchiveConfig) (*Archiver, error) {
+	archivable, ok := store.(ArchivableStore)
+	if !ok {
+		return nil, ErrStoreNotArchivable
+	}
+	if config.After <= 0 {
+		config.After = defaultArchiveAfter
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultArchiveInterval
+	}
+	if config.MaxRecords <= 0 {
+		config.MaxRecords = defaultArchiveMaxRecords
+	}
+	return &Archiver{store: archivable, storage: storage, config: config}, nil
+}
+
+// Start は定期的なアーカイブを開始する（開始時に1回実行する）
+func (a *Archiver) Start() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return
+	}
+	a.started = true
+	a.stopCh = make(chan struct{})
+	a.wg.Add(1)
+	go a.run()
+}
+
+// Stop は定期的なアーカイブを停止する（実行中のアーカイブは最後まで行う）
+func (a *Archiver) Stop() {
+	a.mu.Lock()
+	if !a.started {
+		a.mu.Unlock()
+		return
+	}
+	a.started = false
+	a.mu.Unlock()
+
+	close(a.stopCh)
+	a.wg.Wait()
+}
+
+// LastRun は直近のアーカイブの結果を返す
+func (a *Archiver) LastRun() ArchiveRun {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.lastRun
+}
+
+// run は間隔ごとにアーカイブする
+func (a *Archiver) run() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), archiveTimeout)
+		if _, err := a.RunOnce(ctx); err != nil {
+			event("archive.failed").failed(err).logf("⚠️ タスク履歴をアーカイブできませんでした: %v\n", err)
+		}
+		cancel()
+
+		select {
+		case <-ticker.C:
+		case <-a.stopCh:
+			return
+		}
+	}
+}
+
+// RunOnce は終了済みの記録をアーカイブし、保持期間を過ぎたアーカイブを削除する
+// 保存に失敗した記録はストアに残り、次回にアーカイブされる
+func (a *Archiver) RunOnce(ctx context.Context) (ArchiveRun, error) {
+	run := ArchiveRun{At: time.Now()}
+	err := a.archive(ctx, &run)
+	if err == nil {
+		err = a.expire(ctx, &run)
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	a.mu.Lock()
+	a.lastRun = run
+	a.mu.Unlock()
+
+	if run.Archived > 0 || run.Expired > 0 {
+		event("archive.completed").logf("🗄️ タスク履歴 %d 件を %d 個のオブジェクトにアーカイブしました (期限切れの削除: %d)\n",
+			run.Archived, len(run.Objects), run.Expired)
+	}
+	return run, err
+}
+
+// archive は終了済みの記録をまとめて保存し、保存できた分をストアから削除する
+func (a *Archiver) archive(ctx context.Context, run *ArchiveRun) error {
+	records, err := a.store.LoadFinished(run.At.Add(-a.config.After))
+	if err != nil {
+		return fmt.Errorf("終了済みの記録を読み込めません: %w", err)
+	}
+
+	for start := 0; start < len(records); start += a.config.MaxRecords {
+		chunk := records[start:min(start+a.config.MaxRecords, len(records))]
+		data, err := encodeArchive(chunk)
+		if err != nil {
+			return err
+		}
+
+		key := a.objectKey(run.At)
+		if err := a.storage.Put(ctx, key, data); err != nil {
+			return fmt.Errorf("アーカイブ %s を保存できません: %w", key, err)
+		}
+		run.Objects = append(run.Objects, key)
+
+		deleted, err := a.store.DeleteFinished(chunk)
+		run.Archived += deleted
+		if err != nil {
+			return fmt.Errorf("アーカイブした記録をストアから削除できません: %w", err)
+		}
+	}
+	return nil
+}
+
+// expire は保持期間を過ぎたアーカイブを削除する
+func (a *Archiver) expire(ctx context.Context, run *ArchiveRun) error {
+	if a.config.Retention <= 0 {
+		return nil
+	}
+
+	objects, err := a.storage.List(ctx, a.config.Prefix)
+	if err != nil {
+		return fmt.Errorf("アーカイブの一覧を取得できません: %w", err)
+	}
+	cutoff := run.At.Add(-a.config.Retention)
+	for _, object := range objects {
+		if object.LastModified.IsZero() || !object.LastModified.Before(cutoff) {
+			continue
+		}
+		if err := a.storage.Delete(ctx, object.Key); err != nil {
+			return fmt.Errorf("アーカイブ %s を削除できません: %w", object.Key, err)
+		}
+		run.Expired++
+	}
+	return nil
+}
+
+// objectKey は保存するオブジェクトのキーを返す（日付ごとに分け、時刻と連番で一意にする）
+func (a *Archiver) objectKey(at time.Time) string {
+	a.mu.Lock()
+	a.seq++
+	seq := a.seq
+	a.mu.Unlock()
+
+	at = at.UTC()
+	return fmt.Sprintf("%s%s/history-%s-%04d.jsonl.gz", a.config.Prefix, at.Format("2006/01/02"), at.Format("20060102T150405Z"), seq)
+}
+
+// encodeArchive は記録を1行1件の JSON にして gzip で圧縮する
+func encodeArchive(records []TaskRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return nil, fmt.Errorf("記録 %d をアーカイブ用に変換できません: %w", record.Task.ID, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LoadFinished は before より前に終了した記録を返す
+func (s *MemoryStore) LoadFinished(before time.Time) ([]TaskRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return finishedRecords(s.records, before), nil
+}
+
+// DeleteFinished は変わっていない終了済みの記録を削除する
+func (s *MemoryStore) DeleteFinished(records []TaskRecord) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return deleteFinishedRecords(s.records, records), nil
+}
+
+// LoadFinished は before より前に終了した記録を返す
+func (s *FileStore) LoadFinished(before time.Time) ([]TaskRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return finishedRecords(s.records, before), nil
+}
+
+// DeleteFinished は変わっていない終了済みの記録を削除し、ファイルに書き出す
+func (s *FileStore) DeleteFinished(records []TaskRecord) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := deleteFinishedRecords(s.records, records)
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, s.flush()
+}
+
+// finishedRecords は before より前に終了した記録を終了日時の順に返す
+func finishedRecords(records map[int]TaskRecord, before time.Time) []TaskRecord {
+	finished := make([]TaskRecord, 0)
+	for _, record := range records {
+		if record.State.isTerminal() && record.UpdatedAt.Before(before) {
+			finished = append(finished, record)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].UpdatedAt.Before(finished[j].UpdatedAt) })
+	return finished
+}
+
+// deleteFinishedRecords は読み込んだ時点から更新されていない終了済みの記録を削除する
+// （同じIDで再投入されたタスクの記録は削除しない）
+func deleteFinishedRecords(records map[int]TaskRecord, targets []TaskRecord) int {
+	deleted := 0
+	for _, target := range targets {
+		current, exists := records[target.Task.ID]
+		if !exists || !current.State.isTerminal() || !current.UpdatedAt.Equal(target.UpdatedAt) {
+			continue
+		}
+		delete(records, target.Task.ID)
+		deleted++
+	}
+	return deleted
+}
+
+// DirStorage はローカルのディレクトリ（NFS などのマウント先を含む）をアーカイブ先とする
+type DirStorage struct {
+	Dir string
+}
+
+// Put はファイルとして保存する（キーの / はディレクトリになる）
+func (d DirStorage) Put(ctx context.Context, key string, data []byte) error {
+	path := filepath.Join(d.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// List は prefix で始まるファイルを返す
+func (d DirStorage) List(ctx context.Context, prefix string) ([]ArchiveObject, error) {
+	var objects []ArchiveObject
+	err := filepath.WalkDir(d.Dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(d.Dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) || strings.HasSuffix(key, ".tmp") {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ArchiveObject{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return objects, err
+}
+
+// Delete はファイルを削除する
+func (d DirStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(d.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,325 @@
+package workerpool
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ActorLocal は操作者を指定せずに AuditedPool を通して変更した場合の操作者
+const ActorLocal = "local"
+
+// 監査ログに記録する設定変更の種類
+const (
+	AuditResize            = "resize"
+	AuditPause             = "pause"
+	AuditResume            = "resume"
+	AuditRetryPolicy       = "retry_policy"
+	AuditTaskTimeout       = "task_timeout"
+	AuditProcessorReplace  = "processor_replace"
+	AuditProcessorSwap     = "processor_swap"
+	AuditProcessorRemove   = "processor_unregister"
+	AuditMaintenanceWindow = "maintenance_windows"
+)
+
+// ConfigChange は監査ログに記録した実行時の設定変更
+type ConfigChange struct {
+	ID       uint64      `json:"id"`
+	At       time.Time   `json:"at"`
+	Actor    string      `json:"actor"`
+	Action   string      `json:"action"`
+	TaskType TaskType    `json:"task_type,omitempty"` // タスクタイプごとの設定の場合
+	Before   interface{} `json:"before"`
+	After    interface{} `json:"after"`
+	Error    string      `json:"error,omitempty"` // 変更に失敗した場合（Before と After は同じ）
+}
+
+// AuditLog は実行時の設定変更の監査ログ（容量を超えると古いものから破棄）
+// SetOutput で書き出し先を指定すると、破棄される前の記録も JSON Lines で残せる
+type AuditLog struct {
+	mu       sync.Mutex
+	seq      uint64
+	entries  []ConfigChange
+	capacity int
+	out      io.Writer
+}
+
+// NewAuditLog は新しい監査ログを作成する
+func NewAuditLog(capacity int) *AuditLog {
+	return &AuditLog{capacity: max(capacity, 1)}
+}
+
+// SetOutput は記録した変更を1行1件の JSON で書き出す先を設定する（nil で書き出さない）
+func (l *AuditLog) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.out = w
+}
+
+// record は設定変更を記録する
+func (l *AuditLog) record(change ConfigChange) ConfigChange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	change.ID = l.seq
+	change.At = time.Now()
+	l.entries = append(l.entries, change)
+	if len(l.entries) > l.capacity {
+		l.entries = l.entries[len(l.entries)-l.capacity:]
+	}
+	if l.out != nil {
+		if data, err := json.Marshal(change); err == nil {
+			l.out.Write(append(data, '\n'))
+		}
+	}
+	return change
+}
+
+// List は sinceID より後に記録した変更を古い順に返す（0 ですべて）
+func (l *AuditLog) List(sinceID uint64) []ConfigChange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make([]ConfigChange, 0, len(l.entries))
+	for _, entry := range l.entries {
+		if entry.ID > sinceID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// AuditedPool は実行時の設定変更（ワーカー数・一時停止・リトライポリシー・タイムアウト・プロセッサの差し替え・
+// メンテナンスウィンドウ）を操作者・日時・変更前後の値とともに監査ログに記録する
+// すべての変更を記録するには、プールを組み立てる箇所で一番外側に重ね、設定の変更はこのデコレーターを通して行うこと
+//
+//	pool := NewAuditedPool(NewAuthzPool(NewWorkerPool(4), authorize), NewAuditLog(1000))
+//	pool.As("alice").Pause()
+type AuditedPool struct {
+	Pool
+
+	log   *AuditLog
+	actor string
+}
+
+// NewAuditedPool は設定変更を log に記録するプールを作成する（操作者は ActorLocal）
+func NewAuditedPool(pool Pool, log *AuditLog) *AuditedPool {
+	return &AuditedPool{Pool: pool, log: log, actor: ActorLocal}
+}
+
+// Unwrap は元のプールを返す
+func (p *AuditedPool) Unwrap() Pool { return p.Pool }
+
+// AuditLog は記録先の監査ログを返す
+func (p *AuditedPool) AuditLog() *AuditLog { return p.log }
+
+// As は操作者を actor として記録するプールを返す（監査ログと元のプールは共有する）
+func (p *AuditedPool) As(actor string) *AuditedPool {
+	if actor == "" {
+		actor = ActorLocal
+	}
+	return &AuditedPool{Pool: p.Pool, log: p.log, actor: actor}
+}
+
+// record は変更を監査ログに記録する（失敗した場合は変更前の値を After にも入れる）
+func (p *AuditedPool) record(action string, taskType TaskType, before, after interface{}, err error) {
+	change := ConfigChange{Actor: p.actor, Action: action, TaskType: taskType, Before: before, After: after}
+	if err != nil {
+		change.After = before
+		change.Error = err.Error()
+	}
+	change = p.log.record(change)
+	event("config.changed").failed(err).logf("📝 設定変更 #%d: %s %s (操作者: %s)\n", change.ID, action, taskType, p.actor)
+}
+
+// capability は元のプールに登録されたタスクタイプの実行環境と制限を返す
+func (p *AuditedPool) capability(taskType TaskType) (TaskCapability, bool) {
+	for _, capability := range DescribeCapabilities(p.Pool).TaskTypes {
+		if capability.TaskType == taskType {
+			return capability, true
+		}
+	}
+	return TaskCapability{}, false
+}
+
+// timeouts はタスクタイプごとのタイムアウトを返す
+func (p *AuditedPool) timeouts() map[TaskType]time.Duration {
+	timeouts := make(map[TaskType]time.Duration)
+	for _, capability := range DescribeCapabilities(p.Pool).TaskTypes {
+		timeouts[capability.TaskType] = capability.Timeout
+	}
+	return timeouts
+}
+
+func (p *AuditedPool) Pause() error {
+	before := p.Pool.State()
+	err := p.Pool.Pause()
+	p.record(AuditPause, "", before, p.Pool.State(), err)
+	return err
+}
+
+func (p *AuditedPool) Resume() error {
+	before := p.Pool.State()
+	err := p.Pool.Resume()
+	p.record(AuditResume, "", before, p.Pool.State(), err)
+	return err
+}
+
+func (p *AuditedPool) SetMaintenanceWindows(windows []MaintenanceWindow) error {
+	before := p.Pool.MaintenanceWindows()
+	err := p.Pool.SetMaintenanceWindows(windows)
+	p.record(AuditMaintenanceWindow, "", before, p.Pool.MaintenanceWindows(), err)
+	return err
+}
+
+func (p *AuditedPool) ReplaceProcessor(taskType TaskType, processor TaskProcessor) error {
+	before, _ := p.capability(taskType)
+	err := p.Pool.ReplaceProcessor(taskType, processor)
+	after, _ := p.capability(taskType)
+	p.record(AuditProcessorReplace, taskType, before.Executor, after.Executor, err)
+	return err
+}
+
+func (p *AuditedPool) UnregisterProcessor(taskType TaskType) error {
+	before, _ := p.capability(taskType)
+	err := p.Pool.UnregisterProcessor(taskType)
+	p.record(AuditProcessorRemove, taskType, before.Executor, nil, err)
+	return err
+}
+
+// Resize はワーカー数を変更する（元のプールが対応していない場合は何もしない）
+func (p *AuditedPool) Resize(n int) error {
+	pool, ok := UnwrapPool(p.Pool).(resizablePool)
+	if !ok {
+		return nil
+	}
+	before := pool.MaxWorkers()
+	err := pool.Resize(n)
+	p.record(AuditResize, "", before, pool.MaxWorkers(), err)
+	return err
+}
+
+// MaxWorkers は元のプールのワーカー数の上限を返す
+func (p *AuditedPool) MaxWorkers() int {
+	if pool, ok := UnwrapPool(p.Pool).(resizablePool); ok {
+		return pool.MaxWorkers()
+	}
+	return 0
+}
+
+// RunningWorkers は元のプールの起動中のワーカー数を返す
+func (p *AuditedPool) RunningWorkers() int {
+	if pool, ok := UnwrapPool(p.Pool).(resizablePool); ok {
+		return pool.RunningWorkers()
+	}
+	return 0
+}
+
+// SetRetryPolicy はタスクタイプのリトライポリシーを変更する（元のプールが対応していない場合は何もしない）
+func (p *AuditedPool) SetRetryPolicy(taskType TaskType, policy RetryPolicy) {
+	pool, ok := UnwrapPool(p.Pool).(interface {
+		SetRetryPolicy(TaskType, RetryPolicy)
+	})
+	if !ok {
+		return
+	}
+	var before interface{}
+	if capability, exists := p.capability(taskType); exists {
+		before = capability.Retry
+	}
+	pool.SetRetryPolicy(taskType, policy)
+	p.record(AuditRetryPolicy, taskType, before, retryCapabilityOf(policy), nil)
+}
+
+// SetTaskTimeout はタスクのタイムアウトを変更する（元のプールが対応していない場合は何もしない）
+func (p *AuditedPool) SetTaskTimeout(timeout time.Duration) {
+	pool, ok := UnwrapPool(p.Pool).(interface{ SetTaskTimeout(time.Duration) })
+	if !ok {
+		return
+	}
+	before := p.timeouts()
+	pool.SetTaskTimeout(timeout)
+	p.record(AuditTaskTimeout, "", before, p.timeouts(), nil)
+}
+
+// SwapProcessor はプロセッサを差し替え、旧プロセッサで実行中のタスクが終わるまで待つ
+// 元のプールが対応していない場合は ReplaceProcessor で差し替える
+func (p *AuditedPool) SwapProcessor(ctx context.Context, taskType TaskType, processor TaskProcessor) error {
+	pool, ok := UnwrapPool(p.Pool).(interface {
+		SwapProcessor(context.Context, TaskType, TaskProcessor) error
+	})
+	if !ok {
+		return p.ReplaceProcessor(taskType, processor)
+	}
+	before, _ := p.capability(taskType)
+	err := pool.SwapProcessor(ctx, taskType, processor)
+	after, _ := p.capability(taskType)
+	p.record(AuditProcessorSwap, taskType, before.Executor, after.Executor, err)
+	return err
+}
+
+// auditedPool は Monitor のプールから AuditedPool を探す（重ねていない場合は nil）
+func auditedPool(pool Pool) *AuditedPool {
+	for {
+		if audited, ok := pool.(*AuditedPool); ok {
+			return audited
+		}
+		wrapper, ok := pool.(interface{ Unwrap() Pool })
+		if !ok {
+			return nil
+		}
+		pool = wrapper.Unwrap()
+	}
+}
+
+// poolFor はリクエストの操作者として設定変更を記録するプールを返す（AuditedPool を重ねていない場合はそのまま）
+// 操作者は X-Actor ヘッダー、Basic認証のユーザー名の順に探し、どちらもない場合は接続元のアドレスとする
+func (m *Monitor) poolFor(r *http.Request) Pool {
+	audited, ok := m.pool.(*AuditedPool)
+	if !ok {
+		return m.pool
+	}
+	actor := r.Header.Get("X-Actor")
+	if actor == "" {
+		actor, _, _ = r.BasicAuth()
+	}
+	if actor == "" {
+		actor = "api"
+	}
+	return audited.As(actor + " (" + r.RemoteAddr + ")")
+}
+
+// handleAudit は GET /audit で設定変更の監査ログを古い順に返す（?since={id} でそれより後の変更のみ）
+func (m *Monitor) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+	audited := auditedPool(m.pool)
+	if audited == nil {
+		writeJSONError(w, http.StatusNotImplemented, "監査ログが設定されていません（NewAuditedPool で重ねてください）")
+		return
+	}
+
+	var since uint64
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "since が不正です: "+value)
+			return
+		}
+		since = parsed
+	}
+	changes := audited.log.List(since)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":   len(changes),
+		"changes": changes,
+	})
+}
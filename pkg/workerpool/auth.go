@@ -0,0 +1,47 @@
+package workerpool
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// SetAdminToken は管理系エンドポイント（タスク投入API、APIエクスプローラ）の認証トークンを設定する
+// 空文字の場合は認証なし。Authorization: Bearer <token> または
+// Basic認証のパスワード（ブラウザから開く場合）で指定する
+func (m *Monitor) SetAdminToken(token string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.adminToken = token
+}
+
+// requireAdmin は管理用トークンを検証してからハンドラを呼び出す
+func (m *Monitor) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mutex.RLock()
+		token := m.adminToken
+		m.mutex.RUnlock()
+
+		if token != "" && !validAdminToken(r, token) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="workerpool admin"`)
+			writeJSONError(w, http.StatusUnauthorized, "認証が必要です")
+			return
+		}
+		if m.rejectReadOnly(w, r) {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// validAdminToken はリクエストのBearerトークンまたはBasic認証のパスワードを検証する
+func validAdminToken(r *http.Request, token string) bool {
+	var given string
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		given = strings.TrimPrefix(auth, "Bearer ")
+	} else if _, password, ok := r.BasicAuth(); ok {
+		given = password
+	}
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
@@ -0,0 +1,370 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// バックフィルのエラー
+var (
+	ErrInvalidBackfill = errors.New("バックフィルの設定が不正です")
+	ErrBackfillAborted = errors.New("バックフィル中止: 失敗が上限に達しました")
+)
+
+// defaultBackfillConcurrency は同時に実行する日付の既定数
+const defaultBackfillConcurrency = 4
+
+// backfillDateFormat はペイロード・ラベル・タスク名に使う日付の形式
+const backfillDateFormat = "2006-01-02"
+
+// BackfillStep は日付を進める単位
+type BackfillStep string
+
+const (
+	BackfillDaily   BackfillStep = "day"
+	BackfillWeekly  BackfillStep = "week"
+	BackfillMonthly BackfillStep = "month"
+)
+
+// TaskExecutor はタスクを実行して最終結果を返す（Pool のほか、リモートのプールへの投入も実装できる）
+type TaskExecutor interface {
+	Execute(ctx context.Context, task Task) (TaskResult, error)
+}
+
+// BackfillConfig は過去の期間を日付ごとに再実行する設定
+// 各タスクのペイロードは {"date": "YYYY-MM-DD", ...Params} となり、ラベル backfill_date に日付を付ける。
+// Template を指定した場合は、変数 date と Params を埋め込んでテンプレートからタスクを作成する
+type BackfillConfig struct {
+	Type        TaskType               `json:"type"`         // 投入するタスクのタイプ（Template を指定した場合は不要）
+	Template    *TaskTemplate          `json:"template"`     // タスクを作成するテンプレート
+	From        time.Time              `json:"from"`         // 開始日（この日を含む）
+	To          time.Time              `json:"to"`           // 終了日（この日を含む）
+	Step        BackfillStep           `json:"step"`         // 日付の単位（空の場合は day）
+	Calendar    Calendar               `json:"-"`            // 指定した場合は営業日のみ対象にする
+	FirstID     int                    `json:"first_id"`     // 最初のタスクID（以降は連番）
+	Name        string                 `json:"name"`         // タスク名（{date} を日付に置き換える、空の場合は <type>-{date}）
+	Params      map[string]interface{} `json:"params"`       // ペイロードに追加する値
+	Labels      map[string]string      `json:"labels"`       // タスクに付けるラベル
+	Priority    Priority               `json:"priority"`     // 0 の場合はテンプレートの既定値（テンプレートがなければ通常）
+	Timeout     time.Duration          `json:"timeout_ns"`   // 0 の場合はテンプレートの既定値（テンプレートがなければプールの設定）
+	MaxRetries  int                    `json:"max_retries"`  // 0 の場合はテンプレートの既定値（テンプレートがなければタイプのポリシー）
+	Concurrency int                    `json:"concurrency"`  // 同時に実行する日付の数（0 の場合は4）
+	MaxFailures int                    `json:"max_failures"` // この件数を失敗したら残りを中止する（0 で中止しない）
+}
+
+// BackfillFailure は失敗した日付
+type BackfillFailure struct {
+	Date   string `json:"date"`
+	TaskID int    `json:"task_id"`
+	Error  string `json:"error"`
+}
+
+// BackfillProgress はバックフィル全体の進捗
+type BackfillProgress struct {
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Running   int               `json:"running"`
+	Pending   int               `json:"pending"`
+	Skipped   int               `json:"skipped"` // 中止・キャンセルで実行しなかった日付
+	Elapsed   time.Duration     `json:"elapsed_ns"`
+	ETA       time.Duration     `json:"eta_ns"` // 完了までの見込み（実行中でない場合は0）
+	Failures  []BackfillFailure `json:"failures,omitempty"`
+}
+
+// Done は終了した日付の数を返す
+func (p BackfillProgress) Done() int {
+	return p.Succeeded + p.Failed
+}
+
+// Percent は終了した日付の割合（0〜100）を返す
+func (p BackfillProgress) Percent() float64 {
+	if p.Total == 0 {
+		return 100
+	}
+	return float64(p.Done()) / float64(p.Total) * 100
+}
+
+// Backfill は日付ごとのタスクを同時実行数を制限して実行し、全体の進捗を追跡する
+type Backfill struct {
+	executor TaskExecutor
+	config   BackfillConfig
+	tasks    []Task
+
+	mu       sync.Mutex
+	started  time.Time
+	finished time.Time
+	progress BackfillProgress
+}
+
+// NewBackfill は期間内の日付ごとのタスクを生成する
+func NewBackfill(executor TaskExecutor, config BackfillConfig) (*Backfill, error) {
+	if config.Template != nil {
+		if err := config.Template.Validate(); err != nil {
+			return nil, err
+		}
+		if config.Type != "" && config.Type != config.Template.Type {
+			return nil, fmt.Errorf("%w: type %s はテンプレート %s のタイプ %s と異なります", ErrInvalidBackfill, config.Type, config.Template.Name, config.Template.Type)
+		}
+		config.Type = config.Template.Type
+	}
+	if config.Type == "" {
+		return nil, fmt.Errorf("%w: type を指定してください", ErrInvalidBackfill)
+	}
+	if config.FirstID <= 0 {
+		return nil, fmt.Errorf("%w: first_id は正の整数で指定してください", ErrInvalidBackfill)
+	}
+	if config.From.IsZero() || config.To.IsZero() || config.To.Before(config.From) {
+		return nil, fmt.Errorf("%w: from と to を from <= to となるよう指定してください", ErrInvalidBackfill)
+	}
+	if config.Timeout < 0 {
+		return nil, fmt.Errorf("%w: timeout に負の値は指定できません", ErrInvalidBackfill)
+	}
+	if config.Step == "" {
+		config.Step = BackfillDaily
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaultBackfillConcurrency
+	}
+	if config.Name == "" && config.Template == nil {
+		config.Name = string(config.Type) + "-{date}"
+	}
+
+	dates, err := BackfillDates(config.From, config.To, config.Step, config.Calendar)
+	if err != nil {
+		return nil, err
+	}
+	b := &Backfill{executor: executor, config: config}
+	for i, date := range dates {
+		task, err := b.newTask(config.FirstID+i, date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBackfill, date.Format(backfillDateFormat), err)
+		}
+		b.tasks = append(b.tasks, task)
+	}
+	b.progress = BackfillProgress{Total: len(b.tasks), Pending: len(b.tasks)}
+	return b, nil
+}
+
+// BackfillDates は from から to まで（両端を含む）の日付を step ごとに返す
+// 月単位の場合、存在しない日は翌月に繰り越さず月末日にする（1/31 から始めると 1/31, 2/28, 3/31）
+func BackfillDates(from, to time.Time, step BackfillStep, calendar Calendar) ([]time.Time, error) {
+	from = truncateDay(from)
+	to = truncateDay(to)
+
+	var dates []time.Time
+	for i := 0; ; i++ {
+		var date time.Time
+		switch step {
+		case BackfillDaily:
+			date = from.AddDate(0, 0, i)
+		case BackfillWeekly:
+			date = from.AddDate(0, 0, 7*i)
+		case BackfillMonthly:
+			date = addMonthsClamped(from, i)
+		default:
+			return nil, fmt.Errorf("%w: step %q は day, week, month のいずれかで指定してください", ErrInvalidBackfill, step)
+		}
+		if date.After(to) {
+			return dates, nil
+		}
+		if calendar == nil || calendar.IsBusinessDay(date) {
+			dates = append(dates, date)
+		}
+	}
+}
+
+// truncateDay は日時をそのタイムゾーンの0時にする
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// addMonthsClamped は months か月後の日付を返す（存在しない日は月末日にする）
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, last)-1)
+}
+
+// newTask は日付のタスクを作成する
+func (b *Backfill) newTask(id int, date time.Time) (Task, error) {
+	day := date.Format(backfillDateFormat)
+	var task Task
+	if b.config.Template != nil {
+		vars := map[string]interface{}{"date": day}
+		for key, value := range b.config.Params {
+			vars[key] = value
+		}
+		var err error
+		if task, err = b.config.Template.Instantiate(id, vars); err != nil {
+			return Task{}, err
+		}
+	} else {
+		payload := map[string]interface{}{"date": day}
+		for key, value := range b.config.Params {
+			payload[key] = value
+		}
+		task = Task{ID: id, Type: b.config.Type, Payload: payload}
+	}
+
+	if b.config.Name != "" {
+		task.Name = strings.ReplaceAll(b.config.Name, "{date}", day)
+	}
+	if task.Labels == nil {
+		task.Labels = make(map[string]string)
+	}
+	for key, value := range b.config.Labels {
+		task.Labels[key] = value
+	}
+	task.Labels["backfill_date"] = day
+	if b.config.Priority != 0 {
+		task.Priority = b.config.Priority
+	}
+	if b.config.Timeout > 0 {
+		task.Timeout = b.config.Timeout
+	}
+	if b.config.MaxRetries != 0 {
+		task.MaxRetries = b.config.MaxRetries
+	}
+	return task, nil
+}
+
+// Tasks は生成したタスクを日付の順に返す（実行前の確認用）
+func (b *Backfill) Tasks() []Task {
+	return append([]Task(nil), b.tasks...)
+}
+
+// Progress は現在の進捗を返す
+func (b *Backfill) Progress() BackfillProgress {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	progress := b.progress
+	progress.Failures = append([]BackfillFailure(nil), b.progress.Failures...)
+	switch {
+	case !b.finished.IsZero():
+		progress.Elapsed = b.finished.Sub(b.started)
+	case !b.started.IsZero():
+		progress.Elapsed = time.Since(b.started)
+		if done := progress.Done(); done > 0 {
+			remaining := progress.Total - done - progress.Skipped
+			progress.ETA = time.Duration(float64(progress.Elapsed) / float64(done) * float64(remaining))
+		}
+	}
+	return progress
+}
+
+// Run はタスクを同時実行数を制限して実行し、すべて終わるまで待つ
+// ctx のキャンセル、または失敗が MaxFailures に達した場合は残りの日付を実行せずに戻る（実行中のタスクはキャンセルする）
+func (b *Backfill) Run(ctx context.Context) (BackfillProgress, error) {
+	b.mu.Lock()
+	if !b.started.IsZero() {
+		b.mu.Unlock()
+		return b.Progress(), fmt.Errorf("%w: バックフィルは既に実行されています", ErrInvalidBackfill)
+	}
+	b.started = time.Now()
+	b.mu.Unlock()
+
+	event("backfill.started").logf("⏪ バックフィルを開始します: %s %s〜%s (%d 件, 同時実行 %d)\n",
+		b.config.Type, b.config.From.Format(backfillDateFormat), b.config.To.Format(backfillDateFormat), len(b.tasks), b.config.Concurrency)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var aborted bool
+	tasks := make(chan Task)
+	var wg sync.WaitGroup
+	for i := 0; i < b.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range tasks {
+				if b.execute(ctx, task) {
+					b.mu.Lock()
+					aborted = true
+					b.mu.Unlock()
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, task := range b.tasks {
+		select {
+		case tasks <- task:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(tasks)
+	wg.Wait()
+
+	b.mu.Lock()
+	b.finished = time.Now()
+	b.progress.Skipped += b.progress.Pending
+	b.progress.Pending = 0
+	b.mu.Unlock()
+
+	progress := b.Progress()
+	var err error
+	switch {
+	case aborted:
+		err = ErrBackfillAborted
+	case ctx.Err() != nil:
+		err = ctx.Err()
+	}
+	if err != nil {
+		event("backfill.aborted").failed(err).logf("🛑 バックフィルを中止しました (成功: %d, 失敗: %d, 未実行: %d): %v\n",
+			progress.Succeeded, progress.Failed, progress.Skipped, err)
+		return progress, err
+	}
+	event("backfill.completed").logf("✅ バックフィルが完了しました (成功: %d, 失敗: %d, 所要時間: %v)\n",
+		progress.Succeeded, progress.Failed, progress.Elapsed.Round(time.Millisecond))
+	return progress, nil
+}
+
+// execute は1日分のタスクを実行して進捗に反映し、失敗の上限に達したかを返す
+func (b *Backfill) execute(ctx context.Context, task Task) bool {
+	if ctx.Err() != nil {
+		return false // 中止後に受け取った日付は未実行として数える
+	}
+
+	b.mu.Lock()
+	b.progress.Pending--
+	b.progress.Running++
+	b.mu.Unlock()
+
+	result, err := b.executor.Execute(ctx, task)
+	if err == nil && !result.Success {
+		err = result.Error
+		if err == nil {
+			err = errors.New("タスクが失敗しました")
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.progress.Running--
+	if err == nil {
+		b.progress.Succeeded++
+		return false
+	}
+	if ctx.Err() != nil {
+		// 中止・キャンセルで打ち切られたタスクは失敗ではなく未実行とする
+		b.progress.Skipped++
+		return false
+	}
+	date := task.Labels["backfill_date"]
+	b.progress.Failed++
+	b.progress.Failures = append(b.progress.Failures, BackfillFailure{Date: date, TaskID: task.ID, Error: err.Error()})
+	event("backfill.task_failed").taskOf(task).failed(err).logf("❌ バックフィル %s (タスク %d) が失敗しました: %v\n", date, task.ID, err)
+	return b.config.MaxFailures > 0 && b.progress.Failed >= b.config.MaxFailures
+}
@@ -0,0 +1,398 @@
+package workerpool
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBlackoutRefresh はブラックアウト期間を再取得する既定の間隔
+const defaultBlackoutRefresh = 5 * time.Minute
+
+// ErrInvalidICal はiCalendarの形式が不正な場合のエラー
+var ErrInvalidICal = errors.New("iCalendarの形式が不正です")
+
+// Blackout はタスクを実行しない期間（外部で管理される変更凍結など）
+type Blackout struct {
+	Name      string     `json:"name"`
+	TaskTypes []TaskType `json:"task_types,omitempty"` // 対象のタスクタイプ（空の場合はすべて）
+	Start     time.Time  `json:"start"`
+	End       time.Time  `json:"end"`
+}
+
+// activeFor は now の時点でタスクタイプが期間に含まれるか判定
+func (b Blackout) activeFor(taskType TaskType, now time.Time) bool {
+	if now.Before(b.Start) || !now.Before(b.End) {
+		return false
+	}
+	if len(b.TaskTypes) == 0 {
+		return true
+	}
+	for _, t := range b.TaskTypes {
+		if t == taskType {
+			return true
+		}
+	}
+	return false
+}
+
+// BlackoutProvider はブラックアウト期間の取得元
+type BlackoutProvider interface {
+	Blackouts(ctx context.Context) ([]Blackout, error)
+}
+
+// StaticBlackouts は設定で固定したブラックアウト期間
+type StaticBlackouts []Blackout
+
+// Blackouts は設定された期間をそのまま返す
+func (s StaticBlackouts) Blackouts(ctx context.Context) ([]Blackout, error) {
+	return s, nil
+}
+
+// ICalBlackouts はiCalendar（.ics）のURLから予定をブラックアウト期間として取得する
+// 予定の CATEGORIES をタスクタイプとして扱い、ない場合は TaskTypes を使う。繰り返し（RRULE）には対応しない
+type ICalBlackouts struct {
+	URL       string       `json:"url"`
+	TaskTypes []TaskType   `json:"task_types,omitempty"` // CATEGORIES のない予定の対象（空の場合はすべて）
+	Client    *http.Client `json:"-"`                    // nil の場合は http.DefaultClient
+}
+
+// Blackouts はURLからiCalendarを取得して予定を返す
+func (ic *ICalBlackouts) Blackouts(ctx context.Context) ([]Blackout, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ic.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("iCalendar %s: %w", ic.URL, err)
+	}
+	client := ic.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("iCalendar %s の取得に失敗しました: %w", ic.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("iCalendar %s の取得に失敗しました: ステータス %d", ic.URL, resp.StatusCode)
+	}
+	return ParseICal(resp.Body, ic.TaskTypes)
+}
+
+// ParseICal はiCalendarの予定（VEVENT）をブラックアウト期間に変換する
+// CATEGORIES のない予定は taskTypes を対象とする
+func ParseICal(r io.Reader, taskTypes []TaskType) ([]Blackout, error) {
+	lines, err := unfoldICal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var blackouts []Blackout
+	var event map[string]icalProperty
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			event = make(map[string]icalProperty)
+		case line == "END:VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("%w: BEGIN:VEVENT のない END:VEVENT があります", ErrInvalidICal)
+			}
+			blackout, err := icalEventToBlackout(event, taskTypes)
+			if err != nil {
+				return nil, err
+			}
+			blackouts = append(blackouts, blackout)
+			event = nil
+		case event != nil:
+			prop := parseICalProperty(line)
+			existing, exists := event[prop.name]
+			switch {
+			case !exists:
+				event[prop.name] = prop
+			case prop.name == "CATEGORIES":
+				// CATEGORIES は複数行に分けて書ける
+				existing.value += "," + prop.value
+				event[prop.name] = existing
+			}
+		}
+	}
+	return blackouts, nil
+}
+
+// icalProperty はiCalendarの1行分のプロパティ
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// unfoldICal は折り返された行（先頭が空白）を連結して返す
+func unfoldICal(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidICal, err)
+	}
+	return lines, nil
+}
+
+// parseICalProperty は NAME;PARAM=VALUE:値 の形式を分解する
+// パラメータの値は引用符で囲めば : を含められる（ALTREP="https://..." など）
+func parseICalProperty(line string) icalProperty {
+	head, value := line, ""
+	quoted := false
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			head, value = line[:i], line[i+1:]
+			break
+		}
+	}
+	parts := strings.Split(head, ";")
+	prop := icalProperty{name: strings.ToUpper(parts[0]), params: make(map[string]string), value: value}
+	for _, param := range parts[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+	return prop
+}
+
+// icalEventToBlackout は予定をブラックアウト期間に変換する
+func icalEventToBlackout(event map[string]icalProperty, taskTypes []TaskType) (Blackout, error) {
+	dtstart, exists := event["DTSTART"]
+	if !exists {
+		return Blackout{}, fmt.Errorf("%w: DTSTART のない予定があります", ErrInvalidICal)
+	}
+	start, allDay, err := parseICalTime(dtstart)
+	if err != nil {
+		return Blackout{}, err
+	}
+
+	end := start
+	if allDay {
+		end = start.AddDate(0, 0, 1) // 終日の予定は DTEND がなければ1日
+	}
+	if dtend, exists := event["DTEND"]; exists {
+		if end, _, err = parseICalTime(dtend); err != nil {
+			return Blackout{}, err
+		}
+	}
+
+	blackout := Blackout{
+		Name:      unescapeICal(event["SUMMARY"].value),
+		TaskTypes: taskTypes,
+		Start:     start,
+		End:       end,
+	}
+	if categories, exists := event["CATEGORIES"]; exists && categories.value != "" {
+		blackout.TaskTypes = nil
+		for _, category := range strings.Split(categories.value, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				blackout.TaskTypes = append(blackout.TaskTypes, TaskType(category))
+			}
+		}
+	}
+	return blackout, nil
+}
+
+// parseICalTime は DTSTART / DTEND の値を解釈する（終日の場合は allDay が true）
+func parseICalTime(prop icalProperty) (t time.Time, allDay bool, err error) {
+	location := time.Local
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if location, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: タイムゾーン %s: %v", ErrInvalidICal, tzid, err)
+		}
+	}
+
+	switch {
+	case prop.params["VALUE"] == "DATE" || len(prop.value) == len("20060102"):
+		t, err = time.ParseInLocation("20060102", prop.value, location)
+		allDay = true
+	case strings.HasSuffix(prop.value, "Z"):
+		t, err = time.Parse("20060102T150405Z", prop.value)
+	default:
+		t, err = time.ParseInLocation("20060102T150405", prop.value, location)
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: 日時 %q: %v", ErrInvalidICal, prop.value, err)
+	}
+	return t, allDay, nil
+}
+
+// unescapeICal はテキスト値のエスケープを戻す
+func unescapeICal(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+// BlackoutCalendar は複数の取得元のブラックアウト期間を定期的に取得して保持する
+// スケジューラとワーカープールが参照し、期間中の対象タイプのタスクを止める
+type BlackoutCalendar struct {
+	providers []BlackoutProvider
+	refresh   time.Duration
+
+	mu        sync.Mutex
+	blackouts []Blackout
+	lastErr   error
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewBlackoutCalendar はブラックアウト期間の取得元をまとめる（refresh が0以下の場合は5分間隔）
+func NewBlackoutCalendar(refresh time.Duration, providers ...BlackoutProvider) *BlackoutCalendar {
+	if refresh <= 0 {
+		refresh = defaultBlackoutRefresh
+	}
+	return &BlackoutCalendar{
+		providers: providers,
+		refresh:   refresh,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Refresh はすべての取得元から期間を取得し直す
+// 取得に失敗した場合は直前に取得できた期間を使い続け、エラーを返す
+func (bc *BlackoutCalendar) Refresh(ctx context.Context) error {
+	var blackouts []Blackout
+	var errs []error
+	for _, provider := range bc.providers {
+		list, err := provider.Blackouts(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		blackouts = append(blackouts, list...)
+	}
+	err := errors.Join(errs...)
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	bc.lastErr = err
+	if err != nil {
+		return err
+	}
+	bc.blackouts = blackouts
+	return nil
+}
+
+// Start は初回の取得を行い、以降は定期的に取得し直す
+func (bc *BlackoutCalendar) Start() {
+	if err := bc.Refresh(context.Background()); err != nil {
+		event("blackout.refresh_failed").failed(err).logf("⚠️ ブラックアウト期間の取得に失敗しました: %v\n", err)
+	}
+
+	bc.wg.Add(1)
+	go func() {
+		defer bc.wg.Done()
+
+		ticker := time.NewTicker(bc.refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := bc.Refresh(context.Background()); err != nil {
+					event("blackout.refresh_failed").failed(err).logf("⚠️ ブラックアウト期間の取得に失敗しました（前回の期間を使用します）: %v\n", err)
+				}
+			case <-bc.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop は定期的な取得を停止する
+func (bc *BlackoutCalendar) Stop() {
+	close(bc.stopCh)
+	bc.wg.Wait()
+}
+
+// Blackouts は保持しているブラックアウト期間を返す
+func (bc *BlackoutCalendar) Blackouts() []Blackout {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	return append([]Blackout(nil), bc.blackouts...)
+}
+
+// LastError は直近の取得エラーを返す（成功している場合は nil）
+func (bc *BlackoutCalendar) LastError() error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	return bc.lastErr
+}
+
+// Active はタスクタイプが now の時点でブラックアウト期間中であれば、該当する期間を返す
+// 重なる期間がある場合は最も遅く終わるものを返す
+func (bc *BlackoutCalendar) Active(taskType TaskType, now time.Time) (Blackout, bool) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	var found Blackout
+	active := false
+	for _, blackout := range bc.blackouts {
+		if blackout.activeFor(taskType, now) && (!active || blackout.End.After(found.End)) {
+			found = blackout
+			active = true
+		}
+	}
+	return found, active
+}
+
+// SetBlackoutCalendar はワーカープールが参照するブラックアウト期間を設定する（nil で解除）
+// 期間中の対象タイプのタスクはメンテナンスウィンドウと同様に保留され、期間が終わるとキューに戻る
+func (wp *WorkerPool) SetBlackoutCalendar(calendar *BlackoutCalendar) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.blackouts = calendar
+}
+
+// SetBlackoutCalendar はすべてのサブプールにブラックアウト期間を設定する
+func (tp *TypedPool) SetBlackoutCalendar(calendar *BlackoutCalendar) {
+	for _, pool := range tp.subPools() {
+		pool.SetBlackoutCalendar(calendar)
+	}
+	tp.mu.Lock()
+	tp.blackouts = calendar
+	tp.mu.Unlock()
+}
+
+// SetBlackoutCalendar はスケジューラが参照するブラックアウト期間を設定する（nil で解除）
+// 期間中の対象タイプの実行はスキップし、次回の実行日時まで待つ
+func (s *Scheduler) SetBlackoutCalendar(calendar *BlackoutCalendar) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blackouts = calendar
+}
+
+// BlackoutCalendar は設定ファイルのブラックアウト期間とiCalendarをまとめる（どちらもない場合は nil）
+func (c *Config) BlackoutCalendar(refresh time.Duration) *BlackoutCalendar {
+	var providers []BlackoutProvider
+	if len(c.Blackouts) > 0 {
+		providers = append(providers, c.Blackouts)
+	}
+	for _, ical := range c.ICal {
+		providers = append(providers, ical)
+	}
+	if len(providers) == 0 {
+		return nil
+	}
+	return NewBlackoutCalendar(refresh, providers...)
+}
@@ -0,0 +1,167 @@
+package workerpool
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// icalCalendar は VEVENT の行を VCALENDAR で囲んだ CRLF 区切りの iCalendar を作る
+func icalCalendar(lines ...string) string {
+	all := append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, lines...)
+	all = append(all, "END:VCALENDAR")
+	return strings.Join(all, "\r\n") + "\r\n"
+}
+
+func TestParseICal(t *testing.T) {
+	tokyo := loadLocation(t, "Asia/Tokyo")
+	newYork := loadLocation(t, "America/New_York")
+	defaults := []TaskType{"report"}
+
+	tests := []struct {
+		name  string
+		event []string
+		want  Blackout
+	}{
+		{
+			name:  "all day without DTEND",
+			event: []string{"SUMMARY:棚卸し", "DTSTART;VALUE=DATE:20240320"},
+			want: Blackout{Name: "棚卸し", TaskTypes: defaults,
+				Start: time.Date(2024, 3, 20, 0, 0, 0, 0, time.Local), End: time.Date(2024, 3, 21, 0, 0, 0, 0, time.Local)},
+		},
+		{
+			name:  "all day with DTEND",
+			event: []string{"SUMMARY:連休", "DTSTART;VALUE=DATE:20240503", "DTEND;VALUE=DATE:20240507"},
+			want: Blackout{Name: "連休", TaskTypes: defaults,
+				Start: time.Date(2024, 5, 3, 0, 0, 0, 0, time.Local), End: time.Date(2024, 5, 7, 0, 0, 0, 0, time.Local)},
+		},
+		{
+			name:  "all day without VALUE",
+			event: []string{"SUMMARY:祝日", "DTSTART:20240320"},
+			want: Blackout{Name: "祝日", TaskTypes: defaults,
+				Start: time.Date(2024, 3, 20, 0, 0, 0, 0, time.Local), End: time.Date(2024, 3, 21, 0, 0, 0, 0, time.Local)},
+		},
+		{
+			name:  "all day with TZID",
+			event: []string{"SUMMARY:棚卸し", "DTSTART;VALUE=DATE;TZID=Asia/Tokyo:20240320"},
+			want: Blackout{Name: "棚卸し", TaskTypes: defaults,
+				Start: time.Date(2024, 3, 20, 0, 0, 0, 0, tokyo), End: time.Date(2024, 3, 21, 0, 0, 0, 0, tokyo)},
+		},
+		{
+			name:  "TZID",
+			event: []string{"SUMMARY:リリース", `DTSTART;TZID="America/New_York":20241103T013000`, "DTEND;TZID=America/New_York:20241103T030000"},
+			want: Blackout{Name: "リリース", TaskTypes: defaults,
+				Start: time.Date(2024, 11, 3, 1, 30, 0, 0, newYork), End: time.Date(2024, 11, 3, 3, 0, 0, 0, newYork)},
+		},
+		{
+			name:  "UTC",
+			event: []string{"SUMMARY:メンテナンス", "DTSTART:20240320T150000Z", "DTEND:20240320T170000Z"},
+			want: Blackout{Name: "メンテナンス", TaskTypes: defaults,
+				Start: time.Date(2024, 3, 20, 15, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 20, 17, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "UTC ignores TZID",
+			event: []string{"SUMMARY:メンテナンス", "DTSTART;TZID=Asia/Tokyo:20240320T150000Z", "DTEND:20240320T170000Z"},
+			want: Blackout{Name: "メンテナンス", TaskTypes: defaults,
+				Start: time.Date(2024, 3, 20, 15, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 20, 17, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "floating time without DTEND",
+			event: []string{"SUMMARY:切り替え", "DTSTART:20240320T090000"},
+			want: Blackout{Name: "切り替え", TaskTypes: defaults,
+				Start: time.Date(2024, 3, 20, 9, 0, 0, 0, time.Local), End: time.Date(2024, 3, 20, 9, 0, 0, 0, time.Local)},
+		},
+		{
+			name: "folded lines",
+			event: []string{
+				"SUMMARY:四半期末の変更凍結\\, 経理",
+				" 部門",
+				"DTSTART:20240329T000000Z",
+				"DTEND:2024040",
+				"\t1T000000Z",
+				"CATEGORIES:report,ema",
+				" il",
+			},
+			want: Blackout{Name: "四半期末の変更凍結, 経理部門", TaskTypes: []TaskType{"report", "email"},
+				Start: time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "categories",
+			event: []string{"SUMMARY:凍結", "DTSTART:20240320T150000Z", "DTEND:20240320T170000Z", "CATEGORIES: email , webhook,"},
+			want: Blackout{Name: "凍結", TaskTypes: []TaskType{"email", "webhook"},
+				Start: time.Date(2024, 3, 20, 15, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 20, 17, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "categories on several lines",
+			event: []string{"SUMMARY:凍結", "DTSTART:20240320T150000Z", "DTEND:20240320T170000Z", "CATEGORIES:email", "CATEGORIES:webhook"},
+			want: Blackout{Name: "凍結", TaskTypes: []TaskType{"email", "webhook"},
+				Start: time.Date(2024, 3, 20, 15, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 20, 17, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "quoted parameter with colon",
+			event: []string{`SUMMARY;ALTREP="https://example.com/freeze":凍結`, "DTSTART:20240320T150000Z", "DTEND:20240320T170000Z"},
+			want: Blackout{Name: "凍結", TaskTypes: defaults,
+				Start: time.Date(2024, 3, 20, 15, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 20, 17, 0, 0, 0, time.UTC)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append([]string{"BEGIN:VEVENT", "UID:1"}, tt.event...)
+			lines = append(lines, "END:VEVENT")
+			blackouts, err := ParseICal(strings.NewReader(icalCalendar(lines...)), defaults)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(blackouts) != 1 {
+				t.Fatalf("予定 = %d 件, want 1", len(blackouts))
+			}
+			got := blackouts[0]
+			if got.Name != tt.want.Name || !slices.Equal(got.TaskTypes, tt.want.TaskTypes) {
+				t.Errorf("予定 = %q %v, want %q %v", got.Name, got.TaskTypes, tt.want.Name, tt.want.TaskTypes)
+			}
+			if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) {
+				t.Errorf("期間 = %s - %s, want %s - %s", got.Start, got.End, tt.want.Start, tt.want.End)
+			}
+		})
+	}
+}
+
+func TestParseICalMultipleEvents(t *testing.T) {
+	ics := icalCalendar(
+		"BEGIN:VEVENT", "SUMMARY:1", "DTSTART:20240320T150000Z", "END:VEVENT",
+		"BEGIN:VEVENT", "SUMMARY:2", "DTSTART;VALUE=DATE:20240321",
+		"BEGIN:VALARM", "TRIGGER:-PT15M", "END:VALARM", "END:VEVENT",
+	)
+	blackouts, err := ParseICal(strings.NewReader(ics), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blackouts) != 2 || blackouts[0].Name != "1" || blackouts[1].Name != "2" {
+		t.Fatalf("予定 = %+v", blackouts)
+	}
+	if blackouts[1].TaskTypes != nil {
+		t.Errorf("CATEGORIES のない予定の対象 = %v, want すべて", blackouts[1].TaskTypes)
+	}
+}
+
+func TestParseICalInvalid(t *testing.T) {
+	tests := map[string][]string{
+		"missing DTSTART":      {"BEGIN:VEVENT", "SUMMARY:x", "END:VEVENT"},
+		"END without BEGIN":    {"END:VEVENT"},
+		"bad date time":        {"BEGIN:VEVENT", "DTSTART:20240320T1500", "END:VEVENT"},
+		"bad date":             {"BEGIN:VEVENT", "DTSTART;VALUE=DATE:2024-03-20", "END:VEVENT"},
+		"bad DTEND":            {"BEGIN:VEVENT", "DTSTART:20240320T150000Z", "DTEND:tomorrow", "END:VEVENT"},
+		"unknown time zone":    {"BEGIN:VEVENT", "DTSTART;TZID=Mars/Olympus:20240320T150000", "END:VEVENT"},
+		"bad UTC time":         {"BEGIN:VEVENT", "DTSTART:20241320T150000Z", "END:VEVENT"},
+		"unknown all day TZID": {"BEGIN:VEVENT", "DTSTART;VALUE=DATE;TZID=Nowhere:20240320", "END:VEVENT"},
+	}
+	for name, lines := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseICal(strings.NewReader(icalCalendar(lines...)), nil); !errors.Is(err, ErrInvalidICal) {
+				t.Errorf("error = %v, want ErrInvalidICal", err)
+			}
+		})
+	}
+}
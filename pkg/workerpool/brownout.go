@@ -0,0 +1,236 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrBrownout はブラウンアウト中のため任意のタスクタイプの受付を止めている場合のエラー
+	ErrBrownout = errors.New("ブラウンアウト中のため受付を停止しています")
+	// ErrInvalidBrownoutPolicy はブラウンアウトの設定が不正な場合のエラー
+	ErrInvalidBrownoutPolicy = errors.New("ブラウンアウトの設定が不正です")
+)
+
+// defaultBrownoutSustain は過負荷・回復と判定するまでに状態が続く必要がある既定の時間
+const defaultBrownoutSustain = 30 * time.Second
+
+// BrownoutMode はブラウンアウト中にプールに適用する制限
+type BrownoutMode struct {
+	DisabledTypes []TaskType    // 受付を止める任意のタスクタイプ
+	Retry         RetryThrottle // すべてのタイプのリトライの絞り方（MaxRetries と DelayFactor を使う）
+}
+
+// SetBrownout はブラウンアウトの制限を適用する（nil で解除）
+// DisabledTypes のタスクは ErrBrownout で受付を拒否し、リトライは回数を減らして遅延を延ばす。
+// 受付済みのタスクはそのまま実行する
+func (wp *WorkerPool) SetBrownout(mode *BrownoutMode) {
+	if mode == nil {
+		wp.brownout.Store(nil)
+		return
+	}
+	applied := BrownoutMode{
+		DisabledTypes: append([]TaskType(nil), mode.DisabledTypes...),
+		Retry:         mode.Retry.withDefaults(),
+	}
+	wp.brownout.Store(&applied)
+}
+
+// SetBrownout はすべてのサブプールにブラウンアウトの制限を適用する
+func (tp *TypedPool) SetBrownout(mode *BrownoutMode) {
+	for _, pool := range tp.subPools() {
+		pool.SetBrownout(mode)
+	}
+}
+
+// checkBrownout はブラウンアウト中に受付を止めているタイプのタスクであればエラーを返す
+func (wp *WorkerPool) checkBrownout(task Task) error {
+	mode := wp.brownout.Load()
+	if mode == nil || !slices.Contains(mode.DisabledTypes, task.Type) {
+		return nil
+	}
+	atomic.AddInt64(&wp.admissionStats.Rejected, 1)
+	return fmt.Errorf("%w: タスクタイプ %s", ErrBrownout, task.Type)
+}
+
+// brownoutRetry はブラウンアウト中であればリトライを絞ったポリシーを返す
+func (wp *WorkerPool) brownoutRetry(policy RetryPolicy) RetryPolicy {
+	mode := wp.brownout.Load()
+	if mode == nil {
+		return policy
+	}
+	return mode.Retry.tighten(policy)
+}
+
+// brownoutPool はブラウンアウトの制限を適用できるプール
+type brownoutPool interface {
+	SetBrownout(mode *BrownoutMode)
+}
+
+// BrownoutPolicy は過負荷が続いた場合に任意の処理を止めて主要な処理を守る設定（Monitor が判定する）
+// キュー滞留数または稼働率が閾値以上の状態が Sustain 続くとブラウンアウトに入り、
+// 閾値を下回った状態が Cooldown 続くと自動的に元に戻す
+type BrownoutPolicy struct {
+	OptionalTypes    []TaskType    `json:"optional_types"`          // ブラウンアウト中に受付を止める任意のタスクタイプ
+	MaxQueueDepth    int64         `json:"max_queue_depth"`         // キュー滞留数とリトライ待ちの合計の閾値（0で無効）
+	MaxUtilization   float64       `json:"max_utilization_percent"` // ワーカーの稼働率（%）の閾値（0で無効）
+	Sustain          time.Duration `json:"sustain_ns"`              // 過負荷と判定するまでの継続時間（0 の場合は30秒）
+	Cooldown         time.Duration `json:"cooldown_ns"`             // 元に戻すまでの継続時間（0 の場合は Sustain と同じ）
+	RetryMaxRetries  int           `json:"retry_max_retries"`       // ブラウンアウト中の最大リトライ回数（0 の場合は1、負の値はリトライしない）
+	RetryDelayFactor float64       `json:"retry_delay_factor"`      // ブラウンアウト中のリトライの遅延の倍率（0 の場合は4）
+}
+
+// withDefaults は既定値を補った設定を返す
+func (p BrownoutPolicy) withDefaults() BrownoutPolicy {
+	if p.Sustain <= 0 {
+		p.Sustain = defaultBrownoutSustain
+	}
+	if p.Cooldown <= 0 {
+		p.Cooldown = p.Sustain
+	}
+	p.OptionalTypes = append([]TaskType(nil), p.OptionalTypes...)
+	return p
+}
+
+// mode はブラウンアウト中にプールに適用する制限を返す
+func (p BrownoutPolicy) mode() *BrownoutMode {
+	return &BrownoutMode{
+		DisabledTypes: p.OptionalTypes,
+		Retry:         RetryThrottle{MaxRetries: p.RetryMaxRetries, DelayFactor: p.RetryDelayFactor},
+	}
+}
+
+// overloaded は統計が閾値を超えていれば、その理由を返す
+func (p BrownoutPolicy) overloaded(stats PoolStats) (string, bool) {
+	var reasons []string
+	if backlog := stats.QueuedTasks + stats.RetryingTasks; p.MaxQueueDepth > 0 && backlog >= p.MaxQueueDepth {
+		reasons = append(reasons, fmt.Sprintf("キュー滞留 %d 件", backlog))
+	}
+	if p.MaxUtilization > 0 && stats.Utilization >= p.MaxUtilization {
+		reasons = append(reasons, fmt.Sprintf("稼働率 %.0f%%", stats.Utilization))
+	}
+	return strings.Join(reasons, "・"), len(reasons) > 0
+}
+
+// BrownoutStatus はブラウンアウトの状態（GET /stats とダッシュボードで返す）
+type BrownoutStatus struct {
+	Active        bool          `json:"active"`
+	Since         time.Time     `json:"since,omitempty"`  // 現在の状態になった日時
+	Reason        string        `json:"reason,omitempty"` // ブラウンアウトに入った理由
+	DisabledTypes []TaskType    `json:"disabled_types,omitempty"`
+	RetryLimit    int           `json:"retry_max_retries"`  // ブラウンアウト中の最大リトライ回数
+	DelayFactor   float64       `json:"retry_delay_factor"` // ブラウンアウト中のリトライの遅延の倍率
+	Pending       time.Duration `json:"pending_ns"`         // 状態が切り替わる条件を満たしてからの時間（満たしていない場合は0）
+	Activations   int           `json:"activations"`        // ブラウンアウトに入った回数の累計
+}
+
+// brownoutState は Monitor が保持するブラウンアウトの判定の状態
+type brownoutState struct {
+	policy       BrownoutPolicy
+	status       BrownoutStatus
+	triggerSince time.Time // 状態を切り替える条件を満たし始めた日時（満たしていない場合はゼロ値）
+}
+
+// SetBrownoutPolicy は過負荷が続いた場合のブラウンアウトを有効にする（nil で無効化し、ブラウンアウト中であれば解除する）
+func (m *Monitor) SetBrownoutPolicy(policy *BrownoutPolicy) error {
+	if policy != nil && policy.MaxQueueDepth <= 0 && policy.MaxUtilization <= 0 {
+		return fmt.Errorf("%w: max_queue_depth または max_utilization_percent を指定してください", ErrInvalidBrownoutPolicy)
+	}
+
+	m.mutex.Lock()
+	wasActive := m.brownout != nil && m.brownout.status.Active
+	if policy == nil {
+		m.brownout = nil
+		m.stats.Brownout = nil
+	} else {
+		applied := policy.withDefaults()
+		throttle := applied.mode().Retry.withDefaults()
+		m.brownout = &brownoutState{
+			policy: applied,
+			status: BrownoutStatus{Since: time.Now(), RetryLimit: throttle.MaxRetries, DelayFactor: throttle.DelayFactor},
+		}
+		m.stats.Brownout = m.brownout.snapshot()
+	}
+	m.mutex.Unlock()
+
+	if wasActive {
+		if pool, ok := UnwrapPool(m.pool).(brownoutPool); ok {
+			pool.SetBrownout(nil)
+		}
+		event("brownout.exited").logln("🌤️ ブラウンアウトの設定を変更したため、ブラウンアウトを解除しました")
+	}
+	return nil
+}
+
+// snapshot は状態の複製を返す
+func (b *brownoutState) snapshot() *BrownoutStatus {
+	status := b.status
+	status.DisabledTypes = append([]TaskType(nil), status.DisabledTypes...)
+	return &status
+}
+
+// adjustBrownout は直近の統計からブラウンアウトに入る・抜けるかを判定し、プールに反映する
+// プールの呼び出しは m.mutex を保持せずに行う
+func (m *Monitor) adjustBrownout() {
+	pool, ok := UnwrapPool(m.pool).(brownoutPool)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	m.mutex.Lock()
+	state := m.brownout
+	if state == nil {
+		m.mutex.Unlock()
+		return
+	}
+	reason, overloaded := state.policy.overloaded(m.stats)
+	// ブラウンアウト中は回復、そうでなければ過負荷が続いているかを見る
+	triggered, wait := overloaded, state.policy.Sustain
+	if state.status.Active {
+		triggered, wait = !overloaded, state.policy.Cooldown
+	}
+	if !triggered {
+		state.triggerSince = time.Time{}
+	} else if state.triggerSince.IsZero() {
+		state.triggerSince = now
+	}
+	state.status.Pending = 0
+	if triggered {
+		state.status.Pending = now.Sub(state.triggerSince)
+	}
+	switched := triggered && state.status.Pending >= wait
+	if switched {
+		state.status.Active = !state.status.Active
+		state.status.Since = now
+		state.status.Pending = 0
+		state.triggerSince = time.Time{}
+		if state.status.Active {
+			state.status.Reason = reason
+			state.status.DisabledTypes = state.policy.OptionalTypes
+			state.status.Activations++
+		} else {
+			state.status.Reason = ""
+			state.status.DisabledTypes = nil
+		}
+	}
+	active, policy := state.status.Active, state.policy
+	m.stats.Brownout = state.snapshot()
+	m.mutex.Unlock()
+
+	if !switched {
+		return
+	}
+	if active {
+		pool.SetBrownout(policy.mode())
+		event("brownout.entered").logf("🟤 過負荷（%s）が %v 続いたためブラウンアウトに入りました（停止するタイプ: %v）\n",
+			reason, policy.Sustain, policy.OptionalTypes)
+		return
+	}
+	pool.SetBrownout(nil)
+	event("brownout.exited").logf("🌤️ 負荷が %v 下がった状態が続いたため、ブラウンアウトを解除しました\n", policy.Cooldown)
+}
@@ -0,0 +1,123 @@
+package workerpool
+
+import (
+	"fmt"
+	"time"
+)
+
+// 時間の内訳の区間の種類
+const (
+	BudgetPhaseQueue     = "queue"      // 作成から最初の試行まで
+	BudgetPhaseAttempt   = "attempt"    // 試行（プロセッサの実行）
+	BudgetPhaseRetryWait = "retry_wait" // 試行の終了から次の試行まで（バックオフ・再投入後のキュー待ち）
+)
+
+// attemptSpan は1回の試行の開始と終了
+type attemptSpan struct {
+	start, end time.Time
+}
+
+// BudgetPhase は時間の内訳の1区間
+type BudgetPhase struct {
+	Phase    string        `json:"phase"`             // queue / attempt / retry_wait
+	Attempt  int           `json:"attempt,omitempty"` // 何回目の試行（の前の待ち）か（1始まり）
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Name は区間の名前（queue, attempt1, retry_wait1 など）を返す
+func (p BudgetPhase) Name() string {
+	if p.Attempt == 0 {
+		return p.Phase
+	}
+	return fmt.Sprintf("%s%d", p.Phase, p.Attempt)
+}
+
+// LatencyBudget はタスクに与えられた時間（期限・タイムアウト）と、実際に使った時間の内訳
+// 期限を超えたタスクは BlownAt で、キュー待ち・何回目の試行・リトライ待ちのどこで超えたかがわかる
+type LatencyBudget struct {
+	Deadline       time.Time     `json:"deadline,omitempty"`     // 呼び出し元の期限（ゼロ値で無制限）
+	Budget         time.Duration `json:"budget_ns,omitempty"`    // 作成から期限までの時間（期限がない場合は0）
+	AttemptTimeout time.Duration `json:"attempt_timeout_ns"`     // 1回の試行のタイムアウト
+	Consumed       time.Duration `json:"consumed_ns"`            // 作成から結果までに使った時間
+	Queue          time.Duration `json:"queue_ns"`               // 最初の試行までのキュー待ち
+	Processing     time.Duration `json:"processing_ns"`          // 試行の合計
+	RetryWait      time.Duration `json:"retry_wait_ns"`          // 試行と試行の間の待ちの合計
+	Phases         []BudgetPhase `json:"phases"`                 // 時系列の内訳
+	Exceeded       bool          `json:"exceeded"`               // 期限を超えた
+	BlownAt        string        `json:"blown_at,omitempty"`     // 期限を超えた区間（queue, attempt2 など）
+	Remaining      time.Duration `json:"remaining_ns,omitempty"` // 結果の時点で残っていた時間（超えた場合は負の値）
+}
+
+// recordAttempt は試行の開始と終了を記録する（上限を超えると古いものから破棄）
+func (t *Task) recordAttempt(start, end time.Time) {
+	attempts := append(t.attempts[:len(t.attempts):len(t.attempts)], attemptSpan{start: start, end: end})
+	if len(attempts) > maxAttemptHistory {
+		attempts = attempts[len(attempts)-maxAttemptHistory:]
+	}
+	t.attempts = attempts
+}
+
+// latencyBudget は end の時点での時間の内訳を返す
+// 最後の試行の後に end までの時間がある場合（リトライ待ちの取り消しなど）は、その時間を待ちとして数える
+func (t *Task) latencyBudget(attemptTimeout time.Duration, end time.Time) *LatencyBudget {
+	budget := &LatencyBudget{
+		Deadline:       t.Deadline,
+		AttemptTimeout: attemptTimeout,
+	}
+	origin := t.CreatedAt
+	if origin.IsZero() {
+		origin = t.FirstAttempt
+	}
+	if origin.IsZero() {
+		origin = end
+	}
+	budget.Consumed = end.Sub(origin)
+	if !t.Deadline.IsZero() {
+		budget.Budget = t.Deadline.Sub(origin)
+		budget.Remaining = t.Deadline.Sub(end)
+		budget.Exceeded = budget.Remaining < 0
+	}
+
+	// 最後の試行は AttemptCount+1 回目。記録が古いものから破棄されている場合は、残っている最初の試行の番号から数える
+	first := t.AttemptCount + 2 - len(t.attempts)
+	if first < 1 {
+		first = 1
+	}
+	cursor := origin
+	add := func(phase string, attempt int, until time.Time) {
+		if until.Before(cursor) {
+			until = cursor
+		}
+		p := BudgetPhase{Phase: phase, Attempt: attempt, Duration: until.Sub(cursor)}
+		switch phase {
+		case BudgetPhaseQueue:
+			budget.Queue += p.Duration
+		case BudgetPhaseAttempt:
+			budget.Processing += p.Duration
+		case BudgetPhaseRetryWait:
+			budget.RetryWait += p.Duration
+		}
+		if budget.Exceeded && budget.BlownAt == "" && until.After(t.Deadline) {
+			budget.BlownAt = p.Name()
+		}
+		budget.Phases = append(budget.Phases, p)
+		cursor = until
+	}
+	for i, span := range t.attempts {
+		attempt := first + i
+		if i == 0 && attempt == 1 {
+			add(BudgetPhaseQueue, 0, span.start)
+		} else {
+			add(BudgetPhaseRetryWait, attempt, span.start)
+		}
+		add(BudgetPhaseAttempt, attempt, span.end)
+	}
+	if len(t.attempts) == 0 {
+		// 一度も実行されずに結果になった（取り消し・期限切れなど）
+		add(BudgetPhaseQueue, 0, end)
+	} else if end.After(cursor) {
+		// 最後の試行の後に取り消された場合など
+		add(BudgetPhaseRetryWait, first+len(t.attempts), end)
+	}
+	return budget
+}
@@ -0,0 +1,236 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrTaskCanceled は管理操作（CancelTasks・CancelTask）でタスクを取り消した場合のエラー
+var ErrTaskCanceled = errors.New("タスク取り消し: 管理操作により実行を中止しました")
+
+// TaskFilter は一括操作の対象を選ぶ条件（指定した条件をすべて満たすタスクが対象）
+type TaskFilter struct {
+	Types         []TaskType        `json:"types,omitempty"`          // いずれかのタイプに一致
+	Labels        map[string]string `json:"labels,omitempty"`         // すべてのラベルが一致（値 * はキーの存在のみ）
+	CreatedAfter  time.Time         `json:"created_after,omitempty"`  // この日時以降に作成
+	CreatedBefore time.Time         `json:"created_before,omitempty"` // この日時より前に作成
+}
+
+// IsEmpty は条件が1つも指定されていないか判定（すべてのタスクが対象になる）
+func (f TaskFilter) IsEmpty() bool {
+	return len(f.Types) == 0 && len(f.Labels) == 0 && f.CreatedAfter.IsZero() && f.CreatedBefore.IsZero()
+}
+
+// Matches はタスクが条件を満たすか判定
+func (f TaskFilter) Matches(task Task) bool {
+	if len(f.Types) > 0 {
+		found := false
+		for _, taskType := range f.Types {
+			if task.Type == taskType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for key, want := range f.Labels {
+		got, exists := task.Labels[key]
+		if !exists || (want != "*" && got != want) {
+			return false
+		}
+	}
+	if !f.CreatedAfter.IsZero() && task.CreatedAt.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !task.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// CancelTasks は条件に一致するキュー待ち・リトライ待ち・予定時刻待ち・保留中（負荷制御・メンテナンス）のタスクを取り消し、取り消した件数を返す
+// 取り消したタスクはDLQに送り、最終的な失敗（ErrTaskCanceled）として結果を通知する。実行中のタスクは対象外
+func (wp *WorkerPool) CancelTasks(filter TaskFilter) int {
+	canceled := wp.removeWaiting(filter.Matches)
+	for _, task := range canceled {
+		wp.abandon(task, ErrTaskCanceled)
+	}
+	if len(canceled) > 0 {
+		event("bulk.canceled").logf("🚮 %d 件のタスクを取り消し、DLQに送りました\n", len(canceled))
+	}
+	return len(canceled)
+}
+
+// removeWaiting は条件に一致するキュー待ち・リトライ待ち・予定時刻待ち・保留中のタスクを取り除いて返す（実行中のタスクは対象外）
+func (wp *WorkerPool) removeWaiting(match func(Task) bool) []Task {
+	removed := wp.queue.removeWhere(match)
+	for _, lane := range wp.heatTracker().laneQueues() {
+		removed = append(removed, lane.removeWhere(match)...)
+	}
+	for _, queue := range wp.queueRouter().taskQueues() {
+		removed = append(removed, queue.removeWhere(match)...)
+	}
+	for _, task := range removed {
+		wp.trackQueued(task.Type, -1)
+	}
+	removed = append(removed, wp.retries.removeWhere(match)...)
+	removed = append(removed, wp.delayed.removeWhere(match)...)
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	kept := wp.deferred[:0]
+	for _, task := range wp.deferred {
+		if match(task) {
+			removed = append(removed, task)
+		} else {
+			kept = append(kept, task)
+		}
+	}
+	wp.deferred = kept
+	kept = wp.held[:0]
+	for _, task := range wp.held {
+		if match(task) {
+			removed = append(removed, task)
+		} else {
+			kept = append(kept, task)
+		}
+	}
+	wp.held = kept
+	return removed
+}
+
+// RequeueDeadLettersWhere は条件に一致するDLQのエントリをキューに戻し、戻した件数を返す
+func (wp *WorkerPool) RequeueDeadLettersWhere(filter TaskFilter) (int, error) {
+	var ids []uint64
+	for _, entry := range wp.dlq.List() {
+		if filter.Matches(entry.Task) {
+			ids = append(ids, entry.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return wp.RequeueDeadLetters(ids)
+}
+
+// SetPriorityWhere は条件に一致するキュー待ち・リトライ待ち・予定時刻待ち・保留中のタスクの優先度を変更し、変更した件数を返す
+func (wp *WorkerPool) SetPriorityWhere(filter TaskFilter, priority Priority) int {
+	setPriority := func(task *Task) { task.Priority = priority }
+	updated := wp.queue.updateWhere(filter.Matches, setPriority)
+	for _, lane := range wp.heatTracker().laneQueues() {
+		updated += lane.updateWhere(filter.Matches, setPriority)
+	}
+	for _, queue := range wp.queueRouter().taskQueues() {
+		updated += queue.updateWhere(filter.Matches, setPriority)
+	}
+	updated += wp.retries.updateWhere(filter.Matches, setPriority)
+	updated += wp.delayed.updateWhere(filter.Matches, setPriority)
+
+	wp.mu.Lock()
+	for i := range wp.deferred {
+		if filter.Matches(wp.deferred[i]) {
+			wp.deferred[i].Priority = priority
+			updated++
+		}
+	}
+	for i := range wp.held {
+		if filter.Matches(wp.held[i]) {
+			wp.held[i].Priority = priority
+			updated++
+		}
+	}
+	wp.mu.Unlock()
+
+	if updated > 0 {
+		event("bulk.priority_changed").logf("🔀 %d 件のタスクの優先度を %d に変更しました\n", updated, priority)
+	}
+	return updated
+}
+
+// CancelTasks はすべてのサブプールで条件に一致するタスクを取り消す
+func (tp *TypedPool) CancelTasks(filter TaskFilter) int {
+	total := 0
+	for _, pool := range tp.subPools() {
+		total += pool.CancelTasks(filter)
+	}
+	return total
+}
+
+// RequeueDeadLettersWhere はすべてのサブプールで条件に一致するDLQのエントリをキューに戻す
+func (tp *TypedPool) RequeueDeadLettersWhere(filter TaskFilter) (int, error) {
+	total := 0
+	for _, pool := range tp.subPools() {
+		n, err := pool.RequeueDeadLettersWhere(filter)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// SetPriorityWhere はすべてのサブプールで条件に一致するタスクの優先度を変更する
+func (tp *TypedPool) SetPriorityWhere(filter TaskFilter, priority Priority) int {
+	total := 0
+	for _, pool := range tp.subPools() {
+		total += pool.SetPriorityWhere(filter, priority)
+	}
+	return total
+}
+
+// bulkRequest は一括操作のリクエスト
+// 条件を指定しない場合はすべてのタスクが対象になるため、誤操作を防ぐよう all: true を必須とする
+type bulkRequest struct {
+	Filter   TaskFilter `json:"filter"`
+	All      bool       `json:"all"`
+	Priority *Priority  `json:"priority"` // /bulk/priority のみ
+}
+
+// handleBulk は一括操作を扱う
+//
+//	POST /bulk/cancel    {"filter": {...}}                キュー待ち・リトライ待ちのタスクを取り消す
+//	POST /bulk/requeue   {"filter": {...}}                DLQのエントリをキューに戻す
+//	POST /bulk/priority  {"filter": {...}, "priority": 1} キュー待ち・リトライ待ちのタスクの優先度を変更する
+func (m *Monitor) handleBulk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "POST のみ対応しています")
+		return
+	}
+
+	var req bulkRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTaskRequestBytes)).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "リクエストの形式が不正です: "+err.Error())
+		return
+	}
+	if req.Filter.IsEmpty() && !req.All {
+		writeJSONError(w, http.StatusBadRequest, "filter を指定するか、すべてを対象にする場合は all: true を指定してください")
+		return
+	}
+
+	switch action := strings.TrimPrefix(r.URL.Path, "/bulk/"); action {
+	case "cancel":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"canceled": m.pool.CancelTasks(req.Filter)})
+	case "requeue":
+		n, err := m.pool.RequeueDeadLettersWhere(req.Filter)
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"requeued": n, "error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"requeued": n})
+	case "priority":
+		if req.Priority == nil {
+			writeJSONError(w, http.StatusBadRequest, "priority を指定してください")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"updated": m.pool.SetPriorityWhere(req.Filter, *req.Priority)})
+	default:
+		writeJSONError(w, http.StatusNotFound, "不明な操作です: "+action)
+	}
+}
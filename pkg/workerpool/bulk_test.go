@@ -0,0 +1,144 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTaskFilterMatches(t *testing.T) {
+	created := time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+	task := Task{ID: 1, Type: "email", Labels: map[string]string{"tenant": "a", "batch": "7"}, CreatedAt: created}
+
+	tests := []struct {
+		name   string
+		filter TaskFilter
+		want   bool
+	}{
+		{"empty", TaskFilter{}, true},
+		{"type", TaskFilter{Types: []TaskType{"report", "email"}}, true},
+		{"other type", TaskFilter{Types: []TaskType{"report"}}, false},
+		{"label", TaskFilter{Labels: map[string]string{"tenant": "a"}}, true},
+		{"all labels", TaskFilter{Labels: map[string]string{"tenant": "a", "batch": "8"}}, false},
+		{"label wildcard", TaskFilter{Labels: map[string]string{"batch": "*"}}, true},
+		{"missing label wildcard", TaskFilter{Labels: map[string]string{"region": "*"}}, false},
+		{"created after is inclusive", TaskFilter{CreatedAfter: created}, true},
+		{"created after", TaskFilter{CreatedAfter: created.Add(time.Second)}, false},
+		{"created before is exclusive", TaskFilter{CreatedBefore: created}, false},
+		{"created before", TaskFilter{CreatedBefore: created.Add(time.Second)}, true},
+		{"type and label", TaskFilter{Types: []TaskType{"email"}, Labels: map[string]string{"tenant": "b"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(task); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// 一括操作はキュー待ちのタスクのうち条件に一致するものだけを取り消し・優先度変更し、DLQから戻す
+func TestBulkOperationsWithFilter(t *testing.T) {
+	pool := NewWorkerPool(1)
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	var mu sync.Mutex
+	var order []int
+	process := func(ctx context.Context, task Task) error {
+		if task.ID == 0 {
+			close(started)
+			<-unblock
+		}
+		mu.Lock()
+		order = append(order, task.ID)
+		mu.Unlock()
+		return nil
+	}
+	pool.RegisterProcessor("email", process)
+	pool.RegisterProcessor("report", process)
+
+	results := make(chan TaskResult, 10)
+	pool.OnResult(func(result TaskResult) {
+		if result.IsFinal {
+			results <- result
+		}
+	})
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Stop()
+
+	// タスク0でワーカーを塞ぎ、残りをキューに溜める
+	if err := pool.AddTask(Task{ID: 0, Type: "email"}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	for _, task := range []Task{
+		{ID: 1, Type: "email", Labels: map[string]string{"tenant": "a"}},
+		{ID: 2, Type: "email", Labels: map[string]string{"tenant": "b"}},
+		{ID: 3, Type: "report", Labels: map[string]string{"tenant": "a"}},
+		{ID: 4, Type: "report"},
+	} {
+		if err := pool.AddTask(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := pool.SetPriorityWhere(TaskFilter{Types: []TaskType{"report"}}, PriorityHigh); got != 2 {
+		t.Fatalf("SetPriorityWhere = %d, want 2", got)
+	}
+	if got := pool.CancelTasks(TaskFilter{Types: []TaskType{"report"}, Labels: map[string]string{"tenant": "b"}}); got != 0 {
+		t.Fatalf("一致しない条件の CancelTasks = %d, want 0", got)
+	}
+	if got := pool.CancelTasks(TaskFilter{Types: []TaskType{"email"}, Labels: map[string]string{"tenant": "a"}}); got != 1 {
+		t.Fatalf("CancelTasks = %d, want 1", got)
+	}
+
+	select {
+	case result := <-results:
+		if result.TaskID != 1 || !errors.Is(result.Error, ErrTaskCanceled) {
+			t.Fatalf("取り消したタスクの結果 = %d %v, want 1 %v", result.TaskID, result.Error, ErrTaskCanceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("取り消したタスクの結果が通知されませんでした")
+	}
+	letters := pool.DeadLetters()
+	if len(letters) != 1 || letters[0].Task.ID != 1 || letters[0].Reason != DeadLetterCanceled {
+		t.Fatalf("DLQ = %+v, want 取り消したタスク1", letters)
+	}
+
+	if got, err := pool.RequeueDeadLettersWhere(TaskFilter{Types: []TaskType{"report"}}); err != nil || got != 0 {
+		t.Fatalf("一致しない条件の RequeueDeadLettersWhere = (%d, %v), want (0, nil)", got, err)
+	}
+	if got, err := pool.RequeueDeadLettersWhere(TaskFilter{Labels: map[string]string{"tenant": "*"}}); err != nil || got != 1 {
+		t.Fatalf("RequeueDeadLettersWhere = (%d, %v), want (1, nil)", got, err)
+	}
+	if got := len(pool.DeadLetters()); got != 0 {
+		t.Fatalf("戻した後の DLQ = %d 件, want 0", got)
+	}
+
+	close(unblock)
+	seen := make(map[int]bool)
+	timeout := time.After(5 * time.Second)
+	for len(seen) < 5 {
+		select {
+		case result := <-results:
+			if result.Error != nil {
+				t.Fatalf("タスク %d が失敗しました: %v", result.TaskID, result.Error)
+			}
+			seen[result.TaskID] = true
+		case <-timeout:
+			t.Fatalf("実行されたタスク = %v", seen)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// 優先度を上げたレポートが先に実行される
+	if len(order) != 5 || order[0] != 0 || !slices.Equal(order[1:3], []int{3, 4}) {
+		t.Errorf("実行順 = %v, want [0 3 4 ...]", order)
+	}
+}
@@ -0,0 +1,107 @@
+package workerpool
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// holidayDateFormat は祝日の日付の形式
+const holidayDateFormat = "2006-01-02"
+
+// Calendar は営業日を判定する
+type Calendar interface {
+	// IsBusinessDay は日付（t のタイムゾーンの暦）が営業日か判定する
+	IsBusinessDay(t time.Time) bool
+}
+
+// BusinessCalendar は週末と祝日を休業日とする営業日カレンダー
+type BusinessCalendar struct {
+	Weekend  []time.Weekday    `json:"weekend,omitempty"`  // 休業日の曜日（空の場合は土日）
+	Holidays map[string]string `json:"holidays,omitempty"` // 祝日（YYYY-MM-DD → 名称）
+}
+
+// NewJapaneseCalendar は土日と指定した祝日を休業日とするカレンダーを作成
+// 祝日は年ごとに変わるため、内閣府の「国民の祝日」CSV などから LoadHolidaysCSV で読み込んで渡す
+func NewJapaneseCalendar(holidays map[string]string) *BusinessCalendar {
+	return &BusinessCalendar{
+		Weekend:  []time.Weekday{time.Saturday, time.Sunday},
+		Holidays: holidays,
+	}
+}
+
+// IsBusinessDay は日付が休業日の曜日・祝日でないか判定
+func (c *BusinessCalendar) IsBusinessDay(t time.Time) bool {
+	weekend := c.Weekend
+	if len(weekend) == 0 {
+		weekend = []time.Weekday{time.Saturday, time.Sunday}
+	}
+	for _, w := range weekend {
+		if t.Weekday() == w {
+			return false
+		}
+	}
+	_, holiday := c.Holidays[t.Format(holidayDateFormat)]
+	return !holiday
+}
+
+// HolidayName は日付が祝日であればその名称を返す
+func (c *BusinessCalendar) HolidayName(t time.Time) (string, bool) {
+	name, holiday := c.Holidays[t.Format(holidayDateFormat)]
+	return name, holiday
+}
+
+// LoadHolidaysCSV は「日付,名称」形式のCSVから祝日を読み込む
+// 日付は YYYY-MM-DD または YYYY/M/D 形式。1行目が見出しの場合は読み飛ばす
+// 内閣府の syukujitsu.csv は Shift_JIS のため、UTF-8 に変換してから読み込むこと
+func LoadHolidaysCSV(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("祝日ファイルの読み込みに失敗しました: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	holidays := make(map[string]string)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("祝日ファイルの形式が不正です: %w", err)
+		}
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		date, err := parseHolidayDate(strings.TrimSpace(record[0]))
+		if err != nil {
+			if line == 1 {
+				continue // 見出し行
+			}
+			return nil, fmt.Errorf("祝日ファイル %d 行目: %w", line, err)
+		}
+		name := ""
+		if len(record) > 1 {
+			name = strings.TrimSpace(record[1])
+		}
+		holidays[date.Format(holidayDateFormat)] = name
+	}
+	return holidays, nil
+}
+
+// parseHolidayDate は祝日の日付を解釈する
+func parseHolidayDate(value string) (time.Time, error) {
+	for _, layout := range []string{holidayDateFormat, "2006/1/2"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("日付 %q は YYYY-MM-DD または YYYY/M/D 形式で指定してください", value)
+}
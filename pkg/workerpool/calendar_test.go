@@ -0,0 +1,95 @@
+package workerpool
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeHolidaysCSV(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "holidays.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadHolidaysCSV(t *testing.T) {
+	path := writeHolidaysCSV(t, "国民の祝日・休日月日,国民の祝日・休日名称\n"+
+		"2024/1/1,元日\n"+
+		"\n"+
+		"2024-02-12, 休日 \n"+
+		"2024/3/20\n")
+
+	holidays, err := LoadHolidaysCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"2024-01-01": "元日", "2024-02-12": "休日", "2024-03-20": ""}
+	if len(holidays) != len(want) {
+		t.Fatalf("祝日 = %v, want %v", holidays, want)
+	}
+	for date, name := range want {
+		if got, ok := holidays[date]; !ok || got != name {
+			t.Errorf("祝日 %s = %q (%v), want %q", date, got, ok, name)
+		}
+	}
+}
+
+func TestLoadHolidaysCSVInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"bad date", "日付,名称\n2024/1/1,元日\n2024/13/1,不正\n", "3 行目"},
+		{"bad first line without header", "2024-01-01,元日\n1月2日,不正\n", "2 行目"},
+		{"unterminated quote", "2024-01-01,\"元日\n", "形式が不正"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadHolidaysCSV(writeHolidaysCSV(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadHolidaysCSV error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadHolidaysCSV(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("存在しないファイルでエラーになりません")
+	}
+}
+
+func TestBusinessCalendar(t *testing.T) {
+	japanese := NewJapaneseCalendar(map[string]string{"2024-03-20": "春分の日"})
+	fridayOff := &BusinessCalendar{Weekend: []time.Weekday{time.Friday}}
+
+	tests := []struct {
+		name     string
+		calendar *BusinessCalendar
+		date     time.Time
+		want     bool
+	}{
+		{"weekday", japanese, time.Date(2024, 3, 19, 12, 0, 0, 0, time.UTC), true},
+		{"holiday", japanese, time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC), false},
+		{"saturday", japanese, time.Date(2024, 3, 23, 12, 0, 0, 0, time.UTC), false},
+		{"sunday", japanese, time.Date(2024, 3, 24, 12, 0, 0, 0, time.UTC), false},
+		{"empty weekend defaults to saturday and sunday", &BusinessCalendar{}, time.Date(2024, 3, 23, 12, 0, 0, 0, time.UTC), false},
+		{"custom weekend", fridayOff, time.Date(2024, 3, 22, 12, 0, 0, 0, time.UTC), false},
+		{"saturday outside custom weekend", fridayOff, time.Date(2024, 3, 23, 12, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.calendar.IsBusinessDay(tt.date); got != tt.want {
+				t.Errorf("IsBusinessDay(%s) = %v, want %v", tt.date.Format(holidayDateFormat), got, tt.want)
+			}
+		})
+	}
+
+	if name, ok := japanese.HolidayName(time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)); !ok || name != "春分の日" {
+		t.Errorf("HolidayName = %q (%v), want 春分の日", name, ok)
+	}
+}
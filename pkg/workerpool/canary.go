@@ -0,0 +1,126 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+// 実行したプロセッサの種別（カナリア設定中のタイプのみ TaskResult.Variant に設定される）
+const (
+	VariantStable = "stable" // 現行のプロセッサ
+	VariantCanary = "canary" // 新しいバージョンのプロセッサ
+)
+
+// ErrNoCanary はカナリアが設定されていない場合のエラー
+var ErrNoCanary = errors.New("カナリアが設定されていません")
+
+// canary はタスクタイプの一部を振り分ける新しいバージョンのプロセッサ
+type canary struct {
+	entry   *processorEntry
+	percent float64 // 振り分ける割合（0〜100）
+}
+
+// SetCanary はタスクタイプの percent% を新しいプロセッサ（カナリア）で実行する
+// 振り分けはタスクIDで決まるため、同じタスクのリトライは同じプロセッサで実行される
+// 結果は TaskResult.Variant で区別され、Monitor で現行版と比較できる
+func (wp *WorkerPool) SetCanary(taskType TaskType, processor TaskProcessor, percent float64) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("カナリアの割合は 0〜100 で指定してください: %v", percent)
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if _, exists := wp.processors[taskType]; !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	if wp.canaries == nil {
+		wp.canaries = make(map[TaskType]*canary)
+	}
+	wp.canaries[taskType] = &canary{entry: &processorEntry{processor: processor}, percent: percent}
+	event("canary.started").logf("🐤 タスクタイプ %s の %.1f%% をカナリアで実行します\n", taskType, percent)
+	return nil
+}
+
+// PromoteCanary はカナリアを現行のプロセッサに昇格し、すべてのタスクをカナリアで実行する
+func (wp *WorkerPool) PromoteCanary(taskType TaskType) error {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	c, exists := wp.canaries[taskType]
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrNoCanary)
+	}
+	// 実行環境とペイロードの型は現行のエントリから引き継ぐ
+	if current, exists := wp.processors[taskType]; exists {
+		c.entry.executor, c.entry.payloadType = current.executor, current.payloadType
+	}
+	wp.processors[taskType] = c.entry
+	delete(wp.canaries, taskType)
+	event("canary.promoted").logf("🐤 タスクタイプ %s のカナリアを昇格しました\n", taskType)
+	return nil
+}
+
+// ClearCanary はカナリアを取り消し、すべてのタスクを現行のプロセッサで実行する（ロールバック）
+func (wp *WorkerPool) ClearCanary(taskType TaskType) error {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if _, exists := wp.canaries[taskType]; !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrNoCanary)
+	}
+	delete(wp.canaries, taskType)
+	event("canary.aborted").logf("🐤 タスクタイプ %s のカナリアを取り消しました\n", taskType)
+	return nil
+}
+
+// selectEntryLocked はタスクを実行するプロセッサと種別を選ぶ（呼び出し側でロックを保持）
+func (wp *WorkerPool) selectEntryLocked(task Task) (*processorEntry, string, bool) {
+	entry, exists := wp.processors[task.Type]
+	if !exists {
+		return nil, "", false
+	}
+	c, hasCanary := wp.canaries[task.Type]
+	if !hasCanary {
+		return entry, "", true
+	}
+	if canaryBucket(task) < c.percent {
+		return c.entry, VariantCanary, true
+	}
+	return entry, VariantStable, true
+}
+
+// canaryBucket はタスクIDから 0〜100 の値を決める（同じタスクは常に同じ値）
+func canaryBucket(task Task) float64 {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s/%d", task.Type, task.ID)
+	return float64(h.Sum32()%10000) / 100
+}
+
+// SetCanary はタイプのサブプールにカナリアを設定する
+func (tp *TypedPool) SetCanary(taskType TaskType, processor TaskProcessor, percent float64) error {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	return pool.SetCanary(taskType, processor, percent)
+}
+
+// PromoteCanary はタイプのサブプールのカナリアを昇格する
+func (tp *TypedPool) PromoteCanary(taskType TaskType) error {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrNoCanary)
+	}
+	return pool.PromoteCanary(taskType)
+}
+
+// ClearCanary はタイプのサブプールのカナリアを取り消す
+func (tp *TypedPool) ClearCanary(taskType TaskType) error {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrNoCanary)
+	}
+	return pool.ClearCanary(taskType)
+}
@@ -0,0 +1,61 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTaskNotFound は指定したタスクがキュー待ち・実行中でない場合のエラー
+var ErrTaskNotFound = errors.New("キュー待ち・実行中のタスクが見つかりません")
+
+// CancelTask はタスクを取り消す
+// キュー待ち・リトライ待ち・予定時刻待ち・保留中のタスクは実行せずに取り除き、
+// 実行中のタスクはプロセッサのコンテキストをキャンセルする（context.Cause は ErrTaskCanceled を返す）。
+// 取り消したタスクはリトライせずDLQに送り、結果は Canceled として通知する。
+// プロセッサがキャンセルを無視して成功した場合は成功のまま扱う
+func (wp *WorkerPool) CancelTask(taskID int) error {
+	waiting := wp.removeWaiting(func(task Task) bool { return task.ID == taskID })
+	for _, task := range waiting {
+		wp.abandon(task, ErrTaskCanceled)
+		event("task.canceled").taskOf(task).logf("🚮 タスク %d を取り消し、DLQに送りました\n", task.ID)
+	}
+	if len(waiting) > 0 {
+		return nil
+	}
+
+	if !wp.cancelInFlight(taskID) {
+		return fmt.Errorf("タスク %d: %w", taskID, ErrTaskNotFound)
+	}
+	event("task.cancel_requested").logf("🛑 実行中のタスク %d のコンテキストをキャンセルしました\n", taskID)
+	return nil
+}
+
+// cancelInFlight は実行中のタスクのコンテキストを ErrTaskCanceled でキャンセルする
+func (wp *WorkerPool) cancelInFlight(taskID int) bool {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	found := false
+	for entry := range wp.inFlight {
+		if entry.task.ID == taskID && !entry.aborted {
+			entry.cancel(ErrTaskCanceled)
+			found = true
+		}
+	}
+	return found
+}
+
+// CancelTask はタスクを持つサブプールでタスクを取り消す
+func (tp *TypedPool) CancelTask(taskID int) error {
+	for _, pool := range tp.subPools() {
+		if err := pool.CancelTask(taskID); !errors.Is(err, ErrTaskNotFound) {
+			return err
+		}
+	}
+	return fmt.Errorf("タスク %d: %w", taskID, ErrTaskNotFound)
+}
+
+// isCanceled は管理操作（CancelTask・CancelTasks・CancelRetry）で取り消したタスクのエラーか判定
+func isCanceled(err error) bool {
+	return errors.Is(err, ErrTaskCanceled) || errors.Is(err, ErrRetryCanceled)
+}
@@ -0,0 +1,138 @@
+package workerpool
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// defaultOverrunGrace はコンテキストの終了後にプロセッサが戻るまでの猶予の既定値
+const defaultOverrunGrace = time.Second
+
+// CheckCancel はコンテキストが終了していればその理由（タイムアウト・CancelTask による取り消しなど）を返す
+// ループの区切りなどで呼び出し、エラーが返ればそのまま返して処理を打ち切ればよい
+func CheckCancel(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return context.Cause(ctx)
+}
+
+// Sleep は d だけ待つ（コンテキストが終了した場合はすぐに戻り、CheckCancel と同じエラーを返す）
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return CheckCancel(ctx)
+	}
+}
+
+// HTTPClient はリクエストをプロセッサのコンテキストで打ち切る HTTP クライアントを返す（base が nil の場合は http.DefaultClient を元にする）
+// リクエスト自身のコンテキストも有効なため、NewRequest で作ったリクエストをそのまま渡せる。
+// レスポンスのボディの読み込みも打ち切られるため、読み終えたら Close すること
+func HTTPClient(ctx context.Context, base *http.Client) *http.Client {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	client := *base
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	client.Transport = &contextTransport{ctx: ctx, base: transport}
+	return &client
+}
+
+// contextTransport はリクエストのコンテキストにプロセッサのコンテキストの終了を伝える
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := CheckCancel(t.ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancelCause(req.Context())
+	stop := context.AfterFunc(t.ctx, func() { cancel(context.Cause(t.ctx)) })
+	release := func() {
+		stop()
+		cancel(nil)
+	}
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody はボディを閉じたときにコンテキストを解放する
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// SetOverrunGrace はコンテキストの終了（タイムアウト・取り消し）後にプロセッサが戻るまでの猶予を設定する
+// 猶予を超えて実行を続けたタスクは警告を出し、PoolSnapshot.OverrunTasks に数える（0 以下の場合は1秒）
+func (wp *WorkerPool) SetOverrunGrace(grace time.Duration) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.overrunGrace = grace
+}
+
+// SetOverrunGrace はすべてのサブプールに猶予を設定する
+func (tp *TypedPool) SetOverrunGrace(grace time.Duration) {
+	for _, pool := range tp.subPools() {
+		pool.SetOverrunGrace(grace)
+	}
+}
+
+// OverrunCount はコンテキストの終了後も猶予を超えて実行を続けたタスクの累計を返す
+func (wp *WorkerPool) OverrunCount() int64 {
+	return atomic.LoadInt64(&wp.overruns)
+}
+
+// watchOverrun はコンテキストが終了した時刻を記録する
+// 返す関数はプロセッサが戻った直後に呼び出し、終了から猶予を超えていれば記録する
+func (wp *WorkerPool) watchOverrun(ctx context.Context, task Task, workerID int) func() {
+	var doneAt atomic.Int64
+	stop := context.AfterFunc(ctx, func() { doneAt.Store(time.Now().UnixNano()) })
+
+	return func() {
+		stop()
+		at := doneAt.Load()
+		if at == 0 {
+			return
+		}
+		wp.mu.Lock()
+		grace := wp.overrunGrace
+		wp.mu.Unlock()
+		if grace <= 0 {
+			grace = defaultOverrunGrace
+		}
+
+		overrun := time.Since(time.Unix(0, at))
+		if overrun <= grace {
+			return
+		}
+		atomic.AddInt64(&wp.overruns, 1)
+		event("task.overrun").taskOf(task).worker(workerID).took(overrun).logf("🐢 ワーカー %d: タスク %d はキャンセルから %v 経ってから戻りました（猶予 %v）\n",
+			workerID, task.ID, overrun.Round(time.Millisecond), grace)
+	}
+}
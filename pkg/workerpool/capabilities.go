@@ -0,0 +1,215 @@
+package workerpool
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// 実行環境の種類（ExecutorInfo.Kind）
+const (
+	ExecutorInProcess  = "in-process" // Go の関数として同じプロセスで実行する（既定）
+	ExecutorExec       = "exec"       // 外部コマンド（NewExecProcessor）
+	ExecutorDocker     = "docker"     // コンテナ（NewDockerProcessor）
+	ExecutorKubernetes = "kubernetes" // Kubernetes の Job（NewKubernetesJobProcessor）
+	ExecutorScript     = "script"     // スクリプト（NewScriptProcessor）
+	ExecutorSimulator  = "simulator"  // シミュレーター（NewSimulatedProcessor）
+)
+
+// ExecutorInfo はタスクタイプのプロセッサが実行される環境
+type ExecutorInfo struct {
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"` // コマンド・イメージなど
+}
+
+// RetryCapability はタスクタイプに適用するリトライポリシー（JSON で返す形）
+type RetryCapability struct {
+	MaxRetries     int            `json:"max_retries"`
+	InitialDelay   time.Duration  `json:"initial_delay_ns"`
+	MaxDelay       time.Duration  `json:"max_delay_ns"`
+	BackoffFactor  float64        `json:"backoff_factor"`
+	Exponential    bool           `json:"exponential"`
+	Jitter         float64        `json:"jitter,omitempty"`
+	JitterStrategy JitterStrategy `json:"jitter_strategy,omitempty"`
+	RetryOn        []string       `json:"retry_on,omitempty"` // リトライ対象のエラー（RetryOn と RetryableErrors の接頭辞）
+
+	ClassBackoff map[ErrorClass]BackoffCapability `json:"class_backoff,omitempty"` // エラーの種類ごとの遅延の曲線
+}
+
+// BackoffCapability はエラーの種類ごとの遅延の曲線（JSON で返す形）
+type BackoffCapability struct {
+	InitialDelay  time.Duration `json:"initial_delay_ns"`
+	MaxDelay      time.Duration `json:"max_delay_ns"`
+	BackoffFactor float64       `json:"backoff_factor"`
+	Exponential   bool          `json:"exponential"`
+}
+
+// TaskCapability はタスクタイプの実行環境と制限
+type TaskCapability struct {
+	TaskType   TaskType        `json:"task_type"`
+	Executor   ExecutorInfo    `json:"executor"`
+	Canary     bool            `json:"canary,omitempty"` // カナリアで一部のタスクを別のプロセッサで実行している
+	Timeout    time.Duration   `json:"timeout_ns"`       // タスクに指定がない場合のタイムアウト
+	Retry      RetryCapability `json:"retry"`
+	MaxWorkers int             `json:"max_workers"` // このタイプを実行できるワーカー数の上限
+	Shared     bool            `json:"shared"`      // ワーカーを他のタイプと共有している（WorkerPool）
+}
+
+// RateLimitCapability はプール全体の投入レートの上限（RateLimitedPool）
+type RateLimitCapability struct {
+	Rate  float64 `json:"rate_per_sec"`
+	Burst int     `json:"burst"`
+}
+
+// Capabilities はプールが処理できるタスクタイプとその実行環境の一覧
+type Capabilities struct {
+	TaskTypes []TaskCapability     `json:"task_types"`
+	RateLimit *RateLimitCapability `json:"rate_limit,omitempty"` // 投入レートを制限していない場合は nil
+}
+
+// capabilityReporter はタスクタイプごとの実行環境を返せるプール
+type capabilityReporter interface {
+	TaskCapabilities() []TaskCapability
+	describeExecutor(taskType TaskType, info ExecutorInfo) error
+}
+
+// DescribeExecutor は登録済みのプロセッサの実行環境を記録する（/capabilities で返す）
+// 記録は ReplaceProcessor・SwapProcessor・PromoteCanary による差し替え後も引き継がれる（登録を解除すると消える）
+func DescribeExecutor(pool Pool, taskType TaskType, info ExecutorInfo) error {
+	reporter, ok := UnwrapPool(pool).(capabilityReporter)
+	if !ok {
+		return nil
+	}
+	return reporter.describeExecutor(taskType, info)
+}
+
+// describeExecutor はプロセッサのエントリに実行環境を記録する
+func (wp *WorkerPool) describeExecutor(taskType TaskType, info ExecutorInfo) error {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	entry, exists := wp.processors[taskType]
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	entry.executor = info
+	return nil
+}
+
+// describeExecutor はタイプのサブプールに実行環境を記録する
+func (tp *TypedPool) describeExecutor(taskType TaskType, info ExecutorInfo) error {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	return pool.describeExecutor(taskType, info)
+}
+
+// TaskCapabilities は登録済みのタスクタイプの実行環境と制限をタイプ順に返す
+func (wp *WorkerPool) TaskCapabilities() []TaskCapability {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	capabilities := make([]TaskCapability, 0, len(wp.processors))
+	for taskType, entry := range wp.processors {
+		executor := entry.executor
+		if executor.Kind == "" {
+			executor.Kind = ExecutorInProcess
+		}
+		_, canary := wp.canaries[taskType]
+		capabilities = append(capabilities, TaskCapability{
+			TaskType:   taskType,
+			Executor:   executor,
+			Canary:     canary,
+			Timeout:    wp.taskTimeout,
+			Retry:      retryCapabilityOf(wp.retryPolicyFor(Task{Type: taskType})),
+			MaxWorkers: wp.workers,
+			Shared:     len(wp.processors) > 1,
+		})
+	}
+	sort.Slice(capabilities, func(i, j int) bool {
+		return capabilities[i].TaskType < capabilities[j].TaskType
+	})
+	return capabilities
+}
+
+// TaskCapabilities はすべてのサブプールのタスクタイプの実行環境と制限をタイプ順に返す
+func (tp *TypedPool) TaskCapabilities() []TaskCapability {
+	var capabilities []TaskCapability
+	for _, pool := range tp.subPools() {
+		capabilities = append(capabilities, pool.TaskCapabilities()...)
+	}
+	sort.Slice(capabilities, func(i, j int) bool {
+		return capabilities[i].TaskType < capabilities[j].TaskType
+	})
+	return capabilities
+}
+
+// DescribeCapabilities はプールのタスクタイプごとの実行環境と、デコレーターによる投入レートの上限を返す
+func DescribeCapabilities(pool Pool) Capabilities {
+	capabilities := Capabilities{TaskTypes: []TaskCapability{}}
+	for {
+		if limited, ok := pool.(*RateLimitedPool); ok && capabilities.RateLimit == nil {
+			capabilities.RateLimit = &RateLimitCapability{Rate: limited.rate, Burst: int(limited.burst)}
+		}
+		wrapper, ok := pool.(interface{ Unwrap() Pool })
+		if !ok {
+			break
+		}
+		pool = wrapper.Unwrap()
+	}
+	if reporter, ok := pool.(capabilityReporter); ok {
+		capabilities.TaskTypes = append(capabilities.TaskTypes, reporter.TaskCapabilities()...)
+	}
+	return capabilities
+}
+
+// retryCapabilityOf はリトライポリシーを JSON で返す形に変換する
+func retryCapabilityOf(policy RetryPolicy) RetryCapability {
+	capability := RetryCapability{
+		MaxRetries:     policy.MaxRetries,
+		InitialDelay:   policy.InitialDelay,
+		MaxDelay:       policy.MaxDelay,
+		BackoffFactor:  policy.BackoffFactor,
+		Exponential:    !policy.Linear,
+		Jitter:         policy.Jitter,
+		JitterStrategy: policy.JitterStrategy,
+	}
+	for class, curve := range policy.ClassBackoff {
+		if capability.ClassBackoff == nil {
+			capability.ClassBackoff = make(map[ErrorClass]BackoffCapability, len(policy.ClassBackoff))
+		}
+		capability.ClassBackoff[class] = BackoffCapability{
+			InitialDelay:  curve.InitialDelay,
+			MaxDelay:      curve.MaxDelay,
+			BackoffFactor: curve.BackoffFactor,
+			Exponential:   !curve.Linear,
+		}
+	}
+	// 型が失われたエラー向けの接頭辞は RetryOn のエラーと同じメッセージのことが多いため、重複を除く
+	seen := make(map[string]bool)
+	for _, target := range policy.RetryOn {
+		if message := target.Error(); !seen[message] {
+			seen[message] = true
+			capability.RetryOn = append(capability.RetryOn, message)
+		}
+	}
+	for _, prefix := range policy.RetryableErrors {
+		if !seen[prefix] {
+			seen[prefix] = true
+			capability.RetryOn = append(capability.RetryOn, prefix)
+		}
+	}
+	return capability
+}
+
+// handleCapabilities は GET /capabilities でタスクタイプごとの実行環境・タイムアウト・リトライポリシー・並行数・投入レートの上限を返す
+func (m *Monitor) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+	writeJSON(w, http.StatusOK, DescribeCapabilities(m.pool))
+}
@@ -0,0 +1,413 @@
+package workerpool
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNoCheckpoint はプール外（チェックポイントを保存できないコンテキスト）で Checkpoint を呼び出した場合のエラー
+var ErrNoCheckpoint = errors.New("チェックポイントを保存できるコンテキストではありません")
+
+// チェックポイントの保存の既定値
+const (
+	defaultCheckpointPrefix  = "checkpoints/"
+	defaultCheckpointTimeout = 30 * time.Second
+)
+
+// CheckpointStorage はチェックポイントの保存の設定
+// Interval より短い間隔の Checkpoint はメモリ上の進捗だけを更新し、保存は Interval ごとにまとめる（試行の終了時には直近の進捗を保存する）。
+// ブロブストアを指定した場合はチェックポイントの本体をブロブストアに保存し、タスク（タスクストアの記録）には参照だけを持たせる
+type CheckpointStorage struct {
+	Interval time.Duration `json:"interval_ns"` // 保存の最小間隔（0 の場合は Checkpoint のたびに保存する）
+	Prefix   string        `json:"prefix"`      // オブジェクトのキーの接頭辞（空の場合は checkpoints/）
+	Timeout  time.Duration `json:"timeout_ns"`  // 保存・読み込みのタイムアウト（0 の場合は30秒）
+}
+
+// withDefaults は既定値を補った設定を返す
+func (c CheckpointStorage) withDefaults() CheckpointStorage {
+	if c.Prefix == "" {
+		c.Prefix = defaultCheckpointPrefix
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultCheckpointTimeout
+	}
+	return c
+}
+
+// checkpointStorage はチェックポイントの保存先と設定
+type checkpointStorage struct {
+	store  BlobStore // nil の場合はタスクストアの記録に含める
+	config CheckpointStorage
+}
+
+// checkpointKey はコンテキストにチェックポイントの保存先を持たせるためのキー
+type checkpointKey struct{}
+
+// checkpointSlot は実行中のタスクのチェックポイントの保存先
+type checkpointSlot struct {
+	mu        sync.Mutex
+	state     json.RawMessage // 直近のチェックポイント（ブロブストアから読み込むまでは nil）
+	stored    json.RawMessage // タスクに持たせるチェックポイント（ブロブストアに保存した場合は参照）
+	dirty     bool            // 保存していない進捗がある
+	savedAt   time.Time
+	interval  time.Duration
+	requested chan struct{} // 停止処理でチェックポイントを求めたときに閉じる
+	once      sync.Once
+
+	saveMu sync.Mutex                                           // 保存を直列にする（mu を持ったまま保存しない）
+	save   func(state json.RawMessage) (json.RawMessage, error) // 保存し、タスクに持たせる形を返す
+	load   func() (json.RawMessage, error)                      // ブロブストアからの読み込み（参照でない場合は nil）
+}
+
+// latest は直近のチェックポイントを返す（読み込んでいない場合は参照のまま）
+func (s *checkpointSlot) latest() json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != nil {
+		return s.state
+	}
+	return s.stored
+}
+
+// resume は前回の実行から引き継いだチェックポイントを返す（ブロブストアの参照は読み込む）
+func (s *checkpointSlot) resume() (json.RawMessage, error) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	state, load := s.state, s.load
+	s.mu.Unlock()
+	if state != nil || load == nil {
+		return state, nil
+	}
+
+	state, err := load()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == nil {
+		s.state = state
+	}
+	return s.state, nil
+}
+
+// update は進捗を更新し、前回の保存から Interval が経っていれば保存する
+func (s *checkpointSlot) update(state json.RawMessage) error {
+	s.mu.Lock()
+	s.state = state
+	s.dirty = true
+	due := time.Since(s.savedAt) >= s.interval
+	s.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return s.flush()
+}
+
+// flush は保存していない進捗を保存する
+func (s *checkpointSlot) flush() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	if !s.dirty || s.save == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	state := s.state
+	s.dirty = false
+	s.savedAt = time.Now()
+	s.mu.Unlock()
+
+	stored, err := s.save(state)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.dirty = true
+		return err
+	}
+	s.stored = stored
+	return nil
+}
+
+// persisted はタスクに持たせる保存済みのチェックポイントを返す
+func (s *checkpointSlot) persisted() json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stored
+}
+
+// request はプロセッサにチェックポイントを保存して戻るよう求める
+func (s *checkpointSlot) request() {
+	s.once.Do(func() { close(s.requested) })
+}
+
+// Checkpoint は実行中のタスクの進捗（JSON にできる値）を保存する
+// 中断・失敗したタスクは、次の試行（リトライ・Shutdown 後の再投入・再起動後の復元）で ResumeCheckpoint から保存した進捗を受け取れる。
+// ストアを設定している場合はストアにも書き込むため、プロセスが落ちても引き継がれる（間隔は SetCheckpointStorage で調整できる）
+func Checkpoint(ctx context.Context, state interface{}) error {
+	slot, ok := ctx.Value(checkpointKey{}).(*checkpointSlot)
+	if !ok {
+		return ErrNoCheckpoint
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("チェックポイントを JSON にできません: %w", err)
+	}
+	if err := slot.update(data); err != nil {
+		return fmt.Errorf("チェックポイントを保存できません: %w", err)
+	}
+	return nil
+}
+
+// ResumeCheckpoint は前回の実行で保存したチェックポイントを v にデコードする（チェックポイントがない場合は false）
+func ResumeCheckpoint(ctx context.Context, v interface{}) (bool, error) {
+	slot, ok := ctx.Value(checkpointKey{}).(*checkpointSlot)
+	if !ok {
+		return false, nil
+	}
+	state, err := slot.resume()
+	if err != nil {
+		return false, err
+	}
+	if len(state) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal(state, v); err != nil {
+		return false, fmt.Errorf("チェックポイントを読み込めません: %w", err)
+	}
+	return true, nil
+}
+
+// CheckpointRequested は停止処理でチェックポイントの保存を求められたときに閉じるチャネルを返す
+// 長時間のプロセッサはこれを待ち、Checkpoint で進捗を保存してからエラー（ErrCheckpointed など）を返して戻ればよい。
+// プール外では nil（閉じないチャネル）を返す
+func CheckpointRequested(ctx context.Context) <-chan struct{} {
+	if slot, ok := ctx.Value(checkpointKey{}).(*checkpointSlot); ok {
+		return slot.requested
+	}
+	return nil
+}
+
+// ErrCheckpointed はプロセッサがチェックポイントを保存して途中で戻ったことを表すエラー（プロセッサから返す用）
+var ErrCheckpointed = errors.New("チェックポイントを保存して中断しました")
+
+// SetCheckpointGrace は Shutdown で期限までに終わらないタスクにチェックポイントの保存を求めてから中断するまでの猶予を設定する（0 で無効）
+// Shutdown は ctx の期限の grace 前までタスクの完了を待ち、残ったタスクに CheckpointRequested で保存を求め、
+// 猶予の間に戻らなかったタスクをキャンセルする。中断したタスクは直近のチェックポイントとともに未完了として返す
+func (wp *WorkerPool) SetCheckpointGrace(grace time.Duration) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.checkpointGrace = grace
+}
+
+// SetCheckpointGrace はすべてのサブプールにチェックポイントの猶予を設定する
+func (tp *TypedPool) SetCheckpointGrace(grace time.Duration) {
+	for _, pool := range tp.subPools() {
+		pool.SetCheckpointGrace(grace)
+	}
+}
+
+// SetCheckpointStorage はチェックポイントの保存の間隔と保存先を設定する（store が nil の場合はタスクストアの記録に含める）
+// 完了したタスクのチェックポイントは削除する。失敗したタスクのチェックポイントはDLQからの再投入のために残す
+func (wp *WorkerPool) SetCheckpointStorage(store BlobStore, config CheckpointStorage) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.checkpoints = &checkpointStorage{store: store, config: config.withDefaults()}
+}
+
+// SetCheckpointStorage はすべてのサブプールにチェックポイントの保存を設定する
+func (tp *TypedPool) SetCheckpointStorage(store BlobStore, config CheckpointStorage) {
+	for _, pool := range tp.subPools() {
+		pool.SetCheckpointStorage(store, config)
+	}
+}
+
+// checkpointStorage はチェックポイントの保存の設定を返す
+func (wp *WorkerPool) checkpointStorage() checkpointStorage {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.checkpoints == nil {
+		return checkpointStorage{config: CheckpointStorage{}.withDefaults()}
+	}
+	return *wp.checkpoints
+}
+
+// withCheckpoint はタスクのチェックポイントの保存先を設定したコンテキストを返す
+func (wp *WorkerPool) withCheckpoint(ctx context.Context, task Task) (context.Context, *checkpointSlot) {
+	storage := wp.checkpointStorage()
+	slot := &checkpointSlot{
+		stored:    task.Checkpoint,
+		interval:  storage.config.Interval,
+		requested: make(chan struct{}),
+		savedAt:   time.Now(),
+	}
+	if ref, ok := checkpointRefOf(task.Checkpoint); ok {
+		slot.load = func() (json.RawMessage, error) { return storage.get(ctx, ref) }
+	} else {
+		slot.state = task.Checkpoint
+	}
+	slot.save = func(state json.RawMessage) (json.RawMessage, error) {
+		stored := state
+		if storage.store != nil {
+			var err error
+			if stored, err = storage.put(task, state); err != nil {
+				return nil, err
+			}
+		}
+		// 実行中のまま進捗を保存し、再起動後の復元で引き継ぐ
+		saved := task
+		saved.Checkpoint = stored
+		return stored, wp.commit(saved, TaskStateRunning, nil)
+	}
+	return context.WithValue(ctx, checkpointKey{}, slot), slot
+}
+
+// settleCheckpoint は試行の終了時にタスクに持たせるチェックポイントを返す
+func (wp *WorkerPool) settleCheckpoint(task Task, slot *checkpointSlot) json.RawMessage {
+	if wp.checkpointStorage().store == nil {
+		// タスクストアには試行の結果とともに保存される
+		return slot.latest()
+	}
+	if err := slot.flush(); err != nil {
+		event("checkpoint.save_failed").taskOf(task).failed(err).logf("⚠️ タスク %d のチェックポイントを保存できません: %v\n", task.ID, err)
+	}
+	return slot.persisted()
+}
+
+// discardCheckpoint はブロブストアに保存したチェックポイントを削除する（完了したタスク用）
+func (wp *WorkerPool) discardCheckpoint(task Task) {
+	ref, ok := checkpointRefOf(task.Checkpoint)
+	if !ok {
+		return
+	}
+	storage := wp.checkpointStorage()
+	if storage.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storage.config.Timeout)
+	defer cancel()
+	if err := storage.store.Delete(ctx, ref.Key); err != nil {
+		event("checkpoint.delete_failed").taskOf(task).failed(err).logf("⚠️ タスク %d のチェックポイント %s を削除できません: %v\n", task.ID, ref.Key, err)
+	}
+}
+
+// put はチェックポイントをブロブストアに保存し、参照を返す
+func (s checkpointStorage) put(task Task, state json.RawMessage) (json.RawMessage, error) {
+	sum := sha256.Sum256(state)
+	ref := PayloadRef{
+		Key:    fmt.Sprintf("%s%d", s.config.Prefix, task.ID),
+		Size:   len(state),
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	if err := s.store.Put(ctx, ref.Key, state); err != nil {
+		return nil, err
+	}
+	return json.Marshal(ref)
+}
+
+// get はブロブストアに保存したチェックポイントを読み込む
+func (s checkpointStorage) get(ctx context.Context, ref PayloadRef) (json.RawMessage, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("%w: ブロブストアが設定されていません (%s)", ErrPayloadUnavailable, ref.Key)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	data, err := s.store.Get(ctx, ref.Key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrPayloadUnavailable, ref.Key, err)
+	}
+	if sum := sha256.Sum256(data); ref.SHA256 != "" && hex.EncodeToString(sum[:]) != ref.SHA256 {
+		return nil, fmt.Errorf("%w: チェックポイント %s のチェックサムが一致しません", ErrTaskCorrupt, ref.Key)
+	}
+	return data, nil
+}
+
+// checkpointRefOf はチェックポイントがブロブストアに保存した参照であれば返す
+func checkpointRefOf(checkpoint json.RawMessage) (PayloadRef, bool) {
+	if len(checkpoint) == 0 || checkpoint[0] != '{' {
+		return PayloadRef{}, false
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(checkpoint, &decoded); err != nil {
+		return PayloadRef{}, false
+	}
+	return payloadRefOf(decoded)
+}
+
+// drainContext は Shutdown で完了を待つコンテキスト（ctx の期限からチェックポイントの猶予を差し引いたもの）と猶予を返す
+func (wp *WorkerPool) drainContext(ctx context.Context) (context.Context, context.CancelFunc, time.Duration) {
+	wp.mu.Lock()
+	grace := wp.checkpointGrace
+	wp.mu.Unlock()
+
+	deadline, ok := ctx.Deadline()
+	if grace <= 0 || !ok {
+		return ctx, func() {}, grace
+	}
+	drainCtx, cancel := context.WithDeadline(ctx, deadline.Add(-grace))
+	return drainCtx, cancel, grace
+}
+
+// checkpointInFlight は実行中のタスクにチェックポイントの保存を求め、猶予の間に戻らなかったタスクを中断する
+// 保存して戻ったタスクと中断したタスクを、直近のチェックポイントとともに返す
+func (wp *WorkerPool) checkpointInFlight(grace time.Duration) []Task {
+	if grace <= 0 {
+		return wp.abortInFlight()
+	}
+
+	wp.mu.Lock()
+	count := len(wp.inFlight)
+	for entry := range wp.inFlight {
+		entry.checkpointing = true
+		entry.slot.request()
+	}
+	wp.mu.Unlock()
+	if count > 0 {
+		event("pool.checkpointing").logf("💾 実行中の %d 件のタスクにチェックポイントの保存を求めます（猶予 %v）\n", count, grace)
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+wait:
+	for {
+		wp.mu.Lock()
+		remaining := len(wp.inFlight)
+		wp.mu.Unlock()
+		if remaining == 0 {
+			break
+		}
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			break wait
+		}
+	}
+
+	// 先に中断してから集めることで、中断と同時に戻ったタスクを取りこぼさない
+	aborted := wp.abortInFlight()
+	wp.mu.Lock()
+	checkpointed := wp.checkpointed
+	wp.checkpointed = nil
+	wp.mu.Unlock()
+	return append(checkpointed, aborted...)
+}
@@ -0,0 +1,249 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrInvalidClickHouse は ClickHouse 出力の設定が不正な場合のエラー
+var ErrInvalidClickHouse = errors.New("ClickHouse の設定が不正です")
+
+// ClickHouse 出力の既定値
+const (
+	defaultClickHouseTable     = "task_results"
+	defaultClickHouseBatch     = 1000
+	defaultClickHouseFlush     = 10 * time.Second
+	defaultClickHouseMaxBuffer = 100000
+	clickHouseTimeout          = 30 * time.Second
+)
+
+// clickHouseDateTime は DateTime64(3, 'UTC') の列に渡す日時の形式
+const clickHouseDateTime = "2006-01-02 15:04:05.000"
+
+// clickHouseIdentifier はデータベース名・テーブル名として使える文字
+var clickHouseIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ClickHouseConfig はタスクごとの記録を ClickHouse に書き込む設定
+type ClickHouseConfig struct {
+	URL           string        `json:"url"`               // HTTP インターフェースのURL（例: http://localhost:8123）
+	Database      string        `json:"database"`          // 空の場合は default
+	Table         string        `json:"table"`             // 空の場合は task_results
+	Username      string        `json:"username"`          //
+	Password      string        `json:"password"`          //
+	CreateTable   bool          `json:"create_table"`      // テーブルがなければ作成する
+	TTLDays       int           `json:"ttl_days"`          // 作成するテーブルの保持日数（0 で無期限）
+	BatchSize     int           `json:"batch_size"`        // まとめて書き込む件数（0 の場合は1000）
+	FlushInterval time.Duration `json:"flush_interval_ns"` // 溜まった記録を書き込む間隔（0 の場合は10秒）
+	MaxBuffered   int           `json:"max_buffered"`      // 書き込めない間に保持する上限（0 の場合は100000）
+	Client        *http.Client  `json:"-"`                 // nil の場合は30秒でタイムアウトするクライアント
+}
+
+// clickHouseRow は ClickHouse に書き込む1行（JSONEachRow 形式）
+type clickHouseRow struct {
+	TaskID          int               `json:"task_id"`
+	TaskName        string            `json:"task_name"`
+	TaskType        TaskType          `json:"task_type"`
+	Labels          map[string]string `json:"labels"`
+	Instance        map[string]string `json:"instance"`
+	Variant         string            `json:"variant"`
+	Success         bool              `json:"success"`
+	ErrorCode       string            `json:"error_code"`
+	Error           string            `json:"error"`
+	Retryable       bool              `json:"retryable"`
+	DurationMs      float64           `json:"duration_ms"`
+	TotalDurationMs float64           `json:"total_duration_ms"`
+	AgeMs           float64           `json:"age_ms"`
+	AttemptCount    int               `json:"attempt_count"`
+	WorkerID        int               `json:"worker_id"`
+	StartTime       string            `json:"start_time"`
+	EndTime         string            `json:"end_time"`
+	CreatedAt       string            `json:"created_at"`
+}
+
+// clickHouseTableSchema はテーブルを作成する場合の定義（月ごとに分割し、タイプ・終了日時で並べる）
+// 顧客ごとの集計などはラベルを labels['customer'] のように参照する（インスタンスごとは instance['region'] など）
+const clickHouseTableSchema = `CREATE TABLE IF NOT EXISTS %s (
+	task_id Int64,
+	task_name String,
+	task_type LowCardinality(String),
+	labels Map(String, String),
+	instance Map(String, String),
+	variant LowCardinality(String),
+	success Bool,
+	error_code LowCardinality(String),
+	error String,
+	retryable Bool,
+	duration_ms Float64,
+	total_duration_ms Float64,
+	age_ms Float64,
+	attempt_count UInt32,
+	worker_id Int32,
+	start_time DateTime64(3, 'UTC'),
+	end_time DateTime64(3, 'UTC'),
+	created_at DateTime64(3, 'UTC')
+) ENGINE = MergeTree
+PARTITION BY toYYYYMM(end_time)
+ORDER BY (task_type, end_time)`
+
+// ClickHouseSink はタスクごとの記録を ClickHouse に書き込む（ResultSink）
+// 月単位の失敗傾向や顧客ごとのレイテンシなど、メモリ上のモニターでは扱えない長期間の分析に使う
+// 記録はバッファに溜め、件数か間隔のどちらかに達したらまとめて INSERT する
+type ClickHouseSink struct {
+	*resultBatcher
+	config ClickHouseConfig
+	client *http.Client
+	table  string // データベース名を含むテーブル名
+}
+
+// NewClickHouseSink は ClickHouse への出力を作成する
+func NewClickHouseSink(config ClickHouseConfig) (*ClickHouseSink, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("%w: url を指定してください", ErrInvalidClickHouse)
+	}
+	if config.Database == "" {
+		config.Database = "default"
+	}
+	if config.Table == "" {
+		config.Table = defaultClickHouseTable
+	}
+	for _, name := range []string{config.Database, config.Table} {
+		if !clickHouseIdentifier.MatchString(name) {
+			return nil, fmt.Errorf("%w: 名前 %q には英数字と _ のみ使えます", ErrInvalidClickHouse, name)
+		}
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultClickHouseBatch
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultClickHouseFlush
+	}
+	if config.MaxBuffered <= 0 {
+		config.MaxBuffered = defaultClickHouseMaxBuffer
+	}
+
+	s := &ClickHouseSink{
+		config: config,
+		client: config.Client,
+		table:  config.Database + "." + config.Table,
+	}
+	if s.client == nil {
+		s.client = &http.Client{Timeout: clickHouseTimeout}
+	}
+	if config.CreateTable {
+		if err := s.createTable(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	s.resultBatcher = newResultBatcher("ClickHouse", config.BatchSize, config.MaxBuffered, config.FlushInterval, s.insert)
+	return s, nil
+}
+
+// WriteResult は記録をバッファに追加する（書き込みはバックグラウンドで行う）
+func (s *ClickHouseSink) WriteResult(result TaskResult) error {
+	row, err := json.Marshal(newClickHouseRow(result))
+	if err != nil {
+		return err
+	}
+	return s.add(row)
+}
+
+// Close は残りの記録を書き込んで出力を終了する
+func (s *ClickHouseSink) Close() error {
+	return s.close(clickHouseTimeout)
+}
+
+// newClickHouseRow は結果を書き込む行に変換する
+func newClickHouseRow(result TaskResult) clickHouseRow {
+	labels := result.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return clickHouseRow{
+		TaskID:          result.TaskID,
+		TaskName:        result.TaskName,
+		TaskType:        result.TaskType,
+		Labels:          labels,
+		Instance:        instanceLabels(),
+		Variant:         result.Variant,
+		Success:         result.Success,
+		ErrorCode:       result.ErrorCode(),
+		Error:           errorMessage(result.Error),
+		Retryable:       result.IsRetryable(),
+		DurationMs:      durationToMs(result.Duration),
+		TotalDurationMs: durationToMs(result.TotalDuration),
+		AgeMs:           durationToMs(result.Age),
+		AttemptCount:    result.AttemptCount,
+		WorkerID:        result.WorkerID,
+		StartTime:       clickHouseTime(result.StartTime),
+		EndTime:         clickHouseTime(result.EndTime),
+		CreatedAt:       clickHouseTime(result.CreatedAt),
+	}
+}
+
+// clickHouseTime は日時を UTC の DateTime64 の形式にする（ゼロ値は 1970-01-01）
+func clickHouseTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Unix(0, 0)
+	}
+	return t.UTC().Format(clickHouseDateTime)
+}
+
+// insert は記録をまとめて INSERT する
+// instance 列を追加する前に作成したテーブルにも書き込めるよう、テーブルにない項目は無視させる
+func (s *ClickHouseSink) insert(ctx context.Context, rows [][]byte) error {
+	var body bytes.Buffer
+	for _, row := range rows {
+		body.Write(row)
+		body.WriteByte('\n')
+	}
+	return s.exec(ctx, "INSERT INTO "+s.table+" SETTINGS input_format_skip_unknown_fields = 1 FORMAT JSONEachRow", &body)
+}
+
+// createTable はテーブルがなければ作成する
+func (s *ClickHouseSink) createTable(ctx context.Context) error {
+	query := fmt.Sprintf(clickHouseTableSchema, s.table)
+	if s.config.TTLDays > 0 {
+		query += fmt.Sprintf("\nTTL toDateTime(end_time) + INTERVAL %d DAY", s.config.TTLDays)
+	}
+	if err := s.exec(ctx, query, nil); err != nil {
+		return fmt.Errorf("テーブル %s を作成できません: %w", s.table, err)
+	}
+	event("sink.clickhouse_table").logf("🏠 ClickHouse のテーブル %s を準備しました\n", s.table)
+	return nil
+}
+
+// exec はクエリを実行する（body は INSERT するデータ）
+func (s *ClickHouseSink) exec(ctx context.Context, query string, body io.Reader) error {
+	endpoint := strings.TrimRight(s.config.URL, "/") + "/?" + url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if s.config.Username != "" {
+		req.Header.Set("X-ClickHouse-User", s.config.Username)
+		req.Header.Set("X-ClickHouse-Key", s.config.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ClickHouse に接続できません: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("ClickHouse がエラーを返しました (%d): %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
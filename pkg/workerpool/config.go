@@ -0,0 +1,36 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config は設定ファイル（JSON）の内容
+type Config struct {
+	Scripts     []ScriptConfig      `json:"scripts"`     // スクリプトで定義するプロセッサ
+	Exec        []ExecConfig        `json:"exec"`        // 外部コマンドを実行するプロセッサ
+	Maintenance []MaintenanceWindow `json:"maintenance"` // タスクを実行しない時間帯
+	Schedules   []ScheduleSpec      `json:"schedules"`   // cron式で定期的に投入するタスク
+	Calendar    *BusinessCalendar   `json:"calendar"`    // スケジュールの営業日判定に使うカレンダー
+	Blackouts   StaticBlackouts     `json:"blackouts"`   // タスクを実行しない期間（変更凍結など）
+	ICal        []*ICalBlackouts    `json:"ical"`        // ブラックアウト期間を取得するiCalendarのURL
+	Templates   []TaskTemplate      `json:"templates"`   // スケジュール・バックフィル・APIで使うタスクのひな形
+	SLOs        []SLO               `json:"slos"`        // タスクタイプごとのサービスレベル目標
+	Queues      []QueueConfig       `json:"queues"`      // 名前付きキュー（SetQueues）
+	Routes      []QueueRoute        `json:"routes"`      // タスクを名前付きキューに振り分ける規則（定義した順に評価する）
+}
+
+// LoadConfig は設定ファイルを読み込む
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("設定ファイルの読み込みに失敗しました: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("設定ファイルの形式が不正です: %w", err)
+	}
+	return &config, nil
+}
@@ -0,0 +1,166 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidConsoleFormat は不明な出力形式を指定した場合のエラー
+var ErrInvalidConsoleFormat = errors.New("不明な出力形式です")
+
+// ConsoleFormat は進行状況のメッセージの出力形式
+type ConsoleFormat int
+
+const (
+	ConsoleText ConsoleFormat = iota // 人が読むための文章（既定）
+	ConsoleJSON                      // 1イベント1行のJSON（ログ収集基盤への取り込み用）
+)
+
+// ParseConsoleFormat は出力形式の名前（text / json）を解釈する
+func ParseConsoleFormat(name string) (ConsoleFormat, error) {
+	switch strings.ToLower(name) {
+	case "", "text":
+		return ConsoleText, nil
+	case "json":
+		return ConsoleJSON, nil
+	}
+	return ConsoleText, fmt.Errorf("%w: %s (text / json)", ErrInvalidConsoleFormat, name)
+}
+
+// console はプールが出力する進行状況のメッセージ（タスクの完了・リトライなど）の出力先
+var console = struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format ConsoleFormat
+}{out: os.Stdout}
+
+// SetConsoleOutput は進行状況のメッセージの出力先を変更し、変更前の出力先を返す
+// io.Discard を指定すると出力しない。PrintStats などの明示的な表示には影響しない
+func SetConsoleOutput(w io.Writer) io.Writer {
+	if w == nil {
+		w = io.Discard
+	}
+
+	console.mu.Lock()
+	defer console.mu.Unlock()
+
+	previous := console.out
+	console.out = w
+	return previous
+}
+
+// SetConsoleFormat は進行状況のメッセージの出力形式を設定する
+func SetConsoleFormat(format ConsoleFormat) {
+	console.mu.Lock()
+	defer console.mu.Unlock()
+
+	console.format = format
+}
+
+// ConsoleEvent は進行状況のイベント（JSON形式では1行に1件出力する）
+// Event はイベントの種類（task.completed など）で、該当しない項目は省略する
+type ConsoleEvent struct {
+	Time       time.Time     `json:"time"`
+	Event      string        `json:"event"`
+	TaskID     *int          `json:"task_id,omitempty"`
+	TaskType   TaskType      `json:"task_type,omitempty"`
+	WorkerID   *int          `json:"worker_id,omitempty"`
+	Attempt    int           `json:"attempt,omitempty"`
+	DurationMs *float64      `json:"duration_ms,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Message    string        `json:"message"`
+	Instance   *InstanceInfo `json:"instance,omitempty"` // SetInstanceInfo で設定したインスタンス情報
+}
+
+// event はイベントを作成する。項目を設定してから logf・logln で出力する
+func event(name string) *ConsoleEvent {
+	return &ConsoleEvent{Event: name}
+}
+
+// task はイベントの対象タスクを設定する
+func (e *ConsoleEvent) task(id int) *ConsoleEvent {
+	e.TaskID = &id
+	return e
+}
+
+// taskOf はイベントの対象タスクとタイプを設定する
+func (e *ConsoleEvent) taskOf(task Task) *ConsoleEvent {
+	e.TaskType = task.Type
+	return e.task(task.ID)
+}
+
+// worker はイベントを出したワーカーを設定する
+func (e *ConsoleEvent) worker(id int) *ConsoleEvent {
+	e.WorkerID = &id
+	return e
+}
+
+// attempt はタスクの試行回数を設定する
+func (e *ConsoleEvent) attempt(count int) *ConsoleEvent {
+	e.Attempt = count
+	return e
+}
+
+// took はイベントに関わる処理時間を設定する
+func (e *ConsoleEvent) took(d time.Duration) *ConsoleEvent {
+	ms := durationToMs(d)
+	e.DurationMs = &ms
+	return e
+}
+
+// failed はイベントの原因となったエラーを設定する
+func (e *ConsoleEvent) failed(err error) *ConsoleEvent {
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// logf はイベントを出力する（文章形式ではメッセージをそのまま出力する）
+func (e *ConsoleEvent) logf(format string, args ...interface{}) {
+	e.write(fmt.Sprintf(format, args...))
+}
+
+// logln はイベントを1行のメッセージとして出力する
+func (e *ConsoleEvent) logln(args ...interface{}) {
+	e.write(fmt.Sprintln(args...))
+}
+
+// write は出力形式に合わせてイベントを書き出す
+func (e *ConsoleEvent) write(text string) {
+	console.mu.Lock()
+	defer console.mu.Unlock()
+
+	if console.format != ConsoleJSON {
+		io.WriteString(console.out, text)
+		return
+	}
+
+	e.Time = time.Now()
+	e.Message = plainMessage(text)
+	e.Instance = CurrentInstance()
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	console.out.Write(append(line, '\n'))
+}
+
+// plainMessage はメッセージから前後の空白と先頭の絵文字を取り除く
+func plainMessage(text string) string {
+	text = strings.TrimSpace(text)
+	head, rest, found := strings.Cut(text, " ")
+	if found && strings.IndexFunc(head, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) < 0 {
+		return strings.TrimSpace(rest)
+	}
+	return text
+}
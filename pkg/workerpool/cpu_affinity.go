@@ -0,0 +1,102 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrAffinityUnsupported はCPUアフィニティに対応していないビルドで設定しようとした場合のエラー
+	// Linux で -tags affinity を指定してビルドした場合のみ対応する
+	ErrAffinityUnsupported = errors.New("CPUアフィニティはこのビルドでは使えません（Linux で -tags affinity を指定してください）")
+	// ErrInvalidCPUSet はCPUの指定が不正な場合のエラー
+	ErrInvalidCPUSet = errors.New("CPUの指定が不正です")
+)
+
+// maxAffinityCPU は指定できるCPU番号の上限（sched_setaffinity のマスクの大きさ）
+const maxAffinityCPU = 1024
+
+// SetCPUAffinity はワーカーを実行するCPUを cpus に限定する（Start 前に呼び出すこと。nil で解除）
+// CPU負荷の高いプロセッサを同じNUMAノードのCPUに寄せ、ソケットをまたぐメモリアクセスを減らすためのもの。
+// 設定したワーカーはOSスレッドに固定され、終了時にそのスレッドも破棄される（アフィニティが他の goroutine に漏れないようにする）
+func (wp *WorkerPool) SetCPUAffinity(cpus []int) error {
+	if len(cpus) > 0 {
+		if !affinitySupported {
+			return ErrAffinityUnsupported
+		}
+		for _, cpu := range cpus {
+			if cpu < 0 || cpu >= maxAffinityCPU {
+				return fmt.Errorf("%w: CPU %d は 0〜%d の範囲で指定してください", ErrInvalidCPUSet, cpu, maxAffinityCPU-1)
+			}
+		}
+	}
+
+	wp.mu.Lock()
+	wp.cpuAffinity = append([]int(nil), cpus...)
+	wp.mu.Unlock()
+
+	if len(cpus) > 0 {
+		event("pool.cpu_affinity").logf("📌 ワーカーをCPU %v に固定します\n", cpus)
+	}
+	return nil
+}
+
+// SetCPUAffinity は指定したタイプのサブプール（ワーカーグループ）のワーカーを実行するCPUを限定する
+func (tp *TypedPool) SetCPUAffinity(taskType TaskType, cpus []int) error {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s のサブプールが定義されていません", taskType)
+	}
+	return pool.SetCPUAffinity(cpus)
+}
+
+// pinWorkerThread はワーカーの goroutine をOSスレッドに固定し、CPUアフィニティを設定する
+// 返す関数はワーカーの終了時に呼び出す。アフィニティを設定したスレッドは固定したまま終了させ、ランタイムに破棄させる
+func (wp *WorkerPool) pinWorkerThread(id int) func() {
+	wp.mu.Lock()
+	lockOSThread := wp.lockOSThread
+	cpus := wp.cpuAffinity
+	wp.mu.Unlock()
+
+	if !lockOSThread && len(cpus) == 0 {
+		return func() {}
+	}
+	// スレッドに依存するプロセッサのため、このワーカーを1つのOSスレッドに固定
+	runtime.LockOSThread()
+	if len(cpus) == 0 {
+		return runtime.UnlockOSThread
+	}
+	if err := setThreadAffinity(cpus); err != nil {
+		event("worker.cpu_affinity_failed").worker(id).failed(err).logf("⚠️ ワーカー %d のCPUアフィニティを設定できませんでした: %v\n", id, err)
+		return runtime.UnlockOSThread
+	}
+	return func() {}
+}
+
+// parseCPUList は "0-3,8,10-11" の形式のCPUリストを解析する
+func parseCPUList(list string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(strings.TrimSpace(list), ",") {
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCPUSet, part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil || last < first {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidCPUSet, part)
+			}
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
@@ -0,0 +1,36 @@
+//go:build linux && affinity
+
+package workerpool
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// affinitySupported はこのビルドでCPUアフィニティを設定できるか
+const affinitySupported = true
+
+// setThreadAffinity は呼び出し元のOSスレッドを実行するCPUを限定する（runtime.LockOSThread の後に呼び出すこと）
+func setThreadAffinity(cpus []int) error {
+	var mask [maxAffinityCPU / 64]uint64
+	for _, cpu := range cpus {
+		mask[cpu/64] |= 1 << (uint(cpu) % 64)
+	}
+	// pid に 0 を指定すると呼び出し元のスレッドが対象になる
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0, uintptr(len(mask)*8), uintptr(unsafe.Pointer(&mask[0])))
+	if errno != 0 {
+		return fmt.Errorf("sched_setaffinity: %w", errno)
+	}
+	return nil
+}
+
+// NUMANodeCPUs はNUMAノードに属するCPUの番号を返す（SetCPUAffinity に渡す用）
+func NUMANodeCPUs(node int) ([]int, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/sys/devices/system/node/node%d/cpulist", node))
+	if err != nil {
+		return nil, fmt.Errorf("NUMAノード %d のCPUを取得できません: %w", node, err)
+	}
+	return parseCPUList(string(data))
+}
@@ -0,0 +1,16 @@
+//go:build !linux || !affinity
+
+package workerpool
+
+// affinitySupported はこのビルドでCPUアフィニティを設定できるか
+const affinitySupported = false
+
+// setThreadAffinity はCPUアフィニティに対応していないビルドでは常にエラーを返す
+func setThreadAffinity(cpus []int) error {
+	return ErrAffinityUnsupported
+}
+
+// NUMANodeCPUs はCPUアフィニティに対応していないビルドでは常にエラーを返す
+func NUMANodeCPUs(node int) ([]int, error) {
+	return nil, ErrAffinityUnsupported
+}
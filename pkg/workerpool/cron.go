@@ -0,0 +1,185 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron はcron式が不正な場合のエラー
+var ErrInvalidCron = errors.New("cron式が不正です")
+
+// cronSearchDays は次回実行日時を探す最大日数（2月29日のみの指定でも見つかるよう4年強）
+const cronSearchDays = 366*4 + 1
+
+// cronMacros は @ で始まる省略形
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// CronSchedule は5フィールド（分 時 日 月 曜日）のcron式
+// 日付はタイムゾーンの暦で判定する。日と曜日の両方を指定した場合はどちらかに一致すれば実行し、
+// どちらかが * で始まる場合（*/2 など）は Vixie cron と同じく両方に一致した日だけ実行する
+type CronSchedule struct {
+	expr     string
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	dayStar  bool // 日が * で始まる（日と曜日の両方に一致した日に実行する）
+	weekStar bool // 曜日が * で始まる（日と曜日の両方に一致した日に実行する）
+	location *time.Location
+}
+
+// ParseCron はcron式を解釈する（location が nil の場合はローカルタイムゾーン）
+// 各フィールドは * , - / を使える。曜日は 0（または7）が日曜
+func ParseCron(expr string, location *time.Location) (*CronSchedule, error) {
+	if location == nil {
+		location = time.Local
+	}
+	spec := strings.TrimSpace(expr)
+	if macro, ok := cronMacros[spec]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q は5つのフィールド（分 時 日 月 曜日）が必要です", ErrInvalidCron, expr)
+	}
+
+	cron := &CronSchedule{expr: expr, location: location}
+	bounds := []struct {
+		name     string
+		min, max int
+		bits     *uint64
+	}{
+		{"分", 0, 59, &cron.minutes},
+		{"時", 0, 23, &cron.hours},
+		{"日", 1, 31, &cron.days},
+		{"月", 1, 12, &cron.months},
+		{"曜日", 0, 7, &cron.weekdays},
+	}
+	for i, b := range bounds {
+		bits, err := parseCronField(fields[i], b.min, b.max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q の%s: %v", ErrInvalidCron, expr, b.name, err)
+		}
+		*b.bits = bits
+	}
+	// 7 は日曜として扱う
+	if cron.weekdays&(1<<7) != 0 {
+		cron.weekdays |= 1
+	}
+	// Vixie cron と同じく */2 などの * で始まるフィールドも * とみなす
+	cron.dayStar = strings.HasPrefix(fields[2], "*")
+	cron.weekStar = strings.HasPrefix(fields[4], "*")
+	return cron, nil
+}
+
+// parseCronField はフィールドを値のビット集合に変換する
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("間隔 %q が不正です", part[i+1:])
+			}
+			rangePart, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("範囲 %q が不正です", rangePart)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("値 %q が不正です", rangePart)
+			}
+			lo = n
+			if step == 1 {
+				hi = n
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q は %d-%d の範囲で指定してください", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// String は元のcron式を返す
+func (c *CronSchedule) String() string {
+	return c.expr
+}
+
+// Location はcron式を評価するタイムゾーンを返す
+func (c *CronSchedule) Location() *time.Location {
+	return c.location
+}
+
+// Next は after より後の最初の実行日時を返す（見つからない場合はゼロ値）
+// 夏時間の切り替えで存在しない時刻はスキップする
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	local := after.In(c.location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.location)
+
+	for i := 0; i < cronSearchDays; i++ {
+		date := day.AddDate(0, 0, i)
+		if !c.matchesDate(date) {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if c.hours&(1<<uint(hour)) == 0 {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if c.minutes&(1<<uint(minute)) == 0 {
+					continue
+				}
+				t := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, c.location)
+				if t.Hour() != hour || t.Minute() != minute {
+					continue // 夏時間で存在しない時刻
+				}
+				if t.After(after) {
+					return t
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDate は日付（タイムゾーンの暦）が日・月・曜日の条件を満たすか判定
+func (c *CronSchedule) matchesDate(date time.Time) bool {
+	if c.months&(1<<uint(date.Month())) == 0 {
+		return false
+	}
+	dayMatch := c.days&(1<<uint(date.Day())) != 0
+	weekMatch := c.weekdays&(1<<uint(date.Weekday())) != 0
+	if c.dayStar || c.weekStar {
+		return dayMatch && weekMatch
+	}
+	return dayMatch || weekMatch
+}
@@ -0,0 +1,117 @@
+package workerpool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// cronTimeLayout はテストで実行日時を比べる形式（タイムゾーンの暦と略称で比べる）
+const cronTimeLayout = "2006-01-02 15:04 MST"
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("タイムゾーン %s を読み込めません: %v", name, err)
+	}
+	return location
+}
+
+func TestCronNext(t *testing.T) {
+	newYork := loadLocation(t, "America/New_York")
+	tokyo := loadLocation(t, "Asia/Tokyo")
+
+	tests := []struct {
+		name     string
+		expr     string
+		location *time.Location
+		after    string // location の暦で 2006-01-02 15:04
+		want     string // cronTimeLayout
+	}{
+		{"every 15 minutes", "*/15 * * * *", time.UTC, "2024-03-08 10:07", "2024-03-08 10:15 UTC"},
+		{"step from value", "5/20 * * * *", time.UTC, "2024-03-08 10:30", "2024-03-08 10:45 UTC"},
+		{"step within range", "0 8-18/4 * * *", time.UTC, "2024-03-08 12:00", "2024-03-08 16:00 UTC"},
+		{"list", "0,30 9 * * *", time.UTC, "2024-03-08 09:00", "2024-03-08 09:30 UTC"},
+		{"strictly after", "0 9 * * *", time.UTC, "2024-03-08 09:00", "2024-03-09 09:00 UTC"},
+		{"weekdays skip weekend", "0 9 * * 1-5", time.UTC, "2024-03-08 10:00", "2024-03-11 09:00 UTC"},
+		{"7 is sunday", "0 0 * * 7", time.UTC, "2024-03-01 00:00", "2024-03-03 00:00 UTC"},
+		{"range ending at 7 includes sunday", "0 0 * * 6-7", time.UTC, "2024-03-09 00:00", "2024-03-10 00:00 UTC"},
+		{"day or weekday", "0 0 1 * 0", time.UTC, "2024-03-01 00:00", "2024-03-03 00:00 UTC"},
+		{"day or weekday friday 13th", "0 0 13 * 5", time.UTC, "2024-09-01 00:00", "2024-09-06 00:00 UTC"},
+		{"stepped day is star-like", "0 0 */2 * 1", time.UTC, "2024-03-01 00:00", "2024-03-11 00:00 UTC"},
+		{"stepped weekday is star-like", "0 0 1 * */2", time.UTC, "2024-03-01 00:00", "2024-06-01 00:00 UTC"},
+		{"month", "0 0 1 6 *", time.UTC, "2024-03-01 00:00", "2024-06-01 00:00 UTC"},
+		{"leap day", "0 0 29 2 *", time.UTC, "2024-03-01 00:00", "2028-02-29 00:00 UTC"},
+		{"hourly macro", "@hourly", time.UTC, "2024-03-08 10:07", "2024-03-08 11:00 UTC"},
+		{"weekly macro", "@weekly", time.UTC, "2024-03-08 10:00", "2024-03-10 00:00 UTC"},
+		{"monthly macro", "@monthly", time.UTC, "2024-03-08 10:00", "2024-04-01 00:00 UTC"},
+		{"yearly macro", "@yearly", time.UTC, "2024-03-08 10:00", "2025-01-01 00:00 UTC"},
+		{"time zone", "0 9 * * *", tokyo, "2024-01-01 08:00", "2024-01-01 09:00 JST"},
+		{"spring forward skips missing time", "30 2 * * *", newYork, "2024-03-10 00:00", "2024-03-11 02:30 EDT"},
+		{"spring forward hourly", "0 * * * *", newYork, "2024-03-10 01:30", "2024-03-10 03:00 EDT"},
+		{"fall back runs once", "30 1 * * *", newYork, "2024-11-03 00:00", "2024-11-03 01:30 EDT"},
+		{"fall back after repeated hour", "30 1 * * *", newYork, "2024-11-03 01:45", "2024-11-04 01:30 EST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr, tt.location)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			after, err := time.ParseInLocation("2006-01-02 15:04", tt.after, tt.location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := cron.Next(after)
+			if got.IsZero() {
+				t.Fatalf("Next(%s) が見つかりません", tt.after)
+			}
+			if s := got.In(tt.location).Format(cronTimeLayout); s != tt.want {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, s, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextRepeatedHourRunsOnce(t *testing.T) {
+	newYork := loadLocation(t, "America/New_York")
+	cron, err := ParseCron("30 1 * * *", newYork)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := cron.Next(time.Date(2024, 11, 3, 0, 0, 0, 0, newYork))
+	second := cron.Next(first)
+	if second.Sub(first) < 24*time.Hour {
+		t.Errorf("夏時間の終了日に2回実行されます: %s, %s", first, second)
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@every 5m",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1-x * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseCron(expr, time.UTC); !errors.Is(err, ErrInvalidCron) {
+				t.Errorf("ParseCron(%q) error = %v, want ErrInvalidCron", expr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,724 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Pool のデコレーター
+// いずれも Pool を埋め込み、対象のメソッドだけを差し替える。機能をコアの構造体に組み込まずに重ねられる
+//
+//	pool := NewMeteredPool(NewTracedPool(NewRateLimitedPool(NewWorkerPool(4), 50, 100)))
+
+// デコレーターのエラー
+var (
+	ErrRateLimited = errors.New("レート制限: 投入数が上限を超えています")
+	ErrForbidden   = errors.New("権限がありません")
+)
+
+// UnwrapPool はデコレーターを外した元のプールを返す（デコレーターでない場合はそのまま返す）
+func UnwrapPool(pool Pool) Pool {
+	for {
+		wrapper, ok := pool.(interface{ Unwrap() Pool })
+		if !ok {
+			return pool
+		}
+		pool = wrapper.Unwrap()
+	}
+}
+
+// RateLimitedPool はタスクの投入数をトークンバケットで制限する
+// AddTask は上限を超えると ErrRateLimited を返し、Execute はトークンが補充されるまで待つ
+type RateLimitedPool struct {
+	Pool
+
+	mu      sync.Mutex
+	rate    float64 // 1秒あたりに補充するトークン数
+	burst   float64 // バケットの容量
+	tokens  float64
+	last    time.Time
+	limited int64 // 上限を超えて拒否した件数
+}
+
+// NewRateLimitedPool は1秒あたり rate 件（瞬間的には burst 件まで）に投入を制限する
+func NewRateLimitedPool(pool Pool, rate float64, burst int) *RateLimitedPool {
+	burst = max(burst, 1)
+	return &RateLimitedPool{
+		Pool:   pool,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Unwrap は元のプールを返す
+func (p *RateLimitedPool) Unwrap() Pool { return p.Pool }
+
+// Limited は上限を超えて拒否した件数を返す
+func (p *RateLimitedPool) Limited() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.limited
+}
+
+// AddTask はトークンがあれば投入し、なければ ErrRateLimited を返す
+func (p *RateLimitedPool) AddTask(task Task) error {
+	if !p.take() {
+		event("task.rejected").taskOf(task).failed(ErrRateLimited).logf("🚫 タスク %d (%s) の受付を拒否しました: %v\n", task.ID, task.Name, ErrRateLimited)
+		return ErrRateLimited
+	}
+	return p.Pool.AddTask(task)
+}
+
+// Execute はトークンが補充されるまで待ってから実行する（待機中に ctx が終了した場合は ctx.Err()）
+func (p *RateLimitedPool) Execute(ctx context.Context, task Task) (TaskResult, error) {
+	wait, ok := p.reserve()
+	if !ok {
+		return TaskResult{}, ErrRateLimited
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			p.refund()
+			return TaskResult{}, ctx.Err()
+		}
+	}
+	return p.Pool.Execute(ctx, task)
+}
+
+// PlanTask は元のプールの計画に、トークンがない場合の拒否を反映する
+func (p *RateLimitedPool) PlanTask(task Task) SubmissionPlan {
+	plan := p.Pool.PlanTask(task)
+	if !plan.Accepted() {
+		return plan
+	}
+
+	p.mu.Lock()
+	p.refillLocked(time.Now())
+	available := p.tokens >= 1
+	p.mu.Unlock()
+	if !available {
+		return plan.reject(PlanRejected, ErrRateLimited)
+	}
+	return plan
+}
+
+// take はトークンを1つ取り出す（なければ拒否として数えて false）
+func (p *RateLimitedPool) take() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.refillLocked(time.Now())
+	if p.tokens < 1 {
+		p.limited++
+		return false
+	}
+	p.tokens--
+	return true
+}
+
+// reserve はトークンを先に借り、使えるようになるまでの待ち時間を返す（補充されない設定の場合は false）
+func (p *RateLimitedPool) reserve() (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.refillLocked(time.Now())
+	if p.tokens < 1 && p.rate <= 0 {
+		p.limited++
+		return 0, false
+	}
+	p.tokens--
+	if p.tokens >= 0 {
+		return 0, true
+	}
+	return time.Duration(-p.tokens / p.rate * float64(time.Second)), true
+}
+
+// refund は待機を取りやめた分のトークンを戻す
+func (p *RateLimitedPool) refund() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tokens = min(p.tokens+1, p.burst)
+}
+
+// refillLocked は経過時間に応じてトークンを補充する（p.mu を保持して呼び出すこと）
+func (p *RateLimitedPool) refillLocked(now time.Time) {
+	p.tokens = min(p.tokens+now.Sub(p.last).Seconds()*p.rate, p.burst)
+	p.last = now
+}
+
+// TracedPool はタスクの投入・実行と管理操作を OpenTelemetry のスパンとして記録する
+// スパンは元のプールに SetTracerProvider で設定した TracerProvider に送る（未設定の場合は記録しない）。
+// Execute ではスパンを含むコンテキストがタスクのスパン（task）とプロセッサまで伝わるため、プロセッサ内で子スパンを作成できる
+type TracedPool struct {
+	Pool
+}
+
+// tracerSource は SetTracerProvider で設定した Tracer を返せるプール
+type tracerSource interface {
+	tracerOf() trace.Tracer
+}
+
+// NewTracedPool はトレースするプールを作成する
+func NewTracedPool(pool Pool) *TracedPool {
+	return &TracedPool{Pool: pool}
+}
+
+// Unwrap は元のプールを返す
+func (p *TracedPool) Unwrap() Pool { return p.Pool }
+
+// tracer は元のプールに設定された Tracer を返す（後から SetTracerProvider を呼び出した場合も反映する）
+func (p *TracedPool) tracer() trace.Tracer {
+	if source, ok := UnwrapPool(p.Pool).(tracerSource); ok {
+		return source.tracerOf()
+	}
+	return noopTracer
+}
+
+// AddTask は投入をスパンとして記録する
+func (p *TracedPool) AddTask(task Task) error {
+	_, span := p.tracer().Start(context.Background(), "pool.add_task", trace.WithAttributes(taskAttributes(task)...))
+	err := p.Pool.AddTask(task)
+	endSpan(span, err)
+	return err
+}
+
+// Execute は投入から最終結果までをスパンとして記録する（期限と時間の内訳は budget.* の属性）
+func (p *TracedPool) Execute(ctx context.Context, task Task) (TaskResult, error) {
+	ctx, span := p.tracer().Start(ctx, "pool.execute", trace.WithAttributes(taskAttributes(task)...))
+	result, err := p.Pool.Execute(ctx, task)
+	if err == nil {
+		span.SetAttributes(attrTaskAttempt.Int(result.AttemptCount), attrWorkerID.Int(result.WorkerID))
+		span.SetAttributes(budgetAttributes(result.Budget)...)
+		if !result.Success {
+			err = result.Error
+		}
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+// RetryNow はリトライの前倒しをスパンとして記録する
+func (p *TracedPool) RetryNow(taskID int) error {
+	return p.trace("pool.retry_now", []attribute.KeyValue{attrTaskID.Int(taskID)}, func() error {
+		return p.Pool.RetryNow(taskID)
+	})
+}
+
+// RequeueDeadLetters はDLQからの再投入をスパンとして記録する
+func (p *TracedPool) RequeueDeadLetters(ids []uint64) (int, error) {
+	var requeued int
+	err := p.trace("pool.requeue_dead_letters", []attribute.KeyValue{attribute.Int("dlq.ids", len(ids))}, func() error {
+		var err error
+		requeued, err = p.Pool.RequeueDeadLetters(ids)
+		return err
+	})
+	return requeued, err
+}
+
+// CancelTask はタスクの取り消しをスパンとして記録する
+func (p *TracedPool) CancelTask(taskID int) error {
+	return p.trace("pool.cancel_task", []attribute.KeyValue{attrTaskID.Int(taskID)}, func() error {
+		return p.Pool.CancelTask(taskID)
+	})
+}
+
+// CancelTasks は一括取り消しをスパンとして記録する
+func (p *TracedPool) CancelTasks(filter TaskFilter) int {
+	var canceled int
+	p.trace("pool.cancel_tasks", nil, func() error {
+		canceled = p.Pool.CancelTasks(filter)
+		return nil
+	})
+	return canceled
+}
+
+// Pause は一時停止をスパンとして記録する
+func (p *TracedPool) Pause() error {
+	return p.trace("pool.pause", nil, p.Pool.Pause)
+}
+
+// Resume は再開をスパンとして記録する
+func (p *TracedPool) Resume() error {
+	return p.trace("pool.resume", nil, p.Pool.Resume)
+}
+
+// Shutdown は停止処理をスパンとして記録する
+func (p *TracedPool) Shutdown(ctx context.Context) ([]Task, error) {
+	ctx, span := p.tracer().Start(ctx, "pool.shutdown")
+	unfinished, err := p.Pool.Shutdown(ctx)
+	span.SetAttributes(attribute.Int("pool.unfinished", len(unfinished)))
+	endSpan(span, err)
+	return unfinished, err
+}
+
+// trace は操作をスパンとして記録する
+func (p *TracedPool) trace(name string, attributes []attribute.KeyValue, fn func() error) error {
+	_, span := p.tracer().Start(context.Background(), name, trace.WithAttributes(attributes...))
+	err := fn()
+	endSpan(span, err)
+	return err
+}
+
+// taskAttributes はタスクの情報をスパンの属性にする
+func taskAttributes(task Task) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attrTaskID.Int(task.ID),
+		attrTaskType.String(string(task.Type)),
+		attribute.Int("task.priority", int(task.Priority)),
+	}
+}
+
+// budgetAttributes は期限と時間の内訳をスパンの属性にする（時間はナノ秒）
+func budgetAttributes(budget *LatencyBudget) []attribute.KeyValue {
+	if budget == nil {
+		return nil
+	}
+	var attributes []attribute.KeyValue
+	if budget.Budget > 0 {
+		attributes = append(attributes,
+			attribute.Int64("budget.total_ns", int64(budget.Budget)),
+			attribute.Int64("budget.remaining_ns", int64(budget.Remaining)),
+			attribute.Bool("budget.exceeded", budget.Exceeded),
+		)
+	}
+	if budget.BlownAt != "" {
+		attributes = append(attributes, attribute.String("budget.blown_at", budget.BlownAt))
+	}
+	return append(attributes,
+		attribute.Int64("budget.consumed_ns", int64(budget.Consumed)),
+		attribute.Int64("budget.queue_ns", int64(budget.Queue)),
+		attribute.Int64("budget.processing_ns", int64(budget.Processing)),
+		attribute.Int64("budget.retry_wait_ns", int64(budget.RetryWait)),
+	)
+}
+
+// PoolAction は権限を確認する操作の種類
+type PoolAction string
+
+const (
+	ActionSubmit PoolAction = "submit" // タスクの投入・実行・処理計画
+	ActionRead   PoolAction = "read"   // リトライ待ち・DLQの閲覧
+	ActionManage PoolAction = "manage" // リトライ・DLQ・一括操作・メンテナンス・一時停止
+	ActionAdmin  PoolAction = "admin"  // プロセッサの登録・差し替え、プールの開始・停止
+)
+
+// Authorizer は操作を許可するか判定する（taskType はタイプを問わない操作では空）
+type Authorizer func(action PoolAction, taskType TaskType) bool
+
+// TaskTypeAuthorizer は指定したタイプのタスクの投入・閲覧・操作のみ許可する
+// タイプを問わない操作（一時停止・メンテナンス・条件なしの一括操作など）と admin は許可しない
+func TaskTypeAuthorizer(types ...TaskType) Authorizer {
+	allowed := make(map[TaskType]bool, len(types))
+	for _, taskType := range types {
+		allowed[taskType] = true
+	}
+	return func(action PoolAction, taskType TaskType) bool {
+		return action != ActionAdmin && allowed[taskType]
+	}
+}
+
+// AuthzPool は操作ごとに権限を確認する（テナントごとにプールの一部だけを公開する場合など）
+// 許可されない操作は ErrForbidden を返し、リトライ待ち・DLQの一覧は閲覧できるタイプのみ返す。
+// スナップショット・状態・結果の取得は制限しない
+type AuthzPool struct {
+	Pool
+	authorize Authorizer
+}
+
+// NewAuthzPool は権限を確認するプールを作成する
+func NewAuthzPool(pool Pool, authorize Authorizer) *AuthzPool {
+	return &AuthzPool{Pool: pool, authorize: authorize}
+}
+
+// Unwrap は元のプールを返す
+func (p *AuthzPool) Unwrap() Pool { return p.Pool }
+
+// check は操作が許可されているか確認する
+func (p *AuthzPool) check(action PoolAction, taskType TaskType) error {
+	if p.authorize(action, taskType) {
+		return nil
+	}
+	if taskType == "" {
+		return fmt.Errorf("%w: %s", ErrForbidden, action)
+	}
+	return fmt.Errorf("%w: %s (%s)", ErrForbidden, action, taskType)
+}
+
+// checkFilter は条件に一致しうるすべてのタイプで操作が許可されているか確認する
+func (p *AuthzPool) checkFilter(action PoolAction, filter TaskFilter) error {
+	if len(filter.Types) == 0 {
+		return p.check(action, "")
+	}
+	for _, taskType := range filter.Types {
+		if err := p.check(action, taskType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *AuthzPool) RegisterProcessor(taskType TaskType, processor TaskProcessor) error {
+	if err := p.check(ActionAdmin, taskType); err != nil {
+		return err
+	}
+	return p.Pool.RegisterProcessor(taskType, processor)
+}
+
+func (p *AuthzPool) ReplaceProcessor(taskType TaskType, processor TaskProcessor) error {
+	if err := p.check(ActionAdmin, taskType); err != nil {
+		return err
+	}
+	return p.Pool.ReplaceProcessor(taskType, processor)
+}
+
+func (p *AuthzPool) UnregisterProcessor(taskType TaskType) error {
+	if err := p.check(ActionAdmin, taskType); err != nil {
+		return err
+	}
+	return p.Pool.UnregisterProcessor(taskType)
+}
+
+func (p *AuthzPool) AddTask(task Task) error {
+	if err := p.check(ActionSubmit, task.Type); err != nil {
+		return err
+	}
+	return p.Pool.AddTask(task)
+}
+
+func (p *AuthzPool) PlanTask(task Task) SubmissionPlan {
+	plan := p.Pool.PlanTask(task)
+	if err := p.check(ActionSubmit, task.Type); err != nil {
+		return plan.reject(PlanRejected, err)
+	}
+	return plan
+}
+
+func (p *AuthzPool) Execute(ctx context.Context, task Task) (TaskResult, error) {
+	if err := p.check(ActionSubmit, task.Type); err != nil {
+		return TaskResult{}, err
+	}
+	return p.Pool.Execute(ctx, task)
+}
+
+func (p *AuthzPool) Start() error {
+	if err := p.check(ActionAdmin, ""); err != nil {
+		return err
+	}
+	return p.Pool.Start()
+}
+
+// Stop は admin が許可されていない場合は何もしない
+func (p *AuthzPool) Stop() {
+	if err := p.check(ActionAdmin, ""); err != nil {
+		event("authz.denied").failed(err).logf("🔒 プールの停止を拒否しました: %v\n", err)
+		return
+	}
+	p.Pool.Stop()
+}
+
+func (p *AuthzPool) Shutdown(ctx context.Context) ([]Task, error) {
+	if err := p.check(ActionAdmin, ""); err != nil {
+		return nil, err
+	}
+	return p.Pool.Shutdown(ctx)
+}
+
+func (p *AuthzPool) PendingRetries() []RetryEntry {
+	var entries []RetryEntry
+	for _, entry := range p.Pool.PendingRetries() {
+		if p.authorize(ActionRead, entry.TaskType) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (p *AuthzPool) RetryNow(taskID int) error {
+	if err := p.checkRetry(taskID); err != nil {
+		return err
+	}
+	return p.Pool.RetryNow(taskID)
+}
+
+func (p *AuthzPool) CancelRetry(taskID int) error {
+	if err := p.checkRetry(taskID); err != nil {
+		return err
+	}
+	return p.Pool.CancelRetry(taskID)
+}
+
+// CancelTask は実行中のタスクのタイプを問い合わせられないため、すべてのタイプで manage が許可されている必要がある
+func (p *AuthzPool) CancelTask(taskID int) error {
+	if err := p.check(ActionManage, ""); err != nil {
+		return err
+	}
+	return p.Pool.CancelTask(taskID)
+}
+
+// checkRetry はリトライ待ちのタスクのタイプで manage が許可されているか確認する
+// （見つからない場合は元のプールにエラーを返させる）
+func (p *AuthzPool) checkRetry(taskID int) error {
+	for _, entry := range p.Pool.PendingRetries() {
+		if entry.TaskID == taskID {
+			return p.check(ActionManage, entry.TaskType)
+		}
+	}
+	return nil
+}
+
+func (p *AuthzPool) DeadLetters() []DeadLetter {
+	var letters []DeadLetter
+	for _, letter := range p.Pool.DeadLetters() {
+		if p.authorize(ActionRead, letter.Task.Type) {
+			letters = append(letters, letter)
+		}
+	}
+	return letters
+}
+
+func (p *AuthzPool) RequeueDeadLetters(ids []uint64) (int, error) {
+	if err := p.checkDeadLetters(ids); err != nil {
+		return 0, err
+	}
+	return p.Pool.RequeueDeadLetters(ids)
+}
+
+// PurgeDeadLetters は権限のないエントリを含む場合は何も削除しない
+func (p *AuthzPool) PurgeDeadLetters(ids []uint64) int {
+	if err := p.checkDeadLetters(ids); err != nil {
+		event("authz.denied").failed(err).logf("🔒 DLQの削除を拒否しました: %v\n", err)
+		return 0
+	}
+	return p.Pool.PurgeDeadLetters(ids)
+}
+
+// checkDeadLetters はDLQのエントリのタイプで manage が許可されているか確認する
+func (p *AuthzPool) checkDeadLetters(ids []uint64) error {
+	targets := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		targets[id] = true
+	}
+	for _, letter := range p.Pool.DeadLetters() {
+		if targets[letter.ID] {
+			if err := p.check(ActionManage, letter.Task.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CancelTasks は権限がない場合は何も取り消さない
+func (p *AuthzPool) CancelTasks(filter TaskFilter) int {
+	if err := p.checkFilter(ActionManage, filter); err != nil {
+		event("authz.denied").failed(err).logf("🔒 一括取り消しを拒否しました: %v\n", err)
+		return 0
+	}
+	return p.Pool.CancelTasks(filter)
+}
+
+func (p *AuthzPool) RequeueDeadLettersWhere(filter TaskFilter) (int, error) {
+	if err := p.checkFilter(ActionManage, filter); err != nil {
+		return 0, err
+	}
+	return p.Pool.RequeueDeadLettersWhere(filter)
+}
+
+// SetPriorityWhere は権限がない場合は何も変更しない
+func (p *AuthzPool) SetPriorityWhere(filter TaskFilter, priority Priority) int {
+	if err := p.checkFilter(ActionManage, filter); err != nil {
+		event("authz.denied").failed(err).logf("🔒 優先度の一括変更を拒否しました: %v\n", err)
+		return 0
+	}
+	return p.Pool.SetPriorityWhere(filter, priority)
+}
+
+func (p *AuthzPool) SetMaintenanceWindows(windows []MaintenanceWindow) error {
+	if err := p.check(ActionManage, ""); err != nil {
+		return err
+	}
+	return p.Pool.SetMaintenanceWindows(windows)
+}
+
+func (p *AuthzPool) Pause() error {
+	if err := p.check(ActionManage, ""); err != nil {
+		return err
+	}
+	return p.Pool.Pause()
+}
+
+func (p *AuthzPool) Resume() error {
+	if err := p.check(ActionManage, ""); err != nil {
+		return err
+	}
+	return p.Pool.Resume()
+}
+
+// MethodMetrics はメソッドごとの呼び出しの集計
+type MethodMetrics struct {
+	Calls     int64         `json:"calls"`
+	Errors    int64         `json:"errors"`
+	TotalTime time.Duration `json:"total_time_ns"`
+	MaxTime   time.Duration `json:"max_time_ns"`
+}
+
+// AverageTime は1回あたりの平均時間を返す
+func (m MethodMetrics) AverageTime() time.Duration {
+	if m.Calls == 0 {
+		return 0
+	}
+	return m.TotalTime / time.Duration(m.Calls)
+}
+
+// MeteredPool は投入・実行と管理操作の呼び出し回数・エラー数・所要時間を集計する
+// Execute はタスクが失敗した場合もエラーとして数える
+type MeteredPool struct {
+	Pool
+
+	mu      sync.Mutex
+	metrics map[string]*MethodMetrics
+}
+
+// NewMeteredPool は呼び出しを集計するプールを作成する
+func NewMeteredPool(pool Pool) *MeteredPool {
+	return &MeteredPool{Pool: pool, metrics: make(map[string]*MethodMetrics)}
+}
+
+// Unwrap は元のプールを返す
+func (p *MeteredPool) Unwrap() Pool { return p.Pool }
+
+// Metrics はメソッド名ごとの集計を返す
+func (p *MeteredPool) Metrics() map[string]MethodMetrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	metrics := make(map[string]MethodMetrics, len(p.metrics))
+	for method, m := range p.metrics {
+		metrics[method] = *m
+	}
+	return metrics
+}
+
+// observe は1回の呼び出しを集計する
+func (p *MeteredPool) observe(method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	m, exists := p.metrics[method]
+	if !exists {
+		m = &MethodMetrics{}
+		p.metrics[method] = m
+	}
+	m.Calls++
+	if err != nil {
+		m.Errors++
+	}
+	m.TotalTime += elapsed
+	m.MaxTime = max(m.MaxTime, elapsed)
+}
+
+func (p *MeteredPool) AddTask(task Task) error {
+	start := time.Now()
+	err := p.Pool.AddTask(task)
+	p.observe("AddTask", start, err)
+	return err
+}
+
+func (p *MeteredPool) Execute(ctx context.Context, task Task) (TaskResult, error) {
+	start := time.Now()
+	result, err := p.Pool.Execute(ctx, task)
+	failure := err
+	if failure == nil && !result.Success {
+		failure = result.Error
+		if failure == nil {
+			failure = errors.New("タスクが失敗しました")
+		}
+	}
+	p.observe("Execute", start, failure)
+	return result, err
+}
+
+func (p *MeteredPool) RetryNow(taskID int) error {
+	start := time.Now()
+	err := p.Pool.RetryNow(taskID)
+	p.observe("RetryNow", start, err)
+	return err
+}
+
+func (p *MeteredPool) CancelRetry(taskID int) error {
+	start := time.Now()
+	err := p.Pool.CancelRetry(taskID)
+	p.observe("CancelRetry", start, err)
+	return err
+}
+
+func (p *MeteredPool) CancelTask(taskID int) error {
+	start := time.Now()
+	err := p.Pool.CancelTask(taskID)
+	p.observe("CancelTask", start, err)
+	return err
+}
+
+func (p *MeteredPool) RequeueDeadLetters(ids []uint64) (int, error) {
+	start := time.Now()
+	requeued, err := p.Pool.RequeueDeadLetters(ids)
+	p.observe("RequeueDeadLetters", start, err)
+	return requeued, err
+}
+
+func (p *MeteredPool) RequeueDeadLettersWhere(filter TaskFilter) (int, error) {
+	start := time.Now()
+	requeued, err := p.Pool.RequeueDeadLettersWhere(filter)
+	p.observe("RequeueDeadLettersWhere", start, err)
+	return requeued, err
+}
+
+func (p *MeteredPool) CancelTasks(filter TaskFilter) int {
+	start := time.Now()
+	canceled := p.Pool.CancelTasks(filter)
+	p.observe("CancelTasks", start, nil)
+	return canceled
+}
+
+func (p *MeteredPool) Pause() error {
+	start := time.Now()
+	err := p.Pool.Pause()
+	p.observe("Pause", start, err)
+	return err
+}
+
+func (p *MeteredPool) Resume() error {
+	start := time.Now()
+	err := p.Pool.Resume()
+	p.observe("Resume", start, err)
+	return err
+}
+
+func (p *MeteredPool) Shutdown(ctx context.Context) ([]Task, error) {
+	start := time.Now()
+	unfinished, err := p.Pool.Shutdown(ctx)
+	p.observe("Shutdown", start, err)
+	return unfinished, err
+}
+
+var (
+	_ Pool = (*RateLimitedPool)(nil)
+	_ Pool = (*TracedPool)(nil)
+	_ Pool = (*AuthzPool)(nil)
+	_ Pool = (*MeteredPool)(nil)
+)
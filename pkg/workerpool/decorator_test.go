@@ -0,0 +1,65 @@
+package workerpool
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TracedPool は元のプールに設定した TracerProvider にスパンを送り、タスクのスパンは Execute のスパンの子になる
+func TestTracedPoolUsesConfiguredTracerProvider(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.RegisterProcessor("email", func(ctx context.Context, task Task) error { return nil })
+	traced := NewTracedPool(pool)
+
+	// NewTracedPool の後に設定した TracerProvider も使う
+	recorder := tracetest.NewSpanRecorder()
+	pool.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	if err := traced.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := traced.Execute(context.Background(), Task{ID: 1, Type: "email"}); err != nil {
+		t.Fatal(err)
+	}
+	traced.Stop()
+	if err := traced.AddTask(Task{ID: 2, Type: "report"}); err == nil {
+		t.Fatal("停止したプールがタスクを受け付けました")
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	execute, ok := spans["pool.execute"]
+	if !ok {
+		t.Fatalf("pool.execute のスパンがありません: %v", spans)
+	}
+	task, ok := spans["task email"]
+	if !ok {
+		t.Fatalf("タスクのスパンがありません: %v", spans)
+	}
+	if task.Parent().SpanID() != execute.SpanContext().SpanID() {
+		t.Fatalf("タスクのスパンが pool.execute の子になっていません: parent=%v execute=%v task=%v", task.Parent(), execute.SpanContext(), task.SpanContext())
+	}
+	addTask, ok := spans["pool.add_task"]
+	if !ok {
+		t.Fatalf("pool.add_task のスパンがありません: %v", spans)
+	}
+	if addTask.Status().Code.String() != "Error" {
+		t.Fatalf("失敗した投入のスパンの状態 = %v, want Error", addTask.Status())
+	}
+}
+
+// TypedPool でも SetTracerProvider で設定した TracerProvider にスパンを送る
+func TestTracedTypedPoolUsesConfiguredTracerProvider(t *testing.T) {
+	pool := NewTypedPool(map[TaskType]int{"email": 1})
+	recorder := tracetest.NewSpanRecorder()
+	pool.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	NewTracedPool(pool).Pause()
+	if ended := recorder.Ended(); len(ended) != 1 || ended[0].Name() != "pool.pause" {
+		t.Fatalf("スパン = %v, want pool.pause", ended)
+	}
+}
@@ -0,0 +1,274 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// タイマーホイールの既定値（1周 = 50ms × 1024 ≒ 51秒。それより先の予定は周回数で数える）
+const (
+	defaultWheelTick  = 50 * time.Millisecond
+	defaultWheelSlots = 1024
+)
+
+// timerWheel は予定時刻まで待たせるタスクを持つタイマーホイール
+// 予定時刻を tick 単位に丸めてスロットに振り分け、1周より先の予定は残りの周回数を持たせる。
+// タスクごとにタイマーを作らずに済むため、大量の遅延タスクでも登録・取り出しは O(1) で済む。
+// 予定時刻より前に取り出すことはないが、tick の切り上げと進める間隔の分（最大で tick の2倍）遅れることがある
+type timerWheel struct {
+	mu      sync.Mutex
+	tick    time.Duration
+	slots   []map[uint64]*delayedTask
+	pos     int       // 現在のスロット
+	current time.Time // 現在のスロットの時刻（これより前の予定はすべて取り出し済み）
+	seq     uint64
+	entries map[uint64]*delayedTask // 一括操作用（登録番号ごと）
+}
+
+// delayedTask は予定時刻まで待っているタスク
+type delayedTask struct {
+	seq    uint64
+	task   Task
+	runAt  time.Time
+	slot   int
+	rounds int // 残りの周回数
+}
+
+func newTimerWheel(tick time.Duration, slots int) *timerWheel {
+	w := &timerWheel{
+		tick:    tick,
+		slots:   make([]map[uint64]*delayedTask, slots),
+		current: time.Now().Truncate(tick),
+		entries: make(map[uint64]*delayedTask),
+	}
+	for i := range w.slots {
+		w.slots[i] = make(map[uint64]*delayedTask)
+	}
+	return w
+}
+
+// add はタスクを予定時刻のスロットに登録する
+func (w *timerWheel) add(task Task, runAt time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seq++
+	entry := &delayedTask{seq: w.seq, task: task, runAt: runAt}
+	w.place(entry)
+	w.entries[entry.seq] = entry
+}
+
+// place は予定時刻より前に取り出されないよう、tick 単位で切り上げたスロットに置く（呼び出し側でロックを保持）
+func (w *timerWheel) place(entry *delayedTask) {
+	ticks := max(1, int((entry.runAt.Sub(w.current)+w.tick-1)/w.tick))
+	entry.slot = (w.pos + ticks) % len(w.slots)
+	entry.rounds = (ticks - 1) / len(w.slots)
+	w.slots[entry.slot][entry.seq] = entry
+}
+
+// advance は now までスロットを進め、予定時刻になったタスクを予定時刻の順に返す
+func (w *timerWheel) advance(now time.Time) []Task {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	behind := int(now.Sub(w.current) / w.tick)
+	if behind <= 0 {
+		return nil
+	}
+	if behind > len(w.slots) {
+		// 停止などで1周以上遅れた場合は、スロットを1つずつ進めずにすべて振り分け直す
+		return w.rebuild(now, behind)
+	}
+
+	var due []*delayedTask
+	for i := 0; i < behind; i++ {
+		w.current = w.current.Add(w.tick)
+		w.pos = (w.pos + 1) % len(w.slots)
+		due = append(due, w.expire(w.pos)...)
+	}
+	return sortedDue(due)
+}
+
+// rebuild は予定時刻を過ぎたタスクを取り出し、残りを現在の時刻から振り分け直す（呼び出し側でロックを保持）
+func (w *timerWheel) rebuild(now time.Time, behind int) []Task {
+	w.current = w.current.Add(time.Duration(behind) * w.tick)
+	for i := range w.slots {
+		w.slots[i] = make(map[uint64]*delayedTask)
+	}
+
+	var due []*delayedTask
+	for seq, entry := range w.entries {
+		if !entry.runAt.After(now) {
+			delete(w.entries, seq)
+			due = append(due, entry)
+			continue
+		}
+		w.place(entry)
+	}
+	return sortedDue(due)
+}
+
+// expire はスロットのうち周回数が残っていないタスクを取り出し、残りは周回数を減らす（呼び出し側でロックを保持）
+func (w *timerWheel) expire(slot int) []*delayedTask {
+	var due []*delayedTask
+	for seq, entry := range w.slots[slot] {
+		if entry.rounds > 0 {
+			entry.rounds--
+			continue
+		}
+		delete(w.slots[slot], seq)
+		delete(w.entries, seq)
+		due = append(due, entry)
+	}
+	return due
+}
+
+// sortedDue は取り出したタスクを予定時刻・登録の順に並べる
+func sortedDue(due []*delayedTask) []Task {
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].runAt.Equal(due[j].runAt) {
+			return due[i].runAt.Before(due[j].runAt)
+		}
+		return due[i].seq < due[j].seq
+	})
+	tasks := make([]Task, len(due))
+	for i, entry := range due {
+		tasks[i] = entry.task
+	}
+	return tasks
+}
+
+// removeWhere は条件に一致する待機中のタスクを取り出す
+func (w *timerWheel) removeWhere(match func(Task) bool) []Task {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var removed []Task
+	for seq, entry := range w.entries {
+		if match(entry.task) {
+			removed = append(removed, entry.task)
+			delete(w.slots[entry.slot], seq)
+			delete(w.entries, seq)
+		}
+	}
+	return removed
+}
+
+// updateWhere は条件に一致する待機中のタスクを書き換え、書き換えた件数を返す
+func (w *timerWheel) updateWhere(match func(Task) bool, update func(*Task)) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	updated := 0
+	for _, entry := range w.entries {
+		if match(entry.task) {
+			update(&entry.task)
+			updated++
+		}
+	}
+	return updated
+}
+
+// len は待機中のタスク数を返す
+func (w *timerWheel) len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.entries)
+}
+
+// AddTaskAt はタスクを runAt まで待たせてから投入する（runAt が過ぎている場合はすぐに投入する）
+// アドミッション制御・負荷制御などの受付の判定は予定時刻に行う。
+// 停止中も予定は保持し、再開後に予定時刻を過ぎていたタスクはすぐに投入する。
+// ストアを設定している場合は予定時刻とともに保存し、再起動後も予定どおり投入する
+func (wp *WorkerPool) AddTaskAt(task Task, runAt time.Time) error {
+	if !time.Now().Before(runAt) {
+		return wp.AddTask(task)
+	}
+	if state := wp.lifecycle.current(); state == StateDraining || state == StateStopped {
+		event("task.rejected").taskOf(task).failed(ErrPoolStopped).logf("🚫 タスク %d (%s) の受付を拒否しました: %v\n", task.ID, task.Name, ErrPoolStopped)
+		closeStream(task)
+		return ErrPoolStopped
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+
+	// 予定時刻を次回実行予定として保存し、再起動後の復元で待たせ直す
+	task.nextRetryAt = runAt
+	wp.persist(task, TaskStatePending, nil)
+	task.nextRetryAt = time.Time{}
+	wp.delayed.add(task, runAt)
+	event("task.delayed").taskOf(task).logf("🕰️ タスク %d (%s) を %s に投入します\n", task.ID, task.Name, runAt.Format(time.RFC3339))
+	return nil
+}
+
+// AddTaskAfter はタスクを delay だけ待たせてから投入する
+func (wp *WorkerPool) AddTaskAfter(task Task, delay time.Duration) error {
+	return wp.AddTaskAt(task, time.Now().Add(delay))
+}
+
+// AddTaskAt はタスクをタイプのサブプールで runAt まで待たせてから投入する
+func (tp *TypedPool) AddTaskAt(task Task, runAt time.Time) error {
+	tp.record(task)
+	pool, exists := tp.SubPool(task.Type)
+	if !exists {
+		closeStream(task)
+		return fmt.Errorf("タスクタイプ %s のサブプールが定義されていません", task.Type)
+	}
+	return pool.AddTaskAt(task, runAt)
+}
+
+// AddTaskAfter はタスクをタイプのサブプールで delay だけ待たせてから投入する
+func (tp *TypedPool) AddTaskAfter(task Task, delay time.Duration) error {
+	return tp.AddTaskAt(task, time.Now().Add(delay))
+}
+
+// DelayedCount は予定時刻を待っているタスク数を返す
+func (wp *WorkerPool) DelayedCount() int {
+	return wp.delayed.len()
+}
+
+// delayedReleaser は tick ごとにタイマーホイールを進め、予定時刻になったタスクを投入する
+func (wp *WorkerPool) delayedReleaser() {
+	defer wp.bgWg.Done()
+
+	ticker := time.NewTicker(wp.delayed.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, task := range wp.delayed.advance(now) {
+				wp.releaseDelayed(task)
+			}
+		case <-wp.shutdownCh:
+			return
+		}
+	}
+}
+
+// releaseDelayed は予定時刻になったタスクを投入する
+// AddTaskAt で受付済みのタスクのため停止処理中も投入し（Shutdown はその完了を待つ）、
+// 受付の判定で拒否された・停止したため投入できなかったタスクは失われないようDLQに記録する
+func (wp *WorkerPool) releaseDelayed(task Task) {
+	task = wp.startTaskSpan(task)
+	err := wp.acceptTask(task)
+	if err == nil {
+		return
+	}
+	endTaskSpan(task, err)
+	closeStream(task)
+	event("task.delayed_rejected").taskOf(task).failed(err).logf("⚠️ 予定時刻になったタスク %d を投入できなかったためDLQに記録しました: %v\n", task.ID, err)
+	switch {
+	case errors.Is(err, ErrTaskShed):
+		// 負荷制御で破棄したタスクは shed でDLQに記録済み
+	case errors.Is(err, ErrPoolStopped):
+		wp.dlq.Add(task, DeadLetterShutdown, err)
+	default:
+		wp.dlq.Add(task, DeadLetterRejected, err)
+	}
+}
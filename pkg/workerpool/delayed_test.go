@@ -0,0 +1,69 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newDelayedTestPool(t *testing.T, ran *atomic.Int32) *WorkerPool {
+	t.Helper()
+	pool := NewWorkerPool(1)
+	pool.RegisterProcessor("noop", func(ctx context.Context, task Task) error {
+		ran.Add(1)
+		return nil
+	})
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return pool
+}
+
+// Shutdown は予定時刻を待っているタスクが実行されるまで待つ
+func TestShutdownWaitsForDelayedTasks(t *testing.T) {
+	var ran atomic.Int32
+	pool := newDelayedTestPool(t, &ran)
+	if err := pool.AddTaskAfter(Task{ID: 1, Type: "noop"}, 300*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if pool.IsIdle() {
+		t.Fatal("予定時刻を待っているタスクがあるのにアイドルと判定されました")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	unfinished, err := pool.Shutdown(ctx)
+	if err != nil || len(unfinished) != 0 {
+		t.Fatalf("Shutdown = (%v, %v), want (nil, nil)", unfinished, err)
+	}
+	if got := ran.Load(); got != 1 {
+		t.Fatalf("実行されたタスク数 = %d, want 1", got)
+	}
+	if got := len(pool.DeadLetters()); got != 0 {
+		t.Fatalf("DLQ = %d 件, want 0", got)
+	}
+}
+
+// 期限までに予定時刻にならなかったタスクは未完了として返す
+func TestShutdownReturnsPendingDelayedTasks(t *testing.T) {
+	var ran atomic.Int32
+	pool := newDelayedTestPool(t, &ran)
+	if err := pool.AddTaskAfter(Task{ID: 1, Type: "noop"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	unfinished, err := pool.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if len(unfinished) != 1 || unfinished[0].ID != 1 {
+		t.Fatalf("unfinished = %v, want [task 1]", unfinished)
+	}
+	if got := pool.DelayedCount(); got != 0 {
+		t.Fatalf("DelayedCount = %d, want 0", got)
+	}
+}
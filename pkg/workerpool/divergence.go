@@ -0,0 +1,121 @@
+package workerpool
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DivergenceReport はシャドー実行・カナリアと現行のプロセッサの乖離レポート
+type DivergenceReport struct {
+	Shadow      map[TaskType]ShadowDivergence `json:"shadow,omitempty"`
+	Canary      map[TaskType]CanaryDivergence `json:"canary,omitempty"`
+	GeneratedAt time.Time                     `json:"generated_at"`
+}
+
+// ShadowDivergence は本番とシャドーの結果の乖離
+type ShadowDivergence struct {
+	Executed       int64   `json:"executed"`
+	Mismatched     int64   `json:"mismatched"`
+	MismatchRate   float64 `json:"mismatch_rate"` // 0〜1
+	PrimaryAvgTime float64 `json:"primary_avg_time_ms"`
+	ShadowAvgTime  float64 `json:"shadow_avg_time_ms"`
+	LatencyDelta   float64 `json:"latency_delta_ms"` // シャドー − 本番
+}
+
+// CanaryDivergence は現行版とカナリアの失敗率・処理時間の差
+type CanaryDivergence struct {
+	StableTotal       int64   `json:"stable_total"`
+	CanaryTotal       int64   `json:"canary_total"`
+	StableFailureRate float64 `json:"stable_failure_rate"` // 0〜1
+	CanaryFailureRate float64 `json:"canary_failure_rate"` // 0〜1
+	FailureRateDelta  float64 `json:"failure_rate_delta"`  // カナリア − 現行版
+	StableAvgTime     float64 `json:"stable_avg_time_ms"`
+	CanaryAvgTime     float64 `json:"canary_avg_time_ms"`
+	LatencyDelta      float64 `json:"latency_delta_ms"` // カナリア − 現行版
+}
+
+// buildDivergenceReport はシャドーとカナリアの集計からレポートを作成する（どちらもない場合は nil）
+func buildDivergenceReport(shadow map[TaskType]ShadowStats, canary map[TaskType]map[string]TaskTypeStats) *DivergenceReport {
+	if len(shadow) == 0 && len(canary) == 0 {
+		return nil
+	}
+
+	report := &DivergenceReport{GeneratedAt: time.Now()}
+	if len(shadow) > 0 {
+		report.Shadow = make(map[TaskType]ShadowDivergence, len(shadow))
+		for taskType, s := range shadow {
+			d := ShadowDivergence{
+				Executed:       s.Executed,
+				Mismatched:     s.Mismatched,
+				PrimaryAvgTime: s.PrimaryAvgTime,
+				ShadowAvgTime:  s.ShadowAvgTime,
+				LatencyDelta:   s.ShadowAvgTime - s.PrimaryAvgTime,
+			}
+			if s.Executed > 0 {
+				d.MismatchRate = float64(s.Mismatched) / float64(s.Executed)
+			}
+			report.Shadow[taskType] = d
+		}
+	}
+	if len(canary) > 0 {
+		report.Canary = make(map[TaskType]CanaryDivergence, len(canary))
+		for taskType, variants := range canary {
+			stable, canaryStats := variants[VariantStable], variants[VariantCanary]
+			d := CanaryDivergence{
+				StableTotal:       stable.Total,
+				CanaryTotal:       canaryStats.Total,
+				StableFailureRate: failureRate(stable),
+				CanaryFailureRate: failureRate(canaryStats),
+				StableAvgTime:     stable.AvgTime,
+				CanaryAvgTime:     canaryStats.AvgTime,
+			}
+			d.FailureRateDelta = d.CanaryFailureRate - d.StableFailureRate
+			if stable.Total > 0 && canaryStats.Total > 0 {
+				d.LatencyDelta = canaryStats.AvgTime - stable.AvgTime
+			}
+			report.Canary[taskType] = d
+		}
+	}
+	return report
+}
+
+// failureRate は失敗率（0〜1）を返す
+func failureRate(stats TaskTypeStats) float64 {
+	if stats.Total == 0 {
+		return 0
+	}
+	return float64(stats.Failed) / float64(stats.Total)
+}
+
+// DivergenceReport は現時点の乖離レポートを返す（シャドー・カナリアが未設定の場合は nil）
+// シャドーの集計は定期更新を待たずにプールから直接取得する
+func (m *Monitor) DivergenceReport() *DivergenceReport {
+	stats := m.GetStats()
+	return buildDivergenceReport(m.pool.Snapshot().Shadow, stats.CanaryStats)
+}
+
+// handleDivergence は GET /divergence で乖離レポートを返す
+func (m *Monitor) handleDivergence(w http.ResponseWriter, r *http.Request) {
+	report := m.DivergenceReport()
+	if report == nil {
+		report = &DivergenceReport{GeneratedAt: time.Now()}
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// printDivergence は乖離レポートをコンソールに表示する
+func printDivergence(report *DivergenceReport) {
+	if report == nil {
+		return
+	}
+	fmt.Println("\n🔬 乖離レポート:")
+	for taskType, d := range report.Shadow {
+		fmt.Printf("  [%s/shadow] 実行:%d 不一致:%d (%.1f%%) 処理時間差:%+.1fms\n",
+			taskType, d.Executed, d.Mismatched, d.MismatchRate*100, d.LatencyDelta)
+	}
+	for taskType, d := range report.Canary {
+		fmt.Printf("  [%s/canary] 失敗率差:%+.1f%% 処理時間差:%+.1fms (現行 %d件 / カナリア %d件)\n",
+			taskType, d.FailureRateDelta*100, d.LatencyDelta, d.StableTotal, d.CanaryTotal)
+	}
+}
@@ -0,0 +1,349 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDeadLetterNotFound は指定したIDのDLQエントリがない場合のエラー
+var ErrDeadLetterNotFound = errors.New("DLQのエントリが見つかりません")
+
+// deadLetterSeq はDLQエントリのID（TypedPool のサブプール間でも重複しないようプロセス全体で採番）
+var deadLetterSeq atomic.Uint64
+
+// maxAttemptHistory はタスクごとに保持する失敗履歴の上限
+const maxAttemptHistory = 20
+
+// DeadLetterReason はDLQに送られた理由
+type DeadLetterReason string
+
+const (
+	DeadLetterFailed    DeadLetterReason = "failed"    // リトライ上限に達して失敗
+	DeadLetterShed      DeadLetterReason = "shed"      // 負荷制御により破棄
+	DeadLetterShutdown  DeadLetterReason = "shutdown"  // 停止時に未処理のまま残った
+	DeadLetterCanceled  DeadLetterReason = "canceled"  // 管理操作でリトライを取り消した
+	DeadLetterCorrupt   DeadLetterReason = "corrupt"   // 内容がチェックサムと一致しない（永続化・退避の途中で破損した）
+	DeadLetterUntrusted DeadLetterReason = "untrusted" // 信頼する発行元の署名がない
+	DeadLetterRejected  DeadLetterReason = "rejected"  // 予定時刻の受付の判定で拒否された（AddTaskAt）
+)
+
+// DeadLetter はDLQに送られたタスクの記録
+type DeadLetter struct {
+	ID      uint64           `json:"id"`
+	Task    Task             `json:"task"`
+	Reason  DeadLetterReason `json:"reason"`
+	Error   string           `json:"error"`
+	History []AttemptError   `json:"history,omitempty"` // 試行ごとの失敗履歴（古い順）
+	AddedAt time.Time        `json:"added_at"`
+}
+
+// AttemptError は1回の試行の失敗記録
+type AttemptError struct {
+	Attempt int       `json:"attempt"` // 何回目の試行か（1始まり）
+	Error   string    `json:"error"`
+	At      time.Time `json:"at"`
+}
+
+// recordAttemptError は試行の失敗を履歴に追加する（上限を超えると古いものから破棄）
+func (t *Task) recordAttemptError(err error, at time.Time) {
+	history := append(t.history[:len(t.history):len(t.history)], AttemptError{
+		Attempt: t.AttemptCount + 1,
+		Error:   err.Error(),
+		At:      at,
+	})
+	if len(history) > maxAttemptHistory {
+		history = history[len(history)-maxAttemptHistory:]
+	}
+	t.history = history
+}
+
+// DeadLetterQueue は処理できなかったタスクを保持する（容量を超えると古いものから破棄）
+type DeadLetterQueue struct {
+	mu       sync.Mutex
+	entries  []DeadLetter
+	capacity int
+}
+
+// NewDeadLetterQueue は新しいDLQを作成
+func NewDeadLetterQueue(capacity int) *DeadLetterQueue {
+	return &DeadLetterQueue{capacity: capacity}
+}
+
+// Add はタスクをDLQに追加
+func (q *DeadLetterQueue) Add(task Task, reason DeadLetterReason, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry := DeadLetter{
+		ID:      deadLetterSeq.Add(1),
+		Task:    task,
+		Reason:  reason,
+		History: append([]AttemptError(nil), task.history...),
+		AddedAt: time.Now(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	q.entries = append(q.entries, entry)
+	if len(q.entries) > q.capacity {
+		q.entries = q.entries[len(q.entries)-q.capacity:]
+	}
+}
+
+// List はDLQの内容を古い順に返す
+func (q *DeadLetterQueue) List() []DeadLetter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := make([]DeadLetter, len(q.entries))
+	copy(entries, q.entries)
+	return entries
+}
+
+// Len はDLQ内のタスク数を返す
+func (q *DeadLetterQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.entries)
+}
+
+// Get はIDでDLQのエントリを返す
+func (q *DeadLetterQueue) Get(id uint64) (DeadLetter, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, entry := range q.entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	return DeadLetter{}, false
+}
+
+// Remove は指定したIDのエントリをDLQから取り除いて返す（存在しないIDは無視する）
+func (q *DeadLetterQueue) Remove(ids ...uint64) []DeadLetter {
+	want := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var removed []DeadLetter
+	kept := q.entries[:0]
+	for _, entry := range q.entries {
+		if want[entry.ID] {
+			removed = append(removed, entry)
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+	// 取り除いた分の参照を残さない
+	for i := len(kept); i < len(q.entries); i++ {
+		q.entries[i] = DeadLetter{}
+	}
+	q.entries = kept
+	return removed
+}
+
+// restore は再投入に失敗したエントリを元のIDのままDLQに戻す
+func (q *DeadLetterQueue) restore(entries []DeadLetter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.entries = append(q.entries, entries...)
+	sort.SliceStable(q.entries, func(i, j int) bool { return q.entries[i].ID < q.entries[j].ID })
+	if len(q.entries) > q.capacity {
+		q.entries = q.entries[len(q.entries)-q.capacity:]
+	}
+}
+
+// DeadLetter はIDでDLQのエントリを返す
+func (wp *WorkerPool) DeadLetter(id uint64) (DeadLetter, bool) {
+	return wp.dlq.Get(id)
+}
+
+// RequeueDeadLetters は指定したDLQのエントリを試行回数をリセットしてキューに戻し、戻した件数を返す
+// 失敗履歴は引き継ぐ。存在しないIDは無視する
+func (wp *WorkerPool) RequeueDeadLetters(ids []uint64) (int, error) {
+	entries := wp.dlq.Remove(ids...)
+	for i, entry := range entries {
+		task := entry.Task
+		task.AttemptCount = 0
+		task.FirstAttempt = time.Time{}
+		task.nextRetryAt = time.Time{}
+		task.LastError = nil
+		task.fenceToken = 0
+
+		wp.persist(task, TaskStatePending, nil)
+		if err := wp.enqueue(task); err != nil {
+			wp.dlq.restore(entries[i:])
+			return i, ErrPoolStopped
+		}
+		event("dlq.requeued").taskOf(task).logf("📤 DLQのタスク %d をキューに戻しました (エントリ %d)\n", task.ID, entry.ID)
+	}
+	return len(entries), nil
+}
+
+// PurgeDeadLetters は指定したDLQのエントリを削除し、削除した件数を返す
+func (wp *WorkerPool) PurgeDeadLetters(ids []uint64) int {
+	removed := wp.dlq.Remove(ids...)
+	if len(removed) > 0 {
+		event("dlq.purged").logf("🧹 DLQから %d 件を削除しました\n", len(removed))
+	}
+	return len(removed)
+}
+
+// DeadLetters はすべてのサブプールのDLQの内容を古い順に返す
+func (tp *TypedPool) DeadLetters() []DeadLetter {
+	var entries []DeadLetter
+	for _, pool := range tp.subPools() {
+		entries = append(entries, pool.DeadLetters()...)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// RequeueDeadLetters は各サブプールのDLQから指定したエントリをキューに戻す
+func (tp *TypedPool) RequeueDeadLetters(ids []uint64) (int, error) {
+	total := 0
+	for _, pool := range tp.subPools() {
+		n, err := pool.RequeueDeadLetters(ids)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// PurgeDeadLetters は各サブプールのDLQから指定したエントリを削除する
+func (tp *TypedPool) PurgeDeadLetters(ids []uint64) int {
+	total := 0
+	for _, pool := range tp.subPools() {
+		total += pool.PurgeDeadLetters(ids)
+	}
+	return total
+}
+
+// deadLetterIDsRequest は DLQ の一括操作で受け付けるID
+type deadLetterIDsRequest struct {
+	IDs []uint64 `json:"ids"`
+}
+
+// handleDeadLetters は DLQ の一覧と一括操作を扱う
+//
+//	GET  /dlq          一覧（古い順）
+//	POST /dlq/requeue  {"ids": [...]} をキューに戻す
+//	POST /dlq/purge    {"ids": [...]} を削除する
+func (m *Monitor) handleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+
+	entries := m.pool.DeadLetters()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":        len(entries),
+		"dead_letters": entries,
+	})
+}
+
+// handleDeadLetterAction は DLQ のエントリを個別または一括で操作する
+//
+//	GET    /dlq/{id}          詳細（ペイロードと失敗履歴）
+//	POST   /dlq/{id}/requeue  キューに戻す
+//	DELETE /dlq/{id}          削除する
+//	POST   /dlq/requeue       {"ids": [...]} を一括でキューに戻す
+//	POST   /dlq/purge         {"ids": [...]} を一括で削除する
+func (m *Monitor) handleDeadLetterAction(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/dlq/")
+	if rest == "requeue" || rest == "purge" {
+		m.handleDeadLetterBulk(w, r, rest)
+		return
+	}
+
+	idPart, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "エントリIDが不正です: "+idPart)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		for _, entry := range m.pool.DeadLetters() {
+			if entry.ID == id {
+				writeJSON(w, http.StatusOK, entry)
+				return
+			}
+		}
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("エントリ %d: %v", id, ErrDeadLetterNotFound))
+	case action == "" && r.Method == http.MethodDelete:
+		if m.pool.PurgeDeadLetters([]uint64{id}) == 0 {
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("エントリ %d: %v", id, ErrDeadLetterNotFound))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": id, "status": "purged"})
+	case action == "requeue" && r.Method == http.MethodPost:
+		n, err := m.pool.RequeueDeadLetters([]uint64{id})
+		switch {
+		case err != nil:
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+		case n == 0:
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("エントリ %d: %v", id, ErrDeadLetterNotFound))
+		default:
+			writeJSON(w, http.StatusOK, map[string]interface{}{"id": id, "status": "requeued"})
+		}
+	case action == "":
+		w.Header().Set("Allow", "GET, DELETE")
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET または DELETE のみ対応しています")
+	case action == "requeue":
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "POST のみ対応しています")
+	default:
+		writeJSONError(w, http.StatusNotFound, "不明な操作です: "+action)
+	}
+}
+
+// handleDeadLetterBulk は指定したIDのエントリを一括で再投入または削除する
+func (m *Monitor) handleDeadLetterBulk(w http.ResponseWriter, r *http.Request, action string) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "POST のみ対応しています")
+		return
+	}
+
+	var req deadLetterIDsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTaskRequestBytes)).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "リクエストの形式が不正です: "+err.Error())
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "ids を指定してください")
+		return
+	}
+
+	if action == "purge" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"purged": m.pool.PurgeDeadLetters(req.IDs)})
+		return
+	}
+	n, err := m.pool.RequeueDeadLetters(req.IDs)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"requeued": n, "error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"requeued": n})
+}
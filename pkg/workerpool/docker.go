@@ -0,0 +1,151 @@
+package workerpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// コンテナ実行エラーのプレフィックス（一時エラーは RetryableError、恒久エラーは PermanentError で包む）
+const (
+	containerTransientError = "コンテナ一時エラー" // デーモンの障害や強制終了など、再実行で回復しうるもの
+	containerPermanentError = "コンテナ実行エラー" // コマンドの失敗など、再実行しても変わらないもの
+)
+
+// DockerTask はコンテナで実行するタスクのペイロード
+type DockerTask struct {
+	Image string            `json:"image"`
+	Args  []string          `json:"args"`
+	Env   map[string]string `json:"env"`
+}
+
+// DockerConfig は DockerProcessor の設定
+type DockerConfig struct {
+	Binary             string   `json:"binary"`               // docker コマンドのパス（空の場合は "docker"）
+	RunArgs            []string `json:"run_args"`             // docker run に追加する引数（--memory, --network など）
+	RetryableExitCodes []int    `json:"retryable_exit_codes"` // 一時エラーとして扱う終了コード（空の場合は 125, 137）
+	// KillGrace はタイムアウト・キャンセル時に docker stop で SIGTERM を送ってから強制終了するまでの猶予（0 の場合は docker kill ですぐに強制終了する）
+	KillGrace time.Duration `json:"kill_grace_ns"`
+}
+
+// dockerImageReference はイメージの参照（[レジストリ/]名前[:タグ][@ダイジェスト]）の形式
+// タスクの投入元が指定する値のため、docker run のフラグとして解釈されうる値はここで弾く
+var dockerImageReference = regexp.MustCompile(`^` +
+	`(?:(?:[A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9-]*[A-Za-z0-9])(?:\.(?:[A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9-]*[A-Za-z0-9]))*(?::[0-9]+)?/)?` +
+	`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+	`(?::[A-Za-z0-9_][A-Za-z0-9_.-]{0,127})?` +
+	`(?:@[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,})?$`)
+
+// defaultRetryableExitCodes はデーモンのエラー（125）と強制終了・OOM（137）
+var defaultRetryableExitCodes = []int{125, 137}
+
+// NewDockerProcessor はタスクを使い捨てのコンテナとして実行するプロセッサを作成する
+// ペイロードは DockerTask（または同じ形のJSON）で、コンテナの出力は TaskOutput に書き込まれる
+// 終了コードは一時エラー（containerTransientError）と恒久エラー（containerPermanentError）に変換する
+func NewDockerProcessor(config DockerConfig) TaskProcessor {
+	if config.Binary == "" {
+		config.Binary = "docker"
+	}
+	if len(config.RetryableExitCodes) == 0 {
+		config.RetryableExitCodes = defaultRetryableExitCodes
+	}
+
+	return func(ctx context.Context, task Task) error {
+		spec, err := decodeDockerTask(task.Payload)
+		if err != nil {
+			return Permanent(fmt.Errorf("%s: ペイロードが不正です: %v", containerPermanentError, err))
+		}
+
+		name := fmt.Sprintf("workerpool-task-%d-%d-%d", task.ID, task.AttemptCount, time.Now().UnixNano())
+		args := append([]string{"run", "--rm", "--name", name}, config.RunArgs...)
+		keys := make([]string, 0, len(spec.Env))
+		for key := range spec.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			args = append(args, "-e", key+"="+spec.Env[key])
+		}
+		args = append(args, "--", spec.Image) // 以降はフラグとして解釈させない
+		args = append(args, spec.Args...)
+
+		output := TaskOutput(ctx)
+		cmd := exec.CommandContext(ctx, config.Binary, args...)
+		cmd.Stdout = output
+		cmd.Stderr = output
+		cmd.Cancel = func() error {
+			// CLI を止めてもコンテナは動き続けるため、コンテナ自体を停止する
+			stopContainer(config, name)
+			return cmd.Process.Kill()
+		}
+		// コンテナが止まらない場合も、出力のパイプを閉じて戻る
+		cmd.WaitDelay = config.KillGrace + killWaitMargin
+
+		err = cmd.Run()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return containerError(err, config.RetryableExitCodes)
+	}
+}
+
+// stopContainer はコンテナを停止する（KillGrace を指定した場合は猶予の間に終了処理を待ち、過ぎれば強制終了する）
+func stopContainer(config DockerConfig, name string) {
+	if config.KillGrace <= 0 {
+		exec.Command(config.Binary, "kill", name).Run()
+		return
+	}
+	seconds := int((config.KillGrace + time.Second - 1) / time.Second)
+	if err := exec.Command(config.Binary, "stop", "--time", fmt.Sprint(seconds), name).Run(); err != nil {
+		exec.Command(config.Binary, "kill", name).Run()
+	}
+}
+
+// decodeDockerTask はペイロードをJSON経由で DockerTask に変換する
+func decodeDockerTask(payload interface{}) (DockerTask, error) {
+	var spec DockerTask
+	if task, ok := payload.(DockerTask); ok {
+		spec = task
+	} else {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return spec, err
+		}
+		if err := json.Unmarshal(data, &spec); err != nil {
+			return spec, err
+		}
+	}
+	if spec.Image == "" {
+		return spec, errors.New("image を指定してください")
+	}
+	if !dockerImageReference.MatchString(spec.Image) {
+		return spec, fmt.Errorf("image %q はイメージの参照として不正です", spec.Image)
+	}
+	return spec, nil
+}
+
+// containerError は docker run の終了状態をリトライ可否の分かるエラーに変換する
+func containerError(err error, retryableExitCodes []int) error {
+	if err == nil {
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		// docker コマンドが見つからない・起動できない
+		return Retryable(fmt.Errorf("%s: docker を実行できません: %v", containerTransientError, err))
+	}
+
+	code := exitErr.ExitCode()
+	for _, retryable := range retryableExitCodes {
+		if code == retryable {
+			return Retryable(fmt.Errorf("%s: 終了コード %d", containerTransientError, code))
+		}
+	}
+	return Permanent(fmt.Errorf("%s: 終了コード %d", containerPermanentError, code))
+}
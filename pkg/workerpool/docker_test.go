@@ -0,0 +1,69 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// イメージの参照として不正な値（docker run のフラグになりうる値を含む）を弾く
+func TestDecodeDockerTaskImage(t *testing.T) {
+	tests := []struct {
+		image string
+		valid bool
+	}{
+		{"alpine", true},
+		{"alpine:3.20", true},
+		{"library/alpine:latest", true},
+		{"ghcr.io/hizzuu/worker:v1.2.3", true},
+		{"localhost:5000/tools/report_builder", true},
+		{"alpine@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", true},
+		{"", false},
+		{"--privileged", false},
+		{"-v=/:/host", false},
+		{"-it", false},
+		{"Alpine", false},
+		{"alpine latest", false},
+		{"alpine:--rm", false},
+		{"alpine:", false},
+	}
+	for _, tt := range tests {
+		_, err := decodeDockerTask(map[string]interface{}{"image": tt.image})
+		if (err == nil) != tt.valid {
+			t.Errorf("image %q: err = %v, valid = %v", tt.image, err, tt.valid)
+		}
+	}
+}
+
+// イメージの前に -- を置いて docker run に渡し、不正なイメージでは docker を実行しない
+func TestDockerProcessorImageArgument(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	binary := writeSidecar(t, `printf '%s\n' "$@" > `+argsFile)
+
+	process := NewDockerProcessor(DockerConfig{Binary: binary, RunArgs: []string{"--network", "none"}})
+	ctx := context.Background()
+	if err := process(ctx, Task{ID: 1, Payload: DockerTask{Image: "alpine:3.20", Args: []string{"--version"}}}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := strings.Fields(string(data))
+	if got := strings.Join(args[len(args)-3:], " "); got != "-- alpine:3.20 --version" {
+		t.Fatalf("docker run の末尾の引数 = %q, want %q", got, "-- alpine:3.20 --version")
+	}
+
+	os.Remove(argsFile)
+	err = process(ctx, Task{ID: 2, Payload: map[string]interface{}{"image": "--privileged", "args": []string{"alpine"}}})
+	if !IsPermanent(err) {
+		t.Fatalf("err = %v, want PermanentError", err)
+	}
+	if _, statErr := os.Stat(argsFile); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatalf("不正なイメージで docker が実行されました: %v", statErr)
+	}
+}
@@ -0,0 +1,187 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PlanDecision はドライランでの受付判定
+type PlanDecision string
+
+const (
+	PlanQueued   PlanDecision = "queued"   // キューに投入される
+	PlanDeferred PlanDecision = "deferred" // 負荷制御で保留される
+	PlanHeld     PlanDecision = "held"     // メンテナンスウィンドウ・ブラックアウト期間が終わるまで保留される
+	PlanShed     PlanDecision = "shed"     // 過負荷のため破棄される
+	PlanRejected PlanDecision = "rejected" // 受付を拒否される
+)
+
+// defaultQueueName は共有キューの名前（TypedPool ではタスクタイプ名になる）
+const defaultQueueName = "default"
+
+// SubmissionPlan はタスクを投入した場合の処理計画（実行はしない）
+type SubmissionPlan struct {
+	TaskID            int           `json:"task_id"`
+	TaskType          TaskType      `json:"task_type"`
+	Decision          PlanDecision  `json:"decision"`
+	Reason            string        `json:"reason,omitempty"` // 拒否・破棄・保留の理由
+	Queue             string        `json:"queue"`            // 投入先のキュー
+	RequestedPriority Priority      `json:"requested_priority"`
+	Priority          Priority      `json:"priority"` // アドミッション制御適用後の優先度
+	Degraded          bool          `json:"degraded"` // SLA超過で優先度を下げる
+	HasProcessor      bool          `json:"has_processor"`
+	Variant           string        `json:"variant,omitempty"` // カナリア設定中に使われるプロセッサの種別
+	Shadowed          bool          `json:"shadowed"`          // シャドー実行の対象
+	QueuePosition     int           `json:"queue_position"`    // 先に取り出されるキュー内のタスク数
+	AvailableWorkers  int           `json:"available_workers"` // すぐに取り出せるワーカー数（遅延起動の余地を含む）
+	DrainETA          time.Duration `json:"drain_eta_ns"`      // アドミッション制御が予測した完了時間（未判定は負の値）
+	PredictedWait     time.Duration `json:"predicted_wait_ns"` // 実行開始までの予測待ち時間（推定できない場合は負の値）
+}
+
+// Accepted はタスクが受け付けられる（キュー投入または保留）か判定
+func (p SubmissionPlan) Accepted() bool {
+	return p.Decision == PlanQueued || p.Decision == PlanDeferred || p.Decision == PlanHeld
+}
+
+// PlanTask はタスクを投入した場合の検証・ルーティング・スケジューリングの判定を返す
+// AddTask と同じ判定を行うが、キューへの投入やカウンタの更新は行わない
+func (wp *WorkerPool) PlanTask(task Task) SubmissionPlan {
+	plan := SubmissionPlan{
+		TaskID:            task.ID,
+		TaskType:          task.Type,
+		Queue:             defaultQueueName,
+		RequestedPriority: task.Priority,
+		Priority:          task.Priority,
+		DrainETA:          -1,
+		PredictedWait:     -1,
+	}
+
+	wp.mu.Lock()
+	entry, variant, exists := wp.selectEntryLocked(task)
+	plan.HasProcessor = exists && entry != nil
+	plan.Variant = variant
+	plan.AvailableWorkers = wp.idle + wp.workers - wp.running
+	wp.mu.Unlock()
+
+	wp.shadows.mu.Lock()
+	_, plan.Shadowed = wp.shadows.processors[task.Type]
+	wp.shadows.mu.Unlock()
+
+	if err := wp.checkMemory(false); err != nil {
+		return plan.reject(PlanRejected, err)
+	}
+	if err := wp.checkSelector(task); err != nil {
+		return plan.reject(PlanRejected, err)
+	}
+
+	verdict := wp.evaluateAdmission(task)
+	plan.DrainETA = verdict.eta
+	if verdict.err != nil {
+		if errors.Is(verdict.err, ErrTaskShed) {
+			return plan.reject(PlanShed, verdict.err)
+		}
+		return plan.reject(PlanRejected, verdict.err)
+	}
+	plan.Priority = verdict.task.Priority
+	plan.Degraded = verdict.degraded
+
+	if action, shedding := wp.shedAction(verdict.task); shedding {
+		if action == ShedDefer {
+			plan.Decision = PlanDeferred
+			plan.Reason = "高負荷のため負荷が下がるまで保留されます"
+			return plan
+		}
+		return plan.reject(PlanShed, ErrTaskShed)
+	}
+
+	if reason, end, paused := wp.pausedFor(task.Type, time.Now()); paused {
+		plan.Decision = PlanHeld
+		plan.Reason = fmt.Sprintf("%s のため %s まで保留されます", reason, end.Format(time.RFC3339))
+		if wait := time.Until(end); wait > 0 {
+			plan.PredictedWait = wait
+		}
+		return plan
+	}
+
+	plan.Decision = PlanQueued
+	plan.QueuePosition = wp.queue.countAhead(plan.Priority)
+	router := wp.queueRouter()
+	if q := router.route(verdict.task); q != nil {
+		// 名前付きキューのタスクはそのキューの専用ワーカーだけが取り出す
+		plan.Queue = q.config.Name
+		plan.QueuePosition = q.queue.countAhead(plan.Priority)
+		plan.AvailableWorkers = router.idleWorkers(q)
+	}
+	if plan.QueuePosition < plan.AvailableWorkers {
+		plan.PredictedWait = 0
+	} else if plan.DrainETA >= 0 {
+		// 予測完了時間をキュー全体で均等に割り振って概算する
+		if queued := wp.queuedTasks(); queued > 0 {
+			plan.PredictedWait = time.Duration(float64(plan.DrainETA) * float64(plan.QueuePosition) / float64(queued))
+		}
+	}
+	return plan
+}
+
+// reject は受け付けられない場合の判定を設定する
+func (p SubmissionPlan) reject(decision PlanDecision, err error) SubmissionPlan {
+	p.Decision = decision
+	p.Reason = err.Error()
+	return p
+}
+
+// PlanTask はタスクを投入した場合の処理計画を返す（実行はしない）
+func (tp *TypedPool) PlanTask(task Task) SubmissionPlan {
+	pool, exists := tp.SubPool(task.Type)
+	if !exists {
+		return SubmissionPlan{
+			TaskID:            task.ID,
+			TaskType:          task.Type,
+			Decision:          PlanRejected,
+			Reason:            fmt.Sprintf("タスクタイプ %s のサブプールが定義されていません", task.Type),
+			RequestedPriority: task.Priority,
+			Priority:          task.Priority,
+			DrainETA:          -1,
+			PredictedWait:     -1,
+		}
+	}
+
+	plan := pool.PlanTask(task)
+	plan.Queue = string(task.Type)
+	return plan
+}
+
+// PlanTask はプールの処理計画に、直近のスループットから求めた待ち時間の予測を加えて返す
+func (m *Monitor) PlanTask(task Task) SubmissionPlan {
+	plan := m.pool.PlanTask(task)
+	if plan.Decision != PlanQueued || plan.PredictedWait == 0 {
+		return plan
+	}
+
+	m.mutex.Lock()
+	wait, _, _ := m.estimateDrain(map[TaskType]int{task.Type: plan.QueuePosition}, time.Now())
+	m.mutex.Unlock()
+	if wait >= 0 {
+		plan.PredictedWait = wait
+	}
+	return plan
+}
+
+// PrintPlan は処理計画をコンソールに表示する
+func PrintPlan(plan SubmissionPlan) {
+	fmt.Printf("🧪 ドライラン: タスク %d (%s) → %s", plan.TaskID, plan.TaskType, plan.Decision)
+	if plan.Reason != "" {
+		fmt.Printf(" (%s)", plan.Reason)
+	}
+	fmt.Println()
+	if plan.Decision != PlanQueued {
+		return
+	}
+	wait := "不明"
+	if plan.PredictedWait >= 0 {
+		wait = plan.PredictedWait.Round(time.Millisecond).String()
+	}
+	fmt.Printf("  キュー:%s 優先度:%d→%d 先行タスク:%d 空きワーカー:%d 予測待ち時間:%s\n",
+		plan.Queue, plan.RequestedPriority, plan.Priority, plan.QueuePosition, plan.AvailableWorkers, wait)
+}
@@ -0,0 +1,169 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redactedValue は秘密情報の代わりに返す値
+const redactedValue = "REDACTED"
+
+// secretKeyMarkers は値を秘密情報として伏せる項目名（環境変数名を含む）に含まれる語（小文字で比較）
+var secretKeyMarkers = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "credential", "private_key", "authorization", "cookie"}
+
+// ConfigSource は実行中のインスタンスに適用した設定の出所と値（設定ファイル・コマンドラインの引数・環境変数など）
+type ConfigSource struct {
+	Name   string      `json:"name"`   // 出所（file:/etc/workerpool.json、flags など）
+	Values interface{} `json:"values"` // 適用した値（秘密情報は伏せる）
+}
+
+// EffectiveConfig は実行中のインスタンスが実際に使っている設定（GET /config で返す）
+// プールの値は設定の取り込み・API での変更をすべて反映した現在の値で、秘密情報は伏せる
+type EffectiveConfig struct {
+	Instance           *InstanceInfo        `json:"instance,omitempty"`
+	State              PoolState            `json:"state"`
+	Workers            int                  `json:"workers,omitempty"` // ワーカー数の上限（変更できないプールでは省略）
+	RunningWorkers     int                  `json:"running_workers"`
+	TaskTypes          []TaskCapability     `json:"task_types"` // タスクタイプごとの実行環境・タイムアウト・リトライポリシー
+	RateLimit          *RateLimitCapability `json:"rate_limit,omitempty"`
+	MaintenanceWindows []MaintenanceWindow  `json:"maintenance_windows"`
+	SLOs               []string             `json:"slos"`      // 監視しているSLOの名前
+	Templates          []string             `json:"templates"` // POST /tasks で使えるテンプレートの名前
+	AdminAuth          bool                 `json:"admin_auth"`
+	ReadOnly           bool                 `json:"read_only"`
+	Audited            bool                 `json:"audited"` // 設定変更を監査ログに記録している
+	Sources            []ConfigSource       `json:"sources"` // 取り込んだ順の設定の出所
+}
+
+// AddConfigSource は取り込んだ設定の出所と値を記録する（GET /config で秘密情報を伏せて返す）
+// 後から追加した出所ほど優先して適用したものとして、取り込んだ順に呼び出すこと
+func (m *Monitor) AddConfigSource(name string, values interface{}) {
+	source := ConfigSource{Name: name, Values: redactSecrets(values)}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.configSources = append(m.configSources, source)
+}
+
+// EffectiveConfig は実行中のインスタンスが使っている設定を返す
+func (m *Monitor) EffectiveConfig() EffectiveConfig {
+	capabilities := DescribeCapabilities(m.pool)
+	snapshot := m.pool.Snapshot()
+	config := EffectiveConfig{
+		Instance:           CurrentInstance(),
+		State:              snapshot.State,
+		RunningWorkers:     snapshot.RunningWorkers,
+		TaskTypes:          capabilities.TaskTypes,
+		RateLimit:          capabilities.RateLimit,
+		MaintenanceWindows: m.pool.MaintenanceWindows(),
+		SLOs:               []string{},
+		Templates:          []string{},
+		Audited:            auditedPool(m.pool) != nil,
+	}
+	if config.MaintenanceWindows == nil {
+		config.MaintenanceWindows = []MaintenanceWindow{}
+	}
+	if pool, ok := UnwrapPool(m.pool).(resizablePool); ok {
+		config.Workers = pool.MaxWorkers()
+	}
+
+	m.mutex.RLock()
+	templates := m.templates
+	config.AdminAuth = m.adminToken != ""
+	config.ReadOnly = m.readOnly
+	for _, tracker := range m.slos {
+		config.SLOs = append(config.SLOs, tracker.slo.Name)
+	}
+	config.Sources = append([]ConfigSource{}, m.configSources...)
+	m.mutex.RUnlock()
+
+	if templates != nil {
+		for _, template := range templates.List() {
+			config.Templates = append(config.Templates, template.Name)
+		}
+	}
+	return config
+}
+
+// redactSecrets は値を JSON の形に変換し、秘密情報らしい項目の値と URL の認証情報を伏せる
+func redactSecrets(values interface{}) interface{} {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil
+	}
+	return redactValue(decoded)
+}
+
+// redactValue は JSON の値を再帰的にたどって秘密情報を伏せる
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSecretKey(key) && item != nil && item != "" {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	case string:
+		return redactURL(v)
+	}
+	return value
+}
+
+// isSecretKey は項目名が秘密情報を表すかどうかを返す
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range secretKeyMarkers {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL は URL に含まれるパスワードと、秘密情報らしいクエリパラメータを伏せる（URL でなければそのまま返す）
+func redactURL(value string) string {
+	if !strings.Contains(value, "://") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return value
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), redactedValue)
+	}
+	query, redacted := u.Query(), false
+	for key := range query {
+		if isSecretKey(key) || strings.EqualFold(key, "key") || strings.EqualFold(key, "sig") {
+			query.Set(key, redactedValue)
+			redacted = true
+		}
+	}
+	if redacted {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// handleConfig は GET /config で実行中のインスタンスが実際に使っている設定を返す（秘密情報は伏せる）
+func (m *Monitor) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+	writeJSON(w, http.StatusOK, m.EffectiveConfig())
+}
@@ -0,0 +1,291 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrInvalidElasticsearch は Elasticsearch 出力の設定が不正な場合のエラー
+var ErrInvalidElasticsearch = errors.New("Elasticsearch の設定が不正です")
+
+// Elasticsearch 出力の既定値
+const (
+	defaultElasticsearchIndex     = "worker-results-{date}"
+	defaultElasticsearchBatch     = 500
+	defaultElasticsearchFlush     = 5 * time.Second
+	defaultElasticsearchMaxBuffer = 10000
+	elasticsearchTimeout          = 30 * time.Second
+)
+
+// ElasticsearchConfig は結果を Elasticsearch（OpenSearch）に登録する設定
+// Index には次の置換子を使える: {date}（終了日 YYYY.MM.DD, UTC）、{month}（YYYY.MM）、{type}（タスクタイプ）、{status}（success / failure）
+type ElasticsearchConfig struct {
+	URL           string        `json:"url"`               // 例: http://localhost:9200
+	Index         string        `json:"index"`             // インデックス名のテンプレート（空の場合は worker-results-{date}）
+	Username      string        `json:"username"`          // Basic 認証
+	Password      string        `json:"password"`          //
+	APIKey        string        `json:"api_key"`           // API キー（Username より優先）
+	Template      string        `json:"template"`          // 作成するインデックステンプレートの名前（空の場合は作成しない）
+	BatchSize     int           `json:"batch_size"`        // まとめて登録する件数（0 の場合は500）
+	FlushInterval time.Duration `json:"flush_interval_ns"` // 溜まった結果を登録する間隔（0 の場合は5秒）
+	MaxBuffered   int           `json:"max_buffered"`      // 登録できない間に保持する上限（超えた分は古い順に捨てる。0 の場合は10000）
+	Client        *http.Client  `json:"-"`                 // nil の場合は30秒でタイムアウトするクライアント
+}
+
+// resultDocument は Elasticsearch に登録する結果1件
+type resultDocument struct {
+	Timestamp       time.Time         `json:"@timestamp"` // 終了日時
+	TaskID          int               `json:"task_id"`
+	TaskName        string            `json:"task_name"`
+	TaskType        TaskType          `json:"task_type"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Instance        *InstanceInfo     `json:"instance,omitempty"`
+	Variant         string            `json:"variant,omitempty"`
+	Success         bool              `json:"success"`
+	Error           *ResultError      `json:"error,omitempty"`
+	DurationMs      float64           `json:"duration_ms"`
+	TotalDurationMs float64           `json:"total_duration_ms"`
+	AgeMs           float64           `json:"age_ms"`
+	AttemptCount    int               `json:"attempt_count"`
+	WorkerID        int               `json:"worker_id"`
+	StartTime       time.Time         `json:"start_time"`
+	CreatedAt       *time.Time        `json:"created_at,omitempty"`
+	Output          string            `json:"output,omitempty"`
+}
+
+// ElasticsearchSink は最終結果とエラーを Elasticsearch に登録する（ResultSink）
+// 結果はバッファに溜め、件数か間隔のどちらかに達したら Bulk API でまとめて登録する
+// 登録に失敗した結果は保持しておき、次の登録で再送する
+type ElasticsearchSink struct {
+	*resultBatcher
+	config ElasticsearchConfig
+	client *http.Client
+}
+
+// NewElasticsearchSink は Elasticsearch への出力を作成する
+// Template を指定した場合は、インデックスの対応付け（マッピング）をテンプレートとして登録してから開始する
+func NewElasticsearchSink(config ElasticsearchConfig) (*ElasticsearchSink, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("%w: url を指定してください", ErrInvalidElasticsearch)
+	}
+	if config.Index == "" {
+		config.Index = defaultElasticsearchIndex
+	}
+	if config.Index != strings.ToLower(config.Index) {
+		return nil, fmt.Errorf("%w: インデックス名は小文字で指定してください: %s", ErrInvalidElasticsearch, config.Index)
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultElasticsearchBatch
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultElasticsearchFlush
+	}
+	if config.MaxBuffered <= 0 {
+		config.MaxBuffered = defaultElasticsearchMaxBuffer
+	}
+
+	s := &ElasticsearchSink{config: config, client: config.Client}
+	if s.client == nil {
+		s.client = &http.Client{Timeout: elasticsearchTimeout}
+	}
+	if config.Template != "" {
+		if err := s.putTemplate(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	s.resultBatcher = newResultBatcher("Elasticsearch", config.BatchSize, config.MaxBuffered, config.FlushInterval, s.bulk)
+	return s, nil
+}
+
+// WriteResult は結果をバッファに追加する（登録はバックグラウンドで行う）
+func (s *ElasticsearchSink) WriteResult(result TaskResult) error {
+	action, err := json.Marshal(map[string]map[string]string{"index": {"_index": s.indexFor(result)}})
+	if err != nil {
+		return err
+	}
+	document, err := json.Marshal(newResultDocument(result))
+	if err != nil {
+		return err
+	}
+
+	// 操作行と文書の2行で1件
+	return s.add(append(append(action, '\n'), document...))
+}
+
+// Close は残りの結果を登録して出力を終了する
+func (s *ElasticsearchSink) Close() error {
+	return s.close(elasticsearchTimeout)
+}
+
+// indexFor は結果を登録するインデックス名を返す
+func (s *ElasticsearchSink) indexFor(result TaskResult) string {
+	end := result.EndTime.UTC()
+	status := "success"
+	if !result.Success {
+		status = "failure"
+	}
+	return strings.NewReplacer(
+		"{date}", end.Format("2006.01.02"),
+		"{month}", end.Format("2006.01"),
+		"{type}", strings.ToLower(string(result.TaskType)),
+		"{status}", status,
+	).Replace(s.config.Index)
+}
+
+// newResultDocument は結果を登録する文書に変換する
+func newResultDocument(result TaskResult) resultDocument {
+	document := resultDocument{
+		Timestamp:       result.EndTime,
+		TaskID:          result.TaskID,
+		TaskName:        result.TaskName,
+		TaskType:        result.TaskType,
+		Labels:          result.Labels,
+		Instance:        CurrentInstance(),
+		Variant:         result.Variant,
+		Success:         result.Success,
+		Error:           result.resultError(),
+		DurationMs:      durationToMs(result.Duration),
+		TotalDurationMs: durationToMs(result.TotalDuration),
+		AgeMs:           durationToMs(result.Age),
+		AttemptCount:    result.AttemptCount,
+		WorkerID:        result.WorkerID,
+		StartTime:       result.StartTime,
+		Output:          result.Output,
+	}
+	if !result.CreatedAt.IsZero() {
+		document.CreatedAt = &result.CreatedAt
+	}
+	return document
+}
+
+// bulk は Bulk API で結果をまとめて登録する
+// 個別の文書の登録に失敗した場合（マッピング不一致など）は再送しても直らないため、件数を報告して破棄する
+func (s *ElasticsearchSink) bulk(ctx context.Context, records [][]byte) error {
+	var body bytes.Buffer
+	for _, record := range records {
+		body.Write(record)
+		body.WriteByte('\n')
+	}
+
+	var response struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := s.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", &body, &response); err != nil {
+		return err
+	}
+	if !response.Errors {
+		return nil
+	}
+
+	failed, reason := 0, ""
+	for _, item := range response.Items {
+		for _, result := range item {
+			if result.Status >= 300 {
+				failed++
+				if reason == "" {
+					reason = result.Error.Type + ": " + result.Error.Reason
+				}
+			}
+		}
+	}
+	event("sink.elasticsearch_rejected").logf("⚠️ Elasticsearch が %d 件の結果を登録しませんでした: %s\n", failed, reason)
+	return nil
+}
+
+// putTemplate は結果のインデックスに適用するインデックステンプレートを登録する
+func (s *ElasticsearchSink) putTemplate(ctx context.Context) error {
+	pattern := s.config.Index
+	for _, placeholder := range []string{"{date}", "{month}", "{type}", "{status}"} {
+		pattern = strings.ReplaceAll(pattern, placeholder, "*")
+	}
+
+	keyword := map[string]string{"type": "keyword"}
+	date := map[string]string{"type": "date"}
+	template := map[string]interface{}{
+		"index_patterns": []string{pattern},
+		"template": map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"dynamic_templates": []map[string]interface{}{
+					{"labels": map[string]interface{}{"path_match": "labels.*", "mapping": keyword}},
+					{"instance": map[string]interface{}{"path_match": "instance.*", "mapping": keyword}},
+				},
+				"properties": map[string]interface{}{
+					"@timestamp": date,
+					"start_time": date,
+					"created_at": date,
+					"task_id":    map[string]string{"type": "long"},
+					"task_name":  keyword,
+					"task_type":  keyword,
+					"variant":    keyword,
+					"success":    map[string]string{"type": "boolean"},
+					"error": map[string]interface{}{"properties": map[string]interface{}{
+						"message":   map[string]string{"type": "text"},
+						"code":      keyword,
+						"retryable": map[string]string{"type": "boolean"},
+					}},
+					"duration_ms":       map[string]string{"type": "double"},
+					"total_duration_ms": map[string]string{"type": "double"},
+					"age_ms":            map[string]string{"type": "double"},
+					"attempt_count":     map[string]string{"type": "integer"},
+					"worker_id":         map[string]string{"type": "integer"},
+					"output":            map[string]string{"type": "text"},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	if err := s.do(ctx, http.MethodPut, "/_index_template/"+s.config.Template, "application/json", bytes.NewReader(data), nil); err != nil {
+		return fmt.Errorf("インデックステンプレート %s を登録できません: %w", s.config.Template, err)
+	}
+	event("sink.elasticsearch_template").logf("🔎 インデックステンプレート %s を登録しました (%s)\n", s.config.Template, pattern)
+	return nil
+}
+
+// do はリクエストを送り、JSON の応答を out に読み込む（out が nil の場合は捨てる）
+func (s *ElasticsearchSink) do(ctx context.Context, method, path, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(s.config.URL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case s.config.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+s.config.APIKey)
+	case s.config.Username != "":
+		req.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Elasticsearch に接続できません: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Elasticsearch がエラーを返しました (%d): %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
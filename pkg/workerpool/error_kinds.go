@@ -0,0 +1,94 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+)
+
+// エラーの分類の名前（ErrorKinds）
+const (
+	ErrorKindRetryable = "retryable" // RetryableError で包まれている
+	ErrorKindPermanent = "permanent" // PermanentError で包まれている
+)
+
+// knownErrors はプロセスをまたいでも errors.Is で判定できるよう名前で受け渡す既知のエラー
+var knownErrors = []struct {
+	name string
+	err  error
+}{
+	{"deadline_exceeded", context.DeadlineExceeded},
+	{"canceled", context.Canceled},
+	{"smtp_connection", ErrSMTPConnection},
+	{"database_connection", ErrDatabaseConnection},
+	{"data_inconsistent", ErrDataInconsistent},
+	{"deadline", ErrDeadlineExceeded},
+}
+
+// ErrorKinds はエラーが該当する既知のエラーとリトライの分類の名前を返す
+// リモートワーカーなど、エラーを文字列でしか渡せない経路で RestoreError と組で使う
+func ErrorKinds(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var kinds []string
+	if retryable, classified := classifyRetry(err); classified {
+		if retryable {
+			kinds = append(kinds, ErrorKindRetryable)
+		} else {
+			kinds = append(kinds, ErrorKindPermanent)
+		}
+	}
+	for _, known := range knownErrors {
+		if errors.Is(err, known.err) {
+			kinds = append(kinds, known.name)
+		}
+	}
+	return kinds
+}
+
+// RestoreError はメッセージと ErrorKinds の名前からエラーを復元する
+// 復元したエラーは元のエラーと同じく errors.Is・IsRetryable・IsPermanent で判定できる（知らない名前は無視する）
+func RestoreError(message string, kinds []string) error {
+	restored := &restoredError{message: message}
+	var retryable, permanent bool
+	for _, kind := range kinds {
+		switch kind {
+		case ErrorKindRetryable:
+			retryable = true
+		case ErrorKindPermanent:
+			permanent = true
+		default:
+			for _, known := range knownErrors {
+				if known.name == kind {
+					restored.targets = append(restored.targets, known.err)
+				}
+			}
+		}
+	}
+
+	switch {
+	case retryable:
+		return Retryable(restored)
+	case permanent:
+		return Permanent(restored)
+	default:
+		return restored
+	}
+}
+
+// restoredError は RestoreError で復元したエラー（元のメッセージを保ち、既知のエラーとして判定できる）
+type restoredError struct {
+	message string
+	targets []error
+}
+
+func (e *restoredError) Error() string { return e.message }
+
+func (e *restoredError) Is(target error) bool {
+	for _, known := range e.targets {
+		if known == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// 文字列でしか渡せない経路を通っても、型でリトライの判定ができること
+func TestRestoreErrorKeepsRetryClassification(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	tests := []struct {
+		name  string
+		err   error
+		retry bool
+	}{
+		{"RetryOn のエラー", fmt.Errorf("%w: 送信に失敗しました", ErrSMTPConnection), true},
+		{"タイムアウト", fmt.Errorf("処理中断: %w", context.DeadlineExceeded), true},
+		{"RetryableError", Retryable(errors.New("一時的な障害")), true},
+		{"PermanentError", Permanent(fmt.Errorf("%w: 宛先不明", ErrSMTPConnection)), false},
+		{"分類のないエラー", errors.New("SMTP接続エラー: 接頭辞だけ一致"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := RestoreError(tt.err.Error(), ErrorKinds(tt.err))
+			if restored.Error() != tt.err.Error() {
+				t.Errorf("message = %q, want %q", restored.Error(), tt.err.Error())
+			}
+			if got := policy.ShouldRetry(restored, 0); got != tt.retry {
+				t.Errorf("ShouldRetry = %v, want %v", got, tt.retry)
+			}
+		})
+	}
+}
+
+func TestIsTimeoutUsesErrorsIs(t *testing.T) {
+	wrapped := TaskResult{Error: fmt.Errorf("タスク 1: %w", context.DeadlineExceeded)}
+	if !wrapped.IsTimeout() {
+		t.Error("包まれた context.DeadlineExceeded をタイムアウトと判定しませんでした")
+	}
+	lookalike := TaskResult{Error: errors.New("context deadline exceeded")}
+	if lookalike.IsTimeout() {
+		t.Error("メッセージが同じだけのエラーをタイムアウトと判定しました")
+	}
+}
@@ -0,0 +1,170 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// killWaitMargin は強制終了の後、出力のパイプが閉じるのを待つ時間（孫プロセスがパイプを持ち続けても Wait が戻るようにする）
+const killWaitMargin = time.Second
+
+// ExecIsolation は外部プロセスを実行する際の隔離設定
+// 重い外部ツールがプールのホストを巻き込んで落ちないよう、タスクタイプごとに指定する
+type ExecIsolation struct {
+	Dir        string            `json:"dir"`           // 作業ディレクトリ（空の場合はプールと同じ）
+	Env        map[string]string `json:"env"`           // 追加する環境変数
+	InheritEnv bool              `json:"inherit_env"`   // プールの環境変数を引き継ぐか（false の場合は PATH のみ）
+	Limits     ResourceLimits    `json:"limits"`        // ulimit による制限
+	Cgroup     string            `json:"cgroup"`        // 所属させる cgroup v2 のディレクトリ（Linux のみ）
+	KillGrace  time.Duration     `json:"kill_grace_ns"` // タイムアウト・キャンセル時に SIGTERM から強制終了までの猶予（0 の場合はすぐに強制終了する）
+}
+
+// ResourceLimits はプロセスごとのリソース制限（0 は無制限）
+type ResourceLimits struct {
+	CPUSeconds  int    `json:"cpu_seconds"`  // CPU時間（ulimit -t）
+	MemoryBytes uint64 `json:"memory_bytes"` // 仮想メモリ（ulimit -v）
+	OpenFiles   int    `json:"open_files"`   // ファイルディスクリプタ数（ulimit -n）
+	Processes   int    `json:"processes"`    // プロセス数（ulimit -u）
+}
+
+// ulimitArgs は ulimit コマンドの引数を返す
+func (l ResourceLimits) ulimitArgs() []string {
+	var args []string
+	if l.CPUSeconds > 0 {
+		args = append(args, fmt.Sprintf("ulimit -t %d", l.CPUSeconds))
+	}
+	if l.MemoryBytes > 0 {
+		// ulimit -v はKB単位
+		args = append(args, fmt.Sprintf("ulimit -v %d", (l.MemoryBytes+1023)/1024))
+	}
+	if l.OpenFiles > 0 {
+		args = append(args, fmt.Sprintf("ulimit -n %d", l.OpenFiles))
+	}
+	if l.Processes > 0 {
+		args = append(args, fmt.Sprintf("ulimit -u %d", l.Processes))
+	}
+	return args
+}
+
+// command は隔離設定を適用したコマンドを作成する
+// 戻り値の cleanup はプロセスの終了後に呼ぶこと
+func (iso *ExecIsolation) command(ctx context.Context, path string, args ...string) (*exec.Cmd, func(), error) {
+	if ulimits := iso.Limits.ulimitArgs(); len(ulimits) > 0 {
+		if runtime.GOOS == "windows" {
+			return nil, nil, fmt.Errorf("リソース制限は %s では使えません", runtime.GOOS)
+		}
+		// シェルで制限を設定してから本来のコマンドに置き換える
+		script := strings.Join(ulimits, " && ") + ` && exec "$0" "$@"`
+		args = append([]string{"-c", script, path}, args...)
+		path = "/bin/sh"
+	}
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = iso.Dir
+	cmd.Env = iso.environ()
+
+	cleanup, err := applyIsolation(cmd, iso)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cmd, cleanup, nil
+}
+
+// environ は子プロセスの環境変数を返す
+func (iso *ExecIsolation) environ() []string {
+	var env []string
+	if iso.InheritEnv {
+		env = os.Environ()
+	} else if path, ok := os.LookupEnv("PATH"); ok {
+		env = []string{"PATH=" + path}
+	}
+
+	keys := make([]string, 0, len(iso.Env))
+	for key := range iso.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+iso.Env[key])
+	}
+	return env
+}
+
+// ExecConfig はタスクごとに外部コマンドを実行するプロセッサの設定
+type ExecConfig struct {
+	Type      TaskType      `json:"type"`
+	Command   string        `json:"command"`
+	Args      []string      `json:"args"`
+	Isolation ExecIsolation `json:"isolation"`
+}
+
+// NewExecProcessor はタスクごとに外部コマンドを実行するプロセッサを作成する
+// タスクはJSON（Sidecar と同じ形式の task）として標準入力に渡され、
+// 終了コード 0 で成功、それ以外は標準エラーの末尾をエラーメッセージとして失敗とする
+// タイムアウトやキャンセル時はプロセスグループごと停止する（Isolation.KillGrace を指定した場合は SIGTERM の後、猶予を過ぎてから強制終了する）
+func NewExecProcessor(config ExecConfig) TaskProcessor {
+	return func(ctx context.Context, task Task) error {
+		input, err := json.Marshal(sidecarTask{
+			ID:           task.ID,
+			Name:         task.Name,
+			Type:         task.Type,
+			Payload:      task.Payload,
+			AttemptCount: task.AttemptCount,
+		})
+		if err != nil {
+			return fmt.Errorf("タスク %d をコマンドに渡せません: %w", task.ID, err)
+		}
+
+		cmd, cleanup, err := config.Isolation.command(ctx, config.Command, config.Args...)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		var stderr bytes.Buffer
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if message := lastLine(stderr.String()); message != "" {
+				return fmt.Errorf("%s", message)
+			}
+			return fmt.Errorf("コマンド %s が失敗しました: %w", config.Command, err)
+		}
+		return nil
+	}
+}
+
+// RegisterExecProcessors は設定ファイルの外部コマンドをプロセッサとして登録する
+func RegisterExecProcessors(pool Pool, configs []ExecConfig) error {
+	for _, config := range configs {
+		if config.Type == "" || config.Command == "" {
+			return fmt.Errorf("外部コマンドの type と command を指定してください")
+		}
+		if err := pool.RegisterProcessor(config.Type, NewExecProcessor(config)); err != nil {
+			return err
+		}
+		if err := DescribeExecutor(pool, config.Type, ExecutorInfo{Kind: ExecutorExec, Detail: config.Command}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastLine は出力の最後の空でない行を返す
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,78 @@
+package workerpool
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// applyIsolation はプロセスグループと cgroup を設定する
+// キャンセル時はプロセスグループごと停止し、孫プロセスが残らないようにする。
+// KillGrace を指定した場合は SIGTERM で終了処理の機会を与え、猶予を過ぎても残っていればプロセスグループごと SIGKILL する
+func applyIsolation(cmd *exec.Cmd, iso *ExecIsolation) (func(), error) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	killer := &groupKiller{grace: iso.KillGrace}
+	cmd.Cancel = func() error {
+		return killer.cancel(cmd.Process.Pid)
+	}
+	if iso.KillGrace > 0 {
+		cmd.WaitDelay = iso.KillGrace + killWaitMargin
+	}
+
+	if iso.Cgroup == "" {
+		return killer.cleanup, nil
+	}
+
+	// 起動と同時に cgroup に所属させる（起動後に移すと、その前に作られた子プロセスが漏れる）
+	dir, err := os.Open(iso.Cgroup)
+	if err != nil {
+		return nil, fmt.Errorf("cgroup %s を開けません: %w", iso.Cgroup, err)
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(dir.Fd())
+	return func() {
+		killer.cleanup()
+		dir.Close()
+	}, nil
+}
+
+// groupKiller はキャンセルされたプロセスグループを猶予の後に強制終了する
+type groupKiller struct {
+	mu    sync.Mutex
+	grace time.Duration
+	pgid  int // キャンセルしたプロセスグループ（0 はキャンセルしていない）
+	timer *time.Timer
+}
+
+// cancel はプロセスグループに SIGTERM を送り、猶予の後に SIGKILL する（猶予が0の場合はすぐに SIGKILL）
+func (k *groupKiller) cancel(pid int) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.pgid = pid
+	if k.grace <= 0 {
+		return syscall.Kill(-pid, syscall.SIGKILL)
+	}
+	k.timer = time.AfterFunc(k.grace, func() {
+		event("exec.killed").logf("🔪 プロセスグループ %d が猶予 %v を過ぎても終了しないため強制終了します\n", pid, k.grace)
+		syscall.Kill(-pid, syscall.SIGKILL)
+	})
+	return syscall.Kill(-pid, syscall.SIGTERM)
+}
+
+// cleanup はプロセスの終了後に呼び出し、キャンセルしたプロセスグループに残ったプロセスを強制終了する
+func (k *groupKiller) cleanup() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.pgid == 0 {
+		return
+	}
+	if k.timer != nil {
+		k.timer.Stop()
+	}
+	syscall.Kill(-k.pgid, syscall.SIGKILL)
+}
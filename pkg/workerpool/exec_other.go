@@ -0,0 +1,28 @@
+//go:build !linux
+
+package workerpool
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// applyIsolation は cgroup に対応していない環境では cgroup の指定をエラーにする
+// KillGrace を指定した場合は割り込みを送り、猶予を過ぎても終了しなければ強制終了する（割り込みを送れない環境ではすぐに強制終了する）
+func applyIsolation(cmd *exec.Cmd, iso *ExecIsolation) (func(), error) {
+	if iso.Cgroup != "" {
+		return nil, fmt.Errorf("cgroup は %s では使えません", runtime.GOOS)
+	}
+	if iso.KillGrace > 0 {
+		cmd.Cancel = func() error {
+			if err := cmd.Process.Signal(os.Interrupt); err != nil {
+				return cmd.Process.Kill()
+			}
+			return nil
+		}
+		cmd.WaitDelay = iso.KillGrace
+	}
+	return func() {}, nil
+}
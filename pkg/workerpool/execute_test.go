@@ -0,0 +1,68 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Execute は同じIDで別に投入したタスクの結果を受け取らない
+func TestExecuteIgnoresOtherTaskWithSameID(t *testing.T) {
+	pool := NewWorkerPool(2)
+	started, release := make(chan struct{}), make(chan struct{})
+	pool.RegisterProcessor("slow", func(ctx context.Context, task Task) error {
+		close(started)
+		<-release
+		return nil
+	})
+	pool.RegisterProcessor("fast", func(ctx context.Context, task Task) error { return nil })
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	unblock := sync.OnceFunc(func() { close(release) })
+	defer pool.Stop()
+	defer unblock() // 失敗した場合もワーカーを止められるようにする
+
+	type outcome struct {
+		result TaskResult
+		err    error
+	}
+	executed := make(chan outcome, 1)
+	go func() {
+		result, err := pool.Execute(context.Background(), Task{ID: 7, Type: "slow"})
+		executed <- outcome{result, err}
+	}()
+	<-started
+
+	if err := pool.AddTask(Task{ID: 7, Type: "fast"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case result := <-pool.Results():
+		if result.TaskType != "fast" {
+			t.Fatalf("Results に流れた結果のタイプ = %s, want fast", result.TaskType)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("AddTask で投入したタスクの結果が Results に流れませんでした")
+	}
+
+	select {
+	case got := <-executed:
+		t.Fatalf("Execute が slow の完了前に戻りました: %+v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unblock()
+	select {
+	case got := <-executed:
+		if got.err != nil {
+			t.Fatal(got.err)
+		}
+		if got.result.TaskType != "slow" || !got.result.IsFinal {
+			t.Fatalf("Execute の結果 = %s (final=%v), want slow の最終結果", got.result.TaskType, got.result.IsFinal)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Execute が戻りませんでした")
+	}
+}
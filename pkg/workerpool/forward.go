@@ -0,0 +1,360 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// forwardedHopsHeader は HTTPPeer が転送したタスクの経路（Task.Hops）を渡すヘッダー（経由順に1つずつ付ける）
+	// POST /tasks のボディでは受け付けないため、転送の経路は管理APIの認証を通ったピアからしか指定できない
+	forwardedHopsHeader = "X-Workerpool-Hop"
+
+	// defaultForwardMaxHops は転送回数の上限の既定値
+	defaultForwardMaxHops = 3
+	// defaultForwardTimeout は1回の転送の制限時間の既定値
+	defaultForwardTimeout = 5 * time.Second
+)
+
+var (
+	// ErrForwardLoop は転送済みのタスクが戻ってきた・転送回数の上限に達したため転送しなかった場合のエラー
+	ErrForwardLoop = errors.New("転送ループ: タスクは既にこのプールを経由しているか、転送回数の上限に達しています")
+	// ErrPeerRejected はピアがタスクを受け付けなかった場合のエラー
+	ErrPeerRejected = errors.New("ピアがタスクを受け付けませんでした")
+)
+
+// TaskForwarder はタスクを別のプール（ピア）に渡す転送先
+// Forward はピアがタスクを受け付けた時点で nil を返す（結果はピアの側で通知される）
+type TaskForwarder interface {
+	Name() string
+	Forward(ctx context.Context, task Task) error
+}
+
+// ForwardReason はタスクを転送した理由
+type ForwardReason string
+
+const (
+	ForwardUnregistered ForwardReason = "unregistered"  // プロセッサが登録されていないタイプ
+	ForwardOverCapacity ForwardReason = "over_capacity" // メモリ・アドミッション制御・負荷制御・キューの空きのため受け付けられない
+)
+
+// ForwardPolicy は自分で処理できないタスクをピアに転送する設定
+// ピアは定義した順に試し、最初に受け付けたピアに渡す。どのピアも受け付けない場合は転送しなかった場合と同じ扱いになる。
+// 転送したタスクには経由したプールの識別子（Task.Hops）を付け、同じプールに戻ってくるループを防ぐ。
+// Execute で結果を待っているタスクは転送しない
+type ForwardPolicy struct {
+	ID           string          // このプールの識別子（空の場合はインスタンスID、未設定の場合はホスト名）
+	Peers        []TaskForwarder // 転送先（定義した順に試す）
+	Unregistered bool            // プロセッサが登録されていないタイプを転送する
+	OverCapacity bool            // 受付の上限に達したタスクを拒否・破棄・保留する代わりに転送する
+	MaxHops      int             // 転送回数の上限（0 の場合は3）
+	Timeout      time.Duration   // 1回の転送の制限時間（0 の場合は5秒）
+}
+
+// ForwardStats は転送の集計
+type ForwardStats struct {
+	Forwarded int64                   `json:"forwarded"` // ピアに渡したタスクの累計
+	ByPeer    map[string]int64        `json:"by_peer"`   // ピアごとの転送件数
+	ByReason  map[ForwardReason]int64 `json:"by_reason"` // 理由ごとの転送件数
+	Failed    int64                   `json:"failed"`    // どのピアも受け付けなかった件数
+	Looped    int64                   `json:"looped"`    // ループ防止のため転送しなかった件数
+}
+
+// forwarder は転送の設定と集計
+type forwarder struct {
+	policy ForwardPolicy
+
+	mu    sync.Mutex
+	stats ForwardStats
+}
+
+func newForwarder(policy ForwardPolicy) *forwarder {
+	if policy.ID == "" {
+		if info := CurrentInstance(); info != nil {
+			policy.ID = info.ID
+		} else {
+			policy.ID, _ = os.Hostname()
+		}
+	}
+	if policy.MaxHops <= 0 {
+		policy.MaxHops = defaultForwardMaxHops
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = defaultForwardTimeout
+	}
+	policy.Peers = append([]TaskForwarder(nil), policy.Peers...)
+	return &forwarder{
+		policy: policy,
+		stats: ForwardStats{
+			ByPeer:   make(map[string]int64),
+			ByReason: make(map[ForwardReason]int64),
+		},
+	}
+}
+
+// enabled は理由に対して転送が有効か判定
+func (f *forwarder) enabled(reason ForwardReason) bool {
+	if f == nil || len(f.policy.Peers) == 0 {
+		return false
+	}
+	switch reason {
+	case ForwardUnregistered:
+		return f.policy.Unregistered
+	case ForwardOverCapacity:
+		return f.policy.OverCapacity
+	}
+	return false
+}
+
+// forward はタスクをピアに渡し、受け付けたピアの名前を返す
+// このプールを経由済み・転送回数の上限に達したタスクは ErrForwardLoop、どのピアも受け付けない場合は最後のエラーを返す
+func (f *forwarder) forward(task Task, reason ForwardReason) (string, error) {
+	if visited(task.Hops, f.policy.ID) || len(task.Hops) >= f.policy.MaxHops {
+		return "", f.fail(ErrForwardLoop, true)
+	}
+
+	task.Hops = append(append([]string(nil), task.Hops...), f.policy.ID)
+	lastErr := error(ErrPeerRejected)
+	for _, peer := range f.policy.Peers {
+		if visited(task.Hops, peer.Name()) {
+			continue // 経由済みのピアには戻さない
+		}
+		ctx, cancel := context.WithTimeout(task.context(), f.policy.Timeout)
+		err := peer.Forward(ctx, task)
+		cancel()
+		if err != nil {
+			lastErr = fmt.Errorf("ピア %s: %w", peer.Name(), err)
+			continue
+		}
+
+		f.mu.Lock()
+		f.stats.Forwarded++
+		f.stats.ByPeer[peer.Name()]++
+		f.stats.ByReason[reason]++
+		f.mu.Unlock()
+		return peer.Name(), nil
+	}
+	return "", f.fail(lastErr, false)
+}
+
+// fail は転送しなかった件数を数えてエラーを返す
+func (f *forwarder) fail(err error, looped bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if looped {
+		f.stats.Looped++
+	} else {
+		f.stats.Failed++
+	}
+	return err
+}
+
+// snapshot は集計のコピーを返す
+func (f *forwarder) snapshot() *ForwardStats {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stats := f.stats
+	stats.ByPeer = make(map[string]int64, len(f.stats.ByPeer))
+	for peer, count := range f.stats.ByPeer {
+		stats.ByPeer[peer] = count
+	}
+	stats.ByReason = make(map[ForwardReason]int64, len(f.stats.ByReason))
+	for reason, count := range f.stats.ByReason {
+		stats.ByReason[reason] = count
+	}
+	return &stats
+}
+
+// mergeForwardStats はサブプールの転送の集計を合算する
+func mergeForwardStats(total, stats *ForwardStats) *ForwardStats {
+	if stats == nil {
+		return total
+	}
+	if total == nil {
+		total = &ForwardStats{ByPeer: make(map[string]int64), ByReason: make(map[ForwardReason]int64)}
+	}
+	total.Forwarded += stats.Forwarded
+	total.Failed += stats.Failed
+	total.Looped += stats.Looped
+	for peer, count := range stats.ByPeer {
+		total.ByPeer[peer] += count
+	}
+	for reason, count := range stats.ByReason {
+		total.ByReason[reason] += count
+	}
+	return total
+}
+
+// forwardedHops は HTTPPeer が付けた転送の経路をリクエストのヘッダーから読み込む
+func forwardedHops(r *http.Request) []string {
+	var hops []string
+	for _, hop := range r.Header.Values(forwardedHopsHeader) {
+		if hop = strings.TrimSpace(hop); hop != "" {
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// visited は経路に識別子が含まれるか判定
+func visited(hops []string, id string) bool {
+	for _, hop := range hops {
+		if hop == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SetForwarding は自分で処理できないタスクをピアに転送する設定を行う（nil で無効化）
+func (wp *WorkerPool) SetForwarding(policy *ForwardPolicy) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if policy == nil {
+		wp.forwarder = nil
+		return
+	}
+	wp.forwarder = newForwarder(*policy)
+}
+
+// ForwardStats は転送の集計を返す（転送が無効の場合は nil）
+func (wp *WorkerPool) ForwardStats() *ForwardStats {
+	wp.mu.Lock()
+	f := wp.forwarder
+	wp.mu.Unlock()
+
+	return f.snapshot()
+}
+
+// tryForward は転送が有効な理由であればタスクをピアに渡し、渡せた場合は true を返す
+// 渡せなかった場合は呼び出し元がこれまでどおり処理する
+func (wp *WorkerPool) tryForward(task Task, reason ForwardReason) bool {
+	wp.mu.Lock()
+	f := wp.forwarder
+	wp.mu.Unlock()
+
+	if !f.enabled(reason) || task.waiter != 0 {
+		return false
+	}
+	if _, streaming := StreamOf(task); streaming {
+		return false // ストリームはこのプロセスの外に渡せない
+	}
+
+	peer, err := f.forward(task, reason)
+	if err != nil {
+		event("task.forward_failed").taskOf(task).failed(err).logf("📡 タスク %d をピアに転送できませんでした (%s): %v\n", task.ID, reason, err)
+		return false
+	}
+	event("task.forwarded").taskOf(task).logf("📡 タスク %d (%s) をピア %s に転送しました (%s)\n", task.ID, task.Name, peer, reason)
+	taskSpan(task).AddEvent("forwarded to " + peer)
+	endTaskSpan(task, nil)
+	return true
+}
+
+// hasProcessor はタイプのプロセッサが登録されているか判定
+func (wp *WorkerPool) hasProcessor(taskType TaskType) bool {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	_, exists := wp.processors[taskType]
+	return exists
+}
+
+// queueFull はタスクの投入先のキュー（名前付きキューまたは共有キュー）が満杯か判定
+func (wp *WorkerPool) queueFull(task Task) bool {
+	if q := wp.queueRouter().route(task); q != nil {
+		return q.queue.full()
+	}
+	return wp.queue.full()
+}
+
+// SetForwarding はすべてのサブプールに転送の設定を行う（集計はサブプールごと）
+// サブプールが定義されていないタイプのタスクは転送しない
+func (tp *TypedPool) SetForwarding(policy *ForwardPolicy) {
+	for _, pool := range tp.subPools() {
+		pool.SetForwarding(policy)
+	}
+}
+
+// HTTPPeer は別のプールのWebサーバーの POST /tasks にタスクを転送する
+type HTTPPeer struct {
+	name   string
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTPPeer はプールのWebサーバーのURL（例: http://pool-b:8080）への転送先を作成する
+// token は管理APIのトークン（不要な場合は空）。ピアの名前はピア側の ForwardPolicy.ID と揃えるとループを早く検出できる
+func NewHTTPPeer(name, baseURL, token string) *HTTPPeer {
+	return &HTTPPeer{
+		name:   name,
+		url:    strings.TrimRight(baseURL, "/") + "/tasks",
+		token:  token,
+		client: &http.Client{},
+	}
+}
+
+// Name はピアの名前を返す
+func (p *HTTPPeer) Name() string { return p.name }
+
+// Forward はタスクを POST /tasks で投入する（202 以外は ErrPeerRejected）
+func (p *HTTPPeer) Forward(ctx context.Context, task Task) error {
+	priority := task.Priority
+	body, err := json.Marshal(taskRequest{
+		ID:         task.ID,
+		Name:       task.Name,
+		Type:       task.Type,
+		Payload:    task.Payload,
+		Labels:     task.Labels,
+		Selector:   task.Selector,
+		Priority:   &priority,
+		Sheddable:  task.Sheddable,
+		Deadline:   task.Deadline,
+		MaxRetries: task.MaxRetries,
+		Timeout:    task.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, hop := range task.Hops {
+		req.Header.Add(forwardedHopsHeader, hop)
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusAccepted {
+		return nil
+	}
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&apiErr)
+	return fmt.Errorf("%w: HTTP %d: %s", ErrPeerRejected, resp.StatusCode, apiErr.Error)
+}
@@ -0,0 +1,58 @@
+package workerpool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 転送の経路は HTTPPeer が付けるヘッダーからだけ受け取り、POST /tasks のボディの hops は使わない
+func TestSubmitTaskTakesHopsOnlyFromPeerHeader(t *testing.T) {
+	pool := NewWorkerPool(1)
+	hops := make(chan []string, 1)
+	pool.RegisterProcessor("email", func(ctx context.Context, task Task) error {
+		hops <- task.Hops
+		return nil
+	})
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Stop()
+	server := httptest.NewServer(http.HandlerFunc(NewMonitor(pool).handleSubmitTask))
+	defer server.Close()
+
+	received := func() []string {
+		t.Helper()
+		select {
+		case got := <-hops:
+			return got
+		case <-time.After(5 * time.Second):
+			t.Fatal("タスクが実行されませんでした")
+			return nil
+		}
+	}
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"id":1,"type":"email","hops":["a","b","c","d"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", resp.StatusCode)
+	}
+	if got := received(); len(got) != 0 {
+		t.Fatalf("ボディの hops を受け付けました: %v", got)
+	}
+
+	peer := NewHTTPPeer("pool-b", server.URL, "")
+	if err := peer.Forward(context.Background(), Task{ID: 2, Type: "email", Hops: []string{"pool-a", "pool-c"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := received(), []string{"pool-a", "pool-c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Hops = %v, want %v", got, want)
+	}
+}
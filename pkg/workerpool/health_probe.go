@@ -0,0 +1,246 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ヘルスチェックの既定値
+const (
+	defaultProbeInterval = 10 * time.Second
+	defaultProbeTimeout  = 5 * time.Second
+)
+
+// HealthProbe はタスクタイプが依存する下流（SMTPサーバー・データベースなど）の疎通を確認する（失敗時はエラーを返す）
+type HealthProbe func(ctx context.Context) error
+
+// TCPProbe は addr に TCP で接続できるかを確認するヘルスチェックを返す（SMTPサーバーなど）
+func TCPProbe(addr string) HealthProbe {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// Pinger は疎通を確認できる接続（*sql.DB など）
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingProbe は接続の PingContext で疎通を確認するヘルスチェックを返す（*sql.DB の場合は SELECT 1 相当）
+func PingProbe(pinger Pinger) HealthProbe {
+	return pinger.PingContext
+}
+
+// HealthProbeOptions はヘルスチェックの実行間隔と、状態を切り替える連続回数
+type HealthProbeOptions struct {
+	Interval         time.Duration `json:"interval_ns"`       // 確認する間隔（0 の場合は10秒）
+	Timeout          time.Duration `json:"timeout_ns"`        // 1回の確認の制限時間（0 の場合は5秒）
+	FailureThreshold int           `json:"failure_threshold"` // 障害とみなす連続失敗回数（0 の場合は1）
+	SuccessThreshold int           `json:"success_threshold"` // 回復とみなす連続成功回数（0 の場合は1）
+}
+
+// withDefaults は既定値を補った設定を返す
+func (o HealthProbeOptions) withDefaults() HealthProbeOptions {
+	if o.Interval <= 0 {
+		o.Interval = defaultProbeInterval
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = min(defaultProbeTimeout, o.Interval)
+	}
+	o.FailureThreshold = max(o.FailureThreshold, 1)
+	o.SuccessThreshold = max(o.SuccessThreshold, 1)
+	return o
+}
+
+// DownstreamStatus はタスクタイプの下流のヘルスチェックの状況
+type DownstreamStatus struct {
+	TaskType    TaskType  `json:"task_type"`
+	Healthy     bool      `json:"healthy"`
+	Since       time.Time `json:"since"`                  // 現在の状態になった日時
+	LastChecked time.Time `json:"last_checked,omitempty"` // 直近の確認日時
+	NextCheck   time.Time `json:"next_check,omitempty"`   // 次の確認予定日時
+	LastError   string    `json:"last_error,omitempty"`   // 直近の失敗のエラー（成功した場合は空）
+	Failures    int       `json:"consecutive_failures"`
+	Successes   int       `json:"consecutive_successes"`
+}
+
+// healthProbe は登録されたヘルスチェックと直近の結果
+// 障害と判定している間、対象タイプのタスクはメンテナンスウィンドウと同様に保留される
+type healthProbe struct {
+	check   HealthProbe
+	options HealthProbeOptions
+
+	mu     sync.Mutex
+	status DownstreamStatus
+}
+
+// unhealthy は下流を障害と判定していれば、直近のエラーと次の確認予定日時を返す
+func (p *healthProbe) unhealthy() (string, time.Time, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.status.LastError, p.status.NextCheck, !p.status.Healthy
+}
+
+// record は確認の結果を記録し、状態が切り替わった場合は true を返す
+func (p *healthProbe) record(err error, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := &p.status
+	status.LastChecked = now
+	status.NextCheck = now.Add(p.options.Interval)
+	if err != nil {
+		status.LastError = err.Error()
+		status.Failures++
+		status.Successes = 0
+		if status.Healthy && status.Failures >= p.options.FailureThreshold {
+			status.Healthy = false
+			status.Since = now
+			return true
+		}
+		return false
+	}
+	status.LastError = ""
+	status.Successes++
+	status.Failures = 0
+	if !status.Healthy && status.Successes >= p.options.SuccessThreshold {
+		status.Healthy = true
+		status.Since = now
+		return true
+	}
+	return false
+}
+
+// SetHealthProbe はタスクタイプの下流のヘルスチェックを登録する（Start 前に呼び出すこと。probe が nil で解除）
+// 開始後は一定間隔で確認し、障害と判定している間はそのタイプのタスクを実行せずに保留する。
+// 障害中に失敗したタスクも試行回数に数えずに保留し、回復した時点でキューに戻す
+func (wp *WorkerPool) SetHealthProbe(taskType TaskType, probe HealthProbe, options HealthProbeOptions) error {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.lifecycle.current() != StateCreated {
+		return fmt.Errorf("タスクタイプ %s のヘルスチェック: %w", taskType, ErrPoolStarted)
+	}
+	if probe == nil {
+		delete(wp.probes, taskType)
+		return nil
+	}
+	if wp.probes == nil {
+		wp.probes = make(map[TaskType]*healthProbe)
+	}
+	wp.probes[taskType] = &healthProbe{
+		check:   probe,
+		options: options.withDefaults(),
+		status:  DownstreamStatus{TaskType: taskType, Healthy: true, Since: time.Now()},
+	}
+	return nil
+}
+
+// SetHealthProbe はタイプのサブプールに下流のヘルスチェックを登録する（プロセッサの登録後、Start 前に呼び出すこと）
+func (tp *TypedPool) SetHealthProbe(taskType TaskType, probe HealthProbe, options HealthProbeOptions) error {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	return pool.SetHealthProbe(taskType, probe, options)
+}
+
+// healthProbes は登録されたヘルスチェックを返す
+func (wp *WorkerPool) healthProbes() []*healthProbe {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	probes := make([]*healthProbe, 0, len(wp.probes))
+	for _, probe := range wp.probes {
+		probes = append(probes, probe)
+	}
+	return probes
+}
+
+// DownstreamStatus は下流のヘルスチェックの状況をタスクタイプ順に返す（登録がない場合は nil）
+func (wp *WorkerPool) DownstreamStatus() []DownstreamStatus {
+	var statuses []DownstreamStatus
+	for _, probe := range wp.healthProbes() {
+		probe.mu.Lock()
+		statuses = append(statuses, probe.status)
+		probe.mu.Unlock()
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].TaskType < statuses[j].TaskType })
+	return statuses
+}
+
+// healthProber は一定間隔でヘルスチェックを実行し、障害・回復を判定する
+func (wp *WorkerPool) healthProber(probe *healthProbe) {
+	defer wp.bgWg.Done()
+
+	ticker := time.NewTicker(probe.options.Interval)
+	defer ticker.Stop()
+
+	wp.runHealthProbe(probe, time.Now())
+	for {
+		select {
+		case now := <-ticker.C:
+			wp.runHealthProbe(probe, now)
+		case <-wp.shutdownCh:
+			return
+		}
+	}
+}
+
+// runHealthProbe はヘルスチェックを1回実行し、回復した場合は保留中のタスクをすぐにキューに戻す
+func (wp *WorkerPool) runHealthProbe(probe *healthProbe, now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), probe.options.Timeout)
+	err := probe.check(ctx)
+	cancel()
+
+	if !probe.record(err, now) {
+		return
+	}
+	taskType := probe.status.TaskType
+	if err != nil {
+		event("downstream.unhealthy").failed(err).logf("🩺 タスクタイプ %s の下流のヘルスチェックに失敗したため、回復するまでタスクを保留します: %v\n", taskType, err)
+		return
+	}
+	event("downstream.recovered").logf("💚 タスクタイプ %s の下流が回復したため、保留中のタスクを再開します\n", taskType)
+	wp.releaseHeld()
+}
+
+// holdForDownstream は下流の障害中に失敗したタスクを、試行回数に数えずに回復まで保留する
+// 保留した場合は true を返す（呼び出し側はリトライ・DLQの処理を行わない）
+func (wp *WorkerPool) holdForDownstream(task Task, err error) bool {
+	if deadLetterReasonFor(err) != DeadLetterFailed || isCanceled(err) || task.context().Err() != nil {
+		return false
+	}
+	wp.mu.Lock()
+	probe := wp.probes[task.Type]
+	wp.mu.Unlock()
+	if probe == nil {
+		return false
+	}
+	if _, _, down := probe.unhealthy(); !down {
+		return false
+	}
+
+	task.LastError = err
+	if wp.commit(task, TaskStatePending, err) != nil {
+		wp.skipOrdered(task)
+		return true
+	}
+	// 保留の間に回復した場合も、保留中のタスクを定期的に戻す処理でキューに戻る
+	wp.mu.Lock()
+	wp.held = append(wp.held, task)
+	wp.mu.Unlock()
+	event("task.held").taskOf(task).failed(err).logf("🩺 タスクタイプ %s の下流の障害中に失敗したため、タスク %d を試行回数に数えずに保留しました (エラー: %v)\n",
+		task.Type, task.ID, err)
+	return true
+}
@@ -0,0 +1,468 @@
+package workerpool
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultHeatWindow は分類に使う直近の期間の既定値
+	defaultHeatWindow = time.Minute
+	// defaultHeatInterval は分類し直す間隔の既定値
+	defaultHeatInterval = 5 * time.Second
+	// defaultHotShare は hot に分類する処理件数の割合の既定値
+	defaultHotShare = 0.5
+	// defaultHeatMinTasks は hot に分類するために必要な期間内の処理件数の既定値
+	defaultHeatMinTasks = 20
+	// maxHeatSamples はタイプごとに保持する処理時間の上限（超えた分は古い順に破棄する）
+	maxHeatSamples = 4096
+)
+
+// HeatClass はタスクタイプの分類
+type HeatClass string
+
+const (
+	HeatCold HeatClass = "cold" // 共有キューで処理する
+	HeatHot  HeatClass = "hot"  // 専用レーンと専用ワーカーで処理する
+)
+
+// HeatPolicy はタスクタイプを直近の処理件数と処理時間で hot / cold に分類する設定
+// hot のタイプには専用のレーン（小さなキュー）と、あらかじめ起動した専用ワーカーを割り当て、
+// cold のタスクの後ろに並ばずにすぐ実行する。レーンが満杯の場合は共有キューに回す
+type HeatPolicy struct {
+	Window          time.Duration `json:"window_ns"`        // 分類に使う直近の期間（0 の場合は1分）
+	Interval        time.Duration `json:"interval_ns"`      // 分類し直す間隔（0 の場合は5秒）
+	HotShare        float64       `json:"hot_share"`        // hot に分類する処理件数の割合（0 の場合は0.5）
+	CoolShare       float64       `json:"cool_share"`       // hot のタイプを cold に戻す割合（0 の場合は HotShare の3/4。分類の振動を防ぐ）
+	MinTasks        int           `json:"min_tasks"`        // hot に分類するために必要な期間内の処理件数（0 の場合は20）
+	MaxLatency      time.Duration `json:"max_latency_ns"`   // p99 がこれを超えるタイプは hot にしない（専用ワーカーを長く占有するため。0 で無制限）
+	ReservedWorkers int           `json:"reserved_workers"` // hot のタイプごとの専用ワーカー数（0 の場合は1）
+	LaneDepth       int           `json:"lane_depth"`       // 専用レーンに並べられるタスク数（0 の場合は専用ワーカー数）
+}
+
+// withDefaults は既定値を補った設定を返す
+func (p HeatPolicy) withDefaults() HeatPolicy {
+	if p.Window <= 0 {
+		p.Window = defaultHeatWindow
+	}
+	if p.Interval <= 0 {
+		p.Interval = defaultHeatInterval
+	}
+	if p.HotShare <= 0 {
+		p.HotShare = defaultHotShare
+	}
+	if p.CoolShare <= 0 || p.CoolShare > p.HotShare {
+		p.CoolShare = p.HotShare * 3 / 4
+	}
+	if p.MinTasks <= 0 {
+		p.MinTasks = defaultHeatMinTasks
+	}
+	if p.ReservedWorkers <= 0 {
+		p.ReservedWorkers = 1
+	}
+	if p.LaneDepth <= 0 {
+		p.LaneDepth = p.ReservedWorkers
+	}
+	return p
+}
+
+// TaskHeat はタスクタイプの分類と、分類の根拠になった直近の値
+type TaskHeat struct {
+	Class           HeatClass     `json:"class"`
+	Since           time.Time     `json:"since"`            // 現在の分類になった日時
+	Tasks           int           `json:"tasks"`            // 期間内の処理件数
+	Share           float64       `json:"share"`            // 期間内の処理件数の割合
+	Rate            float64       `json:"rate_per_sec"`     // 期間内の処理件数の毎秒の平均
+	P99             time.Duration `json:"p99_ns"`           // 期間内の処理時間の99パーセンタイル
+	ReservedWorkers int           `json:"reserved_workers"` // 専用ワーカー数（cold の場合は0）
+	FastPath        int64         `json:"fast_path"`        // 専用レーンに投入した件数
+	Spilled         int64         `json:"spilled"`          // 専用レーンが満杯のため共有キューに回した件数
+}
+
+// heatSample は1回の試行の終了時刻と処理時間
+type heatSample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// fastLane は hot のタイプの専用レーン
+type fastLane struct {
+	queue *taskQueue
+}
+
+// heatTracker はタスクタイプの分類と専用レーンを管理する
+type heatTracker struct {
+	mu      sync.Mutex
+	policy  HeatPolicy
+	samples map[TaskType][]heatSample // 古い順
+	heat    map[TaskType]*TaskHeat
+	lanes   map[TaskType]*fastLane
+	busy    int  // 専用ワーカーが実行中のタスク数
+	closed  bool // 停止処理中は新しいレーンを開かない
+}
+
+func newHeatTracker(policy HeatPolicy) *heatTracker {
+	return &heatTracker{
+		policy:  policy.withDefaults(),
+		samples: make(map[TaskType][]heatSample),
+		heat:    make(map[TaskType]*TaskHeat),
+		lanes:   make(map[TaskType]*fastLane),
+	}
+}
+
+// observe は試行の処理時間を記録する
+func (h *heatTracker) observe(taskType TaskType, duration time.Duration, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	samples := append(h.samples[taskType], heatSample{at: now, duration: duration})
+	if len(samples) > maxHeatSamples {
+		samples = samples[len(samples)-maxHeatSamples:]
+	}
+	h.samples[taskType] = samples
+}
+
+// classify は直近の期間の処理件数と処理時間からタイプを分類し直し、hot になったタイプと cold に戻ったタイプを返す
+func (h *heatTracker) classify(now time.Time) (heated, cooled []TaskType) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := now.Add(-h.policy.Window)
+	total := 0
+	for taskType, samples := range h.samples {
+		drop := 0
+		for drop < len(samples) && samples[drop].at.Before(cutoff) {
+			drop++
+		}
+		if samples = samples[drop:]; len(samples) == 0 {
+			delete(h.samples, taskType)
+			continue
+		}
+		h.samples[taskType] = samples
+		total += len(samples)
+	}
+
+	for taskType, heat := range h.heat {
+		if _, active := h.samples[taskType]; !active && heat.Class == HeatCold {
+			delete(h.heat, taskType)
+		}
+	}
+	for taskType := range h.heat {
+		if _, active := h.samples[taskType]; !active {
+			h.samples[taskType] = nil // 処理がなくなった hot のタイプも cold に戻すため評価する
+		}
+	}
+
+	for taskType, samples := range h.samples {
+		heat, exists := h.heat[taskType]
+		if !exists {
+			heat = &TaskHeat{Class: HeatCold, Since: now}
+			h.heat[taskType] = heat
+		}
+		heat.Tasks = len(samples)
+		heat.Share = 0
+		if total > 0 {
+			heat.Share = float64(len(samples)) / float64(total)
+		}
+		heat.Rate = float64(len(samples)) / h.policy.Window.Seconds()
+		heat.P99 = heatP99(samples)
+
+		share := h.policy.HotShare
+		if heat.Class == HeatHot {
+			share = h.policy.CoolShare
+		}
+		hot := heat.Tasks >= h.policy.MinTasks && heat.Share >= share &&
+			(h.policy.MaxLatency == 0 || heat.P99 <= h.policy.MaxLatency)
+		switch {
+		case hot && heat.Class == HeatCold:
+			heat.Class, heat.Since = HeatHot, now
+			heated = append(heated, taskType)
+		case !hot && heat.Class == HeatHot:
+			heat.Class, heat.Since = HeatCold, now
+			cooled = append(cooled, taskType)
+		}
+		if len(samples) == 0 {
+			delete(h.samples, taskType)
+		}
+	}
+	return heated, cooled
+}
+
+// heatP99 は処理時間の99パーセンタイルを返す
+func heatP99(samples []heatSample) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	durations := make([]time.Duration, len(samples))
+	for i, sample := range samples {
+		durations[i] = sample.duration
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return durations[(len(durations)*99-1)/100]
+}
+
+// openLane は hot になったタイプの専用レーンを作成し、専用ワーカーの分だけ wg を加算する（停止処理中・作成済みの場合は nil）
+// 停止処理は closeAll の後に wg を待つため、加算はレーンの作成と同じロックの中で行う
+func (h *heatTracker) openLane(taskType TaskType, wg *sync.WaitGroup) *fastLane {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, exists := h.lanes[taskType]; exists || h.closed {
+		return nil
+	}
+	lane := &fastLane{queue: newTaskQueue(h.policy.LaneDepth)}
+	h.lanes[taskType] = lane
+	wg.Add(h.policy.ReservedWorkers)
+	if heat := h.heat[taskType]; heat != nil {
+		heat.ReservedWorkers = h.policy.ReservedWorkers
+	}
+	return lane
+}
+
+// closeLane は cold に戻ったタイプの専用レーンを閉じる（並んでいるタスクは専用ワーカーが処理してから終了する）
+func (h *heatTracker) closeLane(taskType TaskType) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if lane, exists := h.lanes[taskType]; exists {
+		delete(h.lanes, taskType)
+		lane.queue.close()
+	}
+	if heat := h.heat[taskType]; heat != nil {
+		heat.ReservedWorkers = 0
+	}
+}
+
+// closeAll は停止処理のためすべての専用レーンを閉じ、以降はレーンを開かない
+func (h *heatTracker) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for taskType, lane := range h.lanes {
+		delete(h.lanes, taskType)
+		lane.queue.close()
+		if heat := h.heat[taskType]; heat != nil {
+			heat.Class, heat.ReservedWorkers = HeatCold, 0
+		}
+	}
+}
+
+// reopen は再開したプールで再びレーンを開けるようにする
+func (h *heatTracker) reopen() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = false
+}
+
+// offer はタスクを専用レーンに投入する。レーンがない・満杯の場合は false（共有キューに回す）
+func (h *heatTracker) offer(task Task) bool {
+	h.mu.Lock()
+	lane, exists := h.lanes[task.Type]
+	h.mu.Unlock()
+	if !exists {
+		return false
+	}
+
+	accepted := lane.queue.tryPush(task)
+	h.mu.Lock()
+	if heat := h.heat[task.Type]; heat != nil {
+		if accepted {
+			heat.FastPath++
+		} else {
+			heat.Spilled++
+		}
+	}
+	h.mu.Unlock()
+	return accepted
+}
+
+// laneQueues は開いている専用レーンのキューを返す
+func (h *heatTracker) laneQueues() []*taskQueue {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	queues := make([]*taskQueue, 0, len(h.lanes))
+	for _, lane := range h.lanes {
+		queues = append(queues, lane.queue)
+	}
+	return queues
+}
+
+// pending は専用レーンに並んでいるタスク数と、専用ワーカーが実行中のタスク数を返す
+func (h *heatTracker) pending() (queued, busy int) {
+	if h == nil {
+		return 0, 0
+	}
+	for _, queue := range h.laneQueues() {
+		queued += queue.len()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return queued, h.busy
+}
+
+// addBusy は専用ワーカーが実行中のタスク数を増減する
+func (h *heatTracker) addBusy(delta int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.busy += delta
+}
+
+// reservedWorkers は起動している専用ワーカーの合計を返す
+func (h *heatTracker) reservedWorkers() int {
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.lanes) * h.policy.ReservedWorkers
+}
+
+// snapshot はタイプごとの分類のコピーを返す
+func (h *heatTracker) snapshot() map[TaskType]TaskHeat {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	heat := make(map[TaskType]TaskHeat, len(h.heat))
+	for taskType, entry := range h.heat {
+		heat[taskType] = *entry
+	}
+	return heat
+}
+
+// SetHeatPolicy はタスクタイプの hot / cold の分類と、hot のタイプの専用レーンを設定する（Start 前に呼び出すこと。nil で無効化）
+func (wp *WorkerPool) SetHeatPolicy(policy *HeatPolicy) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if policy == nil {
+		wp.heat = nil
+		return
+	}
+	wp.heat = newHeatTracker(*policy)
+}
+
+// heatTracker は分類の設定を返す（無効の場合は nil）
+func (wp *WorkerPool) heatTracker() *heatTracker {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return wp.heat
+}
+
+// queuedTasks は共有キュー・専用レーン・名前付きキューに並んでいるタスク数を返す
+func (wp *WorkerPool) queuedTasks() int {
+	queued, _ := wp.heatTracker().pending()
+	routed, _ := wp.queueRouter().pending()
+	return wp.queue.len() + queued + routed
+}
+
+// TaskHeat はタスクタイプごとの hot / cold の分類を返す（分類が無効の場合は nil）
+func (wp *WorkerPool) TaskHeat() map[TaskType]TaskHeat {
+	return wp.heatTracker().snapshot()
+}
+
+// observeHeat は分類のために試行の処理時間を記録する
+func (wp *WorkerPool) observeHeat(taskType TaskType, duration time.Duration, now time.Time) {
+	if heat := wp.heatTracker(); heat != nil {
+		heat.observe(taskType, duration, now)
+	}
+}
+
+// offerFastLane は hot のタイプのタスクを専用レーンに投入する（投入できなければ false）
+func (wp *WorkerPool) offerFastLane(task Task) bool {
+	heat := wp.heatTracker()
+	return heat != nil && heat.offer(task)
+}
+
+// heatClassifier は一定間隔でタイプを分類し直し、専用レーンを開閉する
+func (wp *WorkerPool) heatClassifier(heat *heatTracker) {
+	defer wp.bgWg.Done()
+
+	ticker := time.NewTicker(heat.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			heated, cooled := heat.classify(now)
+			for _, taskType := range cooled {
+				heat.closeLane(taskType)
+				event("heat.cooled").logf("🧊 タスクタイプ %s を cold に分類しました（専用レーンを閉じます）\n", taskType)
+			}
+			for _, taskType := range heated {
+				lane := heat.openLane(taskType, &wp.wg)
+				if lane == nil {
+					continue
+				}
+				entry := heat.snapshot()[taskType]
+				event("heat.heated").logf("♨️ タスクタイプ %s を hot に分類しました（割合 %.0f%%, p99 %v）。専用ワーカー %d 個を割り当てます\n",
+					taskType, entry.Share*100, entry.P99.Round(time.Millisecond), heat.policy.ReservedWorkers)
+				wp.mu.Lock()
+				for i := 0; i < heat.policy.ReservedWorkers; i++ {
+					id := wp.nextWorkerID
+					wp.nextWorkerID++
+					go wp.laneWorker(id, taskType, lane, heat)
+				}
+				wp.mu.Unlock()
+			}
+		case <-wp.shutdownCh:
+			return
+		}
+	}
+}
+
+// laneWorker は hot のタイプの専用レーンだけを処理するワーカー
+// 遅延起動の対象外で、レーンが閉じられ空になるまで常駐する
+func (wp *WorkerPool) laneWorker(id int, taskType TaskType, lane *fastLane, heat *heatTracker) {
+	defer wp.wg.Done()
+
+	defer wp.pinWorkerThread(id)()
+	defer wp.workerStats.started(id)()
+
+	event("worker.lane_started").worker(id).logf("🏎️ 専用ワーカー %d が [%s] のレーンで開始されました\n", id, taskType)
+	for {
+		wp.lifecycle.waitWhilePaused()
+
+		task, err := lane.queue.pop(nil, 0)
+		if err != nil {
+			break
+		}
+		// 取り出したタスクはキュー滞留数から実行中に移す（停止処理がどちらにも数えない瞬間を作らない）
+		heat.addBusy(1)
+		wp.trackQueued(task.Type, -1)
+		wp.lifecycle.waitWhilePaused()
+		if !wp.hold(task) {
+			wp.executeTask(task, id)
+		}
+		heat.addBusy(-1)
+	}
+	event("worker.lane_stopped").worker(id).logf("🛑 専用ワーカー %d が [%s] のレーンを終了しました\n", id, taskType)
+}
+
+// handleHeat は GET /heat でタスクタイプごとの hot / cold の分類を返す
+func (m *Monitor) handleHeat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+	heat := m.pool.Snapshot().Heat
+	if heat == nil {
+		heat = map[TaskType]TaskHeat{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"task_heat": heat})
+}
@@ -0,0 +1,190 @@
+package workerpool
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultHistorySize は Monitor が保持するタスク結果の履歴の既定の件数
+	defaultHistorySize = 1000
+	// defaultHistoryLimit は GET /history で limit を省略した場合に返す件数
+	defaultHistoryLimit = 100
+)
+
+// 履歴の結果の状態
+const (
+	HistorySucceeded = "succeeded" // 成功
+	HistoryFailed    = "failed"    // 最終的に失敗（DLQに送られた）
+	HistoryRetrying  = "retrying"  // 失敗したがリトライされる
+	HistoryCanceled  = "canceled"  // 取り消し・キャンセル
+)
+
+// HistoryEntry はタスク結果の履歴の1件
+type HistoryEntry struct {
+	TaskID       int           `json:"task_id"`
+	TaskName     string        `json:"task_name"`
+	TaskType     TaskType      `json:"task_type"`
+	Status       string        `json:"status"`
+	Error        *ResultError  `json:"error,omitempty"`
+	AttemptCount int           `json:"attempt_count"`
+	WorkerID     int           `json:"worker_id"`
+	Variant      string        `json:"variant,omitempty"`
+	Duration     time.Duration `json:"duration_ns"`
+	EndTime      time.Time     `json:"end_time"`
+}
+
+// historyStatus は結果の状態を返す
+func historyStatus(result TaskResult) string {
+	switch {
+	case result.Success:
+		return HistorySucceeded
+	case result.Canceled:
+		return HistoryCanceled
+	case !result.IsFinal:
+		return HistoryRetrying
+	default:
+		return HistoryFailed
+	}
+}
+
+// newHistoryEntry は結果を履歴の1件に変換する（出力・ペイロードは保持しない）
+func newHistoryEntry(result TaskResult) HistoryEntry {
+	endTime := result.EndTime
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+	return HistoryEntry{
+		TaskID:       result.TaskID,
+		TaskName:     result.TaskName,
+		TaskType:     result.TaskType,
+		Status:       historyStatus(result),
+		Error:        result.resultError(),
+		AttemptCount: result.AttemptCount,
+		WorkerID:     result.WorkerID,
+		Variant:      result.Variant,
+		Duration:     result.Duration,
+		EndTime:      endTime,
+	}
+}
+
+// HistoryFilter は履歴の絞り込み条件（空の項目は条件にしない）
+type HistoryFilter struct {
+	TaskType TaskType
+	Status   string
+	Limit    int // 返す最大件数（0以下ですべて）
+}
+
+// matches は履歴の1件が条件に一致するか判定
+func (f HistoryFilter) matches(entry HistoryEntry) bool {
+	if f.TaskType != "" && entry.TaskType != f.TaskType {
+		return false
+	}
+	if f.Status != "" && entry.Status != f.Status {
+		return false
+	}
+	return true
+}
+
+// taskHistory は直近のタスク結果を保持するリングバッファ（Monitor の mutex で保護する）
+type taskHistory struct {
+	entries []HistoryEntry
+	next    int  // 次に書き込む位置
+	full    bool // 一周して古いものを上書きしている
+}
+
+func newTaskHistory(size int) *taskHistory {
+	return &taskHistory{entries: make([]HistoryEntry, max(size, 1))}
+}
+
+// add は結果を記録する（容量を超えると最も古いものを上書きする）
+func (h *taskHistory) add(entry HistoryEntry) {
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list は条件に一致する履歴を新しい順に返す
+func (h *taskHistory) list(filter HistoryFilter) []HistoryEntry {
+	count := h.next
+	if h.full {
+		count = len(h.entries)
+	}
+	capacity := count
+	if filter.Limit > 0 {
+		capacity = min(capacity, filter.Limit)
+	}
+	entries := make([]HistoryEntry, 0, capacity)
+	for i := 1; i <= count; i++ {
+		entry := h.entries[(h.next-i+len(h.entries))%len(h.entries)]
+		if !filter.matches(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+		if filter.Limit > 0 && len(entries) >= filter.Limit {
+			break
+		}
+	}
+	return entries
+}
+
+// SetHistorySize は保持するタスク結果の履歴の件数を変更する（0以下で履歴を保持しない。既定は1000件）
+// 変更すると保持していた履歴は破棄する
+func (m *Monitor) SetHistorySize(size int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if size <= 0 {
+		m.history = nil
+		return
+	}
+	m.history = newTaskHistory(size)
+}
+
+// History は条件に一致する直近のタスク結果を新しい順に返す
+func (m *Monitor) History(filter HistoryFilter) []HistoryEntry {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.history == nil {
+		return []HistoryEntry{}
+	}
+	return m.history.list(filter)
+}
+
+// handleHistory は GET /history で直近のタスク結果を新しい順に返す
+//
+//	GET /history?limit=100&type=email&status=failed
+func (m *Monitor) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := HistoryFilter{TaskType: TaskType(query.Get("type")), Status: query.Get("status"), Limit: defaultHistoryLimit}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "limit は正の整数で指定してください: "+value)
+			return
+		}
+		filter.Limit = limit
+	}
+	switch filter.Status {
+	case "", HistorySucceeded, HistoryFailed, HistoryRetrying, HistoryCanceled:
+	default:
+		writeJSONError(w, http.StatusBadRequest, "status は succeeded / failed / retrying / canceled のいずれかで指定してください: "+filter.Status)
+		return
+	}
+
+	entries := m.History(filter)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":   len(entries),
+		"history": entries,
+	})
+}
@@ -0,0 +1,92 @@
+package workerpool
+
+import (
+	"time"
+)
+
+// idlePollInterval はアイドル状態を確認する間隔の上限
+const idlePollInterval = 100 * time.Millisecond
+
+// IsIdle は処理待ち・処理中のタスクがないか判定
+// キュー・リトライ待ち・保留中（負荷制御・メンテナンス）・予定時刻待ち（AddTaskAt）が空で、すべてのワーカーがタスク待ちの場合にアイドルとみなす
+func (wp *WorkerPool) IsIdle() bool {
+	if wp.queue.len() > 0 || wp.retries.len() > 0 || wp.delayed.len() > 0 {
+		return false
+	}
+	if queued, busy := wp.heatTracker().pending(); queued+busy > 0 {
+		return false
+	}
+	if queued, busy := wp.queueRouter().pending(); queued+busy > 0 {
+		return false
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return wp.running == wp.idle && len(wp.deferred) == 0 && len(wp.held) == 0
+}
+
+// StopWhenIdle はアイドル状態が grace の間続いた時点でプールを停止する（バッチ処理用）
+// 停止すると返したチャネルが閉じる。他の呼び出しで停止した場合も閉じる。
+// 一時停止中はアイドルとみなさない。結果は停止まで読み出し続けること（結果チャネルが詰まるとワーカーがアイドルにならない）
+func (wp *WorkerPool) StopWhenIdle(grace time.Duration) <-chan struct{} {
+	return stopWhenIdle(grace, wp.State, wp.IsIdle, wp.Stop)
+}
+
+// IsIdle はすべてのサブプールがアイドルか判定
+func (tp *TypedPool) IsIdle() bool {
+	for _, pool := range tp.subPools() {
+		if !pool.IsIdle() {
+			return false
+		}
+	}
+	return true
+}
+
+// StopWhenIdle はすべてのサブプールのアイドル状態が grace の間続いた時点でプールを停止する
+func (tp *TypedPool) StopWhenIdle(grace time.Duration) <-chan struct{} {
+	return stopWhenIdle(grace, tp.State, tp.IsIdle, tp.Stop)
+}
+
+// stopWhenIdle は実行中にアイドル状態が grace の間続いたら stop を呼び出す
+func stopWhenIdle(grace time.Duration, state func() PoolState, idle func() bool, stop func()) <-chan struct{} {
+	done := make(chan struct{})
+	interval := idlePollInterval
+	if grace > 0 && grace < interval {
+		interval = grace
+	}
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var idleSince time.Time
+		for range ticker.C {
+			switch state() {
+			case StateStopped:
+				return
+			case StateRunning:
+			default:
+				// 未開始・一時停止中・停止処理中はアイドルの計測をやり直す
+				idleSince = time.Time{}
+				continue
+			}
+
+			if !idle() {
+				idleSince = time.Time{}
+				continue
+			}
+			if idleSince.IsZero() {
+				idleSince = time.Now()
+			}
+			if time.Since(idleSince) >= grace {
+				event("pool.idle_stop").logf("💤 %v の間アイドルだったためプールを停止します\n", grace)
+				stop()
+				return
+			}
+		}
+	}()
+	return done
+}
@@ -0,0 +1,103 @@
+package workerpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitClosed は done が閉じるまで待つ
+func waitClosed(t *testing.T, done <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s: チャネルが閉じませんでした", what)
+	}
+}
+
+// アイドル状態が続くとプールを停止してチャネルを閉じる（処理中のタスクが終わるまでは停止しない）
+func TestStopWhenIdleStopsAfterTasks(t *testing.T) {
+	pool := NewWorkerPool(1)
+	unblock := make(chan struct{})
+	var ran atomic.Int32
+	pool.RegisterProcessor("slow", func(ctx context.Context, task Task) error {
+		<-unblock
+		ran.Add(1)
+		return nil
+	})
+	pool.OnResult(func(TaskResult) {})
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Stop()
+
+	for id := 1; id <= 2; id++ {
+		if err := pool.AddTask(Task{ID: id, Type: "slow"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	done := pool.StopWhenIdle(50 * time.Millisecond)
+
+	time.Sleep(300 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("処理中のタスクがあるのに停止しました")
+	default:
+	}
+	if pool.IsIdle() {
+		t.Fatal("処理中のタスクがあるのにアイドルと判定されました")
+	}
+
+	close(unblock)
+	waitClosed(t, done, "StopWhenIdle")
+	if got := pool.State(); got != StateStopped {
+		t.Fatalf("State = %s, want stopped", got)
+	}
+	if got := ran.Load(); got != 2 {
+		t.Fatalf("実行されたタスク = %d, want 2", got)
+	}
+}
+
+// 一時停止中はアイドルとみなさず、再開後にアイドルが続いた時点で停止する
+func TestStopWhenIdleWaitsWhilePaused(t *testing.T) {
+	pool := NewTypedPool(map[TaskType]int{"noop": 1})
+	pool.RegisterProcessor("noop", func(ctx context.Context, task Task) error { return nil })
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Stop()
+	if err := pool.Pause(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := pool.StopWhenIdle(20 * time.Millisecond)
+	time.Sleep(300 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("一時停止中に停止しました")
+	default:
+	}
+
+	if err := pool.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	waitClosed(t, done, "StopWhenIdle")
+	if got := pool.State(); got != StateStopped {
+		t.Fatalf("State = %s, want stopped", got)
+	}
+}
+
+// 他の呼び出しで停止した場合もチャネルを閉じる
+func TestStopWhenIdleClosesOnExternalStop(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.RegisterProcessor("noop", func(ctx context.Context, task Task) error { return nil })
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := pool.StopWhenIdle(time.Hour)
+	pool.Stop()
+	waitClosed(t, done, "Stop")
+}
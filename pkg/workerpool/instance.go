@@ -0,0 +1,80 @@
+package workerpool
+
+import (
+	"os"
+	"sync"
+)
+
+// InstanceInfo はプールを動かしているインスタンスの情報
+// 設定すると統計（/stats・/stats/delta）、JSON形式のイベント、結果の出力先の記録に付けて出力し、
+// 複数のインスタンスの統計を集約する側がサービス・リージョン・バージョンごとに分けられるようにする
+type InstanceInfo struct {
+	ID      string            `json:"id,omitempty"` // インスタンスID（空の場合はホスト名）
+	Service string            `json:"service,omitempty"`
+	Region  string            `json:"region,omitempty"`
+	Version string            `json:"version,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"` // その他の任意のラベル（環境・クラスタなど）
+}
+
+// instance はプロセスのインスタンス情報（未設定の場合は nil）
+var instance = struct {
+	mu   sync.RWMutex
+	info *InstanceInfo
+}{}
+
+// SetInstanceInfo はインスタンス情報を設定する（プロセス内のすべてのプールとモニターで共有する）
+func SetInstanceInfo(info InstanceInfo) {
+	if info.ID == "" {
+		info.ID, _ = os.Hostname()
+	}
+	labels := make(map[string]string, len(info.Labels))
+	for key, value := range info.Labels {
+		labels[key] = value
+	}
+	info.Labels = labels
+
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	instance.info = &info
+}
+
+// CurrentInstance は設定されたインスタンス情報を返す（未設定の場合は nil）
+func CurrentInstance() *InstanceInfo {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	if instance.info == nil {
+		return nil
+	}
+	info := *instance.info
+	info.Labels = make(map[string]string, len(instance.info.Labels))
+	for key, value := range instance.info.Labels {
+		info.Labels[key] = value
+	}
+	return &info
+}
+
+// Flatten はインスタンス情報を1階層のラベルにする（id・service・region・version と任意のラベル）
+// 任意のラベルに同じキーがある場合は項目の値を優先する
+func (i *InstanceInfo) Flatten() map[string]string {
+	labels := make(map[string]string, len(i.Labels)+4)
+	for key, value := range i.Labels {
+		labels[key] = value
+	}
+	for key, value := range map[string]string{"id": i.ID, "service": i.Service, "region": i.Region, "version": i.Version} {
+		if value != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// instanceLabels は設定されたインスタンス情報を1階層のラベルで返す（未設定の場合は空）
+func instanceLabels() map[string]string {
+	info := CurrentInstance()
+	if info == nil {
+		return map[string]string{}
+	}
+	return info.Flatten()
+}
@@ -0,0 +1,86 @@
+package workerpool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrTaskCorrupt はタスクの内容がチェックサムと一致しない（永続化・退避の途中で壊れた）場合のエラー
+// リトライしても直らないため、リトライせずに DeadLetterCorrupt としてDLQに送る
+var ErrTaskCorrupt = errors.New("タスクの内容が破損しています")
+
+// checksumPrefix はチェックサムのアルゴリズムを表す接頭辞
+const checksumPrefix = "sha256:"
+
+// taskChecksum はタスクのID・タイプ・ペイロードのチェックサムを計算する
+// ストアを経由すると構造体のペイロードは map に、数値は float64 になるため、
+// JSON をデコードし直した正規形（キーの順序が決まった JSON）から計算し、経由の前後で同じ値になるようにする
+func taskChecksum(task Task) (string, error) {
+	payload, err := json.Marshal(task.Payload)
+	if err != nil {
+		return "", fmt.Errorf("ペイロードを JSON にできません: %w", err)
+	}
+	var canonical interface{}
+	if err := json.Unmarshal(payload, &canonical); err != nil {
+		return "", fmt.Errorf("ペイロードを JSON にできません: %w", err)
+	}
+	data, err := json.Marshal(struct {
+		ID      int         `json:"id"`
+		Type    TaskType    `json:"type"`
+		Payload interface{} `json:"payload"`
+	}{task.ID, task.Type, canonical})
+	if err != nil {
+		return "", fmt.Errorf("ペイロードを JSON にできません: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return checksumPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// sealTask はストアが設定されていれば、永続化する前にタスクのチェックサムを付ける（付いている場合はそのまま）
+func (wp *WorkerPool) sealTask(task Task) (Task, error) {
+	wp.mu.Lock()
+	store := wp.store
+	wp.mu.Unlock()
+	if store == nil || task.Checksum != "" {
+		return task, nil
+	}
+
+	checksum, err := taskChecksum(task)
+	if err != nil {
+		return task, err
+	}
+	task.Checksum = checksum
+	return task, nil
+}
+
+// verifyChecksum は実行の前にタスクの内容がチェックサムと一致するか確認する（チェックサムがない場合は確認しない）
+func verifyChecksum(task Task) error {
+	if task.Checksum == "" {
+		return nil
+	}
+	checksum, err := taskChecksum(task)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTaskCorrupt, err)
+	}
+	if checksum != task.Checksum {
+		return fmt.Errorf("%w: チェックサムが一致しません（記録 %s、実際 %s）", ErrTaskCorrupt, task.Checksum, checksum)
+	}
+	return nil
+}
+
+// deadLetterReasonFor はエラーに応じたDLQへの送信理由を返す
+func deadLetterReasonFor(err error) DeadLetterReason {
+	switch {
+	case errors.Is(err, ErrTaskCorrupt):
+		return DeadLetterCorrupt
+	case errors.Is(err, ErrUntrustedTask):
+		return DeadLetterUntrusted
+	case isCanceled(err):
+		return DeadLetterCanceled
+	default:
+		return DeadLetterFailed
+	}
+}
@@ -0,0 +1,298 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// インクラスタ設定のファイル
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+// KubernetesConfig は KubernetesJobProcessor の設定
+type KubernetesConfig struct {
+	APIServer    string        // API サーバーのURL（例: https://kubernetes.default.svc）
+	Token        string        // サービスアカウントのトークン
+	CAFile       string        // API サーバーの証明書（空の場合はシステムの証明書）
+	Namespace    string        // Job を作成する名前空間
+	PollInterval time.Duration // Job の状態を確認する間隔（0 の場合は2秒）
+	BackoffLimit int32         // Job 内での再試行回数（プールのリトライと二重にならないよう既定は0）
+	TTLSeconds   int32         // 終了後に Job を自動削除するまでの秒数（0 の場合は即時に削除）
+	KillGrace    time.Duration // キャンセル時にポッドを強制終了するまでの猶予（0 の場合はクラスタの既定値）
+}
+
+// InClusterKubernetesConfig はポッド内で実行されている場合のサービスアカウントの設定を返す
+func InClusterKubernetesConfig() (KubernetesConfig, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return KubernetesConfig{}, fmt.Errorf("クラスタ内で実行されていません")
+	}
+	token, err := os.ReadFile(serviceAccountDir + "/token")
+	if err != nil {
+		return KubernetesConfig{}, fmt.Errorf("サービスアカウントのトークンを読み込めません: %w", err)
+	}
+	namespace, err := os.ReadFile(serviceAccountDir + "/namespace")
+	if err != nil {
+		return KubernetesConfig{}, fmt.Errorf("名前空間を読み込めません: %w", err)
+	}
+	return KubernetesConfig{
+		APIServer: "https://" + host + ":" + port,
+		Token:     strings.TrimSpace(string(token)),
+		CAFile:    serviceAccountDir + "/ca.crt",
+		Namespace: strings.TrimSpace(string(namespace)),
+	}, nil
+}
+
+// kubeClient は API サーバーへの最小限のクライアント
+type kubeClient struct {
+	config KubernetesConfig
+	http   *http.Client
+}
+
+func newKubeClient(config KubernetesConfig) (*kubeClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("CA証明書を読み込めません: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA証明書の形式が不正です: %s", config.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &kubeClient{config: config, http: &http.Client{Transport: transport}}, nil
+}
+
+// do はリクエストを送り、JSON の応答を out に読み込む（out が nil の場合は捨てる）
+func (c *kubeClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.config.APIServer, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if _, isLogs := out.(*string); !isLogs {
+		req.Header.Set("Accept", "application/json")
+	}
+	if c.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Retryable(fmt.Errorf("%s: API サーバーに接続できません: %v", containerTransientError, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		message = bytes.TrimSpace(message)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusConflict {
+			return Retryable(fmt.Errorf("%s: API サーバーがエラーを返しました (%d): %s", containerTransientError, resp.StatusCode, message))
+		}
+		return Permanent(fmt.Errorf("%s: API サーバーがエラーを返しました (%d): %s", containerPermanentError, resp.StatusCode, message))
+	}
+	if out == nil {
+		return nil
+	}
+	if logs, ok := out.(*string); ok {
+		data, err := io.ReadAll(resp.Body)
+		*logs = string(data)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// kubeJobStatus は Job の状態（必要なフィールドのみ）
+type kubeJobStatus struct {
+	Status struct {
+		Succeeded  int32 `json:"succeeded"`
+		Failed     int32 `json:"failed"`
+		Conditions []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// NewKubernetesJobProcessor はタスクを Kubernetes の Job として実行するプロセッサを作成する
+// ペイロードは DockerProcessor と同じ DockerTask で、Job の完了を API サーバーで監視する
+// ポッドのログは TaskOutput に書き込まれ、タスクのキャンセル時は Job を削除する（TTLSeconds を指定した場合も削除する）。
+// タスクに期限（タイムアウト）がある場合は期限と KillGrace を activeDeadlineSeconds に設定し、プールが落ちてもクラスタ側で止める
+func NewKubernetesJobProcessor(config KubernetesConfig) (TaskProcessor, error) {
+	if config.APIServer == "" || config.Namespace == "" {
+		return nil, fmt.Errorf("API サーバーと名前空間を指定してください")
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+	client, err := newKubeClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, task Task) error {
+		spec, err := decodeDockerTask(task.Payload)
+		if err != nil {
+			return Permanent(fmt.Errorf("%s: ペイロードが不正です: %v", containerPermanentError, err))
+		}
+
+		name := fmt.Sprintf("workerpool-task-%d-%d-%d", task.ID, task.AttemptCount, time.Now().Unix())
+		jobsPath := "/apis/batch/v1/namespaces/" + url.PathEscape(config.Namespace) + "/jobs"
+		manifest := kubeJobManifest(name, task, spec, config)
+		if deadline, ok := ctx.Deadline(); ok {
+			seconds := int64((time.Until(deadline) + config.KillGrace + time.Second - 1) / time.Second)
+			manifest["spec"].(map[string]interface{})["activeDeadlineSeconds"] = max(seconds, 1)
+		}
+		if err := client.do(ctx, http.MethodPost, jobsPath, manifest, nil); err != nil {
+			return err
+		}
+		event("kubernetes.job_created").taskOf(task).logf("☸️ タスク %d を Job %s として投入しました\n", task.ID, name)
+
+		// 終了後は Job とポッドを削除する（キャンセル時も削除できるよう独立したコンテキストで）
+		// キャンセルした Job は TTL を待たずに削除し、ポッドを止める
+		defer func() {
+			if config.TTLSeconds > 0 && ctx.Err() == nil {
+				return
+			}
+			query := "?propagationPolicy=Background"
+			if ctx.Err() != nil && config.KillGrace > 0 {
+				query += fmt.Sprintf("&gracePeriodSeconds=%d", int64((config.KillGrace+time.Second-1)/time.Second))
+			}
+			deleteCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			client.do(deleteCtx, http.MethodDelete, jobsPath+"/"+name+query, nil, nil)
+		}()
+
+		ticker := time.NewTicker(config.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+
+			var job kubeJobStatus
+			if err := client.do(ctx, http.MethodGet, jobsPath+"/"+name, nil, &job); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				event("kubernetes.job_status_failed").failed(err).logf("⚠️ Job %s の状態を取得できませんでした: %v\n", name, err)
+				continue
+			}
+
+			for _, condition := range job.Status.Conditions {
+				if condition.Status != "True" {
+					continue
+				}
+				switch condition.Type {
+				case "Complete":
+					copyJobLogs(ctx, client, name, TaskOutput(ctx))
+					return nil
+				case "Failed":
+					copyJobLogs(ctx, client, name, TaskOutput(ctx))
+					return Permanent(fmt.Errorf("%s: Job %s が失敗しました (%s: %s)", containerPermanentError, name, condition.Reason, condition.Message))
+				}
+			}
+		}
+	}, nil
+}
+
+// kubeJobManifest は Job のマニフェストを作成する
+func kubeJobManifest(name string, task Task, spec DockerTask, config KubernetesConfig) map[string]interface{} {
+	env := make([]map[string]string, 0, len(spec.Env))
+	keys := make([]string, 0, len(spec.Env))
+	for key := range spec.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, map[string]string{"name": key, "value": spec.Env[key]})
+	}
+
+	container := map[string]interface{}{
+		"name":  "task",
+		"image": spec.Image,
+		"env":   env,
+	}
+	if len(spec.Args) > 0 {
+		container["args"] = spec.Args
+	}
+
+	jobSpec := map[string]interface{}{
+		"backoffLimit": config.BackoffLimit,
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]string{"workerpool/task-id": fmt.Sprint(task.ID)},
+			},
+			"spec": map[string]interface{}{
+				"restartPolicy": "Never",
+				"containers":    []interface{}{container},
+			},
+		},
+	}
+	if config.TTLSeconds > 0 {
+		jobSpec["ttlSecondsAfterFinished"] = config.TTLSeconds
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name": name,
+			"labels": map[string]string{
+				"workerpool/task-id":   fmt.Sprint(task.ID),
+				"workerpool/task-type": string(task.Type),
+			},
+		},
+		"spec": jobSpec,
+	}
+}
+
+// copyJobLogs は Job のポッドのログを出力先に書き込む（取得できない場合は何もしない）
+func copyJobLogs(ctx context.Context, client *kubeClient, jobName string, out io.Writer) {
+	podsPath := "/api/v1/namespaces/" + url.PathEscape(client.config.Namespace) + "/pods"
+
+	var pods struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	selector := url.QueryEscape("job-name=" + jobName)
+	if err := client.do(ctx, http.MethodGet, podsPath+"?labelSelector="+selector, nil, &pods); err != nil {
+		return
+	}
+	for _, pod := range pods.Items {
+		var logs string
+		if err := client.do(ctx, http.MethodGet, podsPath+"/"+pod.Metadata.Name+"/log", nil, &logs); err == nil {
+			io.WriteString(out, logs)
+		}
+	}
+}
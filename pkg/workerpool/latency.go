@@ -0,0 +1,75 @@
+package workerpool
+
+import (
+	"math"
+	"sort"
+)
+
+// latencyAccuracy は処理時間のパーセンタイルの相対誤差
+const latencyAccuracy = 0.01
+
+// latencyGamma は隣り合うバケットの境界の比（相対誤差が latencyAccuracy 以内になるように決める）
+var latencyGamma = (1 + latencyAccuracy) / (1 - latencyAccuracy)
+
+// latencyHistogram は処理時間（ms）の分布を対数のバケットで数えるストリーミングのヒストグラム（DDSketch と同じ方式）
+// 件数によらず保持するのは値の範囲に応じたバケット数だけで、パーセンタイルを相対誤差1%以内で推定できる
+type latencyHistogram struct {
+	buckets map[int]int64 // バケット番号 → 件数（番号 i のバケットは (γ^(i-1), γ^i] の値を数える）
+	zero    int64         // 0ms 以下の件数
+	total   int64
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{buckets: make(map[int]int64)}
+}
+
+// add は処理時間を1件分数える
+func (h *latencyHistogram) add(ms float64) {
+	h.total++
+	if ms <= 0 {
+		h.zero++
+		return
+	}
+	h.buckets[int(math.Ceil(math.Log(ms)/math.Log(latencyGamma)))]++
+}
+
+// quantile は q（0〜1）のパーセンタイルの推定値（ms）を返す（記録がない場合は0）
+func (h *latencyHistogram) quantile(q float64) float64 {
+	if h.total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(h.total)))
+	rank = min(max(rank, 1), h.total)
+	if rank <= h.zero {
+		return 0
+	}
+
+	indexes := make([]int, 0, len(h.buckets))
+	for index := range h.buckets {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	seen := h.zero
+	for _, index := range indexes {
+		seen += h.buckets[index]
+		if seen >= rank {
+			// バケットの境界の中間を推定値にすると、バケット内のどの値とも相対誤差が latencyAccuracy 以内になる
+			return 2 * math.Pow(latencyGamma, float64(index)) / (latencyGamma + 1)
+		}
+	}
+	return 0
+}
+
+// latencyPercentiles は処理時間のパーセンタイル（ms）
+type latencyPercentiles struct {
+	P50, P95, P99 float64
+}
+
+// percentiles は p50 / p95 / p99 を返す
+func (h *latencyHistogram) percentiles() latencyPercentiles {
+	return latencyPercentiles{
+		P50: h.quantile(0.50),
+		P95: h.quantile(0.95),
+		P99: h.quantile(0.99),
+	}
+}
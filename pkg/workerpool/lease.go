@@ -0,0 +1,327 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStaleLease は期限切れのリース（古いフェンシングトークン）で結果を書き込もうとした場合のエラー
+var ErrStaleLease = errors.New("リースが失効しています: 他のインスタンスがタスクを引き取りました")
+
+// LeaseStore はリース（貸し出し）による実行中タスクの管理に対応したストア
+// 複数インスタンスで共有すると、停止したインスタンスが抱えていたタスクを
+// 可視性タイムアウト経過後に他のインスタンスが引き取って再実行できる
+// 各操作はフェンシングトークンの比較と更新を、共有しているすべてのインスタンスに対して不可分に行うこと。
+// 同梱の実装では、複数プロセスで共有できるのは SharedFileStore だけで、
+// MemoryStore・FileStore は同じプロセス内のプールの間でしか排他されない
+type LeaseStore interface {
+	TaskStore
+	// Lease はタスクを owner に until まで貸し出し、新しいフェンシングトークンを返す
+	// 有効なリースが既にある場合や、タスクが既に終了している場合は false を返す
+	Lease(task Task, owner string, until time.Time) (uint64, bool, error)
+	// Renew はトークンが最新の場合に限りリースを until まで延長する
+	Renew(taskID int, token uint64, until time.Time) (bool, error)
+	// Commit はトークンが最新の場合に限り実行結果を保存する（古い場合は ErrStaleLease）
+	Commit(record TaskRecord, token uint64) error
+	// ReclaimExpired は放置されたタスク（リース切れ、または可視性タイムアウトを超えて
+	// 実行されていないもの）を owner に貸し出し直して返す
+	ReclaimExpired(owner string, now time.Time, visibilityTimeout time.Duration) ([]TaskRecord, error)
+}
+
+// SetLeasing はリースによる実行中タスクの管理を有効にする（Start 前に呼び出すこと）
+// ストアが LeaseStore を実装している必要がある。instanceID はインスタンスごとに一意な値を指定する。
+// 複数プロセスで分散する場合は、すべてのプロセスで同じ SharedFileStore（同じパス）を使う
+func (wp *WorkerPool) SetLeasing(instanceID string, visibilityTimeout time.Duration) error {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if _, ok := wp.store.(LeaseStore); !ok {
+		return fmt.Errorf("リースを使うにはストアが LeaseStore を実装している必要があります")
+	}
+	wp.instanceID = instanceID
+	wp.visibilityTimeout = visibilityTimeout
+	return nil
+}
+
+// leaseStore はリースが有効な場合にストアを返す
+func (wp *WorkerPool) leaseStore() (LeaseStore, bool) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	store, ok := wp.store.(LeaseStore)
+	if !ok || wp.visibilityTimeout <= 0 {
+		return nil, false
+	}
+	return store, true
+}
+
+// acquireLease は実行前にタスクのリースを取得し、フェンシングトークンを設定したタスクを返す
+// 他のインスタンスが処理中・処理済みの場合は false を返す。リース無効時は状態を保存するだけ
+func (wp *WorkerPool) acquireLease(task Task) (Task, bool) {
+	store, ok := wp.leaseStore()
+	if !ok {
+		wp.persist(task, TaskStateRunning, task.LastError)
+		return task, true
+	}
+
+	token, leased, err := store.Lease(task, wp.instanceID, time.Now().Add(wp.visibilityTimeout))
+	if err != nil {
+		event("lease.acquire_failed").taskOf(task).failed(err).logf("⚠️ タスク %d のリースを取得できませんでした: %v\n", task.ID, err)
+		return task, false
+	}
+	task.fenceToken = token
+	return task, leased
+}
+
+// commit は実行後の状態を保存する
+// リース有効時はフェンシングトークンを検証し、リースを失った後の書き込み（ゾンビワーカー）は
+// ErrStaleLease を返す。呼び出し側は結果を破棄すること
+func (wp *WorkerPool) commit(task Task, state TaskState, err error) error {
+	store, ok := wp.leaseStore()
+	if !ok || task.fenceToken == 0 {
+		wp.persist(task, state, err)
+		return nil
+	}
+
+	commitErr := store.Commit(wp.newRecord(task, state, err), task.fenceToken)
+	if errors.Is(commitErr, ErrStaleLease) {
+		event("lease.stale").taskOf(task).failed(commitErr).logf("🧟 タスク %d のリースが失効していたため結果を破棄しました\n", task.ID)
+		return commitErr
+	}
+	if commitErr != nil {
+		event("task.commit_failed").taskOf(task).failed(commitErr).logf("⚠️ タスク %d の状態を保存できませんでした: %v\n", task.ID, commitErr)
+	}
+	return nil
+}
+
+// keepLease は実行中のタスクのリースを定期的に延長する
+// 延長できなかった場合（他のインスタンスに引き取られた場合）は cancel で処理を中断する
+// 戻り値の関数を呼ぶと延長を終了する
+func (wp *WorkerPool) keepLease(task Task, cancel context.CancelFunc) func() {
+	store, ok := wp.leaseStore()
+	if !ok {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+
+		ticker := time.NewTicker(wp.visibilityTimeout / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				renewed, err := store.Renew(task.ID, task.fenceToken, time.Now().Add(wp.visibilityTimeout))
+				if err != nil || !renewed {
+					event("lease.lost").taskOf(task).failed(err).logf("⚠️ タスク %d のリースを失ったため処理を中断します\n", task.ID)
+					cancel()
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// 延長処理が完全に終わってから結果を保存するよう、終了を待つ
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// leaseReclaimer は放置されたタスクを定期的に引き取ってキューに投入する
+func (wp *WorkerPool) leaseReclaimer(store LeaseStore) {
+	defer wp.bgWg.Done()
+
+	ticker := time.NewTicker(wp.visibilityTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			records, err := store.ReclaimExpired(wp.instanceID, time.Now(), wp.visibilityTimeout)
+			if err != nil {
+				event("lease.reclaim_failed").failed(err).logf("⚠️ 放置タスクの引き取りに失敗しました: %v\n", err)
+				continue
+			}
+			for _, record := range records {
+				event("lease.reclaimed").taskOf(record.Task).logf("♻️ 放置されていたタスク %d を引き取りました\n", record.Task.ID)
+				if err := wp.enqueue(record.Task); err != nil {
+					return
+				}
+			}
+		case <-wp.shutdownCh:
+			return
+		}
+	}
+}
+
+// Lease はタスクを owner に貸し出す
+func (s *MemoryStore) Lease(task Task, owner string, until time.Time) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := leaseRecord(s.records, task, owner, until)
+	return token, ok, nil
+}
+
+// Renew はリースを延長する
+func (s *MemoryStore) Renew(taskID int, token uint64, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return renewRecord(s.records, taskID, token, until), nil
+}
+
+// Commit はトークンを検証して実行結果を保存する
+func (s *MemoryStore) Commit(record TaskRecord, token uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return commitRecord(s.records, record, token)
+}
+
+// ReclaimExpired は放置されたタスクを owner に貸し出し直して返す
+func (s *MemoryStore) ReclaimExpired(owner string, now time.Time, visibilityTimeout time.Duration) ([]TaskRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return reclaimRecords(s.records, owner, now, visibilityTimeout), nil
+}
+
+// Lease はタスクを owner に貸し出し、ファイルに書き出す
+func (s *FileStore) Lease(task Task, owner string, until time.Time) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := leaseRecord(s.records, task, owner, until)
+	if !ok {
+		return 0, false, nil
+	}
+	return token, true, s.flush()
+}
+
+// Renew はリースを延長し、ファイルに書き出す
+func (s *FileStore) Renew(taskID int, token uint64, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !renewRecord(s.records, taskID, token, until) {
+		return false, nil
+	}
+	return true, s.flush()
+}
+
+// Commit はトークンを検証して実行結果を保存し、ファイルに書き出す
+func (s *FileStore) Commit(record TaskRecord, token uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := commitRecord(s.records, record, token); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// ReclaimExpired は放置されたタスクを owner に貸し出し直して返す
+func (s *FileStore) ReclaimExpired(owner string, now time.Time, visibilityTimeout time.Duration) ([]TaskRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reclaimed := reclaimRecords(s.records, owner, now, visibilityTimeout)
+	if len(reclaimed) == 0 {
+		return nil, nil
+	}
+	return reclaimed, s.flush()
+}
+
+// leaseRecord はリースを取得できればレコードを実行中に更新し、新しいトークンを返す
+// 同じインスタンスであっても有効なリースがある間は取得できない（二重実行の防止）
+func leaseRecord(records map[int]TaskRecord, task Task, owner string, until time.Time) (uint64, bool) {
+	now := time.Now()
+	record, exists := records[task.ID]
+	if exists {
+		if record.State.isTerminal() {
+			return 0, false
+		}
+		if record.State == TaskStateRunning && now.Before(record.LeaseExpiresAt) {
+			return 0, false
+		}
+	}
+
+	token := record.FenceToken + 1
+	records[task.ID] = TaskRecord{
+		Task:           task,
+		State:          TaskStateRunning,
+		LeaseOwner:     owner,
+		LeaseExpiresAt: until,
+		FenceToken:     token,
+		LastError:      record.LastError,
+		UpdatedAt:      now,
+	}
+	return token, true
+}
+
+// renewRecord はトークンが最新の実行中レコードのリースを延長する
+func renewRecord(records map[int]TaskRecord, taskID int, token uint64, until time.Time) bool {
+	record, exists := records[taskID]
+	if !exists || record.State != TaskStateRunning || record.FenceToken != token {
+		return false
+	}
+	record.LeaseExpiresAt = until
+	records[taskID] = record
+	return true
+}
+
+// commitRecord はトークンが最新の場合に限りレコードを書き込む
+func commitRecord(records map[int]TaskRecord, record TaskRecord, token uint64) error {
+	current, exists := records[record.Task.ID]
+	if exists && current.FenceToken != token {
+		return ErrStaleLease
+	}
+	record.FenceToken = token
+	records[record.Task.ID] = record
+	return nil
+}
+
+// reclaimRecords は放置されたレコードを owner に貸し出し直して返す
+func reclaimRecords(records map[int]TaskRecord, owner string, now time.Time, visibilityTimeout time.Duration) []TaskRecord {
+	reclaimed := make([]TaskRecord, 0)
+	for id, record := range records {
+		if !record.abandoned(owner, now, visibilityTimeout) {
+			continue
+		}
+		record.State = TaskStatePending
+		record.LeaseOwner = owner
+		record.LeaseExpiresAt = time.Time{}
+		record.UpdatedAt = now
+		records[id] = record
+		reclaimed = append(reclaimed, record)
+	}
+	return reclaimed
+}
+
+// abandoned はレコードが放置されているか判定
+// キュー待ち・リトライ待ちのレコードの LeaseOwner は投入したインスタンスを表し、
+// owner 自身が投入したものは自分のキューにあるため対象外とする
+func (r *TaskRecord) abandoned(owner string, now time.Time, visibilityTimeout time.Duration) bool {
+	switch r.State {
+	case TaskStateRunning:
+		// 実行中のままリースが切れた
+		return now.After(r.LeaseExpiresAt)
+	case TaskStatePending:
+		// 実行されないまま可視性タイムアウトを超えた
+		return r.LeaseOwner != owner && now.Sub(r.UpdatedAt) > visibilityTimeout
+	case TaskStateRetrying:
+		// リトライ予定時刻を可視性タイムアウト以上過ぎても再実行されていない
+		return r.LeaseOwner != owner && now.Sub(r.NextRetryAt) > visibilityTimeout
+	default:
+		return false
+	}
+}
@@ -0,0 +1,168 @@
+package workerpool
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 古いフェンシングトークンでのコミットは、どのストアでも ErrStaleLease で拒否され記録を上書きしない
+func TestStaleLeaseCommitRejected(t *testing.T) {
+	dir := t.TempDir()
+	stores := map[string]func(t *testing.T) (LeaseStore, LeaseStore){
+		"MemoryStore": func(t *testing.T) (LeaseStore, LeaseStore) {
+			store := NewMemoryStore()
+			return store, store
+		},
+		"FileStore": func(t *testing.T) (LeaseStore, LeaseStore) {
+			store, err := NewFileStore(filepath.Join(dir, "file.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return store, store
+		},
+		// 別々に開いた SharedFileStore は別プロセスと同じくファイルロックだけで排他される
+		"SharedFileStore": func(t *testing.T) (LeaseStore, LeaseStore) {
+			path := filepath.Join(dir, "shared.json")
+			first, err := NewSharedFileStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := NewSharedFileStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return first, second
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			zombie, successor := open(t)
+			task := Task{ID: 1, Type: "report"}
+
+			staleToken, leased, err := zombie.Lease(task, "a", time.Now().Add(-time.Second))
+			if err != nil || !leased {
+				t.Fatalf("Lease = %v, %v", leased, err)
+			}
+			token, leased, err := successor.Lease(task, "b", time.Now().Add(time.Minute))
+			if err != nil || !leased {
+				t.Fatalf("期限切れのリースを引き取れません: %v, %v", leased, err)
+			}
+			if token <= staleToken {
+				t.Fatalf("トークンが増えていません: %d -> %d", staleToken, token)
+			}
+
+			if renewed, err := zombie.Renew(task.ID, staleToken, time.Now().Add(time.Minute)); err != nil || renewed {
+				t.Fatalf("古いトークンで延長できました: %v, %v", renewed, err)
+			}
+			err = zombie.Commit(TaskRecord{Task: task, State: TaskStateCompleted}, staleToken)
+			if !errors.Is(err, ErrStaleLease) {
+				t.Fatalf("古いトークンのコミット = %v, want ErrStaleLease", err)
+			}
+			if err := successor.Commit(TaskRecord{Task: task, State: TaskStateFailed, LastError: "b"}, token); err != nil {
+				t.Fatalf("最新のトークンのコミット = %v", err)
+			}
+
+			// 終了済みのタスクは貸し出さない
+			if _, leased, _ := zombie.Lease(task, "a", time.Now().Add(time.Minute)); leased {
+				t.Fatal("終了済みのタスクを貸し出しました")
+			}
+			pending, err := successor.LoadPending()
+			if err != nil || len(pending) != 0 {
+				t.Fatalf("LoadPending = %+v, %v", pending, err)
+			}
+		})
+	}
+}
+
+// プールはリースを失った後の結果を ErrStaleLease として破棄し、引き取ったインスタンスの記録を残す
+func TestPoolCommitWithStaleTokenDiscarded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, err := NewSharedFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewSharedFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := NewWorkerPool(1)
+	pool.SetTaskStore(store)
+	if err := pool.SetLeasing("a", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	task, leased := pool.acquireLease(Task{ID: 7, Type: "report"})
+	if !leased || task.fenceToken == 0 {
+		t.Fatalf("acquireLease = %v, token %d", leased, task.fenceToken)
+	}
+	time.Sleep(40 * time.Millisecond)
+	reclaimed, err := other.ReclaimExpired("b", time.Now(), 20*time.Millisecond)
+	if err != nil || len(reclaimed) != 1 {
+		t.Fatalf("ReclaimExpired = %+v, %v", reclaimed, err)
+	}
+	if _, leased, err := other.Lease(reclaimed[0].Task, "b", time.Now().Add(time.Minute)); err != nil || !leased {
+		t.Fatalf("引き取ったタスクのリース = %v, %v", leased, err)
+	}
+
+	if err := pool.commit(task, TaskStateCompleted, nil); !errors.Is(err, ErrStaleLease) {
+		t.Fatalf("commit = %v, want ErrStaleLease", err)
+	}
+	pending, err := store.LoadPending()
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("LoadPending = %+v, %v", pending, err)
+	}
+	if record := pending[0]; record.State != TaskStateRunning || record.LeaseOwner != "b" {
+		t.Fatalf("記録が上書きされました: %+v", record)
+	}
+}
+
+// 同じファイルを別々に開いたストアが同時にリースを取り合っても、各タスクを取得できるのは1つだけ
+func TestSharedFileStoreLeaseIsExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+
+	const contenders, tasks = 4, 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	won := make(map[int][]uint64)
+	for i := 0; i < contenders; i++ {
+		store, err := NewSharedFileStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := 1; id <= tasks; id++ {
+				token, leased, err := store.Lease(Task{ID: id, Type: "report"}, "owner", time.Now().Add(time.Minute))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if leased {
+					mu.Lock()
+					won[id] = append(won[id], token)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for id := 1; id <= tasks; id++ {
+		if tokens := won[id]; len(tokens) != 1 || tokens[0] != 1 {
+			t.Errorf("タスク %d のリースを取得したストア = %v, want 1件（トークン1）", id, tokens)
+		}
+	}
+	pending, err := NewSharedFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records, err := pending.LoadPending(); err != nil || len(records) != tasks {
+		t.Fatalf("LoadPending = %d 件, %v, want %d 件", len(records), err, tasks)
+	}
+}
@@ -0,0 +1,297 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidStateTransition は現在の状態から遷移できない操作をした場合のエラー
+var ErrInvalidStateTransition = errors.New("プールの状態遷移が不正です")
+
+// stateHistoryLimit は保持する状態遷移の履歴数
+const stateHistoryLimit = 50
+
+// PoolState はプールのライフサイクル上の状態
+type PoolState int
+
+const (
+	StateCreated  PoolState = iota // 作成済み（未開始）
+	StateRunning                   // 実行中
+	StatePaused                    // 一時停止中（受付は続けるが、新たにタスクを実行しない）
+	StateDraining                  // 停止処理中（残りのタスクを処理している）
+	StateStopped                   // 停止済み
+)
+
+var poolStateNames = map[PoolState]string{
+	StateCreated:  "created",
+	StateRunning:  "running",
+	StatePaused:   "paused",
+	StateDraining: "draining",
+	StateStopped:  "stopped",
+}
+
+func (s PoolState) String() string {
+	if name, ok := poolStateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// MarshalText は状態を名前で出力する
+func (s PoolState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// StateTransition は状態の遷移（状態変更イベント）
+type StateTransition struct {
+	From PoolState `json:"from"`
+	To   PoolState `json:"to"`
+	At   time.Time `json:"at"`
+}
+
+// PoolStateInfo は現在の状態と遷移の記録
+type PoolStateInfo struct {
+	State     PoolState               `json:"state"`
+	Since     time.Time               `json:"since"`      // 現在の状態になった日時
+	EnteredAt map[PoolState]time.Time `json:"entered_at"` // 各状態に最後に遷移した日時
+	History   []StateTransition       `json:"history"`    // 直近の遷移（古い順）
+}
+
+// lifecycle はプールの状態と遷移を管理する
+type lifecycle struct {
+	mu        sync.Mutex
+	state     PoolState
+	entered   map[PoolState]time.Time
+	history   []StateTransition
+	listeners []func(StateTransition)
+	resumed   chan struct{} // 一時停止中に作成し、一時停止を抜けると閉じる
+	starting  sync.Mutex    // begin を直列化する（再開の準備と実行中への遷移の間に他の begin を入れない）
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{
+		state:   StateCreated,
+		entered: map[PoolState]time.Time{StateCreated: time.Now()},
+	}
+}
+
+// current は現在の状態を返す
+func (lc *lifecycle) current() PoolState {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	return lc.state
+}
+
+// transition は状態を遷移させ、登録された関数に通知する。遷移前の状態を返す
+// allowed を指定した場合、現在の状態がそのいずれかでなければ ErrInvalidStateTransition を返す
+func (lc *lifecycle) transition(to PoolState, allowed ...PoolState) (PoolState, error) {
+	lc.mu.Lock()
+	from := lc.state
+	if len(allowed) > 0 && !containsState(allowed, from) {
+		lc.mu.Unlock()
+		return from, fmt.Errorf("%w: %s から %s には遷移できません", ErrInvalidStateTransition, from, to)
+	}
+
+	event := StateTransition{From: from, To: to, At: time.Now()}
+	lc.state = to
+	lc.entered[to] = event.At
+	lc.history = append(lc.history, event)
+	if len(lc.history) > stateHistoryLimit {
+		lc.history = lc.history[len(lc.history)-stateHistoryLimit:]
+	}
+	if from == StatePaused && lc.resumed != nil {
+		close(lc.resumed)
+		lc.resumed = nil
+	}
+	if to == StatePaused {
+		lc.resumed = make(chan struct{})
+	}
+	listeners := append([]func(StateTransition){}, lc.listeners...)
+	lc.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+	return from, nil
+}
+
+// begin は作成済み・停止済みのプールを実行中に遷移させる
+// 停止済みからの再開では、実行中に遷移する前に prepare を呼び出す
+// （実行中と判定した呼び出し元が、作り直す前のチャネルを読まないようにするため）。
+// 既に実行中・一時停止中の場合は何もせず started が false になる（Start を冪等にするため）
+func (lc *lifecycle) begin(prepare func()) (started, restart bool, err error) {
+	lc.starting.Lock()
+	defer lc.starting.Unlock()
+
+	switch from := lc.current(); from {
+	case StateRunning, StatePaused:
+		return false, false, nil
+	case StateStopped:
+		restart = true
+		prepare()
+	}
+	if _, err := lc.transition(StateRunning, StateCreated, StateStopped); err != nil {
+		return false, false, err
+	}
+	return true, restart, nil
+}
+
+// containsState は状態が一覧に含まれるか判定
+func containsState(states []PoolState, state PoolState) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// waitWhilePaused は一時停止中であれば、一時停止を抜けるまで待つ
+func (lc *lifecycle) waitWhilePaused() {
+	for {
+		lc.mu.Lock()
+		resumed := lc.resumed
+		lc.mu.Unlock()
+		if resumed == nil {
+			return
+		}
+		<-resumed
+	}
+}
+
+// subscribe は状態変更時に呼び出す関数を登録する
+func (lc *lifecycle) subscribe(listener func(StateTransition)) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.listeners = append(lc.listeners, listener)
+}
+
+// info は現在の状態と遷移の記録を返す
+func (lc *lifecycle) info() PoolStateInfo {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	info := PoolStateInfo{
+		State:     lc.state,
+		Since:     lc.entered[lc.state],
+		EnteredAt: make(map[PoolState]time.Time, len(lc.entered)),
+		History:   append([]StateTransition(nil), lc.history...),
+	}
+	for state, at := range lc.entered {
+		info.EnteredAt[state] = at
+	}
+	return info
+}
+
+// State はプールの現在の状態を返す
+func (wp *WorkerPool) State() PoolState {
+	return wp.lifecycle.current()
+}
+
+// StateInfo はプールの状態と遷移の日時を返す
+func (wp *WorkerPool) StateInfo() PoolStateInfo {
+	return wp.lifecycle.info()
+}
+
+// OnStateChange は状態が変わるたびに呼び出す関数を登録する
+// 関数は遷移させた操作（Start・Pause など）の呼び出し元で同期的に呼ばれるため、長時間ブロックしないこと
+func (wp *WorkerPool) OnStateChange(listener func(StateTransition)) {
+	wp.lifecycle.subscribe(listener)
+}
+
+// Pause はタスクの実行を一時停止する（実行中のタスクは最後まで処理する）
+// 一時停止中もタスクの受付とリトライ待ちの再投入は続き、Resume で実行を再開する
+func (wp *WorkerPool) Pause() error {
+	if _, err := wp.lifecycle.transition(StatePaused, StateRunning); err != nil {
+		return err
+	}
+	event("pool.paused").logln("⏸️ ワーカープールを一時停止しました")
+	return nil
+}
+
+// Resume は一時停止したタスクの実行を再開する
+func (wp *WorkerPool) Resume() error {
+	if _, err := wp.lifecycle.transition(StateRunning, StatePaused); err != nil {
+		return err
+	}
+	event("pool.resumed").logln("▶️ ワーカープールを再開しました")
+	return nil
+}
+
+// State はプールの現在の状態を返す
+func (tp *TypedPool) State() PoolState {
+	return tp.lifecycle.current()
+}
+
+// StateInfo はプールの状態と遷移の日時を返す
+func (tp *TypedPool) StateInfo() PoolStateInfo {
+	return tp.lifecycle.info()
+}
+
+// OnStateChange は状態が変わるたびに呼び出す関数を登録する（サブプール個別の遷移は通知しない）
+func (tp *TypedPool) OnStateChange(listener func(StateTransition)) {
+	tp.lifecycle.subscribe(listener)
+}
+
+// Pause はすべてのサブプールの実行を一時停止する
+func (tp *TypedPool) Pause() error {
+	if _, err := tp.lifecycle.transition(StatePaused, StateRunning); err != nil {
+		return err
+	}
+	for _, pool := range tp.subPools() {
+		pool.Pause()
+	}
+	return nil
+}
+
+// Resume はすべてのサブプールの実行を再開する
+func (tp *TypedPool) Resume() error {
+	if _, err := tp.lifecycle.transition(StateRunning, StatePaused); err != nil {
+		return err
+	}
+	for _, pool := range tp.subPools() {
+		pool.Resume()
+	}
+	return nil
+}
+
+// handleState はプールの状態と遷移の日時を返す（GET /state）
+func (m *Monitor) handleState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+	writeJSON(w, http.StatusOK, m.pool.StateInfo())
+}
+
+// handleStateAction はプールを一時停止・再開する（POST /pause, POST /resume）
+func (m *Monitor) handleStateAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "POST のみ対応しています")
+		return
+	}
+
+	var err error
+	switch action := strings.TrimPrefix(r.URL.Path, "/"); action {
+	case "pause":
+		err = m.poolFor(r).Pause()
+	case "resume":
+		err = m.poolFor(r).Resume()
+	default:
+		writeJSONError(w, http.StatusNotFound, "不明な操作です: "+action)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, m.pool.StateInfo())
+}
@@ -0,0 +1,223 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 停止と再開を繰り返している間も投入・結果の取得と競合しない（go test -race で確認する）
+func TestRestartWhileSubmitting(t *testing.T) {
+	pools := map[string]func() restartablePool{
+		"WorkerPool": func() restartablePool { return NewWorkerPool(2) },
+		"TypedPool":  func() restartablePool { return NewTypedPool(map[TaskType]int{"noop": 2}) },
+	}
+	for name, newPool := range pools {
+		t.Run(name, func(t *testing.T) {
+			pool := newPool()
+			pool.RegisterProcessor("noop", func(ctx context.Context, task Task) error { return nil })
+			if err := pool.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			stop := make(chan struct{})
+			var wg sync.WaitGroup
+			var nextID atomic.Int64
+			loop := func(call func()) {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						select {
+						case <-stop:
+							return
+						default:
+							call()
+						}
+					}
+				}()
+			}
+			for i := 0; i < 4; i++ {
+				loop(func() { pool.AddTask(Task{ID: int(nextID.Add(1)), Type: "noop"}) })
+			}
+			loop(func() { pool.GetResultsWhere(matchAll, 0) })
+			loop(func() { pool.GetResultByTaskID(int(nextID.Load()), 0) })
+			loop(func() { pool.Results() })
+
+			for i := 0; i < 20; i++ {
+				pool.Stop()
+				if err := pool.Start(); err != nil {
+					t.Fatal(err)
+				}
+				if state := pool.State(); state != StateRunning {
+					t.Fatalf("再開後の状態 = %s, want running", state)
+				}
+			}
+			close(stop)
+			wg.Wait()
+			pool.Stop()
+		})
+	}
+}
+
+// restartablePool は再開のテストで使う、条件付きで結果を取得できるプール
+type restartablePool interface {
+	Pool
+	GetResultsWhere(match func(TaskResult) bool, timeout time.Duration) []TaskResult
+	GetResultByTaskID(taskID int, timeout time.Duration) (TaskResult, error)
+}
+
+// 同時に Start を呼び出しても再開の準備は1回だけ行われる
+func TestConcurrentStartRestartsOnce(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.RegisterProcessor("noop", func(ctx context.Context, task Task) error { return nil })
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pool.Stop()
+
+	var restarts atomic.Int32
+	pool.OnStateChange(func(transition StateTransition) {
+		if transition.From == StateStopped && transition.To == StateRunning {
+			restarts.Add(1)
+		}
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pool.Start(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	defer pool.Stop()
+
+	if got := restarts.Load(); got != 1 {
+		t.Fatalf("再開の回数 = %d, want 1", got)
+	}
+	if err := pool.AddTask(Task{ID: 1, Type: "noop"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.GetResultByTaskID(1, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// サブプールを開始できない場合は開始済みのサブプールを止めて停止済みに戻し、再度 Start できる
+func TestTypedPoolStartFailureStopsStartedSubPools(t *testing.T) {
+	types := []TaskType{"a", "b", "c"}
+	pool := NewTypedPool(map[TaskType]int{"a": 1, "b": 1, "c": 1})
+	for _, taskType := range types {
+		pool.RegisterProcessor(taskType, func(ctx context.Context, task Task) error { return nil })
+	}
+	broken, _ := pool.SubPool("b")
+	broken.lifecycle.transition(StateDraining) // 停止処理中のサブプールは開始できない
+
+	if err := pool.Start(); err == nil {
+		t.Fatal("サブプールを開始できないのに Start が成功しました")
+	}
+	if got := pool.State(); got != StateStopped {
+		t.Fatalf("State = %s, want stopped", got)
+	}
+	for _, taskType := range []TaskType{"a", "c"} {
+		sub, _ := pool.SubPool(taskType)
+		if got := sub.State(); got == StateRunning {
+			t.Fatalf("サブプール %s が動き続けています", taskType)
+		}
+	}
+
+	broken.lifecycle.transition(StateStopped)
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for id, taskType := range types {
+		if result, err := pool.Execute(ctx, Task{ID: id + 1, Type: taskType}); err != nil || !result.Success {
+			t.Fatalf("サブプール %s: result = %+v, err = %v", taskType, result, err)
+		}
+	}
+}
+
+// 一時停止中はタスクを受け付けるが実行せず、再開すると実行する
+func TestPauseBlocksDispatchUntilResume(t *testing.T) {
+	type pausablePool interface {
+		restartablePool
+		OnStateChange(listener func(StateTransition))
+	}
+	pools := map[string]func() pausablePool{
+		"WorkerPool": func() pausablePool { return NewWorkerPool(2) },
+		"TypedPool":  func() pausablePool { return NewTypedPool(map[TaskType]int{"noop": 2}) },
+	}
+	for name, newPool := range pools {
+		t.Run(name, func(t *testing.T) {
+			pool := newPool()
+			var ran atomic.Int32
+			pool.RegisterProcessor("noop", func(ctx context.Context, task Task) error {
+				ran.Add(1)
+				return nil
+			})
+			var mu sync.Mutex
+			var transitions []PoolState
+			pool.OnStateChange(func(transition StateTransition) {
+				mu.Lock()
+				transitions = append(transitions, transition.To)
+				mu.Unlock()
+			})
+
+			if err := pool.Pause(); !errors.Is(err, ErrInvalidStateTransition) {
+				t.Fatalf("開始前の Pause error = %v, want ErrInvalidStateTransition", err)
+			}
+			if err := pool.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer pool.Stop()
+			if err := pool.Resume(); !errors.Is(err, ErrInvalidStateTransition) {
+				t.Fatalf("実行中の Resume error = %v, want ErrInvalidStateTransition", err)
+			}
+			if err := pool.Pause(); err != nil {
+				t.Fatal(err)
+			}
+			if got := pool.State(); got != StatePaused {
+				t.Fatalf("State = %s, want paused", got)
+			}
+
+			for id := 1; id <= 3; id++ {
+				if err := pool.AddTask(Task{ID: id, Type: "noop"}); err != nil {
+					t.Fatalf("一時停止中の AddTask: %v", err)
+				}
+			}
+			time.Sleep(200 * time.Millisecond)
+			if got := ran.Load(); got != 0 {
+				t.Fatalf("一時停止中に %d 件実行されました", got)
+			}
+
+			if err := pool.Resume(); err != nil {
+				t.Fatal(err)
+			}
+			for id := 1; id <= 3; id++ {
+				if _, err := pool.GetResultByTaskID(id, 5*time.Second); err != nil {
+					t.Fatalf("再開後のタスク %d: %v", id, err)
+				}
+			}
+			if got := ran.Load(); got != 3 {
+				t.Fatalf("再開後に実行されたタスク = %d, want 3", got)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if want := []PoolState{StateRunning, StatePaused, StateRunning}; !slices.Equal(transitions, want) {
+				t.Errorf("状態変更イベント = %v, want %v", transitions, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidMaintenanceWindow はメンテナンスウィンドウの設定が不正な場合のエラー
+var ErrInvalidMaintenanceWindow = errors.New("メンテナンスウィンドウの設定が不正です")
+
+// MaintenanceWindow はタスクを実行しない時間帯（毎日または指定した曜日）
+// 時間帯に入ったタスクは保留され、時間帯が終わると自動的にキューに戻る
+type MaintenanceWindow struct {
+	Name      string         `json:"name"`
+	TaskTypes []TaskType     `json:"task_types,omitempty"` // 対象のタスクタイプ（空の場合はすべて）
+	Start     string         `json:"start"`                // 開始時刻（HH:MM）
+	End       string         `json:"end"`                  // 終了時刻（HH:MM、開始より前の場合は日をまたぐ）
+	TimeZone  string         `json:"time_zone,omitempty"`  // IANAタイムゾーン名（例: Asia/Tokyo、空の場合はローカル）
+	Weekdays  []time.Weekday `json:"weekdays,omitempty"`   // 開始する曜日（0=日曜、空の場合は毎日）
+
+	start    time.Duration // 0:00 からの経過時間
+	end      time.Duration
+	location *time.Location
+}
+
+// compile は時刻とタイムゾーンを解釈する
+func (mw *MaintenanceWindow) compile() error {
+	var err error
+	if mw.start, err = parseClock(mw.Start); err != nil {
+		return fmt.Errorf("%w: %s の開始時刻: %v", ErrInvalidMaintenanceWindow, mw.Name, err)
+	}
+	if mw.end, err = parseClock(mw.End); err != nil {
+		return fmt.Errorf("%w: %s の終了時刻: %v", ErrInvalidMaintenanceWindow, mw.Name, err)
+	}
+	if mw.start == mw.end {
+		return fmt.Errorf("%w: %s の開始時刻と終了時刻が同じです", ErrInvalidMaintenanceWindow, mw.Name)
+	}
+
+	mw.location = time.Local
+	if mw.TimeZone != "" {
+		if mw.location, err = time.LoadLocation(mw.TimeZone); err != nil {
+			return fmt.Errorf("%w: %s のタイムゾーン: %v", ErrInvalidMaintenanceWindow, mw.Name, err)
+		}
+	}
+	return nil
+}
+
+// parseClock は HH:MM 形式の時刻を 0:00 からの経過時間に変換する
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("時刻 %q は HH:MM 形式で指定してください", clock)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// appliesTo はタスクタイプが対象か判定
+func (mw MaintenanceWindow) appliesTo(taskType TaskType) bool {
+	if len(mw.TaskTypes) == 0 {
+		return true
+	}
+	for _, t := range mw.TaskTypes {
+		if t == taskType {
+			return true
+		}
+	}
+	return false
+}
+
+// activeUntil は now が時間帯に含まれる場合に、その時間帯の終了日時を返す
+func (mw MaintenanceWindow) activeUntil(now time.Time) (time.Time, bool) {
+	local := now.In(mw.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, mw.location)
+
+	// 日をまたぐ時間帯は前日に始まったものも確認する
+	for _, dayStart := range []time.Time{midnight, midnight.AddDate(0, 0, -1)} {
+		if !mw.onWeekday(dayStart.Weekday()) {
+			continue
+		}
+		start := dayStart.Add(mw.start)
+		end := dayStart.Add(mw.end)
+		if mw.end < mw.start {
+			end = dayStart.AddDate(0, 0, 1).Add(mw.end)
+		}
+		if !now.Before(start) && now.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// onWeekday は指定した曜日に時間帯が始まるか判定
+func (mw MaintenanceWindow) onWeekday(weekday time.Weekday) bool {
+	if len(mw.Weekdays) == 0 {
+		return true
+	}
+	for _, w := range mw.Weekdays {
+		if w == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// SetMaintenanceWindows はメンテナンスウィンドウを設定する（空で解除）
+// 解除や変更で対象外になった保留中のタスクは次の確認時にキューに戻る
+func (wp *WorkerPool) SetMaintenanceWindows(windows []MaintenanceWindow) error {
+	compiled, err := compileMaintenanceWindows(windows)
+	if err != nil {
+		return err
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.maintenance = compiled
+	return nil
+}
+
+// compileMaintenanceWindows はすべての時間帯の設定を解釈した複製を返す
+func compileMaintenanceWindows(windows []MaintenanceWindow) ([]MaintenanceWindow, error) {
+	compiled := make([]MaintenanceWindow, len(windows))
+	for i, window := range windows {
+		if err := window.compile(); err != nil {
+			return nil, err
+		}
+		compiled[i] = window
+	}
+	return compiled, nil
+}
+
+// MaintenanceWindows は設定中のメンテナンスウィンドウを返す
+func (wp *WorkerPool) MaintenanceWindows() []MaintenanceWindow {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return append([]MaintenanceWindow(nil), wp.maintenance...)
+}
+
+// HeldCount はメンテナンスウィンドウ・ブラックアウト期間・下流の障害のため保留中のタスク数を返す
+func (wp *WorkerPool) HeldCount() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return len(wp.held)
+}
+
+// pausedFor はタスクタイプが現在メンテナンス中・ブラックアウト期間中・下流の障害中であれば、その理由と終了日時を返す
+// 下流の障害の場合は次のヘルスチェックの予定日時を返す
+func (wp *WorkerPool) pausedFor(taskType TaskType, now time.Time) (string, time.Time, bool) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return wp.pausedForLocked(taskType, now)
+}
+
+// pausedForLocked は pausedFor と同じ（wp.mu を保持して呼び出すこと）
+func (wp *WorkerPool) pausedForLocked(taskType TaskType, now time.Time) (string, time.Time, bool) {
+	for _, window := range wp.maintenance {
+		if !window.appliesTo(taskType) {
+			continue
+		}
+		if end, active := window.activeUntil(now); active {
+			return "メンテナンス " + window.Name, end, true
+		}
+	}
+	if wp.blackouts != nil {
+		if blackout, active := wp.blackouts.Active(taskType, now); active {
+			return "ブラックアウト " + blackout.Name, blackout.End, true
+		}
+	}
+	if probe, exists := wp.probes[taskType]; exists {
+		if lastErr, nextCheck, down := probe.unhealthy(); down {
+			return fmt.Sprintf("下流の障害（%s）", lastErr), nextCheck, true
+		}
+	}
+	return "", time.Time{}, false
+}
+
+// hold はメンテナンス中・ブラックアウト期間中・下流の障害中のタイプのタスクを保留する
+// タスクを保留した場合は true を返す（呼び出し側はキューに投入・実行しない）
+func (wp *WorkerPool) hold(task Task) bool {
+	wp.mu.Lock()
+	reason, end, paused := wp.pausedForLocked(task.Type, time.Now())
+	if paused {
+		wp.held = append(wp.held, task)
+	}
+	wp.mu.Unlock()
+
+	if paused {
+		event("task.held").taskOf(task).logf("🚧 %s のためタスク %d を %s まで保留しました\n",
+			reason, task.ID, end.Format("01/02 15:04"))
+	}
+	return paused
+}
+
+// releaseHeld はメンテナンス・ブラックアウト期間が終わったタイプ、下流が回復したタイプの保留中のタスクをキューに戻す
+func (wp *WorkerPool) releaseHeld() {
+	now := time.Now()
+	wp.mu.Lock()
+	var released []Task
+	kept := wp.held[:0]
+	for _, task := range wp.held {
+		if _, _, paused := wp.pausedForLocked(task.Type, now); paused {
+			kept = append(kept, task)
+		} else {
+			released = append(released, task)
+		}
+	}
+	wp.held = kept
+	wp.mu.Unlock()
+
+	for i, task := range released {
+		if err := wp.enqueue(task); err != nil {
+			// 停止中のため戻せなかったタスクは失われないようDLQに記録
+			for _, rest := range released[i:] {
+				wp.dlq.Add(rest, DeadLetterShutdown, ErrPoolStopped)
+			}
+			return
+		}
+		event("task.released").taskOf(task).logf("▶️ 保留の理由（メンテナンス・ブラックアウト期間・下流の障害）が解消したため、タスク %d をキューに戻しました\n", task.ID)
+	}
+}
+
+// SetMaintenanceWindows はすべてのサブプールにメンテナンスウィンドウを設定する
+// 対象タイプの限定は TaskTypes で行う（サブプールごとに対象外のタイプは影響を受けない）
+func (tp *TypedPool) SetMaintenanceWindows(windows []MaintenanceWindow) error {
+	compiled, err := compileMaintenanceWindows(windows)
+	if err != nil {
+		return err
+	}
+	for _, pool := range tp.subPools() {
+		if err := pool.SetMaintenanceWindows(compiled); err != nil {
+			return err
+		}
+	}
+	tp.mu.Lock()
+	tp.maintenance = compiled
+	tp.mu.Unlock()
+	return nil
+}
+
+// MaintenanceWindows は設定中のメンテナンスウィンドウを返す
+func (tp *TypedPool) MaintenanceWindows() []MaintenanceWindow {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	return append([]MaintenanceWindow(nil), tp.maintenance...)
+}
+
+// maintenanceStatus はメンテナンスウィンドウと現在の状態
+type maintenanceStatus struct {
+	MaintenanceWindow
+	Active    bool       `json:"active"`
+	ActiveEnd *time.Time `json:"active_until,omitempty"`
+}
+
+// handleMaintenance はメンテナンスウィンドウの一覧と現在の状態、下流のヘルスチェックの状況を返す（GET /maintenance）
+func (m *Monitor) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+
+	now := time.Now()
+	windows := m.pool.MaintenanceWindows()
+	statuses := make([]maintenanceStatus, 0, len(windows))
+	for _, window := range windows {
+		status := maintenanceStatus{MaintenanceWindow: window}
+		if end, active := window.activeUntil(now); active {
+			status.Active = true
+			status.ActiveEnd = &end
+		}
+		statuses = append(statuses, status)
+	}
+	snapshot := m.pool.Snapshot()
+	downstream := snapshot.Downstream
+	if downstream == nil {
+		downstream = []DownstreamStatus{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"windows":    statuses,
+		"downstream": downstream,
+		"held":       snapshot.HeldTasks,
+	})
+}
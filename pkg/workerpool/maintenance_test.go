@@ -0,0 +1,159 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindowActiveUntil(t *testing.T) {
+	tests := []struct {
+		name     string
+		window   MaintenanceWindow
+		now      time.Time
+		want     time.Time
+		isActive bool
+	}{
+		{
+			name:     "inside",
+			window:   MaintenanceWindow{Start: "02:00", End: "04:00"},
+			now:      time.Date(2024, 3, 20, 3, 0, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 20, 4, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		{
+			name:   "end is exclusive",
+			window: MaintenanceWindow{Start: "02:00", End: "04:00"},
+			now:    time.Date(2024, 3, 20, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "across midnight before midnight",
+			window:   MaintenanceWindow{Start: "23:00", End: "01:00"},
+			now:      time.Date(2024, 3, 20, 23, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 21, 1, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		{
+			name:     "across midnight after midnight",
+			window:   MaintenanceWindow{Start: "23:00", End: "01:00"},
+			now:      time.Date(2024, 3, 21, 0, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 21, 1, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		{
+			name: "weekday is the start day",
+			// 水曜 23:00 から木曜 01:00 まで
+			window:   MaintenanceWindow{Start: "23:00", End: "01:00", Weekdays: []time.Weekday{time.Wednesday}},
+			now:      time.Date(2024, 3, 21, 0, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 21, 1, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		{
+			name:   "other weekday",
+			window: MaintenanceWindow{Start: "23:00", End: "01:00", Weekdays: []time.Weekday{time.Thursday}},
+			now:    time.Date(2024, 3, 21, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "time zone",
+			window:   MaintenanceWindow{Start: "02:00", End: "04:00", TimeZone: "Asia/Tokyo"},
+			now:      time.Date(2024, 3, 19, 18, 0, 0, 0, time.UTC), // 3/20 03:00 JST
+			want:     time.Date(2024, 3, 19, 19, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := tt.window
+			if window.TimeZone == "" {
+				window.TimeZone = "UTC"
+			}
+			if err := window.compile(); err != nil {
+				t.Fatal(err)
+			}
+			end, active := window.activeUntil(tt.now)
+			if active != tt.isActive || !end.Equal(tt.want) {
+				t.Errorf("activeUntil = (%s, %v), want (%s, %v)", end, active, tt.want, tt.isActive)
+			}
+		})
+	}
+}
+
+func TestSetMaintenanceWindowsInvalid(t *testing.T) {
+	pool := NewWorkerPool(1)
+	for _, window := range []MaintenanceWindow{
+		{Name: "start", Start: "24:00", End: "01:00"},
+		{Name: "end", Start: "01:00", End: "1時"},
+		{Name: "same", Start: "01:00", End: "01:00"},
+		{Name: "zone", Start: "01:00", End: "02:00", TimeZone: "Mars/Olympus"},
+	} {
+		if err := pool.SetMaintenanceWindows([]MaintenanceWindow{window}); !errors.Is(err, ErrInvalidMaintenanceWindow) {
+			t.Errorf("%s: error = %v, want ErrInvalidMaintenanceWindow", window.Name, err)
+		}
+	}
+	if got := len(pool.MaintenanceWindows()); got != 0 {
+		t.Errorf("不正な設定のあとのメンテナンスウィンドウ = %d 件, want 0", got)
+	}
+}
+
+// メンテナンス中の対象タイプのタスクは保留され、時間帯が終わると（ここでは解除すると）自動的に実行される
+func TestMaintenanceWindowHoldsAndReleasesTasks(t *testing.T) {
+	pool := NewWorkerPool(1)
+	var reports, emails atomic.Int32
+	pool.RegisterProcessor("report", func(ctx context.Context, task Task) error {
+		reports.Add(1)
+		return nil
+	})
+	pool.RegisterProcessor("email", func(ctx context.Context, task Task) error {
+		emails.Add(1)
+		return nil
+	})
+	pool.OnResult(func(TaskResult) {})
+
+	// 現在時刻を含む時間帯（前後1時間）
+	now := time.Now().UTC()
+	err := pool.SetMaintenanceWindows([]MaintenanceWindow{{
+		Name:      "nightly",
+		TaskTypes: []TaskType{"report"},
+		Start:     now.Add(-time.Hour).Format("15:04"),
+		End:       now.Add(time.Hour).Format("15:04"),
+		TimeZone:  "UTC",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Stop()
+
+	if err := pool.AddTask(Task{ID: 1, Type: "report"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.AddTask(Task{ID: 2, Type: "email"}); err != nil {
+		t.Fatal(err)
+	}
+	// 対象外のタイプは実行される
+	waitFor(t, func() bool { return emails.Load() == 1 })
+	if got := pool.HeldCount(); got != 1 {
+		t.Fatalf("HeldCount = %d, want 1", got)
+	}
+	if pool.IsIdle() {
+		t.Fatal("保留中のタスクがあるのにアイドルと判定されました")
+	}
+
+	// 保留の解除の判定が走っても時間帯の間は保留したまま
+	time.Sleep(memorySampleInterval + 200*time.Millisecond)
+	if got := reports.Load(); got != 0 {
+		t.Fatalf("メンテナンス中にタスクが %d 件実行されました", got)
+	}
+
+	if err := pool.SetMaintenanceWindows(nil); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return reports.Load() == 1 })
+	if got := pool.HeldCount(); got != 0 {
+		t.Fatalf("解除後の HeldCount = %d, want 0", got)
+	}
+}
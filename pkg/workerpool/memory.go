@@ -0,0 +1,296 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMemoryPressure はメモリ使用量が上限を超えているため新しいタスクの受付を止めている場合のエラー
+	ErrMemoryPressure = errors.New("メモリ使用量が上限を超えているため受付を停止しています")
+	// ErrInvalidMemoryWatermark はメモリ使用量の監視設定が不正な場合のエラー
+	ErrInvalidMemoryWatermark = errors.New("メモリ使用量の監視設定が不正です")
+)
+
+const (
+	// defaultMemoryCheckInterval はメモリ使用量を確認する間隔の既定値
+	defaultMemoryCheckInterval = time.Second
+	// defaultMemoryShrink は上限を超えている間に減らすワーカー数の割合の既定値
+	defaultMemoryShrink = 0.5
+	// defaultMaxGCBackoff はGCを繰り返す間隔の上限の既定値
+	defaultMaxGCBackoff = 30 * time.Second
+)
+
+// MemorySource はメモリ使用量として監視する値
+type MemorySource string
+
+const (
+	MemorySourceHeap MemorySource = "heap" // Goのヒープ使用量（HeapAlloc）
+	MemorySourceRSS  MemorySource = "rss"  // プロセスの常駐メモリ（Linux 以外では Sys で代用）
+)
+
+// MemoryWatermark はメモリ使用量の監視設定
+// 使用量が High を超えると新しいタスクの受付を止め、ワーカーを減らしてGCを実行する。
+// Low を下回るまでGCを間隔を空けながら繰り返し、下回った時点でワーカー数を戻して受付を再開する。
+// 大きなペイロードが一度に届いた場合に、コンテナがOOMで強制終了されるのを防ぐためのもの
+type MemoryWatermark struct {
+	High         uint64        `json:"high_bytes"`        // 受付を止める使用量
+	Low          uint64        `json:"low_bytes"`         // 受付を再開する使用量（0 の場合は High の9割）
+	Source       MemorySource  `json:"source"`            // 監視する値（空の場合は heap）
+	Interval     time.Duration `json:"interval_ns"`       // 確認する間隔（0 の場合は1秒）
+	Shrink       float64       `json:"shrink"`            // 上限を超えている間のワーカー数の割合（0 の場合は0.5、1 で減らさない）
+	MaxGCBackoff time.Duration `json:"max_gc_backoff_ns"` // GCを繰り返す間隔の上限（0 の場合は30秒。間隔は Interval から倍々に延ばす）
+}
+
+// withDefaults は既定値を補った設定を返す
+func (w MemoryWatermark) withDefaults() MemoryWatermark {
+	if w.Low == 0 || w.Low > w.High {
+		w.Low = w.High / 10 * 9
+	}
+	if w.Source == "" {
+		w.Source = MemorySourceHeap
+	}
+	if w.Interval <= 0 {
+		w.Interval = defaultMemoryCheckInterval
+	}
+	if w.Shrink <= 0 || w.Shrink > 1 {
+		w.Shrink = defaultMemoryShrink
+	}
+	if w.MaxGCBackoff <= 0 {
+		w.MaxGCBackoff = defaultMaxGCBackoff
+	}
+	return w
+}
+
+// MemoryStatus はメモリ使用量の監視状況
+type MemoryStatus struct {
+	Source   MemorySource `json:"source"`
+	Usage    uint64       `json:"usage_bytes"` // 直近の使用量
+	High     uint64       `json:"high_bytes"`
+	Low      uint64       `json:"low_bytes"`
+	Pressure bool         `json:"pressure"`        // 上限を超えて受付を止めている
+	Since    time.Time    `json:"since,omitempty"` // 受付を止めた日時
+	GCs      int64        `json:"gcs"`             // 上限を超えて実行したGCの回数
+	Rejected int64        `json:"rejected"`        // 受付を止めている間に拒否したタスク数
+}
+
+// memoryGuard はメモリ使用量を監視し、上限を超えた場合の受付停止とワーカーの縮小を管理する
+type memoryGuard struct {
+	mu       sync.Mutex
+	policy   MemoryWatermark
+	status   MemoryStatus
+	restore  int           // 縮小前のワーカー数（縮小していない場合は0）
+	backoff  time.Duration // 次のGCまでの間隔
+	nextGCAt time.Time
+}
+
+func newMemoryGuard(policy MemoryWatermark) *memoryGuard {
+	policy = policy.withDefaults()
+	return &memoryGuard{
+		policy: policy,
+		status: MemoryStatus{Source: policy.Source, High: policy.High, Low: policy.Low},
+	}
+}
+
+// SetMemoryWatermark はメモリ使用量の監視を設定する（Start 前に呼び出すこと。nil で無効化）
+func (wp *WorkerPool) SetMemoryWatermark(watermark *MemoryWatermark) error {
+	if watermark == nil {
+		wp.mu.Lock()
+		wp.memory = nil
+		wp.mu.Unlock()
+		return nil
+	}
+	if watermark.High == 0 {
+		return fmt.Errorf("%w: high_bytes を指定してください", ErrInvalidMemoryWatermark)
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.memory = newMemoryGuard(*watermark)
+	return nil
+}
+
+// SetMemoryWatermark はすべてのサブプールにメモリ使用量の監視を設定する
+func (tp *TypedPool) SetMemoryWatermark(watermark *MemoryWatermark) error {
+	for _, pool := range tp.subPools() {
+		if err := pool.SetMemoryWatermark(watermark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// memoryGuard はメモリ使用量の監視を返す（無効の場合は nil）
+func (wp *WorkerPool) memoryGuard() *memoryGuard {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return wp.memory
+}
+
+// MemoryStatus はメモリ使用量の監視状況を返す（監視が無効の場合は nil）
+func (wp *WorkerPool) MemoryStatus() *MemoryStatus {
+	guard := wp.memoryGuard()
+	if guard == nil {
+		return nil
+	}
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	status := guard.status
+	return &status
+}
+
+// checkMemory はメモリ使用量が上限を超えて受付を止めている場合にエラーを返す
+func (wp *WorkerPool) checkMemory(count bool) error {
+	guard := wp.memoryGuard()
+	if guard == nil {
+		return nil
+	}
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	if !guard.status.Pressure {
+		return nil
+	}
+	if count {
+		guard.status.Rejected++
+	}
+	return fmt.Errorf("%w（使用量 %s / 上限 %s）", ErrMemoryPressure, formatBytes(guard.status.Usage), formatBytes(guard.status.High))
+}
+
+// memoryWatcher は一定間隔でメモリ使用量を確認し、受付の停止・再開とワーカー数の縮小・復元を行う
+func (wp *WorkerPool) memoryWatcher(guard *memoryGuard) {
+	defer wp.bgWg.Done()
+
+	ticker := time.NewTicker(guard.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			wp.checkMemoryWatermark(guard, readMemoryUsage(guard.policy.Source), now)
+		case <-wp.shutdownCh:
+			return
+		}
+	}
+}
+
+// checkMemoryWatermark は使用量を水位と比べ、状態が変わった場合はワーカー数を変更する
+func (wp *WorkerPool) checkMemoryWatermark(guard *memoryGuard, usage uint64, now time.Time) {
+	guard.mu.Lock()
+	guard.status.Usage = usage
+	policy := guard.policy
+
+	switch {
+	case !guard.status.Pressure && usage >= policy.High:
+		guard.status.Pressure = true
+		guard.status.Since = now
+		guard.backoff = policy.Interval
+		guard.nextGCAt = now // 超えた時点ですぐにGCする
+		current := wp.MaxWorkers()
+		shrunk := max(1, int(math.Ceil(float64(current)*policy.Shrink)))
+		if shrunk < current {
+			guard.restore = current
+		}
+		guard.mu.Unlock()
+
+		event("memory.pressure").logf("🧯 メモリ使用量 %s が上限 %s を超えたため受付を停止し、ワーカーを %d 個から %d 個に減らします\n",
+			formatBytes(usage), formatBytes(policy.High), current, shrunk)
+		if shrunk < current {
+			wp.Resize(shrunk)
+		}
+		wp.collectGarbage(guard, now)
+
+	case guard.status.Pressure && usage < policy.Low:
+		guard.status.Pressure = false
+		elapsed := now.Sub(guard.status.Since)
+		guard.status.Since = time.Time{}
+		restore := guard.restore
+		guard.restore = 0
+		guard.mu.Unlock()
+
+		if restore > 0 {
+			wp.Resize(restore)
+		}
+		event("memory.recovered").logf("✅ メモリ使用量が %s まで下がったため受付を再開します（停止していた時間: %v）\n",
+			formatBytes(usage), elapsed.Round(time.Millisecond))
+
+	case guard.status.Pressure:
+		guard.mu.Unlock()
+		wp.collectGarbage(guard, now)
+
+	default:
+		guard.mu.Unlock()
+	}
+}
+
+// collectGarbage は前回からバックオフの間隔が空いていればGCを実行し、次の間隔を倍にする
+// GCはCPUを使うため、下がらない場合に毎回実行し続けないようにする
+func (wp *WorkerPool) collectGarbage(guard *memoryGuard, now time.Time) {
+	guard.mu.Lock()
+	if now.Before(guard.nextGCAt) {
+		guard.mu.Unlock()
+		return
+	}
+	guard.status.GCs++
+	guard.nextGCAt = now.Add(guard.backoff)
+	next := guard.backoff
+	guard.backoff = min(guard.backoff*2, guard.policy.MaxGCBackoff)
+	source := guard.policy.Source
+	guard.mu.Unlock()
+
+	debug.FreeOSMemory()
+	usage := readMemoryUsage(source)
+	event("memory.gc").logf("🧹 GCを実行しました（使用量 %s、次のGCは %v 後以降）\n", formatBytes(usage), next)
+
+	guard.mu.Lock()
+	guard.status.Usage = usage
+	guard.mu.Unlock()
+}
+
+// readMemoryUsage は監視する値の現在の使用量を返す
+func readMemoryUsage(source MemorySource) uint64 {
+	if source == MemorySourceRSS {
+		if rss, ok := readRSS(); ok {
+			return rss
+		}
+	}
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	if source == MemorySourceRSS {
+		return memStats.Sys
+	}
+	return memStats.HeapAlloc
+}
+
+// readRSS は /proc/self/statm からプロセスの常駐メモリを読み取る（Linux のみ）
+func readRSS() (uint64, bool) {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * uint64(os.Getpagesize()), true
+}
+
+// formatBytes はバイト数を MiB 単位の文字列にする
+func formatBytes(n uint64) string {
+	return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+}
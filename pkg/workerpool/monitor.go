@@ -3,11 +3,18 @@ package workerpool
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // PoolStats はワーカープールの統計情報
 type PoolStats struct {
+	// プールの状態（created / running / paused / draining / stopped）
+	State PoolState `json:"state"`
+
+	// インスタンス情報（SetInstanceInfo で設定した場合）
+	Instance *InstanceInfo `json:"instance,omitempty"`
+
 	// 基本統計
 	TotalTasks     int64 `json:"total_tasks"`
 	CompletedTasks int64 `json:"completed_tasks"`
@@ -15,20 +22,81 @@ type PoolStats struct {
 	ActiveTasks    int64 `json:"active_tasks"`
 	QueuedTasks    int64 `json:"queued_tasks"`
 	RetryingTasks  int64 `json:"retrying_tasks"`
+	DeferredTasks  int64 `json:"deferred_tasks"`
+	HeldTasks      int64 `json:"held_tasks"`
+	DelayedTasks   int64 `json:"delayed_tasks"`
+	DeadLetters    int64 `json:"dead_letters"`
+	OverrunTasks   int64 `json:"overrun_tasks"` // キャンセル後も猶予を超えて実行を続けたタスクの累計
 
 	// ワーカー統計
 	TotalWorkers  int `json:"total_workers"`
 	ActiveWorkers int `json:"active_workers"`
 	IdleWorkers   int `json:"idle_workers"`
+	// 稼働率（前回の更新からの期間で、ワーカーがタスクを実行していた時間の割合）
+	Utilization float64 `json:"utilization_percent"`
+
+	// ワーカーごとの統計（処理件数・実行中のタスク・稼働時間・エラー数）
+	WorkerStats []WorkerStats `json:"worker_stats"`
 
 	// 処理時間統計
 	AverageTime float64 `json:"average_time_ms"`
 	MinTime     float64 `json:"min_time_ms"`
 	MaxTime     float64 `json:"max_time_ms"`
+	// 処理時間のパーセンタイル（起動からのすべての結果の分布から相対誤差1%以内で推定）
+	P50Time float64 `json:"p50_time_ms"`
+	P95Time float64 `json:"p95_time_ms"`
+	P99Time float64 `json:"p99_time_ms"`
+
+	// タスクの滞在時間統計（作成から最終結果まで）
+	AverageAge float64 `json:"average_age_ms"`
+	MaxAge     float64 `json:"max_age_ms"`
 
 	// タスクタイプ別統計
 	TaskTypeStats map[TaskType]TaskTypeStats `json:"task_type_stats"`
 
+	// ラベル別統計（ラベルキー → ラベル値 → 統計）
+	LabelStats map[string]map[string]TaskTypeStats `json:"label_stats"`
+
+	// group_by で選択された集計軸の統計
+	GroupBy    string                   `json:"group_by,omitempty"`
+	GroupStats map[string]TaskTypeStats `json:"group_stats,omitempty"`
+
+	// カナリア比較（タスクタイプ → stable/canary → 統計）
+	CanaryStats map[TaskType]map[string]TaskTypeStats `json:"canary_stats,omitempty"`
+
+	// シャドー実行の集計と、シャドー・カナリアの乖離レポート
+	ShadowStats map[TaskType]ShadowStats `json:"shadow_stats,omitempty"`
+	Divergence  *DivergenceReport        `json:"divergence,omitempty"`
+
+	// 完了予測（キューが空になるまでの推定時間、算出できない場合は -1）
+	Throughput     float64              `json:"throughput_per_sec"`
+	DrainETA       float64              `json:"drain_eta_ms"`
+	DrainETAByType map[TaskType]float64 `json:"drain_eta_by_type_ms"`
+
+	// アドミッション制御
+	Admission AdmissionStats `json:"admission"`
+
+	// SLOの達成状況とバーンレートのアラート
+	SLOs []SLOStatus `json:"slos,omitempty"`
+
+	// タスクタイプの hot / cold の分類
+	TaskHeat map[TaskType]TaskHeat `json:"task_heat,omitempty"`
+
+	// 名前付きキューごとの状況
+	Queues map[string]QueueStatus `json:"queues,omitempty"`
+
+	// ピアへの転送の集計
+	Forwarding *ForwardStats `json:"forwarding,omitempty"`
+
+	// メモリ使用量の監視状況
+	Memory *MemoryStatus `json:"memory,omitempty"`
+
+	// 下流のヘルスチェックの状況
+	Downstream []DownstreamStatus `json:"downstream,omitempty"`
+
+	// ブラウンアウトの状態（無効の場合は nil）
+	Brownout *BrownoutStatus `json:"brownout,omitempty"`
+
 	// システム情報
 	Uptime      time.Duration `json:"uptime_ms"`
 	LastUpdated time.Time     `json:"last_updated"`
@@ -41,48 +109,135 @@ type TaskTypeStats struct {
 	Failed    int64   `json:"failed"`
 	Retried   int64   `json:"retried"`
 	AvgTime   float64 `json:"avg_time_ms"`
+	AvgAge    float64 `json:"avg_age_ms"` // 作成から最終結果までの平均時間
+	// 処理時間のパーセンタイル（タスクタイプ別の統計のみ、ラベル別・カナリアでは省略）
+	P50Time float64 `json:"p50_time_ms,omitempty"`
+	P95Time float64 `json:"p95_time_ms,omitempty"`
+	P99Time float64 `json:"p99_time_ms,omitempty"`
+}
+
+// record はタスク結果を1件分集計に加える
+func (ts *TaskTypeStats) record(result TaskResult, timeMs float64) {
+	ts.Total++
+	if result.Success {
+		ts.Succeeded++
+	} else {
+		ts.Failed++
+	}
+	if result.WasRetried() {
+		ts.Retried++
+	}
+
+	// 平均時間を更新
+	ageMs := durationToMs(result.Age)
+	if ts.Total == 1 {
+		ts.AvgTime = timeMs
+		ts.AvgAge = ageMs
+	} else {
+		ts.AvgTime = (ts.AvgTime*float64(ts.Total-1) + timeMs) / float64(ts.Total)
+		ts.AvgAge = (ts.AvgAge*float64(ts.Total-1) + ageMs) / float64(ts.Total)
+	}
+}
+
+// throughputWindow はスループット算出に使う直近の期間
+const throughputWindow = 60 * time.Second
+
+// completion は直近の完了記録（スループット算出用）
+type completion struct {
+	at       time.Time
+	taskType TaskType
 }
 
 // Monitor はリアルタイム監視機能
 type Monitor struct {
-	pool      *WorkerPool
+	pool      Pool
 	stats     PoolStats
 	mutex     sync.RWMutex
 	startTime time.Time
+	recent    []completion // 直近の完了記録
+
+	adminToken string            // 管理系エンドポイントの認証トークン（空で認証なし）
+	readOnly   bool              // 管理系エンドポイントの変更系のリクエストを拒否する（デモ・サンドボックス用）
+	templates  *TemplateRegistry // POST /tasks で template を指定した場合に使うテンプレート
+
+	configSources []ConfigSource // GET /config で返す取り込んだ設定の出所（秘密情報は伏せた値）
+
+	// GET /stats/delta のトークンごとのカウンタ（発行順に deltaOrder で管理）
+	deltaTokens map[string]statsBaseline
+	deltaOrder  []string
+	deltaSeq    uint64
+
+	slos []*sloTracker // 監視するSLO
+
+	// エラーバジェットに応じたリトライの調整（nil で無効）と、絞っているタイプの元のポリシー
+	retryThrottle *RetryThrottle
+	throttled     map[TaskType]RetryPolicy
+
+	brownout *brownoutState // 過負荷が続いた場合のブラウンアウト（nil で無効）
+
+	history *taskHistory // 直近のタスク結果（nil で保持しない）
+
+	// 処理時間のパーセンタイルの推定に使う分布（全体とタスクタイプ別）
+	latency       *latencyHistogram
+	latencyByType map[TaskType]*latencyHistogram
+
+	// 稼働率の算出に使う前回の更新時点の値
+	lastSampleAt time.Time
+	lastBusyTime time.Duration
 
 	// リアルタイム更新用
-	updateCh chan TaskResult
-	stopCh   chan struct{}
-	wg       sync.WaitGroup
+	unsubscribe func()      // プールの結果の購読を解除する
+	subscribed  atomic.Bool // プールの結果を購読中（OnTaskResult での手動の通知は無視する）
+	updateCh    chan TaskResult
+	stopCh      chan struct{}
+	wg          sync.WaitGroup
 }
 
 // NewMonitor は新しいモニターを作成
-func NewMonitor(pool *WorkerPool) *Monitor {
+func NewMonitor(pool Pool) *Monitor {
 	return &Monitor{
 		pool:      pool,
 		startTime: time.Now(),
 		updateCh:  make(chan TaskResult, 100),
 		stopCh:    make(chan struct{}),
+		history:   newTaskHistory(defaultHistorySize),
+		latency:   newLatencyHistogram(),
 		stats: PoolStats{
 			TaskTypeStats: make(map[TaskType]TaskTypeStats),
+			LabelStats:    make(map[string]map[string]TaskTypeStats),
+			DrainETA:      -1,
 		},
 	}
 }
 
-// Start はモニタリングを開始
+// Start はモニタリングを開始し、プールの結果を購読する（結果を OnTaskResult で渡す必要はない）
 func (m *Monitor) Start() {
+	m.unsubscribe = m.pool.OnResult(m.observe)
+	m.subscribed.Store(true)
 	m.wg.Add(1)
 	go m.updateLoop()
 }
 
 // Stop はモニタリングを停止
 func (m *Monitor) Stop() {
+	if m.subscribed.Swap(false) {
+		m.unsubscribe()
+	}
 	close(m.stopCh)
 	m.wg.Wait()
 }
 
 // OnTaskResult はタスク結果を受信
+// Start 後はプールの結果を購読しているため何もしない（二重に集計しない）
 func (m *Monitor) OnTaskResult(result TaskResult) {
+	if m.subscribed.Load() {
+		return
+	}
+	m.observe(result)
+}
+
+// observe はタスク結果を集計に回す
+func (m *Monitor) observe(result TaskResult) {
 	select {
 	case m.updateCh <- result:
 	default:
@@ -104,6 +259,8 @@ func (m *Monitor) updateLoop() {
 
 		case <-ticker.C:
 			m.updateSystemStats()
+			m.adjustRetryThrottle()
+			m.adjustBrownout()
 
 		case <-m.stopCh:
 			return
@@ -116,6 +273,10 @@ func (m *Monitor) updateStats(result TaskResult) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	if m.history != nil {
+		m.history.add(newHistoryEntry(result))
+	}
+
 	// 基本統計を更新
 	m.stats.TotalTasks++
 	if result.Success {
@@ -141,26 +302,60 @@ func (m *Monitor) updateStats(result TaskResult) {
 		m.stats.AverageTime = (m.stats.AverageTime*float64(m.stats.TotalTasks-1) + timeMs) / float64(m.stats.TotalTasks)
 	}
 
+	m.latency.add(timeMs)
+	if m.latencyByType == nil {
+		m.latencyByType = make(map[TaskType]*latencyHistogram)
+	}
+	typeLatency, exists := m.latencyByType[result.TaskType]
+	if !exists {
+		typeLatency = newLatencyHistogram()
+		m.latencyByType[result.TaskType] = typeLatency
+	}
+	typeLatency.add(timeMs)
+
+	// 滞在時間統計を更新
+	ageMs := durationToMs(result.Age)
+	m.stats.AverageAge = (m.stats.AverageAge*float64(m.stats.TotalTasks-1) + ageMs) / float64(m.stats.TotalTasks)
+	if ageMs > m.stats.MaxAge {
+		m.stats.MaxAge = ageMs
+	}
+
 	// タスクタイプ別統計を更新
 	typeStats := m.stats.TaskTypeStats[result.TaskType]
-	typeStats.Total++
-	if result.Success {
-		typeStats.Succeeded++
-	} else {
-		typeStats.Failed++
+	typeStats.record(result, timeMs)
+	m.stats.TaskTypeStats[result.TaskType] = typeStats
+	m.recent = append(m.recent, completion{at: time.Now(), taskType: result.TaskType})
+	for _, slo := range m.slos {
+		slo.record(result, time.Now())
 	}
-	if result.WasRetried() {
-		typeStats.Retried++
+
+	// カナリア設定中のタイプは現行版とカナリアを分けて集計
+	if result.Variant != "" {
+		if m.stats.CanaryStats == nil {
+			m.stats.CanaryStats = make(map[TaskType]map[string]TaskTypeStats)
+		}
+		variants, exists := m.stats.CanaryStats[result.TaskType]
+		if !exists {
+			variants = make(map[string]TaskTypeStats)
+			m.stats.CanaryStats[result.TaskType] = variants
+		}
+		variantStats := variants[result.Variant]
+		variantStats.record(result, timeMs)
+		variants[result.Variant] = variantStats
 	}
 
-	// タスクタイプ別平均時間を更新
-	if typeStats.Total == 1 {
-		typeStats.AvgTime = timeMs
-	} else {
-		typeStats.AvgTime = (typeStats.AvgTime*float64(typeStats.Total-1) + timeMs) / float64(typeStats.Total)
+	// ラベル別統計を更新
+	for key, value := range result.Labels {
+		values, exists := m.stats.LabelStats[key]
+		if !exists {
+			values = make(map[string]TaskTypeStats)
+			m.stats.LabelStats[key] = values
+		}
+		labelStats := values[value]
+		labelStats.record(result, timeMs)
+		values[value] = labelStats
 	}
 
-	m.stats.TaskTypeStats[result.TaskType] = typeStats
 	m.stats.LastUpdated = time.Now()
 }
 
@@ -170,15 +365,136 @@ func (m *Monitor) updateSystemStats() {
 	defer m.mutex.Unlock()
 
 	m.stats.Uptime = time.Since(m.startTime)
-	m.stats.TotalWorkers = m.pool.workers
+	snapshot := m.pool.Snapshot()
+	m.stats.TotalWorkers = snapshot.RunningWorkers
 
 	// キューの長さを取得（近似値）
-	m.stats.QueuedTasks = int64(len(m.pool.tasks))
-	m.stats.RetryingTasks = int64(len(m.pool.retryQueue))
+	m.stats.QueuedTasks = int64(snapshot.QueuedTasks)
+	m.stats.RetryingTasks = int64(snapshot.RetryingTasks)
+	m.stats.State = snapshot.State
+	m.stats.DeferredTasks = int64(snapshot.DeferredTasks)
+	m.stats.HeldTasks = int64(snapshot.HeldTasks)
+	m.stats.DelayedTasks = int64(snapshot.DelayedTasks)
+	m.stats.DeadLetters = int64(snapshot.DeadLetters)
+	m.stats.OverrunTasks = snapshot.OverrunTasks
+	m.stats.Admission = snapshot.Admission
+	if len(snapshot.Shadow) > 0 {
+		m.stats.ShadowStats = snapshot.Shadow
+	}
+	m.stats.TaskHeat = snapshot.Heat
+	m.stats.Queues = snapshot.Queues
+	m.stats.Forwarding = snapshot.Forwarding
+	m.stats.Memory = snapshot.Memory
+	m.stats.Downstream = snapshot.Downstream
+	m.stats.WorkerStats = snapshot.Workers
+	m.stats.Divergence = buildDivergenceReport(m.stats.ShadowStats, m.stats.CanaryStats)
+
+	// ワーカーが報告する実行中・待機中の数と、前回の更新からの稼働率
+	now := time.Now()
+	m.stats.ActiveWorkers = min(snapshot.ActiveWorkers, m.stats.TotalWorkers)
+	m.stats.IdleWorkers = m.stats.TotalWorkers - m.stats.ActiveWorkers
+	m.stats.Utilization = m.utilization(snapshot, now)
+	m.lastSampleAt, m.lastBusyTime = now, snapshot.BusyTime
+
+	// SLOを評価（アラートの発報・解消もここで記録する）
+	if len(m.slos) > 0 {
+		now := time.Now()
+		m.stats.SLOs = make([]SLOStatus, 0, len(m.slos))
+		for _, slo := range m.slos {
+			m.stats.SLOs = append(m.stats.SLOs, slo.evaluate(now))
+		}
+	}
 
-	// アクティブワーカー数は実装により異なる（ここでは推定）
-	m.stats.ActiveWorkers = m.stats.TotalWorkers
-	m.stats.IdleWorkers = 0
+	// 完了予測を更新
+	eta, etaByType, throughput := m.estimateDrain(snapshot.QueuedByType, time.Now())
+	m.stats.Throughput = throughput
+	m.stats.DrainETA = durationToMs(eta)
+	m.stats.DrainETAByType = make(map[TaskType]float64, len(etaByType))
+	for taskType, typeETA := range etaByType {
+		m.stats.DrainETAByType[taskType] = durationToMs(typeETA)
+	}
+}
+
+// utilization は前回の更新からの期間の稼働率（%）を返す（呼び出し側でロックを保持）
+// 初回や実行時間の累計が戻った場合（プールの差し替えなど）は、その時点の実行中のワーカーの割合を返す
+func (m *Monitor) utilization(snapshot PoolSnapshot, now time.Time) float64 {
+	if m.stats.TotalWorkers == 0 {
+		return 0
+	}
+	elapsed := now.Sub(m.lastSampleAt)
+	busy := snapshot.BusyTime - m.lastBusyTime
+	if m.lastSampleAt.IsZero() || elapsed <= 0 || busy < 0 {
+		return float64(m.stats.ActiveWorkers) / float64(m.stats.TotalWorkers) * 100
+	}
+	return min(float64(busy)/(float64(elapsed)*float64(m.stats.TotalWorkers))*100, 100)
+}
+
+// DrainETA は現在のキューが空になるまでの推定時間を返す
+// 直近のスループットがなく推定できない場合は負の値を返す
+func (m *Monitor) DrainETA() time.Duration {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	eta, _, _ := m.estimateDrain(m.pool.Snapshot().QueuedByType, time.Now())
+	return eta
+}
+
+// estimateDrain は直近のタイプ別スループットからキュー消化時間を推定
+// タイプ同士は並行して処理されるため、全体の推定値はタイプ別推定の最大値とする
+// 呼び出し側でロックを保持していること
+func (m *Monitor) estimateDrain(queued map[TaskType]int, now time.Time) (time.Duration, map[TaskType]time.Duration, float64) {
+	// 期間外の完了記録を破棄
+	cutoff := now.Add(-throughputWindow)
+	i := 0
+	for i < len(m.recent) && m.recent[i].at.Before(cutoff) {
+		i++
+	}
+	m.recent = m.recent[i:]
+
+	window := now.Sub(m.startTime)
+	if window > throughputWindow {
+		window = throughputWindow
+	}
+	if window <= 0 {
+		return -1, nil, 0
+	}
+
+	countByType := make(map[TaskType]int)
+	for _, c := range m.recent {
+		countByType[c.taskType]++
+	}
+	throughput := float64(len(m.recent)) / window.Seconds()
+
+	var eta time.Duration
+	etaByType := make(map[TaskType]time.Duration, len(queued))
+	for taskType, count := range queued {
+		// 該当タイプの実績がない場合は全体のスループットで代用
+		rate := float64(countByType[taskType]) / window.Seconds()
+		if rate == 0 {
+			rate = throughput
+		}
+		if rate == 0 {
+			etaByType[taskType] = -1
+			eta = -1
+			continue
+		}
+
+		typeETA := time.Duration(float64(count) / rate * float64(time.Second))
+		etaByType[taskType] = typeETA
+		if eta >= 0 && typeETA > eta {
+			eta = typeETA
+		}
+	}
+
+	return eta, etaByType, throughput
+}
+
+// durationToMs は期間をミリ秒に変換（負の値は -1 として扱う）
+func durationToMs(d time.Duration) float64 {
+	if d < 0 {
+		return -1
+	}
+	return float64(d.Nanoseconds()) / 1e6
 }
 
 // GetStats は現在の統計情報を取得
@@ -188,10 +504,80 @@ func (m *Monitor) GetStats() PoolStats {
 
 	// ディープコピーを返す
 	stats := m.stats
+	stats.Instance = CurrentInstance()
 	stats.TaskTypeStats = make(map[TaskType]TaskTypeStats)
 	for k, v := range m.stats.TaskTypeStats {
+		if latency, exists := m.latencyByType[k]; exists {
+			percentiles := latency.percentiles()
+			v.P50Time, v.P95Time, v.P99Time = percentiles.P50, percentiles.P95, percentiles.P99
+		}
 		stats.TaskTypeStats[k] = v
 	}
+	percentiles := m.latency.percentiles()
+	stats.P50Time, stats.P95Time, stats.P99Time = percentiles.P50, percentiles.P95, percentiles.P99
+	stats.DrainETAByType = make(map[TaskType]float64)
+	for k, v := range m.stats.DrainETAByType {
+		stats.DrainETAByType[k] = v
+	}
+	stats.LabelStats = make(map[string]map[string]TaskTypeStats)
+	for key, values := range m.stats.LabelStats {
+		copied := make(map[string]TaskTypeStats, len(values))
+		for k, v := range values {
+			copied[k] = v
+		}
+		stats.LabelStats[key] = copied
+	}
+	if m.stats.ShadowStats != nil {
+		stats.ShadowStats = make(map[TaskType]ShadowStats, len(m.stats.ShadowStats))
+		for k, v := range m.stats.ShadowStats {
+			stats.ShadowStats[k] = v
+		}
+	}
+	if m.stats.TaskHeat != nil {
+		stats.TaskHeat = make(map[TaskType]TaskHeat, len(m.stats.TaskHeat))
+		for k, v := range m.stats.TaskHeat {
+			stats.TaskHeat[k] = v
+		}
+	}
+	if m.stats.Queues != nil {
+		stats.Queues = make(map[string]QueueStatus, len(m.stats.Queues))
+		for k, v := range m.stats.Queues {
+			stats.Queues[k] = v
+		}
+	}
+	if m.stats.CanaryStats != nil {
+		stats.CanaryStats = make(map[TaskType]map[string]TaskTypeStats, len(m.stats.CanaryStats))
+		for taskType, variants := range m.stats.CanaryStats {
+			copied := make(map[string]TaskTypeStats, len(variants))
+			for k, v := range variants {
+				copied[k] = v
+			}
+			stats.CanaryStats[taskType] = copied
+		}
+	}
+
+	return stats
+}
+
+// GetStatsGroupedBy は指定した軸で集計した統計情報を取得
+// groupBy が空または "type" の場合はタスクタイプ別、それ以外はラベルキーとして扱う
+func (m *Monitor) GetStatsGroupedBy(groupBy string) PoolStats {
+	stats := m.GetStats()
+	if groupBy == "" {
+		groupBy = "type"
+	}
+
+	stats.GroupBy = groupBy
+	stats.GroupStats = make(map[string]TaskTypeStats)
+	if groupBy == "type" {
+		for taskType, typeStats := range stats.TaskTypeStats {
+			stats.GroupStats[string(taskType)] = typeStats
+		}
+	} else {
+		for value, labelStats := range stats.LabelStats[groupBy] {
+			stats.GroupStats[value] = labelStats
+		}
+	}
 
 	return stats
 }
@@ -201,24 +587,80 @@ func (m *Monitor) PrintStats() {
 	stats := m.GetStats()
 
 	fmt.Println("\n📊 === リアルタイム統計情報 ===")
-	fmt.Printf("稼働時間: %v\n", stats.Uptime.Round(time.Second))
+	fmt.Printf("稼働時間: %v | 状態: %s\n", stats.Uptime.Round(time.Second), stats.State)
 	fmt.Printf("総タスク数: %d | 完了: %d | 失敗: %d\n",
 		stats.TotalTasks, stats.CompletedTasks, stats.FailedTasks)
-	fmt.Printf("キュー: %d | リトライ中: %d\n",
-		stats.QueuedTasks, stats.RetryingTasks)
-	fmt.Printf("ワーカー: %d/%d アクティブ\n",
-		stats.ActiveWorkers, stats.TotalWorkers)
-	fmt.Printf("処理時間: 平均 %.1fms | 最小 %.1fms | 最大 %.1fms\n",
-		stats.AverageTime, stats.MinTime, stats.MaxTime)
+	fmt.Printf("キュー: %d | リトライ中: %d | 保留中: %d | DLQ: %d\n",
+		stats.QueuedTasks, stats.RetryingTasks, stats.DeferredTasks, stats.DeadLetters)
+	if stats.HeldTasks > 0 {
+		fmt.Printf("🚧 メンテナンスで保留中: %d\n", stats.HeldTasks)
+	}
+	if stats.Brownout != nil && stats.Brownout.Active {
+		fmt.Printf("🟤 ブラウンアウト中（%s から、%s）: 停止中のタイプ %v\n",
+			stats.Brownout.Since.Format("15:04:05"), stats.Brownout.Reason, stats.Brownout.DisabledTypes)
+	}
+	for _, downstream := range stats.Downstream {
+		if !downstream.Healthy {
+			fmt.Printf("🩺 %s の下流が障害中（%s から）: %s\n", downstream.TaskType, downstream.Since.Format("15:04:05"), downstream.LastError)
+		}
+	}
+	if stats.DelayedTasks > 0 {
+		fmt.Printf("🕰️ 予定時刻待ち: %d\n", stats.DelayedTasks)
+	}
+	if stats.OverrunTasks > 0 {
+		fmt.Printf("🐢 キャンセル後も実行を続けたタスク: %d\n", stats.OverrunTasks)
+	}
+	fmt.Printf("ワーカー: %d/%d アクティブ (待機 %d, 稼働率 %.1f%%)\n",
+		stats.ActiveWorkers, stats.TotalWorkers, stats.IdleWorkers, stats.Utilization)
+	fmt.Printf("処理時間: 平均 %.1fms | 最小 %.1fms | 最大 %.1fms | p50 %.1fms | p95 %.1fms | p99 %.1fms\n",
+		stats.AverageTime, stats.MinTime, stats.MaxTime, stats.P50Time, stats.P95Time, stats.P99Time)
+	fmt.Printf("滞在時間: 平均 %.1fms | 最大 %.1fms\n", stats.AverageAge, stats.MaxAge)
+	if stats.Admission != (AdmissionStats{}) {
+		fmt.Printf("受付制御: 拒否 %d | 破棄 %d | 優先度低下 %d | 保留 %d\n",
+			stats.Admission.Rejected, stats.Admission.Shed, stats.Admission.Degraded, stats.Admission.Deferred)
+	}
+	if stats.DrainETA >= 0 {
+		fmt.Printf("スループット: %.2f件/秒 | キュー消化予測: %v\n",
+			stats.Throughput, (time.Duration(stats.DrainETA) * time.Millisecond).Round(time.Second))
+	} else {
+		fmt.Printf("スループット: %.2f件/秒 | キュー消化予測: 算出不可\n", stats.Throughput)
+	}
 
 	if len(stats.TaskTypeStats) > 0 {
 		fmt.Println("\n📋 タスクタイプ別統計:")
 		for taskType, typeStats := range stats.TaskTypeStats {
 			successRate := float64(typeStats.Succeeded) / float64(typeStats.Total) * 100
-			fmt.Printf("  [%s] 総数:%d 成功:%d 失敗:%d リトライ:%d 成功率:%.1f%% 平均:%.1fms\n",
+			fmt.Printf("  [%s] 総数:%d 成功:%d 失敗:%d リトライ:%d 成功率:%.1f%% 平均:%.1fms p95:%.1fms p99:%.1fms\n",
 				taskType, typeStats.Total, typeStats.Succeeded, typeStats.Failed,
-				typeStats.Retried, successRate, typeStats.AvgTime)
+				typeStats.Retried, successRate, typeStats.AvgTime, typeStats.P95Time, typeStats.P99Time)
+		}
+	}
+
+	if len(stats.LabelStats) > 0 {
+		fmt.Println("\n🏷️ ラベル別統計:")
+		for key, values := range stats.LabelStats {
+			for value, labelStats := range values {
+				fmt.Printf("  [%s=%s] 総数:%d 成功:%d 失敗:%d 平均:%.1fms\n",
+					key, value, labelStats.Total, labelStats.Succeeded, labelStats.Failed, labelStats.AvgTime)
+			}
+		}
+	}
+
+	if len(stats.CanaryStats) > 0 {
+		fmt.Println("\n🐤 カナリア比較:")
+		for taskType, variants := range stats.CanaryStats {
+			for _, variant := range []string{VariantStable, VariantCanary} {
+				variantStats, exists := variants[variant]
+				if !exists {
+					continue
+				}
+				failureRate := float64(variantStats.Failed) / float64(variantStats.Total) * 100
+				fmt.Printf("  [%s/%s] 総数:%d 失敗率:%.1f%% 平均:%.1fms\n",
+					taskType, variant, variantStats.Total, failureRate, variantStats.AvgTime)
+			}
 		}
 	}
+	printDivergence(stats.Divergence)
+	printSLOs(stats.SLOs)
 	fmt.Println("==================================================")
 }
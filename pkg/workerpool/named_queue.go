@@ -0,0 +1,418 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultNamedQueueSize は名前付きキューに並べられるタスク数の既定値（共有キューと同じ）
+const defaultNamedQueueSize = 10
+
+// ErrInvalidQueueConfig は名前付きキュー・ルーティング規則の設定が不正な場合のエラー
+var ErrInvalidQueueConfig = errors.New("名前付きキューの設定が不正です")
+
+// QueueConfig は名前付きキューの設定
+// 名前付きキューは共有キューとは別のキューと専用ワーカーを持ち、ルーティング規則に一致したタスクだけを処理する
+type QueueConfig struct {
+	Name    string  `json:"name"`
+	Size    int     `json:"size"`         // キューに並べられるタスク数（0 の場合は10）
+	Workers int     `json:"workers"`      // 専用ワーカー数（0 の場合は1）
+	Rate    float64 `json:"rate_per_sec"` // 1秒あたりに取り出すタスク数の上限（0 で無制限）
+	Burst   int     `json:"burst"`        // 瞬間的に取り出せるタスク数（0 の場合は1）
+}
+
+// withDefaults は既定値を補った設定を返す
+func (c QueueConfig) withDefaults() QueueConfig {
+	if c.Size <= 0 {
+		c.Size = defaultNamedQueueSize
+	}
+	if c.Workers <= 0 {
+		c.Workers = 1
+	}
+	if c.Burst <= 0 {
+		c.Burst = 1
+	}
+	return c
+}
+
+// QueueRoute はタスクを名前付きキューに振り分ける規則（指定した条件をすべて満たすタスクが対象）
+// 規則は定義した順に評価し、最初に一致した規則のキューに入れる。どの規則にも一致しないタスクは共有キューで処理する
+type QueueRoute struct {
+	Queue       string            `json:"queue"`                  // 振り分け先のキューの名前
+	Types       []TaskType        `json:"types,omitempty"`        // いずれかのタイプに一致
+	Labels      map[string]string `json:"labels,omitempty"`       // すべてのラベルが一致（値 * はキーの存在のみ）
+	MinPriority *Priority         `json:"min_priority,omitempty"` // この優先度以上
+	MaxPriority *Priority         `json:"max_priority,omitempty"` // この優先度以下
+}
+
+// Matches はタスクが規則の条件を満たすか判定
+func (r QueueRoute) Matches(task Task) bool {
+	if !(TaskFilter{Types: r.Types, Labels: r.Labels}).Matches(task) {
+		return false
+	}
+	if r.MinPriority != nil && task.Priority < *r.MinPriority {
+		return false
+	}
+	if r.MaxPriority != nil && task.Priority > *r.MaxPriority {
+		return false
+	}
+	return true
+}
+
+// QueueStatus は名前付きキューの設定と状況
+type QueueStatus struct {
+	Size      int     `json:"size"`
+	Workers   int     `json:"workers"`                // 起動中の専用ワーカー数
+	Rate      float64 `json:"rate_per_sec,omitempty"` // 取り出しのレート上限（無制限の場合は省略）
+	Queued    int     `json:"queued"`                 // キューに並んでいるタスク数
+	Busy      int     `json:"busy"`                   // 専用ワーカーが実行中のタスク数
+	Routed    int64   `json:"routed"`                 // 振り分けたタスクの累計
+	Throttled int64   `json:"throttled"`              // レート上限のため取り出しを待った回数
+}
+
+// namedQueue は名前付きキューと、その専用ワーカーの状況
+type namedQueue struct {
+	config QueueConfig
+	queue  *taskQueue
+
+	// 以下は queueRouter.mu で保護する
+	tokens    float64 // 取り出しに使えるトークン（先に借りた分は負になる）
+	last      time.Time
+	running   int
+	busy      int
+	routed    int64
+	throttled int64
+}
+
+// queueRouter は名前付きキューとルーティング規則を管理する
+type queueRouter struct {
+	mu     sync.Mutex
+	queues map[string]*namedQueue
+	routes []QueueRoute
+}
+
+// newQueueRouter は設定を検証してキューを作成する（名前の重複・未定義のキューへの規則は ErrInvalidQueueConfig）
+func newQueueRouter(queues []QueueConfig, routes []QueueRoute) (*queueRouter, error) {
+	router := &queueRouter{
+		queues: make(map[string]*namedQueue, len(queues)),
+		routes: append([]QueueRoute(nil), routes...),
+	}
+	for _, config := range queues {
+		if config.Name == "" || config.Name == defaultQueueName {
+			return nil, fmt.Errorf("%w: キューの名前 %q は使えません", ErrInvalidQueueConfig, config.Name)
+		}
+		if _, exists := router.queues[config.Name]; exists {
+			return nil, fmt.Errorf("%w: キュー %s が重複しています", ErrInvalidQueueConfig, config.Name)
+		}
+		if config.Rate < 0 {
+			return nil, fmt.Errorf("%w: キュー %s のレートが負の値です", ErrInvalidQueueConfig, config.Name)
+		}
+		config = config.withDefaults()
+		router.queues[config.Name] = &namedQueue{
+			config: config,
+			queue:  newTaskQueue(config.Size),
+			tokens: float64(config.Burst),
+			last:   time.Now(),
+		}
+	}
+	for i, route := range routes {
+		if _, exists := router.queues[route.Queue]; !exists {
+			return nil, fmt.Errorf("%w: 規則 %d のキュー %q が定義されていません", ErrInvalidQueueConfig, i+1, route.Queue)
+		}
+	}
+	return router, nil
+}
+
+// route はタスクの振り分け先のキューを返す（どの規則にも一致しない場合は nil）
+func (r *queueRouter) route(task Task) *namedQueue {
+	if r == nil {
+		return nil
+	}
+	for _, route := range r.routes {
+		if route.Matches(task) {
+			return r.queues[route.Queue]
+		}
+	}
+	return nil
+}
+
+// push はタスクを振り分け先のキューに投入する。満杯の場合は空きが出るか stop が閉じられるまで待つ
+func (r *queueRouter) push(q *namedQueue, task Task, stop <-chan struct{}) error {
+	if err := q.queue.push(task, stop); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	q.routed++
+	r.mu.Unlock()
+	return nil
+}
+
+// reserve は取り出し用のトークンを1つ借り、使えるようになるまでの待ち時間を返す（レート上限がない場合は0）
+func (r *queueRouter) reserve(q *namedQueue) time.Duration {
+	if q.config.Rate <= 0 {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	q.tokens = min(q.tokens+now.Sub(q.last).Seconds()*q.config.Rate, float64(q.config.Burst))
+	q.last = now
+	q.tokens--
+	if q.tokens >= 0 {
+		return 0
+	}
+	q.throttled++
+	return time.Duration(-q.tokens / q.config.Rate * float64(time.Second))
+}
+
+// list は名前順のキューを返す
+func (r *queueRouter) list() []*namedQueue {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.queues))
+	for name := range r.queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	queues := make([]*namedQueue, len(names))
+	for i, name := range names {
+		queues[i] = r.queues[name]
+	}
+	return queues
+}
+
+// taskQueues は名前付きキューのキューを返す
+func (r *queueRouter) taskQueues() []*taskQueue {
+	var queues []*taskQueue
+	for _, q := range r.list() {
+		queues = append(queues, q.queue)
+	}
+	return queues
+}
+
+// pending は名前付きキューに並んでいるタスク数と、専用ワーカーが実行中のタスク数を返す
+func (r *queueRouter) pending() (queued, busy int) {
+	if r == nil {
+		return 0, 0
+	}
+	for _, q := range r.list() {
+		queued += q.queue.len()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, q := range r.queues {
+		busy += q.busy
+	}
+	return queued, busy
+}
+
+// addBusy は専用ワーカーが実行中のタスク数を増減する
+func (r *queueRouter) addBusy(q *namedQueue, delta int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	q.busy += delta
+}
+
+// addRunning は起動中の専用ワーカー数を増減する
+func (r *queueRouter) addRunning(q *namedQueue, delta int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	q.running += delta
+}
+
+// idleWorkers はキューの専用ワーカーのうちタスクを実行していない数を返す
+func (r *queueRouter) idleWorkers(q *namedQueue) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return max(q.running-q.busy, 0)
+}
+
+// runningWorkers は起動中の専用ワーカーの合計を返す
+func (r *queueRouter) runningWorkers() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	running := 0
+	for _, q := range r.queues {
+		running += q.running
+	}
+	return running
+}
+
+// closeAll は停止処理のためすべての名前付きキューを閉じる（並んでいるタスクは専用ワーカーが処理してから終了する）
+func (r *queueRouter) closeAll() {
+	for _, queue := range r.taskQueues() {
+		queue.close()
+	}
+}
+
+// reopen は再開したプールで再び名前付きキューに投入できるようにする
+func (r *queueRouter) reopen() {
+	for _, queue := range r.taskQueues() {
+		queue.reopen()
+	}
+}
+
+// snapshot はキューごとの状況を返す
+func (r *queueRouter) snapshot() map[string]QueueStatus {
+	if r == nil {
+		return nil
+	}
+	queues := r.list()
+	statuses := make(map[string]QueueStatus, len(queues))
+	for _, q := range queues {
+		queued := q.queue.len()
+		r.mu.Lock()
+		statuses[q.config.Name] = QueueStatus{
+			Size:      q.config.Size,
+			Workers:   q.running,
+			Rate:      q.config.Rate,
+			Queued:    queued,
+			Busy:      q.busy,
+			Routed:    q.routed,
+			Throttled: q.throttled,
+		}
+		r.mu.Unlock()
+	}
+	return statuses
+}
+
+// SetQueues は名前付きキューとルーティング規則を設定する（Start 前に呼び出すこと。queues が空の場合は無効化）
+// 開始後は ErrPoolStarted、名前の重複や未定義のキューを指す規則がある場合は ErrInvalidQueueConfig を返す
+func (wp *WorkerPool) SetQueues(queues []QueueConfig, routes []QueueRoute) error {
+	var router *queueRouter
+	if len(queues) > 0 || len(routes) > 0 {
+		var err error
+		if router, err = newQueueRouter(queues, routes); err != nil {
+			return err
+		}
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.lifecycle.current() != StateCreated {
+		return fmt.Errorf("名前付きキュー: %w", ErrPoolStarted)
+	}
+	wp.router = router
+	return nil
+}
+
+// queueRouter は名前付きキューの設定を返す（無効の場合は nil）
+func (wp *WorkerPool) queueRouter() *queueRouter {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return wp.router
+}
+
+// QueueStatus は名前付きキューごとの状況を返す（名前付きキューがない場合は nil）
+func (wp *WorkerPool) QueueStatus() map[string]QueueStatus {
+	return wp.queueRouter().snapshot()
+}
+
+// routeTask はルーティング規則に一致したタスクを名前付きキューに投入する（一致しなければ false）
+func (wp *WorkerPool) routeTask(task Task) (bool, error) {
+	router := wp.queueRouter()
+	q := router.route(task)
+	if q == nil {
+		return false, nil
+	}
+	return true, router.push(q, task, wp.shutdownSignal())
+}
+
+// startQueueWorkers は名前付きキューごとに専用ワーカーを起動する
+func (wp *WorkerPool) startQueueWorkers(router *queueRouter) {
+	router.reopen()
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	for _, q := range router.list() {
+		for i := 0; i < q.config.Workers; i++ {
+			id := wp.nextWorkerID
+			wp.nextWorkerID++
+			router.addRunning(q, 1)
+			wp.wg.Add(1)
+			go wp.queueWorker(id, router, q)
+		}
+	}
+}
+
+// queueWorker は名前付きキューだけを処理するワーカー
+// 遅延起動・Resize の対象外で、キューが閉じられ空になるまで常駐する
+func (wp *WorkerPool) queueWorker(id int, router *queueRouter, q *namedQueue) {
+	defer wp.wg.Done()
+	defer router.addRunning(q, -1)
+
+	defer wp.pinWorkerThread(id)()
+	defer wp.workerStats.started(id)()
+
+	event("worker.queue_started").worker(id).logf("📬 専用ワーカー %d がキュー [%s] で開始されました\n", id, q.config.Name)
+	for {
+		wp.lifecycle.waitWhilePaused()
+
+		// レート上限を超えないよう、取り出す前にトークンが補充されるまで待つ
+		if wait := router.reserve(q); wait > 0 {
+			time.Sleep(wait)
+		}
+		task, err := q.queue.pop(nil, 0)
+		if err != nil {
+			break
+		}
+		// 取り出したタスクはキュー滞留数から実行中に移す（停止処理がどちらにも数えない瞬間を作らない）
+		router.addBusy(q, 1)
+		wp.trackQueued(task.Type, -1)
+		wp.lifecycle.waitWhilePaused()
+		if !wp.hold(task) {
+			wp.executeTask(task, id)
+		}
+		router.addBusy(q, -1)
+	}
+	event("worker.queue_stopped").worker(id).logf("🛑 専用ワーカー %d がキュー [%s] を終了しました\n", id, q.config.Name)
+}
+
+// mergeQueueStatus はサブプールの名前付きキューの状況を名前ごとに合算する
+func mergeQueueStatus(total map[string]QueueStatus, queues map[string]QueueStatus) map[string]QueueStatus {
+	for name, status := range queues {
+		if total == nil {
+			total = make(map[string]QueueStatus)
+		}
+		merged := total[name]
+		merged.Size += status.Size
+		merged.Workers += status.Workers
+		merged.Rate += status.Rate
+		merged.Queued += status.Queued
+		merged.Busy += status.Busy
+		merged.Routed += status.Routed
+		merged.Throttled += status.Throttled
+		total[name] = merged
+	}
+	return total
+}
+
+// handleQueues は GET /queues で名前付きキューごとの状況を返す
+func (m *Monitor) handleQueues(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+	queues := m.pool.Snapshot().Queues
+	if queues == nil {
+		queues = map[string]QueueStatus{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"queues": queues})
+}
@@ -0,0 +1,216 @@
+package workerpool
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrPayloadUnavailable はブロブストアに退避したペイロードを読み込めない場合のエラー
+var ErrPayloadUnavailable = errors.New("退避したペイロードを読み込めません")
+
+// ペイロードの退避の既定値
+const (
+	defaultOffloadThreshold = 256 << 10 // 256KiB
+	defaultOffloadPrefix    = "payloads/"
+	defaultOffloadTimeout   = 30 * time.Second
+)
+
+// BlobStore はペイロードの退避先（S3・GCS・ディレクトリなど）
+type BlobStore interface {
+	// Put はオブジェクトを保存する
+	Put(ctx context.Context, key string, data []byte) error
+	// Get はオブジェクトを読み込む
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete はオブジェクトを削除する
+	Delete(ctx context.Context, key string) error
+}
+
+// PayloadOffload はペイロードの退避の設定
+// JSON にしたペイロードが Threshold を超えるタスクは、ペイロードをブロブストアに保存して参照（PayloadRef）だけをキュー・ストア・リモートに流す。
+// ワーカーは実行の直前にペイロードを読み込み直すため、メモリとメッセージの上限を超えずに大きなタスクを扱える
+type PayloadOffload struct {
+	Threshold int           `json:"threshold_bytes"` // 退避するペイロードの大きさ（0 の場合は256KiB）
+	Prefix    string        `json:"prefix"`          // オブジェクトのキーの接頭辞（空の場合は payloads/）
+	Timeout   time.Duration `json:"timeout_ns"`      // 保存・読み込みのタイムアウト（0 の場合は30秒）
+}
+
+// withDefaults は既定値を補った設定を返す
+func (o PayloadOffload) withDefaults() PayloadOffload {
+	if o.Threshold <= 0 {
+		o.Threshold = defaultOffloadThreshold
+	}
+	if o.Prefix == "" {
+		o.Prefix = defaultOffloadPrefix
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaultOffloadTimeout
+	}
+	return o
+}
+
+// PayloadRef はブロブストアに退避したペイロードの参照（タスクの Payload に入る）
+// ストアやリモートを経由して map[string]interface{} になった場合も "$blob" のキーで参照とみなす
+type PayloadRef struct {
+	Key    string `json:"$blob"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// payloadOffloader はペイロードの退避先と設定
+type payloadOffloader struct {
+	store  BlobStore
+	config PayloadOffload
+}
+
+// SetPayloadOffload は大きなペイロードをブロブストアに退避する（Start 前に呼び出すこと。store が nil で無効化）
+// 完了したタスクのオブジェクトは削除する。失敗したタスクのオブジェクトはDLQからの再投入のために残すため、
+// 保存先のライフサイクルルールなどで期限を設けること
+func (wp *WorkerPool) SetPayloadOffload(store BlobStore, config PayloadOffload) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if store == nil {
+		wp.offloader = nil
+		return
+	}
+	wp.offloader = &payloadOffloader{store: store, config: config.withDefaults()}
+}
+
+// SetPayloadOffload はすべてのサブプールにペイロードの退避を設定する
+func (tp *TypedPool) SetPayloadOffload(store BlobStore, config PayloadOffload) {
+	for _, pool := range tp.subPools() {
+		pool.SetPayloadOffload(store, config)
+	}
+}
+
+// payloadOffloader はペイロードの退避先を返す（無効の場合は nil）
+func (wp *WorkerPool) payloadOffloader() *payloadOffloader {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return wp.offloader
+}
+
+// offloadPayload はペイロードが閾値を超えていればブロブストアに保存し、参照に置き換えたタスクを返す
+func (wp *WorkerPool) offloadPayload(task Task) (Task, error) {
+	offloader := wp.payloadOffloader()
+	if offloader == nil || task.Payload == nil {
+		return task, nil
+	}
+	if _, ok := payloadRefOf(task.Payload); ok {
+		return task, nil // 退避済み（DLQからの再投入など）
+	}
+	data, err := json.Marshal(task.Payload)
+	if err != nil {
+		return task, fmt.Errorf("ペイロードを JSON にできません: %w", err)
+	}
+	if len(data) <= offloader.config.Threshold {
+		return task, nil
+	}
+
+	sum := sha256.Sum256(data)
+	ref := PayloadRef{
+		Key:    fmt.Sprintf("%s%d-%s", offloader.config.Prefix, task.ID, hex.EncodeToString(sum[:8])),
+		Size:   len(data),
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+	ctx, cancel := context.WithTimeout(task.context(), offloader.config.Timeout)
+	defer cancel()
+	if err := offloader.store.Put(ctx, ref.Key, data); err != nil {
+		return task, fmt.Errorf("ペイロードを退避できません: %w", err)
+	}
+	task.Payload = ref
+	event("payload.offloaded").taskOf(task).logf("📦 タスク %d のペイロード (%s) を %s に退避しました\n", task.ID, formatBytes(uint64(len(data))), ref.Key)
+	return task, nil
+}
+
+// rehydratePayload は退避したペイロードをブロブストアから読み込み、JSON としてデコードした値を返す
+// 参照でないペイロードはそのまま返す
+func (wp *WorkerPool) rehydratePayload(ctx context.Context, payload interface{}) (interface{}, error) {
+	ref, ok := payloadRefOf(payload)
+	if !ok {
+		return payload, nil
+	}
+	offloader := wp.payloadOffloader()
+	if offloader == nil {
+		return nil, fmt.Errorf("%w: ブロブストアが設定されていません (%s)", ErrPayloadUnavailable, ref.Key)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, offloader.config.Timeout)
+	defer cancel()
+	data, err := offloader.store.Get(ctx, ref.Key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrPayloadUnavailable, ref.Key, err)
+	}
+	if ref.SHA256 != "" {
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != ref.SHA256 {
+			return nil, fmt.Errorf("%w: 退避したペイロード %s のチェックサムが一致しません", ErrTaskCorrupt, ref.Key)
+		}
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("%w: 退避したペイロード %s: %v", ErrTaskCorrupt, ref.Key, err)
+	}
+	return decoded, nil
+}
+
+// discardPayload は退避したペイロードを削除する（受け付けなかったタスク・完了したタスク用）
+func (wp *WorkerPool) discardPayload(task Task) {
+	ref, ok := payloadRefOf(task.Payload)
+	if !ok {
+		return
+	}
+	offloader := wp.payloadOffloader()
+	if offloader == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), offloader.config.Timeout)
+	defer cancel()
+	if err := offloader.store.Delete(ctx, ref.Key); err != nil {
+		event("payload.delete_failed").taskOf(task).failed(err).logf("⚠️ タスク %d の退避したペイロード %s を削除できません: %v\n", task.ID, ref.Key, err)
+	}
+}
+
+// payloadRefOf はペイロードが退避したペイロードの参照であれば返す
+func payloadRefOf(payload interface{}) (PayloadRef, bool) {
+	switch p := payload.(type) {
+	case PayloadRef:
+		return p, true
+	case *PayloadRef:
+		if p == nil {
+			return PayloadRef{}, false
+		}
+		return *p, true
+	case map[string]interface{}:
+		// ストア・リモートを経由して JSON からデコードされた参照
+		key, ok := p["$blob"].(string)
+		if !ok || key == "" {
+			return PayloadRef{}, false
+		}
+		ref := PayloadRef{Key: key}
+		if size, ok := p["size"].(float64); ok {
+			ref.Size = int(size)
+		}
+		ref.SHA256, _ = p["sha256"].(string)
+		return ref, true
+	}
+	return PayloadRef{}, false
+}
+
+// Get はファイルを読み込む（BlobStore として使う場合）
+func (d DirStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(d.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("オブジェクト %s がありません: %w", key, err)
+	}
+	return data, err
+}
@@ -0,0 +1,129 @@
+package workerpool
+
+import (
+	"sync"
+)
+
+// resultOrderer は完了順に届いた結果をバッファし、投入順に並べ直して返す
+type resultOrderer struct {
+	mu         sync.Mutex
+	sendMu     sync.Mutex // 解放した結果の送信順を保つためのロック
+	groupLabel string     // 順序を保証する単位となるラベル（空ならプール全体）
+	assigned   map[string]uint64
+	next       map[string]uint64
+	buffered   map[string]map[uint64]*TaskResult // nil は結果を返さないタスク
+}
+
+func newResultOrderer(groupLabel string) *resultOrderer {
+	return &resultOrderer{
+		groupLabel: groupLabel,
+		assigned:   make(map[string]uint64),
+		next:       make(map[string]uint64),
+		buffered:   make(map[string]map[uint64]*TaskResult),
+	}
+}
+
+// group はタスクが属する順序グループを返す
+func (o *resultOrderer) group(labels map[string]string) string {
+	if o.groupLabel == "" {
+		return ""
+	}
+	return labels[o.groupLabel]
+}
+
+// assign は投入順の番号を割り当てる
+func (o *resultOrderer) assign(task *Task) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	group := o.group(task.Labels)
+	o.assigned[group]++
+	task.orderSeq = o.assigned[group]
+}
+
+// complete は結果（nil の場合はその番号を飛ばす）を登録し、投入順に返せるようになった結果を返す
+func (o *resultOrderer) complete(labels map[string]string, seq uint64, result *TaskResult) []TaskResult {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	group := o.group(labels)
+	next := o.next[group]
+	if next == 0 {
+		next = 1
+	}
+
+	// 既に追い越された番号（重複実行など）はそのまま返す
+	if seq < next {
+		if result == nil {
+			return nil
+		}
+		return []TaskResult{*result}
+	}
+
+	buffered, exists := o.buffered[group]
+	if !exists {
+		buffered = make(map[uint64]*TaskResult)
+		o.buffered[group] = buffered
+	}
+	buffered[seq] = result
+
+	released := make([]TaskResult, 0)
+	for {
+		r, ok := buffered[next]
+		if !ok {
+			break
+		}
+		delete(buffered, next)
+		if r != nil {
+			released = append(released, *r)
+		}
+		next++
+	}
+	o.next[group] = next
+	return released
+}
+
+// SetOrderedResults は GetResult で返す結果を完了順ではなく投入順にする（Start 前に呼び出すこと）
+// groupLabel を指定するとそのラベルの値ごとに投入順を保証し、空の場合はプール全体で保証する。
+// 先に投入したタスクの完了を待つため、後続の結果は前のタスクが終わるまで保留される。
+// 負荷制御で保留されたタスクや他のインスタンスから引き取ったタスクは順序保証の対象外
+func (wp *WorkerPool) SetOrderedResults(enabled bool, groupLabel string) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if !enabled {
+		wp.orderer = nil
+		return
+	}
+	wp.orderer = newResultOrderer(groupLabel)
+	event("pool.ordered_results").logf("🔢 結果を投入順に返します (グループ: %q)\n", groupLabel)
+}
+
+// resultOrdererFor は順序保証の対象となるタスクの場合に orderer を返す
+func (wp *WorkerPool) resultOrdererFor(task Task) (*resultOrderer, bool) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.orderer == nil || task.orderSeq == 0 {
+		return nil, false
+	}
+	return wp.orderer, true
+}
+
+// deliverOrdered は順序保証の対象タスクの結果を登録し、返せるようになった結果を送信する
+// result が nil の場合は結果を返さないタスクとしてその番号を飛ばす
+func (wp *WorkerPool) deliverOrdered(orderer *resultOrderer, task Task, result *TaskResult) {
+	orderer.sendMu.Lock()
+	defer orderer.sendMu.Unlock()
+
+	for _, released := range orderer.complete(task.Labels, task.orderSeq, result) {
+		wp.inbox.deliver(wp.results, released, wp.subscribers.active())
+	}
+}
+
+// skipOrdered は結果を返さずに終わったタスクの番号を飛ばす
+func (wp *WorkerPool) skipOrdered(task Task) {
+	if orderer, ok := wp.resultOrdererFor(task); ok {
+		wp.deliverOrdered(orderer, task, nil)
+	}
+}
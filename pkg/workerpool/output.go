@@ -0,0 +1,58 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// maxTaskOutput はタスク結果に残す出力の上限（超えた場合は末尾を残す）
+const maxTaskOutput = 64 * 1024
+
+type taskOutputKey struct{}
+
+// TaskOutput はプロセッサがログなどの出力を書き込む先を返す
+// 書き込んだ内容は TaskResult.Output として結果に含まれる（プール外で呼んだ場合は破棄される）
+func TaskOutput(ctx context.Context) io.Writer {
+	if output, ok := ctx.Value(taskOutputKey{}).(io.Writer); ok {
+		return output
+	}
+	return io.Discard
+}
+
+// WithTaskOutput はプロセッサの出力先を設定したコンテキストを返す
+// プール外（リモートワーカーなど）でプロセッサを呼び出す場合に使う
+func WithTaskOutput(ctx context.Context, output io.Writer) context.Context {
+	return context.WithValue(ctx, taskOutputKey{}, output)
+}
+
+// outputBuffer は複数のゴルーチンから書き込める、上限付きの出力バッファ
+type outputBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf.Write(p)
+	if over := b.buf.Len() - maxTaskOutput; over > 0 {
+		b.buf.Next(over)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+// String は出力を返す（上限を超えた場合は先頭を省略したことを示す）
+func (b *outputBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.truncated {
+		return "...(省略)...\n" + b.buf.String()
+	}
+	return b.buf.String()
+}
@@ -0,0 +1,359 @@
+package workerpool
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"time"
+)
+
+// Parquet ファイルの最小限の書き込み
+// 列はすべて REQUIRED、エンコーディングは PLAIN、圧縮なしで、行グループごとに列チャンクを1ページで書き出す
+// （外部ライブラリを使わずに分析基盤へ渡すため。読み込みは pyarrow・DuckDB・Spark などで行う）
+
+// parquetMagic はファイルの先頭と末尾に置く識別子
+const parquetMagic = "PAR1"
+
+// parquetRowGroupBytes は1つの行グループにまとめる値の大きさの目安
+// 溜まった行はこの大きさに達するたびに行グループとして書き出し、メモリから解放する
+const parquetRowGroupBytes = 4 << 20
+
+// Parquet の物理型
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+)
+
+// Parquet の変換型（論理型）
+const (
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+)
+
+// Parquet のエンコーディング
+const (
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+// parquetFileWriter は結果を行グループの大きさまで溜めて列ごとに書き出し、close でフッターを書く
+type parquetFileWriter struct {
+	file          *os.File
+	fields        []resultField
+	rowGroupBytes int64 // 行グループを書き出す大きさ
+
+	rows    [][]interface{}   // まだ書き出していない行
+	pending int64             // まだ書き出していない値の大きさ
+	offset  int64             // ファイルに書き込んだバイト数（次の列チャンクの位置）
+	groups  []parquetRowGroup // 書き出した行グループ（フッターに記録する）
+	bytes   int64             // 値の大きさの合計（切り替えの判定用）
+}
+
+// parquetRowGroup は書き出した行グループの位置
+type parquetRowGroup struct {
+	rows   int64
+	chunks []parquetColumnChunk
+}
+
+// parquetColumnChunk は列チャンク（ページヘッダとページ）の位置と大きさ
+type parquetColumnChunk struct {
+	offset int64
+	size   int64
+}
+
+func newParquetFileWriter(file *os.File, fields []resultField) *parquetFileWriter {
+	return &parquetFileWriter{file: file, fields: fields, rowGroupBytes: parquetRowGroupBytes}
+}
+
+func (w *parquetFileWriter) write(row []interface{}) error {
+	w.rows = append(w.rows, row)
+	for i, value := range row {
+		var size int64 = 8
+		if w.fields[i].kind == fieldString {
+			size = int64(4 + len(value.(string)))
+		}
+		w.pending += size
+		w.bytes += size
+	}
+	if w.pending >= w.rowGroupBytes {
+		return w.flushRowGroup()
+	}
+	return nil
+}
+
+func (w *parquetFileWriter) size() int64 {
+	return w.bytes
+}
+
+func (w *parquetFileWriter) close() error {
+	err := w.flushRowGroup()
+	if err == nil {
+		err = w.writeFooter()
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// flushRowGroup は溜まった行を1つの行グループとして書き出す
+func (w *parquetFileWriter) flushRowGroup() error {
+	if len(w.rows) == 0 {
+		return nil
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+
+	group := parquetRowGroup{rows: int64(len(w.rows)), chunks: make([]parquetColumnChunk, len(w.fields))}
+	for i, field := range w.fields {
+		chunk := encodeColumnChunk(field.kind, w.rows, i)
+		group.chunks[i] = parquetColumnChunk{offset: w.offset, size: int64(len(chunk))}
+		if err := w.writeBytes(chunk); err != nil {
+			return err
+		}
+	}
+	w.groups = append(w.groups, group)
+	w.rows = nil
+	w.pending = 0
+	return nil
+}
+
+// writeFooter はファイルのメタデータと末尾の識別子を書く
+func (w *parquetFileWriter) writeFooter() error {
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+	meta := encodeParquetMetadata(w.fields, w.groups)
+	footer := binary.LittleEndian.AppendUint32(meta, uint32(len(meta)))
+	return w.writeBytes(append(footer, parquetMagic...))
+}
+
+// writeMagic はまだ何も書いていなければ先頭の識別子を書く
+func (w *parquetFileWriter) writeMagic() error {
+	if w.offset > 0 {
+		return nil
+	}
+	return w.writeBytes([]byte(parquetMagic))
+}
+
+func (w *parquetFileWriter) writeBytes(data []byte) error {
+	n, err := w.file.Write(data)
+	w.offset += int64(n)
+	return err
+}
+
+// parquetType は項目の種類に対応する物理型と変換型を返す（変換型がない場合は -1）
+func parquetType(kind fieldKind) (physical, converted int32) {
+	switch kind {
+	case fieldInt:
+		return parquetInt64, -1
+	case fieldFloat:
+		return parquetDouble, -1
+	case fieldBool:
+		return parquetBoolean, -1
+	case fieldTime:
+		return parquetInt64, parquetTimestampMillis
+	default:
+		return parquetByteArray, parquetUTF8
+	}
+}
+
+// encodeColumnChunk は行の1列をページヘッダ付きの1ページに変換する
+func encodeColumnChunk(kind fieldKind, rows [][]interface{}, column int) []byte {
+	page := encodePlainColumn(kind, rows, column)
+
+	header := newThriftCompact()
+	header.i32(1, 0) // DATA_PAGE
+	header.i32(2, int32(len(page)))
+	header.i32(3, int32(len(page)))
+	header.beginStruct(5) // DataPageHeader
+	header.i32(1, int32(len(rows)))
+	header.i32(2, parquetPlain)
+	header.i32(3, parquetRLE)
+	header.i32(4, parquetRLE)
+	header.end()
+	header.end()
+
+	return append(header.buf, page...)
+}
+
+// encodeParquetMetadata はスキーマと行グループの位置をファイルのメタデータ（FileMetaData）に変換する
+func encodeParquetMetadata(fields []resultField, groups []parquetRowGroup) []byte {
+	var numRows int64
+	for _, group := range groups {
+		numRows += group.rows
+	}
+
+	meta := newThriftCompact()
+	meta.i32(1, 1) // version
+	meta.list(2, thriftStruct, len(fields)+1)
+	meta.push() // ルート
+	meta.str(4, "schema")
+	meta.i32(5, int32(len(fields)))
+	meta.end()
+	for _, field := range fields {
+		physical, converted := parquetType(field.kind)
+		meta.push()
+		meta.i32(1, physical)
+		meta.i32(3, 0) // REQUIRED
+		meta.str(4, field.name)
+		if converted >= 0 {
+			meta.i32(6, converted)
+		}
+		meta.end()
+	}
+	meta.i64(3, numRows)
+	meta.list(4, thriftStruct, len(groups)) // 行グループ
+	for _, group := range groups {
+		var total int64
+		meta.push()
+		meta.list(1, thriftStruct, len(fields))
+		for i, field := range fields {
+			physical, _ := parquetType(field.kind)
+			chunk := group.chunks[i]
+			total += chunk.size
+			meta.push() // ColumnChunk
+			meta.i64(2, chunk.offset)
+			meta.beginStruct(3) // ColumnMetaData
+			meta.i32(1, physical)
+			meta.list(2, thriftI32, 1)
+			meta.varint(parquetPlain)
+			meta.list(3, thriftBinary, 1)
+			meta.rawString(field.name)
+			meta.i32(4, 0) // UNCOMPRESSED
+			meta.i64(5, group.rows)
+			meta.i64(6, chunk.size)
+			meta.i64(7, chunk.size)
+			meta.i64(9, chunk.offset)
+			meta.end()
+			meta.end()
+		}
+		meta.i64(2, total)
+		meta.i64(3, group.rows)
+		meta.end()
+	}
+	meta.str(6, "worker-example")
+	meta.end()
+	return meta.buf
+}
+
+// encodePlainColumn は列の値を PLAIN エンコーディングで並べる
+func encodePlainColumn(kind fieldKind, rows [][]interface{}, column int) []byte {
+	var page []byte
+	if kind == fieldBool {
+		page = make([]byte, (len(rows)+7)/8)
+		for i, row := range rows {
+			if row[column].(bool) {
+				page[i/8] |= 1 << (i % 8)
+			}
+		}
+		return page
+	}
+
+	for _, row := range rows {
+		switch value := row[column].(type) {
+		case int64:
+			page = binary.LittleEndian.AppendUint64(page, uint64(value))
+		case float64:
+			page = binary.LittleEndian.AppendUint64(page, math.Float64bits(value))
+		case time.Time:
+			var millis int64
+			if !value.IsZero() {
+				millis = value.UnixMilli()
+			}
+			page = binary.LittleEndian.AppendUint64(page, uint64(millis))
+		case string:
+			page = binary.LittleEndian.AppendUint32(page, uint32(len(value)))
+			page = append(page, value...)
+		}
+	}
+	return page
+}
+
+// Thrift Compact Protocol の型
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftCompact は Parquet のメタデータを Thrift Compact Protocol で書き出す
+type thriftCompact struct {
+	buf  []byte
+	last []int16 // 構造体ごとの直前のフィールドID（差分で書くため）
+}
+
+func newThriftCompact() *thriftCompact {
+	return &thriftCompact{last: []int16{0}}
+}
+
+// field はフィールドのヘッダを書く
+func (t *thriftCompact) field(id int16, typ byte) {
+	last := &t.last[len(t.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.varint(int64(id))
+	}
+	*last = id
+}
+
+// varint は整数を ZigZag 変換した可変長整数で書く
+func (t *thriftCompact) varint(v int64) {
+	t.buf = binary.AppendUvarint(t.buf, uint64((v<<1)^(v>>63)))
+}
+
+func (t *thriftCompact) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftCompact) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftCompact) str(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.rawString(s)
+}
+
+// rawString はリストの要素などヘッダなしの文字列を書く
+func (t *thriftCompact) rawString(s string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+// list はリストのヘッダを書く（要素は続けて書く）
+func (t *thriftCompact) list(id int16, elem byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elem)
+		return
+	}
+	t.buf = append(t.buf, 0xf0|elem)
+	t.buf = binary.AppendUvarint(t.buf, uint64(size))
+}
+
+// beginStruct は構造体のフィールドを開始する
+func (t *thriftCompact) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.push()
+}
+
+// push はリストの要素などヘッダなしの構造体を開始する
+func (t *thriftCompact) push() {
+	t.last = append(t.last, 0)
+}
+
+// end は構造体を終える
+func (t *thriftCompact) end() {
+	t.buf = append(t.buf, 0)
+	t.last = t.last[:len(t.last)-1]
+}
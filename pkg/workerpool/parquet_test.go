@@ -0,0 +1,214 @@
+package workerpool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 行グループの大きさに達するたびに書き出し、溜める行は行グループ1つ分までに抑える
+func TestParquetWriterFlushesRowGroups(t *testing.T) {
+	fields, err := compileResultFields([]string{"task_id", "task_type", "success", "end_time"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "results.parquet")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newParquetFileWriter(file, fields)
+	w.rowGroupBytes = 1000
+
+	const rows = 500
+	for i := 0; i < rows; i++ {
+		if err := w.write([]interface{}{int64(i), "email", i%3 == 0, time.UnixMilli(int64(i))}); err != nil {
+			t.Fatal(err)
+		}
+		if w.pending >= w.rowGroupBytes {
+			t.Fatalf("%d 行目: 書き出していない値が %d バイト溜まっています", i, w.pending)
+		}
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := readParquetMetadata(t, data)
+	if got := meta[3]; got != int64(rows) {
+		t.Fatalf("num_rows = %v, want %d", got, rows)
+	}
+	groups := meta[4].([]interface{})
+	if len(groups) < 2 {
+		t.Fatalf("行グループ = %d, want 2以上", len(groups))
+	}
+
+	var ids []int64
+	var successes []bool
+	for _, g := range groups {
+		group := g.(map[int16]interface{})
+		count := int(group[3].(int64))
+		chunks := group[1].([]interface{})
+		if len(chunks) != len(fields) {
+			t.Fatalf("列チャンク = %d, want %d", len(chunks), len(fields))
+		}
+		page := readParquetPage(t, data, chunks[0].(map[int16]interface{}), count)
+		for i := 0; i < count; i++ {
+			ids = append(ids, int64(binary.LittleEndian.Uint64(page[i*8:])))
+		}
+		page = readParquetPage(t, data, chunks[2].(map[int16]interface{}), count)
+		for i := 0; i < count; i++ {
+			successes = append(successes, page[i/8]&(1<<(i%8)) != 0)
+		}
+	}
+	if len(ids) != rows {
+		t.Fatalf("読み込んだ行 = %d, want %d", len(ids), rows)
+	}
+	for i := range ids {
+		if ids[i] != int64(i) || successes[i] != (i%3 == 0) {
+			t.Fatalf("%d 行目 = (%d, %v), want (%d, %v)", i, ids[i], successes[i], i, i%3 == 0)
+		}
+	}
+}
+
+// 結果のないファイルも行グループのない Parquet ファイルとして書き終える
+func TestParquetWriterEmptyFile(t *testing.T) {
+	fields, err := compileResultFields([]string{"task_id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "empty.parquet")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newParquetFileWriter(file, fields).close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := readParquetMetadata(t, data)
+	if meta[3] != int64(0) || len(meta[4].([]interface{})) != 0 {
+		t.Fatalf("num_rows = %v, row_groups = %v", meta[3], meta[4])
+	}
+}
+
+// readParquetMetadata はファイルの先頭・末尾の識別子を確かめ、フッターの FileMetaData を読み込む
+func readParquetMetadata(t *testing.T, data []byte) map[int16]interface{} {
+	t.Helper()
+	if len(data) < 12 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatalf("Parquet の識別子がありません (%d バイト)", len(data))
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	r := &thriftReader{t: t, data: data[len(data)-8-size : len(data)-8]}
+	return r.structValue()
+}
+
+// readParquetPage は列チャンクのページヘッダを読み、ページの内容を返す
+func readParquetPage(t *testing.T, data []byte, chunk map[int16]interface{}, rows int) []byte {
+	t.Helper()
+	columnMeta := chunk[3].(map[int16]interface{})
+	offset := columnMeta[9].(int64)
+	if columnMeta[5] != int64(rows) {
+		t.Fatalf("num_values = %v, want %d", columnMeta[5], rows)
+	}
+	r := &thriftReader{t: t, data: data[offset:]}
+	header := r.structValue()
+	if values := header[5].(map[int16]interface{})[1]; values != int64(rows) {
+		t.Fatalf("ページの num_values = %v, want %d", values, rows)
+	}
+	size := int(header[3].(int64))
+	if int64(r.pos)+int64(size) != columnMeta[7].(int64) {
+		t.Fatalf("列チャンクの大きさ = %v, want %d", columnMeta[7], r.pos+size)
+	}
+	return r.data[r.pos : r.pos+size]
+}
+
+// thriftReader はテストで Thrift Compact Protocol を読み込む
+// 構造体はフィールドIDごとの値（整数は int64、文字列は []byte、リストは []interface{}）にする
+type thriftReader struct {
+	t    *testing.T
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) structValue() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		last = id
+		fields[id] = r.value(header & 0x0f)
+	}
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case 3:
+		return int64(r.byte())
+	case 4, thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		size := int(r.uvarint())
+		value := r.data[r.pos : r.pos+size]
+		r.pos += size
+		return bytes.Clone(value)
+	case thriftList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		values := make([]interface{}, size)
+		for i := range values {
+			values[i] = r.value(header & 0x0f)
+		}
+		return values
+	case thriftStruct:
+		return r.structValue()
+	default:
+		r.t.Fatalf("未対応の Thrift の型 %d (位置 %d)", typ, r.pos)
+		return nil
+	}
+}
+
+func (r *thriftReader) byte() byte {
+	if r.pos >= len(r.data) {
+		r.t.Fatal(fmt.Errorf("Thrift のデータが途中で終わっています"))
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.t.Fatalf("Thrift の可変長整数が不正です (位置 %d)", r.pos)
+	}
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
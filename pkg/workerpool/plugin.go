@@ -0,0 +1,123 @@
+package workerpool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"strings"
+)
+
+// PluginSymbol はGoプラグインが公開するプロセッサ一覧のシンボル名
+// プラグインは次のような変数を公開する:
+//
+//	var Processors = map[workerpool.TaskType]workerpool.TaskProcessor{...}
+const PluginSymbol = "Processors"
+
+// PluginSet はプラグインから読み込んだプロセッサの集合
+type PluginSet struct {
+	processors map[TaskType]TaskProcessor
+	sources    map[TaskType]string // タスクタイプ → 読み込み元のファイル
+	sidecars   []*Sidecar
+}
+
+// LoadPlugins はディレクトリからプロセッサを読み込む
+//   - *.so: Goプラグイン（PluginSymbol の変数を参照）
+//   - その他の実行可能ファイル: サイドカー（標準入出力のJSON行プロトコル、Sidecar 参照）
+//
+// 同じタスクタイプを複数のプラグインが提供する場合はエラーを返す
+func LoadPlugins(dir string) (*PluginSet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("プラグインディレクトリを読み込めません: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	set := &PluginSet{
+		processors: make(map[TaskType]TaskProcessor),
+		sources:    make(map[TaskType]string),
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		var processors map[TaskType]TaskProcessor
+		if filepath.Ext(path) == ".so" {
+			processors, err = loadGoPlugin(path)
+		} else if info, statErr := entry.Info(); statErr == nil && info.Mode()&0111 != 0 {
+			var sidecar *Sidecar
+			sidecar, err = StartSidecar(path)
+			if err == nil {
+				set.sidecars = append(set.sidecars, sidecar)
+				processors = sidecar.Processors()
+			}
+		} else {
+			continue
+		}
+		if err != nil {
+			set.Close()
+			return nil, err
+		}
+
+		for taskType, processor := range processors {
+			if source, exists := set.sources[taskType]; exists {
+				set.Close()
+				return nil, fmt.Errorf("タスクタイプ %s が %s と %s の両方で提供されています", taskType, source, path)
+			}
+			set.processors[taskType] = processor
+			set.sources[taskType] = path
+		}
+		event("plugin.loaded").logf("🔌 プラグイン %s を読み込みました (%d タイプ)\n", entry.Name(), len(processors))
+	}
+	return set, nil
+}
+
+// loadGoPlugin はGoプラグインからプロセッサを読み込む
+func loadGoPlugin(path string) (map[TaskType]TaskProcessor, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("プラグイン %s を開けません: %w", path, err)
+	}
+	symbol, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("プラグイン %s に %s がありません: %w", path, PluginSymbol, err)
+	}
+	processors, ok := symbol.(*map[TaskType]TaskProcessor)
+	if !ok {
+		return nil, fmt.Errorf("プラグイン %s の %s の型が不正です: %T", path, PluginSymbol, symbol)
+	}
+	return *processors, nil
+}
+
+// Processors は読み込んだプロセッサを返す
+func (s *PluginSet) Processors() map[TaskType]TaskProcessor {
+	processors := make(map[TaskType]TaskProcessor, len(s.processors))
+	for taskType, processor := range s.processors {
+		processors[taskType] = processor
+	}
+	return processors
+}
+
+// Register は読み込んだプロセッサをプールに登録する（Start 前に呼び出すこと）
+func (s *PluginSet) Register(pool Pool) error {
+	for taskType, processor := range s.processors {
+		if err := pool.RegisterProcessor(taskType, processor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close はサイドカーのプロセスを停止する（プールの停止後に呼び出すこと）
+func (s *PluginSet) Close() error {
+	var firstErr error
+	for _, sidecar := range s.sidecars {
+		if err := sidecar.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
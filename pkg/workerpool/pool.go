@@ -0,0 +1,96 @@
+package workerpool
+
+import (
+	"context"
+	"time"
+)
+
+// Pool はワーカープールの共通インターフェース
+// WorkerPool と TypedPool が実装し、Monitor はこのインターフェースを通してプールを監視する
+type Pool interface {
+	RegisterProcessor(taskType TaskType, processor TaskProcessor) error
+	ReplaceProcessor(taskType TaskType, processor TaskProcessor) error
+	UnregisterProcessor(taskType TaskType) error
+	AddTask(task Task) error
+	PlanTask(task Task) SubmissionPlan
+	Execute(ctx context.Context, task Task) (TaskResult, error)
+	GetResult() TaskResult
+	Results() <-chan TaskResult
+	OnResult(fn func(TaskResult)) (unsubscribe func())
+	Start() error
+	Stop()
+	Shutdown(ctx context.Context) ([]Task, error)
+	Snapshot() PoolSnapshot
+	PendingRetries() []RetryEntry
+	RetryNow(taskID int) error
+	CancelRetry(taskID int) error
+	CancelTask(taskID int) error
+	DeadLetters() []DeadLetter
+	RequeueDeadLetters(ids []uint64) (int, error)
+	PurgeDeadLetters(ids []uint64) int
+	CancelTasks(filter TaskFilter) int
+	RequeueDeadLettersWhere(filter TaskFilter) (int, error)
+	SetPriorityWhere(filter TaskFilter, priority Priority) int
+	SetMaintenanceWindows(windows []MaintenanceWindow) error
+	MaintenanceWindows() []MaintenanceWindow
+	State() PoolState
+	StateInfo() PoolStateInfo
+	Pause() error
+	Resume() error
+}
+
+// PoolSnapshot はある時点でのプールの状態
+type PoolSnapshot struct {
+	State          PoolState                // プールの状態
+	RunningWorkers int                      // 起動中のワーカー数
+	ActiveWorkers  int                      // タスクを実行中のワーカー数
+	BusyTime       time.Duration            // すべてのワーカーがタスクの実行に使った時間の累計（稼働率の算出用）
+	QueuedTasks    int                      // キュー滞留数
+	RetryingTasks  int                      // リトライ待ちのタスク数
+	DeferredTasks  int                      // 負荷制御で保留中のタスク数
+	HeldTasks      int                      // メンテナンスウィンドウ・下流の障害などのため保留中のタスク数
+	DelayedTasks   int                      // 予定時刻を待っているタスク数（AddTaskAt）
+	DeadLetters    int                      // DLQ内のタスク数
+	OverrunTasks   int64                    // コンテキストの終了後も猶予を超えて実行を続けたタスクの累計
+	QueuedByType   map[TaskType]int         // タイプ別のキュー滞留数
+	Admission      AdmissionStats           // アドミッション制御のカウンタ
+	Shadow         map[TaskType]ShadowStats // シャドー実行の集計
+	Heat           map[TaskType]TaskHeat    // タスクタイプの hot / cold の分類（分類が無効の場合は nil）
+	Queues         map[string]QueueStatus   // 名前付きキューごとの状況（名前付きキューがない場合は nil）
+	Memory         *MemoryStatus            // メモリ使用量の監視状況（監視が無効の場合は nil）
+	Downstream     []DownstreamStatus       // 下流のヘルスチェックの状況（登録がない場合は nil）
+	Forwarding     *ForwardStats            // ピアへの転送の集計（転送が無効の場合は nil）
+	Workers        []WorkerStats            // ワーカーごとの統計（ワーカーID順）
+}
+
+// Snapshot は現在のプールの状態を返す
+func (wp *WorkerPool) Snapshot() PoolSnapshot {
+	active, busy := wp.workerStats.utilization()
+	return PoolSnapshot{
+		State:          wp.State(),
+		RunningWorkers: wp.RunningWorkers() + wp.heatTracker().reservedWorkers() + wp.queueRouter().runningWorkers(),
+		ActiveWorkers:  active,
+		BusyTime:       busy,
+		QueuedTasks:    wp.queuedTasks(),
+		RetryingTasks:  wp.retries.len(),
+		DeferredTasks:  wp.DeferredCount(),
+		HeldTasks:      wp.HeldCount(),
+		DelayedTasks:   wp.DelayedCount(),
+		DeadLetters:    wp.dlq.Len(),
+		OverrunTasks:   wp.OverrunCount(),
+		QueuedByType:   wp.QueuedByType(),
+		Admission:      wp.AdmissionStats(),
+		Shadow:         wp.ShadowStats(),
+		Heat:           wp.TaskHeat(),
+		Queues:         wp.QueueStatus(),
+		Memory:         wp.MemoryStatus(),
+		Downstream:     wp.DownstreamStatus(),
+		Forwarding:     wp.ForwardStats(),
+		Workers:        wp.workerStats.snapshot(),
+	}
+}
+
+var (
+	_ Pool = (*WorkerPool)(nil)
+	_ Pool = (*TypedPool)(nil)
+)
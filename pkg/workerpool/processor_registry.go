@@ -0,0 +1,128 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// ErrProcessorExists は同じタスクタイプのプロセッサが既に登録されている場合のエラー
+	ErrProcessorExists = errors.New("プロセッサは既に登録されています")
+	// ErrProcessorNotFound は指定したタスクタイプのプロセッサが登録されていない場合のエラー
+	ErrProcessorNotFound = errors.New("プロセッサが登録されていません")
+	// ErrPoolStarted は開始後に行えない操作を呼び出した場合のエラー
+	ErrPoolStarted = errors.New("ワーカープールは既に開始されています")
+)
+
+// processorEntry は登録されたプロセッサと、それを使って実行中のタスク数
+// 差し替え時は新しいエントリを作るため、旧プロセッサで実行中のタスクだけを待てる
+type processorEntry struct {
+	processor   TaskProcessor
+	inFlight    sync.WaitGroup
+	executor    ExecutorInfo // DescribeExecutor で記録した実行環境（空の場合は in-process）
+	payloadType reflect.Type // RegisterTypedProcessor で登録したペイロードの型（型付きでない場合は nil）
+}
+
+// successor はプロセッサを差し替えた新しいエントリを返す
+// 実行中のタスク数は引き継がず、実行環境とペイロードの型は引き継ぐ
+func (e *processorEntry) successor(processor TaskProcessor) *processorEntry {
+	return &processorEntry{processor: processor, executor: e.executor, payloadType: e.payloadType}
+}
+
+// RegisterProcessor はタスクタイプのプロセッサを登録する（Start 前に呼び出すこと）
+// 登録済みのタイプは ErrProcessorExists、開始後は ErrPoolStarted を返す
+// 開始後に差し替える場合は ReplaceProcessor を使う
+func (wp *WorkerPool) RegisterProcessor(taskType TaskType, processor TaskProcessor) error {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.lifecycle.current() != StateCreated {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrPoolStarted)
+	}
+	if _, exists := wp.processors[taskType]; exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorExists)
+	}
+	wp.processors[taskType] = &processorEntry{processor: processor}
+	return nil
+}
+
+// ReplaceProcessor は登録済みのプロセッサを差し替える（開始後も可能）
+// 実行中のタスクは差し替え前のプロセッサで最後まで処理される
+func (wp *WorkerPool) ReplaceProcessor(taskType TaskType, processor TaskProcessor) error {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	_, err := wp.swapProcessorLocked(taskType, processor)
+	return err
+}
+
+// SwapProcessor はプロセッサを差し替え、旧プロセッサで実行中のタスクが終わるまで待つ
+// 差し替え以降に実行されるタスク（キュー待ち・リトライ待ちを含む）は新しいプロセッサで処理される
+// 戻った時点で旧プロセッサは使われていないため、旧プロセッサが持つリソースを安全に解放できる
+// ctx がキャンセルされた場合も差し替えは有効のまま、待機だけを打ち切って ctx のエラーを返す
+func (wp *WorkerPool) SwapProcessor(ctx context.Context, taskType TaskType, processor TaskProcessor) error {
+	wp.mu.Lock()
+	old, err := wp.swapProcessorLocked(taskType, processor)
+	wp.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		old.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		event("processor.drained").logf("🔁 タスクタイプ %s の旧プロセッサで実行中だったタスクがすべて完了しました\n", taskType)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// swapProcessorLocked はプロセッサを差し替えて旧エントリを返す（呼び出し側でロックを保持）
+// 実行環境とペイロードの型はタイプの設定として新しいエントリに引き継ぐ
+func (wp *WorkerPool) swapProcessorLocked(taskType TaskType, processor TaskProcessor) (*processorEntry, error) {
+	old, exists := wp.processors[taskType]
+	if !exists {
+		return nil, fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	wp.processors[taskType] = old.successor(processor)
+	event("processor.replaced").logf("🔁 タスクタイプ %s のプロセッサを差し替えました\n", taskType)
+	return old, nil
+}
+
+// UnregisterProcessor はプロセッサの登録を解除する（開始後も可能）
+// 解除後に実行されるそのタイプのタスクはプロセッサ未登録として失敗する
+func (wp *WorkerPool) UnregisterProcessor(taskType TaskType) error {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if _, exists := wp.processors[taskType]; !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	delete(wp.processors, taskType)
+	delete(wp.canaries, taskType)
+	return nil
+}
+
+// acquireProcessor はタスクを実行するプロセッサを取得し、実行中として記録する
+// カナリア設定中のタイプでは種別（VariantStable / VariantCanary）も返す
+// 実行が終わったら戻り値の release を呼ぶこと（未登録の場合も呼んでよい）
+func (wp *WorkerPool) acquireProcessor(task Task) (TaskProcessor, func(), string, bool) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	entry, variant, exists := wp.selectEntryLocked(task)
+	if !exists {
+		return nil, func() {}, "", false
+	}
+	entry.inFlight.Add(1)
+	return entry.processor, entry.inFlight.Done, variant, true
+}
@@ -0,0 +1,65 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type invoicePayload struct {
+	CustomerID string `json:"customer_id"`
+}
+
+// プロセッサを差し替えても、記録した実行環境とペイロードの型はタイプの設定として残る
+func TestProcessorReplacementKeepsExecutorAndPayloadType(t *testing.T) {
+	noop := func(ctx context.Context, task Task) error { return nil }
+	executor := ExecutorInfo{Kind: ExecutorExec, Detail: "bin/invoice"}
+
+	tests := []struct {
+		name    string
+		replace func(pool *WorkerPool) error
+	}{
+		{"ReplaceProcessor", func(pool *WorkerPool) error {
+			return pool.ReplaceProcessor("invoice", noop)
+		}},
+		{"SwapProcessor", func(pool *WorkerPool) error {
+			return pool.SwapProcessor(context.Background(), "invoice", noop)
+		}},
+		{"PromoteCanary", func(pool *WorkerPool) error {
+			if err := pool.SetCanary("invoice", noop, 50); err != nil {
+				return err
+			}
+			return pool.PromoteCanary("invoice")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := NewWorkerPool(1)
+			err := RegisterTypedProcessor(pool, "invoice", func(ctx context.Context, invoice invoicePayload) error {
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := DescribeExecutor(pool, "invoice", executor); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.replace(pool); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := pool.payloadType("invoice"), reflect.TypeFor[invoicePayload](); got != want {
+				t.Errorf("payloadType = %v, want %v", got, want)
+			}
+			capabilities := pool.TaskCapabilities()
+			if len(capabilities) != 1 || capabilities[0].Executor != executor {
+				t.Errorf("TaskCapabilities = %+v, want executor %+v", capabilities, executor)
+			}
+			if err := AddTypedTask(pool, Task{ID: 1, Type: "invoice"}, "not an invoice"); !errors.Is(err, ErrPayloadType) {
+				t.Errorf("AddTypedTask(string) = %v, want ErrPayloadType", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package workerpool
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// progressBarWidth はプログレスバーの幅（文字数）
+	progressBarWidth = 40
+	// defaultProgressInterval は画面を再描画する間隔
+	defaultProgressInterval = 200 * time.Millisecond
+	// defaultProgressLogLines はプログレスバーの下に表示する直近のメッセージ数
+	defaultProgressLogLines = 5
+)
+
+// ANSI エスケープシーケンス
+const (
+	ansiCursorUp  = "\x1b[%dA"
+	ansiClearLine = "\x1b[2K\r"
+)
+
+// ProgressUI はバッチ処理の進捗をターミナルに表示する（ANSI エスケープシーケンスで同じ位置を再描画）
+// 件数・成功/失敗・ワーカーとキューの状況・スループット・残り時間を表示し、
+// 実行中はプールのメッセージを取り込んでプログレスバーの下に直近の数行だけ表示する
+// 件数はモニターの集計を使う（Monitor.Start でプールの結果を購読する）
+type ProgressUI struct {
+	monitor  *Monitor
+	total    int // 総タスク数（0 以下の場合は不明として割合・残り時間を表示しない）
+	out      io.Writer
+	interval time.Duration
+	logLines int
+
+	mu       sync.Mutex
+	logs     []string // 直近のメッセージ
+	partial  string   // 改行で終わっていない書きかけのメッセージ
+	rendered int      // 前回描画した行数（再描画時にカーソルを戻す）
+	started  time.Time
+	previous io.Writer // 開始前のメッセージの出力先
+	running  bool
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewProgressUI は進捗表示を作成（total は投入予定の総タスク数）
+func NewProgressUI(monitor *Monitor, total int) *ProgressUI {
+	return &ProgressUI{
+		monitor:  monitor,
+		total:    total,
+		out:      os.Stdout,
+		interval: defaultProgressInterval,
+		logLines: defaultProgressLogLines,
+	}
+}
+
+// SetOutput は表示先を設定する（既定は標準出力。ANSI エスケープシーケンスに対応した端末であること）
+func (ui *ProgressUI) SetOutput(w io.Writer) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	ui.out = w
+}
+
+// SetInterval は再描画の間隔を設定する
+func (ui *ProgressUI) SetInterval(interval time.Duration) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	if interval > 0 {
+		ui.interval = interval
+	}
+}
+
+// SetLogLines はプログレスバーの下に表示するメッセージの行数を設定する（0 で表示しない）
+func (ui *ProgressUI) SetLogLines(lines int) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	ui.logLines = max(lines, 0)
+	ui.trimLogs()
+}
+
+// Start は進捗表示を開始する。停止するまでプールのメッセージは表示に取り込む
+func (ui *ProgressUI) Start() {
+	ui.mu.Lock()
+	if ui.running {
+		ui.mu.Unlock()
+		return
+	}
+	ui.running = true
+	ui.started = time.Now()
+	ui.rendered = 0
+	ui.stopCh = make(chan struct{})
+	interval := ui.interval
+	ui.mu.Unlock()
+
+	ui.previous = SetConsoleOutput(ui)
+
+	ui.wg.Add(1)
+	go func() {
+		defer ui.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ui.render()
+			case <-ui.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop は最終状態を描画して進捗表示を終了し、メッセージの出力先を元に戻す
+func (ui *ProgressUI) Stop() {
+	ui.mu.Lock()
+	if !ui.running {
+		ui.mu.Unlock()
+		return
+	}
+	ui.running = false
+	ui.mu.Unlock()
+
+	close(ui.stopCh)
+	ui.wg.Wait()
+	ui.render()
+	SetConsoleOutput(ui.previous)
+}
+
+// Write はプールのメッセージを受け取る（io.Writer）
+func (ui *ProgressUI) Write(p []byte) (int, error) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	text := ui.partial + string(p)
+	lines := strings.Split(text, "\n")
+	ui.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		if line = strings.TrimSpace(line); line != "" {
+			ui.logs = append(ui.logs, line)
+		}
+	}
+	ui.trimLogs()
+	return len(p), nil
+}
+
+// trimLogs は表示行数を超えた古いメッセージを捨てる（ui.mu を保持して呼び出すこと）
+func (ui *ProgressUI) trimLogs() {
+	if over := len(ui.logs) - ui.logLines; over > 0 {
+		ui.logs = append([]string(nil), ui.logs[over:]...)
+	}
+}
+
+// render は前回の表示を消して現在の進捗を描画する
+func (ui *ProgressUI) render() {
+	stats := ui.monitor.GetStats()
+	snapshot := ui.monitor.pool.Snapshot()
+
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	lines := ui.progressLines(stats, snapshot, time.Since(ui.started))
+	for _, log := range ui.logs {
+		lines = append(lines, "  "+log)
+	}
+
+	var b strings.Builder
+	if ui.rendered > 0 {
+		fmt.Fprintf(&b, ansiCursorUp, ui.rendered)
+	}
+	for _, line := range lines {
+		b.WriteString(ansiClearLine)
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	// 前回より行数が減った場合は残った行を消す
+	for i := len(lines); i < ui.rendered; i++ {
+		b.WriteString(ansiClearLine + "\n")
+	}
+	if extra := ui.rendered - len(lines); extra > 0 {
+		fmt.Fprintf(&b, ansiCursorUp, extra)
+	}
+	ui.rendered = len(lines)
+	io.WriteString(ui.out, b.String())
+}
+
+// progressLines は進捗の表示内容を組み立てる
+func (ui *ProgressUI) progressLines(stats PoolStats, snapshot PoolSnapshot, elapsed time.Duration) []string {
+	done := stats.CompletedTasks + stats.FailedTasks
+
+	// スループットは直近の値を優先し、まだ算出されていなければ開始からの平均を使う
+	throughput := stats.Throughput
+	if throughput <= 0 && elapsed > 0 {
+		throughput = float64(done) / elapsed.Seconds()
+	}
+
+	var progress, eta string
+	if ui.total > 0 {
+		ratio := min(float64(done)/float64(ui.total), 1)
+		filled := int(ratio * progressBarWidth)
+		progress = fmt.Sprintf("[%s%s] %3.0f%% %d/%d",
+			strings.Repeat("█", filled), strings.Repeat("░", progressBarWidth-filled), ratio*100, done, ui.total)
+
+		remaining := int64(ui.total) - done
+		switch {
+		case remaining <= 0:
+			eta = "完了"
+		case throughput > 0:
+			eta = (time.Duration(float64(remaining)/throughput) * time.Second).Round(time.Second).String()
+		default:
+			eta = "算出中"
+		}
+	} else {
+		progress = fmt.Sprintf("%d 件処理済み", done)
+		eta = "不明"
+	}
+
+	return []string{
+		fmt.Sprintf("📦 %s", progress),
+		fmt.Sprintf("✅ 成功: %d | ❌ 失敗: %d | 経過: %v | 残り: %s",
+			stats.CompletedTasks, stats.FailedTasks, elapsed.Round(time.Second), eta),
+		fmt.Sprintf("👷 ワーカー: %d (%s) | キュー: %d | リトライ待ち: %d | 保留: %d | DLQ: %d",
+			snapshot.RunningWorkers, snapshot.State, snapshot.QueuedTasks, snapshot.RetryingTasks,
+			snapshot.DeferredTasks+snapshot.HeldTasks, snapshot.DeadLetters),
+		fmt.Sprintf("⚡ スループット: %.2f件/秒", throughput),
+	}
+}
@@ -0,0 +1,253 @@
+package workerpool
+
+import (
+	"container/heap"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrQueueClosed はクローズ済みのキューに投入しようとした場合のエラー
+var ErrQueueClosed = errors.New("キューはクローズされています")
+
+var (
+	// errQueueStopped は待機中に停止シグナルを受けた場合のエラー
+	errQueueStopped = errors.New("キューの待機が中断されました")
+	// errQueueTimeout は取り出し待ちがタイムアウトした場合のエラー
+	errQueueTimeout = errors.New("キューの取り出しがタイムアウトしました")
+)
+
+// queueItem はキュー内の要素
+type queueItem struct {
+	task Task
+	seq  uint64 // 投入順（同じ優先度内での順序保証用）
+}
+
+// taskHeap は優先度の高い順、同じ優先度では投入順に並ぶヒープ
+type taskHeap []queueItem
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].task.Priority != h[j].task.Priority {
+		return h[i].task.Priority > h[j].task.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *taskHeap) Push(x interface{}) { *h = append(*h, x.(queueItem)) }
+
+func (h *taskHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// taskQueue は容量付きの優先度キュー
+// 満杯の場合は push がブロックし、空の場合は pop がブロックする
+type taskQueue struct {
+	mu       sync.Mutex
+	items    taskHeap
+	capacity int
+	seq      uint64
+	closed   bool
+	notEmpty chan struct{} // 要素が追加されたら閉じて差し替える
+	notFull  chan struct{} // 要素が取り出されたら閉じて差し替える
+}
+
+func newTaskQueue(capacity int) *taskQueue {
+	return &taskQueue{
+		capacity: capacity,
+		notEmpty: make(chan struct{}),
+		notFull:  make(chan struct{}),
+	}
+}
+
+// push はタスクを投入する。満杯の場合は空きが出るか stop が閉じられるまで待つ
+func (q *taskQueue) push(task Task, stop <-chan struct{}) error {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return ErrQueueClosed
+		}
+		if len(q.items) < q.capacity {
+			q.seq++
+			heap.Push(&q.items, queueItem{task: task, seq: q.seq})
+			close(q.notEmpty)
+			q.notEmpty = make(chan struct{})
+			q.mu.Unlock()
+			return nil
+		}
+		wait := q.notFull
+		q.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-stop:
+			return errQueueStopped
+		}
+	}
+}
+
+// tryPush はタスクを投入する。満杯・クローズ済みの場合は待たずに false を返す
+func (q *taskQueue) tryPush(task Task) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed || len(q.items) >= q.capacity {
+		return false
+	}
+	q.seq++
+	heap.Push(&q.items, queueItem{task: task, seq: q.seq})
+	close(q.notEmpty)
+	q.notEmpty = make(chan struct{})
+	return true
+}
+
+// pop は最も優先度の高いタスクを取り出す
+// キューがクローズされ空になった場合は ErrQueueClosed、stop が閉じられた場合は errQueueStopped、
+// timeout（0以下で無制限）を過ぎても空の場合は errQueueTimeout を返す
+func (q *taskQueue) pop(stop <-chan struct{}, timeout time.Duration) (Task, error) {
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	timedOut := false
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			item := heap.Pop(&q.items).(queueItem)
+			close(q.notFull)
+			q.notFull = make(chan struct{})
+			q.mu.Unlock()
+			return item.task, nil
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return Task{}, ErrQueueClosed
+		}
+		if timedOut {
+			q.mu.Unlock()
+			return Task{}, errQueueTimeout
+		}
+		wait := q.notEmpty
+		q.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-stop:
+			return Task{}, errQueueStopped
+		case <-timer:
+			// タイムアウト直前に投入されたタスクを取りこぼさないよう、もう一度確認する
+			timedOut = true
+		}
+	}
+}
+
+// len はキュー内のタスク数を返す
+func (q *taskQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.items)
+}
+
+// full はキューが満杯か判定
+func (q *taskQueue) full() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.items) >= q.capacity
+}
+
+// countAhead は指定した優先度のタスクを投入した場合に、先に取り出されるタスク数を返す
+func (q *taskQueue) countAhead(priority Priority) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ahead := 0
+	for _, item := range q.items {
+		if item.task.Priority >= priority {
+			ahead++
+		}
+	}
+	return ahead
+}
+
+// removeWhere は条件に一致するタスクをキューから取り除いて返す
+func (q *taskQueue) removeWhere(match func(Task) bool) []Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var removed []Task
+	kept := q.items[:0]
+	for _, item := range q.items {
+		if match(item.task) {
+			removed = append(removed, item.task)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	for i := len(kept); i < len(q.items); i++ {
+		q.items[i] = queueItem{}
+	}
+	q.items = kept
+	heap.Init(&q.items)
+
+	// 空きができたので投入待ちを起こす
+	close(q.notFull)
+	q.notFull = make(chan struct{})
+	return removed
+}
+
+// updateWhere は条件に一致するタスクを update で書き換え、書き換えた件数を返す
+// 優先度が変わっても順序が正しくなるようヒープを組み直す（同じ優先度内の投入順は保つ）
+func (q *taskQueue) updateWhere(match func(Task) bool, update func(*Task)) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	updated := 0
+	for i := range q.items {
+		if match(q.items[i].task) {
+			update(&q.items[i].task)
+			updated++
+		}
+	}
+	if updated > 0 {
+		heap.Init(&q.items)
+	}
+	return updated
+}
+
+// reopen はクローズしたキューを再び投入できるようにする（プールの再開用）
+func (q *taskQueue) reopen() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = false
+}
+
+// close はキューをクローズする。残っているタスクは引き続き取り出せる
+func (q *taskQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.notEmpty)
+	q.notEmpty = make(chan struct{})
+	close(q.notFull)
+	q.notFull = make(chan struct{})
+}
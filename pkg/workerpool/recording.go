@@ -0,0 +1,234 @@
+package workerpool
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// RecordedTask は記録した投入1件（1行1JSON）
+type RecordedTask struct {
+	Offset     time.Duration `json:"offset_ns"`                // 記録開始からの経過時間
+	DeadlineIn time.Duration `json:"deadline_in_ns,omitempty"` // 投入時点から期限までの時間（期限なしは0）
+	Task       Task          `json:"task"`
+}
+
+// Recorder は投入されたタスクの流れを時刻付きで記録する
+type Recorder struct {
+	mu      sync.Mutex
+	w       *bufio.Writer
+	closer  io.Closer
+	started time.Time
+	count   int
+	err     error // 最初の書き込みエラー（以降の記録は行わない）
+}
+
+// NewRecorder は w に記録するレコーダーを作成する
+func NewRecorder(w io.Writer) *Recorder {
+	r := &Recorder{w: bufio.NewWriter(w)}
+	if closer, ok := w.(io.Closer); ok {
+		r.closer = closer
+	}
+	return r
+}
+
+// CreateRecorder はファイルを作成して記録するレコーダーを作成する
+func CreateRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("記録ファイル %s を作成できません: %w", path, err)
+	}
+	return NewRecorder(file), nil
+}
+
+// Record はタスクを1件記録する（最初の記録の時刻を起点とする）
+func (r *Recorder) Record(task Task) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+	if r.count == 0 {
+		r.started = now
+	}
+
+	record := RecordedTask{Offset: now.Sub(r.started), Task: task}
+	if !task.Deadline.IsZero() {
+		record.DeadlineIn = task.Deadline.Sub(now)
+	}
+	line, err := json.Marshal(record)
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	if err != nil {
+		r.err = err
+		event("recording.failed").taskOf(task).failed(err).logf("⚠️ タスク %d を記録できません。以降の記録を停止します: %v\n", task.ID, err)
+		return
+	}
+	r.count++
+}
+
+// Count は記録したタスク数を返す
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.count
+}
+
+// Close はバッファを書き出して記録を終了する
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.w.Flush()
+	if r.closer != nil {
+		if closeErr := r.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err == nil {
+		err = r.err
+	}
+	return err
+}
+
+// SetRecorder は投入されたタスクを記録するレコーダーを設定する（nil で無効化）
+// 受付の判定より前に記録するため、拒否されたタスクも含まれる
+func (wp *WorkerPool) SetRecorder(recorder *Recorder) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.recorder = recorder
+}
+
+// SetRecorder はすべてのサブプールへの投入を記録するレコーダーを設定する（nil で無効化）
+func (tp *TypedPool) SetRecorder(recorder *Recorder) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	tp.recorder = recorder
+}
+
+// record は設定されたレコーダーにタスクを記録する
+func (wp *WorkerPool) record(task Task) {
+	wp.mu.Lock()
+	recorder := wp.recorder
+	wp.mu.Unlock()
+
+	if recorder != nil {
+		recorder.Record(task)
+	}
+}
+
+// LoadRecording は記録ファイルを読み込む
+func LoadRecording(path string) ([]RecordedTask, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("記録ファイル %s を開けません: %w", path, err)
+	}
+	defer file.Close()
+
+	return ReadRecording(file)
+}
+
+// ReadRecording は記録を読み込む
+func ReadRecording(r io.Reader) ([]RecordedTask, error) {
+	var records []RecordedTask
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record RecordedTask
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("記録の %d 行目が不正です: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("記録を読み込めません: %w", err)
+	}
+	return records, nil
+}
+
+// ReplayOptions は再生の設定
+type ReplayOptions struct {
+	Speed   float64 // 再生速度の倍率（0以下は等倍、2 で2倍速）
+	NoDelay bool    // 間隔を空けずにすべて投入する
+}
+
+// ReplayStats は再生の結果
+type ReplayStats struct {
+	Submitted int           `json:"submitted"` // 受け付けられたタスク数
+	Rejected  int           `json:"rejected"`  // AddTask がエラーを返したタスク数
+	Duration  time.Duration `json:"duration"`  // 再生にかかった時間
+	MaxLag    time.Duration `json:"max_lag"`   // 予定時刻からの最大の遅れ
+}
+
+// Replay は記録したタスクを元の間隔（または倍速）で pool に再投入する
+// ctx がキャンセルされると残りを投入せずに終了する
+func Replay(ctx context.Context, pool Pool, records []RecordedTask, opts ReplayOptions) (stats ReplayStats, err error) {
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+
+	started := time.Now()
+	defer func() { stats.Duration = time.Since(started) }()
+
+	for _, record := range records {
+		if !opts.NoDelay {
+			due := started.Add(time.Duration(float64(record.Offset) / speed))
+			if wait := time.Until(due); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return stats, ctx.Err()
+				}
+			} else if lag := -wait; lag > stats.MaxLag {
+				stats.MaxLag = lag
+			}
+		} else if ctx.Err() != nil {
+			return stats, ctx.Err()
+		}
+
+		task := record.Task
+		task.CreatedAt = time.Now()
+		task.AttemptCount = 0
+		task.FirstAttempt = time.Time{}
+		task.Deadline = time.Time{}
+		if record.DeadlineIn != 0 {
+			task.Deadline = task.CreatedAt.Add(record.DeadlineIn)
+		}
+
+		if err := pool.AddTask(task); err != nil {
+			stats.Rejected++
+			continue
+		}
+		stats.Submitted++
+	}
+	return stats, nil
+}
+
+// record は設定されたレコーダーにタスクを記録する
+func (tp *TypedPool) record(task Task) {
+	tp.mu.Lock()
+	recorder := tp.recorder
+	tp.mu.Unlock()
+
+	if recorder != nil {
+		recorder.Record(task)
+	}
+}
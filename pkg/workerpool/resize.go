@@ -0,0 +1,96 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrInvalidWorkerCount はワーカー数に1未満を指定した場合のエラー
+var ErrInvalidWorkerCount = errors.New("ワーカー数は1以上を指定してください")
+
+// Resize は実行中のプールのワーカー数（最大数）を変更する
+// 増やす場合はすぐにワーカーを追加起動し（遅延起動の場合は常駐数までのみ）、
+// 減らす場合は超過分のワーカーが処理中のタスクを終えてから終了する。停止中に呼び出した場合は次の Start から反映する
+func (wp *WorkerPool) Resize(n int) error {
+	if n < 1 {
+		return fmt.Errorf("%w: %d", ErrInvalidWorkerCount, n)
+	}
+
+	wp.mu.Lock()
+	previous := wp.workers
+	wp.workers = n
+	// 遅延起動でない（常駐数が最大数と同じ）場合は常駐数も合わせる
+	if wp.minWorkers == previous || wp.minWorkers > n {
+		wp.minWorkers = n
+	}
+	state := wp.lifecycle.current()
+	if state == StateRunning || state == StatePaused {
+		for wp.running < wp.minWorkers {
+			wp.spawnWorkerLocked()
+		}
+	}
+	if n < previous {
+		close(wp.resized)
+		wp.resized = make(chan struct{})
+	}
+	wp.mu.Unlock()
+
+	if n != previous {
+		event("pool.resized").logf("📐 ワーカー数を %d から %d に変更しました\n", previous, n)
+	}
+	return nil
+}
+
+// MaxWorkers は現在のワーカー数の上限を返す
+func (wp *WorkerPool) MaxWorkers() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return wp.workers
+}
+
+// resizablePool はワーカー数を変更できるプール（POST /workers で使う）
+type resizablePool interface {
+	Resize(n int) error
+	MaxWorkers() int
+	RunningWorkers() int
+}
+
+// handleWorkers はワーカー数を返す（GET /workers）・変更する（POST /workers {"workers": n}）
+func (m *Monitor) handleWorkers(w http.ResponseWriter, r *http.Request) {
+	// AuditedPool を重ねている場合は、変更を監査ログに記録するためデコレーターを通す
+	pool, ok := m.poolFor(r).(resizablePool)
+	if !ok {
+		pool, ok = UnwrapPool(m.pool).(resizablePool)
+	}
+	if !ok {
+		writeJSONError(w, http.StatusNotImplemented, "このプールはワーカー数を変更できません")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			Workers int `json:"workers"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "リクエストの形式が不正です: "+err.Error())
+			return
+		}
+		if err := pool.Resize(req.Workers); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET・POST のみ対応しています")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{
+		"workers": pool.MaxWorkers(),
+		"running": pool.RunningWorkers(),
+	})
+}
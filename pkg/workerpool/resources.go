@@ -0,0 +1,165 @@
+package workerpool
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// cgroupRoot は cgroup v2 のマウント先
+	cgroupRoot = "/sys/fs/cgroup"
+	// shedHeapRatio はメモリの上限のうち、負荷制御の閾値にするヒープ使用量の割合
+	shedHeapRatio = 0.8
+)
+
+// ContainerLimits はプロセスが使えるCPUとメモリ
+// コンテナ（Kubernetes など）ではホストの NumCPU ではなく cgroup の上限を使い、ワーカーの過剰な起動を防ぐ
+type ContainerLimits struct {
+	CPUs        float64 `json:"cpus"`                   // 使えるCPU（CPUクォータ・cpuset・ホストのCPU数のうち最小）
+	MemoryBytes uint64  `json:"memory_bytes,omitempty"` // メモリの上限（0 の場合は無制限・不明）
+	HostCPUs    int     `json:"host_cpus"`              // ホストのCPU数
+	Source      string  `json:"source"`                 // 上限の取得元（cgroup v2 / host）
+}
+
+// DetectContainerLimits は cgroup v2 の上限（cpu.max, cpuset.cpus.effective, memory.max）を読み取る
+// プロセスの cgroup から親へたどり、最も厳しい上限を使う。cgroup v2 でない場合はホストのCPU数を返す
+func DetectContainerLimits() ContainerLimits {
+	return detectContainerLimits(cgroupRoot, "/proc/self/cgroup")
+}
+
+// detectContainerLimits は root にマウントされた cgroup v2 から上限を読み取る
+func detectContainerLimits(root, selfCgroup string) ContainerLimits {
+	limits := ContainerLimits{
+		CPUs:     float64(runtime.NumCPU()),
+		HostCPUs: runtime.NumCPU(),
+		Source:   "host",
+	}
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+		return limits // cgroup v2 ではない
+	}
+	path, err := cgroupV2Path(selfCgroup)
+	if err != nil {
+		return limits
+	}
+
+	found := false
+	for dir := filepath.Join(root, path); ; dir = filepath.Dir(dir) {
+		if cpus, ok := readCPUMax(filepath.Join(dir, "cpu.max")); ok && cpus < limits.CPUs {
+			limits.CPUs, found = cpus, true
+		}
+		if cpus, ok := readCPUSet(filepath.Join(dir, "cpuset.cpus.effective")); ok && cpus < limits.CPUs {
+			limits.CPUs, found = cpus, true
+		}
+		if memory, ok := readMemoryMax(filepath.Join(dir, "memory.max")); ok && (limits.MemoryBytes == 0 || memory < limits.MemoryBytes) {
+			limits.MemoryBytes, found = memory, true
+		}
+		if len(dir) <= len(root) {
+			break
+		}
+	}
+	if found {
+		limits.Source = "cgroup v2"
+	}
+	return limits
+}
+
+// cgroupV2Path は /proc/self/cgroup から cgroup v2 のパス（"0::" の行）を返す
+func cgroupV2Path(selfCgroup string) (string, error) {
+	file, err := os.Open(selfCgroup)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return filepath.Clean("/" + path), nil
+		}
+	}
+	return "", fmt.Errorf("%s に cgroup v2 のパスがありません", selfCgroup)
+}
+
+// readCPUMax は cpu.max（"クォータ 期間" または "max 期間"）からCPU数に換算した上限を返す
+func readCPUMax(path string) (float64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 || fields[0] == "max" {
+		return 0, false
+	}
+	quota, err1 := strconv.ParseFloat(fields[0], 64)
+	period, err2 := strconv.ParseFloat(fields[1], 64)
+	if err1 != nil || err2 != nil || quota <= 0 || period <= 0 {
+		return 0, false
+	}
+	return quota / period, true
+}
+
+// readCPUSet は cpuset.cpus.effective からCPUの数を返す
+func readCPUSet(path string) (float64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	cpus, err := parseCPUList(string(data))
+	if err != nil || len(cpus) == 0 {
+		return 0, false
+	}
+	return float64(len(cpus)), true
+}
+
+// readMemoryMax は memory.max（バイト数または "max"）からメモリの上限を返す
+func readMemoryMax(path string) (uint64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "max" {
+		return 0, false
+	}
+	memory, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || memory == 0 {
+		return 0, false
+	}
+	return memory, true
+}
+
+// Workers はCPUの上限に合わせた既定のワーカー数を返す（端数は切り上げ、最低1）
+func (l ContainerLimits) Workers() int {
+	return max(1, int(math.Ceil(l.CPUs)))
+}
+
+// ShedPolicy はメモリの上限の8割をヒープ使用量の閾値にした負荷制御ポリシーを返す（上限が不明な場合は nil）
+func (l ContainerLimits) ShedPolicy(action ShedAction) *ShedPolicy {
+	if l.MemoryBytes == 0 {
+		return nil
+	}
+	return &ShedPolicy{
+		MaxHeapBytes: uint64(float64(l.MemoryBytes) * shedHeapRatio),
+		Action:       action,
+	}
+}
+
+// String は上限を表示用の文字列にする
+func (l ContainerLimits) String() string {
+	memory := "無制限"
+	if l.MemoryBytes > 0 {
+		memory = fmt.Sprintf("%.0fMiB", float64(l.MemoryBytes)/(1<<20))
+	}
+	return fmt.Sprintf("CPU %.2g（ホスト %d）, メモリ %s [%s]", l.CPUs, l.HostCPUs, memory, l.Source)
+}
+
+// DefaultWorkers はコンテナのCPUの上限に合わせた既定のワーカー数を返す
+func DefaultWorkers() int {
+	return DetectContainerLimits().Workers()
+}
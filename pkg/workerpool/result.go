@@ -1,20 +1,189 @@
 package workerpool
 
-import "time"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
 
 type TaskResult struct {
 	TaskID        int
 	TaskName      string
 	TaskType      TaskType
+	Labels        map[string]string // タスクのラベル
+	Output        string            // プロセッサが TaskOutput に書き込んだ出力（ログなど）
+	Variant       string            // カナリア設定中のタイプで実行したプロセッサ（VariantStable / VariantCanary）
 	Success       bool
+	Canceled      bool // CancelTask などの管理操作で取り消された（Success は false）
 	Error         error
 	Duration      time.Duration
 	TotalDuration time.Duration // リトライ含む総処理時間
 	WorkerID      int
 	StartTime     time.Time
 	EndTime       time.Time
-	AttemptCount  int  // 試行回数
-	IsFinal       bool // 最終結果かどうか
+	AttemptCount  int            // 試行回数
+	IsFinal       bool           // 最終結果かどうか
+	CreatedAt     time.Time      // タスクの作成日時
+	Age           time.Duration  // タスクの作成からこの結果までの時間（キュー待ち・リトライ待ちを含む）
+	Budget        *LatencyBudget // 期限・タイムアウトと、キュー待ち・試行・リトライ待ちの時間の内訳
+
+	retryable bool // リトライポリシー上リトライ対象のエラーかどうか（JSON出力用）
+}
+
+// エラーコード（JSON出力時の分類）
+const (
+	ErrorCodeTimeout          = "TIMEOUT"           // タスクのタイムアウト
+	ErrorCodeCanceled         = "CANCELED"          // 呼び出し元によるキャンセル・管理操作による取り消し
+	ErrorCodeDeadlineExceeded = "DEADLINE_EXCEEDED" // 呼び出し元の期限切れ
+	ErrorCodeCorrupt          = "CORRUPT"           // タスクの内容の破損
+	ErrorCodeUntrusted        = "UNTRUSTED"         // 信頼する発行元の署名がない
+	ErrorCodeInvalidPayload   = "INVALID_PAYLOAD"   // ペイロードの型の不一致・検証エラー
+	ErrorCodeFailed           = "FAILED"            // その他の処理エラー
+)
+
+// ResultError はJSONから復元したタスクのエラー
+type ResultError struct {
+	Message   string `json:"message"`
+	Code      string `json:"code"`
+	Retryable bool   `json:"retryable"`
+}
+
+func (e *ResultError) Error() string {
+	return e.Message
+}
+
+// taskResultJSON は TaskResult のJSON表現
+type taskResultJSON struct {
+	TaskID        int               `json:"task_id"`
+	TaskName      string            `json:"task_name"`
+	TaskType      TaskType          `json:"task_type"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Output        string            `json:"output,omitempty"`
+	Variant       string            `json:"variant,omitempty"`
+	Success       bool              `json:"success"`
+	Canceled      bool              `json:"canceled,omitempty"`
+	Error         *ResultError      `json:"error,omitempty"`
+	Duration      time.Duration     `json:"duration_ns"`
+	TotalDuration time.Duration     `json:"total_duration_ns"`
+	WorkerID      int               `json:"worker_id"`
+	StartTime     time.Time         `json:"start_time"`
+	EndTime       time.Time         `json:"end_time"`
+	AttemptCount  int               `json:"attempt_count"`
+	IsFinal       bool              `json:"is_final"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Age           time.Duration     `json:"age_ns"`
+	Budget        *LatencyBudget    `json:"budget,omitempty"`
+}
+
+// MarshalJSON はエラーをメッセージ・コード・リトライ可否に分けて出力する
+func (tr TaskResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(taskResultJSON{
+		TaskID:        tr.TaskID,
+		TaskName:      tr.TaskName,
+		TaskType:      tr.TaskType,
+		Labels:        tr.Labels,
+		Output:        tr.Output,
+		Variant:       tr.Variant,
+		Success:       tr.Success,
+		Canceled:      tr.Canceled,
+		Error:         tr.resultError(),
+		Duration:      tr.Duration,
+		TotalDuration: tr.TotalDuration,
+		WorkerID:      tr.WorkerID,
+		StartTime:     tr.StartTime,
+		EndTime:       tr.EndTime,
+		AttemptCount:  tr.AttemptCount,
+		IsFinal:       tr.IsFinal,
+		CreatedAt:     tr.CreatedAt,
+		Age:           tr.Age,
+		Budget:        tr.Budget,
+	})
+}
+
+// UnmarshalJSON はエラーを *ResultError として復元する
+func (tr *TaskResult) UnmarshalJSON(data []byte) error {
+	var v taskResultJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*tr = TaskResult{
+		TaskID:        v.TaskID,
+		TaskName:      v.TaskName,
+		TaskType:      v.TaskType,
+		Labels:        v.Labels,
+		Output:        v.Output,
+		Variant:       v.Variant,
+		Success:       v.Success,
+		Canceled:      v.Canceled,
+		Duration:      v.Duration,
+		TotalDuration: v.TotalDuration,
+		WorkerID:      v.WorkerID,
+		StartTime:     v.StartTime,
+		EndTime:       v.EndTime,
+		AttemptCount:  v.AttemptCount,
+		IsFinal:       v.IsFinal,
+		CreatedAt:     v.CreatedAt,
+		Age:           v.Age,
+		Budget:        v.Budget,
+	}
+	if v.Error != nil {
+		tr.Error = v.Error
+		tr.retryable = v.Error.Retryable
+	}
+	return nil
+}
+
+// resultError はエラーをJSON出力用の形式に変換する
+func (tr *TaskResult) resultError() *ResultError {
+	if tr.Error == nil {
+		return nil
+	}
+
+	var resultErr *ResultError
+	if errors.As(tr.Error, &resultErr) {
+		return resultErr
+	}
+	return &ResultError{
+		Message:   tr.Error.Error(),
+		Code:      tr.ErrorCode(),
+		Retryable: tr.retryable,
+	}
+}
+
+// ErrorCode はエラーの分類コードを返す（エラーがない場合は空文字）
+func (tr *TaskResult) ErrorCode() string {
+	var resultErr *ResultError
+	switch {
+	case tr.Error == nil:
+		return ""
+	case errors.As(tr.Error, &resultErr):
+		return resultErr.Code
+	case tr.IsTimeout():
+		return ErrorCodeTimeout
+	case errors.Is(tr.Error, context.Canceled), isCanceled(tr.Error):
+		return ErrorCodeCanceled
+	case errors.Is(tr.Error, ErrDeadlineExceeded):
+		return ErrorCodeDeadlineExceeded
+	case errors.Is(tr.Error, ErrTaskCorrupt):
+		return ErrorCodeCorrupt
+	case errors.Is(tr.Error, ErrUntrustedTask):
+		return ErrorCodeUntrusted
+	case errors.Is(tr.Error, ErrPayloadType), errors.Is(tr.Error, ErrInvalidPayload):
+		return ErrorCodeInvalidPayload
+	default:
+		return ErrorCodeFailed
+	}
+}
+
+// IsRetryable はエラーがリトライポリシー上リトライ対象だったかどうかを返す
+func (tr *TaskResult) IsRetryable() bool {
+	var resultErr *ResultError
+	if errors.As(tr.Error, &resultErr) {
+		return resultErr.Retryable
+	}
+	return tr.retryable
 }
 
 func (tr *TaskResult) IsTimeout() bool {
@@ -22,7 +191,11 @@ func (tr *TaskResult) IsTimeout() bool {
 		return false
 	}
 
-	return tr.Error.Error() == "context deadline exceeded"
+	var resultErr *ResultError
+	if errors.As(tr.Error, &resultErr) {
+		return resultErr.Code == ErrorCodeTimeout
+	}
+	return errors.Is(tr.Error, context.DeadlineExceeded)
 }
 
 func (tr *TaskResult) GetErrorType() string {
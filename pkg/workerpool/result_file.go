@@ -0,0 +1,335 @@
+package workerpool
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidFileSink はファイル出力の設定が不正な場合のエラー
+var ErrInvalidFileSink = errors.New("結果ファイルの設定が不正です")
+
+// 結果ファイルの形式
+const (
+	ResultFileCSV     = "csv"
+	ResultFileParquet = "parquet"
+)
+
+// resultLabelPrefix はラベルの値を出力する項目名の接頭辞（label.region など）
+const resultLabelPrefix = "label."
+
+// resultInstancePrefix はインスタンス情報を出力する項目名の接頭辞（instance.service など、SetInstanceInfo の id・service・region・version とラベル）
+const resultInstancePrefix = "instance."
+
+// DefaultResultFields は項目を指定しない場合に出力する項目
+var DefaultResultFields = []string{
+	"task_id", "task_name", "task_type", "success", "error_code", "error",
+	"duration_ms", "total_duration_ms", "attempt_count", "worker_id", "start_time", "end_time", "age_ms",
+}
+
+// fieldKind は結果の項目の値の種類（Parquet の列の型に対応）
+type fieldKind int
+
+const (
+	fieldInt fieldKind = iota
+	fieldFloat
+	fieldBool
+	fieldString
+	fieldTime
+)
+
+// resultField は結果ファイルに出力する項目
+type resultField struct {
+	name  string
+	kind  fieldKind
+	value func(TaskResult) interface{}
+}
+
+// resultFieldValues は項目名ごとの値の取り出し方
+var resultFieldValues = map[string]resultField{
+	"task_id":           {kind: fieldInt, value: func(r TaskResult) interface{} { return int64(r.TaskID) }},
+	"task_name":         {kind: fieldString, value: func(r TaskResult) interface{} { return r.TaskName }},
+	"task_type":         {kind: fieldString, value: func(r TaskResult) interface{} { return string(r.TaskType) }},
+	"variant":           {kind: fieldString, value: func(r TaskResult) interface{} { return r.Variant }},
+	"success":           {kind: fieldBool, value: func(r TaskResult) interface{} { return r.Success }},
+	"error_code":        {kind: fieldString, value: func(r TaskResult) interface{} { return r.ErrorCode() }},
+	"error":             {kind: fieldString, value: func(r TaskResult) interface{} { return errorMessage(r.Error) }},
+	"retryable":         {kind: fieldBool, value: func(r TaskResult) interface{} { return r.IsRetryable() }},
+	"duration_ms":       {kind: fieldFloat, value: func(r TaskResult) interface{} { return durationToMs(r.Duration) }},
+	"total_duration_ms": {kind: fieldFloat, value: func(r TaskResult) interface{} { return durationToMs(r.TotalDuration) }},
+	"age_ms":            {kind: fieldFloat, value: func(r TaskResult) interface{} { return durationToMs(r.Age) }},
+	"attempt_count":     {kind: fieldInt, value: func(r TaskResult) interface{} { return int64(r.AttemptCount) }},
+	"worker_id":         {kind: fieldInt, value: func(r TaskResult) interface{} { return int64(r.WorkerID) }},
+	"start_time":        {kind: fieldTime, value: func(r TaskResult) interface{} { return r.StartTime }},
+	"end_time":          {kind: fieldTime, value: func(r TaskResult) interface{} { return r.EndTime }},
+	"created_at":        {kind: fieldTime, value: func(r TaskResult) interface{} { return r.CreatedAt }},
+	"output":            {kind: fieldString, value: func(r TaskResult) interface{} { return r.Output }},
+}
+
+// errorMessage はエラーのメッセージを返す（nil の場合は空文字）
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// compileResultFields は項目名を解釈する（label.<キー> でラベルの値、instance.<キー> でインスタンス情報を出力する）
+func compileResultFields(names []string) ([]resultField, error) {
+	if len(names) == 0 {
+		names = DefaultResultFields
+	}
+
+	fields := make([]resultField, 0, len(names))
+	for _, name := range names {
+		if key, ok := strings.CutPrefix(name, resultLabelPrefix); ok && key != "" {
+			fields = append(fields, resultField{name: name, kind: fieldString, value: func(r TaskResult) interface{} {
+				return r.Labels[key]
+			}})
+			continue
+		}
+		if key, ok := strings.CutPrefix(name, resultInstancePrefix); ok && key != "" {
+			fields = append(fields, resultField{name: name, kind: fieldString, value: func(TaskResult) interface{} {
+				return instanceLabels()[key]
+			}})
+			continue
+		}
+		field, exists := resultFieldValues[name]
+		if !exists {
+			return nil, fmt.Errorf("%w: 不明な項目 %q", ErrInvalidFileSink, name)
+		}
+		field.name = name
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// FileSinkConfig は結果ファイルの出力設定
+type FileSinkConfig struct {
+	Dir         string        `json:"dir"`             // 出力先のディレクトリ
+	Prefix      string        `json:"prefix"`          // ファイル名の接頭辞（空の場合は results）
+	Format      string        `json:"format"`          // csv / parquet（空の場合は csv）
+	Fields      []string      `json:"fields"`          // 出力する項目（空の場合は DefaultResultFields）
+	MaxBytes    int64         `json:"max_bytes"`       // この大きさを超えたら次のファイルに切り替える（0 で無制限）
+	RotateEvery time.Duration `json:"rotate_every_ns"` // この時間が経ったら次のファイルに切り替える（0 で無制限）
+}
+
+// resultFileWriter は1ファイル分の書き込み
+type resultFileWriter interface {
+	write(row []interface{}) error
+	size() int64 // 書き込んだ（Parquet は書き出していない行を含む）おおよそのバイト数
+	close() error
+}
+
+// FileSink は最終結果をCSV・Parquet ファイルに書き出す（ResultSink）
+// ファイルは大きさ・経過時間で切り替わり、切り替えたファイルから順に分析基盤で読み込める
+// Parquet は列ごとにまとめて書くため、行グループの大きさ（約4MB）に達するまで結果をメモリに保持する
+type FileSink struct {
+	config FileSinkConfig
+	fields []resultField
+
+	mu     sync.Mutex
+	writer resultFileWriter // 書き込み中のファイル（最初の結果で作成する）
+	path   string
+	opened time.Time
+	seq    int
+	files  []string // 書き終えたファイル
+	closed bool
+}
+
+// NewFileSink は結果ファイルの出力先を作成する
+func NewFileSink(config FileSinkConfig) (*FileSink, error) {
+	switch config.Format {
+	case "":
+		config.Format = ResultFileCSV
+	case ResultFileCSV, ResultFileParquet:
+	default:
+		return nil, fmt.Errorf("%w: 不明な形式 %q (csv / parquet)", ErrInvalidFileSink, config.Format)
+	}
+	if config.Prefix == "" {
+		config.Prefix = "results"
+	}
+	fields, err := compileResultFields(config.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if config.Dir != "" {
+		if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("結果ファイルの出力先を作成できません: %w", err)
+		}
+	}
+	return &FileSink{config: config, fields: fields}, nil
+}
+
+// WriteResult は結果を1行書き込む
+func (s *FileSink) WriteResult(result TaskResult) error {
+	row := make([]interface{}, len(s.fields))
+	for i, field := range s.fields {
+		row[i] = field.value(result)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("結果ファイル: %w", os.ErrClosed)
+	}
+	if s.writer != nil && s.config.RotateEvery > 0 && time.Since(s.opened) >= s.config.RotateEvery {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	if s.writer == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	if err := s.writer.write(row); err != nil {
+		return fmt.Errorf("結果ファイル %s に書き込めません: %w", s.path, err)
+	}
+	if s.config.MaxBytes > 0 && s.writer.size() >= s.config.MaxBytes {
+		return s.rotate()
+	}
+	return nil
+}
+
+// Files は書き終えたファイルの一覧を返す（書き込み中のファイルは含まない）
+func (s *FileSink) Files() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.files...)
+}
+
+// Close は書き込み中のファイルを書き終えて出力を終了する
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.writer == nil {
+		return nil
+	}
+	return s.rotate()
+}
+
+// open は次のファイルを作成する（s.mu を保持して呼び出すこと）
+func (s *FileSink) open() error {
+	s.seq++
+	s.opened = time.Now()
+	name := fmt.Sprintf("%s-%s-%04d.%s", s.config.Prefix, s.opened.Format("20060102-150405"), s.seq, s.config.Format)
+	s.path = filepath.Join(s.config.Dir, name)
+
+	file, err := os.Create(s.path)
+	if err != nil {
+		return fmt.Errorf("結果ファイル %s を作成できません: %w", s.path, err)
+	}
+	if s.config.Format == ResultFileParquet {
+		s.writer = newParquetFileWriter(file, s.fields)
+		return nil
+	}
+	s.writer, err = newCSVFileWriter(file, s.fields)
+	return err
+}
+
+// rotate は書き込み中のファイルを書き終える。次のファイルは次の結果で作成する（s.mu を保持して呼び出すこと）
+func (s *FileSink) rotate() error {
+	writer, path := s.writer, s.path
+	s.writer = nil
+	if err := writer.close(); err != nil {
+		return fmt.Errorf("結果ファイル %s を書き終えられません: %w", path, err)
+	}
+	s.files = append(s.files, path)
+	event("sink.file_rotated").logf("🗂️ 結果ファイル %s を書き終えました\n", path)
+	return nil
+}
+
+// csvFileWriter は見出し行付きのCSVファイルに書き込む
+type csvFileWriter struct {
+	file    *os.File
+	counter *countingWriter
+	csv     *csv.Writer
+	kinds   []fieldKind
+}
+
+func newCSVFileWriter(file *os.File, fields []resultField) (*csvFileWriter, error) {
+	counter := &countingWriter{w: file}
+	w := &csvFileWriter{file: file, counter: counter, csv: csv.NewWriter(counter)}
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = field.name
+		w.kinds = append(w.kinds, field.kind)
+	}
+	if err := w.csv.Write(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *csvFileWriter) write(row []interface{}) error {
+	record := make([]string, len(row))
+	for i, value := range row {
+		record[i] = formatResultValue(w.kinds[i], value)
+	}
+	if err := w.csv.Write(record); err != nil {
+		return err
+	}
+	// 大きさで切り替えられるよう、行ごとにファイルへ書き出す
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *csvFileWriter) size() int64 {
+	return w.counter.n
+}
+
+func (w *csvFileWriter) close() error {
+	w.csv.Flush()
+	err := w.csv.Error()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// formatResultValue はCSVに書き込む値を文字列にする（日時はRFC3339、ゼロ値は空欄）
+func formatResultValue(kind fieldKind, value interface{}) string {
+	switch kind {
+	case fieldInt:
+		return strconv.FormatInt(value.(int64), 10)
+	case fieldFloat:
+		return strconv.FormatFloat(value.(float64), 'f', -1, 64)
+	case fieldBool:
+		return strconv.FormatBool(value.(bool))
+	case fieldTime:
+		if t := value.(time.Time); !t.IsZero() {
+			return t.Format(time.RFC3339Nano)
+		}
+		return ""
+	default:
+		return value.(string)
+	}
+}
+
+// countingWriter は書き込んだバイト数を数える
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
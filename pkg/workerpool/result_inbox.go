@@ -0,0 +1,206 @@
+package workerpool
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrResultTimeout は指定時間内に条件に合う結果が届かなかった場合のエラー
+var ErrResultTimeout = errors.New("結果の待機がタイムアウトしました")
+
+// unclaimedResultLimit は GetResult・Results が使われる前に退避しておく結果の上限（超えた分は古いものから捨てる）
+const unclaimedResultLimit = 1000
+
+// resultInbox は結果チャネルの受信側
+// 条件に合わない結果は退避しておき、後続の取得で返す
+type resultInbox struct {
+	ch     <-chan TaskResult
+	mu     sync.Mutex
+	stash  []TaskResult  // 条件に合わず退避した結果（到着順）
+	notify chan struct{} // 退避が増えたら閉じて差し替える
+
+	claimed atomic.Bool // GetResult・Results などで結果を取り出す呼び出し元がいる
+}
+
+func newResultInbox(ch <-chan TaskResult) *resultInbox {
+	return &resultInbox{ch: ch, notify: make(chan struct{})}
+}
+
+// next は条件に合う結果を1件返す。timeout が閉じるかチャネルが閉じられた場合は false を返す
+func (in *resultInbox) next(match func(TaskResult) bool, timeout <-chan time.Time) (TaskResult, bool) {
+	in.claim()
+	for {
+		in.mu.Lock()
+		for i, result := range in.stash {
+			if match(result) {
+				in.stash = append(in.stash[:i], in.stash[i+1:]...)
+				in.mu.Unlock()
+				return result, true
+			}
+		}
+		notify := in.notify
+		in.mu.Unlock()
+
+		select {
+		case result, ok := <-in.ch:
+			if !ok {
+				return TaskResult{}, false
+			}
+			if match(result) {
+				return result, true
+			}
+			in.put(result)
+		case <-notify:
+			// 他の呼び出し元が退避した結果を確認し直す
+		case <-timeout:
+			return TaskResult{}, false
+		}
+	}
+}
+
+// available は待たずに取得できる結果のうち条件に合うものをすべて返す
+func (in *resultInbox) available(match func(TaskResult) bool) []TaskResult {
+	in.claim()
+	for {
+		select {
+		case result, ok := <-in.ch:
+			if !ok {
+				return in.take(match)
+			}
+			in.put(result)
+		default:
+			return in.take(match)
+		}
+	}
+}
+
+// carryOver は新しい結果チャネルの受信側を作成し、未取得の結果を引き継ぐ（プールの再開用）
+func (in *resultInbox) carryOver(ch <-chan TaskResult) *resultInbox {
+	next := newResultInbox(ch)
+	next.claimed.Store(in.claimed.Load())
+	next.stash = in.available(matchAll)
+	return next
+}
+
+// claim は結果を取り出す呼び出し元がいることを記録する（以降、結果チャネルへの送信は取り出されるまで待つ）
+func (in *resultInbox) claim() {
+	in.claimed.Store(true)
+}
+
+// deliver は結果を結果チャネル ch（この受信側のチャネル）に送る
+// 結果を取り出す呼び出し元がまだおらず、OnResult の購読者（observed）だけが受け取っている間は、
+// チャネルが一杯でもワーカーを止めないよう受信側に退避する（unclaimedResultLimit 件まで）
+func (in *resultInbox) deliver(ch chan<- TaskResult, result TaskResult, observed bool) {
+	if !observed || in.claimed.Load() {
+		ch <- result
+		return
+	}
+	select {
+	case ch <- result:
+	default:
+		in.keep(result)
+	}
+}
+
+// keep は取り出されていない結果を上限まで退避する（上限を超えた場合は古いものから捨てる）
+func (in *resultInbox) keep(result TaskResult) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if len(in.stash) >= unclaimedResultLimit {
+		in.stash = append(in.stash[:0], in.stash[len(in.stash)-unclaimedResultLimit+1:]...)
+	}
+	in.stash = append(in.stash, result)
+	close(in.notify)
+	in.notify = make(chan struct{})
+}
+
+// put は結果を退避する
+func (in *resultInbox) put(result TaskResult) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.stash = append(in.stash, result)
+	close(in.notify)
+	in.notify = make(chan struct{})
+}
+
+// take は退避中の結果から条件に合うものを取り出す
+func (in *resultInbox) take(match func(TaskResult) bool) []TaskResult {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	matched := make([]TaskResult, 0)
+	rest := in.stash[:0]
+	for _, result := range in.stash {
+		if match(result) {
+			matched = append(matched, result)
+		} else {
+			rest = append(rest, result)
+		}
+	}
+	in.stash = rest
+	return matched
+}
+
+// where は timeout の間に届いた条件に合う結果をすべて集め、タスクID順に返す
+// timeout が0以下の場合は待たずに取得できるものだけを返す
+func (in *resultInbox) where(match func(TaskResult) bool, timeout time.Duration) []TaskResult {
+	var results []TaskResult
+	if timeout <= 0 {
+		results = in.available(match)
+	} else {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		results = make([]TaskResult, 0)
+		for {
+			result, ok := in.next(match, timer.C)
+			if !ok {
+				break
+			}
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].TaskID < results[j].TaskID })
+	return results
+}
+
+// byTaskID は指定したタスクの結果を timeout まで待って返す
+func (in *resultInbox) byTaskID(taskID int, timeout time.Duration) (TaskResult, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	result, ok := in.next(func(r TaskResult) bool { return r.TaskID == taskID }, timer.C)
+	if !ok {
+		return TaskResult{}, ErrResultTimeout
+	}
+	return result, nil
+}
+
+func matchAll(TaskResult) bool { return true }
+
+// GetResultsWhere は timeout の間に届いた結果のうち条件に合うものをタスクID順に返す
+// 条件に合わない結果は保持され、以降の GetResult などで取得できる
+func (wp *WorkerPool) GetResultsWhere(match func(TaskResult) bool, timeout time.Duration) []TaskResult {
+	return wp.resultInbox().where(match, timeout)
+}
+
+// GetResultByTaskID は指定したタスクの結果を timeout まで待って返す
+func (wp *WorkerPool) GetResultByTaskID(taskID int, timeout time.Duration) (TaskResult, error) {
+	return wp.resultInbox().byTaskID(taskID, timeout)
+}
+
+// GetResultsWhere は timeout の間に届いた結果のうち条件に合うものをタスクID順に返す
+func (tp *TypedPool) GetResultsWhere(match func(TaskResult) bool, timeout time.Duration) []TaskResult {
+	return tp.resultInbox().where(match, timeout)
+}
+
+// GetResultByTaskID は指定したタスクの結果を timeout まで待って返す
+func (tp *TypedPool) GetResultByTaskID(taskID int, timeout time.Duration) (TaskResult, error) {
+	return tp.resultInbox().byTaskID(taskID, timeout)
+}
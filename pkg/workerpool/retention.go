@@ -0,0 +1,179 @@
+package workerpool
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultCollectInterval は終了済みの記録を削除する既定の間隔
+const defaultCollectInterval = 10 * time.Minute
+
+// RetentionPolicy はストアに終了済みの記録を残す期間・件数
+// 失敗の記録は調査のため成功より長く残すといった指定ができる。0 の項目は制限しない
+type RetentionPolicy struct {
+	Completed   time.Duration `json:"completed_ns"` // 成功した記録を残す期間
+	Failed      time.Duration `json:"failed_ns"`    // 最終的に失敗した記録を残す期間
+	MaxFinished int           `json:"max_finished"` // 終了済みの記録の最大件数（超えた分は古い順に削除）
+	Interval    time.Duration `json:"interval_ns"`  // 削除する間隔（0 の場合は10分）
+}
+
+// CollectRun は1回の削除の結果
+type CollectRun struct {
+	At        time.Time `json:"at"`
+	Completed int       `json:"completed"` // 期間を過ぎて削除した成功の記録
+	Failed    int       `json:"failed"`    // 期間を過ぎて削除した失敗の記録
+	Overflow  int       `json:"overflow"`  // 件数の上限を超えて削除した記録
+	Remaining int       `json:"remaining"` // 残っている終了済みの記録
+	Error     string    `json:"error,omitempty"`
+}
+
+// StoreCollector は保持ポリシーに従って終了済みの記録をストアから定期的に削除する
+// 履歴を残す必要がある場合は、先に Archiver でアーカイブしてから削除されるよう期間を設定する
+type StoreCollector struct {
+	store  ArchivableStore
+	policy RetentionPolicy
+
+	mu      sync.Mutex
+	lastRun CollectRun
+
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	started bool
+}
+
+// NewStoreCollector は終了済みの記録の削除を作成する（store は ArchivableStore を実装している必要がある）
+func NewStoreCollector(store TaskStore, policy RetentionPolicy) (*StoreCollector, error) {
+	archivable, ok := store.(ArchivableStore)
+	if !ok {
+		return nil, ErrStoreNotArchivable
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = defaultCollectInterval
+	}
+	return &StoreCollector{store: archivable, policy: policy}, nil
+}
+
+// Start は定期的な削除を開始する（開始時に1回実行する）
+func (c *StoreCollector) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.started {
+		return
+	}
+	c.started = true
+	c.stopCh = make(chan struct{})
+	c.wg.Add(1)
+	go c.run()
+}
+
+// Stop は定期的な削除を停止する
+func (c *StoreCollector) Stop() {
+	c.mu.Lock()
+	if !c.started {
+		c.mu.Unlock()
+		return
+	}
+	c.started = false
+	c.mu.Unlock()
+
+	close(c.stopCh)
+	c.wg.Wait()
+}
+
+// LastRun は直近の削除の結果を返す
+func (c *StoreCollector) LastRun() CollectRun {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lastRun
+}
+
+// run は間隔ごとに削除する
+func (c *StoreCollector) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := c.RunOnce(); err != nil {
+			event("retention.failed").failed(err).logf("⚠️ 終了済みの記録を削除できませんでした: %v\n", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// RunOnce は保持期間・件数を超えた終了済みの記録を削除する
+func (c *StoreCollector) RunOnce() (CollectRun, error) {
+	run := CollectRun{At: time.Now()}
+	err := c.collect(&run)
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	c.mu.Lock()
+	c.lastRun = run
+	c.mu.Unlock()
+
+	if deleted := run.Completed + run.Failed + run.Overflow; deleted > 0 {
+		event("retention.collected").logf("🧹 終了済みの記録を %d 件削除しました (成功: %d, 失敗: %d, 上限超過: %d, 残り: %d)\n",
+			deleted, run.Completed, run.Failed, run.Overflow, run.Remaining)
+	}
+	return run, err
+}
+
+// collect は削除する記録を選んで削除する
+func (c *StoreCollector) collect(run *CollectRun) error {
+	finished, err := c.store.LoadFinished(run.At)
+	if err != nil {
+		return fmt.Errorf("終了済みの記録を読み込めません: %w", err)
+	}
+
+	// 期間を過ぎた記録（状態ごと）と、残す記録（古い順）に分ける
+	var expiredCompleted, expiredFailed, kept []TaskRecord
+	for _, record := range finished {
+		limit := c.policy.Completed
+		if record.State == TaskStateFailed {
+			limit = c.policy.Failed
+		}
+		switch {
+		case limit <= 0 || run.At.Sub(record.UpdatedAt) <= limit:
+			kept = append(kept, record)
+		case record.State == TaskStateFailed:
+			expiredFailed = append(expiredFailed, record)
+		default:
+			expiredCompleted = append(expiredCompleted, record)
+		}
+	}
+	var overflow []TaskRecord
+	if c.policy.MaxFinished > 0 && len(kept) > c.policy.MaxFinished {
+		overflow = kept[:len(kept)-c.policy.MaxFinished]
+	}
+
+	for _, target := range []struct {
+		records []TaskRecord
+		count   *int
+	}{
+		{expiredCompleted, &run.Completed},
+		{expiredFailed, &run.Failed},
+		{overflow, &run.Overflow},
+	} {
+		if len(target.records) == 0 {
+			continue
+		}
+		deleted, err := c.store.DeleteFinished(target.records)
+		*target.count = deleted
+		if err != nil {
+			return fmt.Errorf("終了済みの記録を削除できません: %w", err)
+		}
+	}
+	run.Remaining = len(finished) - run.Completed - run.Failed - run.Overflow
+	return nil
+}
@@ -1,79 +1,191 @@
 package workerpool
 
 import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
 	"time"
 )
 
 type RetryPolicy struct {
-	MaxRetries      int           // 最大リトライ回数
-	InitialDelay    time.Duration // 初回リトライまでの遅延
-	MaxDelay        time.Duration // 最大遅延時間
-	BackoffFactor   float64       // バックオフ係数
-	RetryableErrors []string      // リトライ対象のエラーパターン
+	MaxRetries    int           // 最大リトライ回数
+	InitialDelay  time.Duration // 初回リトライまでの遅延
+	MaxDelay      time.Duration // 最大遅延時間（0以下で上限なし）
+	BackoffFactor float64       // バックオフ係数
+	RetryOn       []error       // リトライ対象のエラー（errors.Is で判定する）
+	// RetryableErrors はリトライ対象のエラーメッセージの接頭辞（既定のポリシーでは使わない。指定した場合のみ判定する）
+	//
+	// Deprecated: RetryOn か、プロセッサから返す RetryableError を使うこと。
+	RetryableErrors []string
+	Linear          bool           // true の場合は InitialDelay × BackoffFactor × 試行回数（既定の false は InitialDelay × BackoffFactor^試行回数）
+	Jitter          float64        // 遅延に加えるランダムな揺らぎの割合（0〜1、0.2 で ±20%。JitterProportional の場合のみ）
+	JitterStrategy  JitterStrategy // 揺らぎの加え方（空の場合は JitterProportional）
+	// ClassBackoff はエラーの種類ごとの遅延の曲線（指定のない種類は上の InitialDelay などで計算する）
+	// 例: タイムアウトは長く、接続の拒否は短く待つ。レート制限で再試行までの時間が指定されていればそれを優先する
+	ClassBackoff map[ErrorClass]BackoffCurve
 }
 
+// JitterStrategy はリトライの遅延に揺らぎを加える方法
+type JitterStrategy string
+
+const (
+	JitterProportional JitterStrategy = ""      // 遅延の ±Jitter の割合
+	JitterFull         JitterStrategy = "full"  // 0〜遅延の一様乱数（最も分散するが、InitialDelay を下回ることがある）
+	JitterEqual        JitterStrategy = "equal" // 遅延の半分＋0〜半分の一様乱数（遅延の半分は必ず待つ）
+)
+
 func DefaultRetryPolicy() RetryPolicy {
 	return RetryPolicy{
-		MaxRetries:    3,
-		InitialDelay:  1 * time.Second,
-		MaxDelay:      30 * time.Second,
-		BackoffFactor: 2.0,
-		RetryableErrors: []string{
-			"SMTP接続エラー",
-			"データベース接続エラー",
-			"context deadline exceeded", // タイムアウト
+		MaxRetries:     3,
+		InitialDelay:   1 * time.Second,
+		MaxDelay:       30 * time.Second,
+		BackoffFactor:  2.0,
+		JitterStrategy: JitterEqual, // 同時に失敗したタスクのリトライを分散する
+		// コンテナの一時エラー・リモートワーカーの切断は RetryableError で包まれているため指定しなくてもリトライする
+		// （リモートワーカーのエラーも ErrorKinds・RestoreError で型を保って届く）
+		RetryOn: []error{
+			ErrSMTPConnection,
+			ErrDatabaseConnection,
+			context.DeadlineExceeded, // タイムアウト
 		},
 	}
 }
 
+// LegacyRetryPatterns は以前の既定のポリシーがメッセージの接頭辞でリトライしていたパターンを返す
+// 型を保てない経路のエラーを従来どおりリトライする場合に、明示的に指定して使う
+//
+//	policy := NewRetryPolicy().AddRetryableErrors(LegacyRetryPatterns()...).Build()
+func LegacyRetryPatterns() []string {
+	return append([]string{}, legacyRetryPatterns...)
+}
+
+// legacyRetryPatterns は型が失われたエラー（リモートワーカーから文字列で返るエラーなど）をリトライするための互換のパターン
+var legacyRetryPatterns = []string{
+	"SMTP接続エラー",
+	"データベース接続エラー",
+	containerTransientError,
+	"リモートワーカー切断エラー",
+	"context deadline exceeded",
+}
+
 func TaskTypeRetryPolicies() map[TaskType]RetryPolicy {
 	return map[TaskType]RetryPolicy{
 		TaskTypeEmail: {
-			MaxRetries:      5, // メールは重要なので多めにリトライ
-			InitialDelay:    2 * time.Second,
-			MaxDelay:        60 * time.Second,
-			BackoffFactor:   2.0,
-			RetryableErrors: []string{"SMTP接続エラー"},
+			MaxRetries:     5, // メールは重要なので多めにリトライ
+			InitialDelay:   2 * time.Second,
+			MaxDelay:       60 * time.Second,
+			BackoffFactor:  2.0,
+			JitterStrategy: JitterEqual,
+			RetryOn:        []error{ErrSMTPConnection},
 		},
 		TaskTypeImage: {
-			MaxRetries:      2, // 画像処理は重くないのでリトライ少なめ
-			InitialDelay:    5 * time.Second,
-			MaxDelay:        30 * time.Second,
-			BackoffFactor:   1.5,
-			RetryableErrors: []string{}, // 形式エラーは基本的にリトライしない
+			MaxRetries:     2, // 画像処理は重くないのでリトライ少なめ
+			InitialDelay:   5 * time.Second,
+			MaxDelay:       30 * time.Second,
+			BackoffFactor:  1.5,
+			JitterStrategy: JitterEqual,
+			RetryOn:        []error{}, // 形式エラーは基本的にリトライしない
 		},
 		TaskTypeDatabase: {
-			MaxRetries:      4, // データベースは接続エラーが多いので多めに
-			InitialDelay:    1 * time.Second,
-			MaxDelay:        20 * time.Second,
-			BackoffFactor:   2.5,
-			RetryableErrors: []string{"データベース接続エラー", "context deadline exceeded"},
+			MaxRetries:     4, // データベースは接続エラーが多いので多めに
+			InitialDelay:   1 * time.Second,
+			MaxDelay:       20 * time.Second,
+			BackoffFactor:  2.5,
+			JitterStrategy: JitterEqual,
+			RetryOn:        []error{ErrDatabaseConnection, context.DeadlineExceeded},
 		},
 		TaskTypeReport: {
-			MaxRetries:      3,
-			InitialDelay:    10 * time.Second, // レポートは重い処理なので待機時間長め
-			MaxDelay:        120 * time.Second,
-			BackoffFactor:   2.0,
-			RetryableErrors: []string{"データ不整合エラー"},
+			MaxRetries:     3,
+			InitialDelay:   10 * time.Second, // レポートは重い処理なので待機時間長め
+			MaxDelay:       120 * time.Second,
+			BackoffFactor:  2.0,
+			JitterStrategy: JitterEqual,
+			RetryOn:        []error{ErrDataInconsistent},
+		},
+		TaskTypeContainer: {
+			MaxRetries:     3,
+			InitialDelay:   5 * time.Second, // デーモンの復旧を待つ
+			MaxDelay:       60 * time.Second,
+			BackoffFactor:  2.0,
+			JitterStrategy: JitterEqual,
+			// コンテナの一時エラーは RetryableError で包まれている
 		},
 	}
 }
 
+// CalculateRetryDelay は試行回数に応じたリトライまでの遅延を返す
+// 結果は試行回数に対して単調増加し、常に InitialDelay 以上 MaxDelay 以下に収まる（MaxDelay が0以下の場合は上限なし）
+// 不正な設定（負の遅延や係数、NaN）や巨大な試行回数でも負の値やオーバーフローした値は返さない
 func (rp *RetryPolicy) CalculateRetryDelay(attemptCount int) time.Duration {
+	limit := time.Duration(math.MaxInt64)
+	if rp.MaxDelay > 0 {
+		limit = rp.MaxDelay
+	}
+	initial := min(max(rp.InitialDelay, 0), limit)
 	if attemptCount <= 0 {
-		return rp.InitialDelay
+		return initial
 	}
 
-	// 指数バックオフ計算
-	delay := float64(rp.InitialDelay) * (rp.BackoffFactor * float64(attemptCount))
-	delayDuration := time.Duration(delay)
+	// バックオフ計算（int64 に収まらない値を time.Duration に変換しないよう、上限との比較は float64 のまま行う）
+	// 係数が1未満（負の値・NaN を含む）の場合は伸ばさない（負の係数の累乗は符号が入れ替わり単調にならない）
+	factor := rp.BackoffFactor
+	if !(factor >= 1) {
+		factor = 1
+	}
+	delay := float64(initial) * math.Pow(factor, float64(attemptCount))
+	if rp.Linear {
+		delay = float64(initial) * (rp.BackoffFactor * float64(attemptCount))
+	}
+	if math.IsNaN(delay) || delay < float64(initial) {
+		return initial
+	}
+	if delay >= float64(limit) {
+		return limit
+	}
 
-	// 最大遅延時間を超えないように制限
-	if delayDuration > rp.MaxDelay {
+	return time.Duration(delay)
+}
+
+// JitteredRetryDelay は CalculateRetryDelay に JitterStrategy の方法でランダムな揺らぎを加えた遅延を返す
+// 同時に失敗したタスクのリトライが一斉に集中しないようにする（結果は0以上、MaxDelay 以下）
+func (rp *RetryPolicy) JitteredRetryDelay(attemptCount int) time.Duration {
+	delay := rp.CalculateRetryDelay(attemptCount)
+	if delay <= 0 {
+		return delay
+	}
+	switch rp.JitterStrategy {
+	case JitterFull:
+		return time.Duration(rand.Int63n(int64(delay)))
+	case JitterEqual:
+		half := delay / 2
+		return half + time.Duration(rand.Int63n(int64(delay-half)))
+	}
+	if rp.Jitter <= 0 || math.IsNaN(rp.Jitter) {
+		return delay
+	}
+
+	jitter := min(rp.Jitter, 1)
+	jittered := float64(delay) * (1 + jitter*(2*rand.Float64()-1))
+	if rp.MaxDelay > 0 && jittered > float64(rp.MaxDelay) {
 		return rp.MaxDelay
 	}
+	if jittered >= math.MaxInt64 {
+		return delay
+	}
+	return time.Duration(max(jittered, 0))
+}
 
-	return delayDuration
+// ForTask はタスク単位の最大リトライ回数を反映したポリシーを返す
+// Task.MaxRetries が正の値ならポリシーの値を上書きし、負の値ならリトライしない（0 はポリシーのまま）
+func (rp RetryPolicy) ForTask(task Task) RetryPolicy {
+	switch {
+	case task.MaxRetries > 0:
+		rp.MaxRetries = task.MaxRetries
+	case task.MaxRetries < 0:
+		rp.MaxRetries = 0
+	}
+	return rp
 }
 
 // ShouldRetry はエラーがリトライ対象かどうかを判定
@@ -86,6 +198,24 @@ func (rp *RetryPolicy) ShouldRetry(err error, attemptCount int) bool {
 		return false
 	}
 
+	return rp.isRetryableError(err)
+}
+
+// isRetryableError はエラーがリトライ対象か判定（試行回数は考慮しない）
+// RetryableError・PermanentError で包まれたエラーはそれに従い、次に RetryOn、最後に（指定した場合のみ）非推奨のメッセージの接頭辞で判定する
+func (rp *RetryPolicy) isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if retryable, classified := classifyRetry(err); classified {
+		return retryable
+	}
+	for _, target := range rp.RetryOn {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
 	errorMsg := err.Error()
 	for _, retryableError := range rp.RetryableErrors {
 		if len(retryableError) > 0 && len(errorMsg) >= len(retryableError) {
@@ -97,3 +227,9 @@ func (rp *RetryPolicy) ShouldRetry(err error, attemptCount int) bool {
 
 	return false
 }
+
+// retryPolicyFor はタスクに適用するリトライポリシーを返す（タイプのポリシー、未設定ならデフォルトにタスク単位の上限を反映）
+// ブラウンアウト中は回数を減らして遅延を延ばしたポリシーを返す
+func (wp *WorkerPool) retryPolicyFor(task Task) RetryPolicy {
+	return wp.brownoutRetry(wp.RetryPolicy(task.Type).ForTask(task))
+}
@@ -0,0 +1,172 @@
+package workerpool
+
+import (
+	"context"
+	"time"
+)
+
+// AggressiveRetryPolicy は一時的な障害からの早い回復を狙い、短い間隔で何度もリトライするポリシー
+func AggressiveRetryPolicy() RetryPolicy {
+	return NewRetryPolicy().
+		WithMaxRetries(8).
+		WithExponentialBackoff(200*time.Millisecond, 10*time.Second, 1.5).
+		WithJitter(0.2).
+		Build()
+}
+
+// ConservativeRetryPolicy は下流への負荷を抑えるため、少ない回数を長い間隔でリトライするポリシー
+func ConservativeRetryPolicy() RetryPolicy {
+	return NewRetryPolicy().
+		WithMaxRetries(2).
+		WithExponentialBackoff(10*time.Second, 5*time.Minute, 3).
+		WithJitter(0.1).
+		Build()
+}
+
+// NoRetryPolicy はリトライしないポリシー
+func NoRetryPolicy() RetryPolicy {
+	return NewRetryPolicy().WithMaxRetries(0).WithRetryOn().Build()
+}
+
+// NetworkRetryPolicy はネットワーク越しの呼び出し向けのポリシー
+// 接続・タイムアウト系のエラー（と RetryableError で包まれたエラー）のみを、指数バックオフと full jitter でリトライする
+// （下流の障害で一斉に失敗したリトライが同じ時刻に集中しないようにする）
+func NetworkRetryPolicy() RetryPolicy {
+	return NewRetryPolicy().
+		WithMaxRetries(5).
+		WithExponentialBackoff(500*time.Millisecond, 30*time.Second, 2).
+		WithFullJitter().
+		WithRetryOn(
+			ErrSMTPConnection,
+			ErrDatabaseConnection,
+			context.DeadlineExceeded,
+		).
+		Build()
+}
+
+// RetryPolicyBuilder はリトライポリシーを組み立てるビルダー
+//
+//	policy := NewRetryPolicy().
+//		WithMaxRetries(5).
+//		WithExponentialBackoff(time.Second, time.Minute, 2).
+//		WithJitter(0.2).
+//		Build()
+type RetryPolicyBuilder struct {
+	policy RetryPolicy
+}
+
+// NewRetryPolicy は DefaultRetryPolicy を初期値とするビルダーを作成する
+func NewRetryPolicy() *RetryPolicyBuilder {
+	return &RetryPolicyBuilder{policy: DefaultRetryPolicy()}
+}
+
+// WithMaxRetries は最大リトライ回数を設定する（負の値は0として扱う）
+func (b *RetryPolicyBuilder) WithMaxRetries(maxRetries int) *RetryPolicyBuilder {
+	b.policy.MaxRetries = max(maxRetries, 0)
+	return b
+}
+
+// WithExponentialBackoff は InitialDelay × factor^試行回数 の遅延を maxDelay を上限に設定する
+func (b *RetryPolicyBuilder) WithExponentialBackoff(initial, maxDelay time.Duration, factor float64) *RetryPolicyBuilder {
+	b.policy.InitialDelay = initial
+	b.policy.MaxDelay = maxDelay
+	b.policy.BackoffFactor = factor
+	b.policy.Linear = false
+	return b
+}
+
+// WithLinearBackoff は InitialDelay × factor × 試行回数 の遅延を maxDelay を上限に設定する
+func (b *RetryPolicyBuilder) WithLinearBackoff(initial, maxDelay time.Duration, factor float64) *RetryPolicyBuilder {
+	b.policy.InitialDelay = initial
+	b.policy.MaxDelay = maxDelay
+	b.policy.BackoffFactor = factor
+	b.policy.Linear = true
+	return b
+}
+
+// WithFixedDelay は試行回数によらず一定の遅延を設定する
+func (b *RetryPolicyBuilder) WithFixedDelay(delay time.Duration) *RetryPolicyBuilder {
+	b.policy.InitialDelay = delay
+	b.policy.MaxDelay = delay
+	b.policy.BackoffFactor = 1
+	b.policy.Linear = false
+	return b
+}
+
+// WithJitter は遅延に加える揺らぎの割合を設定する（0〜1 に丸める）
+func (b *RetryPolicyBuilder) WithJitter(fraction float64) *RetryPolicyBuilder {
+	b.policy.Jitter = min(max(fraction, 0), 1)
+	b.policy.JitterStrategy = JitterProportional
+	return b
+}
+
+// WithFullJitter は遅延を 0〜遅延の一様乱数にする
+func (b *RetryPolicyBuilder) WithFullJitter() *RetryPolicyBuilder {
+	b.policy.JitterStrategy = JitterFull
+	return b
+}
+
+// WithEqualJitter は遅延を 遅延の半分＋0〜半分の一様乱数にする
+func (b *RetryPolicyBuilder) WithEqualJitter() *RetryPolicyBuilder {
+	b.policy.JitterStrategy = JitterEqual
+	return b
+}
+
+// WithClassBackoff はエラーの種類ごとの遅延の曲線を設定する（ClassifyError で分類する）
+//
+//	NewRetryPolicy().
+//		WithClassBackoff(ErrorClassTimeout, BackoffCurve{InitialDelay: 10 * time.Second, MaxDelay: 5 * time.Minute, BackoffFactor: 2}).
+//		WithClassBackoff(ErrorClassConnection, BackoffCurve{InitialDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second, BackoffFactor: 2})
+func (b *RetryPolicyBuilder) WithClassBackoff(class ErrorClass, curve BackoffCurve) *RetryPolicyBuilder {
+	classBackoff := make(map[ErrorClass]BackoffCurve, len(b.policy.ClassBackoff)+1)
+	for existing, existingCurve := range b.policy.ClassBackoff {
+		classBackoff[existing] = existingCurve
+	}
+	classBackoff[class] = curve
+	b.policy.ClassBackoff = classBackoff
+	return b
+}
+
+// WithRetryOn はリトライ対象のエラー（errors.Is で判定する）を置き換える
+// 非推奨のエラーパターン（RetryableErrors）も空にする
+func (b *RetryPolicyBuilder) WithRetryOn(targets ...error) *RetryPolicyBuilder {
+	b.policy.RetryOn = append([]error{}, targets...)
+	b.policy.RetryableErrors = []string{}
+	return b
+}
+
+// AddRetryOn はリトライ対象のエラーを追加する
+func (b *RetryPolicyBuilder) AddRetryOn(targets ...error) *RetryPolicyBuilder {
+	b.policy.RetryOn = append(b.policy.RetryOn, targets...)
+	return b
+}
+
+// WithRetryableErrors はリトライ対象のエラーパターン（メッセージの前方一致）を置き換える
+//
+// Deprecated: WithRetryOn を使うこと。
+func (b *RetryPolicyBuilder) WithRetryableErrors(patterns ...string) *RetryPolicyBuilder {
+	b.policy.RetryableErrors = append([]string{}, patterns...)
+	return b
+}
+
+// AddRetryableErrors はリトライ対象のエラーパターンを追加する
+//
+// Deprecated: AddRetryOn を使うこと。
+func (b *RetryPolicyBuilder) AddRetryableErrors(patterns ...string) *RetryPolicyBuilder {
+	b.policy.RetryableErrors = append(b.policy.RetryableErrors, patterns...)
+	return b
+}
+
+// Build は組み立てたポリシーを返す（以降ビルダーを変更しても影響しない）
+func (b *RetryPolicyBuilder) Build() RetryPolicy {
+	policy := b.policy
+	policy.RetryOn = append([]error{}, b.policy.RetryOn...)
+	policy.RetryableErrors = append([]string{}, b.policy.RetryableErrors...)
+	if b.policy.ClassBackoff != nil {
+		policy.ClassBackoff = make(map[ErrorClass]BackoffCurve, len(b.policy.ClassBackoff))
+		for class, curve := range b.policy.ClassBackoff {
+			policy.ClassBackoff[class] = curve
+		}
+	}
+	return policy
+}
@@ -0,0 +1,105 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"time"
+)
+
+// ErrorClass はリトライの遅延を決めるためのエラーの種類
+type ErrorClass string
+
+const (
+	ErrorClassTimeout     ErrorClass = "timeout"      // タイムアウト（下流が重いため長めに待つ）
+	ErrorClassConnection  ErrorClass = "connection"   // 接続の拒否・切断（再起動中などのため短い間隔で試す）
+	ErrorClassRateLimited ErrorClass = "rate_limited" // レート制限（429 など、RetryAfter の指定があればそれに従う）
+	ErrorClassOther       ErrorClass = "other"        // 上記以外
+)
+
+// RateLimitError は下流のレート制限で拒否されたエラー（HTTP 429 など）
+// リトライ対象になり、RetryAfter が正の値であればバックオフの計算によらずその時間だけ待ってリトライする
+type RateLimitError struct {
+	Err        error
+	RetryAfter time.Duration // 下流が指定した再試行までの時間（Retry-After ヘッダーなど、0 で指定なし）
+}
+
+func (e *RateLimitError) Error() string   { return e.Err.Error() }
+func (e *RateLimitError) Unwrap() error   { return e.Err }
+func (e *RateLimitError) retryable() bool { return true }
+
+// RateLimited は err をレート制限のエラーとして包む（nil はそのまま）
+func RateLimited(err error, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &RateLimitError{Err: err, RetryAfter: retryAfter}
+}
+
+// BackoffCurve はエラーの種類ごとに使うリトライの遅延の計算方法（揺らぎはポリシーの JitterStrategy に従う）
+type BackoffCurve struct {
+	InitialDelay  time.Duration // 初回リトライまでの遅延
+	MaxDelay      time.Duration // 最大遅延時間（0以下で上限なし）
+	BackoffFactor float64       // バックオフ係数
+	Linear        bool          // true の場合は線形に伸ばす（既定の false は指数）
+}
+
+// connectionErrors は接続の拒否・切断として扱うエラー
+var connectionErrors = []error{
+	syscall.ECONNREFUSED,
+	syscall.ECONNRESET,
+	ErrSMTPConnection,
+	ErrDatabaseConnection,
+}
+
+// ClassifyError はエラーをリトライの遅延を決めるための種類に分類する
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassOther
+	}
+	var rateLimited *RateLimitError
+	if errors.As(err, &rateLimited) {
+		return ErrorClassRateLimited
+	}
+	for _, target := range connectionErrors {
+		if errors.Is(err, target) {
+			return ErrorClassConnection
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return ErrorClassTimeout
+	}
+	return ErrorClassOther
+}
+
+// retryAfterOf はエラーに含まれる下流の再試行までの時間を返す（指定がない場合は0）
+func retryAfterOf(err error) time.Duration {
+	var rateLimited *RateLimitError
+	if errors.As(err, &rateLimited) {
+		return max(rateLimited.RetryAfter, 0)
+	}
+	return 0
+}
+
+// RetryDelayFor は失敗したエラーの種類に応じたリトライまでの遅延を返す
+// レート制限で再試行までの時間が指定されていればそれに従い、ClassBackoff に種類の曲線があればそれを、
+// なければポリシーの曲線を使う（いずれも JitteredRetryDelay と同じ揺らぎを加える）
+func (rp *RetryPolicy) RetryDelayFor(err error, attemptCount int) time.Duration {
+	if retryAfter := retryAfterOf(err); retryAfter > 0 {
+		return retryAfter
+	}
+	curve, exists := rp.ClassBackoff[ClassifyError(err)]
+	if !exists {
+		return rp.JitteredRetryDelay(attemptCount)
+	}
+	classPolicy := *rp
+	classPolicy.InitialDelay = curve.InitialDelay
+	classPolicy.MaxDelay = curve.MaxDelay
+	classPolicy.BackoffFactor = curve.BackoffFactor
+	classPolicy.Linear = curve.Linear
+	return classPolicy.JitteredRetryDelay(attemptCount)
+}
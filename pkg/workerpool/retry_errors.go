@@ -0,0 +1,67 @@
+package workerpool
+
+import "errors"
+
+// RetryableError はリトライすれば回復しうる一時的なエラー
+// プロセッサが Retryable で包んで返すと、リトライポリシーのエラーの指定によらずリトライ対象になる（回数の上限は守る）
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string   { return e.Err.Error() }
+func (e *RetryableError) Unwrap() error   { return e.Err }
+func (e *RetryableError) retryable() bool { return true }
+
+// PermanentError はリトライしても変わらない恒久的なエラー
+// プロセッサが Permanent で包んで返すと、リトライポリシーのエラーの指定によらずリトライしない
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string   { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error   { return e.Err }
+func (e *PermanentError) retryable() bool { return false }
+
+// retryClassified は RetryableError・PermanentError の共通のインターフェース
+type retryClassified interface {
+	error
+	retryable() bool
+}
+
+// Retryable は err をリトライ対象のエラーとして包む（nil はそのまま）
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// Permanent は err をリトライしないエラーとして包む（nil はそのまま）
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsRetryable は err が RetryableError で包まれているか判定する
+// RetryableError と PermanentError を重ねて包んだ場合は外側（errors.As で先に見つかる方）に従う
+func IsRetryable(err error) bool {
+	retryable, _ := classifyRetry(err)
+	return retryable
+}
+
+// IsPermanent は err が PermanentError で包まれているか判定する
+func IsPermanent(err error) bool {
+	retryable, classified := classifyRetry(err)
+	return classified && !retryable
+}
+
+// classifyRetry は型でリトライ可否が分かるエラーの分類を返す（分からない場合は classified が false）
+func classifyRetry(err error) (retryable, classified bool) {
+	var c retryClassified
+	if errors.As(err, &c) {
+		return c.retryable(), true
+	}
+	return false, false
+}
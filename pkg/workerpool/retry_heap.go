@@ -0,0 +1,70 @@
+package workerpool
+
+import (
+	"container/heap"
+	"time"
+)
+
+// retryItem はリトライハンドラーが予定時刻を待っているタスク
+type retryItem struct {
+	task  Task
+	dueAt time.Time
+	seq   uint64 // 予定時刻が同じ場合に登録順で取り出すための番号
+}
+
+// retryHeap は予定時刻の早い順に取り出すリトライ待ちのタスクのヒープ
+// 遅延の異なるリトライがそれぞれの予定時刻に戻れるよう、1つのタイマーを先頭の予定時刻に合わせて使う
+type retryHeap struct {
+	items []retryItem
+	seq   uint64
+}
+
+func (h retryHeap) Len() int { return len(h.items) }
+
+func (h retryHeap) Less(i, j int) bool {
+	if !h.items[i].dueAt.Equal(h.items[j].dueAt) {
+		return h.items[i].dueAt.Before(h.items[j].dueAt)
+	}
+	return h.items[i].seq < h.items[j].seq
+}
+
+func (h retryHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *retryHeap) Push(x interface{}) { h.items = append(h.items, x.(retryItem)) }
+
+func (h *retryHeap) Pop() interface{} {
+	old := h.items
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = retryItem{}
+	h.items = old[:n-1]
+	return item
+}
+
+// schedule はタスクを予定時刻に取り出すよう登録する
+func (h *retryHeap) schedule(task Task, dueAt time.Time) {
+	h.seq++
+	heap.Push(h, retryItem{task: task, dueAt: dueAt, seq: h.seq})
+}
+
+// popDue は予定時刻を過ぎたタスクを予定時刻の早い順に取り出す
+func (h *retryHeap) popDue(now time.Time) []Task {
+	var due []Task
+	for h.Len() > 0 && !h.items[0].dueAt.After(now) {
+		due = append(due, heap.Pop(h).(retryItem).task)
+	}
+	return due
+}
+
+// resetTimer はタイマーを先頭の予定時刻に合わせる（待っているタスクがなければ止める）
+func (h *retryHeap) resetTimer(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	if h.Len() > 0 {
+		timer.Reset(time.Until(h.items[0].dueAt))
+	}
+}
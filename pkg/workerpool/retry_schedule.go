@@ -0,0 +1,320 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRetryNotFound は指定したタスクがリトライ待ちでない場合のエラー
+	ErrRetryNotFound = errors.New("リトライ待ちのタスクが見つかりません")
+	// ErrRetryCanceled は管理操作でリトライを取り消したタスクのエラー
+	ErrRetryCanceled = errors.New("リトライ取り消し: 管理操作によりリトライを中止しました")
+)
+
+// RetryEntry はリトライ待ちのタスク
+type RetryEntry struct {
+	TaskID      int               `json:"task_id"`
+	TaskName    string            `json:"task_name"`
+	TaskType    TaskType          `json:"task_type"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Attempt     int               `json:"attempt"` // 次が何回目の試行か
+	LastError   string            `json:"last_error,omitempty"`
+	NextRetryAt time.Time         `json:"next_retry_at"`
+	ScheduledAt time.Time         `json:"scheduled_at"` // リトライ待ちになった日時
+}
+
+// retrySchedule はリトライ待ちのタスクの一覧（タスクIDごと）
+// リトライハンドラーと管理操作のどちらが先に取り出しても二重に実行しないよう、
+// 登録ごとに採番した番号が一致する場合のみ取り出せる
+type retrySchedule struct {
+	mu      sync.Mutex
+	seq     uint64
+	entries map[int]scheduledRetry
+}
+
+type scheduledRetry struct {
+	task        Task
+	scheduledAt time.Time
+}
+
+func newRetrySchedule() retrySchedule {
+	return retrySchedule{entries: make(map[int]scheduledRetry)}
+}
+
+// add はタスクをリトライ待ちとして登録し、採番したタスクを返す
+func (s *retrySchedule) add(task Task) Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	task.retrySeq = s.seq
+	s.entries[task.ID] = scheduledRetry{task: task, scheduledAt: time.Now()}
+	return task
+}
+
+// claim は登録されたタスクを一覧から取り出す（既に他で取り出されていれば false）
+// 登録後に管理操作で変更された内容（優先度など）を反映したタスクを返す
+func (s *retrySchedule) claim(task Task) (Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[task.ID]
+	if !exists || entry.task.retrySeq != task.retrySeq {
+		return Task{}, false
+	}
+	delete(s.entries, task.ID)
+	return entry.task, true
+}
+
+// take はタスクIDでリトライ待ちのタスクを取り出す
+func (s *retrySchedule) take(taskID int) (Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[taskID]
+	if !exists {
+		return Task{}, false
+	}
+	delete(s.entries, taskID)
+	return entry.task, true
+}
+
+// removeWhere は条件に一致するリトライ待ちのタスクを取り出す
+func (s *retrySchedule) removeWhere(match func(Task) bool) []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed []Task
+	for taskID, entry := range s.entries {
+		if match(entry.task) {
+			removed = append(removed, entry.task)
+			delete(s.entries, taskID)
+		}
+	}
+	return removed
+}
+
+// updateWhere は条件に一致するリトライ待ちのタスクを書き換え、書き換えた件数を返す
+func (s *retrySchedule) updateWhere(match func(Task) bool, update func(*Task)) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated := 0
+	for taskID, entry := range s.entries {
+		if match(entry.task) {
+			update(&entry.task)
+			s.entries[taskID] = entry
+			updated++
+		}
+	}
+	return updated
+}
+
+// pending はタスクがまだリトライ待ちとして登録されているか判定
+func (s *retrySchedule) pending(task Task) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[task.ID]
+	return exists && entry.task.retrySeq == task.retrySeq
+}
+
+// len はリトライ待ちのタスク数を返す
+func (s *retrySchedule) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+// list はリトライ待ちのタスクを予定時刻の早い順に返す
+func (s *retrySchedule) list() []RetryEntry {
+	s.mu.Lock()
+	entries := make([]RetryEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		task := entry.task
+		retry := RetryEntry{
+			TaskID:      task.ID,
+			TaskName:    task.Name,
+			TaskType:    task.Type,
+			Labels:      task.Labels,
+			Attempt:     task.AttemptCount + 1,
+			NextRetryAt: task.nextRetryAt,
+			ScheduledAt: entry.scheduledAt,
+		}
+		if task.LastError != nil {
+			retry.LastError = task.LastError.Error()
+		}
+		entries = append(entries, retry)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].NextRetryAt.Equal(entries[j].NextRetryAt) {
+			return entries[i].NextRetryAt.Before(entries[j].NextRetryAt)
+		}
+		return entries[i].TaskID < entries[j].TaskID
+	})
+	return entries
+}
+
+// PendingRetries はリトライ待ちのタスクを予定時刻の早い順に返す
+func (wp *WorkerPool) PendingRetries() []RetryEntry {
+	return wp.retries.list()
+}
+
+// PendingRetries はすべてのサブプールのリトライ待ちのタスクを予定時刻の早い順に返す
+func (tp *TypedPool) PendingRetries() []RetryEntry {
+	var entries []RetryEntry
+	for _, pool := range tp.subPools() {
+		entries = append(entries, pool.PendingRetries()...)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].NextRetryAt.Before(entries[j].NextRetryAt)
+	})
+	return entries
+}
+
+// scheduleRetry はタスクをリトライ待ちに登録してリトライハンドラーに渡す
+// リトライキューが満杯の場合は登録を取り消して false を返す
+func (wp *WorkerPool) scheduleRetry(task Task) bool {
+	task = wp.retries.add(task)
+	select {
+	case wp.retryQueue <- task:
+		return true
+	default:
+		wp.retries.claim(task)
+		return false
+	}
+}
+
+// RetryNow はリトライ待ちのタスクを予定時刻を待たずにキューに戻す
+func (wp *WorkerPool) RetryNow(taskID int) error {
+	task, exists := wp.retries.take(taskID)
+	if !exists {
+		return fmt.Errorf("タスク %d: %w", taskID, ErrRetryNotFound)
+	}
+
+	task.nextRetryAt = time.Time{}
+	if err := wp.enqueue(task); err != nil {
+		wp.dlq.Add(task, DeadLetterShutdown, ErrPoolStopped)
+		return ErrPoolStopped
+	}
+	event("task.retry_now").taskOf(task).attempt(task.AttemptCount+1).logf("⏩ タスク %d を予定時刻を待たずにリトライします (試行回数: %d)\n", task.ID, task.AttemptCount+1)
+	return nil
+}
+
+// CancelRetry はリトライ待ちのタスクのリトライを取り消し、DLQに送る
+// 結果は最終的な失敗（ErrRetryCanceled）として通知する
+func (wp *WorkerPool) CancelRetry(taskID int) error {
+	task, exists := wp.retries.take(taskID)
+	if !exists {
+		return fmt.Errorf("タスク %d: %w", taskID, ErrRetryNotFound)
+	}
+
+	wp.abandon(task, ErrRetryCanceled)
+	event("task.retry_canceled").taskOf(task).logf("🚮 タスク %d のリトライを取り消し、DLQに送りました\n", task.ID)
+	return nil
+}
+
+// abandon は実行前のタスク（キュー待ち・リトライ待ち）を取り消してDLQに送り、最終的な失敗として結果を通知する
+func (wp *WorkerPool) abandon(task Task, err error) {
+	wp.dlq.Add(task, DeadLetterCanceled, err)
+	wp.commit(task, TaskStateFailed, err)
+
+	// 実行していないためワーカーIDは -1、処理時間は0とする
+	var totalDuration time.Duration
+	if !task.FirstAttempt.IsZero() {
+		totalDuration = time.Since(task.FirstAttempt)
+	}
+	// sendResult は試行中の回数に1を足すため、実施済みの回数（AttemptCount）に合わせる
+	task.AttemptCount--
+	wp.sendResult(task, err, 0, totalDuration, -1, true)
+}
+
+// RetryNow はタスクを持つサブプールでリトライを即時実行する
+func (tp *TypedPool) RetryNow(taskID int) error {
+	for _, pool := range tp.subPools() {
+		if err := pool.RetryNow(taskID); !errors.Is(err, ErrRetryNotFound) {
+			return err
+		}
+	}
+	return fmt.Errorf("タスク %d: %w", taskID, ErrRetryNotFound)
+}
+
+// CancelRetry はタスクを持つサブプールでリトライを取り消す
+func (tp *TypedPool) CancelRetry(taskID int) error {
+	for _, pool := range tp.subPools() {
+		if err := pool.CancelRetry(taskID); !errors.Is(err, ErrRetryNotFound) {
+			return err
+		}
+	}
+	return fmt.Errorf("タスク %d: %w", taskID, ErrRetryNotFound)
+}
+
+// handleRetries は GET /retries でリトライ待ちのタスクを返す
+func (m *Monitor) handleRetries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+
+	entries := m.pool.PendingRetries()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":   len(entries),
+		"retries": entries,
+	})
+}
+
+// handleRetryAction はリトライ待ちのタスクを操作する
+//
+//	POST   /retries/{id}/retry-now  予定時刻を待たずにリトライ
+//	DELETE /retries/{id}            リトライを取り消してDLQに送る
+func (m *Monitor) handleRetryAction(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/retries/")
+	idPart, action, _ := strings.Cut(rest, "/")
+	taskID, err := strconv.Atoi(idPart)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "タスクIDが不正です: "+idPart)
+		return
+	}
+
+	switch {
+	case action == "retry-now" && r.Method == http.MethodPost:
+		err = m.pool.RetryNow(taskID)
+	case action == "" && r.Method == http.MethodDelete:
+		err = m.pool.CancelRetry(taskID)
+	case action == "retry-now":
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "POST のみ対応しています")
+		return
+	case action == "":
+		w.Header().Set("Allow", http.MethodDelete)
+		writeJSONError(w, http.StatusMethodNotAllowed, "DELETE のみ対応しています")
+		return
+	default:
+		writeJSONError(w, http.StatusNotFound, "不明な操作です: "+action)
+		return
+	}
+
+	switch {
+	case errors.Is(err, ErrRetryNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		status := "retrying"
+		if r.Method == http.MethodDelete {
+			status = "canceled"
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"task_id": taskID, "status": status})
+	}
+}
@@ -0,0 +1,286 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// retryPolicySamples は遅延の性質を確かめるポリシー（通常の設定と、不正・極端な設定）
+func retryPolicySamples() map[string]RetryPolicy {
+	samples := map[string]RetryPolicy{
+		"default":           DefaultRetryPolicy(),
+		"linear":            {InitialDelay: time.Second, MaxDelay: time.Minute, BackoffFactor: 2, Linear: true},
+		"no max delay":      {InitialDelay: time.Second, BackoffFactor: 2},
+		"huge initial":      {InitialDelay: time.Duration(math.MaxInt64), BackoffFactor: 10},
+		"factor below one":  {InitialDelay: time.Second, MaxDelay: time.Minute, BackoffFactor: 0.5},
+		"negative factor":   {InitialDelay: time.Second, MaxDelay: time.Minute, BackoffFactor: -3},
+		"NaN factor":        {InitialDelay: time.Second, MaxDelay: time.Minute, BackoffFactor: math.NaN()},
+		"infinite factor":   {InitialDelay: time.Second, MaxDelay: time.Minute, BackoffFactor: math.Inf(1)},
+		"negative initial":  {InitialDelay: -time.Second, MaxDelay: time.Minute, BackoffFactor: 2},
+		"initial above max": {InitialDelay: time.Hour, MaxDelay: time.Minute, BackoffFactor: 2},
+	}
+	for name, policy := range TaskTypeRetryPolicies() {
+		samples["type "+string(name)] = policy
+	}
+	return samples
+}
+
+// 遅延は試行回数に対して単調増加し、0以上・MaxDelay 以下に収まる
+func TestCalculateRetryDelayProperties(t *testing.T) {
+	attempts := []int{math.MinInt, -1, 0, 1, 2, 3, 10, 62, 63, 64, 1000, 1 << 20, math.MaxInt32, math.MaxInt}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		attempts = append(attempts, rng.Intn(10000))
+	}
+
+	for name, policy := range retryPolicySamples() {
+		t.Run(name, func(t *testing.T) {
+			checkRetryDelayProperties(t, policy, attempts)
+		})
+	}
+}
+
+// ランダムな設定でも同じ性質が保たれる
+func TestCalculateRetryDelayRandomPolicies(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	attempts := []int{0, 1, 2, 5, 30, 64, 100, 5000, math.MaxInt}
+	for i := 0; i < 500; i++ {
+		policy := RetryPolicy{
+			InitialDelay:  time.Duration(rng.Int63n(int64(time.Hour))),
+			MaxDelay:      time.Duration(rng.Int63n(int64(24*time.Hour))) - time.Hour,
+			BackoffFactor: rng.Float64() * 10,
+			Linear:        rng.Intn(2) == 0,
+		}
+		checkRetryDelayProperties(t, policy, attempts)
+	}
+}
+
+func checkRetryDelayProperties(t *testing.T, policy RetryPolicy, attempts []int) {
+	t.Helper()
+	sorted := append([]int(nil), attempts...)
+	slices.Sort(sorted)
+
+	var previous time.Duration
+	for i, attempt := range sorted {
+		delay := policy.CalculateRetryDelay(attempt)
+		if delay < 0 {
+			t.Fatalf("%+v: CalculateRetryDelay(%d) = %v, 負の値", policy, attempt, delay)
+		}
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			t.Fatalf("%+v: CalculateRetryDelay(%d) = %v, MaxDelay %v を超えています", policy, attempt, delay, policy.MaxDelay)
+		}
+		if i > 0 && delay < previous {
+			t.Fatalf("%+v: CalculateRetryDelay(%d) = %v が前の試行回数の %v より短くなっています", policy, attempt, delay, previous)
+		}
+		previous = delay
+	}
+}
+
+// 既定の指数バックオフは MaxDelay に達するまで係数倍に伸び、上限で頭打ちになる
+func TestCalculateRetryDelayExponentialByDefault(t *testing.T) {
+	policy := RetryPolicy{InitialDelay: time.Second, MaxDelay: 10 * time.Second, BackoffFactor: 2}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for attempt, expected := range want {
+		if got := policy.CalculateRetryDelay(attempt); got != expected {
+			t.Errorf("CalculateRetryDelay(%d) = %v, want %v", attempt, got, expected)
+		}
+	}
+	if got := policy.CalculateRetryDelay(math.MaxInt); got != policy.MaxDelay {
+		t.Errorf("CalculateRetryDelay(MaxInt) = %v, want %v", got, policy.MaxDelay)
+	}
+}
+
+// 揺らぎを加えた遅延も0以上・MaxDelay 以下に収まる
+func TestJitteredRetryDelayBounded(t *testing.T) {
+	for _, strategy := range []JitterStrategy{JitterProportional, JitterFull, JitterEqual} {
+		policy := RetryPolicy{InitialDelay: time.Second, MaxDelay: time.Minute, BackoffFactor: 2, Jitter: 1, JitterStrategy: strategy}
+		for attempt := 0; attempt < 100; attempt++ {
+			if delay := policy.JitteredRetryDelay(attempt); delay < 0 || delay > policy.MaxDelay {
+				t.Fatalf("%q: JitteredRetryDelay(%d) = %v, 範囲外", strategy, attempt, delay)
+			}
+		}
+	}
+}
+
+// タスクの MaxRetries は正の値ならポリシーを上書きし、負の値ならリトライしない。0（未設定）はポリシーに従う
+func TestRetryPolicyForTask(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, InitialDelay: time.Second, BackoffFactor: 2}
+	tests := []struct {
+		name       string
+		task       Task
+		maxRetries int
+	}{
+		{"未設定はポリシーの上限", Task{ID: 1}, 3},
+		{"0はポリシーの上限", Task{ID: 1, MaxRetries: 0}, 3},
+		{"正の値で上書き", Task{ID: 1, MaxRetries: 7}, 7},
+		{"ポリシーより少ない値で上書き", Task{ID: 1, MaxRetries: 1}, 1},
+		{"負の値はリトライしない", Task{ID: 1, MaxRetries: -1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.ForTask(tt.task)
+			if got.MaxRetries != tt.maxRetries {
+				t.Errorf("ForTask(MaxRetries=%d).MaxRetries = %d, want %d", tt.task.MaxRetries, got.MaxRetries, tt.maxRetries)
+			}
+			if got.InitialDelay != policy.InitialDelay || got.BackoffFactor != policy.BackoffFactor {
+				t.Errorf("ForTask が MaxRetries 以外を変更しました: %+v", got)
+			}
+		})
+	}
+	if policy.MaxRetries != 3 {
+		t.Errorf("ForTask が元のポリシーを変更しました: MaxRetries = %d", policy.MaxRetries)
+	}
+}
+
+// プールが使うリトライの上限は、タスクの値・タイプのポリシー・デフォルトのポリシーの順に決まる
+func TestRetryPolicyForUsesTaskAndPolicyLimits(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.SetRetryPolicy("custom", RetryPolicy{MaxRetries: 5, InitialDelay: time.Second, BackoffFactor: 2})
+	defaultLimit := DefaultRetryPolicy().MaxRetries
+
+	tests := []struct {
+		name       string
+		task       Task
+		maxRetries int
+	}{
+		{"タイプのポリシー", Task{Type: "custom"}, 5},
+		{"タスクの0はタイプのポリシー", Task{Type: "custom", MaxRetries: 0}, 5},
+		{"タスクの値がタイプのポリシーより優先", Task{Type: "custom", MaxRetries: 2}, 2},
+		{"タスクの負の値はタイプのポリシーより優先", Task{Type: "custom", MaxRetries: -1}, 0},
+		{"ポリシーのないタイプはデフォルト", Task{Type: "unknown"}, defaultLimit},
+		{"ポリシーのないタイプでもタスクの値が優先", Task{Type: "unknown", MaxRetries: defaultLimit + 4}, defaultLimit + 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pool.retryPolicyFor(tt.task).MaxRetries; got != tt.maxRetries {
+				t.Errorf("retryPolicyFor(%s, MaxRetries=%d).MaxRetries = %d, want %d", tt.task.Type, tt.task.MaxRetries, got, tt.maxRetries)
+			}
+		})
+	}
+
+	// ブラウンアウト中はタスクの値で上書きした上限もさらに絞る
+	pool.SetBrownout(&BrownoutMode{Retry: RetryThrottle{MaxRetries: 1}})
+	if got := pool.retryPolicyFor(Task{Type: "custom", MaxRetries: 4}).MaxRetries; got != 1 {
+		t.Errorf("ブラウンアウト中の MaxRetries = %d, want 1", got)
+	}
+}
+
+// タスクの MaxRetries が未設定の場合、実際の実行回数はタイプのポリシーの上限に従う
+func TestTaskWithoutMaxRetriesUsesPolicyLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		attempts   int32
+	}{
+		{"未設定", 0, 3},
+		{"タスクの値", 1, 2},
+		{"リトライしない", -1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := NewWorkerPool(1)
+			var attempts atomic.Int32
+			pool.RegisterProcessor("flaky", func(ctx context.Context, task Task) error {
+				attempts.Add(1)
+				return Retryable(errors.New("一時的な失敗"))
+			})
+			pool.SetRetryPolicy("flaky", RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond, BackoffFactor: 2})
+
+			final := make(chan TaskResult, 1)
+			pool.OnResult(func(result TaskResult) {
+				if result.IsFinal {
+					final <- result
+				}
+			})
+			if err := pool.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer pool.Stop()
+			if err := pool.AddTask(Task{ID: 1, Type: "flaky", MaxRetries: tt.maxRetries}); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case result := <-final:
+				if result.Success {
+					t.Fatal("タスクが成功しました")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("最終結果が届きませんでした")
+			}
+			if got := attempts.Load(); got != tt.attempts {
+				t.Errorf("実行回数 = %d, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
+
+// 任意の設定・試行回数でも遅延は0以上・MaxDelay 以下で、試行回数に対して減らない
+func FuzzCalculateRetryDelay(f *testing.F) {
+	f.Add(int64(time.Second), int64(time.Minute), 2.0, false, 3)
+	f.Add(int64(100*time.Millisecond), int64(0), 1.5, true, 1000)
+	f.Add(int64(math.MaxInt64), int64(time.Hour), 10.0, false, math.MaxInt)
+	f.Add(int64(-1), int64(-1), -3.0, false, -1)
+	f.Add(int64(time.Hour), int64(time.Minute), math.NaN(), true, 64)
+
+	f.Fuzz(func(t *testing.T, initial, maxDelay int64, factor float64, linear bool, attempt int) {
+		policy := RetryPolicy{
+			InitialDelay:  time.Duration(initial),
+			MaxDelay:      time.Duration(maxDelay),
+			BackoffFactor: factor,
+			Linear:        linear,
+		}
+		attempts := []int{attempt, attempt / 2, 0}
+		if attempt < math.MaxInt {
+			attempts = append(attempts, attempt+1)
+		}
+		checkRetryDelayProperties(t, policy, attempts)
+	})
+}
+
+// リトライの判定は回数の上限と分類（Retryable・Permanent・RetryOn・RetryableErrors）に従う
+func FuzzShouldRetry(f *testing.F) {
+	f.Add("connection refused", "connection", 0, 3, uint8(0))
+	f.Add("invalid input", "", 1, 3, uint8(1))
+	f.Add("timeout", "timeout", 3, 3, uint8(2))
+	f.Add("", "x", -1, 0, uint8(3))
+	f.Add("smtp down", "smtp", 0, 5, uint8(4))
+
+	f.Fuzz(func(t *testing.T, message, pattern string, attempt, maxRetries int, kind uint8) {
+		policy := RetryPolicy{MaxRetries: maxRetries, RetryOn: []error{ErrSMTPConnection}}
+		if pattern != "" {
+			policy.RetryableErrors = []string{pattern}
+		}
+
+		var err error
+		var want bool
+		base := errors.New(message)
+		switch kind % 5 {
+		case 0:
+			err, want = base, pattern != "" && strings.HasPrefix(message, pattern)
+		case 1:
+			err, want = Retryable(base), true
+		case 2:
+			err, want = Permanent(base), false
+		case 3:
+			err, want = nil, false
+		case 4:
+			err, want = fmt.Errorf("%s: %w", message, ErrSMTPConnection), true
+		}
+		if attempt >= maxRetries {
+			want = false
+		}
+
+		if got := policy.ShouldRetry(err, attempt); got != want {
+			t.Fatalf("ShouldRetry(%v, %d) = %v, want %v (MaxRetries=%d, RetryableErrors=%q)", err, attempt, got, want, maxRetries, policy.RetryableErrors)
+		}
+	})
+}
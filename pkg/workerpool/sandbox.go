@@ -0,0 +1,110 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// ErrReadOnly は読み取り専用モードで変更系の操作を呼び出した場合のエラー
+var ErrReadOnly = errors.New("読み取り専用モードのため変更できません")
+
+// ErrSimulatedFailure はシミュレーターが失敗を模擬した場合のエラー（リトライ対象）
+var ErrSimulatedFailure = errors.New("シミュレーターの模擬エラー")
+
+// デモ・サンドボックスで使うシミュレーターの既定値
+const (
+	defaultSimulatedDuration = 200 * time.Millisecond
+	defaultSimulatedJitter   = 0.5
+)
+
+// Simulation はプロセッサの代わりに実行するシミュレーターの設定
+type Simulation struct {
+	Duration    time.Duration `json:"duration_ns"`  // 平均の処理時間（0 の場合は200ms）
+	Jitter      float64       `json:"jitter"`       // 処理時間の揺らぎの割合（0〜1、0 の場合は0.5）
+	FailureRate float64       `json:"failure_rate"` // 失敗させる割合（0〜1）
+}
+
+// withDefaults は既定値を補った設定を返す
+func (s Simulation) withDefaults() Simulation {
+	if s.Duration <= 0 {
+		s.Duration = defaultSimulatedDuration
+	}
+	if s.Jitter <= 0 {
+		s.Jitter = defaultSimulatedJitter
+	}
+	s.Jitter = min(s.Jitter, 1)
+	return s
+}
+
+// NewSimulatedProcessor は何も処理せず、設定した処理時間だけ待って成功・失敗するプロセッサを作成する
+// 本番と同じ形のプールを外部への副作用なしに動かす（デモ・サンドボックス用）。コンテキストが終了すると待機を打ち切る
+func NewSimulatedProcessor(simulation Simulation) TaskProcessor {
+	simulation = simulation.withDefaults()
+	return func(ctx context.Context, task Task) error {
+		delta := (rand.Float64()*2 - 1) * simulation.Jitter * float64(simulation.Duration)
+		if err := Sleep(ctx, simulation.Duration+time.Duration(delta)); err != nil {
+			return err
+		}
+		if rand.Float64() < simulation.FailureRate {
+			return Retryable(fmt.Errorf("%w: タスク %d", ErrSimulatedFailure, task.ID))
+		}
+		return nil
+	}
+}
+
+// SimulateProcessors は登録済みのすべてのプロセッサをシミュレーターに置き換える（開始後も可能）
+// simulations にないタイプは fallback の設定を使う。置き換えたタイプの実行環境は simulator として /capabilities に表示する
+func SimulateProcessors(pool Pool, simulations map[TaskType]Simulation, fallback Simulation) error {
+	capabilities := DescribeCapabilities(pool).TaskTypes
+	for _, capability := range capabilities {
+		taskType := capability.TaskType
+		simulation, exists := simulations[taskType]
+		if !exists {
+			simulation = fallback
+		}
+		if err := pool.ReplaceProcessor(taskType, NewSimulatedProcessor(simulation)); err != nil {
+			return err
+		}
+		simulation = simulation.withDefaults()
+		detail := fmt.Sprintf("%v ±%.0f%%, 失敗率 %.0f%%", simulation.Duration, simulation.Jitter*100, simulation.FailureRate*100)
+		if err := DescribeExecutor(pool, taskType, ExecutorInfo{Kind: ExecutorSimulator, Detail: detail}); err != nil {
+			return err
+		}
+	}
+	event("sandbox.simulated").logf("🎭 %d 個のタスクタイプのプロセッサをシミュレーターに置き換えました\n", len(capabilities))
+	return nil
+}
+
+// SetReadOnly は管理系エンドポイントを読み取り専用にする（デモ・サンドボックス用）
+// 管理APIとダッシュボードの参照はそのまま使え、変更系のリクエスト（GET・HEAD 以外）は 403 を返す。
+// タスクの処理計画（POST /tasks?dry_run=true）はプールを変更しないため受け付ける
+func (m *Monitor) SetReadOnly(readOnly bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.readOnly = readOnly
+}
+
+// ReadOnly は読み取り専用モードかどうかを返す
+func (m *Monitor) ReadOnly() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.readOnly
+}
+
+// rejectReadOnly は読み取り専用モードで変更系のリクエストを拒否し、拒否した場合は true を返す
+func (m *Monitor) rejectReadOnly(w http.ResponseWriter, r *http.Request) bool {
+	if !m.ReadOnly() || r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return false
+	}
+	if r.URL.Path == "/tasks" && r.URL.Query().Get("dry_run") == "true" {
+		return false
+	}
+	writeJSONError(w, http.StatusForbidden, ErrReadOnly.Error())
+	return true
+}
@@ -0,0 +1,397 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidSchedule はスケジュールの設定が不正な場合のエラー
+	ErrInvalidSchedule = errors.New("スケジュールの設定が不正です")
+	// ErrScheduleExists は同じ名前のスケジュールが登録済みの場合のエラー
+	ErrScheduleExists = errors.New("同じ名前のスケジュールが登録済みです")
+)
+
+// scheduledTaskIDBase はスケジューラが採番するタスクIDの開始値（手動投入のIDと重ならないよう大きくとる）
+const scheduledTaskIDBase = 1 << 30
+
+// scheduleSearchLimit は営業日・営業時間の条件を満たす実行日時を探す最大回数
+const scheduleSearchLimit = 10000
+
+// ScheduleSpec はcron式で定期的に投入するタスクの定義
+type ScheduleSpec struct {
+	Name             string `json:"name"`
+	Cron             string `json:"cron"`                     // 分 時 日 月 曜日（@daily などの省略形も可）
+	TimeZone         string `json:"time_zone,omitempty"`      // cron式・営業日を評価するIANAタイムゾーン名（空の場合はローカル）
+	BusinessDaysOnly bool   `json:"business_days_only"`       // 休業日（カレンダーの週末・祝日）は実行しない
+	BusinessHours    string `json:"business_hours,omitempty"` // 実行してよい時間帯（HH:MM-HH:MM、空の場合は制限なし）
+	Task             Task   `json:"task"`                     // 投入するタスクのひな形（ID は実行ごとに採番）
+
+	// Template を指定した場合はテンプレートからタスクを作成する（Task の名前・ラベル・優先度などで上書きする）
+	// 優先度・タイムアウト・最大リトライ回数は Task でゼロ値以外を指定した場合のみテンプレートの既定値を上書きする
+	// 変数 date（実行日 YYYY-MM-DD）と schedule（スケジュール名）は自動で設定する
+	Template  string                 `json:"template,omitempty"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// ScheduleStatus はスケジュールの状態
+type ScheduleStatus struct {
+	ScheduleSpec
+	NextRun   time.Time `json:"next_run"`             // 次回実行日時（ゼロ値は予定なし）
+	LastRun   time.Time `json:"last_run"`             // 前回実行日時
+	LastError string    `json:"last_error,omitempty"` // 前回の投入エラー
+	Runs      int64     `json:"runs"`                 // 投入した回数
+	Skipped   int64     `json:"skipped"`              // ブラックアウト期間のため実行しなかった回数
+}
+
+// scheduleEntry は登録済みのスケジュール
+type scheduleEntry struct {
+	status     ScheduleStatus
+	cron       *CronSchedule
+	hoursStart time.Duration
+	hoursEnd   time.Duration
+	hasHours   bool
+}
+
+// Scheduler はcron式に従ってタスクをプールに投入する
+// 日時はスケジュールごとのタイムゾーンで評価し、営業日カレンダーで休業日をスキップできる
+type Scheduler struct {
+	pool     Pool
+	calendar Calendar // nil の場合は BusinessDaysOnly を指定できない
+
+	blackouts *BlackoutCalendar // nil の場合はブラックアウト期間なし
+	templates *TemplateRegistry // nil の場合はテンプレートを指定できない
+
+	mu      sync.Mutex
+	entries []*scheduleEntry
+	nextID  int
+	wake    chan struct{} // スケジュールの追加・削除を実行ループに通知
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	started bool
+}
+
+// NewScheduler はスケジューラを作成（calendar は nil 可）
+func NewScheduler(pool Pool, calendar Calendar) *Scheduler {
+	return &Scheduler{
+		pool:     pool,
+		calendar: calendar,
+		nextID:   scheduledTaskIDBase,
+		wake:     make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Add はスケジュールを登録する（開始後も可能）
+func (s *Scheduler) Add(spec ScheduleSpec) error {
+	if spec.Name == "" {
+		return fmt.Errorf("%w: name を指定してください", ErrInvalidSchedule)
+	}
+	location := time.Local
+	if spec.TimeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return fmt.Errorf("%w: %s のタイムゾーン: %v", ErrInvalidSchedule, spec.Name, err)
+		}
+	}
+	cron, err := ParseCron(spec.Cron, location)
+	if err != nil {
+		return fmt.Errorf("スケジュール %s: %w", spec.Name, err)
+	}
+	if spec.BusinessDaysOnly && s.calendar == nil {
+		return fmt.Errorf("%w: %s は営業日のみの指定ですがカレンダーが設定されていません", ErrInvalidSchedule, spec.Name)
+	}
+	if spec.Template != "" {
+		s.mu.Lock()
+		templates := s.templates
+		s.mu.Unlock()
+		if templates == nil {
+			return fmt.Errorf("%w: %s はテンプレートの指定ですがテンプレートが設定されていません", ErrInvalidSchedule, spec.Name)
+		}
+		if _, err := templates.Get(spec.Template); err != nil {
+			return fmt.Errorf("スケジュール %s: %w", spec.Name, err)
+		}
+	}
+
+	entry := &scheduleEntry{status: ScheduleStatus{ScheduleSpec: spec}, cron: cron}
+	if spec.BusinessHours != "" {
+		start, end, found := strings.Cut(spec.BusinessHours, "-")
+		if !found {
+			return fmt.Errorf("%w: %s の営業時間は HH:MM-HH:MM 形式で指定してください", ErrInvalidSchedule, spec.Name)
+		}
+		if entry.hoursStart, err = parseClock(strings.TrimSpace(start)); err == nil {
+			entry.hoursEnd, err = parseClock(strings.TrimSpace(end))
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s の営業時間: %v", ErrInvalidSchedule, spec.Name, err)
+		}
+		entry.hasHours = true
+	}
+
+	s.mu.Lock()
+	for _, existing := range s.entries {
+		if existing.status.Name == spec.Name {
+			s.mu.Unlock()
+			return fmt.Errorf("スケジュール %s: %w", spec.Name, ErrScheduleExists)
+		}
+	}
+	entry.status.NextRun = s.nextRun(entry, time.Now())
+	s.entries = append(s.entries, entry)
+	s.mu.Unlock()
+
+	s.notify()
+	return nil
+}
+
+// Remove はスケジュールを削除する（削除した場合は true）
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	removed := false
+	for i, entry := range s.entries {
+		if entry.status.Name == name {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if removed {
+		s.notify()
+	}
+	return removed
+}
+
+// Schedules は登録済みのスケジュールの状態を返す
+func (s *Scheduler) Schedules() []ScheduleStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]ScheduleStatus, len(s.entries))
+	for i, entry := range s.entries {
+		statuses[i] = entry.status
+	}
+	return statuses
+}
+
+// Start はスケジュールの実行を開始する
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+	s.wg.Add(1)
+	go s.run()
+	event("scheduler.started").logf("🗓️ スケジューラを開始しました (%d 件)\n", len(s.entries))
+}
+
+// Stop はスケジュールの実行を停止する
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.started = false
+	s.mu.Unlock()
+
+	close(s.stopCh)
+	s.wg.Wait()
+	event("scheduler.stopped").logln("🛑 スケジューラが停止しました")
+}
+
+// notify は実行ループに次回実行日時の再計算を促す
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run は次回実行日時まで待ち、到来したスケジュールのタスクを投入する
+func (s *Scheduler) run() {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			s.fireDue()
+		case <-s.wake:
+		case <-s.stopCh:
+			return
+		}
+
+		// 次に到来する実行日時までタイマーを設定する
+		timer.Stop()
+		select {
+		case <-timer.C:
+		default:
+		}
+		if wait, ok := s.untilNext(); ok {
+			timer.Reset(wait)
+		}
+	}
+}
+
+// untilNext は最も早い次回実行日時までの時間を返す（予定がない場合は false）
+func (s *Scheduler) untilNext() (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var earliest time.Time
+	for _, entry := range s.entries {
+		next := entry.status.NextRun
+		if !next.IsZero() && (earliest.IsZero() || next.Before(earliest)) {
+			earliest = next
+		}
+	}
+	if earliest.IsZero() {
+		return 0, false
+	}
+	return max(earliest.Sub(time.Now()), 0), true
+}
+
+// fireDue は実行日時が到来したスケジュールのタスクを投入する
+func (s *Scheduler) fireDue() {
+	now := time.Now()
+
+	s.mu.Lock()
+	var due []Task
+	var dueEntries []*scheduleEntry
+	var dueErrs []error
+	for _, entry := range s.entries {
+		if entry.status.NextRun.IsZero() || entry.status.NextRun.After(now) {
+			continue
+		}
+		task, err := s.newTask(entry)
+		if s.blackouts != nil {
+			if blackout, active := s.blackouts.Active(task.Type, now); active {
+				// 変更凍結中は後から投入せず、この回の実行をスキップする
+				event("schedule.skipped").logf("⛔ ブラックアウト %s のためスケジュール %s の実行をスキップしました\n", blackout.Name, entry.status.Name)
+				entry.status.Skipped++
+				entry.status.NextRun = s.nextRun(entry, now)
+				continue
+			}
+		}
+		task.ID = s.nextID
+		s.nextID++
+		due = append(due, task)
+		dueEntries = append(dueEntries, entry)
+		dueErrs = append(dueErrs, err)
+
+		entry.status.LastRun = entry.status.NextRun
+		entry.status.NextRun = s.nextRun(entry, now)
+	}
+	s.mu.Unlock()
+
+	for i, task := range due {
+		err := dueErrs[i]
+		if err == nil {
+			err = s.pool.AddTask(task)
+		}
+
+		s.mu.Lock()
+		entry := dueEntries[i]
+		entry.status.Runs++
+		entry.status.LastError = ""
+		if err != nil {
+			entry.status.LastError = err.Error()
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			event("schedule.submit_failed").taskOf(task).failed(err).logf("⚠️ スケジュール %s のタスク %d を投入できませんでした: %v\n", entry.status.Name, task.ID, err)
+			continue
+		}
+		event("schedule.submitted").taskOf(task).logf("🗓️ スケジュール %s のタスク %d を投入しました\n", entry.status.Name, task.ID)
+	}
+}
+
+// newTask は今回の実行で投入するタスクを作成する（ID は呼び出し元で採番する。s.mu を保持して呼び出すこと）
+func (s *Scheduler) newTask(entry *scheduleEntry) (Task, error) {
+	spec := entry.status.ScheduleSpec
+	task := spec.Task
+	if spec.Template != "" {
+		if s.templates == nil {
+			return task, fmt.Errorf("%w: %s", ErrTemplateNotFound, spec.Template)
+		}
+		vars := map[string]interface{}{
+			"date":     entry.status.NextRun.In(entry.cron.Location()).Format("2006-01-02"),
+			"schedule": spec.Name,
+		}
+		for name, value := range spec.Variables {
+			vars[name] = value
+		}
+		instantiated, err := s.templates.Instantiate(spec.Template, 0, vars)
+		if err != nil {
+			return task, err
+		}
+		if task.Name != "" {
+			instantiated.Name = task.Name
+		}
+		for key, value := range task.Labels {
+			if instantiated.Labels == nil {
+				instantiated.Labels = make(map[string]string)
+			}
+			instantiated.Labels[key] = value
+		}
+		if task.Priority != 0 {
+			instantiated.Priority = task.Priority
+		}
+		if task.Timeout > 0 {
+			instantiated.Timeout = task.Timeout
+		}
+		if task.MaxRetries != 0 {
+			instantiated.MaxRetries = task.MaxRetries
+		}
+		instantiated.Selector = task.Selector
+		instantiated.Sheddable = task.Sheddable
+		task = instantiated
+	}
+	if task.Name == "" {
+		task.Name = spec.Name
+	}
+	task.CreatedAt = time.Time{}
+	return task, nil
+}
+
+// nextRun は after より後で、営業日・営業時間の条件を満たす最初の実行日時を返す（s.mu を保持して呼び出すこと）
+func (s *Scheduler) nextRun(entry *scheduleEntry, after time.Time) time.Time {
+	t := after
+	for i := 0; i < scheduleSearchLimit; i++ {
+		t = entry.cron.Next(t)
+		if t.IsZero() {
+			return t
+		}
+		if s.allowed(entry, t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// allowed は実行日時がスケジュールの営業日・営業時間の条件を満たすか判定
+func (s *Scheduler) allowed(entry *scheduleEntry, t time.Time) bool {
+	local := t.In(entry.cron.Location())
+	if entry.status.BusinessDaysOnly && !s.calendar.IsBusinessDay(local) {
+		return false
+	}
+	if entry.hasHours {
+		clock := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+		if entry.hoursStart <= entry.hoursEnd {
+			return clock >= entry.hoursStart && clock < entry.hoursEnd
+		}
+		// 日をまたぐ時間帯
+		return clock >= entry.hoursStart || clock < entry.hoursEnd
+	}
+	return true
+}
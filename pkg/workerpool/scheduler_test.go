@@ -0,0 +1,61 @@
+package workerpool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSchedulerNextRunBusinessHours(t *testing.T) {
+	calendar := NewJapaneseCalendar(map[string]string{"2024-03-20": "春分の日"})
+
+	tests := []struct {
+		name             string
+		hours            string
+		businessDaysOnly bool
+		after            string // UTC の 2006-01-02 15:04
+		want             string
+	}{
+		{"before window", "09:00-17:00", false, "2024-03-19 07:30", "2024-03-19 09:00"},
+		{"end is exclusive", "09:00-17:00", false, "2024-03-19 16:30", "2024-03-20 09:00"},
+		{"across midnight before start", "22:00-06:00", false, "2024-03-19 10:30", "2024-03-19 22:00"},
+		{"across midnight after start", "22:00-06:00", false, "2024-03-19 23:30", "2024-03-20 00:00"},
+		{"across midnight before end", "22:00-06:00", false, "2024-03-19 04:30", "2024-03-19 05:00"},
+		{"across midnight end is exclusive", "22:00-06:00", false, "2024-03-19 05:00", "2024-03-19 22:00"},
+		{"across midnight skips holiday", "22:00-06:00", true, "2024-03-19 23:30", "2024-03-21 00:00"},
+		{"across midnight skips weekend", "22:00-06:00", true, "2024-03-22 23:30", "2024-03-25 00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(nil, calendar)
+			err := s.Add(ScheduleSpec{
+				Name:             tt.name,
+				Cron:             "0 * * * *",
+				TimeZone:         "UTC",
+				BusinessDaysOnly: tt.businessDaysOnly,
+				BusinessHours:    tt.hours,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			after, err := time.Parse("2006-01-02 15:04", tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := s.nextRun(s.entries[0], after)
+			if s := got.Format("2006-01-02 15:04"); s != tt.want {
+				t.Errorf("nextRun(%s) = %s, want %s", tt.after, s, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerAddInvalidBusinessHours(t *testing.T) {
+	for _, hours := range []string{"22:00", "25:00-06:00", "09:00-", "9時-17時"} {
+		s := NewScheduler(nil, nil)
+		err := s.Add(ScheduleSpec{Name: "hours", Cron: "@hourly", BusinessHours: hours})
+		if !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("BusinessHours %q: error = %v, want ErrInvalidSchedule", hours, err)
+		}
+	}
+}
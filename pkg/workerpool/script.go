@@ -0,0 +1,206 @@
+package workerpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/d5/tengo/v2"
+	"github.com/d5/tengo/v2/stdlib"
+)
+
+// ScriptConfig はスクリプトで定義するプロセッサの設定
+// スクリプトは Tengo（https://github.com/d5/tengo）で記述し、変数 task でタスクを受け取る
+//
+//	{"type": "greet", "script": "if !task.payload.name { fail(\"name がありません\") }\nprint(\"Hello, \" + task.payload.name)"}
+type ScriptConfig struct {
+	Type   TaskType `json:"type"`
+	Script string   `json:"script"`
+}
+
+// scriptModules はスクリプトから import できる標準モジュール
+// ファイル・プロセスを扱う os と標準出力に書き込む fmt は使わせない
+var scriptModules = []string{"base64", "enum", "hex", "json", "math", "rand", "text", "times"}
+
+// scriptFailure はスクリプトが fail・retry で返したエラー（位置情報を付けずに返すため区別する）
+type scriptFailure struct {
+	err error
+}
+
+func (f *scriptFailure) Error() string { return f.err.Error() }
+func (f *scriptFailure) Unwrap() error { return f.err }
+
+// NewScriptProcessor はスクリプトを実行するプロセッサを作成する
+//
+// スクリプトには次の変数・関数を渡す。
+//   - task: id・name・type・payload（JSONとしてデコードし直した値）・labels・attempt（試行回数、1から）
+//   - print(値...): 出力に1行書き込む（TaskOutput に書き込み、TaskResult.Output に残る）
+//   - fail(メッセージ): タスクを失敗させる（リトライしない）
+//   - retry(メッセージ): タスクをリトライ対象のエラーで失敗させる
+//
+// 出力は out を指定した場合はそちらにも書き込む。ctx がキャンセルされると実行を中断する
+func NewScriptProcessor(script string, out io.Writer) (TaskProcessor, error) {
+	s := tengo.NewScript([]byte(script))
+	s.SetImports(stdlib.GetModuleMap(scriptModules...))
+	for _, name := range []string{"task", "print", "fail", "retry"} {
+		if err := s.Add(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	compiled, err := s.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("スクリプトの構文が不正です: %w", err)
+	}
+
+	return func(ctx context.Context, task Task) error {
+		value, err := scriptTask(task)
+		if err != nil {
+			return fmt.Errorf("ペイロードをスクリプトに渡せません: %w", err)
+		}
+
+		output := TaskOutput(ctx)
+		if out != nil {
+			output = io.MultiWriter(output, &prefixWriter{out: out, prefix: fmt.Sprintf("📜 タスク %d (%s): ", task.ID, task.Type)})
+		}
+
+		// 同じプロセッサを複数のワーカーが同時に実行できるよう、実行ごとに複製する
+		run := compiled.Clone()
+		for name, value := range map[string]interface{}{
+			"task":  value,
+			"print": scriptPrint(output),
+			"fail":  scriptFail(func(message string) error { return errors.New(message) }),
+			"retry": scriptFail(func(message string) error { return Retryable(errors.New(message)) }),
+		} {
+			if err := run.Set(name, value); err != nil {
+				return fmt.Errorf("スクリプトに %s を渡せません: %w", name, err)
+			}
+		}
+
+		if err := run.RunContext(ctx); err != nil {
+			return scriptError(ctx, err)
+		}
+		return nil
+	}, nil
+}
+
+// RegisterScripts は設定ファイルのスクリプトをプロセッサとして登録する
+func RegisterScripts(pool Pool, scripts []ScriptConfig) error {
+	for _, script := range scripts {
+		if script.Type == "" {
+			return fmt.Errorf("スクリプトの type を指定してください")
+		}
+		processor, err := NewScriptProcessor(script.Script, nil)
+		if err != nil {
+			return fmt.Errorf("タスクタイプ %s: %w", script.Type, err)
+		}
+		if err := pool.RegisterProcessor(script.Type, processor); err != nil {
+			return err
+		}
+		if err := DescribeExecutor(pool, script.Type, ExecutorInfo{Kind: ExecutorScript}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scriptTask はスクリプトに渡す task の値を作成する
+func scriptTask(task Task) (map[string]interface{}, error) {
+	payload, err := toScriptValue(task.Payload)
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]interface{}, len(task.Labels))
+	for key, value := range task.Labels {
+		labels[key] = value
+	}
+	return map[string]interface{}{
+		"id":      task.ID,
+		"name":    task.Name,
+		"type":    string(task.Type),
+		"payload": payload,
+		"labels":  labels,
+		"attempt": task.AttemptCount + 1,
+	}, nil
+}
+
+// toScriptValue はペイロードをJSON経由で汎用の値に変換する（構造体のフィールドを JSON 名で参照できるように）
+func toScriptValue(payload interface{}) (interface{}, error) {
+	if payload == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// scriptPrint は引数を空白で区切って1行として出力する print を返す
+func scriptPrint(output io.Writer) *tengo.UserFunction {
+	return &tengo.UserFunction{Name: "print", Value: func(args ...tengo.Object) (tengo.Object, error) {
+		parts := make([]string, len(args))
+		for i, arg := range args {
+			if s, ok := arg.(*tengo.String); ok {
+				parts[i] = s.Value
+			} else {
+				parts[i] = arg.String()
+			}
+		}
+		fmt.Fprintln(output, strings.Join(parts, " "))
+		return tengo.UndefinedValue, nil
+	}}
+}
+
+// scriptFail はメッセージから作ったエラーでスクリプトを終了させる関数を返す
+func scriptFail(newError func(message string) error) *tengo.UserFunction {
+	return &tengo.UserFunction{Value: func(args ...tengo.Object) (tengo.Object, error) {
+		if len(args) != 1 {
+			return nil, tengo.ErrWrongNumArguments
+		}
+		message, ok := tengo.ToString(args[0])
+		if !ok {
+			return nil, tengo.ErrInvalidArgumentType{Name: "message", Expected: "string", Found: args[0].TypeName()}
+		}
+		return nil, &scriptFailure{err: newError(message)}
+	}}
+}
+
+// scriptError はスクリプトのエラーをプロセッサのエラーに変換する
+// fail・retry のエラーは位置情報を取り除き、中断した場合は ctx のエラーを返す
+func scriptError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var failure *scriptFailure
+	if errors.As(err, &failure) {
+		return failure.err
+	}
+	return fmt.Errorf("スクリプトの実行に失敗しました: %w", err)
+}
+
+// prefixWriter は書き込まれた各行の先頭に prefix を付けて out に書き込む
+type prefixWriter struct {
+	out    io.Writer
+	prefix string
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	lines := strings.SplitAfter(string(p), "\n")
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		if _, err := io.WriteString(w.out, w.prefix+line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,131 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// スクリプトは制御構文・演算を使え、print の出力はタスクの出力に残る
+func TestScriptProcessorOutput(t *testing.T) {
+	processor, err := NewScriptProcessor(`
+total := 0
+for item in task.payload.items {
+	if item.qty > 0 {
+		total += item.qty * item.price
+	}
+}
+print("order", task.id, "total", total, "attempt", task.attempt, task.labels.region)
+`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	task := Task{
+		ID:     42,
+		Type:   "order",
+		Labels: map[string]string{"region": "ap"},
+		Payload: map[string]interface{}{"items": []map[string]int{
+			{"qty": 2, "price": 150},
+			{"qty": 0, "price": 999},
+			{"qty": 1, "price": 300},
+		}},
+	}
+	if err := processor(WithTaskOutput(context.Background(), &output), task); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := output.String(), "order 42 total 600 attempt 1 ap\n"; got != want {
+		t.Errorf("出力 = %q, want %q", got, want)
+	}
+}
+
+// fail はリトライしないエラー、retry はリトライ対象のエラーとしてそのメッセージで失敗する
+func TestScriptProcessorErrors(t *testing.T) {
+	script := `
+if !task.payload.name { fail("name がありません") }
+if task.attempt < 3 { retry("まだ準備ができていません") }
+`
+	processor, err := NewScriptProcessor(script, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = processor(context.Background(), Task{ID: 1, Payload: map[string]string{}})
+	if err == nil || err.Error() != "name がありません" || IsRetryable(err) {
+		t.Errorf("fail = %v (retryable=%v)", err, IsRetryable(err))
+	}
+	err = processor(context.Background(), Task{ID: 1, Payload: map[string]string{"name": "a"}})
+	if err == nil || err.Error() != "まだ準備ができていません" || !IsRetryable(err) {
+		t.Errorf("retry = %v (retryable=%v)", err, IsRetryable(err))
+	}
+	if err := processor(context.Background(), Task{ID: 1, AttemptCount: 2, Payload: map[string]string{"name": "a"}}); err != nil {
+		t.Errorf("3回目 = %v, want nil", err)
+	}
+
+	if _, err := NewScriptProcessor(`if {`, nil); err == nil {
+		t.Error("構文が不正なスクリプトを受け付けました")
+	}
+	if _, err := NewScriptProcessor(`os := import("os")`, nil); err == nil {
+		t.Error("os モジュールを import できました")
+	}
+}
+
+// 終わらないスクリプトも ctx のキャンセル・タイムアウトで中断する
+func TestScriptProcessorHonoursContext(t *testing.T) {
+	processor, err := NewScriptProcessor(`for { }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- processor(ctx, Task{ID: 1}) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("err = %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("スクリプトが中断されませんでした")
+	}
+}
+
+// プールで実行したスクリプトの出力は TaskResult.Output に含まれ、並行して実行できる
+func TestScriptProcessorResultOutput(t *testing.T) {
+	pool := NewWorkerPool(4)
+	if err := RegisterScripts(pool, []ScriptConfig{{Type: "greet", Script: `print("Hello, " + task.payload.name)`}}); err != nil {
+		t.Fatal(err)
+	}
+	results := make(chan TaskResult, 8)
+	pool.OnResult(func(result TaskResult) {
+		if result.IsFinal {
+			results <- result
+		}
+	})
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Stop()
+
+	for id := 1; id <= 8; id++ {
+		if err := pool.AddTask(Task{ID: id, Type: "greet", Payload: map[string]string{"name": strings.Repeat("x", id)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 8; i++ {
+		select {
+		case result := <-results:
+			want := "Hello, " + strings.Repeat("x", result.TaskID) + "\n"
+			if !result.Success || result.Output != want {
+				t.Errorf("タスク %d: success=%v output=%q, want %q (%v)", result.TaskID, result.Success, result.Output, want, result.Error)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("結果が届きませんでした")
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxShadowRecords は保持するシャドー実行の記録数（超えた場合は古いものから削除）
+const maxShadowRecords = 1000
+
+// ShadowOutcome は1回の実行の結果
+type ShadowOutcome struct {
+	Success  bool          `json:"success"`
+	Error    string        `json:"error,omitempty"`
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// ShadowRecord は本番のプロセッサとシャドーのプロセッサの実行結果の組
+type ShadowRecord struct {
+	TaskID   int           `json:"task_id"`
+	TaskType TaskType      `json:"task_type"`
+	Attempt  int           `json:"attempt"`
+	Primary  ShadowOutcome `json:"primary"`
+	Shadow   ShadowOutcome `json:"shadow"`
+	Match    bool          `json:"match"` // 成否・エラー・出力がすべて一致したか
+	RecordAt time.Time     `json:"recorded_at"`
+}
+
+// ShadowStats はタスクタイプごとのシャドー実行の集計
+type ShadowStats struct {
+	Executed   int64 `json:"executed"`   // シャドーで実行した数
+	Mismatched int64 `json:"mismatched"` // 本番と結果が異なった数
+	Skipped    int64 `json:"skipped"`    // 同時実行数の上限で実行しなかった数
+
+	PrimaryAvgTime float64 `json:"primary_avg_time_ms"` // 本番の平均処理時間
+	ShadowAvgTime  float64 `json:"shadow_avg_time_ms"`  // シャドーの平均処理時間
+}
+
+// shadowState はシャドー実行の設定と記録
+type shadowState struct {
+	mu         sync.Mutex
+	processors map[TaskType]TaskProcessor
+	sem        chan struct{} // シャドーの同時実行数の上限
+	records    []ShadowRecord
+	stats      map[TaskType]ShadowStats
+}
+
+// SetShadow はタスクタイプのタスクを本番のプロセッサに加えてシャドーのプロセッサでも実行する
+// シャドーの結果は記録されるだけで、呼び出し元やリトライ判定には影響しない
+// シャドーは別のゴルーチンで実行されるため本番のレイテンシには影響しないが、
+// 外部に副作用を持たない（読み取り専用・テスト環境向けの）プロセッサを指定すること
+func (wp *WorkerPool) SetShadow(taskType TaskType, processor TaskProcessor) error {
+	wp.mu.Lock()
+	_, exists := wp.processors[taskType]
+	wp.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+
+	wp.shadows.mu.Lock()
+	defer wp.shadows.mu.Unlock()
+
+	wp.shadows.processors[taskType] = processor
+	event("shadow.started").logf("👥 タスクタイプ %s のシャドー実行を開始しました\n", taskType)
+	return nil
+}
+
+// ClearShadow はシャドー実行を終了する
+func (wp *WorkerPool) ClearShadow(taskType TaskType) {
+	wp.shadows.mu.Lock()
+	defer wp.shadows.mu.Unlock()
+
+	delete(wp.shadows.processors, taskType)
+}
+
+// ShadowRecords はシャドー実行の記録（新しいものが後ろ）を返す
+func (wp *WorkerPool) ShadowRecords() []ShadowRecord {
+	wp.shadows.mu.Lock()
+	defer wp.shadows.mu.Unlock()
+
+	records := make([]ShadowRecord, len(wp.shadows.records))
+	copy(records, wp.shadows.records)
+	return records
+}
+
+// ShadowStats はタスクタイプごとのシャドー実行の集計を返す
+func (wp *WorkerPool) ShadowStats() map[TaskType]ShadowStats {
+	wp.shadows.mu.Lock()
+	defer wp.shadows.mu.Unlock()
+
+	stats := make(map[TaskType]ShadowStats, len(wp.shadows.stats))
+	for taskType, s := range wp.shadows.stats {
+		stats[taskType] = s
+	}
+	return stats
+}
+
+// runShadow は本番の実行結果とあわせて、シャドーのプロセッサでタスクを実行する
+func (wp *WorkerPool) runShadow(task Task, primary ShadowOutcome) {
+	s := &wp.shadows
+	s.mu.Lock()
+	processor, enabled := s.processors[task.Type]
+	if !enabled {
+		s.mu.Unlock()
+		return
+	}
+	select {
+	case s.sem <- struct{}{}:
+	default:
+		// シャドーが詰まっている場合は本番を遅らせないよう実行しない
+		stats := s.stats[task.Type]
+		stats.Skipped++
+		s.stats[task.Type] = stats
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+
+	wp.bgWg.Add(1)
+	go func() {
+		defer wp.bgWg.Done()
+		defer func() { <-s.sem }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), wp.timeoutFor(task))
+		defer cancel()
+		output := &outputBuffer{}
+		start := time.Now()
+		err := processor(WithTaskOutput(ctx, output), task)
+
+		shadow := ShadowOutcome{
+			Success:  err == nil,
+			Output:   output.String(),
+			Duration: time.Since(start),
+		}
+		if err != nil {
+			shadow.Error = err.Error()
+		}
+		s.record(ShadowRecord{
+			TaskID:   task.ID,
+			TaskType: task.Type,
+			Attempt:  task.AttemptCount + 1,
+			Primary:  primary,
+			Shadow:   shadow,
+			Match:    primary.Success == shadow.Success && primary.Error == shadow.Error && primary.Output == shadow.Output,
+			RecordAt: time.Now(),
+		})
+	}()
+}
+
+// record は記録を追加して集計する
+func (s *shadowState) record(record ShadowRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats[record.TaskType]
+	stats.Executed++
+	n := float64(stats.Executed)
+	stats.PrimaryAvgTime = (stats.PrimaryAvgTime*(n-1) + durationToMs(record.Primary.Duration)) / n
+	stats.ShadowAvgTime = (stats.ShadowAvgTime*(n-1) + durationToMs(record.Shadow.Duration)) / n
+	if !record.Match {
+		stats.Mismatched++
+		event("shadow.mismatch").task(record.TaskID).logf("👥 タスク %d のシャドー実行の結果が本番と異なります (本番: %v, シャドー: %v)\n",
+			record.TaskID, record.Primary.Success, record.Shadow.Success)
+	}
+	s.stats[record.TaskType] = stats
+
+	s.records = append(s.records, record)
+	if len(s.records) > maxShadowRecords {
+		s.records = s.records[len(s.records)-maxShadowRecords:]
+	}
+}
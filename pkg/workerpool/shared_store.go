@@ -0,0 +1,141 @@
+package workerpool
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// SharedFileStore は複数のプロセスで共有できるファイルのタスクストア（LeaseStore）
+// 操作のたびにロックファイル（path + ".lock"）をファイルロックで排他し、最新の内容を読み直してから
+// 書き込むため、リースの取得・延長・コミットはフェンシングトークンの比較と更新を1回の操作として行う
+// （compare-and-swap）。同じホスト、またはファイルロックが正しく働く共有ファイルシステムで使うこと
+type SharedFileStore struct {
+	mu   sync.Mutex // 同じプロセス内の操作を直列化する（ファイルロックはプロセス間の排他）
+	path string
+}
+
+// NewSharedFileStore は共有ファイルストアを作成する
+// ファイルは最初の書き込みで作られ、既存のファイルがあればそのまま使う
+func NewSharedFileStore(path string) (*SharedFileStore, error) {
+	s := &SharedFileStore{path: path}
+	// 読み込めない・ロックできない場合は作成時にエラーにする
+	if err := s.view(func(map[int]TaskRecord) {}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save はタスクの記録を保存する
+func (s *SharedFileStore) Save(record TaskRecord) error {
+	return s.update(func(records map[int]TaskRecord) (bool, error) {
+		saveRecord(records, record)
+		return true, nil
+	})
+}
+
+// LoadPending は終了していないタスクの記録をID順に返す
+func (s *SharedFileStore) LoadPending() ([]TaskRecord, error) {
+	var pending []TaskRecord
+	err := s.view(func(records map[int]TaskRecord) {
+		pending = pendingRecords(records)
+	})
+	return pending, err
+}
+
+// Lease はタスクを owner に貸し出す
+func (s *SharedFileStore) Lease(task Task, owner string, until time.Time) (uint64, bool, error) {
+	var token uint64
+	var leased bool
+	err := s.update(func(records map[int]TaskRecord) (bool, error) {
+		token, leased = leaseRecord(records, task, owner, until)
+		return leased, nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return token, leased, nil
+}
+
+// Renew はトークンが最新の場合に限りリースを延長する
+func (s *SharedFileStore) Renew(taskID int, token uint64, until time.Time) (bool, error) {
+	var renewed bool
+	err := s.update(func(records map[int]TaskRecord) (bool, error) {
+		renewed = renewRecord(records, taskID, token, until)
+		return renewed, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return renewed, nil
+}
+
+// Commit はトークンが最新の場合に限り実行結果を保存する（古い場合は ErrStaleLease）
+func (s *SharedFileStore) Commit(record TaskRecord, token uint64) error {
+	return s.update(func(records map[int]TaskRecord) (bool, error) {
+		if err := commitRecord(records, record, token); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+// ReclaimExpired は放置されたタスクを owner に貸し出し直して返す
+func (s *SharedFileStore) ReclaimExpired(owner string, now time.Time, visibilityTimeout time.Duration) ([]TaskRecord, error) {
+	var reclaimed []TaskRecord
+	err := s.update(func(records map[int]TaskRecord) (bool, error) {
+		reclaimed = reclaimRecords(records, owner, now, visibilityTimeout)
+		return len(reclaimed) > 0, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reclaimed, nil
+}
+
+// view はファイルを共有ロックして読み込んだ記録を fn に渡す
+func (s *SharedFileStore) view(fn func(records map[int]TaskRecord)) error {
+	return s.withLock(false, func() error {
+		records, err := readRecordsFile(s.path)
+		if err != nil {
+			return err
+		}
+		fn(records)
+		return nil
+	})
+}
+
+// update はファイルを排他ロックして読み込んだ記録を fn で更新し、変更があれば書き出す
+func (s *SharedFileStore) update(fn func(records map[int]TaskRecord) (bool, error)) error {
+	return s.withLock(true, func() error {
+		records, err := readRecordsFile(s.path)
+		if err != nil {
+			return err
+		}
+		changed, err := fn(records)
+		if err != nil || !changed {
+			return err
+		}
+		return writeRecordsFile(s.path, records)
+	})
+}
+
+// withLock はロックファイルをロックした状態で fn を呼び出す
+func (s *SharedFileStore) withLock(exclusive bool, fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lockFile, err := os.OpenFile(s.path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("タスクストアのロックファイルを開けません: %w", err)
+	}
+	defer lockFile.Close()
+
+	if err := lockFileDescriptor(lockFile, exclusive); err != nil {
+		return fmt.Errorf("タスクストアをロックできません: %w", err)
+	}
+	defer unlockFileDescriptor(lockFile)
+
+	return fn()
+}
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package workerpool
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// lockFileDescriptor はファイルロックに対応していない環境ではエラーを返す
+func lockFileDescriptor(file *os.File, exclusive bool) error {
+	return fmt.Errorf("SharedFileStore は %s では使えません", runtime.GOOS)
+}
+
+// unlockFileDescriptor はファイルロックに対応していない環境では何もしない
+func unlockFileDescriptor(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package workerpool
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFileDescriptor はファイルをロックする（他のプロセスが保持している間は待つ）
+func lockFileDescriptor(file *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	for {
+		err := syscall.Flock(int(file.Fd()), how)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+// unlockFileDescriptor はファイルのロックを解除する
+func unlockFileDescriptor(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
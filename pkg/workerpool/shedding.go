@@ -0,0 +1,169 @@
+package workerpool
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// ShedAction は負荷が閾値を超えた際の任意タスクの扱い
+type ShedAction int
+
+const (
+	// ShedDrop は任意タスクを破棄してDLQに記録する
+	ShedDrop ShedAction = iota
+	// ShedDefer は任意タスクを保留し、負荷が下がった時点でキューに戻す
+	ShedDefer
+)
+
+// memorySampleInterval はメモリ使用量を再取得する間隔
+const memorySampleInterval = 1 * time.Second
+
+// ShedPolicy は任意タスク（Sheddable）の負荷制御ポリシー
+type ShedPolicy struct {
+	MaxQueueDepth int        // キュー滞留数の閾値（0で無効）
+	MaxHeapBytes  uint64     // ヒープ使用量の閾値（0で無効）
+	Action        ShedAction // 閾値超過時の動作
+}
+
+// SetShedPolicy は負荷制御ポリシーを設定する（nil で無効化）
+func (wp *WorkerPool) SetShedPolicy(policy *ShedPolicy) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.shedPolicy = policy
+}
+
+// DeadLetters はDLQの内容を返す
+func (wp *WorkerPool) DeadLetters() []DeadLetter {
+	return wp.dlq.List()
+}
+
+// DeferredCount は負荷制御で保留中のタスク数を返す
+func (wp *WorkerPool) DeferredCount() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return len(wp.deferred)
+}
+
+// shed は負荷が閾値を超えている場合に任意タスクを破棄または保留する
+// タスクを処理した場合は true を返す（呼び出し側はキューに投入しない）
+func (wp *WorkerPool) shed(task Task) (bool, error) {
+	action, shedding := wp.shedAction(task)
+	if !shedding {
+		return false, nil
+	}
+
+	switch action {
+	case ShedDefer:
+		wp.mu.Lock()
+		wp.deferred = append(wp.deferred, task)
+		wp.mu.Unlock()
+		atomic.AddInt64(&wp.admissionStats.Deferred, 1)
+		event("task.deferred").taskOf(task).logf("⏸️ 高負荷のためタスク %d を保留しました\n", task.ID)
+		return true, nil
+	default:
+		wp.dlq.Add(task, DeadLetterShed, ErrTaskShed)
+		atomic.AddInt64(&wp.admissionStats.Shed, 1)
+		event("task.shed").taskOf(task).logf("🗑️ 高負荷のためタスク %d を破棄しDLQに記録しました\n", task.ID)
+		return true, ErrTaskShed
+	}
+}
+
+// shedAction は任意タスクを負荷制御の対象とする場合に、その動作を返す
+func (wp *WorkerPool) shedAction(task Task) (ShedAction, bool) {
+	if !task.Sheddable {
+		return 0, false
+	}
+
+	wp.mu.Lock()
+	policy := wp.shedPolicy
+	wp.mu.Unlock()
+
+	if policy == nil || !wp.underPressure(policy) {
+		return 0, false
+	}
+	return policy.Action, true
+}
+
+// underPressure はキュー滞留数またはヒープ使用量が閾値を超えているか判定
+// wp.mu を取るため、ロックを保持したまま呼び出さないこと
+func (wp *WorkerPool) underPressure(policy *ShedPolicy) bool {
+	if policy.MaxQueueDepth > 0 && wp.queuedTasks() >= policy.MaxQueueDepth {
+		return true
+	}
+	if policy.MaxHeapBytes > 0 && wp.heapAlloc() >= policy.MaxHeapBytes {
+		return true
+	}
+	return false
+}
+
+// heapAlloc は直近のヒープ使用量を返す（ReadMemStats の負荷を抑えるため一定間隔でキャッシュ）
+func (wp *WorkerPool) heapAlloc() uint64 {
+	now := time.Now().UnixNano()
+	if now-atomic.LoadInt64(&wp.memSampledAt) < int64(memorySampleInterval) {
+		return atomic.LoadUint64(&wp.memHeapAlloc)
+	}
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	atomic.StoreUint64(&wp.memHeapAlloc, memStats.HeapAlloc)
+	atomic.StoreInt64(&wp.memSampledAt, now)
+	return memStats.HeapAlloc
+}
+
+// deferredReleaser は負荷が下がった時点やメンテナンスが終わった時点で保留中のタスクをキューに戻す
+func (wp *WorkerPool) deferredReleaser() {
+	defer wp.bgWg.Done()
+
+	ticker := time.NewTicker(memorySampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			wp.releaseDeferred()
+			wp.releaseHeld()
+		case <-wp.shutdownCh:
+			// 停止時に残った保留タスクは失われないようDLQに記録
+			wp.mu.Lock()
+			deferred := append(wp.deferred, wp.held...)
+			wp.deferred = nil
+			wp.held = nil
+			wp.mu.Unlock()
+			for _, task := range deferred {
+				wp.dlq.Add(task, DeadLetterShutdown, ErrPoolStopped)
+			}
+			return
+		}
+	}
+}
+
+// releaseDeferred は負荷が閾値を下回っている間、保留中のタスクを順にキューへ戻す
+func (wp *WorkerPool) releaseDeferred() {
+	for {
+		// underPressure は wp.mu を取るため、ロックの外で判定する
+		wp.mu.Lock()
+		policy := wp.shedPolicy
+		wp.mu.Unlock()
+		if policy != nil && wp.underPressure(policy) {
+			return
+		}
+
+		wp.mu.Lock()
+		if len(wp.deferred) == 0 {
+			wp.mu.Unlock()
+			return
+		}
+		task := wp.deferred[0]
+		wp.deferred = wp.deferred[1:]
+		wp.mu.Unlock()
+
+		if err := wp.enqueue(task); err != nil {
+			wp.dlq.Add(task, DeadLetterShutdown, ErrPoolStopped)
+			return
+		}
+		event("task.released").taskOf(task).logf("▶️ 保留中のタスク %d をキューに戻しました\n", task.ID)
+	}
+}
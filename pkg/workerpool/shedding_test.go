@@ -0,0 +1,77 @@
+package workerpool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// 保留したタスクを戻す判定（underPressure）が wp.mu を取り直してデッドロックしないこと
+func TestReleaseDeferredDoesNotDeadlock(t *testing.T) {
+	pool := NewWorkerPool(1)
+	unblock := make(chan struct{})
+	pool.RegisterProcessor("slow", func(ctx context.Context, task Task) error {
+		<-unblock
+		return nil
+	})
+	pool.SetShedPolicy(&ShedPolicy{MaxQueueDepth: 1, Action: ShedDefer})
+
+	done := make(chan int, 3)
+	pool.OnResult(func(result TaskResult) {
+		if result.IsFinal {
+			done <- result.TaskID
+		}
+	})
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 1件目でワーカーを塞ぎ、2件目でキューを閾値まで埋める
+	for id := 1; id <= 2; id++ {
+		if err := pool.AddTask(Task{ID: id, Type: "slow"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool { return pool.queuedTasks() == 1 })
+	if err := pool.AddTask(Task{ID: 3, Type: "slow", Sheddable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := pool.DeferredCount(); got != 1 {
+		t.Fatalf("DeferredCount = %d, want 1", got)
+	}
+
+	// 保留の解除の判定が少なくとも1回走るまで待つ
+	time.Sleep(memorySampleInterval + 200*time.Millisecond)
+	locked := make(chan int)
+	go func() { locked <- pool.DeferredCount() }()
+	select {
+	case <-locked:
+	case <-time.After(2 * time.Second):
+		t.Fatal("wp.mu deadlocked")
+	}
+
+	close(unblock)
+	seen := make(map[int]bool)
+	timeout := time.After(5 * time.Second)
+	for len(seen) < 3 {
+		select {
+		case id := <-done:
+			seen[id] = true
+		case <-timeout:
+			t.Fatalf("保留したタスクが実行されませんでした: completed=%v", seen)
+		}
+	}
+	pool.Stop()
+}
+
+// waitFor は cond が true になるまで待つ
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("条件が満たされませんでした")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
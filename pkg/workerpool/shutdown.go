@@ -0,0 +1,175 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// drainPollInterval は Shutdown が残りのタスクを確認する間隔
+const drainPollInterval = 20 * time.Millisecond
+
+// inFlightTask は実行中のタスクと、そのタスクを中断するためのキャンセル関数
+type inFlightTask struct {
+	task          Task
+	cancel        context.CancelCauseFunc
+	slot          *checkpointSlot
+	checkpointing bool // Shutdown でチェックポイントの保存を求めた
+	aborted       bool
+}
+
+// trackInFlight は実行中のタスクを記録する
+// 返す関数は実行の終了時にプロセッサのエラーを渡して呼び出し、Shutdown で中断された場合は true を返す
+// チェックポイントの保存を求められた後にエラーで戻ったタスクも中断したものとして扱う
+func (wp *WorkerPool) trackInFlight(task Task, cancel context.CancelCauseFunc, slot *checkpointSlot) func(err error) bool {
+	entry := &inFlightTask{task: task, cancel: cancel, slot: slot}
+	wp.mu.Lock()
+	wp.inFlight[entry] = struct{}{}
+	wp.mu.Unlock()
+
+	return func(err error) bool {
+		wp.mu.Lock()
+		defer wp.mu.Unlock()
+
+		delete(wp.inFlight, entry)
+		if entry.checkpointing && !entry.aborted && err != nil {
+			entry.aborted = true
+			wp.checkpointed = append(wp.checkpointed, entry.withCheckpoint())
+		}
+		return entry.aborted
+	}
+}
+
+// withCheckpoint は直近のチェックポイントを付けたタスクを返す
+func (entry *inFlightTask) withCheckpoint() Task {
+	task := entry.task
+	task.Checkpoint = entry.slot.latest()
+	return task
+}
+
+// abortInFlight は実行中のタスクのコンテキストをキャンセルし、中断したタスクを返す
+func (wp *WorkerPool) abortInFlight() []Task {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	var aborted []Task
+	for entry := range wp.inFlight {
+		entry.aborted = true
+		entry.cancel(nil)
+		aborted = append(aborted, entry.withCheckpoint())
+	}
+	return aborted
+}
+
+// drained はキュー待ち・実行中・リトライ待ち・保留中・予定時刻待ちのタスクがないか判定
+func (wp *WorkerPool) drained() bool {
+	if wp.retries.len() > 0 || wp.delayed.len() > 0 {
+		return false
+	}
+	// 専用レーン・名前付きキューに並んでいるタスクはキュー滞留数に数えられるが、専用ワーカーは running に含まれない
+	if _, busy := wp.heatTracker().pending(); busy > 0 {
+		return false
+	}
+	if _, busy := wp.queueRouter().pending(); busy > 0 {
+		return false
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	// 取り出し直後のタスクはキュー滞留数に、実行中のタスクはタスク待ちでないワーカーとして数えられる
+	return len(wp.queuedByType) == 0 && wp.running == wp.idle && len(wp.deferred) == 0 && len(wp.held) == 0
+}
+
+// waitDrained は残りのタスクがなくなるか ctx が終了するまで待つ
+func (wp *WorkerPool) waitDrained(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	// リトライ待ち・保留からキューへ移す間はどこにも数えられないため、2回続けて空の場合に完了とみなす
+	for empty := 0; empty < 2; {
+		select {
+		case <-ticker.C:
+			if wp.drained() {
+				empty++
+			} else {
+				empty = 0
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Shutdown は新しいタスクの受付を止め、キュー待ち・実行中・リトライ待ち・保留中・予定時刻待ちのタスクが終わるのを待ってから停止する
+// ctx が終了するまでに終わらなかったタスクはプールから取り除き（実行中のタスクはコンテキストをキャンセルして中断する）、
+// 未完了のタスクとして ctx.Err() とともに返す。未完了のタスクはDLQに送らず、結果も通知しない。
+// SetCheckpointGrace を設定している場合、実行中のタスクは中断の前にチェックポイントを保存でき、返すタスクの Checkpoint に入る。
+// Stop と異なり、停止処理中もリトライ待ちのタスクを再実行する。停止処理中・停止済みの場合は ErrInvalidStateTransition を返す
+func (wp *WorkerPool) Shutdown(ctx context.Context) ([]Task, error) {
+	from, err := wp.lifecycle.transition(StateDraining, StateCreated, StateRunning, StatePaused)
+	if err != nil {
+		return nil, err
+	}
+	event("pool.stopping").logln("🔄 ワーカープールを停止中（残りのタスクの完了を待ちます）...")
+
+	// 開始前のプールにはタスクを処理するワーカーがいないため待たない
+	// チェックポイントの猶予を設定している場合は、期限の猶予前までに終わらなかったタスクに保存を求めてから中断する
+	drainCtx, cancelDrain, grace := wp.drainContext(ctx)
+	defer cancelDrain()
+	var unfinished []Task
+	if from != StateCreated {
+		err = wp.waitDrained(drainCtx)
+	}
+	if from == StateCreated || err != nil {
+		unfinished = wp.removeWaiting(func(Task) bool { return true })
+		unfinished = append(unfinished, wp.checkpointInFlight(grace)...)
+	}
+
+	wp.halt()
+	// 中断したタスクや期限直前に失敗したタスクがリトライ待ちに残っていれば、それも未完了として返す
+	unfinished = append(unfinished, wp.retries.removeWhere(func(Task) bool { return true })...)
+	wp.lifecycle.transition(StateStopped)
+
+	if len(unfinished) > 0 {
+		event("pool.stopped").logf("✋ ワーカープールが停止しました（未完了のタスク: %d 件）\n", len(unfinished))
+	} else {
+		event("pool.stopped").logln("✋ ワーカープールが停止しました")
+	}
+	return unfinished, err
+}
+
+// Shutdown はすべてのサブプールを並行して Shutdown し、未完了のタスクをまとめて返す
+func (tp *TypedPool) Shutdown(ctx context.Context) ([]Task, error) {
+	if _, err := tp.lifecycle.transition(StateDraining, StateCreated, StateRunning, StatePaused); err != nil {
+		return nil, err
+	}
+
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		unfinished []Task
+		firstErr   error
+	)
+	for _, pool := range tp.subPools() {
+		wg.Add(1)
+		go func(pool *WorkerPool) {
+			defer wg.Done()
+			tasks, err := pool.Shutdown(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			unfinished = append(unfinished, tasks...)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}(pool)
+	}
+	wg.Wait()
+
+	tp.fanInWg.Wait()
+	close(tp.results)
+	tp.lifecycle.transition(StateStopped)
+	return unfinished, firstErr
+}
@@ -0,0 +1,263 @@
+package workerpool
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// sidecarHelloTimeout は StartSidecar・StartIsolatedSidecar がタスクタイプの宣言を待つ時間
+const sidecarHelloTimeout = 10 * time.Second
+
+// ErrSidecarClosed はサイドカーが停止している場合のエラー
+var ErrSidecarClosed = errors.New("サイドカーが停止しています")
+
+// sidecarHello は起動直後にサイドカーが出力する最初の行
+//
+//	{"types": ["email", "report"]}
+type sidecarHello struct {
+	Types []TaskType `json:"types"`
+}
+
+// sidecarRequest はサイドカーへ送るタスク（1行1JSON）
+type sidecarRequest struct {
+	RequestID uint64            `json:"request_id"`
+	Task      sidecarTask       `json:"task"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type sidecarTask struct {
+	ID           int         `json:"id"`
+	Name         string      `json:"name"`
+	Type         TaskType    `json:"type"`
+	Payload      interface{} `json:"payload,omitempty"`
+	AttemptCount int         `json:"attempt_count"`
+}
+
+// sidecarResponse はサイドカーからの応答（1行1JSON、error が空なら成功）
+type sidecarResponse struct {
+	RequestID uint64 `json:"request_id"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Sidecar は標準入出力でタスクを受け渡す外部プロセスのプロセッサ
+// プロトコル（改行区切りJSON）:
+//  1. 起動直後にサイドカーが {"types": [...]} を出力し、処理できるタスクタイプを宣言する
+//  2. プールは {"request_id": N, "task": {...}} を標準入力に書き込む
+//  3. サイドカーは {"request_id": N, "error": "..."} を標準出力に書き込む（成功時は error を省略）
+//
+// 複数のリクエストを同時に送るため、応答の順序は問わない。標準エラーはそのまま転送する
+type Sidecar struct {
+	path   string
+	cmd    *exec.Cmd
+	cancel context.CancelFunc // プロセスを停止する（隔離設定のプロセスグループごと）
+	types  []TaskType
+
+	writeMu sync.Mutex
+	stdin   io.WriteCloser
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan sidecarResponse
+	closed  bool
+	done    chan struct{} // 応答の読み込みが終わったら閉じる
+}
+
+// StartSidecar はサイドカーを起動し、宣言されたタスクタイプを読み込む
+// 環境変数はプールのものを引き継ぐ。10秒以内に宣言しない場合は停止してエラーを返す
+func StartSidecar(path string, args ...string) (*Sidecar, error) {
+	return StartIsolatedSidecar(path, ExecIsolation{InheritEnv: true}, args...)
+}
+
+// StartIsolatedSidecar は隔離設定（作業ディレクトリ、環境変数、リソース制限、cgroup）を適用してサイドカーを起動する
+// 10秒以内にタスクタイプを宣言しない場合は停止してエラーを返す
+func StartIsolatedSidecar(path string, isolation ExecIsolation, args ...string) (*Sidecar, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sidecarHelloTimeout)
+	defer cancel()
+	return StartSidecarContext(ctx, path, isolation, args...)
+}
+
+// StartSidecarContext は StartIsolatedSidecar と同様にサイドカーを起動する
+// タスクタイプの宣言を ctx の期限まで待ち、宣言する前に ctx が終了した場合はサイドカーを停止して ctx のエラーを返す。
+// 起動した後のサイドカーは ctx に関係なく Close まで動き続ける
+func StartSidecarContext(ctx context.Context, path string, isolation ExecIsolation, args ...string) (*Sidecar, error) {
+	// プロセスの停止は ctx ではなく kill・Close で行う（キャンセル時は隔離設定どおりプロセスグループごと停止する）
+	procCtx, cancel := context.WithCancel(context.Background())
+	cmd, cleanup, err := isolation.command(procCtx, path, args...)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("サイドカー %s を起動できません: %w", path, err)
+	}
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cleanup()
+		cancel()
+		return nil, fmt.Errorf("サイドカー %s を起動できません: %w", path, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cleanup()
+		cancel()
+		return nil, fmt.Errorf("サイドカー %s を起動できません: %w", path, err)
+	}
+	if err := cmd.Start(); err != nil {
+		cleanup()
+		cancel()
+		return nil, fmt.Errorf("サイドカー %s を起動できません: %w", path, err)
+	}
+	// cgroup のディスクリプタは起動時にのみ必要
+	cleanup()
+
+	s := &Sidecar{
+		path:    path,
+		cmd:     cmd,
+		cancel:  cancel,
+		stdin:   stdin,
+		pending: make(map[uint64]chan sidecarResponse),
+		done:    make(chan struct{}),
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	// 宣言を出力しないまま止まったサイドカーで待ち続けないよう、最初の行は別の goroutine で読む
+	scanned := make(chan bool, 1)
+	go func() { scanned <- scanner.Scan() }()
+	select {
+	case ok := <-scanned:
+		if !ok {
+			s.kill()
+			return nil, fmt.Errorf("サイドカー %s がタスクタイプを宣言しませんでした", path)
+		}
+	case <-ctx.Done():
+		s.kill()
+		<-scanned
+		return nil, fmt.Errorf("サイドカー %s がタスクタイプを宣言しませんでした: %w", path, ctx.Err())
+	}
+
+	var hello sidecarHello
+	if err := json.Unmarshal(scanner.Bytes(), &hello); err != nil || len(hello.Types) == 0 {
+		s.kill()
+		return nil, fmt.Errorf("サイドカー %s の宣言が不正です: %s", path, scanner.Text())
+	}
+	s.types = hello.Types
+
+	go s.readResponses(scanner)
+	return s, nil
+}
+
+// Processors はサイドカーが宣言したタスクタイプごとのプロセッサを返す
+func (s *Sidecar) Processors() map[TaskType]TaskProcessor {
+	processors := make(map[TaskType]TaskProcessor, len(s.types))
+	for _, taskType := range s.types {
+		processors[taskType] = s.Process
+	}
+	return processors
+}
+
+// Process はタスクをサイドカーに送り、応答を待つ
+// ctx がキャンセルされた場合は応答を待たずに ctx のエラーを返す（遅れて届いた応答は破棄）
+func (s *Sidecar) Process(ctx context.Context, task Task) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrSidecarClosed
+	}
+	s.nextID++
+	requestID := s.nextID
+	respCh := make(chan sidecarResponse, 1)
+	s.pending[requestID] = respCh
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, requestID)
+		s.mu.Unlock()
+	}()
+
+	line, err := json.Marshal(sidecarRequest{
+		RequestID: requestID,
+		Task: sidecarTask{
+			ID:           task.ID,
+			Name:         task.Name,
+			Type:         task.Type,
+			Payload:      task.Payload,
+			AttemptCount: task.AttemptCount,
+		},
+		Labels: task.Labels,
+	})
+	if err != nil {
+		return fmt.Errorf("タスク %d をサイドカーに送れません: %w", task.ID, err)
+	}
+
+	s.writeMu.Lock()
+	_, err = s.stdin.Write(append(line, '\n'))
+	s.writeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("タスク %d をサイドカーに送れません: %w", task.ID, err)
+	}
+
+	select {
+	case resp := <-respCh:
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		return nil
+	case <-s.done:
+		return fmt.Errorf("タスク %d の処理中に%w", task.ID, ErrSidecarClosed)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// readResponses はサイドカーの応答を待っている呼び出し元に振り分ける
+func (s *Sidecar) readResponses(scanner *bufio.Scanner) {
+	defer close(s.done)
+
+	for scanner.Scan() {
+		var resp sidecarResponse
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			event("sidecar.invalid_response").logf("⚠️ サイドカー %s の応答が不正です: %s\n", s.path, scanner.Text())
+			continue
+		}
+
+		s.mu.Lock()
+		respCh, waiting := s.pending[resp.RequestID]
+		s.mu.Unlock()
+		if waiting {
+			respCh <- resp
+		}
+	}
+
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+}
+
+// Close は標準入力を閉じてサイドカーの終了を待つ
+func (s *Sidecar) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	s.writeMu.Lock()
+	s.stdin.Close()
+	s.writeMu.Unlock()
+
+	<-s.done
+	defer s.cancel()
+	return s.cmd.Wait()
+}
+
+// kill は起動に失敗したサイドカーを停止する
+func (s *Sidecar) kill() {
+	s.cancel()
+	s.cmd.Wait()
+}
@@ -0,0 +1,71 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writeSidecar はテスト用のサイドカー（シェルスクリプト）を作成する
+func writeSidecar(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("シェルスクリプトのサイドカーは windows では動かせません")
+	}
+	path := filepath.Join(t.TempDir(), "sidecar")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// タスクタイプを宣言しないサイドカーは ctx の期限で停止し、起動はエラーになる
+func TestStartSidecarHelloTimeout(t *testing.T) {
+	path := writeSidecar(t, "sleep 30")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	sidecar, err := StartSidecarContext(ctx, path, ExecIsolation{InheritEnv: true})
+	if err == nil {
+		sidecar.Close()
+		t.Fatal("宣言しないサイドカーの起動が成功しました")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("起動の中断に %v かかりました", elapsed)
+	}
+}
+
+// 宣言したサイドカーは ctx の期限を過ぎても Close まで動き続ける
+func TestStartSidecarContextKeepsRunning(t *testing.T) {
+	path := writeSidecar(t, `echo '{"types": ["echo"]}'
+exec cat > /dev/null`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	sidecar, err := StartSidecarContext(ctx, path, ExecIsolation{InheritEnv: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, ok := sidecar.Processors()["echo"]; !ok {
+		t.Fatalf("Processors = %v, want echo", sidecar.Processors())
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- sidecar.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("サイドカーが終了しませんでした")
+	}
+}
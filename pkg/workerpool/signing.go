@@ -0,0 +1,234 @@
+package workerpool
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrUntrustedTask は署名がない・信頼する発行元の署名でない・署名が一致しないタスクのエラー
+	// リトライしても直らないため、リトライせずに DeadLetterUntrusted としてDLQに送る
+	ErrUntrustedTask = errors.New("信頼できる発行元の署名がないタスクです")
+	// ErrInvalidSigningKey は署名の鍵が不正な場合のエラー
+	ErrInvalidSigningKey = errors.New("署名の鍵が不正です")
+)
+
+// signatureContext は署名の対象に含める用途の文字列（他の用途の署名を流用されないようにする）
+const signatureContext = "worker-example/task/v1"
+
+// TaskSigner はタスクに署名する発行元の鍵
+type TaskSigner interface {
+	// KeyID は検証側で鍵を選ぶための識別子を返す（Task.SignedBy に入る）
+	KeyID() string
+	// Sign はデータの署名を返す
+	Sign(data []byte) ([]byte, error)
+}
+
+// TaskVerifier はタスクの署名を検証する
+type TaskVerifier interface {
+	// Verify は keyID の鍵でデータの署名を検証する（信頼しない鍵・一致しない署名はエラー）
+	Verify(keyID string, data, signature []byte) error
+}
+
+// HMACKey は共有鍵（HMAC-SHA256）による署名の鍵（発行元と実行側で同じ鍵を持つ）
+type HMACKey struct {
+	ID     string
+	Secret []byte
+}
+
+// KeyID は鍵の識別子を返す
+func (k HMACKey) KeyID() string { return k.ID }
+
+// Sign はデータの HMAC-SHA256 を返す
+func (k HMACKey) Sign(data []byte) ([]byte, error) {
+	if len(k.Secret) == 0 {
+		return nil, fmt.Errorf("%w: 鍵 %s の共有鍵が空です", ErrInvalidSigningKey, k.ID)
+	}
+	mac := hmac.New(sha256.New, k.Secret)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// Ed25519Key は公開鍵暗号（Ed25519）による署名の鍵（実行側は公開鍵だけを持てばよい）
+type Ed25519Key struct {
+	ID         string
+	PrivateKey ed25519.PrivateKey
+}
+
+// KeyID は鍵の識別子を返す
+func (k Ed25519Key) KeyID() string { return k.ID }
+
+// Sign はデータの Ed25519 署名を返す
+func (k Ed25519Key) Sign(data []byte) ([]byte, error) {
+	if len(k.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: 鍵 %s の秘密鍵の長さが不正です", ErrInvalidSigningKey, k.ID)
+	}
+	return ed25519.Sign(k.PrivateKey, data), nil
+}
+
+// TrustedProducers は信頼する発行元の鍵の一覧（鍵の識別子ごと）
+// 鍵の入れ替えの間は新旧の鍵を両方登録しておけばよい
+type TrustedProducers struct {
+	mu   sync.RWMutex
+	keys map[string]func(data, signature []byte) bool
+}
+
+// NewTrustedProducers は空の一覧を作成する
+func NewTrustedProducers() *TrustedProducers {
+	return &TrustedProducers{keys: make(map[string]func(data, signature []byte) bool)}
+}
+
+// AddHMAC は共有鍵の発行元を信頼する
+func (t *TrustedProducers) AddHMAC(keyID string, secret []byte) error {
+	if keyID == "" || len(secret) == 0 {
+		return fmt.Errorf("%w: 鍵の識別子と共有鍵を指定してください", ErrInvalidSigningKey)
+	}
+	key := HMACKey{ID: keyID, Secret: append([]byte(nil), secret...)}
+	t.add(keyID, func(data, signature []byte) bool {
+		expected, err := key.Sign(data)
+		return err == nil && hmac.Equal(expected, signature)
+	})
+	return nil
+}
+
+// AddEd25519 は公開鍵の発行元を信頼する
+func (t *TrustedProducers) AddEd25519(keyID string, publicKey ed25519.PublicKey) error {
+	if keyID == "" || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: 鍵の識別子と Ed25519 の公開鍵を指定してください", ErrInvalidSigningKey)
+	}
+	publicKey = append(ed25519.PublicKey(nil), publicKey...)
+	t.add(keyID, func(data, signature []byte) bool {
+		return ed25519.Verify(publicKey, data, signature)
+	})
+	return nil
+}
+
+// Remove は発行元を信頼しないようにする（鍵の失効）
+func (t *TrustedProducers) Remove(keyID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.keys, keyID)
+}
+
+func (t *TrustedProducers) add(keyID string, verify func(data, signature []byte) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.keys[keyID] = verify
+}
+
+// Verify は keyID の鍵でデータの署名を検証する
+func (t *TrustedProducers) Verify(keyID string, data, signature []byte) error {
+	t.mu.RLock()
+	verify, trusted := t.keys[keyID]
+	t.mu.RUnlock()
+
+	if !trusted {
+		return fmt.Errorf("%w: 鍵 %s は信頼されていません", ErrUntrustedTask, keyID)
+	}
+	if !verify(data, signature) {
+		return fmt.Errorf("%w: 鍵 %s の署名が一致しません", ErrUntrustedTask, keyID)
+	}
+	return nil
+}
+
+// signingData はタスクの署名の対象（用途・鍵の識別子・ID・タイプ・ペイロードのチェックサム）を返す
+// ペイロードを退避したタスクは参照に含まれるチェックサムを通して中身も対象になる
+func signingData(keyID string, task Task) ([]byte, error) {
+	checksum, err := taskChecksum(task)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(signatureContext + "\n" + keyID + "\n" + checksum), nil
+}
+
+// SignTask はタスクに署名し、SignedBy と Signature を設定したタスクを返す
+// ブローカーなどを経由してプールに渡す場合に、発行元で呼び出す
+func SignTask(task Task, signer TaskSigner) (Task, error) {
+	keyID := signer.KeyID()
+	data, err := signingData(keyID, task)
+	if err != nil {
+		return task, err
+	}
+	signature, err := signer.Sign(data)
+	if err != nil {
+		return task, err
+	}
+	task.SignedBy = keyID
+	task.Signature = base64.StdEncoding.EncodeToString(signature)
+	return task, nil
+}
+
+// VerifyTask はタスクの署名を検証する
+func VerifyTask(task Task, verifier TaskVerifier) error {
+	if task.SignedBy == "" || task.Signature == "" {
+		return fmt.Errorf("%w: 署名がありません", ErrUntrustedTask)
+	}
+	signature, err := base64.StdEncoding.DecodeString(task.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: 署名の形式が不正です", ErrUntrustedTask)
+	}
+	data, err := signingData(task.SignedBy, task)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUntrustedTask, err)
+	}
+	return verifier.Verify(task.SignedBy, data, signature)
+}
+
+// SetTaskSigner は投入されたタスクに署名する（署名済みのタスクはそのまま。nil で無効化）
+func (wp *WorkerPool) SetTaskSigner(signer TaskSigner) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.signer = signer
+}
+
+// SetTaskVerifier は実行の前にタスクの署名を検証し、信頼する発行元の署名がないタスクは実行せずDLQに送る（nil で無効化）
+func (wp *WorkerPool) SetTaskVerifier(verifier TaskVerifier) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.verifier = verifier
+}
+
+// SetTaskSigner はすべてのサブプールで投入されたタスクに署名する
+func (tp *TypedPool) SetTaskSigner(signer TaskSigner) {
+	for _, pool := range tp.subPools() {
+		pool.SetTaskSigner(signer)
+	}
+}
+
+// SetTaskVerifier はすべてのサブプールで実行の前にタスクの署名を検証する
+func (tp *TypedPool) SetTaskVerifier(verifier TaskVerifier) {
+	for _, pool := range tp.subPools() {
+		pool.SetTaskVerifier(verifier)
+	}
+}
+
+// signTask は署名の鍵が設定されていれば、署名のないタスクに署名する
+func (wp *WorkerPool) signTask(task Task) (Task, error) {
+	wp.mu.Lock()
+	signer := wp.signer
+	wp.mu.Unlock()
+	if signer == nil || task.Signature != "" {
+		return task, nil
+	}
+	return SignTask(task, signer)
+}
+
+// verifySignature は検証が設定されていれば、実行の前にタスクの署名を検証する
+func (wp *WorkerPool) verifySignature(task Task) error {
+	wp.mu.Lock()
+	verifier := wp.verifier
+	wp.mu.Unlock()
+	if verifier == nil {
+		return nil
+	}
+	return VerifyTask(task, verifier)
+}
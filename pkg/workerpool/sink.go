@@ -0,0 +1,208 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ResultSink はタスクの結果の出力先（ファイル・検索エンジンなど）
+// プールは最終結果を返すたびに WriteResult を呼び出す。ワーカーの処理中に呼ばれるため、
+// 時間のかかる書き込みはバッファに溜めてまとめて行うこと
+type ResultSink interface {
+	// WriteResult は結果を1件書き込む
+	WriteResult(result TaskResult) error
+	// Close は未書き込みの結果を書き出して出力を終了する
+	Close() error
+}
+
+// AddResultSink は結果の出力先を追加する
+// プールの停止で出力先は閉じないため、Stop の後に呼び出し元で Close すること
+func (wp *WorkerPool) AddResultSink(sink ResultSink) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.sinks = append(wp.sinks, sink)
+}
+
+// AddResultSink はすべてのサブプールに結果の出力先を追加する（後から作成するサブプールにも適用する）
+func (tp *TypedPool) AddResultSink(sink ResultSink) {
+	for _, pool := range tp.subPools() {
+		pool.AddResultSink(sink)
+	}
+	tp.mu.Lock()
+	tp.sinks = append(tp.sinks, sink)
+	tp.mu.Unlock()
+}
+
+// exportResult は結果を出力先に書き込む（失敗しても結果の配送は続ける）
+func (wp *WorkerPool) exportResult(result TaskResult) {
+	wp.mu.Lock()
+	sinks := wp.sinks
+	wp.mu.Unlock()
+
+	for _, sink := range sinks {
+		if err := sink.WriteResult(result); err != nil {
+			event("sink.write_failed").task(result.TaskID).failed(err).
+				logf("⚠️ タスク %d の結果を出力できませんでした: %v\n", result.TaskID, err)
+		}
+	}
+}
+
+// resultBatcher は出力先に送る記録を溜め、件数か間隔のどちらかに達したらまとめて送る
+// 送信に失敗した記録は保持しておき、次の送信で再送する（保持の上限を超えた分は古い順に捨てる）
+type resultBatcher struct {
+	name        string // 出力先の名前（メッセージ用）
+	batchSize   int
+	maxBuffered int
+	send        func(ctx context.Context, records [][]byte) error
+
+	flushMu sync.Mutex // 送信を1つずつ行う
+	mu      sync.Mutex
+	pending [][]byte // 未送信の記録
+	dropped int64    // 上限を超えて捨てた件数
+	lastErr error    // 直近の送信エラー
+	closed  bool
+
+	flushCh chan struct{}
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newResultBatcher は記録の一括送信を開始する
+func newResultBatcher(name string, batchSize, maxBuffered int, interval time.Duration, send func(context.Context, [][]byte) error) *resultBatcher {
+	b := &resultBatcher{
+		name:        name,
+		batchSize:   batchSize,
+		maxBuffered: maxBuffered,
+		send:        send,
+		flushCh:     make(chan struct{}, 1),
+		stopCh:      make(chan struct{}),
+	}
+	b.wg.Add(1)
+	go b.run(interval)
+	return b
+}
+
+// add は記録を追加する（送信はバックグラウンドで行う）
+func (b *resultBatcher) add(record []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return fmt.Errorf("%s: %w", b.name, os.ErrClosed)
+	}
+	b.pending = append(b.pending, record)
+	b.trimLocked()
+	if len(b.pending) >= b.batchSize {
+		select {
+		case b.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush は溜まっている記録をすぐに送る
+func (b *resultBatcher) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	for {
+		b.mu.Lock()
+		count := min(len(b.pending), b.batchSize)
+		batch := b.pending[:count:count]
+		b.pending = b.pending[count:]
+		b.mu.Unlock()
+		if count == 0 {
+			return nil
+		}
+
+		err := b.send(ctx, batch)
+
+		b.mu.Lock()
+		b.lastErr = err
+		if err != nil {
+			// 再送できるよう先頭に戻す
+			b.pending = append(batch, b.pending...)
+			b.trimLocked()
+		}
+		b.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Pending は未送信の記録の件数を返す
+func (b *resultBatcher) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.pending)
+}
+
+// Dropped は保持の上限を超えて捨てた記録の件数を返す
+func (b *resultBatcher) Dropped() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.dropped
+}
+
+// LastError は直近の送信エラーを返す（成功した場合は nil）
+func (b *resultBatcher) LastError() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.lastErr
+}
+
+// close は定期送信を止め、残りの記録を送る
+func (b *resultBatcher) close(timeout time.Duration) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.stopCh)
+	b.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return b.Flush(ctx)
+}
+
+// run は一定間隔、または件数に達した時点で記録を送る
+func (b *resultBatcher) run(interval time.Duration) {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.flushCh:
+		case <-b.stopCh:
+			return
+		}
+		if err := b.Flush(context.Background()); err != nil {
+			event("sink.flush_failed").failed(err).
+				logf("⚠️ %s に結果を送信できませんでした（%d 件を保持して再送します）: %v\n", b.name, b.Pending(), err)
+		}
+	}
+}
+
+// trimLocked は保持の上限を超えた古い記録を捨てる（b.mu を保持して呼び出すこと）
+func (b *resultBatcher) trimLocked() {
+	if over := len(b.pending) - b.maxBuffered; over > 0 {
+		b.pending = b.pending[over:]
+		b.dropped += int64(over)
+	}
+}
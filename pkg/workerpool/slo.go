@@ -0,0 +1,299 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidSLO はSLOの定義が不正な場合のエラー
+var ErrInvalidSLO = errors.New("SLOの定義が不正です")
+
+const (
+	// defaultSLOWindow はエラーバジェットを計算する既定の期間
+	defaultSLOWindow = 30 * 24 * time.Hour
+	// sloBucketSize は結果を集計する単位（バーンレートの期間はこの単位で丸める）
+	sloBucketSize = time.Minute
+)
+
+// SLO はタスクタイプごとのサービスレベル目標（例: メールの99%が30秒以内に完了する）
+// 成功し、かつ作成から最終結果までが Threshold 以内のタスクを「良い」結果として数える
+type SLO struct {
+	Name      string         `json:"name"`                   // SLOの名前（空の場合はタイプ名）
+	Type      TaskType       `json:"type,omitempty"`         // 対象のタスクタイプ（空の場合はすべて）
+	Objective float64        `json:"objective"`              // 目標とする良い結果の割合（0.99 など）
+	Threshold time.Duration  `json:"threshold_ns,omitempty"` // 完了までの上限（0 の場合は成功のみで判定する可用性SLO）
+	Window    time.Duration  `json:"window_ns,omitempty"`    // エラーバジェットの期間（0 の場合は30日）
+	Rules     []BurnRateRule `json:"rules,omitempty"`        // アラートのルール（空の場合は DefaultBurnRateRules）
+}
+
+// BurnRateRule はバーンレートのアラートのルール
+// 長い期間と短い期間の両方でバーンレートが BurnRate 以上の場合に発報する（短い期間で早く解消させる）
+type BurnRateRule struct {
+	Name        string        `json:"name"`
+	Severity    string        `json:"severity"` // page / ticket など
+	LongWindow  time.Duration `json:"long_window_ns"`
+	ShortWindow time.Duration `json:"short_window_ns"`
+	BurnRate    float64       `json:"burn_rate"` // エラーバジェットを消費する速さ（1 でちょうど期間内に使い切る）
+}
+
+// DefaultBurnRateRules は30日のエラーバジェットを前提にした複数期間のルールを返す
+// 1時間で2%、6時間で5%、3日で10%のバジェットを消費する速さに相当する
+func DefaultBurnRateRules() []BurnRateRule {
+	return []BurnRateRule{
+		{Name: "fast_burn", Severity: "page", LongWindow: time.Hour, ShortWindow: 5 * time.Minute, BurnRate: 14.4},
+		{Name: "medium_burn", Severity: "page", LongWindow: 6 * time.Hour, ShortWindow: 30 * time.Minute, BurnRate: 6},
+		{Name: "slow_burn", Severity: "ticket", LongWindow: 3 * 24 * time.Hour, ShortWindow: 6 * time.Hour, BurnRate: 1},
+	}
+}
+
+// withDefaults は既定値を補ったSLOを返す
+func (s SLO) withDefaults() SLO {
+	if s.Name == "" {
+		s.Name = string(s.Type)
+		if s.Name == "" {
+			s.Name = "all"
+		}
+	}
+	if s.Window == 0 {
+		s.Window = defaultSLOWindow
+	}
+	if len(s.Rules) == 0 {
+		s.Rules = DefaultBurnRateRules()
+	}
+	return s
+}
+
+// Validate はSLOの定義を検証する
+func (s SLO) Validate() error {
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return fmt.Errorf("%w: SLO %s の objective は0より大きく1より小さい値を指定してください", ErrInvalidSLO, s.Name)
+	}
+	if s.Threshold < 0 || s.Window < 0 {
+		return fmt.Errorf("%w: SLO %s の threshold・window に負の値は指定できません", ErrInvalidSLO, s.Name)
+	}
+	for _, rule := range s.Rules {
+		if rule.Name == "" || rule.BurnRate <= 0 || rule.ShortWindow <= 0 || rule.LongWindow < rule.ShortWindow {
+			return fmt.Errorf("%w: SLO %s のルール %q は name・burn_rate と long_window >= short_window > 0 を指定してください",
+				ErrInvalidSLO, s.Name, rule.Name)
+		}
+	}
+	return nil
+}
+
+// SLOStatus はSLOの達成状況
+type SLOStatus struct {
+	Name       string        `json:"name"`
+	Type       TaskType      `json:"type,omitempty"`
+	Objective  float64       `json:"objective"`
+	Threshold  time.Duration `json:"threshold_ns,omitempty"`
+	Window     time.Duration `json:"window_ns"`
+	Total      int64         `json:"total"` // 期間内の結果の件数
+	Good       int64         `json:"good"`
+	Bad        int64         `json:"bad"`
+	Compliance float64       `json:"compliance"`       // 良い結果の割合（結果がない場合は1）
+	BudgetLeft float64       `json:"budget_remaining"` // エラーバジェットの残り（1 で未消費、負の値は超過）
+	Alerts     []SLOAlert    `json:"alerts"`
+
+	RetryThrottled bool `json:"retry_throttled,omitempty"` // エラーバジェットのためにタイプのリトライを絞っている（SetRetryThrottle）
+}
+
+// SLOAlert はバーンレートのルールの評価結果
+type SLOAlert struct {
+	Rule          string    `json:"rule"`
+	Severity      string    `json:"severity"`
+	Threshold     float64   `json:"burn_rate_threshold"`
+	LongBurnRate  float64   `json:"long_burn_rate"`
+	ShortBurnRate float64   `json:"short_burn_rate"`
+	Firing        bool      `json:"firing"`
+	Since         time.Time `json:"since,omitempty"` // 発報した日時
+}
+
+// sloBucket は1単位の期間の結果の件数
+type sloBucket struct {
+	start     time.Time
+	good, bad int64
+}
+
+// sloTracker は1つのSLOの結果を期間ごとに集計する
+type sloTracker struct {
+	slo     SLO
+	buckets []sloBucket          // 古い順
+	firing  map[string]time.Time // 発報中のルール → 発報した日時
+}
+
+func newSLOTracker(slo SLO) *sloTracker {
+	return &sloTracker{slo: slo, firing: make(map[string]time.Time)}
+}
+
+// record は最終結果を1件集計する
+func (t *sloTracker) record(result TaskResult, now time.Time) {
+	if !result.IsFinal || (t.slo.Type != "" && result.TaskType != t.slo.Type) {
+		return
+	}
+	elapsed := result.Age
+	if result.CreatedAt.IsZero() {
+		elapsed = result.TotalDuration
+	}
+	good := result.Success && (t.slo.Threshold == 0 || elapsed <= t.slo.Threshold)
+
+	start := now.Truncate(sloBucketSize)
+	if n := len(t.buckets); n == 0 || !t.buckets[n-1].start.Equal(start) {
+		t.buckets = append(t.buckets, sloBucket{start: start})
+	}
+	if good {
+		t.buckets[len(t.buckets)-1].good++
+	} else {
+		t.buckets[len(t.buckets)-1].bad++
+	}
+}
+
+// prune は最も長い期間より古い集計を捨てる
+func (t *sloTracker) prune(now time.Time) {
+	retain := t.slo.Window
+	for _, rule := range t.slo.Rules {
+		if rule.LongWindow > retain {
+			retain = rule.LongWindow
+		}
+	}
+	cutoff := now.Add(-retain - sloBucketSize)
+	drop := 0
+	for drop < len(t.buckets) && t.buckets[drop].start.Before(cutoff) {
+		drop++
+	}
+	t.buckets = t.buckets[drop:]
+}
+
+// count は直近 window の良い結果と悪い結果の件数を返す
+func (t *sloTracker) count(window time.Duration, now time.Time) (good, bad int64) {
+	since := now.Add(-window)
+	for i := len(t.buckets) - 1; i >= 0; i-- {
+		// 期間の境界にかかる単位も含める
+		if t.buckets[i].start.Add(sloBucketSize).Before(since) {
+			break
+		}
+		good += t.buckets[i].good
+		bad += t.buckets[i].bad
+	}
+	return good, bad
+}
+
+// burnRate は直近 window でエラーバジェットを消費している速さを返す
+func (t *sloTracker) burnRate(window time.Duration, now time.Time) float64 {
+	good, bad := t.count(window, now)
+	if good+bad == 0 {
+		return 0
+	}
+	return float64(bad) / float64(good+bad) / (1 - t.slo.Objective)
+}
+
+// evaluate は達成状況を集計し、ルールの発報・解消を記録する
+func (t *sloTracker) evaluate(now time.Time) SLOStatus {
+	t.prune(now)
+	good, bad := t.count(t.slo.Window, now)
+	status := SLOStatus{
+		Name:       t.slo.Name,
+		Type:       t.slo.Type,
+		Objective:  t.slo.Objective,
+		Threshold:  t.slo.Threshold,
+		Window:     t.slo.Window,
+		Total:      good + bad,
+		Good:       good,
+		Bad:        bad,
+		Compliance: 1,
+		BudgetLeft: 1,
+		Alerts:     make([]SLOAlert, 0, len(t.slo.Rules)),
+	}
+	if status.Total > 0 {
+		status.Compliance = float64(good) / float64(status.Total)
+		status.BudgetLeft = 1 - (1-status.Compliance)/(1-t.slo.Objective)
+	}
+
+	for _, rule := range t.slo.Rules {
+		alert := SLOAlert{
+			Rule:          rule.Name,
+			Severity:      rule.Severity,
+			Threshold:     rule.BurnRate,
+			LongBurnRate:  t.burnRate(rule.LongWindow, now),
+			ShortBurnRate: t.burnRate(rule.ShortWindow, now),
+		}
+		alert.Firing = alert.LongBurnRate >= rule.BurnRate && alert.ShortBurnRate >= rule.BurnRate
+
+		since, wasFiring := t.firing[rule.Name]
+		switch {
+		case alert.Firing && !wasFiring:
+			since = now
+			t.firing[rule.Name] = since
+			event("slo.alert_firing").logf("🔥 SLO %s: %s (%s) を発報しました（バーンレート %s: %.1f, %s: %.1f, 閾値: %.1f）\n",
+				t.slo.Name, rule.Name, rule.Severity, rule.LongWindow, alert.LongBurnRate, rule.ShortWindow, alert.ShortBurnRate, rule.BurnRate)
+		case !alert.Firing && wasFiring:
+			delete(t.firing, rule.Name)
+			event("slo.alert_resolved").logf("🟢 SLO %s: %s が解消しました（発報から %v）\n",
+				t.slo.Name, rule.Name, now.Sub(since).Round(time.Second))
+		}
+		if alert.Firing {
+			alert.Since = since
+		}
+		status.Alerts = append(status.Alerts, alert)
+	}
+	return status
+}
+
+// SetSLOs は監視するSLOを設定する（既存の集計は破棄する）
+func (m *Monitor) SetSLOs(slos ...SLO) error {
+	trackers := make([]*sloTracker, 0, len(slos))
+	for _, slo := range slos {
+		slo = slo.withDefaults()
+		if err := slo.Validate(); err != nil {
+			return err
+		}
+		trackers = append(trackers, newSLOTracker(slo))
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.slos = trackers
+	m.stats.SLOs = nil
+	return nil
+}
+
+// SLOStatuses はSLOの達成状況を返す（統計の更新時に評価した値）
+func (m *Monitor) SLOStatuses() []SLOStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]SLOStatus{}, m.stats.SLOs...)
+}
+
+// printSLOs はSLOの達成状況をコンソールに表示する
+func printSLOs(slos []SLOStatus) {
+	if len(slos) == 0 {
+		return
+	}
+	fmt.Println("\n🎯 SLO:")
+	for _, slo := range slos {
+		fmt.Printf("  [%s] 達成率:%.2f%% (目標 %.2f%%) 件数:%d エラーバジェット残:%.1f%%\n",
+			slo.Name, slo.Compliance*100, slo.Objective*100, slo.Total, slo.BudgetLeft*100)
+		if slo.RetryThrottled {
+			fmt.Printf("    🪫 エラーバジェットが残り少ないため %s のリトライを絞っています\n", slo.Type)
+		}
+		for _, alert := range slo.Alerts {
+			if alert.Firing {
+				fmt.Printf("    🔥 %s (%s) バーンレート %.1f / %.1f (閾値 %.1f)\n",
+					alert.Rule, alert.Severity, alert.LongBurnRate, alert.ShortBurnRate, alert.Threshold)
+			}
+		}
+	}
+}
+
+// handleSLO は GET /slo でSLOの達成状況とアラートを返す
+func (m *Monitor) handleSLO(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"slos": m.SLOStatuses()})
+}
@@ -0,0 +1,195 @@
+package workerpool
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ActorRetryThrottle はエラーバジェットによるリトライの調整を監査ログに記録する際の操作者
+const ActorRetryThrottle = "slo-retry-throttle"
+
+// リトライの調整の既定値
+const (
+	defaultThrottleThreshold   = 0.2
+	defaultThrottleMaxRetries  = 1
+	defaultThrottleDelayFactor = 4
+)
+
+// RetryThrottle はエラーバジェットが残り少ないタスクタイプのリトライを絞る設定
+// バジェットを使い切りそうなタイプのリトライでワーカーが埋まり、正常なタイプの処理が遅れるのを防ぐ
+type RetryThrottle struct {
+	BudgetThreshold float64 `json:"budget_threshold"` // エラーバジェットの残りがこれを下回ると絞る（0 の場合は0.2）
+	RecoverAt       float64 `json:"recover_at"`       // 残りがこれ以上に戻ると元のポリシーに戻す（0 の場合は BudgetThreshold の2倍、最大1）
+	MaxRetries      int     `json:"max_retries"`      // 絞った後の最大リトライ回数（0 の場合は1、負の値はリトライせずにDLQに送る）
+	DelayFactor     float64 `json:"delay_factor"`     // 絞った後のリトライの遅延の倍率（0 の場合は4）
+}
+
+// withDefaults は既定値を補った設定を返す
+func (t RetryThrottle) withDefaults() RetryThrottle {
+	if t.BudgetThreshold <= 0 {
+		t.BudgetThreshold = defaultThrottleThreshold
+	}
+	if t.RecoverAt <= 0 {
+		t.RecoverAt = min(t.BudgetThreshold*2, 1)
+	}
+	t.RecoverAt = max(t.RecoverAt, t.BudgetThreshold)
+	if t.MaxRetries == 0 {
+		t.MaxRetries = defaultThrottleMaxRetries
+	}
+	t.MaxRetries = max(t.MaxRetries, 0)
+	if t.DelayFactor <= 0 {
+		t.DelayFactor = defaultThrottleDelayFactor
+	}
+	return t
+}
+
+// tighten はポリシーのリトライ回数を減らし、遅延を延ばしたポリシーを返す
+func (t RetryThrottle) tighten(policy RetryPolicy) RetryPolicy {
+	policy.MaxRetries = min(policy.MaxRetries, t.MaxRetries)
+	policy.InitialDelay = time.Duration(float64(policy.InitialDelay) * t.DelayFactor)
+	if policy.MaxDelay > 0 {
+		policy.MaxDelay = time.Duration(float64(policy.MaxDelay) * t.DelayFactor)
+	}
+	if len(policy.ClassBackoff) > 0 {
+		classBackoff := make(map[ErrorClass]BackoffCurve, len(policy.ClassBackoff))
+		for class, curve := range policy.ClassBackoff {
+			curve.InitialDelay = time.Duration(float64(curve.InitialDelay) * t.DelayFactor)
+			if curve.MaxDelay > 0 {
+				curve.MaxDelay = time.Duration(float64(curve.MaxDelay) * t.DelayFactor)
+			}
+			classBackoff[class] = curve
+		}
+		policy.ClassBackoff = classBackoff
+	}
+	return policy
+}
+
+// retryPolicyPool はリトライポリシーを参照・変更できるプール
+type retryPolicyPool interface {
+	RetryPolicy(taskType TaskType) RetryPolicy
+	SetRetryPolicy(taskType TaskType, policy RetryPolicy)
+}
+
+// SetRetryThrottle はエラーバジェットに応じたリトライの調整を有効にする（SetSLOs でタイプを指定したSLOが対象）
+// タイプのSLOのいずれかでバジェットの残りが閾値を下回るとリトライを絞り、すべてが RecoverAt 以上に戻ると元のポリシーに戻す。
+// 変更は AuditedPool を重ねていれば操作者 ActorRetryThrottle として監査ログに記録する。
+// 絞っている間に手動で変更したポリシーは、戻す際に絞る前のポリシーで上書きされる
+func (m *Monitor) SetRetryThrottle(throttle RetryThrottle) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	throttle = throttle.withDefaults()
+	m.retryThrottle = &throttle
+	if m.throttled == nil {
+		m.throttled = make(map[TaskType]RetryPolicy)
+	}
+}
+
+// ThrottledTypes はエラーバジェットのためにリトライを絞っているタスクタイプを返す
+func (m *Monitor) ThrottledTypes() []TaskType {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	types := make([]TaskType, 0, len(m.throttled))
+	for taskType := range m.throttled {
+		types = append(types, taskType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// throttleChange はリトライの調整で行う変更
+type throttleChange struct {
+	taskType TaskType
+	slo      string  // 判定の根拠にしたSLO
+	budget   float64 // そのSLOのエラーバジェットの残り
+	restore  bool
+	policy   RetryPolicy // 戻す場合は絞る前のポリシー
+}
+
+// adjustRetryThrottle は評価済みのSLOからリトライを絞る・戻すタイプを決め、プールに反映する
+// プールの呼び出しは m.mutex を保持せずに行う
+func (m *Monitor) adjustRetryThrottle() {
+	pool, ok := UnwrapPool(m.pool).(retryPolicyPool)
+	if !ok {
+		return
+	}
+
+	m.mutex.Lock()
+	throttle := m.retryThrottle
+	if throttle == nil {
+		m.mutex.Unlock()
+		return
+	}
+	// タイプごとに最もバジェットの残りが少ないSLOで判定する
+	lowest := make(map[TaskType]SLOStatus)
+	for _, status := range m.stats.SLOs {
+		if status.Type == "" || status.Total == 0 {
+			continue
+		}
+		if current, exists := lowest[status.Type]; !exists || status.BudgetLeft < current.BudgetLeft {
+			lowest[status.Type] = status
+		}
+	}
+	var changes []throttleChange
+	for taskType, status := range lowest {
+		_, throttled := m.throttled[taskType]
+		if !throttled && status.BudgetLeft < throttle.BudgetThreshold {
+			changes = append(changes, throttleChange{taskType: taskType, slo: status.Name, budget: status.BudgetLeft})
+		}
+	}
+	for taskType, original := range m.throttled {
+		status, exists := lowest[taskType]
+		if !exists || status.BudgetLeft >= throttle.RecoverAt {
+			changes = append(changes, throttleChange{taskType: taskType, slo: status.Name, budget: status.BudgetLeft, restore: true, policy: original})
+		}
+	}
+	for _, change := range changes {
+		if change.restore {
+			delete(m.throttled, change.taskType)
+		} else {
+			// 絞る前のポリシーを控えてから変更する（同じ更新の中で二重に絞らないよう先に記録する）
+			m.throttled[change.taskType] = pool.RetryPolicy(change.taskType)
+		}
+	}
+	m.markThrottledLocked()
+	m.mutex.Unlock()
+
+	for _, change := range changes {
+		policy := change.policy
+		if !change.restore {
+			policy = throttle.tighten(pool.RetryPolicy(change.taskType))
+		}
+		m.setRetryPolicy(change, policy)
+		if change.restore {
+			event("slo.retry_restored").logf("🔋 タスクタイプ %s のエラーバジェットが回復したため、リトライポリシーを元に戻しました\n", change.taskType)
+		} else {
+			event("slo.retry_throttled").logf("🪫 SLO %s のエラーバジェットが残り %.0f%% のため、タスクタイプ %s のリトライを絞りました（最大 %d 回、遅延 ×%.1f）\n",
+				change.slo, change.budget*100, change.taskType, policy.MaxRetries, throttle.DelayFactor)
+		}
+	}
+}
+
+// setRetryPolicy はリトライポリシーを変更する（AuditedPool を重ねていれば監査ログに記録する）
+func (m *Monitor) setRetryPolicy(change throttleChange, policy RetryPolicy) {
+	if audited := auditedPool(m.pool); audited != nil {
+		actor := fmt.Sprintf("%s (SLO %s 残り %.0f%%)", ActorRetryThrottle, change.slo, change.budget*100)
+		if change.restore && change.slo == "" {
+			actor = ActorRetryThrottle
+		}
+		audited.As(actor).SetRetryPolicy(change.taskType, policy)
+		return
+	}
+	if pool, ok := UnwrapPool(m.pool).(retryPolicyPool); ok {
+		pool.SetRetryPolicy(change.taskType, policy)
+	}
+}
+
+// markThrottledLocked はSLOの達成状況にリトライを絞っているかを反映する（m.mutex を保持して呼ぶ）
+func (m *Monitor) markThrottledLocked() {
+	for i := range m.stats.SLOs {
+		_, throttled := m.throttled[m.stats.SLOs[i].Type]
+		m.stats.SLOs[i].RetryThrottled = throttled
+	}
+}
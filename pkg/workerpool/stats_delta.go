@@ -0,0 +1,132 @@
+package workerpool
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxDeltaTokens は保持する差分トークンの上限（超えた分は古い順に破棄する）
+const maxDeltaTokens = 256
+
+// StatsDelta は前回のトークン以降に積み上がったカウンタ
+// 収集側は前回の値を保存せずに、interval_ms で割るだけでレートを算出できる
+type StatsDelta struct {
+	Token    string    `json:"token"`       // 次回の since に指定するトークン
+	Since    time.Time `json:"since"`       // 差分の起点
+	Until    time.Time `json:"until"`       // 差分の終点（トークンの発行時刻）
+	Interval float64   `json:"interval_ms"` // 差分の期間
+	Reset    bool      `json:"reset"`       // since が未指定・期限切れのため、モニター開始からの累計を返した
+
+	Instance *InstanceInfo `json:"instance,omitempty"` // SetInstanceInfo で設定したインスタンス情報
+
+	TotalTasks     int64                      `json:"total_tasks"`
+	CompletedTasks int64                      `json:"completed_tasks"`
+	FailedTasks    int64                      `json:"failed_tasks"`
+	Admission      AdmissionStats             `json:"admission"`
+	TaskTypeStats  map[TaskType]TaskTypeStats `json:"task_type_stats"` // 平均時間は期間内に完了したタスクの平均
+}
+
+// statsBaseline はトークン発行時点のカウンタ
+type statsBaseline struct {
+	at             time.Time
+	totalTasks     int64
+	completedTasks int64
+	failedTasks    int64
+	admission      AdmissionStats
+	taskTypeStats  map[TaskType]TaskTypeStats
+}
+
+// since は base の時点以降に積み上がった分の集計を返す
+func (ts TaskTypeStats) since(base TaskTypeStats) TaskTypeStats {
+	delta := TaskTypeStats{
+		Total:     ts.Total - base.Total,
+		Succeeded: ts.Succeeded - base.Succeeded,
+		Failed:    ts.Failed - base.Failed,
+		Retried:   ts.Retried - base.Retried,
+	}
+	if delta.Total > 0 {
+		// 平均 × 件数 = 合計 なので、合計の差から期間内の平均を求める
+		delta.AvgTime = (ts.AvgTime*float64(ts.Total) - base.AvgTime*float64(base.Total)) / float64(delta.Total)
+		delta.AvgAge = (ts.AvgAge*float64(ts.Total) - base.AvgAge*float64(base.Total)) / float64(delta.Total)
+	}
+	return delta
+}
+
+// StatsDelta は since のトークン以降のカウンタの差分と、次回用のトークンを返す
+// since が空・不明（期限切れやプロセスの再起動）の場合は Reset を立ててモニター開始からの累計を返す
+func (m *Monitor) StatsDelta(since string) StatsDelta {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	current := statsBaseline{
+		at:             now,
+		totalTasks:     m.stats.TotalTasks,
+		completedTasks: m.stats.CompletedTasks,
+		failedTasks:    m.stats.FailedTasks,
+		admission:      m.stats.Admission,
+		taskTypeStats:  make(map[TaskType]TaskTypeStats, len(m.stats.TaskTypeStats)),
+	}
+	for taskType, typeStats := range m.stats.TaskTypeStats {
+		current.taskTypeStats[taskType] = typeStats
+	}
+
+	base, exists := m.deltaTokens[since]
+	if !exists {
+		base = statsBaseline{at: m.startTime}
+	}
+
+	delta := StatsDelta{
+		Token:          m.issueDeltaToken(current),
+		Since:          base.at,
+		Until:          now,
+		Interval:       durationToMs(now.Sub(base.at)),
+		Reset:          !exists,
+		Instance:       CurrentInstance(),
+		TotalTasks:     current.totalTasks - base.totalTasks,
+		CompletedTasks: current.completedTasks - base.completedTasks,
+		FailedTasks:    current.failedTasks - base.failedTasks,
+		Admission: AdmissionStats{
+			Rejected: current.admission.Rejected - base.admission.Rejected,
+			Shed:     current.admission.Shed - base.admission.Shed,
+			Degraded: current.admission.Degraded - base.admission.Degraded,
+			Deferred: current.admission.Deferred - base.admission.Deferred,
+		},
+		TaskTypeStats: make(map[TaskType]TaskTypeStats),
+	}
+	for taskType, typeStats := range current.taskTypeStats {
+		if typeDelta := typeStats.since(base.taskTypeStats[taskType]); typeDelta.Total > 0 {
+			delta.TaskTypeStats[taskType] = typeDelta
+		}
+	}
+	return delta
+}
+
+// issueDeltaToken はカウンタを記録してトークンを発行する（m.mutex を保持して呼ぶ）
+// トークンにモニターの開始時刻を含め、再起動前のトークンを別のカウンタと取り違えないようにする
+func (m *Monitor) issueDeltaToken(baseline statsBaseline) string {
+	if m.deltaTokens == nil {
+		m.deltaTokens = make(map[string]statsBaseline)
+	}
+	m.deltaSeq++
+	token := fmt.Sprintf("%x-%d", m.startTime.UnixNano(), m.deltaSeq)
+	m.deltaTokens[token] = baseline
+	m.deltaOrder = append(m.deltaOrder, token)
+	for len(m.deltaOrder) > maxDeltaTokens {
+		delete(m.deltaTokens, m.deltaOrder[0])
+		m.deltaOrder = m.deltaOrder[1:]
+	}
+	return token
+}
+
+// handleStatsDelta は GET /stats/delta?since=token でカウンタの差分を返す
+func (m *Monitor) handleStatsDelta(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	writeJSON(w, http.StatusOK, m.StatsDelta(r.URL.Query().Get("since")))
+}
@@ -0,0 +1,292 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TaskState はストア上のタスクの状態
+type TaskState string
+
+const (
+	TaskStatePending   TaskState = "pending"   // キュー待ち
+	TaskStateRunning   TaskState = "running"   // 実行中
+	TaskStateRetrying  TaskState = "retrying"  // リトライ待ち
+	TaskStateCompleted TaskState = "completed" // 成功
+	TaskStateFailed    TaskState = "failed"    // 最終的に失敗
+)
+
+// isTerminal は終了済みの状態かどうかを返す
+func (s TaskState) isTerminal() bool {
+	return s == TaskStateCompleted || s == TaskStateFailed
+}
+
+// TaskRecord はストアに永続化されるタスクの記録
+type TaskRecord struct {
+	Task        Task      `json:"task"`
+	State       TaskState `json:"state"`
+	NextRetryAt time.Time `json:"next_retry_at"` // リトライ待ちの場合の次回実行予定時刻
+	LastError   string    `json:"last_error,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	LeaseOwner     string    `json:"lease_owner,omitempty"` // 貸し出し先のインスタンス
+	LeaseExpiresAt time.Time `json:"lease_expires_at"`      // リースの有効期限
+	FenceToken     uint64    `json:"fence_token"`           // リースを取得するたびに増えるフェンシングトークン
+}
+
+// TaskStore はタスクの永続化先
+// 設定するとタスクの状態とリトライ予定が保存され、再起動後も処理が再開される
+type TaskStore interface {
+	// Save はタスクの記録を保存（同じIDの記録は上書き）
+	Save(record TaskRecord) error
+	// LoadPending は終了していないタスクの記録を返す
+	LoadPending() ([]TaskRecord, error)
+}
+
+// MemoryStore はメモリ上のタスクストア（プロセス内で複数のプールを共有する場合やテスト用）
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[int]TaskRecord
+}
+
+// NewMemoryStore は新しいメモリストアを作成
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[int]TaskRecord)}
+}
+
+// Save はタスクの記録を保存
+func (s *MemoryStore) Save(record TaskRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saveRecord(s.records, record)
+	return nil
+}
+
+// LoadPending は終了していないタスクの記録をID順に返す
+func (s *MemoryStore) LoadPending() ([]TaskRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return pendingRecords(s.records), nil
+}
+
+// FileStore はJSONファイルにタスクを保存するストア
+// 変更のたびにファイル全体を書き換える単純な実装で、単一プロセスからの利用を想定
+// （記録はメモリ上に持つため、リースも同じプロセス内でしか排他されない。複数プロセスで共有する場合は SharedFileStore を使う）
+type FileStore struct {
+	mu      sync.Mutex
+	path    string
+	records map[int]TaskRecord
+}
+
+// NewFileStore はファイルストアを作成し、既存のファイルがあれば読み込む
+func NewFileStore(path string) (*FileStore, error) {
+	records, err := readRecordsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{path: path, records: records}, nil
+}
+
+// Save はタスクの記録を保存し、ファイルに書き出す
+func (s *FileStore) Save(record TaskRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saveRecord(s.records, record)
+	return s.flush()
+}
+
+// LoadPending は終了していないタスクの記録をID順に返す
+func (s *FileStore) LoadPending() ([]TaskRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return pendingRecords(s.records), nil
+}
+
+// flush はすべての記録をファイルに書き出す（呼び出し側でロックを保持）
+func (s *FileStore) flush() error {
+	return writeRecordsFile(s.path, s.records)
+}
+
+// readRecordsFile はファイルから記録を読み込む（ファイルがない場合は空）
+func readRecordsFile(path string) (map[int]TaskRecord, error) {
+	records := make(map[int]TaskRecord)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("タスクストアの読み込みに失敗しました: %w", err)
+	}
+
+	var list []TaskRecord
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("タスクストアの形式が不正です: %w", err)
+	}
+	for _, record := range list {
+		records[record.Task.ID] = record
+	}
+	return records, nil
+}
+
+// writeRecordsFile はすべての記録を一時ファイルに書き出してから置き換える
+func writeRecordsFile(path string, records map[int]TaskRecord) error {
+	list := make([]TaskRecord, 0, len(records))
+	for _, record := range records {
+		list = append(list, record)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Task.ID < list[j].Task.ID })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("タスクストアの書き出しに失敗しました: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("タスクストアの書き出しに失敗しました: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("タスクストアの書き出しに失敗しました: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("タスクストアの書き出しに失敗しました: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// saveRecord は記録を上書きする。フェンシングトークンは単調増加を保つため引き継ぐ
+func saveRecord(records map[int]TaskRecord, record TaskRecord) {
+	if current, exists := records[record.Task.ID]; exists && record.FenceToken < current.FenceToken {
+		record.FenceToken = current.FenceToken
+	}
+	records[record.Task.ID] = record
+}
+
+// pendingRecords は終了していない記録をID順に返す
+func pendingRecords(records map[int]TaskRecord) []TaskRecord {
+	pending := make([]TaskRecord, 0)
+	for _, record := range records {
+		if !record.State.isTerminal() {
+			pending = append(pending, record)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Task.ID < pending[j].Task.ID })
+	return pending
+}
+
+// SetTaskStore はタスクの永続化先を設定する（Start 前に呼び出すこと）
+func (wp *WorkerPool) SetTaskStore(store TaskStore) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.store = store
+}
+
+// persist はタスクの状態をストアに保存する（ストア未設定の場合は何もしない）
+func (wp *WorkerPool) persist(task Task, state TaskState, err error) {
+	wp.mu.Lock()
+	store := wp.store
+	wp.mu.Unlock()
+	if store == nil {
+		return
+	}
+
+	if saveErr := store.Save(wp.newRecord(task, state, err)); saveErr != nil {
+		event("task.commit_failed").taskOf(task).failed(saveErr).logf("⚠️ タスク %d の状態を保存できませんでした: %v\n", task.ID, saveErr)
+	}
+}
+
+// newRecord はタスクの現在の状態から保存用の記録を作成する
+func (wp *WorkerPool) newRecord(task Task, state TaskState, err error) TaskRecord {
+	wp.mu.Lock()
+	instanceID := wp.instanceID
+	wp.mu.Unlock()
+
+	record := TaskRecord{
+		Task:        task,
+		State:       state,
+		NextRetryAt: task.nextRetryAt,
+		UpdatedAt:   time.Now(),
+	}
+	if state == TaskStatePending || state == TaskStateRetrying {
+		// リース有効時は、どのインスタンスのキューにあるかを記録
+		record.LeaseOwner = instanceID
+	}
+	if err != nil {
+		record.LastError = err.Error()
+	}
+	return record
+}
+
+// loadRecoverable はストアに残っている未完了のタスクのうち、このインスタンスが再開すべきものを返す
+// リース有効時は他のインスタンスが投入したタスクを対象外とする（放置されたものは引き取り処理に任せる）
+func (wp *WorkerPool) loadRecoverable(store TaskStore) []TaskRecord {
+	records, err := store.LoadPending()
+	if err != nil {
+		event("store.restore_failed").failed(err).logf("⚠️ タスクストアから復元できませんでした: %v\n", err)
+		return nil
+	}
+	if _, leasing := wp.leaseStore(); !leasing {
+		return records
+	}
+
+	own := make([]TaskRecord, 0, len(records))
+	for _, record := range records {
+		if record.LeaseOwner == "" || record.LeaseOwner == wp.instanceID {
+			own = append(own, record)
+		}
+	}
+	return own
+}
+
+// recoverTasks は未完了だったタスクを再投入する
+// リトライ待ちのタスクは保存されていた予定時刻にリトライされる
+func (wp *WorkerPool) recoverTasks(records []TaskRecord) {
+	defer wp.bgWg.Done()
+
+	if len(records) > 0 {
+		event("store.restoring").logf("♻️ タスクストアから %d 件の未完了タスクを復元します\n", len(records))
+	}
+
+	for _, record := range records {
+		task := record.Task
+		if record.LastError != "" {
+			task.LastError = fmt.Errorf("%s", record.LastError)
+		}
+
+		if record.State == TaskStateRetrying {
+			task.nextRetryAt = record.NextRetryAt
+			task = wp.retries.add(task)
+			select {
+			case wp.retryQueue <- task:
+			case <-wp.shutdownCh:
+				wp.retries.claim(task)
+				return
+			}
+			continue
+		}
+
+		// 予定時刻を待っていたタスク（AddTaskAt）は予定時刻まで待たせ直す
+		if record.State == TaskStatePending && record.NextRetryAt.After(time.Now()) {
+			wp.delayed.add(task, record.NextRetryAt)
+			continue
+		}
+
+		// キュー待ち・実行中だったタスクはそのまま再投入
+		if err := wp.enqueue(task); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrStreamNotRewindable はストリームを先頭に戻せないためリトライできない場合のエラー
+var ErrStreamNotRewindable = errors.New("ストリームを先頭に戻せないためリトライできません")
+
+// StreamPayload はタスクの Payload に入れるストリーム（大きなファイルをメモリに載せずに処理する用）
+// プロセッサは StreamOf で取り出して Reader から読み、Writer に書き込む。
+// Reader・Writer が io.Closer であれば、タスクが終わった時点（完了・最終的な失敗・受付の拒否）でプールが閉じるため、
+// 呼び出し元は Done で終わりを待ってから後片付けすればよい（io.Pipe の Writer であれば読み手に EOF が届く）。
+// リトライは Reader が io.Seeker、Writer が io.Seeker かつ Truncate を持つ場合（*os.File など）にのみ行い、
+// 試行の前に先頭に戻す。戻せない場合は最初の失敗で最終的な失敗とする
+type StreamPayload struct {
+	Reader io.Reader   // 入力（nil 可）
+	Writer io.Writer   // 出力（nil 可）
+	Meta   interface{} // ストリーム以外の引数（JSON にできる値）
+
+	mu     sync.Mutex
+	done   chan struct{}
+	closed bool
+	err    error // 閉じたときのエラー
+}
+
+// NewStreamPayload はストリームのペイロードを作成する
+func NewStreamPayload(r io.Reader, w io.Writer) *StreamPayload {
+	return &StreamPayload{Reader: r, Writer: w}
+}
+
+// StreamOf はタスクのペイロードがストリームであれば返す
+func StreamOf(task Task) (*StreamPayload, bool) {
+	stream, ok := task.Payload.(*StreamPayload)
+	return stream, ok && stream != nil
+}
+
+// Done はプールがストリームを閉じたときに閉じられるチャネルを返す
+func (s *StreamPayload) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done == nil {
+		s.done = make(chan struct{})
+		if s.closed {
+			close(s.done)
+		}
+	}
+	return s.done
+}
+
+// Err はストリームを閉じたときのエラーを返す（Done の後に呼び出すこと）
+func (s *StreamPayload) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// MarshalJSON はストリームの中身ではなく Meta だけを書き出す（ストア・記録・外部プロセスに渡す場合）
+func (s *StreamPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Stream bool        `json:"$stream"`
+		Meta   interface{} `json:"meta,omitempty"`
+	}{true, s.Meta})
+}
+
+// replayable はリトライの前に先頭に戻せるか判定
+func (s *StreamPayload) replayable() bool {
+	if s.Reader != nil {
+		if _, ok := s.Reader.(io.Seeker); !ok {
+			return false
+		}
+	}
+	if s.Writer != nil {
+		if _, ok := s.Writer.(truncateSeeker); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateSeeker は先頭に戻して中身を捨てられる出力（*os.File など）
+type truncateSeeker interface {
+	io.Seeker
+	Truncate(size int64) error
+}
+
+// rewind は前の試行で読み書きした位置を先頭に戻し、出力を空にする
+func (s *StreamPayload) rewind() error {
+	if !s.replayable() {
+		return ErrStreamNotRewindable
+	}
+	if seeker, ok := s.Reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("入力のストリームを先頭に戻せません: %w", err)
+		}
+	}
+	if writer, ok := s.Writer.(truncateSeeker); ok {
+		if err := writer.Truncate(0); err != nil {
+			return fmt.Errorf("出力のストリームを空にできません: %w", err)
+		}
+		if _, err := writer.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("出力のストリームを先頭に戻せません: %w", err)
+		}
+	}
+	return nil
+}
+
+// close は Reader・Writer を閉じる（2回目以降は何もしない）
+func (s *StreamPayload) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var errs []error
+	if closer, ok := s.Writer.(io.Closer); ok {
+		errs = append(errs, closer.Close())
+	}
+	// Reader と Writer が同じ値（io.ReadWriteCloser）の場合は二重に閉じない
+	if closer, ok := s.Reader.(io.Closer); ok && !sameStream(s.Reader, s.Writer) {
+		errs = append(errs, closer.Close())
+	}
+	s.err = errors.Join(errs...)
+	if s.done != nil {
+		close(s.done)
+	}
+	return s.err
+}
+
+// sameStream は Reader と Writer が同じ値か判定（比較できない型は別の値とみなす）
+func sameStream(r io.Reader, w io.Writer) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return w != nil && interface{}(r) == interface{}(w)
+}
+
+// streamReplayable はペイロードがストリームでないか、先頭に戻してリトライできるストリームか判定
+func streamReplayable(task Task) bool {
+	stream, ok := StreamOf(task)
+	return !ok || stream.replayable()
+}
+
+// rewindStream はリトライの試行の前にストリームを先頭に戻す（最初の試行とストリーム以外では何もしない）
+func rewindStream(task Task) error {
+	stream, ok := StreamOf(task)
+	if !ok || task.AttemptCount == 0 {
+		return nil
+	}
+	return stream.rewind()
+}
+
+// closeStream はタスクが終わったときにストリームを閉じる
+func closeStream(task Task) {
+	stream, ok := StreamOf(task)
+	if !ok {
+		return
+	}
+	if err := stream.close(); err != nil {
+		event("stream.close_failed").taskOf(task).failed(err).logf("⚠️ タスク %d のストリームを閉じる際にエラーが発生しました: %v\n", task.ID, err)
+	}
+}
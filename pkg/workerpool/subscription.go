@@ -0,0 +1,111 @@
+package workerpool
+
+import "sync"
+
+// resultSubscribers は結果の購読者（TypedPool ではサブプールと共有する）
+type resultSubscribers struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   map[int]func(TaskResult)
+}
+
+func newResultSubscribers() *resultSubscribers {
+	return &resultSubscribers{subs: make(map[int]func(TaskResult))}
+}
+
+// add は購読者を登録し、登録を解除する関数を返す（何度呼び出してもよい）
+func (s *resultSubscribers) add(fn func(TaskResult)) func() {
+	s.mu.Lock()
+	id := s.nextID
+	s.nextID++
+	s.subs[id] = fn
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.subs, id)
+	}
+}
+
+// active は購読者がいるかを返す
+func (s *resultSubscribers) active() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.subs) > 0
+}
+
+// notify はすべての購読者に結果を渡す
+func (s *resultSubscribers) notify(result TaskResult) {
+	s.mu.RLock()
+	subs := make([]func(TaskResult), 0, len(s.subs))
+	for _, fn := range s.subs {
+		subs = append(subs, fn)
+	}
+	s.mu.RUnlock()
+
+	for _, fn := range subs {
+		fn(result)
+	}
+}
+
+// resultStream は結果チャネルの受信側から結果を取り出して流すチャネル
+type resultStream struct {
+	inbox *resultInbox
+	ch    chan TaskResult
+}
+
+// newResultStream は inbox から結果を取り出し続け、結果チャネルが閉じられたら閉じるチャネルを作成する
+func newResultStream(inbox *resultInbox) *resultStream {
+	stream := &resultStream{inbox: inbox, ch: make(chan TaskResult)}
+	go func() {
+		defer close(stream.ch)
+		for {
+			result, ok := inbox.next(matchAll, nil)
+			if !ok {
+				return
+			}
+			stream.ch <- result
+		}
+	}()
+	return stream
+}
+
+// OnResult は結果（リトライ前の途中結果、Execute の結果を含む）を受け取る関数を登録し、登録を解除する関数を返す
+// 結果を取り出さずに観測するため、GetResult・Results・Execute の受け取りには影響しない。
+// GetResult・Results を使わずに OnResult だけで結果を受け取る場合もワーカーは止まらない
+// （取り出されていない結果は新しいものから一定数だけ保持する）。
+// fn はワーカーの goroutine から同期的に呼び出されるため、すぐに戻ること
+func (wp *WorkerPool) OnResult(fn func(TaskResult)) (unsubscribe func()) {
+	return wp.subscribers.add(fn)
+}
+
+// Results は結果を順に受け取るチャネルを返す（GetResult を決まった回数呼び出す代わりに使う）
+// プールの停止で閉じられ、再開後は Results を呼び出し直す。GetResult と併用した場合、結果はどちらか一方にだけ届く
+func (wp *WorkerPool) Results() <-chan TaskResult {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.stream == nil || wp.stream.inbox != wp.inbox {
+		wp.stream = newResultStream(wp.inbox)
+	}
+	return wp.stream.ch
+}
+
+// OnResult はすべてのサブプールの結果を受け取る関数を登録する（後から作成するサブプールにも適用する）
+func (tp *TypedPool) OnResult(fn func(TaskResult)) (unsubscribe func()) {
+	return tp.subscribers.add(fn)
+}
+
+// Results はすべてのサブプールの結果を順に受け取るチャネルを返す
+func (tp *TypedPool) Results() <-chan TaskResult {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if tp.stream == nil || tp.stream.inbox != tp.inbox {
+		tp.stream = newResultStream(tp.inbox)
+	}
+	return tp.stream.ch
+}
@@ -0,0 +1,68 @@
+package workerpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+// OnResult だけで結果を受け取る場合も、結果チャネルの容量を超えてタスクを処理し続ける
+func TestOnResultWithoutGetResultDoesNotStall(t *testing.T) {
+	pool := NewWorkerPool(2)
+	pool.RegisterProcessor("noop", func(ctx context.Context, task Task) error { return nil })
+	var seen atomic.Int32
+	pool.OnResult(func(result TaskResult) { seen.Add(1) })
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Stop()
+
+	tasks := 3 * cap(pool.results)
+	for id := 1; id <= tasks; id++ {
+		if err := pool.AddTask(Task{ID: id, Type: "noop"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool { return int(seen.Load()) == tasks })
+
+	// 取り出されていない結果は後から GetResult などで受け取れる
+	if got := len(pool.GetResultsWhere(matchAll, 0)); got != tasks {
+		t.Fatalf("保持されていた結果 = %d, want %d", got, tasks)
+	}
+}
+
+// TypedPool でも OnResult だけで結果を受け取る場合にサブプールが止まらない
+func TestTypedPoolOnResultWithoutGetResultDoesNotStall(t *testing.T) {
+	pool := NewTypedPool(map[TaskType]int{"noop": 2})
+	pool.RegisterProcessor("noop", func(ctx context.Context, task Task) error { return nil })
+	var seen atomic.Int32
+	pool.OnResult(func(result TaskResult) { seen.Add(1) })
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Stop()
+
+	tasks := 3 * cap(pool.results)
+	for id := 1; id <= tasks; id++ {
+		if err := pool.AddTask(Task{ID: id, Type: "noop"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool { return int(seen.Load()) == tasks })
+}
+
+// 取り出されていない結果は上限を超えると古いものから捨てる
+func TestUnclaimedResultsAreBounded(t *testing.T) {
+	results := make(chan TaskResult)
+	inbox := newResultInbox(results)
+	for id := 1; id <= unclaimedResultLimit+5; id++ {
+		inbox.deliver(results, TaskResult{TaskID: id}, true)
+	}
+	kept := inbox.available(matchAll)
+	if len(kept) != unclaimedResultLimit {
+		t.Fatalf("保持した結果 = %d, want %d", len(kept), unclaimedResultLimit)
+	}
+	if kept[0].TaskID != 6 || kept[len(kept)-1].TaskID != unclaimedResultLimit+5 {
+		t.Fatalf("保持した結果の範囲 = %d..%d", kept[0].TaskID, kept[len(kept)-1].TaskID)
+	}
+}
@@ -2,34 +2,106 @@ package workerpool
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math/rand"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Task struct {
-	ID           int
-	Name         string
-	Type         TaskType
-	Payload      interface{}
-	AttemptCount int       // リトライ回数
-	MaxRetries   int       // 最大リトライ回数
-	LastError    error     // 最後のエラー
-	CreatedAt    time.Time // タスクの作成日時
-	FirstAttempt time.Time // 最初の試行日時
+	ID           int               `json:"id"`
+	Name         string            `json:"name"`
+	Type         TaskType          `json:"type"`
+	Payload      interface{}       `json:"payload,omitempty"`
+	Checksum     string            `json:"checksum,omitempty"`   // ID・タイプ・ペイロードのチェックサム（ストア設定時に付け、実行の前に確認する）
+	SignedBy     string            `json:"signed_by,omitempty"`  // 署名した発行元の鍵の識別子
+	Checkpoint   json.RawMessage   `json:"checkpoint,omitempty"` // プロセッサが保存した進捗（次の実行で ResumeCheckpoint から受け取る）
+	Signature    string            `json:"signature,omitempty"`  // 発行元の署名（base64）
+	Labels       map[string]string `json:"labels,omitempty"`     // 任意のラベル（リージョン、顧客ティアなど）
+	Selector     map[string]string `json:"selector,omitempty"`   // 実行できるワーカーのラベル条件（すべて一致するワーカーにのみ割り当てる）
+	Hops         []string          `json:"hops,omitempty"`       // 転送してきたプールの識別子（経由順。転送のループ防止に使う）
+	Priority     Priority          `json:"priority"`             // 優先度（高いものから処理）
+	Sheddable    bool              `json:"sheddable"`            // 過負荷時に破棄してよいタスク
+	Deadline     time.Time         `json:"deadline"`             // 呼び出し元の期限（ゼロ値で無制限、過ぎたタスクは実行しない）
+	AttemptCount int               `json:"attempt_count"`        // リトライ回数
+	MaxRetries   int               `json:"max_retries"`          // 最大リトライ回数（0 はタイプのポリシーに従う、負の値はリトライしない）
+	Timeout      time.Duration     `json:"timeout_ns"`           // 実行のタイムアウト（0 はプールの設定に従う）
+	LastError    error             `json:"-"`                    // 最後のエラー
+	CreatedAt    time.Time         `json:"created_at"`           // タスクの作成日時
+	FirstAttempt time.Time         `json:"first_attempt"`        // 最初の試行日時
+
+	ctx         context.Context // 投入元のコンテキスト（キャンセルされると実行を中止する）
+	waiter      uint64          // Execute で最終結果を待っている場合の待機番号（0 は待っていない）
+	nextRetryAt time.Time       // 次のリトライ予定時刻
+	fenceToken  uint64          // 取得したリースのフェンシングトークン
+	orderSeq    uint64          // 結果の順序保証用の投入番号（0 は対象外）
+	output      string          // 直近の試行でプロセッサが書き込んだ出力
+	variant     string          // 直近の試行で使ったプロセッサの種別（カナリア設定中のみ）
+	retrySeq    uint64          // リトライ待ちへの登録番号（retrySchedule の照合用）
+	history     []AttemptError  // 試行ごとの失敗履歴
+	attempts    []attemptSpan   // 試行ごとの開始・終了（時間の内訳用）
+	span        trace.Span      // 投入から最終結果までのトレースのスパン（SetTracerProvider）
 }
 
 type TaskType string
 
+// Priority はタスクの優先度
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
 const (
-	TaskTypeEmail    TaskType = "email"
-	TaskTypeImage    TaskType = "image"
-	TaskTypeDatabase TaskType = "database"
-	TaskTypeReport   TaskType = "report"
+	TaskTypeEmail     TaskType = "email"
+	TaskTypeImage     TaskType = "image"
+	TaskTypeDatabase  TaskType = "database"
+	TaskTypeReport    TaskType = "report"
+	TaskTypeContainer TaskType = "container" // Docker コンテナで実行するタスク（DockerProcessor）
 )
 
 type TaskProcessor func(ctx context.Context, task Task) error
 
+// MatchesWorker はワーカーのラベルがタスクのセレクタをすべて満たすか判定
+// セレクタの値が "*" の場合はキーが存在すれば一致とみなす
+func (t *Task) MatchesWorker(labels map[string]string) bool {
+	for key, want := range t.Selector {
+		got, exists := labels[key]
+		if !exists || (want != "*" && got != want) {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrDeadlineExceeded は呼び出し元の期限を過ぎたため実行しなかったタスクのエラー
+var ErrDeadlineExceeded = errors.New("タスクの期限切れ: 呼び出し元の期限を過ぎています")
+
+// context は投入元のコンテキストを返す（未設定の場合は Background）
+func (t *Task) context() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+// deadlineExceeded は指定時刻の時点でタスクの期限を過ぎているか判定
+func (t *Task) deadlineExceeded(at time.Time) bool {
+	return !t.Deadline.IsZero() && !at.Before(t.Deadline)
+}
+
+// サンプルのプロセッサが返す一時的なエラー（タイプごとのリトライポリシーの RetryOn で指定する）
+var (
+	ErrSMTPConnection     = errors.New("SMTP接続エラー")
+	ErrDatabaseConnection = errors.New("データベース接続エラー")
+	ErrDataInconsistent   = errors.New("データ不整合エラー")
+)
+
 func EmailProcessor(ctx context.Context, task Task) error {
 	processingTime := time.Duration(1+rand.Intn(2)) * time.Second
 
@@ -42,7 +114,7 @@ func EmailProcessor(ctx context.Context, task Task) error {
 		}
 
 		if rand.Intn(100) < failureRate {
-			return errors.New("SMTP接続エラー: メール送信に失敗しました")
+			return fmt.Errorf("%w: メール送信に失敗しました", ErrSMTPConnection)
 		}
 		return nil
 	case <-ctx.Done():
@@ -57,7 +129,7 @@ func ImageProcessor(ctx context.Context, task Task) error {
 	case <-time.After(processingTime):
 		// 画像形式エラーはリトライしても改善されないことが多い
 		if rand.Intn(10) < 2 {
-			return errors.New("画像形式エラー: サポートされていない形式です")
+			return Permanent(errors.New("画像形式エラー: サポートされていない形式です"))
 		}
 		return nil
 	case <-ctx.Done():
@@ -77,7 +149,7 @@ func DatabaseProcessor(ctx context.Context, task Task) error {
 		}
 
 		if rand.Intn(100) < failureRate {
-			return errors.New("データベース接続エラー: タイムアウトしました")
+			return fmt.Errorf("%w: タイムアウトしました", ErrDatabaseConnection)
 		}
 		return nil
 	case <-ctx.Done():
@@ -97,7 +169,7 @@ func ReportProcessor(ctx context.Context, task Task) error {
 		}
 
 		if rand.Intn(100) < failureRate {
-			return errors.New("データ不整合エラー: レポート生成に必要なデータが不足しています")
+			return fmt.Errorf("%w: レポート生成に必要なデータが不足しています", ErrDataInconsistent)
 		}
 		return nil
 	case <-ctx.Done():
@@ -0,0 +1,268 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidTemplate はタスクテンプレートの定義・変数が不正な場合のエラー
+	ErrInvalidTemplate = errors.New("タスクテンプレートが不正です")
+	// ErrTemplateNotFound は指定した名前のタスクテンプレートがない場合のエラー
+	ErrTemplateNotFound = errors.New("タスクテンプレートが見つかりません")
+)
+
+// templateVariable はテンプレート中の変数の参照（{name}）
+var templateVariable = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// TaskTemplate はタスクのひな形（名前・タイプ・変数を含むペイロード）
+// ペイロード・タスク名・ラベルの文字列中の {name} を変数の値に置き換える。
+// 文字列全体が {name} の場合は値の型（数値・配列など）を保ったまま置き換える。
+// 優先度・タイムアウト・最大リトライ回数は作成するタスクの既定値で、投入時に指定した値が優先される
+type TaskTemplate struct {
+	Name      string                 `json:"name"`                // テンプレートの名前
+	Type      TaskType               `json:"type"`                // 作成するタスクのタイプ
+	TaskName  string                 `json:"task_name,omitempty"` // タスク名（空の場合はテンプレートの名前）
+	Payload   interface{}            `json:"payload,omitempty"`   // ペイロードのひな形
+	Labels    map[string]string      `json:"labels,omitempty"`    // タスクに付けるラベル
+	Variables map[string]interface{} `json:"variables,omitempty"` // 変数の既定値
+
+	Priority   Priority      `json:"priority,omitempty"`    // 既定の優先度
+	Timeout    time.Duration `json:"timeout_ns,omitempty"`  // 既定のタイムアウト（0 の場合はプールの設定）
+	MaxRetries int           `json:"max_retries,omitempty"` // 既定の最大リトライ回数（0 はタイプのポリシー、負の値はリトライしない）
+}
+
+// Validate はテンプレートの定義を検証する
+func (t TaskTemplate) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("%w: name を指定してください", ErrInvalidTemplate)
+	}
+	if t.Type == "" {
+		return fmt.Errorf("%w: テンプレート %s の type を指定してください", ErrInvalidTemplate, t.Name)
+	}
+	if t.Timeout < 0 {
+		return fmt.Errorf("%w: テンプレート %s の timeout に負の値は指定できません", ErrInvalidTemplate, t.Name)
+	}
+	if _, err := json.Marshal(t.Payload); err != nil {
+		return fmt.Errorf("%w: テンプレート %s のペイロード: %v", ErrInvalidTemplate, t.Name, err)
+	}
+	return nil
+}
+
+// Instantiate は変数を埋め込んだタスクを作成する（vars は既定値より優先する）
+func (t TaskTemplate) Instantiate(id int, vars map[string]interface{}) (Task, error) {
+	values := make(map[string]interface{}, len(t.Variables)+len(vars))
+	for name, value := range t.Variables {
+		values[name] = value
+	}
+	for name, value := range vars {
+		values[name] = value
+	}
+
+	// 型を問わず扱えるよう、ペイロードはJSONの値（map・スライス・文字列など）に変換してから埋め込む
+	var payload interface{}
+	if t.Payload != nil {
+		data, err := json.Marshal(t.Payload)
+		if err != nil {
+			return Task{}, fmt.Errorf("%w: テンプレート %s のペイロード: %v", ErrInvalidTemplate, t.Name, err)
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return Task{}, fmt.Errorf("%w: テンプレート %s のペイロード: %v", ErrInvalidTemplate, t.Name, err)
+		}
+	}
+	payload, err := expandValue(payload, values)
+	if err != nil {
+		return Task{}, fmt.Errorf("テンプレート %s のペイロード: %w", t.Name, err)
+	}
+
+	taskName := t.TaskName
+	if taskName == "" {
+		taskName = t.Name
+	}
+	if taskName, err = expandString(taskName, values); err != nil {
+		return Task{}, fmt.Errorf("テンプレート %s のタスク名: %w", t.Name, err)
+	}
+
+	var labels map[string]string
+	if len(t.Labels) > 0 {
+		labels = make(map[string]string, len(t.Labels))
+		for key, value := range t.Labels {
+			if labels[key], err = expandString(value, values); err != nil {
+				return Task{}, fmt.Errorf("テンプレート %s のラベル %s: %w", t.Name, key, err)
+			}
+		}
+	}
+
+	return Task{
+		ID:         id,
+		Name:       taskName,
+		Type:       t.Type,
+		Payload:    payload,
+		Labels:     labels,
+		Priority:   t.Priority,
+		Timeout:    t.Timeout,
+		MaxRetries: t.MaxRetries,
+	}, nil
+}
+
+// expandValue はJSONの値に含まれる文字列の変数を置き換える
+func expandValue(value interface{}, vars map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		// 文字列全体が1つの変数の場合は値をそのまま使う
+		if match := templateVariable.FindStringSubmatch(v); match != nil && match[0] == v {
+			resolved, ok := vars[match[1]]
+			if !ok {
+				return nil, fmt.Errorf("%w: 変数 %s が指定されていません", ErrInvalidTemplate, match[1])
+			}
+			return resolved, nil
+		}
+		return expandString(v, vars)
+	case map[string]interface{}:
+		for key, item := range v {
+			expanded, err := expandValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = expanded
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			expanded, err := expandValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// expandString は文字列中の変数を値の文字列表現に置き換える
+func expandString(s string, vars map[string]interface{}) (string, error) {
+	var missing string
+	expanded := templateVariable.ReplaceAllStringFunc(s, func(ref string) string {
+		name := ref[1 : len(ref)-1]
+		value, ok := vars[name]
+		if !ok {
+			if missing == "" {
+				missing = name
+			}
+			return ref
+		}
+		return fmt.Sprint(value)
+	})
+	if missing != "" {
+		return "", fmt.Errorf("%w: 変数 %s が指定されていません", ErrInvalidTemplate, missing)
+	}
+	return expanded, nil
+}
+
+// TemplateRegistry は名前でタスクテンプレートを管理する
+// スケジューラ・バックフィル・HTTP API で共有し、同じ定義からタスクを作成する
+type TemplateRegistry struct {
+	mu        sync.RWMutex
+	templates map[string]TaskTemplate
+}
+
+// NewTemplateRegistry はテンプレートを登録したレジストリを作成する
+func NewTemplateRegistry(templates ...TaskTemplate) (*TemplateRegistry, error) {
+	r := &TemplateRegistry{templates: make(map[string]TaskTemplate)}
+	for _, template := range templates {
+		if err := r.Add(template); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Add はテンプレートを登録する（同じ名前のテンプレートは置き換える）
+func (r *TemplateRegistry) Add(template TaskTemplate) error {
+	if err := template.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templates[template.Name] = template
+	return nil
+}
+
+// Get は名前のテンプレートを返す
+func (r *TemplateRegistry) Get(name string) (TaskTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	template, exists := r.templates[name]
+	if !exists {
+		return TaskTemplate{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return template, nil
+}
+
+// List は登録済みのテンプレートを名前の順に返す
+func (r *TemplateRegistry) List() []TaskTemplate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := make([]TaskTemplate, 0, len(r.templates))
+	for _, template := range r.templates {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// Instantiate は名前のテンプレートからタスクを作成する
+func (r *TemplateRegistry) Instantiate(name string, id int, vars map[string]interface{}) (Task, error) {
+	template, err := r.Get(name)
+	if err != nil {
+		return Task{}, err
+	}
+	return template.Instantiate(id, vars)
+}
+
+// SetTemplates はスケジュールの template 指定で使うテンプレートを設定する
+func (s *Scheduler) SetTemplates(templates *TemplateRegistry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.templates = templates
+}
+
+// SetTemplates は POST /tasks の template 指定と GET /templates で使うテンプレートを設定する
+func (m *Monitor) SetTemplates(templates *TemplateRegistry) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.templates = templates
+}
+
+// handleTemplates は GET /templates でテンプレートの一覧を返す
+func (m *Monitor) handleTemplates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "GET のみ対応しています")
+		return
+	}
+
+	m.mutex.RLock()
+	templates := m.templates
+	m.mutex.RUnlock()
+
+	list := []TaskTemplate{}
+	if templates != nil {
+		list = templates.List()
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"templates": list})
+}
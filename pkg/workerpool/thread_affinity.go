@@ -0,0 +1,18 @@
+package workerpool
+
+// SetLockOSThread はワーカーを専用のOSスレッドに固定するかを設定する（Start 前に呼び出すこと）
+// スレッドに依存するCライブラリ（CGO）を呼ぶプロセッサ向け。プロセッサはワーカーの
+// goroutine 上で同期的に実行されるため、プロセッサ内で新たに起動した goroutine は対象外
+func (wp *WorkerPool) SetLockOSThread(lock bool) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.lockOSThread = lock
+}
+
+// SetLockOSThread は指定したタイプのサブプールのワーカーをOSスレッドに固定する
+func (tp *TypedPool) SetLockOSThread(taskType TaskType, lock bool) {
+	if pool, exists := tp.SubPool(taskType); exists {
+		pool.SetLockOSThread(lock)
+	}
+}
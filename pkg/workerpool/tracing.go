@@ -0,0 +1,139 @@
+package workerpool
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName は OpenTelemetry の計装ライブラリ名
+const tracerName = "github.com/hizzuu/worker-example/pkg/workerpool"
+
+// noopTracer は TracerProvider を設定していない場合の何も記録しない Tracer
+var noopTracer = noop.NewTracerProvider().Tracer(tracerName)
+
+// スパンに付ける属性のキー
+const (
+	attrTaskID      = attribute.Key("task.id")
+	attrTaskName    = attribute.Key("task.name")
+	attrTaskType    = attribute.Key("task.type")
+	attrTaskAttempt = attribute.Key("task.attempt")
+	attrWorkerID    = attribute.Key("worker.id")
+	attrRetryAt     = attribute.Key("task.retry_at")
+	attrVariant     = attribute.Key("task.variant")
+)
+
+// SetTracerProvider はタスクのトレースを送る OpenTelemetry の TracerProvider を設定する（nil で無効）
+// タスクごとに投入から最終結果までのスパン（task）を作り、試行ごとに子スパン（task.attempt）を作る。
+// 投入元のコンテキストにスパンがあればその子になり、プロセッサのコンテキストには試行のスパンが入る
+func (wp *WorkerPool) SetTracerProvider(provider trace.TracerProvider) {
+	tracer := noopTracer
+	if provider != nil {
+		tracer = provider.Tracer(tracerName)
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.tracer = tracer
+}
+
+// SetTracerProvider はすべてのサブプールにトレースの送り先を設定する（後から作成するサブプールにも適用する）
+func (tp *TypedPool) SetTracerProvider(provider trace.TracerProvider) {
+	for _, pool := range tp.subPools() {
+		pool.SetTracerProvider(provider)
+	}
+	tp.mu.Lock()
+	tp.tracerProvider = provider
+	tp.mu.Unlock()
+}
+
+// tracerOf は設定された Tracer を返す（未設定の場合は何も記録しない Tracer）
+func (wp *WorkerPool) tracerOf() trace.Tracer {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.tracer == nil {
+		return noopTracer
+	}
+	return wp.tracer
+}
+
+// tracerOf は SetTracerProvider で設定した Tracer を返す（未設定の場合は何も記録しない Tracer）
+func (tp *TypedPool) tracerOf() trace.Tracer {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if tp.tracerProvider == nil {
+		return noopTracer
+	}
+	return tp.tracerProvider.Tracer(tracerName)
+}
+
+// startTaskSpan はタスクの投入から最終結果までのスパンを開始してタスクに持たせる
+func (wp *WorkerPool) startTaskSpan(task Task) Task {
+	_, span := wp.tracerOf().Start(task.context(), "task "+string(task.Type),
+		trace.WithAttributes(attrTaskID.Int(task.ID), attrTaskName.String(task.Name), attrTaskType.String(string(task.Type))))
+	task.span = span
+	return task
+}
+
+// taskSpan はタスクのスパンを返す（投入時にスパンを作っていないタスクは何も記録しないスパン）
+func taskSpan(task Task) trace.Span {
+	if task.span == nil {
+		return trace.SpanFromContext(context.Background())
+	}
+	return task.span
+}
+
+// startAttemptSpan は試行のスパンをタスクのスパンの子として開始し、スパンを入れたコンテキストを返す
+// 投入元のキャンセルは ctx から引き継ぐ
+func (wp *WorkerPool) startAttemptSpan(ctx context.Context, task Task, workerID int) (context.Context, trace.Span) {
+	if task.span != nil {
+		ctx = trace.ContextWithSpan(ctx, task.span)
+	}
+	return wp.tracerOf().Start(ctx, "task.attempt",
+		trace.WithAttributes(
+			attrTaskID.Int(task.ID),
+			attrTaskType.String(string(task.Type)),
+			attrTaskAttempt.Int(task.AttemptCount+1),
+			attrWorkerID.Int(workerID),
+		))
+}
+
+// endSpan はエラーがあればスパンに記録して終了する
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// endAttemptSpan は試行のスパンを結果とともに終了する
+func endAttemptSpan(span trace.Span, task Task, err error) {
+	if task.variant != "" {
+		span.SetAttributes(attrVariant.String(task.variant))
+	}
+	endSpan(span, err)
+}
+
+// traceRetry はタスクのスパンにリトライの予定を記録する
+func traceRetry(task Task, err error, retryAt time.Time) {
+	taskSpan(task).AddEvent("retry scheduled", trace.WithAttributes(
+		attrTaskAttempt.Int(task.AttemptCount+1),
+		attrRetryAt.String(retryAt.Format(time.RFC3339Nano)),
+		attribute.String("error", err.Error()),
+	))
+}
+
+// endTaskSpan はタスクのスパンを最終結果で終了する
+func endTaskSpan(task Task, err error) {
+	span := taskSpan(task)
+	span.SetAttributes(attrTaskAttempt.Int(task.AttemptCount + 1))
+	endSpan(span, err)
+}
@@ -0,0 +1,174 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrPayloadType はペイロードがタスクタイプの型として扱えない場合のエラー
+	ErrPayloadType = errors.New("ペイロードの型が一致しません")
+	// ErrInvalidPayload はペイロードの検証（PayloadValidator）に失敗した場合のエラー
+	ErrInvalidPayload = errors.New("ペイロードが不正です")
+)
+
+// PayloadValidator はペイロードの型が実装すると、投入時と実行の前に検証される
+type PayloadValidator interface {
+	Validate() error
+}
+
+// payloadTypeRegistry はタスクタイプごとのペイロードの型を記録できるプール
+type payloadTypeRegistry interface {
+	setPayloadType(taskType TaskType, payloadType reflect.Type) error
+	payloadType(taskType TaskType) reflect.Type
+}
+
+// RegisterTypedProcessor はペイロードを型 T で受け取るプロセッサを登録する
+//
+//	workerpool.RegisterTypedProcessor(pool, "invoice", func(ctx context.Context, invoice Invoice) error {
+//		return send(ctx, invoice.CustomerID, invoice.Amount)
+//	})
+//
+// ペイロードは T・*T のほか、JSON から復元した値（Web API・ストア・リモートを経由した場合）も T に変換して渡す。
+// 変換できない・検証に失敗したタスクはリトライせずに失敗する（エラーコード INVALID_PAYLOAD）。
+// 登録した型は AddTypedTask が投入時の確認に使う
+func RegisterTypedProcessor[T any](pool Pool, taskType TaskType, process func(ctx context.Context, payload T) error) error {
+	if err := pool.RegisterProcessor(taskType, TypedProcessor(process)); err != nil {
+		return err
+	}
+	if registry, ok := UnwrapPool(pool).(payloadTypeRegistry); ok {
+		return registry.setPayloadType(taskType, reflect.TypeFor[T]())
+	}
+	return nil
+}
+
+// TypedProcessor はペイロードを型 T に変換してから process を呼び出すプロセッサを返す
+// ReplaceProcessor・SetCanary などで型付きのプロセッサを使う場合に使う
+func TypedProcessor[T any](process func(ctx context.Context, payload T) error) TaskProcessor {
+	return func(ctx context.Context, task Task) error {
+		payload, err := DecodePayload[T](task)
+		if err != nil {
+			return Permanent(err)
+		}
+		return process(ctx, payload)
+	}
+}
+
+// AddTypedTask はペイロードを型 T として投入する
+// タイプに RegisterTypedProcessor で別の型が登録されている場合は ErrPayloadType、
+// 検証に失敗した場合は ErrInvalidPayload を返し、タスクは投入しない
+func AddTypedTask[T any](pool Pool, task Task, payload T) error {
+	if err := checkTypedPayload(pool, task.Type, payload); err != nil {
+		return fmt.Errorf("タスク %d: %w", task.ID, err)
+	}
+	task.Payload = payload
+	return pool.AddTask(task)
+}
+
+// DecodePayload はタスクのペイロードを型 T として取り出し、検証する
+// T・*T はそのまま使い、それ以外（JSON から復元した map や json.RawMessage など）は JSON を経由して変換する。
+// T にないフィールドを含む場合も型の不一致として扱う
+func DecodePayload[T any](task Task) (T, error) {
+	var payload T
+	switch p := task.Payload.(type) {
+	case T:
+		payload = p
+	case *T:
+		if p == nil {
+			return payload, fmt.Errorf("%w: タスク %d のペイロードが nil です (期待: %s)", ErrPayloadType, task.ID, reflect.TypeFor[T]())
+		}
+		payload = *p
+	case nil:
+		return payload, fmt.Errorf("%w: タスク %d にペイロードがありません (期待: %s)", ErrPayloadType, task.ID, reflect.TypeFor[T]())
+	default:
+		data, ok := p.(json.RawMessage)
+		if !ok {
+			var err error
+			if data, err = json.Marshal(p); err != nil {
+				return payload, fmt.Errorf("%w: タスク %d のペイロード (%T) をJSONに変換できません: %v", ErrPayloadType, task.ID, p, err)
+			}
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&payload); err != nil {
+			return payload, fmt.Errorf("%w: タスク %d のペイロード (%T) を %s として読み込めません: %v", ErrPayloadType, task.ID, p, reflect.TypeFor[T](), err)
+		}
+	}
+
+	if err := validatePayload(payload); err != nil {
+		return payload, fmt.Errorf("タスク %d: %w", task.ID, err)
+	}
+	return payload, nil
+}
+
+// checkTypedPayload は投入するペイロードの型を登録済みの型と照合し、検証する
+func checkTypedPayload[T any](pool Pool, taskType TaskType, payload T) error {
+	if registry, ok := UnwrapPool(pool).(payloadTypeRegistry); ok {
+		want, given := registry.payloadType(taskType), reflect.TypeFor[T]()
+		if want != nil && given != want && given != reflect.PointerTo(want) {
+			return fmt.Errorf("%w: タスクタイプ %s のペイロードは %s ですが %s が指定されました", ErrPayloadType, taskType, want, given)
+		}
+	}
+	return validatePayload(payload)
+}
+
+// validatePayload はペイロード（またはそのポインタ）が PayloadValidator を実装していれば検証する
+func validatePayload[T any](payload T) error {
+	validator, ok := any(payload).(PayloadValidator)
+	if !ok {
+		validator, ok = any(&payload).(PayloadValidator)
+	}
+	if !ok {
+		return nil
+	}
+	if err := validator.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return nil
+}
+
+// setPayloadType は登録済みのプロセッサのエントリにペイロードの型を記録する
+func (wp *WorkerPool) setPayloadType(taskType TaskType, payloadType reflect.Type) error {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	entry, exists := wp.processors[taskType]
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	entry.payloadType = payloadType
+	return nil
+}
+
+// payloadType はタイプのペイロードの型を返す（型付きのプロセッサでない場合は nil）
+func (wp *WorkerPool) payloadType(taskType TaskType) reflect.Type {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if entry, exists := wp.processors[taskType]; exists {
+		return entry.payloadType
+	}
+	return nil
+}
+
+// setPayloadType はタイプのサブプールにペイロードの型を記録する
+func (tp *TypedPool) setPayloadType(taskType TaskType, payloadType reflect.Type) error {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	return pool.setPayloadType(taskType, payloadType)
+}
+
+// payloadType はタイプのサブプールに記録したペイロードの型を返す
+func (tp *TypedPool) payloadType(taskType TaskType) reflect.Type {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return nil
+	}
+	return pool.payloadType(taskType)
+}
@@ -0,0 +1,309 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TypedPool はタスクタイプごとに独立したサブプールを持つプール
+// 各サブプールは専用のキューと並行数を持ち、タイプ間で処理が干渉しない
+type TypedPool struct {
+	mu          sync.Mutex
+	pools       map[TaskType]*WorkerPool
+	results     chan TaskResult
+	inbox       *resultInbox
+	stream      *resultStream      // Results で返すチャネル
+	subscribers *resultSubscribers // OnResult で登録された購読者（サブプールと共有する）
+	fanInWg     sync.WaitGroup
+	lifecycle   *lifecycle
+
+	recorder    *Recorder           // 投入されたタスクの記録先
+	maintenance []MaintenanceWindow // 後から作成するサブプールにも適用する
+	blackouts   *BlackoutCalendar   // 後から作成するサブプールにも適用する
+	sinks       []ResultSink        // 後から作成するサブプールにも適用する
+
+	tracerProvider trace.TracerProvider // 後から作成するサブプールにも適用する（nil で無効）
+}
+
+// NewTypedPool はタスクタイプごとのワーカー数を指定してプールを作成
+func NewTypedPool(workersByType map[TaskType]int) *TypedPool {
+	tp := &TypedPool{
+		pools:       make(map[TaskType]*WorkerPool),
+		results:     make(chan TaskResult, 10),
+		lifecycle:   newLifecycle(),
+		subscribers: newResultSubscribers(),
+	}
+	tp.inbox = newResultInbox(tp.results)
+	for taskType, workers := range workersByType {
+		pool := NewWorkerPool(workers)
+		pool.subscribers = tp.subscribers
+		tp.pools[taskType] = pool
+	}
+	return tp
+}
+
+// SubPool は指定したタイプのサブプールを返す（個別の設定変更用）
+func (tp *TypedPool) SubPool(taskType TaskType) (*WorkerPool, bool) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	pool, exists := tp.pools[taskType]
+	return pool, exists
+}
+
+// RegisterProcessor はタイプのサブプールにプロセッサを登録する（Start 前に呼び出すこと）
+// サブプールが未定義のタイプはワーカー1つのサブプールを作成する
+func (tp *TypedPool) RegisterProcessor(taskType TaskType, processor TaskProcessor) error {
+	tp.mu.Lock()
+	if tp.lifecycle.current() != StateCreated {
+		tp.mu.Unlock()
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrPoolStarted)
+	}
+	pool, exists := tp.pools[taskType]
+	if !exists {
+		pool = NewWorkerPool(1)
+		pool.maintenance = tp.maintenance
+		pool.blackouts = tp.blackouts
+		pool.sinks = append([]ResultSink(nil), tp.sinks...)
+		if tp.tracerProvider != nil {
+			pool.tracer = tp.tracerProvider.Tracer(tracerName)
+		}
+		pool.subscribers = tp.subscribers
+		tp.pools[taskType] = pool
+	}
+	tp.mu.Unlock()
+
+	return pool.RegisterProcessor(taskType, processor)
+}
+
+// ReplaceProcessor はタイプのサブプールのプロセッサを差し替える（開始後も可能）
+func (tp *TypedPool) ReplaceProcessor(taskType TaskType, processor TaskProcessor) error {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	return pool.ReplaceProcessor(taskType, processor)
+}
+
+// SwapProcessor はタイプのサブプールのプロセッサを差し替え、旧プロセッサで実行中のタスクが終わるまで待つ
+func (tp *TypedPool) SwapProcessor(ctx context.Context, taskType TaskType, processor TaskProcessor) error {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	return pool.SwapProcessor(ctx, taskType, processor)
+}
+
+// UnregisterProcessor はタイプのサブプールのプロセッサの登録を解除する
+func (tp *TypedPool) UnregisterProcessor(taskType TaskType) error {
+	pool, exists := tp.SubPool(taskType)
+	if !exists {
+		return fmt.Errorf("タスクタイプ %s: %w", taskType, ErrProcessorNotFound)
+	}
+	return pool.UnregisterProcessor(taskType)
+}
+
+// SetTaskTimeout はすべてのサブプールのタスクタイムアウトを設定
+func (tp *TypedPool) SetTaskTimeout(timeout time.Duration) {
+	for _, pool := range tp.subPools() {
+		pool.SetTaskTimeout(timeout)
+	}
+}
+
+// SetRetryPolicy はタイプのサブプールにリトライポリシーを設定
+func (tp *TypedPool) SetRetryPolicy(taskType TaskType, policy RetryPolicy) {
+	if pool, exists := tp.SubPool(taskType); exists {
+		pool.SetRetryPolicy(taskType, policy)
+	}
+}
+
+// RetryPolicy はタイプのサブプールのリトライポリシーを返す（サブプールがない場合はデフォルト）
+func (tp *TypedPool) RetryPolicy(taskType TaskType) RetryPolicy {
+	if pool, exists := tp.SubPool(taskType); exists {
+		return pool.RetryPolicy(taskType)
+	}
+	return DefaultRetryPolicy()
+}
+
+// AddTask はタスクをタイプのサブプールに投入する
+func (tp *TypedPool) AddTask(task Task) error {
+	tp.record(task)
+	pool, exists := tp.SubPool(task.Type)
+	if !exists {
+		closeStream(task)
+		return fmt.Errorf("タスクタイプ %s のサブプールが定義されていません", task.Type)
+	}
+	return pool.AddTask(task)
+}
+
+// Execute はタスクをタイプのサブプールに投入し、最終結果を待つ
+func (tp *TypedPool) Execute(ctx context.Context, task Task) (TaskResult, error) {
+	tp.record(task)
+	pool, exists := tp.SubPool(task.Type)
+	if !exists {
+		closeStream(task)
+		return TaskResult{}, fmt.Errorf("タスクタイプ %s のサブプールが定義されていません", task.Type)
+	}
+	return pool.Execute(ctx, task)
+}
+
+// GetResult はいずれかのサブプールの結果を取得する
+func (tp *TypedPool) GetResult() TaskResult {
+	result, _ := tp.resultInbox().next(matchAll, nil)
+	return result
+}
+
+// resultInbox は現在の結果の受信側を返す（再開で作り直すため tp.mu を取って読む）
+func (tp *TypedPool) resultInbox() *resultInbox {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	return tp.inbox
+}
+
+// Start はすべてのサブプールを開始し、結果を1つのチャネルに集約する
+// WorkerPool.Start と同様に、実行中は何もせず、停止済みの場合は設定を保ったまま再開する
+func (tp *TypedPool) Start() error {
+	started, _, err := tp.lifecycle.begin(func() {
+		// 実行中に遷移する前に、tp.mu を保持して結果のチャネルを作り直す
+		tp.mu.Lock()
+		defer tp.mu.Unlock()
+
+		tp.results = make(chan TaskResult, cap(tp.results))
+		tp.inbox = tp.inbox.carryOver(tp.results)
+	})
+	if err != nil || !started {
+		return err
+	}
+
+	var running []*WorkerPool
+	for taskType, pool := range tp.subPoolsByType() {
+		event("pool.subpool_started").logf("🧩 サブプール [%s] を開始します\n", taskType)
+		pool.resultInbox().claim() // サブプールの結果は下の goroutine がすべて取り出す
+		if err := pool.Start(); err != nil {
+			tp.abortStart(running)
+			return fmt.Errorf("サブプール %s: %w", taskType, err)
+		}
+		running = append(running, pool)
+
+		tp.fanInWg.Add(1)
+		go func(pool *WorkerPool) {
+			defer tp.fanInWg.Done()
+			for result := range pool.results {
+				tp.inbox.deliver(tp.results, result, tp.subscribers.active())
+			}
+		}(pool)
+	}
+	return nil
+}
+
+// abortStart はサブプールの開始に失敗した場合に、開始済みのサブプールを止めて停止済みに戻す
+// 呼び出し元は開始しなかったものとして扱うため、動き続けるサブプールを残さない（再度 Start できる）
+func (tp *TypedPool) abortStart(running []*WorkerPool) {
+	event("pool.start_failed").logf("⚠️ サブプールを開始できないため、開始済みの %d 個のサブプールを停止します\n", len(running))
+	for _, pool := range running {
+		pool.Stop()
+	}
+	tp.fanInWg.Wait()
+	close(tp.results)
+	tp.lifecycle.transition(StateStopped)
+}
+
+// Stop はすべてのサブプールを停止する
+func (tp *TypedPool) Stop() {
+	if _, err := tp.lifecycle.transition(StateDraining, StateCreated, StateRunning, StatePaused); err != nil {
+		return
+	}
+	for _, pool := range tp.subPools() {
+		pool.Stop()
+	}
+	tp.fanInWg.Wait()
+	close(tp.results)
+	tp.lifecycle.transition(StateStopped)
+}
+
+// Snapshot はすべてのサブプールの状態を合算して返す
+func (tp *TypedPool) Snapshot() PoolSnapshot {
+	total := PoolSnapshot{
+		State:        tp.State(),
+		QueuedByType: make(map[TaskType]int),
+		Shadow:       make(map[TaskType]ShadowStats),
+	}
+	for taskType, pool := range tp.subPoolsByType() {
+		snapshot := pool.Snapshot()
+		total.RunningWorkers += snapshot.RunningWorkers
+		total.ActiveWorkers += snapshot.ActiveWorkers
+		total.BusyTime += snapshot.BusyTime
+		total.QueuedTasks += snapshot.QueuedTasks
+		total.RetryingTasks += snapshot.RetryingTasks
+		total.DeferredTasks += snapshot.DeferredTasks
+		total.HeldTasks += snapshot.HeldTasks
+		total.DelayedTasks += snapshot.DelayedTasks
+		total.DeadLetters += snapshot.DeadLetters
+		total.OverrunTasks += snapshot.OverrunTasks
+		for taskType, count := range snapshot.QueuedByType {
+			total.QueuedByType[taskType] += count
+		}
+		total.Admission.Rejected += snapshot.Admission.Rejected
+		total.Admission.Shed += snapshot.Admission.Shed
+		total.Admission.Degraded += snapshot.Admission.Degraded
+		total.Admission.Deferred += snapshot.Admission.Deferred
+		for taskType, shadow := range snapshot.Shadow {
+			// サブプールはタイプごとに分かれているため、同じタイプが重なることはない
+			total.Shadow[taskType] = shadow
+		}
+		if snapshot.Memory != nil && (total.Memory == nil || snapshot.Memory.Pressure) {
+			// メモリ使用量はプロセス全体の値なので、受付を止めているサブプールがあればその状況を返す
+			total.Memory = snapshot.Memory
+		}
+		total.Downstream = append(total.Downstream, snapshot.Downstream...)
+		for taskType, heat := range snapshot.Heat {
+			if total.Heat == nil {
+				total.Heat = make(map[TaskType]TaskHeat)
+			}
+			total.Heat[taskType] = heat
+		}
+		total.Queues = mergeQueueStatus(total.Queues, snapshot.Queues)
+		total.Forwarding = mergeForwardStats(total.Forwarding, snapshot.Forwarding)
+		// ワーカーIDはサブプールごとに採番されるため、サブプールのタイプで区別する
+		for _, worker := range snapshot.Workers {
+			worker.Pool = taskType
+			total.Workers = append(total.Workers, worker)
+		}
+	}
+	sort.Slice(total.Downstream, func(i, j int) bool { return total.Downstream[i].TaskType < total.Downstream[j].TaskType })
+	sort.Slice(total.Workers, func(i, j int) bool {
+		if total.Workers[i].Pool != total.Workers[j].Pool {
+			return total.Workers[i].Pool < total.Workers[j].Pool
+		}
+		return total.Workers[i].WorkerID < total.Workers[j].WorkerID
+	})
+	return total
+}
+
+func (tp *TypedPool) subPools() []*WorkerPool {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	pools := make([]*WorkerPool, 0, len(tp.pools))
+	for _, pool := range tp.pools {
+		pools = append(pools, pool)
+	}
+	return pools
+}
+
+func (tp *TypedPool) subPoolsByType() map[TaskType]*WorkerPool {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	pools := make(map[TaskType]*WorkerPool, len(tp.pools))
+	for taskType, pool := range tp.pools {
+		pools[taskType] = pool
+	}
+	return pools
+}
@@ -10,18 +10,68 @@ import (
 func (m *Monitor) StartWebServer(port int) {
 	http.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
 		stats := m.GetStats()
+		if groupBy := r.URL.Query().Get("group_by"); groupBy != "" {
+			// ?group_by=type またはラベルキー（例: ?group_by=region）
+			stats = m.GetStatsGroupedBy(groupBy)
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		json.NewEncoder(w).Encode(stats)
 	})
 
+	http.HandleFunc("/stats/delta", m.handleStatsDelta)
+	http.HandleFunc("/tasks", m.requireAdmin(m.handleSubmitTask))
+	http.HandleFunc("/templates", m.requireAdmin(m.handleTemplates))
+	http.HandleFunc("/divergence", m.handleDivergence)
+	http.HandleFunc("/slo", m.handleSLO)
+	http.HandleFunc("/heat", m.handleHeat)
+	http.HandleFunc("/queues", m.handleQueues)
+	http.HandleFunc("/history", m.handleHistory)
+	http.HandleFunc("/capabilities", m.requireAdmin(m.handleCapabilities))
+	http.HandleFunc("/audit", m.requireAdmin(m.handleAudit))
+	http.HandleFunc("/config", m.requireAdmin(m.handleConfig))
+	http.HandleFunc("/state", m.handleState)
+	http.HandleFunc("/pause", m.requireAdmin(m.handleStateAction))
+	http.HandleFunc("/resume", m.requireAdmin(m.handleStateAction))
+	http.HandleFunc("/workers", m.requireAdmin(m.handleWorkers))
+	http.HandleFunc("/maintenance", m.handleMaintenance)
+	http.HandleFunc("/retries", m.requireAdmin(m.handleRetries))
+	http.HandleFunc("/retries/", m.requireAdmin(m.handleRetryAction))
+	http.HandleFunc("/dlq", m.requireAdmin(m.handleDeadLetters))
+	http.HandleFunc("/dlq/", m.requireAdmin(m.handleDeadLetterAction))
+	http.HandleFunc("/bulk/", m.requireAdmin(m.handleBulk))
+	http.HandleFunc("/api/docs", m.requireAdmin(m.handleAPIDocs))
+	http.HandleFunc("/api/docs/openapi.json", m.requireAdmin(m.handleOpenAPISpec))
+	http.HandleFunc("/api/docs/swagger-ui/", m.requireAdmin(m.handleSwaggerUIAssets))
+
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		fmt.Fprintf(w, getHTMLTemplate())
+		fmt.Fprint(w, getHTMLTemplate()) // テンプレート内の % を書式指定として解釈しない
 	})
 
-	fmt.Printf("🌐 Web監視画面: http://localhost:%d\n", port)
-	fmt.Printf("📊 JSON API: http://localhost:%d/stats\n", port)
+	event("web.started").logf("🌐 Web監視画面: http://localhost:%d\n", port)
+	if m.ReadOnly() {
+		event("web.started").logln("🔒 読み取り専用モード: 管理APIの変更系の操作は拒否します")
+	}
+	event("web.started").logf("📊 JSON API: http://localhost:%d/stats\n", port)
+	event("web.started").logf("📈 差分カウンタ: http://localhost:%d/stats/delta?since={token}\n", port)
+	event("web.started").logf("📮 タスク投入API: POST http://localhost:%d/tasks\n", port)
+	event("web.started").logf("🧩 タスクテンプレート: http://localhost:%d/templates\n", port)
+	event("web.started").logf("⏰ リトライ待ち: http://localhost:%d/retries\n", port)
+	event("web.started").logf("💀 DLQ: http://localhost:%d/dlq\n", port)
+	event("web.started").logf("📦 一括操作: POST http://localhost:%d/bulk/{cancel,requeue,priority}\n", port)
+	event("web.started").logf("🚦 プールの状態: http://localhost:%d/state (POST /pause, /resume)\n", port)
+	event("web.started").logf("📐 ワーカー数: http://localhost:%d/workers (POST {\"workers\": n} で変更)\n", port)
+	event("web.started").logf("🚧 メンテナンスウィンドウ: http://localhost:%d/maintenance\n", port)
+	event("web.started").logf("🎯 SLO: http://localhost:%d/slo\n", port)
+	event("web.started").logf("♨️ タスクの hot / cold: http://localhost:%d/heat\n", port)
+	event("web.started").logf("📬 名前付きキュー: http://localhost:%d/queues\n", port)
+	event("web.started").logf("🕘 タスク結果の履歴: http://localhost:%d/history?limit=100&type=email&status=failed\n", port)
+	event("web.started").logf("🧭 タスクタイプの実行環境: http://localhost:%d/capabilities\n", port)
+	event("web.started").logf("📝 設定変更の監査ログ: http://localhost:%d/audit\n", port)
+	event("web.started").logf("⚙️ 実行中の設定: http://localhost:%d/config\n", port)
+	event("web.started").logf("🔬 乖離レポート: http://localhost:%d/divergence\n", port)
+	event("web.started").logf("📖 APIエクスプローラ: http://localhost:%d/api/docs\n", port)
 	go http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
 }
 
@@ -97,6 +147,15 @@ func getHTMLTemplate() string {
             justify-content: space-between; 
             align-items: center;
         }
+        .brownout {
+            display: none;
+            background: #fff3cd;
+            color: #856404;
+            border: 1px solid #ffeeba;
+            padding: 15px;
+            border-radius: 8px;
+            margin-bottom: 20px;
+        }
         .task-types {
             background: white;
             padding: 20px;
@@ -106,7 +165,7 @@ func getHTMLTemplate() string {
         }
         .task-type-row {
             display: grid;
-            grid-template-columns: 1fr 1fr 1fr 1fr 1fr 1fr;
+            grid-template-columns: 1fr 1fr 1fr 1fr 1fr 1fr 1fr;
             gap: 15px;
             padding: 12px 10px;
             border-bottom: 1px solid #eee;
@@ -143,13 +202,67 @@ func getHTMLTemplate() string {
             font-style: italic;
         }
         
+        .dlq, .workers, .history {
+            background: white;
+            padding: 20px;
+            border-radius: 10px;
+            border: 1px solid #ddd;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            margin-top: 20px;
+        }
+        .dlq-toolbar {
+            display: flex;
+            gap: 10px;
+            margin-bottom: 10px;
+        }
+        .history-scroll {
+            max-height: 400px;
+            overflow-y: auto;
+        }
+        .dlq table, .workers table, .history table {
+            width: 100%;
+            border-collapse: collapse;
+            font-size: 14px;
+        }
+        .dlq th, .dlq td, .workers th, .workers td, .history th, .history td {
+            padding: 8px;
+            border-bottom: 1px solid #eee;
+            text-align: left;
+            vertical-align: top;
+        }
+        .dlq th, .workers th, .history th {
+            background: #f8f9fa;
+            color: #495057;
+            position: sticky;
+            top: 0;
+        }
+        .dlq button {
+            border: 1px solid #ccc;
+            background: #fff;
+            border-radius: 4px;
+            padding: 4px 10px;
+            cursor: pointer;
+        }
+        .dlq button.danger {
+            color: #dc3545;
+            border-color: #dc3545;
+        }
+        .dlq-detail {
+            background: #f8f9fa;
+            border-radius: 6px;
+            padding: 10px;
+            white-space: pre-wrap;
+            font-family: monospace;
+            font-size: 12px;
+        }
+        
         @media (max-width: 768px) {
             .stats {
                 grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
                 gap: 15px;
             }
             .task-type-row {
-                grid-template-columns: 1fr 60px 60px 60px 70px 80px;
+                grid-template-columns: 1fr 60px 60px 60px 70px 80px 100px;
                 gap: 8px;
                 font-size: 14px;
             }
@@ -174,11 +287,18 @@ func getHTMLTemplate() string {
                     updateElement('failed-tasks', data.failed_tasks || 0);
                     updateElement('queued-tasks', data.queued_tasks || 0);
                     updateElement('retrying-tasks', data.retrying_tasks || 0);
-                    updateElement('active-workers', (data.active_workers || 0) + '/' + (data.total_workers || 0));
+                    updateElement('active-workers', (data.active_workers || 0) + '/' + (data.total_workers || 0) + ' (' + (data.utilization_percent || 0).toFixed(0) + '%)');
                     updateElement('avg-time', (data.average_time_ms || 0).toFixed(1) + 'ms');
                     updateElement('min-time', (data.min_time_ms || 0).toFixed(1) + 'ms');
                     updateElement('max-time', (data.max_time_ms || 0).toFixed(1) + 'ms');
+                    updateElement('p50-time', (data.p50_time_ms || 0).toFixed(1) + 'ms');
+                    updateElement('p95-time', (data.p95_time_ms || 0).toFixed(1) + 'ms');
+                    updateElement('p99-time', (data.p99_time_ms || 0).toFixed(1) + 'ms');
+                    updateElement('avg-age', (data.average_age_ms || 0).toFixed(1) + 'ms');
                     updateElement('uptime', formatUptime(data.uptime_ms || 0));
+                    updateElement('pool-state', data.state || '-');
+                    updateElement('throughput', (data.throughput_per_sec || 0).toFixed(2) + '/s');
+                    updateElement('drain-eta', formatETA(data.drain_eta_ms));
                     
                     const successRate = data.total_tasks > 0 ? (data.completed_tasks / data.total_tasks * 100).toFixed(1) : 0;
                     updateElement('success-rate', successRate + '%');
@@ -199,6 +319,12 @@ func getHTMLTemplate() string {
                     // タスクタイプ別統計の更新
                     updateTaskTypeStats(data.task_type_stats);
                     
+                    // ワーカー別統計の更新
+                    updateWorkerStats(data.worker_stats);
+                    
+                    // ブラウンアウトの状態の更新
+                    updateBrownout(data.brownout);
+                    
                     // システム状態インジケーターの更新
                     updateSystemStatus(data);
                 })
@@ -234,6 +360,13 @@ func getHTMLTemplate() string {
             }
         }
         
+        function formatETA(etaMs) {
+            if (etaMs === undefined || etaMs < 0) {
+                return '算出不可';
+            }
+            return formatUptime(etaMs * 1000000);
+        }
+        
         function updateTaskTypeStats(taskTypeStats) {
             const container = document.getElementById('task-types-container');
             if (!taskTypeStats || Object.keys(taskTypeStats).length === 0) {
@@ -248,6 +381,7 @@ func getHTMLTemplate() string {
             html += '<div>失敗</div>';
             html += '<div>成功率</div>';
             html += '<div>平均時間</div>';
+            html += '<div>p95 / p99</div>';
             html += '</div>';
             
             Object.keys(taskTypeStats).sort().forEach(taskType => {
@@ -262,12 +396,60 @@ func getHTMLTemplate() string {
                 html += '<div class="failure">' + stats.failed + '</div>';
                 html += '<div class="' + statusColor + '">' + successRate + '%</div>';
                 html += '<div>' + stats.avg_time_ms.toFixed(1) + 'ms</div>';
+                html += '<div>' + (stats.p95_time_ms || 0).toFixed(1) + ' / ' + (stats.p99_time_ms || 0).toFixed(1) + 'ms</div>';
                 html += '</div>';
             });
             
             container.innerHTML = html;
         }
         
+        function updateWorkerStats(workerStats) {
+            const container = document.getElementById('workers-container');
+            if (!workerStats || workerStats.length === 0) {
+                container.innerHTML = '<div class="loading">起動中のワーカーはありません</div>';
+                return;
+            }
+            
+            let html = '<table><tr><th>ワーカー</th><th>状態</th><th>実行中のタスク</th><th>処理件数</th><th>エラー</th><th>稼働率</th><th>稼働 / 待機</th></tr>';
+            workerStats.forEach(worker => {
+                const total = worker.busy_time_ns + worker.idle_time_ns;
+                const utilization = total > 0 ? (worker.busy_time_ns / total * 100).toFixed(1) : 0;
+                let current = '-';
+                if (worker.current_task) {
+                    const task = worker.current_task;
+                    const elapsed = ((Date.now() - new Date(task.started_at).getTime()) / 1000).toFixed(1);
+                    current = '#' + task.id + ' ' + escapeHTML(task.type) + ':' + escapeHTML(task.name) + ' (' + elapsed + 's)';
+                }
+                html += '<tr>';
+                html += '<td><strong>' + (worker.pool ? escapeHTML(worker.pool) + '/' : '') + worker.worker_id + '</strong></td>';
+                html += '<td class="' + (worker.busy ? 'warning' : 'success') + '">' + (worker.busy ? '実行中' : '待機中') + '</td>';
+                html += '<td>' + current + '</td>';
+                html += '<td>' + worker.tasks_processed + '</td>';
+                html += '<td class="failure">' + worker.errors + '</td>';
+                html += '<td>' + utilization + '%</td>';
+                html += '<td>' + formatUptime(worker.busy_time_ns) + ' / ' + formatUptime(worker.idle_time_ns) + '</td>';
+                html += '</tr>';
+            });
+            html += '</table>';
+            container.innerHTML = html;
+        }
+        
+        function updateBrownout(brownout) {
+            const banner = document.getElementById('brownout-banner');
+            if (!brownout || !brownout.active) {
+                banner.style.display = 'none';
+                return;
+            }
+            const types = (brownout.disabled_types || []).map(escapeHTML).join(', ') || '(なし)';
+            let html = '<strong>🟤 ブラウンアウト中</strong>（' + new Date(brownout.since).toLocaleTimeString('ja-JP') + ' から、' + escapeHTML(brownout.reason || '') + '）<br>';
+            html += '受付を停止中のタイプ: ' + types + ' | リトライ: 最大 ' + brownout.retry_max_retries + ' 回、遅延 ×' + brownout.retry_delay_factor;
+            if (brownout.pending_ns > 0) {
+                html += ' | 負荷が下がってから ' + formatUptime(brownout.pending_ns);
+            }
+            banner.innerHTML = html;
+            banner.style.display = 'block';
+        }
+        
         function updateSystemStatus(data) {
             const statusElement = document.getElementById('system-status');
             let statusClass = 'status-running';
@@ -289,15 +471,167 @@ func getHTMLTemplate() string {
                 statusText = 'リトライ多数';
             }
             
+            if (data.brownout && data.brownout.active) {
+                statusClass = 'status-warning';
+                statusText = 'ブラウンアウト中';
+            }
+            
             statusElement.innerHTML = '<span class="status-indicator ' + statusClass + '"></span>' + statusText;
         }
         
+        // DLQ（管理用トークンが設定されている場合はブラウザの認証ダイアログで入力する）
+        let dlqEntries = [];
+        
+        function escapeHTML(s) {
+            return String(s).replace(/[&<>"']/g, c => ({'&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;'}[c]));
+        }
+        
+        function loadDLQ() {
+            fetch('/dlq')
+                .then(response => {
+                    if (!response.ok) throw new Error('HTTP ' + response.status);
+                    return response.json();
+                })
+                .then(data => {
+                    dlqEntries = data.dead_letters || [];
+                    renderDLQ();
+                })
+                .catch(error => {
+                    document.getElementById('dlq-container').innerHTML =
+                        '<div class="loading">DLQを取得できません: ' + escapeHTML(error.message) + '</div>';
+                });
+        }
+        
+        function renderDLQ() {
+            const container = document.getElementById('dlq-container');
+            if (dlqEntries.length === 0) {
+                container.innerHTML = '<div class="loading">DLQは空です</div>';
+                return;
+            }
+            
+            let html = '<table><tr><th><input type="checkbox" onchange="toggleAllDLQ(this.checked)"></th>';
+            html += '<th>ID</th><th>タスク</th><th>タイプ</th><th>理由</th><th>エラー</th><th>追加日時</th><th></th></tr>';
+            dlqEntries.forEach(entry => {
+                html += '<tr>';
+                html += '<td><input type="checkbox" class="dlq-select" value="' + entry.id + '"></td>';
+                html += '<td>' + entry.id + '</td>';
+                html += '<td>' + entry.task.id + ' ' + escapeHTML(entry.task.name || '') + '</td>';
+                html += '<td>' + escapeHTML(entry.task.type) + '</td>';
+                html += '<td>' + escapeHTML(entry.reason) + '</td>';
+                html += '<td class="failure">' + escapeHTML(entry.error || '') + '</td>';
+                html += '<td>' + new Date(entry.added_at).toLocaleString('ja-JP') + '</td>';
+                html += '<td><button onclick="showDLQ(' + entry.id + ')">詳細</button> ';
+                html += '<button onclick="dlqAction([' + entry.id + '], \'requeue\')">再投入</button> ';
+                html += '<button class="danger" onclick="dlqAction([' + entry.id + '], \'purge\')">削除</button></td>';
+                html += '</tr>';
+                html += '<tr id="dlq-detail-' + entry.id + '" style="display:none"><td colspan="8"></td></tr>';
+            });
+            html += '</table>';
+            container.innerHTML = html;
+        }
+        
+        function showDLQ(id) {
+            const row = document.getElementById('dlq-detail-' + id);
+            const entry = dlqEntries.find(e => e.id === id);
+            if (!row || !entry) return;
+            if (row.style.display !== 'none') {
+                row.style.display = 'none';
+                return;
+            }
+            let detail = 'ペイロード:\n' + JSON.stringify(entry.task.payload, null, 2) + '\n\n失敗履歴:\n';
+            (entry.history || []).forEach(h => {
+                detail += '  #' + h.attempt + ' ' + new Date(h.at).toLocaleString('ja-JP') + ' ' + h.error + '\n';
+            });
+            if (!entry.history || entry.history.length === 0) {
+                detail += '  (なし)\n';
+            }
+            row.firstElementChild.innerHTML = '<div class="dlq-detail">' + escapeHTML(detail) + '</div>';
+            row.style.display = '';
+        }
+        
+        function toggleAllDLQ(checked) {
+            document.querySelectorAll('.dlq-select').forEach(cb => cb.checked = checked);
+        }
+        
+        function selectedDLQ() {
+            return Array.from(document.querySelectorAll('.dlq-select:checked')).map(cb => Number(cb.value));
+        }
+        
+        function dlqAction(ids, action) {
+            if (ids.length === 0) {
+                alert('エントリを選択してください');
+                return;
+            }
+            if (action === 'purge' && !confirm(ids.length + ' 件を削除します。よろしいですか？')) {
+                return;
+            }
+            fetch('/dlq/' + action, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({ids: ids})
+            })
+                .then(response => response.json())
+                .then(data => {
+                    if (data.error) alert(data.error);
+                    loadDLQ();
+                })
+                .catch(error => alert(error.message));
+        }
+        
+        // タスク結果の履歴（新しい順）
+        const historyStatusLabels = {succeeded: '成功', failed: '失敗', retrying: 'リトライ', canceled: '取り消し'};
+        const historyStatusClasses = {succeeded: 'success', failed: 'failure', retrying: 'warning', canceled: 'info'};
+        
+        function loadHistory() {
+            const params = new URLSearchParams({limit: document.getElementById('history-limit').value});
+            const type = document.getElementById('history-type').value.trim();
+            const status = document.getElementById('history-status').value;
+            if (type) params.set('type', type);
+            if (status) params.set('status', status);
+            fetch('/history?' + params.toString())
+                .then(response => response.json())
+                .then(data => {
+                    if (data.error) throw new Error(data.error);
+                    renderHistory(data.history || []);
+                })
+                .catch(error => {
+                    document.getElementById('history-container').innerHTML =
+                        '<div class="loading">履歴を取得できません: ' + escapeHTML(error.message) + '</div>';
+                });
+        }
+        
+        function renderHistory(entries) {
+            const container = document.getElementById('history-container');
+            if (entries.length === 0) {
+                container.innerHTML = '<div class="loading">条件に一致する履歴はありません</div>';
+                return;
+            }
+            
+            let html = '<table><tr><th>終了日時</th><th>タスク</th><th>タイプ</th><th>状態</th><th>試行</th><th>ワーカー</th><th>処理時間</th><th>エラー</th></tr>';
+            entries.forEach(entry => {
+                html += '<tr>';
+                html += '<td>' + new Date(entry.end_time).toLocaleTimeString('ja-JP') + '</td>';
+                html += '<td>' + entry.task_id + ' ' + escapeHTML(entry.task_name || '') + '</td>';
+                html += '<td>' + escapeHTML(entry.task_type) + '</td>';
+                html += '<td class="' + (historyStatusClasses[entry.status] || '') + '">' + (historyStatusLabels[entry.status] || escapeHTML(entry.status)) + '</td>';
+                html += '<td>' + entry.attempt_count + '</td>';
+                html += '<td>' + entry.worker_id + '</td>';
+                html += '<td>' + (entry.duration_ns / 1000000).toFixed(1) + 'ms</td>';
+                html += '<td class="failure">' + (entry.error ? escapeHTML(entry.error.code + ': ' + entry.error.message) : '') + '</td>';
+                html += '</tr>';
+            });
+            html += '</table>';
+            container.innerHTML = html;
+        }
+        
         // 1秒ごとに更新
         setInterval(updateStats, 1000);
+        setInterval(loadHistory, 5000);
         
         // 初回読み込み
         document.addEventListener('DOMContentLoaded', function() {
             updateStats();
+            loadHistory();
         });
     </script>
 </head>
@@ -314,6 +648,8 @@ func getHTMLTemplate() string {
         </div>
     </div>
     
+    <div class="brownout" id="brownout-banner"></div>
+    
     <div class="stats">
         <div class="card">
             <div class="label">総タスク数</div>
@@ -355,10 +691,38 @@ func getHTMLTemplate() string {
             <div class="label">最大処理時間</div>
             <div class="metric" id="max-time">0ms</div>
         </div>
+        <div class="card">
+            <div class="label">処理時間 p50</div>
+            <div class="metric" id="p50-time">0ms</div>
+        </div>
+        <div class="card">
+            <div class="label">処理時間 p95</div>
+            <div class="metric warning" id="p95-time">0ms</div>
+        </div>
+        <div class="card">
+            <div class="label">処理時間 p99</div>
+            <div class="metric failure" id="p99-time">0ms</div>
+        </div>
+        <div class="card">
+            <div class="label">平均滞在時間</div>
+            <div class="metric" id="avg-age">0ms</div>
+        </div>
+        <div class="card">
+            <div class="label">スループット</div>
+            <div class="metric info" id="throughput">0/s</div>
+        </div>
+        <div class="card">
+            <div class="label">キュー消化予測</div>
+            <div class="metric warning" id="drain-eta">算出不可</div>
+        </div>
         <div class="card">
             <div class="label">稼働時間</div>
             <div class="metric info" id="uptime">0s</div>
         </div>
+        <div class="card">
+            <div class="label">プールの状態</div>
+            <div class="metric info" id="pool-state">-</div>
+        </div>
     </div>
     
     <div class="task-types">
@@ -367,6 +731,44 @@ func getHTMLTemplate() string {
             データを読み込み中...
         </div>
     </div>
+    
+    <div class="workers">
+        <h3>👷 ワーカー別統計</h3>
+        <div id="workers-container" class="loading">
+            データを読み込み中...
+        </div>
+    </div>
+    
+    <div class="history">
+        <h3>🕘 タスク結果の履歴</h3>
+        <div class="dlq-toolbar">
+            <input id="history-type" placeholder="タスクタイプ" onchange="loadHistory()">
+            <select id="history-status" onchange="loadHistory()">
+                <option value="">すべての状態</option>
+                <option value="failed">失敗</option>
+                <option value="retrying">リトライ</option>
+                <option value="canceled">取り消し</option>
+                <option value="succeeded">成功</option>
+            </select>
+            <select id="history-limit" onchange="loadHistory()">
+                <option value="100">100件</option>
+                <option value="500">500件</option>
+                <option value="1000">1000件</option>
+            </select>
+            <button onclick="loadHistory()">更新</button>
+        </div>
+        <div id="history-container" class="history-scroll loading">データを読み込み中...</div>
+    </div>
+    
+    <div class="dlq">
+        <h3>💀 デッドレターキュー</h3>
+        <div class="dlq-toolbar">
+            <button onclick="loadDLQ()">読み込み</button>
+            <button onclick="dlqAction(selectedDLQ(), 'requeue')">選択を再投入</button>
+            <button class="danger" onclick="dlqAction(selectedDLQ(), 'purge')">選択を削除</button>
+        </div>
+        <div id="dlq-container" class="loading">「読み込み」を押すと表示します</div>
+    </div>
 </body>
 </html>`
 }
@@ -0,0 +1,39 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoMatchingWorker はタスクのセレクタを満たすワーカーがいない場合のエラー
+var ErrNoMatchingWorker = errors.New("セレクタに一致するワーカーがありません")
+
+// SetWorkerLabels はこのプールのワーカーが持つラベル（version, region, gpu など）を設定する
+// 設定すると、セレクタを満たさないタスクは受け付けない（nil で無効化）
+// リモートのエージェントに割り当てるタイプでは、ラベルはエージェント側で宣言する
+func (wp *WorkerPool) SetWorkerLabels(labels map[string]string) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.workerLabels = labels
+}
+
+// WorkerLabels はこのプールのワーカーのラベルを返す
+func (wp *WorkerPool) WorkerLabels() map[string]string {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return wp.workerLabels
+}
+
+// checkSelector はタスクのセレクタをワーカーが満たすか確認する
+func (wp *WorkerPool) checkSelector(task Task) error {
+	wp.mu.Lock()
+	labels := wp.workerLabels
+	wp.mu.Unlock()
+
+	if labels == nil || task.MatchesWorker(labels) {
+		return nil
+	}
+	return fmt.Errorf("タスク %d (セレクタ: %v): %w", task.ID, task.Selector, ErrNoMatchingWorker)
+}
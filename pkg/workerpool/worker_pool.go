@@ -2,104 +2,368 @@ package workerpool
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrPoolStopped は停止済みのプールにタスクを投入した場合のエラー
+var ErrPoolStopped = errors.New("ワーカープールは停止しています")
+
 type WorkerPool struct {
-	tasks         chan Task
+	queue         *taskQueue
 	retryQueue    chan Task
 	results       chan TaskResult
+	inbox         *resultInbox       // 結果の受信側（条件付き取得用）
+	stream        *resultStream      // Results で返すチャネル
+	subscribers   *resultSubscribers // OnResult で登録された購読者
 	workers       int
 	wg            sync.WaitGroup
 	retryWg       sync.WaitGroup
-	processors    map[TaskType]TaskProcessor
+	bgWg          sync.WaitGroup // 補助的なバックグラウンド処理用
+	processors    map[TaskType]*processorEntry
 	retryPolicies map[TaskType]RetryPolicy
+	retryMu       sync.RWMutex // retryPolicies を保護する（実行中にポリシーを変更できるようにする）
 	taskTimeout   time.Duration
 	shutdownCh    chan struct{} // 🆕 シャットダウン用チャネル
+
+	mu           sync.Mutex
+	queuedByType map[TaskType]int // タイプ別のキュー滞留数
+
+	admission      *AdmissionPolicy // nil の場合はアドミッション制御なし
+	admissionStats AdmissionStats
+
+	shedPolicy   *ShedPolicy                  // nil の場合は負荷制御なし
+	brownout     atomic.Pointer[BrownoutMode] // nil の場合はブラウンアウトの制限なし（リトライの計算から参照するため wp.mu とは別に持つ）
+	deferred     []Task                       // 負荷制御で保留中のタスク
+	maintenance  []MaintenanceWindow          // タスクを実行しない時間帯
+	held         []Task                       // メンテナンスウィンドウ・ブラックアウト期間・下流の障害のため保留中のタスク
+	blackouts    *BlackoutCalendar            // nil の場合はブラックアウト期間なし
+	probes       map[TaskType]*healthProbe    // タスクタイプごとの下流のヘルスチェック
+	memHeapAlloc uint64                       // 直近のヒープ使用量
+	memSampledAt int64                        // ヒープ使用量の取得時刻（UnixNano）
+	dlq          *DeadLetterQueue
+
+	lifecycle    *lifecycle           // プールの状態（作成済み・実行中・一時停止中など）
+	running      int                  // 起動中のワーカー数
+	idle         int                  // タスク待ちのワーカー数
+	nextWorkerID int                  // 次に起動するワーカーのID
+	workerStats  *workerTracker       // ワーカーごとの実行状況
+	minWorkers   int                  // 遅延起動時に常駐させるワーカー数
+	idleTimeout  time.Duration        // 遅延起動時にアイドルワーカーを終了させるまでの時間（0で無効）
+	resized      chan struct{}        // Resize で縮小したときに閉じ、タスク待ちのワーカーを起こす
+	lockOSThread bool                 // ワーカーをOSスレッドに固定するか
+	cpuAffinity  []int                // ワーカーを実行するCPU（空の場合は限定しない）
+	workerLabels map[string]string    // ワーカーのラベル（タスクのセレクタと照合する）
+	canaries     map[TaskType]*canary // カナリア設定中のタスクタイプ
+	shadows      shadowState          // シャドー実行の設定と記録
+	heat         *heatTracker         // nil の場合は hot / cold の分類なし
+	router       *queueRouter         // nil の場合は名前付きキューなし（すべて共有キューで処理する）
+	memory       *memoryGuard         // nil の場合はメモリ使用量を監視しない
+	offloader    *payloadOffloader    // nil の場合はペイロードを退避しない
+	signer       TaskSigner           // nil の場合は投入時に署名しない
+	verifier     TaskVerifier         // nil の場合は実行前に署名を検証しない
+	forwarder    *forwarder           // nil の場合は処理できないタスクをピアに転送しない
+
+	waiters         map[uint64]chan TaskResult // 最終結果を個別に待っているタスク（Execute ごとの待機番号で引く）
+	waiterSeq       uint64                     // 最後に割り当てた待機番号
+	inFlight        map[*inFlightTask]struct{} // 実行中のタスク（Shutdown の期限切れで中断する）
+	checkpointed    []Task                     // Shutdown でチェックポイントを保存して戻ったタスク
+	checkpointGrace time.Duration              // Shutdown でチェックポイントの保存を待つ猶予（0 で待たずに中断する）
+	checkpoints     *checkpointStorage         // nil の場合は Checkpoint のたびにタスクストアに保存する
+	overrunGrace    time.Duration              // コンテキストの終了後にプロセッサが戻るまでの猶予（0 以下で既定値）
+	overruns        int64                      // 猶予を超えて戻ったタスクの累計（atomic）
+	store           TaskStore                  // nil の場合は永続化しない
+
+	instanceID        string        // リースの所有者として使うインスタンスID
+	visibilityTimeout time.Duration // リースの有効期間（0でリース無効）
+
+	orderer  *resultOrderer // nil の場合は完了順に結果を返す
+	recorder *Recorder      // nil の場合は投入を記録しない
+	sinks    []ResultSink   // 結果の出力先
+	retries  retrySchedule  // リトライ待ちのタスク
+	delayed  *timerWheel    // 予定時刻を待っているタスク（AddTaskAt）
+	tracer   trace.Tracer   // nil の場合はトレースを記録しない
 }
 
 func NewWorkerPool(workers int) *WorkerPool {
-	return &WorkerPool{
-		tasks:         make(chan Task, 10),
+	wp := &WorkerPool{
+		queue:         newTaskQueue(10),
 		retryQueue:    make(chan Task, 50), // リトライキューは大きめに
 		results:       make(chan TaskResult, 10),
 		workers:       workers,
-		processors:    make(map[TaskType]TaskProcessor),
+		processors:    make(map[TaskType]*processorEntry),
 		retryPolicies: TaskTypeRetryPolicies(), // デフォルトポリシーを設定
 		taskTimeout:   30 * time.Second,
 		shutdownCh:    make(chan struct{}),
+		queuedByType:  make(map[TaskType]int),
+		dlq:           NewDeadLetterQueue(1000),
+		minWorkers:    workers,
+		resized:       make(chan struct{}),
+		waiters:       make(map[uint64]chan TaskResult),
+		inFlight:      make(map[*inFlightTask]struct{}),
+		workerStats:   newWorkerTracker(),
+		retries:       newRetrySchedule(),
+		lifecycle:     newLifecycle(),
+		subscribers:   newResultSubscribers(),
+		delayed:       newTimerWheel(defaultWheelTick, defaultWheelSlots),
 	}
-}
-
-func (wp *WorkerPool) RegisterProcessor(taskType TaskType, processor TaskProcessor) {
-	wp.processors[taskType] = processor
+	wp.inbox = newResultInbox(wp.results)
+	wp.shadows = shadowState{
+		processors: make(map[TaskType]TaskProcessor),
+		sem:        make(chan struct{}, workers),
+		stats:      make(map[TaskType]ShadowStats),
+	}
+	return wp
 }
 
 func (wp *WorkerPool) SetTaskTimeout(timeout time.Duration) {
 	wp.taskTimeout = timeout
 }
 
+// timeoutFor はタスクの実行タイムアウトを返す（タスクに指定がない場合はプールの設定）
+func (wp *WorkerPool) timeoutFor(task Task) time.Duration {
+	if task.Timeout > 0 {
+		return task.Timeout
+	}
+	return wp.taskTimeout
+}
+
 func (wp *WorkerPool) SetRetryPolicy(taskType TaskType, policy RetryPolicy) {
+	wp.retryMu.Lock()
+	defer wp.retryMu.Unlock()
+
 	wp.retryPolicies[taskType] = policy
 }
 
-func (wp *WorkerPool) Start() {
-	fmt.Printf("🚀 %d個のワーカーを開始します\n", wp.workers)
+// RetryPolicy はタスクタイプに設定されたリトライポリシーを返す（未設定の場合はデフォルト）
+func (wp *WorkerPool) RetryPolicy(taskType TaskType) RetryPolicy {
+	wp.retryMu.RLock()
+	defer wp.retryMu.RUnlock()
 
-	for i := 0; i < wp.workers; i++ {
-		wp.wg.Add(1)
-		go wp.worker(i)
+	policy, exists := wp.retryPolicies[taskType]
+	if !exists {
+		return DefaultRetryPolicy()
+	}
+	return policy
+}
+
+// Start はワーカーを起動してタスクの処理を開始する
+// 実行中・一時停止中に呼び出した場合は何もしない。停止処理中は ErrInvalidStateTransition を返す。
+// 停止済みのプールはプロセッサやポリシーなどの設定を保ったまま再開する
+func (wp *WorkerPool) Start() error {
+	started, restart, err := wp.lifecycle.begin(wp.prepareRestart)
+	if err != nil || !started {
+		return err
+	}
+
+	wp.mu.Lock()
+	initial := wp.minWorkers
+	if initial > wp.workers {
+		initial = wp.workers
+	}
+	event("pool.started").logf("🚀 %d個のワーカーを開始します (最大 %d)\n", initial, wp.workers)
+	for i := 0; i < initial; i++ {
+		wp.spawnWorkerLocked()
 	}
+	wp.mu.Unlock()
 
 	wp.retryWg.Add(1)
 	go wp.retryHandler()
+
+	wp.bgWg.Add(1)
+	go wp.deferredReleaser()
+	wp.bgWg.Add(1)
+	go wp.delayedReleaser()
+
+	if router := wp.queueRouter(); router != nil {
+		wp.startQueueWorkers(router)
+	}
+	if heat := wp.heatTracker(); heat != nil {
+		heat.reopen()
+		wp.bgWg.Add(1)
+		go wp.heatClassifier(heat)
+	}
+	if guard := wp.memoryGuard(); guard != nil {
+		wp.bgWg.Add(1)
+		go wp.memoryWatcher(guard)
+	}
+	for _, probe := range wp.healthProbes() {
+		wp.bgWg.Add(1)
+		go wp.healthProber(probe)
+	}
+
+	wp.mu.Lock()
+	store := wp.store
+	wp.mu.Unlock()
+	if store != nil {
+		// 新たに投入されるタスクと区別するため、復元対象は開始時点で読み込む
+		records := wp.loadRecoverable(store)
+		wp.bgWg.Add(1)
+		go wp.recoverTasks(records)
+	}
+	if leaseStore, ok := wp.leaseStore(); ok {
+		wp.bgWg.Add(1)
+		go wp.leaseReclaimer(leaseStore)
+	}
+	if restart {
+		wp.rescheduleRetries()
+	}
+	return nil
+}
+
+// prepareRestart は停止時に閉じたチャネルとキューを作り直す（未取得の結果は引き継ぐ）
+// 実行中に遷移する前に呼び出され、チャネルは wp.mu を保持して入れ替える
+func (wp *WorkerPool) prepareRestart() {
+	event("pool.restarting").logln("♻️ 停止済みのワーカープールを再開します")
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.shutdownCh = make(chan struct{})
+	wp.retryQueue = make(chan Task, cap(wp.retryQueue))
+	wp.results = make(chan TaskResult, cap(wp.results))
+	wp.inbox = wp.inbox.carryOver(wp.results)
+	wp.queue.reopen()
+}
+
+// rescheduleRetries は停止時にリトライ待ちだったタスクを新しいリトライハンドラーに登録し直す
+func (wp *WorkerPool) rescheduleRetries() {
+	for _, task := range wp.retries.removeWhere(func(Task) bool { return true }) {
+		if wp.scheduleRetry(task) {
+			continue
+		}
+		// リトライキューに入りきらない場合は待たずにキューに戻す
+		if err := wp.enqueue(task); err != nil {
+			wp.dlq.Add(task, DeadLetterShutdown, ErrPoolStopped)
+		}
+	}
 }
 
 func (wp *WorkerPool) worker(id int) {
 	defer wp.wg.Done()
 
-	fmt.Printf("👷 ワーカー %d が開始されました\n", id)
+	defer wp.pinWorkerThread(id)()
+	defer wp.workerStats.started(id)()
+
+	event("worker.started").worker(id).logf("👷 ワーカー %d が開始されました\n", id)
 
-	for task := range wp.tasks {
+	for {
+		// 一時停止中は新たにタスクを取り出さない
+		wp.lifecycle.waitWhilePaused()
+
+		wp.mu.Lock()
+		if wp.running > wp.workers {
+			// Resize で縮小された分のワーカーは、次のタスクを取り出さずに終了する
+			wp.running--
+			wp.mu.Unlock()
+			event("worker.resized_exit").worker(id).logf("📉 ワーカー %d が縮小のため終了しました\n", id)
+			return
+		}
+		wp.idle++
+		idleTimeout := wp.idleTimeout
+		resized := wp.resized
+		wp.mu.Unlock()
+
+		task, err := wp.queue.pop(resized, idleTimeout)
+
+		wp.mu.Lock()
+		wp.idle--
+		if err == errQueueStopped {
+			// 縮小の通知で起こされた場合は、終了するかをループの先頭で判定する
+			wp.mu.Unlock()
+			continue
+		}
+		if err == errQueueTimeout {
+			// 常駐数を超えるアイドルワーカーは終了させる
+			if wp.running > wp.minWorkers {
+				wp.running--
+				wp.mu.Unlock()
+				event("worker.idle_exit").worker(id).logf("💤 ワーカー %d がアイドルのため終了しました\n", id)
+				return
+			}
+			wp.mu.Unlock()
+			continue
+		}
+		wp.mu.Unlock()
+
+		if err != nil {
+			break
+		}
+		wp.trackQueued(task.Type, -1)
+		// 取り出しを待っている間に一時停止した場合は、再開してから実行する
+		wp.lifecycle.waitWhilePaused()
+		// キュー待ち・リトライ待ちの間にメンテナンス・ブラックアウト期間に入ったタスクは実行しない
+		if wp.hold(task) {
+			continue
+		}
 		wp.executeTask(task, id)
 	}
 
-	fmt.Printf("🛑 ワーカー %d が終了しました\n", id)
+	wp.mu.Lock()
+	wp.running--
+	wp.mu.Unlock()
+
+	event("worker.stopped").worker(id).logf("🛑 ワーカー %d が終了しました\n", id)
 }
 
-// リトライハンドラー
+// retryHandler はリトライ待ちのタスクを予定時刻の早い順にヒープで管理し、予定時刻になったものからメインキューに戻す
+// 遅延の長いリトライが後から登録された短いリトライを待たせないよう、1件ずつ待たずにタイマーを先頭の予定時刻に合わせる
 func (wp *WorkerPool) retryHandler() {
 	defer wp.retryWg.Done()
 
-	fmt.Println("🔄 リトライハンドラーが開始されました")
+	event("retry_handler.started").logln("🔄 リトライハンドラーが開始されました")
+
+	var pending retryHeap
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
 
 	for {
 		select {
 		case task := <-wp.retryQueue:
-			policy, exists := wp.retryPolicies[task.Type]
-			if !exists {
-				policy = DefaultRetryPolicy()
+			// 管理操作で既に再投入・取り消しされたタスクは待たずに読み飛ばす
+			if !wp.retries.pending(task) {
+				continue
 			}
-
-			// リトライ遅延を計算
-			delay := policy.CalculateRetryDelay(task.AttemptCount)
-			fmt.Printf("⏰ タスク %d を %v 後にリトライします (試行回数: %d/%d)\n",
-				task.ID, delay, task.AttemptCount+1, policy.MaxRetries+1)
-
-			// 遅延後にメインキューに戻す
-			time.Sleep(delay)
-
-			select {
-			case wp.tasks <- task:
-				fmt.Printf("🔄 タスク %d をリトライキューから戻しました\n", task.ID)
-			case <-wp.shutdownCh:
-				return
+			policy := wp.retryPolicyFor(task)
+
+			// リトライ遅延を計算（予定時刻が決まっている場合はそれに従う）
+			delay := policy.RetryDelayFor(task.LastError, task.AttemptCount)
+			if !task.nextRetryAt.IsZero() {
+				delay = time.Until(task.nextRetryAt)
+				if delay < 0 {
+					delay = 0
+				}
+			}
+			event("task.retry_scheduled").taskOf(task).attempt(task.AttemptCount+1).took(delay).logf("⏰ タスク %d を %v 後にリトライします (試行回数: %d/%d)\n",
+				task.ID, delay.Round(time.Millisecond), task.AttemptCount+1, policy.MaxRetries+1)
+
+			pending.schedule(task, time.Now().Add(delay))
+			pending.resetTimer(timer)
+
+		case <-timer.C:
+			// 予定時刻を過ぎたタスクをメインキューに戻す
+			for _, task := range pending.popDue(time.Now()) {
+				task, claimed := wp.retries.claim(task)
+				if !claimed {
+					continue
+				}
+				if err := wp.enqueue(task); err != nil {
+					// 停止時はリトライ待ちのまま残し、再開時に登録し直す
+					wp.retries.add(task)
+					return
+				}
+				event("task.retry_enqueued").taskOf(task).logf("🔄 タスク %d をリトライキューから戻しました\n", task.ID)
 			}
+			pending.resetTimer(timer)
 
 		case <-wp.shutdownCh:
-			fmt.Println("🛑 リトライハンドラーが終了しました")
+			// 停止時はリトライ待ちのまま残し、再開時に登録し直す
+			event("retry_handler.stopped").logln("🛑 リトライハンドラーが終了しました")
 			return
 		}
 	}
@@ -116,69 +380,198 @@ func (wp *WorkerPool) executeTask(task Task, workerID int) {
 		attemptInfo = fmt.Sprintf(" (リトライ %d回目)", task.AttemptCount)
 	}
 
-	fmt.Printf("⚡ ワーカー %d がタスク %d (%s:%s) を処理中...%s\n", workerID, task.ID, task.Type, task.Name, attemptInfo)
+	task, leased := wp.acquireLease(task)
+	if !leased {
+		event("task.lease_skipped").taskOf(task).logf("🔒 タスク %d は他のインスタンスが処理中または処理済みのためスキップします\n", task.ID)
+		taskSpan(task).AddEvent("lease skipped")
+		endTaskSpan(task, nil)
+		wp.skipOrdered(task)
+		return
+	}
+
+	finishWorker := wp.workerStats.begin(workerID, task)
+	attemptCtx, attemptSpan := wp.startAttemptSpan(task.context(), task, workerID)
+	event("task.started").taskOf(task).worker(workerID).attempt(task.AttemptCount+1).logf("⚡ ワーカー %d がタスク %d (%s:%s) を処理中...%s\n", workerID, task.ID, task.Type, task.Name, attemptInfo)
 
 	// タスクを実行
 	var err error
-	processor, exists := wp.processors[task.Type]
+	var aborted bool // Shutdown の期限切れで中断された
+	// 実行中は旧プロセッサとして記録し、差し替え時に完了を待てるようにする
+	processor, releaseProcessor, variant, exists := wp.acquireProcessor(task)
+	task.variant = variant
 	if !exists {
 		err = fmt.Errorf("タスクタイプ %s のプロセッサが登録されていません", task.Type)
+	} else if task.deadlineExceeded(startTime) {
+		// 呼び出し元が既に諦めているタスクは実行しない
+		err = ErrDeadlineExceeded
+	} else if ctxErr := task.context().Err(); ctxErr != nil {
+		// 投入元がキャンセル済みのタスクは実行しない
+		err = ctxErr
+	} else if corruptErr := verifyChecksum(task); corruptErr != nil {
+		// 永続化の途中で壊れたタスクは実行しない
+		err = corruptErr
+	} else if trustErr := wp.verifySignature(task); trustErr != nil {
+		// 信頼する発行元の署名がないタスクは実行しない
+		err = trustErr
 	} else {
-		ctx, cancel := context.WithTimeout(context.Background(), wp.taskTimeout)
-		err = processor(ctx, task)
+		ctx, cancel := context.WithTimeout(attemptCtx, wp.timeoutFor(task))
+		if !task.Deadline.IsZero() {
+			// 呼び出し元の期限がタイムアウトより早い場合はそちらを優先
+			ctx, cancel = withDeadline(ctx, cancel, task.Deadline)
+		}
+		output := &outputBuffer{}
+		ctx = WithTaskOutput(ctx, output)
+		ctx, checkpoint := wp.withCheckpoint(ctx, task)
+		// CancelTask で取り消された場合は context.Cause が ErrTaskCanceled を返す
+		ctx, cancelTask := context.WithCancelCause(ctx)
+		stopLease := wp.keepLease(task, cancel)
+		finish := wp.trackInFlight(task, cancelTask, checkpoint)
+		returned := wp.watchOverrun(ctx, task, workerID)
+		// 退避したペイロードは実行するときだけ読み込み、キュー・リトライ待ちには参照のまま残す
+		run := task
+		if run.Payload, err = wp.rehydratePayload(ctx, task.Payload); err == nil {
+			if err = rewindStream(task); err == nil {
+				err = processor(ctx, run)
+			}
+		}
+		returned()
+		aborted = finish(err)
+		if err != nil && !isCanceled(err) && errors.Is(context.Cause(ctx), ErrTaskCanceled) {
+			// 取り消したタスクはプロセッサのエラーに関わらず取り消しとして扱う
+			err = fmt.Errorf("%w: %v", ErrTaskCanceled, err)
+		}
+		stopLease()
+		cancelTask(nil)
 		cancel()
+		task.output = output.String()
+		// リトライ・再起動後の実行は直近のチェックポイントから再開する
+		task.Checkpoint = wp.settleCheckpoint(task, checkpoint)
+
+		if !aborted {
+			primary := ShadowOutcome{Success: err == nil, Output: task.output, Duration: time.Since(startTime)}
+			if err != nil {
+				primary.Error = err.Error()
+			}
+			wp.runShadow(run, primary)
+		}
+	}
+	releaseProcessor()
+	finishWorker(err)
+	endAttemptSpan(attemptSpan, task, err)
+	if aborted {
+		// 中断したタスクは未完了として Shutdown の呼び出し元に返すため、結果もリトライも記録しない
+		event("task.aborted").taskOf(task).worker(workerID).logf("⛔ ワーカー %d: 停止期限を過ぎたためタスク %d を中断しました\n", workerID, task.ID)
+		taskSpan(task).AddEvent("aborted")
+		endTaskSpan(task, nil)
+		wp.skipOrdered(task)
+		return
 	}
 
 	endTime := time.Now()
 	duration := endTime.Sub(startTime)
 	totalDuration := endTime.Sub(task.FirstAttempt)
+	task.recordAttempt(startTime, endTime)
+	wp.observeHeat(task.Type, duration, endTime)
 
 	if err != nil {
-		// リトライ判定
-		policy, exists := wp.retryPolicies[task.Type]
-		if !exists {
-			policy = DefaultRetryPolicy()
+		// 下流の障害中の失敗は試行回数に数えず、回復するまで保留する
+		if wp.holdForDownstream(task, err) {
+			return
 		}
+		task.recordAttemptError(err, endTime)
 
-		if policy.ShouldRetry(err, task.AttemptCount) {
-			fmt.Printf("🔄 ワーカー %d: タスク %d が失敗、リトライします (エラー: %v)\n",
-				workerID, task.ID, err)
-
+		// リトライ判定
+		policy := wp.retryPolicyFor(task)
+
+		// 次の試行が呼び出し元の期限を過ぎる場合はリトライしない
+		retryAt := endTime.Add(policy.RetryDelayFor(err, task.AttemptCount+1))
+		canceled := task.context().Err() != nil
+		// 破損したタスク・信頼できないタスクはリトライしても直らない
+		reason := deadLetterReasonFor(err)
+		if policy.ShouldRetry(err, task.AttemptCount) && !task.deadlineExceeded(retryAt) && !canceled && reason == DeadLetterFailed && streamReplayable(task) {
 			// リトライ用にタスクを更新
 			task.AttemptCount++
 			task.LastError = err
+			task.nextRetryAt = retryAt
+			if wp.commit(task, TaskStateRetrying, err) != nil {
+				wp.skipOrdered(task)
+				return
+			}
 
-			// リトライキューに送信
-			select {
-			case wp.retryQueue <- task:
-			default:
+			event("task.retrying").taskOf(task).worker(workerID).attempt(task.AttemptCount).took(duration).failed(err).logf("🔄 ワーカー %d: タスク %d が失敗、リトライします (エラー: %v)\n",
+				workerID, task.ID, err)
+			traceRetry(task, err, retryAt)
+
+			// リトライ待ちに登録してリトライキューに送信
+			if !wp.scheduleRetry(task) {
 				// リトライキューが満杯の場合は失敗として処理
-				fmt.Printf("⚠️ リトライキューが満杯のため、タスク %d を失敗として処理します\n", task.ID)
-				wp.sendResult(task, err, duration, totalDuration, workerID, false)
+				event("task.retry_overflow").taskOf(task).failed(err).logf("⚠️ リトライキューが満杯のため、タスク %d を失敗として処理します\n", task.ID)
+				wp.dlq.Add(task, DeadLetterFailed, err)
+				wp.commit(task, TaskStateFailed, err)
+				wp.sendResult(task, err, duration, totalDuration, workerID, true)
 			}
 			return
 		} else {
-			fmt.Printf("❌ ワーカー %d: タスク %d が最終的に失敗 (試行回数: %d, エラー: %v)\n",
-				workerID, task.ID, task.AttemptCount+1, err)
+			if wp.commit(task, TaskStateFailed, err) != nil {
+				wp.skipOrdered(task)
+				return
+			}
+			switch reason {
+			case DeadLetterCorrupt:
+				event("task.corrupt").taskOf(task).worker(workerID).failed(err).logf("🧨 ワーカー %d: タスク %d の内容が破損しているため実行せずDLQに送ります (エラー: %v)\n",
+					workerID, task.ID, err)
+			case DeadLetterUntrusted:
+				event("task.untrusted").taskOf(task).worker(workerID).failed(err).logf("🔏 ワーカー %d: タスク %d は信頼できる発行元の署名がないため実行せずDLQに送ります (エラー: %v)\n",
+					workerID, task.ID, err)
+			case DeadLetterCanceled:
+				event("task.canceled").taskOf(task).worker(workerID).attempt(task.AttemptCount+1).took(duration).failed(err).logf("🚮 ワーカー %d: 実行中のタスク %d を取り消し、DLQに送りました\n",
+					workerID, task.ID)
+			default:
+				event("task.failed").taskOf(task).worker(workerID).attempt(task.AttemptCount+1).took(duration).failed(err).logf("❌ ワーカー %d: タスク %d が最終的に失敗 (試行回数: %d, エラー: %v)\n",
+					workerID, task.ID, task.AttemptCount+1, err)
+			}
+			wp.dlq.Add(task, reason, err)
 		}
 	} else {
+		if wp.commit(task, TaskStateCompleted, nil) != nil {
+			wp.skipOrdered(task)
+			return
+		}
 		successInfo := ""
 		if task.AttemptCount > 0 {
 			successInfo = fmt.Sprintf(" (%d回目で成功)", task.AttemptCount+1)
 		}
-		fmt.Printf("✅ ワーカー %d がタスク %d を完了%s (処理時間: %v, 総時間: %v)\n",
+		event("task.completed").taskOf(task).worker(workerID).attempt(task.AttemptCount+1).took(duration).logf("✅ ワーカー %d がタスク %d を完了%s (処理時間: %v, 総時間: %v)\n",
 			workerID, task.ID, successInfo, duration, totalDuration)
 	}
 
 	wp.sendResult(task, err, duration, totalDuration, workerID, true)
+	if err == nil {
+		wp.discardPayload(task)
+		wp.discardCheckpoint(task)
+	}
+}
+
+// withDeadline は既存のコンテキストに期限を追加し、両方を解放するキャンセル関数を返す
+func withDeadline(parent context.Context, parentCancel context.CancelFunc, deadline time.Time) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithDeadline(parent, deadline)
+	return ctx, func() {
+		cancel()
+		parentCancel()
+	}
 }
 
 func (wp *WorkerPool) sendResult(task Task, err error, duration, totalDuration time.Duration, workerID int, isFinal bool) {
+	endTaskSpan(task, err)
 	result := TaskResult{
 		TaskID:        task.ID,
 		TaskName:      task.Name,
 		TaskType:      task.Type,
+		Labels:        task.Labels,
+		Output:        task.output,
+		Variant:       task.variant,
 		Success:       err == nil,
+		Canceled:      isCanceled(err),
 		Error:         err,
 		Duration:      duration,
 		TotalDuration: totalDuration, // 🆕 リトライ含む総処理時間
@@ -187,43 +580,333 @@ func (wp *WorkerPool) sendResult(task Task, err error, duration, totalDuration t
 		EndTime:       time.Now(),
 		AttemptCount:  task.AttemptCount + 1, // 🆕 試行回数
 		IsFinal:       isFinal,               // 🆕 最終結果かどうか
+		CreatedAt:     task.CreatedAt,
+	}
+	if !task.CreatedAt.IsZero() {
+		result.Age = result.EndTime.Sub(task.CreatedAt)
+	}
+	// ワーカーが実行した結果は最後の試行の終了時点で締める（結果を返すまでの時間を待ちに数えない）
+	budgetEnd := result.EndTime
+	if workerID >= 0 && len(task.attempts) > 0 {
+		budgetEnd = task.attempts[len(task.attempts)-1].end
+	}
+	result.Budget = task.latencyBudget(wp.timeoutFor(task), budgetEnd)
+	if err != nil {
+		policy := wp.retryPolicyFor(task)
+		result.retryable = policy.isRetryableError(err)
+	}
+	// タスクが終わったため、結果を渡す前にストリームを閉じる（出力の読み手に EOF を届ける）
+	closeStream(task)
+	wp.exportResult(result)
+	wp.subscribers.notify(result)
+
+	// 個別に待っている呼び出し元がいれば最終結果をそちらに渡す
+	// （同じIDの別のタスクの結果を渡さないよう、Execute ごとの待機番号で照合する）
+	var (
+		waiter  chan TaskResult
+		waiting bool
+	)
+	if task.waiter != 0 && isFinal {
+		wp.mu.Lock()
+		waiter, waiting = wp.waiters[task.waiter]
+		delete(wp.waiters, task.waiter)
+		wp.mu.Unlock()
+	}
+	if waiting {
+		waiter <- result
+		wp.skipOrdered(task)
+		return
+	}
+
+	if orderer, ok := wp.resultOrdererFor(task); ok {
+		wp.deliverOrdered(orderer, task, &result)
+		return
+	}
+	wp.inbox.deliver(wp.results, result, wp.subscribers.active())
+}
+
+// Execute はタスクを投入し、そのタスクの最終結果（リトライ後を含む）が出るまで待つ
+// ワーカープールによる流量制御を保ったまま関数呼び出しのように使える。
+// ctx がキャンセルされると待機中・実行中のタスクもキャンセルされ、ctx.Err() を返す。
+// 結果はこの呼び出しにのみ返され、GetResult には流れない
+func (wp *WorkerPool) Execute(ctx context.Context, task Task) (TaskResult, error) {
+	waiter := make(chan TaskResult, 1) // 呼び出し元が先に戻ってもワーカーをブロックしない
+	wp.mu.Lock()
+	wp.waiterSeq++
+	task.waiter = wp.waiterSeq
+	wp.waiters[task.waiter] = waiter
+	wp.mu.Unlock()
+
+	task.ctx = ctx
+	if err := wp.AddTask(task); err != nil {
+		wp.mu.Lock()
+		delete(wp.waiters, task.waiter)
+		wp.mu.Unlock()
+		return TaskResult{}, err
+	}
+
+	select {
+	case result := <-waiter:
+		return result, nil
+	case <-ctx.Done():
+		return TaskResult{}, ctx.Err()
+	}
+}
+
+func (wp *WorkerPool) AddTask(task Task) error {
+	task = wp.startTaskSpan(task)
+	if err := wp.addTask(task); err != nil {
+		endTaskSpan(task, err)
+		// 受け付けなかったタスクのストリームはここで閉じる（受け付けた場合はタスクが終わった時点で閉じる）
+		closeStream(task)
+		return err
+	}
+	return nil
+}
+
+// addTask はタスクを受け付けてキューに投入する
+func (wp *WorkerPool) addTask(task Task) error {
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+	if wp.lifecycle.current() == StateDraining {
+		// 停止処理中は新しいタスクを受け付けない（リトライ待ち・保留中のタスクの再投入は続ける）
+		event("task.rejected").taskOf(task).failed(ErrPoolStopped).logf("🚫 タスク %d (%s) の受付を拒否しました: %v\n", task.ID, task.Name, ErrPoolStopped)
+		return ErrPoolStopped
+	}
+	return wp.acceptTask(task)
+}
+
+// acceptTask は受付の判定（転送・メモリ・アドミッション制御・負荷制御など）を行い、タスクをキューに投入する
+// 停止処理中の判定は呼び出し側で行う（予定時刻を待っていたタスクは停止処理中も投入する）
+func (wp *WorkerPool) acceptTask(task Task) error {
+	// プロセッサが登録されていないタイプは、処理できるピアがあればそちらに渡す
+	if !wp.hasProcessor(task.Type) && wp.tryForward(task, ForwardUnregistered) {
+		return nil
+	}
+	original := task // ピアにはブロブストアへの退避・署名の前のタスクを渡す
+	if err := wp.checkMemory(true); err != nil {
+		if wp.tryForward(original, ForwardOverCapacity) {
+			return nil
+		}
+		// メモリ使用量が下がるまで新しいタスクを受け付けない（リトライ・保留中のタスクの再投入は続ける）
+		event("task.rejected").taskOf(task).failed(err).logf("🚫 タスク %d (%s) の受付を拒否しました: %v\n", task.ID, task.Name, err)
+		return err
+	}
+	if err := wp.checkBrownout(task); err != nil {
+		// ブラウンアウト中は任意のタイプを受け付けない（受付済みのタスクは実行する）
+		event("task.rejected").taskOf(task).failed(err).logf("🚫 タスク %d (%s) の受付を拒否しました: %v\n", task.ID, task.Name, err)
+		return err
+	}
+	// 大きなペイロードはブロブストアに退避し、以降は参照だけを持ち回る
+	task, err := wp.offloadPayload(task)
+	if err != nil {
+		event("task.rejected").taskOf(task).failed(err).logf("🚫 タスク %d (%s) の受付を拒否しました: %v\n", task.ID, task.Name, err)
+		return err
+	}
+	// ストアを経由するタスクにはチェックサムを付け、実行の前に破損していないか確認する
+	if task, err = wp.sealTask(task); err != nil {
+		event("task.rejected").taskOf(task).failed(err).logf("🚫 タスク %d (%s) の受付を拒否しました: %v\n", task.ID, task.Name, err)
+		wp.discardPayload(task)
+		return err
+	}
+	if task, err = wp.signTask(task); err != nil {
+		event("task.rejected").taskOf(task).failed(err).logf("🚫 タスク %d (%s) の受付を拒否しました: %v\n", task.ID, task.Name, err)
+		wp.discardPayload(task)
+		return err
+	}
+	wp.record(task)
+	if err := wp.checkSelector(task); err != nil {
+		event("task.rejected").taskOf(task).failed(err).logf("🚫 タスク %d (%s) の受付を拒否しました: %v\n", task.ID, task.Name, err)
+		wp.discardPayload(task)
+		return err
+	}
+
+	task, err = wp.admit(task)
+	if err != nil && wp.tryForward(original, ForwardOverCapacity) {
+		wp.discardPayload(task)
+		return nil
+	}
+	if err != nil {
+		event("task.rejected").taskOf(task).failed(err).logf("🚫 タスク %d (%s) の受付を拒否しました: %v\n", task.ID, task.Name, err)
+		wp.discardPayload(task)
+		return err
+	}
+
+	if _, shedding := wp.shedAction(task); (shedding || wp.queueFull(task)) && wp.tryForward(original, ForwardOverCapacity) {
+		// 負荷制御で保留・破棄する・キューの空きを待つ代わりにピアに渡す
+		wp.discardPayload(task)
+		return nil
+	}
+	if handled, err := wp.shed(task); handled {
+		return err
+	}
+
+	task.nextRetryAt = time.Time{}
+	wp.mu.Lock()
+	orderer := wp.orderer
+	wp.mu.Unlock()
+	if orderer != nil {
+		orderer.assign(&task)
+	}
+	wp.persist(task, TaskStatePending, nil)
+
+	if wp.hold(task) {
+		return nil
+	}
+	if err := wp.enqueue(task); err != nil {
+		wp.skipOrdered(task)
+		wp.discardPayload(task)
+		return ErrPoolStopped
+	}
+	event("task.queued").taskOf(task).logf("📥 タスク %d (%s) がキューに追加されました\n", task.ID, task.Name)
+	return nil
+}
+
+// enqueue はタスクをキューに投入し、必要に応じてワーカーを追加起動する
+func (wp *WorkerPool) enqueue(task Task) error {
+	wp.trackQueued(task.Type, 1)
+	// ルーティング規則に一致したタスクは名前付きキューに並べ、その専用ワーカーだけが処理する
+	if routed, err := wp.routeTask(task); routed {
+		if err != nil {
+			wp.trackQueued(task.Type, -1)
+		}
+		return err
+	}
+	// hot のタイプは専用ワーカーが待っているレーンに優先して並べる
+	if wp.offerFastLane(task) {
+		return nil
+	}
+	if err := wp.queue.push(task, wp.shutdownSignal()); err != nil {
+		wp.trackQueued(task.Type, -1)
+		return err
+	}
+
+	wp.mu.Lock()
+	if state := wp.lifecycle.current(); (state == StateRunning || state == StatePaused || state == StateDraining) && wp.idle == 0 && wp.running < wp.workers {
+		wp.spawnWorkerLocked()
 	}
+	wp.mu.Unlock()
+	return nil
+}
+
+// shutdownSignal は現在のシャットダウン用チャネルを返す
+// 再開で作り直すため、Start より後に起動した goroutine 以外からは wp.mu を取って読む
+func (wp *WorkerPool) shutdownSignal() chan struct{} {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return wp.shutdownCh
+}
+
+// resultInbox は現在の結果の受信側を返す（再開で作り直すため wp.mu を取って読む）
+func (wp *WorkerPool) resultInbox() *resultInbox {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
 
-	wp.results <- result
+	return wp.inbox
 }
 
-func (wp *WorkerPool) AddTask(task Task) {
-	wp.tasks <- task
-	fmt.Printf("📥 タスク %d (%s) がキューに追加されました\n", task.ID, task.Name)
+// spawnWorkerLocked はワーカーを1つ起動する（wp.mu を保持して呼び出すこと）
+func (wp *WorkerPool) spawnWorkerLocked() {
+	id := wp.nextWorkerID
+	wp.nextWorkerID++
+	wp.running++
+	wp.wg.Add(1)
+	go wp.worker(id)
+}
+
+// SetLazyStart はワーカーの遅延起動を設定する
+// 開始時は minWorkers 個だけ起動し、タスクが溜まると最大数まで追加起動する。
+// idleTimeout を超えてアイドルだったワーカーは minWorkers を下回らない範囲で終了させる
+func (wp *WorkerPool) SetLazyStart(minWorkers int, idleTimeout time.Duration) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if minWorkers < 0 {
+		minWorkers = 0
+	}
+	wp.minWorkers = minWorkers
+	wp.idleTimeout = idleTimeout
+}
+
+// RunningWorkers は現在起動中のワーカー数を返す
+func (wp *WorkerPool) RunningWorkers() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return wp.running
+}
+
+// trackQueued はタイプ別のキュー滞留数を増減する
+func (wp *WorkerPool) trackQueued(taskType TaskType, delta int) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.queuedByType[taskType] += delta
+	if wp.queuedByType[taskType] <= 0 {
+		delete(wp.queuedByType, taskType)
+	}
+}
+
+// QueuedByType はタイプ別のキュー滞留数を返す
+func (wp *WorkerPool) QueuedByType() map[TaskType]int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	queued := make(map[TaskType]int, len(wp.queuedByType))
+	for taskType, count := range wp.queuedByType {
+		queued[taskType] = count
+	}
+	return queued
 }
 
 // 🆕 結果を取得する関数
 func (wp *WorkerPool) GetResult() TaskResult {
-	return <-wp.results
+	result, _ := wp.resultInbox().next(matchAll, nil)
+	return result
 }
 
 // 🆕 指定した数の結果を取得する関数
 func (wp *WorkerPool) GetResults(count int) []TaskResult {
 	results := make([]TaskResult, 0, count)
+	inbox := wp.resultInbox()
 	for i := 0; i < count; i++ {
-		result := <-wp.results
+		result, _ := inbox.next(matchAll, nil)
 		results = append(results, result)
 	}
 	return results
 }
 
+// Stop は残りのタスクを処理してからワーカーを停止する（停止処理中・停止済みの場合は何もしない）
 func (wp *WorkerPool) Stop() {
-	fmt.Println("🔄 ワーカープールを停止中...")
+	// シャットダウンシグナルを送信（一時停止中のワーカーも再開して残りを処理する）
+	if _, err := wp.lifecycle.transition(StateDraining, StateCreated, StateRunning, StatePaused); err != nil {
+		return
+	}
+	event("pool.stopping").logln("🔄 ワーカープールを停止中...")
+	wp.halt()
+	wp.lifecycle.transition(StateStopped)
+	event("pool.stopped").logln("✋ ワーカープールが停止しました")
+}
 
-	// シャットダウンシグナルを送信
+// halt はワーカー・リトライハンドラー・バックグラウンド処理を止め、結果チャネルを閉じる
+// キューに残ったタスクはワーカーが処理してから終了する
+func (wp *WorkerPool) halt() {
 	close(wp.shutdownCh)
 
-	close(wp.tasks) // タスクチャネルを閉じる
-	wp.wg.Wait()    // すべてのワーカーの完了を待つ
+	if heat := wp.heatTracker(); heat != nil {
+		heat.closeAll() // 専用レーンを閉じる（並んでいるタスクは専用ワーカーが処理する）
+	}
+	if router := wp.queueRouter(); router != nil {
+		router.closeAll() // 名前付きキューを閉じる（並んでいるタスクは専用ワーカーが処理する）
+	}
+	wp.queue.close() // タスクキューを閉じる
+	wp.wg.Wait()     // すべてのワーカーの完了を待つ
 
+	wp.bgWg.Wait()       // バックグラウンド処理の完了を待つ
 	close(wp.retryQueue) // リトライキューを閉じる
 	wp.retryWg.Wait()    // リトライハンドラーの完了を待つ
 
 	close(wp.results) // 結果チャネルも閉じる
-	fmt.Println("✋ ワーカープールが停止しました")
 }
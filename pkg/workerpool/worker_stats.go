@@ -0,0 +1,138 @@
+package workerpool
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// WorkerStats はワーカーごとの統計
+type WorkerStats struct {
+	WorkerID       int           `json:"worker_id"`
+	Pool           TaskType      `json:"pool,omitempty"` // TypedPool のサブプールのタイプ（ワーカーIDはサブプールごとに採番される）
+	Busy           bool          `json:"busy"`
+	CurrentTask    *CurrentTask  `json:"current_task,omitempty"`
+	TasksProcessed int64         `json:"tasks_processed"`
+	Errors         int64         `json:"errors"`
+	BusyTime       time.Duration `json:"busy_time_ns"`
+	IdleTime       time.Duration `json:"idle_time_ns"`
+	StartedAt      time.Time     `json:"started_at"`
+}
+
+// CurrentTask はワーカーが実行中のタスク
+type CurrentTask struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Type      TaskType  `json:"type"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// workerRecord はワーカー1つ分の集計
+type workerRecord struct {
+	startedAt time.Time
+	current   *CurrentTask
+	processed int64
+	errors    int64
+	busy      time.Duration // 完了したタスクの実行時間の合計
+}
+
+// workerTracker はワーカーごとの実行状況を記録する
+type workerTracker struct {
+	mu      sync.Mutex
+	workers map[int]*workerRecord
+	busy    time.Duration // 完了したタスクの実行時間の合計（終了したワーカーの分も含む）
+}
+
+func newWorkerTracker() *workerTracker {
+	return &workerTracker{workers: make(map[int]*workerRecord)}
+}
+
+// started はワーカーの起動を記録し、終了時に呼ぶ関数を返す
+func (t *workerTracker) started(workerID int) func() {
+	t.mu.Lock()
+	t.workers[workerID] = &workerRecord{startedAt: time.Now()}
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		delete(t.workers, workerID)
+	}
+}
+
+// begin はワーカーがタスクの実行を始めたことを記録し、終了時にエラーを渡して呼ぶ関数を返す
+func (t *workerTracker) begin(workerID int, task Task) func(err error) {
+	now := time.Now()
+	t.mu.Lock()
+	record, exists := t.workers[workerID]
+	if exists {
+		record.current = &CurrentTask{ID: task.ID, Name: task.Name, Type: task.Type, StartedAt: now}
+	}
+	t.mu.Unlock()
+	if !exists {
+		return func(error) {}
+	}
+
+	return func(err error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		elapsed := time.Since(now)
+		record.busy += elapsed
+		t.busy += elapsed
+		record.current = nil
+		record.processed++
+		if err != nil {
+			record.errors++
+		}
+	}
+}
+
+// utilization は実行中のワーカー数と、起動以降のすべてのワーカーの実行時間の合計（実行中の分を含む）を返す
+func (t *workerTracker) utilization() (int, time.Duration) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	active, busy := 0, t.busy
+	for _, record := range t.workers {
+		if record.current != nil {
+			active++
+			busy += now.Sub(record.current.StartedAt)
+		}
+	}
+	return active, busy
+}
+
+// snapshot はワーカーごとの統計をワーカーID順に返す
+func (t *workerTracker) snapshot() []WorkerStats {
+	now := time.Now()
+	t.mu.Lock()
+	stats := make([]WorkerStats, 0, len(t.workers))
+	for workerID, record := range t.workers {
+		busy := record.busy
+		var current *CurrentTask
+		if record.current != nil {
+			busy += now.Sub(record.current.StartedAt)
+			task := *record.current
+			current = &task
+		}
+		stats = append(stats, WorkerStats{
+			WorkerID:       workerID,
+			Busy:           current != nil,
+			CurrentTask:    current,
+			TasksProcessed: record.processed,
+			Errors:         record.errors,
+			BusyTime:       busy,
+			IdleTime:       max(now.Sub(record.startedAt)-busy, 0),
+			StartedAt:      record.startedAt,
+		})
+	}
+	t.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].WorkerID < stats[j].WorkerID
+	})
+	return stats
+}
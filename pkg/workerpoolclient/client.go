@@ -0,0 +1,180 @@
+// Package workerpoolclient は別のプロセスで動くワーカープールにタスクを投入するクライアントを提供する
+//
+// プールのWebサーバーの POST /tasks（New）または FederationService の gRPC（NewGRPC）を使い、
+// 認証トークンの付与と一時的な失敗（受付拒否・接続エラー）のリトライを行う。
+//
+//	client := workerpoolclient.New("http://pool:8080", workerpoolclient.Config{Token: token})
+//	result, err := client.Run(ctx, workerpoolclient.NewTask(workerpool.TaskTypeEmail, payload))
+//	if err == nil { err = result.Err() }
+package workerpoolclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultBackoff    = 200 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+)
+
+var (
+	// ErrRejected はプールがタスクを受け付けなかった場合のエラー（過負荷・停止中など。リトライ対象）
+	ErrRejected = errors.New("プールがタスクを受け付けませんでした")
+	// ErrUnavailable はプールに接続できなかった場合のエラー（リトライ対象）
+	ErrUnavailable = errors.New("プールに接続できません")
+	// ErrUnauthorized は認証トークンが不正な場合のエラー
+	ErrUnauthorized = errors.New("認証に失敗しました")
+	// ErrInvalidTask はタスクの内容が不正な場合のエラー
+	ErrInvalidTask = errors.New("タスクの内容が不正です")
+	// ErrWaitUnsupported は結果を待つ投入に対応していない接続で Run を呼んだ場合のエラー
+	ErrWaitUnsupported = errors.New("この接続では結果を待つ投入（Run）に対応していません")
+	// ErrTaskFailed はタスクが最終的に失敗した場合のエラー（Result.Err）
+	ErrTaskFailed = errors.New("タスクが失敗しました")
+)
+
+// Config はクライアントの設定
+type Config struct {
+	Token      string        // 管理APIのトークン（Authorization: Bearer で送る。不要な場合は空）
+	MaxRetries int           // 受付拒否・接続エラーのリトライ回数（0 の場合は3、負の値はリトライしない）
+	Backoff    time.Duration // 最初のリトライまでの待機時間（0 の場合は200ミリ秒、以降は倍々に延ばす）
+	MaxBackoff time.Duration // リトライの待機時間の上限（0 の場合は5秒）
+	HTTPClient *http.Client  // HTTP の接続に使うクライアント（nil の場合は既定のクライアント）
+}
+
+// Result はタスクの最終結果（リトライ後を含む）
+type Result struct {
+	TaskID       int
+	TaskName     string
+	TaskType     workerpool.TaskType
+	Success      bool
+	Canceled     bool
+	Error        string // 失敗した場合のエラーメッセージ
+	ErrorCode    string // 失敗した場合のエラーコード
+	Duration     time.Duration
+	AttemptCount int
+	WorkerID     int
+}
+
+// Err はタスクが失敗した場合にエラーを返す（成功した場合は nil）
+func (r *Result) Err() error {
+	if r.Success {
+		return nil
+	}
+	if r.ErrorCode != "" {
+		return fmt.Errorf("%w: タスク %d: %s (%s)", ErrTaskFailed, r.TaskID, r.Error, r.ErrorCode)
+	}
+	return fmt.Errorf("%w: タスク %d: %s", ErrTaskFailed, r.TaskID, r.Error)
+}
+
+// transport はプールへの接続方式
+type transport interface {
+	submit(ctx context.Context, task workerpool.Task) error
+	run(ctx context.Context, task workerpool.Task) (*Result, error)
+}
+
+// Client はリモートのプールにタスクを投入するクライアント
+// 複数の goroutine から同時に使える
+type Client struct {
+	config    Config
+	transport transport
+	nextID    atomic.Int64
+}
+
+func newClient(config Config, t transport) *Client {
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaultBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaultMaxBackoff
+	}
+	c := &Client{config: config, transport: t}
+	// 同じプールに複数のクライアントから投入しても ID が重なりにくいよう、開始値をランダムにする
+	c.nextID.Store(rand.Int63n(1 << 40))
+	return c
+}
+
+// Submit はタスクを投入し、プールが受け付けた時点でタスクIDを返す
+// ID を指定していないタスクにはクライアントが ID を割り当てる
+func (c *Client) Submit(ctx context.Context, builder *TaskBuilder) (int, error) {
+	task, err := c.build(builder)
+	if err != nil {
+		return 0, err
+	}
+	err = c.retry(ctx, func() error {
+		return c.transport.submit(ctx, task)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return task.ID, nil
+}
+
+// Run はタスクを投入し、最終結果が出るまで待つ
+// タスクが失敗しても err は nil で、結果は Result.Success（または Result.Err）で確認する。
+// ctx がキャンセルされると接続を切り、プール側でもタスクがキャンセルされる
+func (c *Client) Run(ctx context.Context, builder *TaskBuilder) (*Result, error) {
+	task, err := c.build(builder)
+	if err != nil {
+		return nil, err
+	}
+	var result *Result
+	err = c.retry(ctx, func() error {
+		var err error
+		result, err = c.transport.run(ctx, task)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// build はタスクを組み立て、未指定の ID を割り当てる
+func (c *Client) build(builder *TaskBuilder) (workerpool.Task, error) {
+	task, err := builder.Build()
+	if err != nil {
+		return workerpool.Task{}, err
+	}
+	if task.ID == 0 {
+		task.ID = int(c.nextID.Add(1))
+	}
+	return task, nil
+}
+
+// retry は受付拒否・接続エラーの場合に指数バックオフで呼び出しを繰り返す
+// リトライしても同じタスクIDで投入する
+func (c *Client) retry(ctx context.Context, call func() error) error {
+	backoff := c.config.Backoff
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil || !retryable(err) || attempt >= c.config.MaxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		// 複数のクライアントのリトライが同じ時刻に集中しないよう、待機時間を半分から全体の間でばらつかせる
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+		backoff = min(backoff*2, c.config.MaxBackoff)
+	}
+}
+
+// retryable はリトライすれば成功する見込みのあるエラーか判定
+func retryable(err error) bool {
+	return errors.Is(err, ErrRejected) || errors.Is(err, ErrUnavailable)
+}
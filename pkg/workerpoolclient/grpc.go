@@ -0,0 +1,60 @@
+package workerpoolclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+	"github.com/hizzuu/worker-example/pkg/workerpoolpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// NewGRPC はプールの FederationService（remote.FederationServer）への接続を使うクライアントを作成する
+// gRPC では受け付けた時点で戻る投入（Submit）のみに対応し、Run は ErrWaitUnsupported を返す。
+// トークンはメタデータの authorization に Bearer で付ける（サーバー側のインターセプターで検証する）
+func NewGRPC(conn grpc.ClientConnInterface, config Config) *Client {
+	return newClient(config, &grpcTransport{
+		token:  config.Token,
+		client: workerpoolpb.NewFederationServiceClient(conn),
+	})
+}
+
+// grpcTransport は FederationService.Forward でタスクを投入する
+type grpcTransport struct {
+	token  string
+	client workerpoolpb.FederationServiceClient
+}
+
+func (t *grpcTransport) submit(ctx context.Context, task workerpool.Task) error {
+	pb, err := workerpoolpb.FromTask(task)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+	if t.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+t.token)
+	}
+
+	_, err = t.client.Forward(ctx, &workerpoolpb.ForwardRequest{Task: pb})
+	message := status.Convert(err).Message()
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.ResourceExhausted:
+		return fmt.Errorf("%w: %s", ErrRejected, message)
+	case codes.Unavailable:
+		return fmt.Errorf("%w: %s", ErrUnavailable, message)
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return fmt.Errorf("%w: %s", ErrUnauthorized, message)
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", ErrInvalidTask, message)
+	default:
+		return err
+	}
+}
+
+func (t *grpcTransport) run(ctx context.Context, task workerpool.Task) (*Result, error) {
+	return nil, ErrWaitUnsupported
+}
@@ -0,0 +1,149 @@
+package workerpoolclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+)
+
+// New はプールのWebサーバー（例: http://pool:8080）の POST /tasks を使うクライアントを作成する
+func New(baseURL string, config Config) *Client {
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+	return newClient(config, &httpTransport{
+		url:    strings.TrimRight(baseURL, "/") + "/tasks",
+		token:  config.Token,
+		client: client,
+	})
+}
+
+// httpTransport は POST /tasks でタスクを投入する
+type httpTransport struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// taskRequest は POST /tasks のリクエストボディ
+type taskRequest struct {
+	ID         int                 `json:"id"`
+	Name       string              `json:"name"`
+	Type       workerpool.TaskType `json:"type"`
+	Payload    interface{}         `json:"payload,omitempty"`
+	Labels     map[string]string   `json:"labels,omitempty"`
+	Selector   map[string]string   `json:"selector,omitempty"`
+	Priority   workerpool.Priority `json:"priority"`
+	Sheddable  bool                `json:"sheddable"`
+	Deadline   time.Time           `json:"deadline"`
+	MaxRetries int                 `json:"max_retries"`
+	Timeout    time.Duration       `json:"timeout_ns"`
+}
+
+// taskResponse は POST /tasks のレスポンス（?wait=true の場合は最終結果）
+type taskResponse struct {
+	TaskID       int                 `json:"task_id"`
+	TaskName     string              `json:"task_name"`
+	TaskType     workerpool.TaskType `json:"task_type"`
+	Success      bool                `json:"success"`
+	Canceled     bool                `json:"canceled"`
+	Error        string              `json:"error"`
+	ErrorCode    string              `json:"error_code"`
+	DurationMs   float64             `json:"duration_ms"`
+	AttemptCount int                 `json:"attempt_count"`
+	WorkerID     int                 `json:"worker_id"`
+}
+
+func (t *httpTransport) submit(ctx context.Context, task workerpool.Task) error {
+	_, err := t.post(ctx, task, "", http.StatusAccepted)
+	return err
+}
+
+func (t *httpTransport) run(ctx context.Context, task workerpool.Task) (*Result, error) {
+	resp, err := t.post(ctx, task, "?wait=true", http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		TaskID:       resp.TaskID,
+		TaskName:     resp.TaskName,
+		TaskType:     resp.TaskType,
+		Success:      resp.Success,
+		Canceled:     resp.Canceled,
+		Error:        resp.Error,
+		ErrorCode:    resp.ErrorCode,
+		Duration:     time.Duration(resp.DurationMs * float64(time.Millisecond)),
+		AttemptCount: resp.AttemptCount,
+		WorkerID:     resp.WorkerID,
+	}, nil
+}
+
+// post はタスクを送り、期待したステータスでなければステータスに応じたエラーを返す
+func (t *httpTransport) post(ctx context.Context, task workerpool.Task, query string, expected int) (*taskResponse, error) {
+	body, err := json.Marshal(taskRequest{
+		ID:         task.ID,
+		Name:       task.Name,
+		Type:       task.Type,
+		Payload:    task.Payload,
+		Labels:     task.Labels,
+		Selector:   task.Selector,
+		Priority:   task.Priority,
+		Sheddable:  task.Sheddable,
+		Deadline:   task.Deadline,
+		MaxRetries: task.MaxRetries,
+		Timeout:    task.Timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: ペイロードをJSONに変換できません: %v", ErrInvalidTask, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url+query, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var result taskResponse
+	decodeErr := json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode == expected {
+		if decodeErr != nil {
+			return nil, fmt.Errorf("レスポンスを読み込めません: %w", decodeErr)
+		}
+		return &result, nil
+	}
+	return nil, statusError(resp.StatusCode, result.Error)
+}
+
+// statusError はエラーレスポンスのステータスをエラーに変換する
+func statusError(status int, message string) error {
+	switch status {
+	case http.StatusServiceUnavailable, http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %s", ErrRejected, message)
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return fmt.Errorf("%w: HTTP %d", ErrUnavailable, status)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrUnauthorized, message)
+	case http.StatusBadRequest, http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrInvalidTask, message)
+	default:
+		return fmt.Errorf("HTTP %d: %s", status, message)
+	}
+}
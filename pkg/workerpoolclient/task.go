@@ -0,0 +1,120 @@
+package workerpoolclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+)
+
+// TaskBuilder は投入するタスクを組み立てるビルダー
+//
+//	task := workerpoolclient.NewTask(workerpool.TaskTypeReport, payload).
+//		WithName("月次レポート").
+//		WithPriority(workerpool.PriorityHigh).
+//		WithTimeout(time.Minute)
+type TaskBuilder struct {
+	task workerpool.Task
+}
+
+// NewTask はタイプとペイロードを指定してビルダーを作成する
+// ペイロードはJSONとして表現できる必要がある（nil の場合はペイロードなし）
+func NewTask(taskType workerpool.TaskType, payload interface{}) *TaskBuilder {
+	return &TaskBuilder{task: workerpool.Task{Type: taskType, Payload: payload}}
+}
+
+// WithID はタスクIDを指定する（省略した場合はクライアントが割り当てる）
+func (b *TaskBuilder) WithID(id int) *TaskBuilder {
+	b.task.ID = id
+	return b
+}
+
+// WithName はタスク名を指定する（省略した場合はタイプ名）
+func (b *TaskBuilder) WithName(name string) *TaskBuilder {
+	b.task.Name = name
+	return b
+}
+
+// WithPriority は優先度を指定する
+func (b *TaskBuilder) WithPriority(priority workerpool.Priority) *TaskBuilder {
+	b.task.Priority = priority
+	return b
+}
+
+// WithLabel はラベルを追加する
+func (b *TaskBuilder) WithLabel(key, value string) *TaskBuilder {
+	if b.task.Labels == nil {
+		b.task.Labels = make(map[string]string)
+	}
+	b.task.Labels[key] = value
+	return b
+}
+
+// WithSelector は実行できるワーカーのラベル条件を追加する
+func (b *TaskBuilder) WithSelector(key, value string) *TaskBuilder {
+	if b.task.Selector == nil {
+		b.task.Selector = make(map[string]string)
+	}
+	b.task.Selector[key] = value
+	return b
+}
+
+// WithTimeout は実行のタイムアウトを指定する
+func (b *TaskBuilder) WithTimeout(timeout time.Duration) *TaskBuilder {
+	b.task.Timeout = timeout
+	return b
+}
+
+// WithDeadline は期限を指定する（過ぎたタスクはプールで実行されない）
+func (b *TaskBuilder) WithDeadline(deadline time.Time) *TaskBuilder {
+	b.task.Deadline = deadline
+	return b
+}
+
+// WithMaxRetries はプールでの最大リトライ回数を指定する（負の値はリトライしない）
+func (b *TaskBuilder) WithMaxRetries(maxRetries int) *TaskBuilder {
+	b.task.MaxRetries = maxRetries
+	return b
+}
+
+// Sheddable は過負荷時に破棄してよいタスクとして投入する
+func (b *TaskBuilder) Sheddable() *TaskBuilder {
+	b.task.Sheddable = true
+	return b
+}
+
+// Build はタスクを検証して返す（不正な場合は ErrInvalidTask）
+func (b *TaskBuilder) Build() (workerpool.Task, error) {
+	if b == nil {
+		return workerpool.Task{}, fmt.Errorf("%w: タスクを指定してください", ErrInvalidTask)
+	}
+	task := b.task
+	if task.Type == "" {
+		return workerpool.Task{}, fmt.Errorf("%w: type を指定してください", ErrInvalidTask)
+	}
+	if task.ID < 0 {
+		return workerpool.Task{}, fmt.Errorf("%w: id は正の整数で指定してください", ErrInvalidTask)
+	}
+	if task.Timeout < 0 {
+		return workerpool.Task{}, fmt.Errorf("%w: タイムアウトに負の値は指定できません", ErrInvalidTask)
+	}
+	if task.Name == "" {
+		task.Name = string(task.Type)
+	}
+	task.Labels = cloneMap(task.Labels)
+	task.Selector = cloneMap(task.Selector)
+	task.CreatedAt = time.Now()
+	return task, nil
+}
+
+// cloneMap は同じビルダーから作ったタスク同士でマップを共有しないようにコピーする
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(m))
+	for key, value := range m {
+		clone[key] = value
+	}
+	return clone
+}
@@ -0,0 +1,593 @@
+// リモートワーカー（エージェント）とコーディネーター間のプロトコル
+// エージェントは登録後にタスクを取得して実行し、ハートビートと結果をストリームで送る
+// リトライ判定と監視はコーディネーター（プール）側で行う
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: workerpool/v1/agent.proto
+
+package workerpoolpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RegisterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // version, region, gpu など
+	Capacity      int32                  `protobuf:"varint,3,opt,name=capacity,proto3" json:"capacity,omitempty"`                                                                      // 同時に実行できるタスク数
+	TaskTypes     []string               `protobuf:"bytes,4,rep,name=task_types,json=taskTypes,proto3" json:"task_types,omitempty"`                                                    // 処理できるタスクタイプ
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_workerpool_v1_agent_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_agent_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_agent_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RegisterRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *RegisterRequest) GetCapacity() int32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *RegisterRequest) GetTaskTypes() []string {
+	if x != nil {
+		return x.TaskTypes
+	}
+	return nil
+}
+
+type RegisterResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	HeartbeatInterval *durationpb.Duration   `protobuf:"bytes,1,opt,name=heartbeat_interval,json=heartbeatInterval,proto3" json:"heartbeat_interval,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_workerpool_v1_agent_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_agent_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_agent_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterResponse) GetHeartbeatInterval() *durationpb.Duration {
+	if x != nil {
+		return x.HeartbeatInterval
+	}
+	return nil
+}
+
+type PullTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Wait          *durationpb.Duration   `protobuf:"bytes,2,opt,name=wait,proto3" json:"wait,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullTaskRequest) Reset() {
+	*x = PullTaskRequest{}
+	mi := &file_workerpool_v1_agent_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullTaskRequest) ProtoMessage() {}
+
+func (x *PullTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_agent_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullTaskRequest.ProtoReflect.Descriptor instead.
+func (*PullTaskRequest) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_agent_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PullTaskRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *PullTaskRequest) GetWait() *durationpb.Duration {
+	if x != nil {
+		return x.Wait
+	}
+	return nil
+}
+
+type PullTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HasTask       bool                   `protobuf:"varint,1,opt,name=has_task,json=hasTask,proto3" json:"has_task,omitempty"`
+	AttemptId     uint64                 `protobuf:"varint,2,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"` // 今回の実行の識別子（結果の報告に使う）
+	Task          *Task                  `protobuf:"bytes,3,opt,name=task,proto3" json:"task,omitempty"`
+	Timeout       *durationpb.Duration   `protobuf:"bytes,4,opt,name=timeout,proto3" json:"timeout,omitempty"` // 実行の制限時間（0 で無制限）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullTaskResponse) Reset() {
+	*x = PullTaskResponse{}
+	mi := &file_workerpool_v1_agent_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullTaskResponse) ProtoMessage() {}
+
+func (x *PullTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_agent_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullTaskResponse.ProtoReflect.Descriptor instead.
+func (*PullTaskResponse) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_agent_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PullTaskResponse) GetHasTask() bool {
+	if x != nil {
+		return x.HasTask
+	}
+	return false
+}
+
+func (x *PullTaskResponse) GetAttemptId() uint64 {
+	if x != nil {
+		return x.AttemptId
+	}
+	return 0
+}
+
+func (x *PullTaskResponse) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *PullTaskResponse) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type HeartbeatRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AgentId           string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	RunningAttemptIds []uint64               `protobuf:"varint,2,rep,packed,name=running_attempt_ids,json=runningAttemptIds,proto3" json:"running_attempt_ids,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_workerpool_v1_agent_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_agent_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_agent_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HeartbeatRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetRunningAttemptIds() []uint64 {
+	if x != nil {
+		return x.RunningAttemptIds
+	}
+	return nil
+}
+
+type HeartbeatResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	CancelAttemptIds []uint64               `protobuf:"varint,1,rep,packed,name=cancel_attempt_ids,json=cancelAttemptIds,proto3" json:"cancel_attempt_ids,omitempty"` // 中断すべき実行
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_workerpool_v1_agent_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_agent_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_agent_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HeartbeatResponse) GetCancelAttemptIds() []uint64 {
+	if x != nil {
+		return x.CancelAttemptIds
+	}
+	return nil
+}
+
+type ReportResultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	AttemptId     uint64                 `protobuf:"varint,2,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"`
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Output        string                 `protobuf:"bytes,5,opt,name=output,proto3" json:"output,omitempty"`
+	ErrorKinds    []string               `protobuf:"bytes,6,rep,name=error_kinds,json=errorKinds,proto3" json:"error_kinds,omitempty"` // エラーが該当する既知のエラー・リトライの分類（workerpool.ErrorKinds。コーディネーターで型を復元する）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportResultRequest) Reset() {
+	*x = ReportResultRequest{}
+	mi := &file_workerpool_v1_agent_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportResultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportResultRequest) ProtoMessage() {}
+
+func (x *ReportResultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_agent_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportResultRequest.ProtoReflect.Descriptor instead.
+func (*ReportResultRequest) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_agent_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ReportResultRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ReportResultRequest) GetAttemptId() uint64 {
+	if x != nil {
+		return x.AttemptId
+	}
+	return 0
+}
+
+func (x *ReportResultRequest) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ReportResultRequest) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ReportResultRequest) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *ReportResultRequest) GetErrorKinds() []string {
+	if x != nil {
+		return x.ErrorKinds
+	}
+	return nil
+}
+
+type ReportResultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportResultsResponse) Reset() {
+	*x = ReportResultsResponse{}
+	mi := &file_workerpool_v1_agent_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportResultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportResultsResponse) ProtoMessage() {}
+
+func (x *ReportResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_agent_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportResultsResponse.ProtoReflect.Descriptor instead.
+func (*ReportResultsResponse) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_agent_proto_rawDescGZIP(), []int{7}
+}
+
+var File_workerpool_v1_agent_proto protoreflect.FileDescriptor
+
+const file_workerpool_v1_agent_proto_rawDesc = "" +
+	"\n" +
+	"\x19workerpool/v1/agent.proto\x12\rworkerpool.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1eworkerpool/v1/workerpool.proto\"\xe6\x01\n" +
+	"\x0fRegisterRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12B\n" +
+	"\x06labels\x18\x02 \x03(\v2*.workerpool.v1.RegisterRequest.LabelsEntryR\x06labels\x12\x1a\n" +
+	"\bcapacity\x18\x03 \x01(\x05R\bcapacity\x12\x1d\n" +
+	"\n" +
+	"task_types\x18\x04 \x03(\tR\ttaskTypes\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\\\n" +
+	"\x10RegisterResponse\x12H\n" +
+	"\x12heartbeat_interval\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x11heartbeatInterval\"[\n" +
+	"\x0fPullTaskRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12-\n" +
+	"\x04wait\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x04wait\"\xaa\x01\n" +
+	"\x10PullTaskResponse\x12\x19\n" +
+	"\bhas_task\x18\x01 \x01(\bR\ahasTask\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x02 \x01(\x04R\tattemptId\x12'\n" +
+	"\x04task\x18\x03 \x01(\v2\x13.workerpool.v1.TaskR\x04task\x123\n" +
+	"\atimeout\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"]\n" +
+	"\x10HeartbeatRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12.\n" +
+	"\x13running_attempt_ids\x18\x02 \x03(\x04R\x11runningAttemptIds\"A\n" +
+	"\x11HeartbeatResponse\x12,\n" +
+	"\x12cancel_attempt_ids\x18\x01 \x03(\x04R\x10cancelAttemptIds\"\xb8\x01\n" +
+	"\x13ReportResultRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x02 \x01(\x04R\tattemptId\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x16\n" +
+	"\x06output\x18\x05 \x01(\tR\x06output\x12\x1f\n" +
+	"\verror_kinds\x18\x06 \x03(\tR\n" +
+	"errorKinds\"\x17\n" +
+	"\x15ReportResultsResponse2\xd9\x02\n" +
+	"\fAgentService\x12K\n" +
+	"\bRegister\x12\x1e.workerpool.v1.RegisterRequest\x1a\x1f.workerpool.v1.RegisterResponse\x12K\n" +
+	"\bPullTask\x12\x1e.workerpool.v1.PullTaskRequest\x1a\x1f.workerpool.v1.PullTaskResponse\x12R\n" +
+	"\tHeartbeat\x12\x1f.workerpool.v1.HeartbeatRequest\x1a .workerpool.v1.HeartbeatResponse(\x010\x01\x12[\n" +
+	"\rReportResults\x12\".workerpool.v1.ReportResultRequest\x1a$.workerpool.v1.ReportResultsResponse(\x01B@Z>github.com/hizzuu/worker-example/pkg/workerpoolpb;workerpoolpbb\x06proto3"
+
+var (
+	file_workerpool_v1_agent_proto_rawDescOnce sync.Once
+	file_workerpool_v1_agent_proto_rawDescData []byte
+)
+
+func file_workerpool_v1_agent_proto_rawDescGZIP() []byte {
+	file_workerpool_v1_agent_proto_rawDescOnce.Do(func() {
+		file_workerpool_v1_agent_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_workerpool_v1_agent_proto_rawDesc), len(file_workerpool_v1_agent_proto_rawDesc)))
+	})
+	return file_workerpool_v1_agent_proto_rawDescData
+}
+
+var file_workerpool_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_workerpool_v1_agent_proto_goTypes = []any{
+	(*RegisterRequest)(nil),       // 0: workerpool.v1.RegisterRequest
+	(*RegisterResponse)(nil),      // 1: workerpool.v1.RegisterResponse
+	(*PullTaskRequest)(nil),       // 2: workerpool.v1.PullTaskRequest
+	(*PullTaskResponse)(nil),      // 3: workerpool.v1.PullTaskResponse
+	(*HeartbeatRequest)(nil),      // 4: workerpool.v1.HeartbeatRequest
+	(*HeartbeatResponse)(nil),     // 5: workerpool.v1.HeartbeatResponse
+	(*ReportResultRequest)(nil),   // 6: workerpool.v1.ReportResultRequest
+	(*ReportResultsResponse)(nil), // 7: workerpool.v1.ReportResultsResponse
+	nil,                           // 8: workerpool.v1.RegisterRequest.LabelsEntry
+	(*durationpb.Duration)(nil),   // 9: google.protobuf.Duration
+	(*Task)(nil),                  // 10: workerpool.v1.Task
+}
+var file_workerpool_v1_agent_proto_depIdxs = []int32{
+	8,  // 0: workerpool.v1.RegisterRequest.labels:type_name -> workerpool.v1.RegisterRequest.LabelsEntry
+	9,  // 1: workerpool.v1.RegisterResponse.heartbeat_interval:type_name -> google.protobuf.Duration
+	9,  // 2: workerpool.v1.PullTaskRequest.wait:type_name -> google.protobuf.Duration
+	10, // 3: workerpool.v1.PullTaskResponse.task:type_name -> workerpool.v1.Task
+	9,  // 4: workerpool.v1.PullTaskResponse.timeout:type_name -> google.protobuf.Duration
+	0,  // 5: workerpool.v1.AgentService.Register:input_type -> workerpool.v1.RegisterRequest
+	2,  // 6: workerpool.v1.AgentService.PullTask:input_type -> workerpool.v1.PullTaskRequest
+	4,  // 7: workerpool.v1.AgentService.Heartbeat:input_type -> workerpool.v1.HeartbeatRequest
+	6,  // 8: workerpool.v1.AgentService.ReportResults:input_type -> workerpool.v1.ReportResultRequest
+	1,  // 9: workerpool.v1.AgentService.Register:output_type -> workerpool.v1.RegisterResponse
+	3,  // 10: workerpool.v1.AgentService.PullTask:output_type -> workerpool.v1.PullTaskResponse
+	5,  // 11: workerpool.v1.AgentService.Heartbeat:output_type -> workerpool.v1.HeartbeatResponse
+	7,  // 12: workerpool.v1.AgentService.ReportResults:output_type -> workerpool.v1.ReportResultsResponse
+	9,  // [9:13] is the sub-list for method output_type
+	5,  // [5:9] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_workerpool_v1_agent_proto_init() }
+func file_workerpool_v1_agent_proto_init() {
+	if File_workerpool_v1_agent_proto != nil {
+		return
+	}
+	file_workerpool_v1_workerpool_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_workerpool_v1_agent_proto_rawDesc), len(file_workerpool_v1_agent_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_workerpool_v1_agent_proto_goTypes,
+		DependencyIndexes: file_workerpool_v1_agent_proto_depIdxs,
+		MessageInfos:      file_workerpool_v1_agent_proto_msgTypes,
+	}.Build()
+	File_workerpool_v1_agent_proto = out.File
+	file_workerpool_v1_agent_proto_goTypes = nil
+	file_workerpool_v1_agent_proto_depIdxs = nil
+}
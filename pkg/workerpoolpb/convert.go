@@ -0,0 +1,294 @@
+package workerpoolpb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hizzuu/worker-example/pkg/workerpool"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromTask はタスクを proto 表現に変換する
+// ペイロードはJSONとして表現できる必要がある
+func FromTask(task workerpool.Task) (*Task, error) {
+	payload, err := toValue(task.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("タスク %d のペイロードを変換できません: %w", task.ID, err)
+	}
+
+	pb := &Task{
+		Id:           int64(task.ID),
+		Name:         task.Name,
+		Type:         string(task.Type),
+		Payload:      payload,
+		Labels:       task.Labels,
+		Selector:     task.Selector,
+		Priority:     int32(task.Priority),
+		Sheddable:    task.Sheddable,
+		Deadline:     toTimestamp(task.Deadline),
+		AttemptCount: int32(task.AttemptCount),
+		MaxRetries:   int32(task.MaxRetries),
+		CreatedAt:    toTimestamp(task.CreatedAt),
+		FirstAttempt: toTimestamp(task.FirstAttempt),
+		Hops:         task.Hops,
+	}
+	if task.Timeout > 0 {
+		pb.Timeout = durationpb.New(task.Timeout)
+	}
+	if task.LastError != nil {
+		pb.LastError = task.LastError.Error()
+	}
+	return pb, nil
+}
+
+// ToTask は proto 表現からタスクを復元する
+// ペイロードは JSON をデコードした場合と同じ型（map[string]interface{} など）になる
+func ToTask(pb *Task) workerpool.Task {
+	task := workerpool.Task{
+		ID:           int(pb.GetId()),
+		Name:         pb.GetName(),
+		Type:         workerpool.TaskType(pb.GetType()),
+		Labels:       pb.GetLabels(),
+		Selector:     pb.GetSelector(),
+		Priority:     workerpool.Priority(pb.GetPriority()),
+		Sheddable:    pb.GetSheddable(),
+		Deadline:     fromTimestamp(pb.GetDeadline()),
+		AttemptCount: int(pb.GetAttemptCount()),
+		MaxRetries:   int(pb.GetMaxRetries()),
+		Timeout:      pb.GetTimeout().AsDuration(),
+		CreatedAt:    fromTimestamp(pb.GetCreatedAt()),
+		FirstAttempt: fromTimestamp(pb.GetFirstAttempt()),
+		Hops:         pb.GetHops(),
+	}
+	if pb.GetPayload() != nil {
+		task.Payload = pb.GetPayload().AsInterface()
+	}
+	if pb.GetLastError() != "" {
+		task.LastError = fmt.Errorf("%s", pb.GetLastError())
+	}
+	return task
+}
+
+// FromTaskResult はタスク結果を proto 表現に変換する
+func FromTaskResult(result workerpool.TaskResult) *TaskResult {
+	pb := &TaskResult{
+		TaskId:        int64(result.TaskID),
+		TaskName:      result.TaskName,
+		TaskType:      string(result.TaskType),
+		Labels:        result.Labels,
+		Output:        result.Output,
+		Variant:       result.Variant,
+		Success:       result.Success,
+		Duration:      durationpb.New(result.Duration),
+		TotalDuration: durationpb.New(result.TotalDuration),
+		WorkerId:      int64(result.WorkerID),
+		StartTime:     toTimestamp(result.StartTime),
+		EndTime:       toTimestamp(result.EndTime),
+		AttemptCount:  int32(result.AttemptCount),
+		IsFinal:       result.IsFinal,
+		CreatedAt:     toTimestamp(result.CreatedAt),
+		Age:           durationpb.New(result.Age),
+		Budget:        fromLatencyBudget(result.Budget),
+	}
+	if result.Error != nil {
+		pb.Error = &TaskError{
+			Message:   result.Error.Error(),
+			Code:      result.ErrorCode(),
+			Retryable: result.IsRetryable(),
+		}
+	}
+	return pb
+}
+
+// ToTaskResult は proto 表現からタスク結果を復元する（エラーは *workerpool.ResultError になる）
+func ToTaskResult(pb *TaskResult) workerpool.TaskResult {
+	result := workerpool.TaskResult{
+		TaskID:        int(pb.GetTaskId()),
+		TaskName:      pb.GetTaskName(),
+		TaskType:      workerpool.TaskType(pb.GetTaskType()),
+		Labels:        pb.GetLabels(),
+		Output:        pb.GetOutput(),
+		Variant:       pb.GetVariant(),
+		Success:       pb.GetSuccess(),
+		Duration:      pb.GetDuration().AsDuration(),
+		TotalDuration: pb.GetTotalDuration().AsDuration(),
+		WorkerID:      int(pb.GetWorkerId()),
+		StartTime:     fromTimestamp(pb.GetStartTime()),
+		EndTime:       fromTimestamp(pb.GetEndTime()),
+		AttemptCount:  int(pb.GetAttemptCount()),
+		IsFinal:       pb.GetIsFinal(),
+		CreatedAt:     fromTimestamp(pb.GetCreatedAt()),
+		Age:           pb.GetAge().AsDuration(),
+		Budget:        toLatencyBudget(pb.GetBudget()),
+	}
+	if e := pb.GetError(); e != nil {
+		result.Error = &workerpool.ResultError{
+			Message:   e.GetMessage(),
+			Code:      e.GetCode(),
+			Retryable: e.GetRetryable(),
+		}
+	}
+	return result
+}
+
+// fromLatencyBudget は時間の内訳を proto 表現に変換する
+func fromLatencyBudget(budget *workerpool.LatencyBudget) *LatencyBudget {
+	if budget == nil {
+		return nil
+	}
+	pb := &LatencyBudget{
+		Deadline:       toTimestamp(budget.Deadline),
+		AttemptTimeout: durationpb.New(budget.AttemptTimeout),
+		Consumed:       durationpb.New(budget.Consumed),
+		Queue:          durationpb.New(budget.Queue),
+		Processing:     durationpb.New(budget.Processing),
+		RetryWait:      durationpb.New(budget.RetryWait),
+		Exceeded:       budget.Exceeded,
+		BlownAt:        budget.BlownAt,
+	}
+	if !budget.Deadline.IsZero() {
+		pb.Budget = durationpb.New(budget.Budget)
+		pb.Remaining = durationpb.New(budget.Remaining)
+	}
+	for _, phase := range budget.Phases {
+		pb.Phases = append(pb.Phases, &BudgetPhase{
+			Phase:    phase.Phase,
+			Attempt:  int32(phase.Attempt),
+			Duration: durationpb.New(phase.Duration),
+		})
+	}
+	return pb
+}
+
+// toLatencyBudget は proto 表現から時間の内訳を復元する
+func toLatencyBudget(pb *LatencyBudget) *workerpool.LatencyBudget {
+	if pb == nil {
+		return nil
+	}
+	budget := &workerpool.LatencyBudget{
+		Deadline:       fromTimestamp(pb.GetDeadline()),
+		Budget:         pb.GetBudget().AsDuration(),
+		AttemptTimeout: pb.GetAttemptTimeout().AsDuration(),
+		Consumed:       pb.GetConsumed().AsDuration(),
+		Queue:          pb.GetQueue().AsDuration(),
+		Processing:     pb.GetProcessing().AsDuration(),
+		RetryWait:      pb.GetRetryWait().AsDuration(),
+		Exceeded:       pb.GetExceeded(),
+		BlownAt:        pb.GetBlownAt(),
+		Remaining:      pb.GetRemaining().AsDuration(),
+	}
+	for _, phase := range pb.GetPhases() {
+		budget.Phases = append(budget.Phases, workerpool.BudgetPhase{
+			Phase:    phase.GetPhase(),
+			Attempt:  int(phase.GetAttempt()),
+			Duration: phase.GetDuration().AsDuration(),
+		})
+	}
+	return budget
+}
+
+// FromPoolStats は統計を proto 表現に変換する
+func FromPoolStats(stats workerpool.PoolStats) *PoolStats {
+	pb := &PoolStats{
+		TotalTasks:       stats.TotalTasks,
+		CompletedTasks:   stats.CompletedTasks,
+		FailedTasks:      stats.FailedTasks,
+		ActiveTasks:      stats.ActiveTasks,
+		QueuedTasks:      stats.QueuedTasks,
+		RetryingTasks:    stats.RetryingTasks,
+		DeferredTasks:    stats.DeferredTasks,
+		HeldTasks:        stats.HeldTasks,
+		State:            stats.State.String(),
+		DeadLetters:      stats.DeadLetters,
+		TotalWorkers:     int32(stats.TotalWorkers),
+		ActiveWorkers:    int32(stats.ActiveWorkers),
+		IdleWorkers:      int32(stats.IdleWorkers),
+		AverageTimeMs:    stats.AverageTime,
+		MinTimeMs:        stats.MinTime,
+		MaxTimeMs:        stats.MaxTime,
+		TaskTypeStats:    make(map[string]*TaskTypeStats, len(stats.TaskTypeStats)),
+		LabelStats:       make(map[string]*LabelStats, len(stats.LabelStats)),
+		GroupBy:          stats.GroupBy,
+		GroupStats:       fromStatsMap(stats.GroupStats),
+		ThroughputPerSec: stats.Throughput,
+		DrainEtaMs:       stats.DrainETA,
+		DrainEtaByTypeMs: make(map[string]float64, len(stats.DrainETAByType)),
+		Admission: &AdmissionStats{
+			Rejected: stats.Admission.Rejected,
+			Shed:     stats.Admission.Shed,
+			Degraded: stats.Admission.Degraded,
+			Deferred: stats.Admission.Deferred,
+		},
+		Uptime:       durationpb.New(stats.Uptime),
+		LastUpdated:  toTimestamp(stats.LastUpdated),
+		AverageAgeMs: stats.AverageAge,
+		MaxAgeMs:     stats.MaxAge,
+	}
+	for taskType, s := range stats.TaskTypeStats {
+		pb.TaskTypeStats[string(taskType)] = fromTypeStats(s)
+	}
+	for key, values := range stats.LabelStats {
+		pb.LabelStats[key] = &LabelStats{Values: fromStatsMap(values)}
+	}
+	for taskType, eta := range stats.DrainETAByType {
+		pb.DrainEtaByTypeMs[string(taskType)] = eta
+	}
+	return pb
+}
+
+func fromTypeStats(s workerpool.TaskTypeStats) *TaskTypeStats {
+	return &TaskTypeStats{
+		Total:     s.Total,
+		Succeeded: s.Succeeded,
+		Failed:    s.Failed,
+		Retried:   s.Retried,
+		AvgTimeMs: s.AvgTime,
+		AvgAgeMs:  s.AvgAge,
+	}
+}
+
+func fromStatsMap(stats map[string]workerpool.TaskTypeStats) map[string]*TaskTypeStats {
+	if stats == nil {
+		return nil
+	}
+	pb := make(map[string]*TaskTypeStats, len(stats))
+	for key, s := range stats {
+		pb[key] = fromTypeStats(s)
+	}
+	return pb
+}
+
+// toValue は任意のペイロードをJSON経由で google.protobuf.Value に変換する
+func toValue(payload interface{}) (*structpb.Value, error) {
+	if payload == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(decoded)
+}
+
+// toTimestamp はゼロ値を未設定として扱う
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,181 @@
+// プール間でタスクを転送するプロトコル（フェデレーション）
+// 自分で処理できないタスク（プロセッサが未登録のタイプ・受付の上限に達したタスク）をピアのプールに渡す
+// 結果は受け付けたプールの側で通知される
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: workerpool/v1/federation.proto
+
+package workerpoolpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ForwardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"` // task.hops に転送元までの経路を含む
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForwardRequest) Reset() {
+	*x = ForwardRequest{}
+	mi := &file_workerpool_v1_federation_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardRequest) ProtoMessage() {}
+
+func (x *ForwardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_federation_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardRequest.ProtoReflect.Descriptor instead.
+func (*ForwardRequest) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_federation_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ForwardRequest) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+type ForwardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AcceptedBy    string                 `protobuf:"bytes,1,opt,name=accepted_by,json=acceptedBy,proto3" json:"accepted_by,omitempty"` // 受け付けたプールの識別子
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForwardResponse) Reset() {
+	*x = ForwardResponse{}
+	mi := &file_workerpool_v1_federation_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardResponse) ProtoMessage() {}
+
+func (x *ForwardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_federation_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardResponse.ProtoReflect.Descriptor instead.
+func (*ForwardResponse) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_federation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ForwardResponse) GetAcceptedBy() string {
+	if x != nil {
+		return x.AcceptedBy
+	}
+	return ""
+}
+
+var File_workerpool_v1_federation_proto protoreflect.FileDescriptor
+
+const file_workerpool_v1_federation_proto_rawDesc = "" +
+	"\n" +
+	"\x1eworkerpool/v1/federation.proto\x12\rworkerpool.v1\x1a\x1eworkerpool/v1/workerpool.proto\"9\n" +
+	"\x0eForwardRequest\x12'\n" +
+	"\x04task\x18\x01 \x01(\v2\x13.workerpool.v1.TaskR\x04task\"2\n" +
+	"\x0fForwardResponse\x12\x1f\n" +
+	"\vaccepted_by\x18\x01 \x01(\tR\n" +
+	"acceptedBy2]\n" +
+	"\x11FederationService\x12H\n" +
+	"\aForward\x12\x1d.workerpool.v1.ForwardRequest\x1a\x1e.workerpool.v1.ForwardResponseB@Z>github.com/hizzuu/worker-example/pkg/workerpoolpb;workerpoolpbb\x06proto3"
+
+var (
+	file_workerpool_v1_federation_proto_rawDescOnce sync.Once
+	file_workerpool_v1_federation_proto_rawDescData []byte
+)
+
+func file_workerpool_v1_federation_proto_rawDescGZIP() []byte {
+	file_workerpool_v1_federation_proto_rawDescOnce.Do(func() {
+		file_workerpool_v1_federation_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_workerpool_v1_federation_proto_rawDesc), len(file_workerpool_v1_federation_proto_rawDesc)))
+	})
+	return file_workerpool_v1_federation_proto_rawDescData
+}
+
+var file_workerpool_v1_federation_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_workerpool_v1_federation_proto_goTypes = []any{
+	(*ForwardRequest)(nil),  // 0: workerpool.v1.ForwardRequest
+	(*ForwardResponse)(nil), // 1: workerpool.v1.ForwardResponse
+	(*Task)(nil),            // 2: workerpool.v1.Task
+}
+var file_workerpool_v1_federation_proto_depIdxs = []int32{
+	2, // 0: workerpool.v1.ForwardRequest.task:type_name -> workerpool.v1.Task
+	0, // 1: workerpool.v1.FederationService.Forward:input_type -> workerpool.v1.ForwardRequest
+	1, // 2: workerpool.v1.FederationService.Forward:output_type -> workerpool.v1.ForwardResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_workerpool_v1_federation_proto_init() }
+func file_workerpool_v1_federation_proto_init() {
+	if File_workerpool_v1_federation_proto != nil {
+		return
+	}
+	file_workerpool_v1_workerpool_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_workerpool_v1_federation_proto_rawDesc), len(file_workerpool_v1_federation_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_workerpool_v1_federation_proto_goTypes,
+		DependencyIndexes: file_workerpool_v1_federation_proto_depIdxs,
+		MessageInfos:      file_workerpool_v1_federation_proto_msgTypes,
+	}.Build()
+	File_workerpool_v1_federation_proto = out.File
+	file_workerpool_v1_federation_proto_goTypes = nil
+	file_workerpool_v1_federation_proto_depIdxs = nil
+}
@@ -0,0 +1,5 @@
+// Package workerpoolpb はワーカープールのタスク・結果・統計の Protocol Buffers 表現と、
+// workerpool パッケージの型との相互変換を提供する
+package workerpoolpb
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=module=github.com/hizzuu/worker-example/pkg/workerpoolpb --go-grpc_out=. --go-grpc_opt=module=github.com/hizzuu/worker-example/pkg/workerpoolpb workerpool/v1/workerpool.proto workerpool/v1/agent.proto workerpool/v1/federation.proto
@@ -0,0 +1,1343 @@
+// ワーカープールのタスク・結果・統計の定義
+// 他言語のクライアントやブローカー連携で共通の型として利用する
+//
+// Go のコードは pkg/workerpoolpb に生成済み（go generate ./pkg/workerpoolpb で再生成）
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: workerpool/v1/workerpool.proto
+
+package workerpoolpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Task はワーカープールに投入するタスク
+type Task struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`                                                                               // email, image, database, report など
+	Payload       *structpb.Value        `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`                                                                         // 任意のJSON値
+	Labels        map[string]string      `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 任意のラベル（リージョン、顧客ティアなど）
+	Priority      int32                  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`                                                                      // 優先度（-1: 低, 0: 通常, 1: 高）
+	Sheddable     bool                   `protobuf:"varint,7,opt,name=sheddable,proto3" json:"sheddable,omitempty"`                                                                    // 過負荷時に破棄してよいタスク
+	Deadline      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=deadline,proto3" json:"deadline,omitempty"`                                                                       // 呼び出し元の期限（未設定で無制限）
+	AttemptCount  int32                  `protobuf:"varint,9,opt,name=attempt_count,json=attemptCount,proto3" json:"attempt_count,omitempty"`                                          // リトライ回数
+	MaxRetries    int32                  `protobuf:"varint,10,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`                                               // 最大リトライ回数
+	LastError     string                 `protobuf:"bytes,11,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`                                                   // 最後のエラー
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	FirstAttempt  *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=first_attempt,json=firstAttempt,proto3" json:"first_attempt,omitempty"`
+	Selector      map[string]string      `protobuf:"bytes,14,rep,name=selector,proto3" json:"selector,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 実行できるワーカーのラベル条件
+	Timeout       *durationpb.Duration   `protobuf:"bytes,15,opt,name=timeout,proto3" json:"timeout,omitempty"`                                                                             // 実行のタイムアウト（未設定でプールの設定に従う）
+	Hops          []string               `protobuf:"bytes,16,rep,name=hops,proto3" json:"hops,omitempty"`                                                                                   // 転送してきたプールの識別子（経由順。転送のループ防止に使う）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_workerpool_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Task) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Task) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Task) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Task) GetPayload() *structpb.Value {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Task) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Task) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Task) GetSheddable() bool {
+	if x != nil {
+		return x.Sheddable
+	}
+	return false
+}
+
+func (x *Task) GetDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Deadline
+	}
+	return nil
+}
+
+func (x *Task) GetAttemptCount() int32 {
+	if x != nil {
+		return x.AttemptCount
+	}
+	return 0
+}
+
+func (x *Task) GetMaxRetries() int32 {
+	if x != nil {
+		return x.MaxRetries
+	}
+	return 0
+}
+
+func (x *Task) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Task) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Task) GetFirstAttempt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstAttempt
+	}
+	return nil
+}
+
+func (x *Task) GetSelector() map[string]string {
+	if x != nil {
+		return x.Selector
+	}
+	return nil
+}
+
+func (x *Task) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Task) GetHops() []string {
+	if x != nil {
+		return x.Hops
+	}
+	return nil
+}
+
+// TaskError はタスクのエラー
+type TaskError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`            // TIMEOUT, CANCELED, DEADLINE_EXCEEDED, FAILED
+	Retryable     bool                   `protobuf:"varint,3,opt,name=retryable,proto3" json:"retryable,omitempty"` // リトライポリシー上リトライ対象のエラーかどうか
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskError) Reset() {
+	*x = TaskError{}
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskError) ProtoMessage() {}
+
+func (x *TaskError) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskError.ProtoReflect.Descriptor instead.
+func (*TaskError) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_workerpool_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TaskError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *TaskError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *TaskError) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
+// TaskResult はタスクの実行結果
+type TaskResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        int64                  `protobuf:"varint,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	TaskName      string                 `protobuf:"bytes,2,opt,name=task_name,json=taskName,proto3" json:"task_name,omitempty"`
+	TaskType      string                 `protobuf:"bytes,3,opt,name=task_type,json=taskType,proto3" json:"task_type,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Success       bool                   `protobuf:"varint,5,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *TaskError             `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"` // 失敗時のみ
+	Duration      *durationpb.Duration   `protobuf:"bytes,7,opt,name=duration,proto3" json:"duration,omitempty"`
+	TotalDuration *durationpb.Duration   `protobuf:"bytes,8,opt,name=total_duration,json=totalDuration,proto3" json:"total_duration,omitempty"` // リトライ含む総処理時間
+	WorkerId      int64                  `protobuf:"varint,9,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	AttemptCount  int32                  `protobuf:"varint,12,opt,name=attempt_count,json=attemptCount,proto3" json:"attempt_count,omitempty"` // 試行回数
+	IsFinal       bool                   `protobuf:"varint,13,opt,name=is_final,json=isFinal,proto3" json:"is_final,omitempty"`                // 最終結果かどうか
+	Output        string                 `protobuf:"bytes,14,opt,name=output,proto3" json:"output,omitempty"`                                  // プロセッサが書き込んだ出力（ログなど）
+	Variant       string                 `protobuf:"bytes,15,opt,name=variant,proto3" json:"variant,omitempty"`                                // カナリア設定中のタイプで実行したプロセッサ（stable / canary）
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Age           *durationpb.Duration   `protobuf:"bytes,17,opt,name=age,proto3" json:"age,omitempty"`       // 作成からこの結果までの時間
+	Budget        *LatencyBudget         `protobuf:"bytes,18,opt,name=budget,proto3" json:"budget,omitempty"` // 期限・タイムアウトと時間の内訳
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskResult) Reset() {
+	*x = TaskResult{}
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskResult) ProtoMessage() {}
+
+func (x *TaskResult) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskResult.ProtoReflect.Descriptor instead.
+func (*TaskResult) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_workerpool_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TaskResult) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+func (x *TaskResult) GetTaskName() string {
+	if x != nil {
+		return x.TaskName
+	}
+	return ""
+}
+
+func (x *TaskResult) GetTaskType() string {
+	if x != nil {
+		return x.TaskType
+	}
+	return ""
+}
+
+func (x *TaskResult) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *TaskResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *TaskResult) GetError() *TaskError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *TaskResult) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *TaskResult) GetTotalDuration() *durationpb.Duration {
+	if x != nil {
+		return x.TotalDuration
+	}
+	return nil
+}
+
+func (x *TaskResult) GetWorkerId() int64 {
+	if x != nil {
+		return x.WorkerId
+	}
+	return 0
+}
+
+func (x *TaskResult) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *TaskResult) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *TaskResult) GetAttemptCount() int32 {
+	if x != nil {
+		return x.AttemptCount
+	}
+	return 0
+}
+
+func (x *TaskResult) GetIsFinal() bool {
+	if x != nil {
+		return x.IsFinal
+	}
+	return false
+}
+
+func (x *TaskResult) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *TaskResult) GetVariant() string {
+	if x != nil {
+		return x.Variant
+	}
+	return ""
+}
+
+func (x *TaskResult) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *TaskResult) GetAge() *durationpb.Duration {
+	if x != nil {
+		return x.Age
+	}
+	return nil
+}
+
+func (x *TaskResult) GetBudget() *LatencyBudget {
+	if x != nil {
+		return x.Budget
+	}
+	return nil
+}
+
+// LatencyBudget はタスクに与えられた時間と、実際に使った時間の内訳
+type LatencyBudget struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Deadline       *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=deadline,proto3" json:"deadline,omitempty"`                                   // 呼び出し元の期限（未設定で無制限）
+	Budget         *durationpb.Duration   `protobuf:"bytes,2,opt,name=budget,proto3" json:"budget,omitempty"`                                       // 作成から期限までの時間
+	AttemptTimeout *durationpb.Duration   `protobuf:"bytes,3,opt,name=attempt_timeout,json=attemptTimeout,proto3" json:"attempt_timeout,omitempty"` // 1回の試行のタイムアウト
+	Consumed       *durationpb.Duration   `protobuf:"bytes,4,opt,name=consumed,proto3" json:"consumed,omitempty"`                                   // 作成から結果までに使った時間
+	Queue          *durationpb.Duration   `protobuf:"bytes,5,opt,name=queue,proto3" json:"queue,omitempty"`                                         // 最初の試行までのキュー待ち
+	Processing     *durationpb.Duration   `protobuf:"bytes,6,opt,name=processing,proto3" json:"processing,omitempty"`                               // 試行の合計
+	RetryWait      *durationpb.Duration   `protobuf:"bytes,7,opt,name=retry_wait,json=retryWait,proto3" json:"retry_wait,omitempty"`                // 試行と試行の間の待ちの合計
+	Phases         []*BudgetPhase         `protobuf:"bytes,8,rep,name=phases,proto3" json:"phases,omitempty"`                                       // 時系列の内訳
+	Exceeded       bool                   `protobuf:"varint,9,opt,name=exceeded,proto3" json:"exceeded,omitempty"`                                  // 期限を超えた
+	BlownAt        string                 `protobuf:"bytes,10,opt,name=blown_at,json=blownAt,proto3" json:"blown_at,omitempty"`                     // 期限を超えた区間（queue, attempt2 など）
+	Remaining      *durationpb.Duration   `protobuf:"bytes,11,opt,name=remaining,proto3" json:"remaining,omitempty"`                                // 結果の時点で残っていた時間（超えた場合は負の値）
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LatencyBudget) Reset() {
+	*x = LatencyBudget{}
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LatencyBudget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatencyBudget) ProtoMessage() {}
+
+func (x *LatencyBudget) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatencyBudget.ProtoReflect.Descriptor instead.
+func (*LatencyBudget) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_workerpool_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LatencyBudget) GetDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Deadline
+	}
+	return nil
+}
+
+func (x *LatencyBudget) GetBudget() *durationpb.Duration {
+	if x != nil {
+		return x.Budget
+	}
+	return nil
+}
+
+func (x *LatencyBudget) GetAttemptTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.AttemptTimeout
+	}
+	return nil
+}
+
+func (x *LatencyBudget) GetConsumed() *durationpb.Duration {
+	if x != nil {
+		return x.Consumed
+	}
+	return nil
+}
+
+func (x *LatencyBudget) GetQueue() *durationpb.Duration {
+	if x != nil {
+		return x.Queue
+	}
+	return nil
+}
+
+func (x *LatencyBudget) GetProcessing() *durationpb.Duration {
+	if x != nil {
+		return x.Processing
+	}
+	return nil
+}
+
+func (x *LatencyBudget) GetRetryWait() *durationpb.Duration {
+	if x != nil {
+		return x.RetryWait
+	}
+	return nil
+}
+
+func (x *LatencyBudget) GetPhases() []*BudgetPhase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
+func (x *LatencyBudget) GetExceeded() bool {
+	if x != nil {
+		return x.Exceeded
+	}
+	return false
+}
+
+func (x *LatencyBudget) GetBlownAt() string {
+	if x != nil {
+		return x.BlownAt
+	}
+	return ""
+}
+
+func (x *LatencyBudget) GetRemaining() *durationpb.Duration {
+	if x != nil {
+		return x.Remaining
+	}
+	return nil
+}
+
+// BudgetPhase は時間の内訳の1区間
+type BudgetPhase struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phase         string                 `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"` // queue, attempt, retry_wait
+	Attempt       int32                  `protobuf:"varint,2,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	Duration      *durationpb.Duration   `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BudgetPhase) Reset() {
+	*x = BudgetPhase{}
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BudgetPhase) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BudgetPhase) ProtoMessage() {}
+
+func (x *BudgetPhase) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BudgetPhase.ProtoReflect.Descriptor instead.
+func (*BudgetPhase) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_workerpool_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BudgetPhase) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *BudgetPhase) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *BudgetPhase) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+// TaskTypeStats はタスクタイプ（またはラベル値）別の統計
+type TaskTypeStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int64                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Succeeded     int64                  `protobuf:"varint,2,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed        int64                  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	Retried       int64                  `protobuf:"varint,4,opt,name=retried,proto3" json:"retried,omitempty"`
+	AvgTimeMs     float64                `protobuf:"fixed64,5,opt,name=avg_time_ms,json=avgTimeMs,proto3" json:"avg_time_ms,omitempty"`
+	AvgAgeMs      float64                `protobuf:"fixed64,6,opt,name=avg_age_ms,json=avgAgeMs,proto3" json:"avg_age_ms,omitempty"` // 作成から最終結果までの平均時間
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskTypeStats) Reset() {
+	*x = TaskTypeStats{}
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskTypeStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskTypeStats) ProtoMessage() {}
+
+func (x *TaskTypeStats) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskTypeStats.ProtoReflect.Descriptor instead.
+func (*TaskTypeStats) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_workerpool_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TaskTypeStats) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *TaskTypeStats) GetSucceeded() int64 {
+	if x != nil {
+		return x.Succeeded
+	}
+	return 0
+}
+
+func (x *TaskTypeStats) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *TaskTypeStats) GetRetried() int64 {
+	if x != nil {
+		return x.Retried
+	}
+	return 0
+}
+
+func (x *TaskTypeStats) GetAvgTimeMs() float64 {
+	if x != nil {
+		return x.AvgTimeMs
+	}
+	return 0
+}
+
+func (x *TaskTypeStats) GetAvgAgeMs() float64 {
+	if x != nil {
+		return x.AvgAgeMs
+	}
+	return 0
+}
+
+// LabelStats はラベル値ごとの統計
+type LabelStats struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Values        map[string]*TaskTypeStats `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LabelStats) Reset() {
+	*x = LabelStats{}
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LabelStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LabelStats) ProtoMessage() {}
+
+func (x *LabelStats) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabelStats.ProtoReflect.Descriptor instead.
+func (*LabelStats) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_workerpool_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LabelStats) GetValues() map[string]*TaskTypeStats {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// AdmissionStats はアドミッション制御・負荷制御の統計
+type AdmissionStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rejected      int64                  `protobuf:"varint,1,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	Shed          int64                  `protobuf:"varint,2,opt,name=shed,proto3" json:"shed,omitempty"`
+	Degraded      int64                  `protobuf:"varint,3,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	Deferred      int64                  `protobuf:"varint,4,opt,name=deferred,proto3" json:"deferred,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdmissionStats) Reset() {
+	*x = AdmissionStats{}
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdmissionStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdmissionStats) ProtoMessage() {}
+
+func (x *AdmissionStats) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdmissionStats.ProtoReflect.Descriptor instead.
+func (*AdmissionStats) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_workerpool_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AdmissionStats) GetRejected() int64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *AdmissionStats) GetShed() int64 {
+	if x != nil {
+		return x.Shed
+	}
+	return 0
+}
+
+func (x *AdmissionStats) GetDegraded() int64 {
+	if x != nil {
+		return x.Degraded
+	}
+	return 0
+}
+
+func (x *AdmissionStats) GetDeferred() int64 {
+	if x != nil {
+		return x.Deferred
+	}
+	return 0
+}
+
+// PoolStats はワーカープールの統計
+type PoolStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 基本統計
+	TotalTasks     int64 `protobuf:"varint,1,opt,name=total_tasks,json=totalTasks,proto3" json:"total_tasks,omitempty"`
+	CompletedTasks int64 `protobuf:"varint,2,opt,name=completed_tasks,json=completedTasks,proto3" json:"completed_tasks,omitempty"`
+	FailedTasks    int64 `protobuf:"varint,3,opt,name=failed_tasks,json=failedTasks,proto3" json:"failed_tasks,omitempty"`
+	ActiveTasks    int64 `protobuf:"varint,4,opt,name=active_tasks,json=activeTasks,proto3" json:"active_tasks,omitempty"`
+	QueuedTasks    int64 `protobuf:"varint,5,opt,name=queued_tasks,json=queuedTasks,proto3" json:"queued_tasks,omitempty"`
+	RetryingTasks  int64 `protobuf:"varint,6,opt,name=retrying_tasks,json=retryingTasks,proto3" json:"retrying_tasks,omitempty"`
+	DeferredTasks  int64 `protobuf:"varint,7,opt,name=deferred_tasks,json=deferredTasks,proto3" json:"deferred_tasks,omitempty"`
+	DeadLetters    int64 `protobuf:"varint,8,opt,name=dead_letters,json=deadLetters,proto3" json:"dead_letters,omitempty"`
+	// ワーカー統計
+	TotalWorkers  int32 `protobuf:"varint,9,opt,name=total_workers,json=totalWorkers,proto3" json:"total_workers,omitempty"`
+	ActiveWorkers int32 `protobuf:"varint,10,opt,name=active_workers,json=activeWorkers,proto3" json:"active_workers,omitempty"`
+	IdleWorkers   int32 `protobuf:"varint,11,opt,name=idle_workers,json=idleWorkers,proto3" json:"idle_workers,omitempty"`
+	// 処理時間統計
+	AverageTimeMs float64                   `protobuf:"fixed64,12,opt,name=average_time_ms,json=averageTimeMs,proto3" json:"average_time_ms,omitempty"`
+	MinTimeMs     float64                   `protobuf:"fixed64,13,opt,name=min_time_ms,json=minTimeMs,proto3" json:"min_time_ms,omitempty"`
+	MaxTimeMs     float64                   `protobuf:"fixed64,14,opt,name=max_time_ms,json=maxTimeMs,proto3" json:"max_time_ms,omitempty"`
+	TaskTypeStats map[string]*TaskTypeStats `protobuf:"bytes,15,rep,name=task_type_stats,json=taskTypeStats,proto3" json:"task_type_stats,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	LabelStats    map[string]*LabelStats    `protobuf:"bytes,16,rep,name=label_stats,json=labelStats,proto3" json:"label_stats,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // ラベルキー → ラベル値 → 統計
+	GroupBy       string                    `protobuf:"bytes,17,opt,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	GroupStats    map[string]*TaskTypeStats `protobuf:"bytes,18,rep,name=group_stats,json=groupStats,proto3" json:"group_stats,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// 完了予測（算出できない場合は -1）
+	ThroughputPerSec float64                `protobuf:"fixed64,19,opt,name=throughput_per_sec,json=throughputPerSec,proto3" json:"throughput_per_sec,omitempty"`
+	DrainEtaMs       float64                `protobuf:"fixed64,20,opt,name=drain_eta_ms,json=drainEtaMs,proto3" json:"drain_eta_ms,omitempty"`
+	DrainEtaByTypeMs map[string]float64     `protobuf:"bytes,21,rep,name=drain_eta_by_type_ms,json=drainEtaByTypeMs,proto3" json:"drain_eta_by_type_ms,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Admission        *AdmissionStats        `protobuf:"bytes,22,opt,name=admission,proto3" json:"admission,omitempty"`
+	Uptime           *durationpb.Duration   `protobuf:"bytes,23,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	LastUpdated      *timestamppb.Timestamp `protobuf:"bytes,24,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+	// 滞在時間統計（作成から最終結果まで）
+	AverageAgeMs  float64 `protobuf:"fixed64,25,opt,name=average_age_ms,json=averageAgeMs,proto3" json:"average_age_ms,omitempty"`
+	MaxAgeMs      float64 `protobuf:"fixed64,26,opt,name=max_age_ms,json=maxAgeMs,proto3" json:"max_age_ms,omitempty"`
+	HeldTasks     int64   `protobuf:"varint,27,opt,name=held_tasks,json=heldTasks,proto3" json:"held_tasks,omitempty"` // メンテナンスウィンドウのため保留中のタスク数
+	State         string  `protobuf:"bytes,28,opt,name=state,proto3" json:"state,omitempty"`                           // プールの状態（created / running / paused / draining / stopped）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PoolStats) Reset() {
+	*x = PoolStats{}
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoolStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolStats) ProtoMessage() {}
+
+func (x *PoolStats) ProtoReflect() protoreflect.Message {
+	mi := &file_workerpool_v1_workerpool_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolStats.ProtoReflect.Descriptor instead.
+func (*PoolStats) Descriptor() ([]byte, []int) {
+	return file_workerpool_v1_workerpool_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PoolStats) GetTotalTasks() int64 {
+	if x != nil {
+		return x.TotalTasks
+	}
+	return 0
+}
+
+func (x *PoolStats) GetCompletedTasks() int64 {
+	if x != nil {
+		return x.CompletedTasks
+	}
+	return 0
+}
+
+func (x *PoolStats) GetFailedTasks() int64 {
+	if x != nil {
+		return x.FailedTasks
+	}
+	return 0
+}
+
+func (x *PoolStats) GetActiveTasks() int64 {
+	if x != nil {
+		return x.ActiveTasks
+	}
+	return 0
+}
+
+func (x *PoolStats) GetQueuedTasks() int64 {
+	if x != nil {
+		return x.QueuedTasks
+	}
+	return 0
+}
+
+func (x *PoolStats) GetRetryingTasks() int64 {
+	if x != nil {
+		return x.RetryingTasks
+	}
+	return 0
+}
+
+func (x *PoolStats) GetDeferredTasks() int64 {
+	if x != nil {
+		return x.DeferredTasks
+	}
+	return 0
+}
+
+func (x *PoolStats) GetDeadLetters() int64 {
+	if x != nil {
+		return x.DeadLetters
+	}
+	return 0
+}
+
+func (x *PoolStats) GetTotalWorkers() int32 {
+	if x != nil {
+		return x.TotalWorkers
+	}
+	return 0
+}
+
+func (x *PoolStats) GetActiveWorkers() int32 {
+	if x != nil {
+		return x.ActiveWorkers
+	}
+	return 0
+}
+
+func (x *PoolStats) GetIdleWorkers() int32 {
+	if x != nil {
+		return x.IdleWorkers
+	}
+	return 0
+}
+
+func (x *PoolStats) GetAverageTimeMs() float64 {
+	if x != nil {
+		return x.AverageTimeMs
+	}
+	return 0
+}
+
+func (x *PoolStats) GetMinTimeMs() float64 {
+	if x != nil {
+		return x.MinTimeMs
+	}
+	return 0
+}
+
+func (x *PoolStats) GetMaxTimeMs() float64 {
+	if x != nil {
+		return x.MaxTimeMs
+	}
+	return 0
+}
+
+func (x *PoolStats) GetTaskTypeStats() map[string]*TaskTypeStats {
+	if x != nil {
+		return x.TaskTypeStats
+	}
+	return nil
+}
+
+func (x *PoolStats) GetLabelStats() map[string]*LabelStats {
+	if x != nil {
+		return x.LabelStats
+	}
+	return nil
+}
+
+func (x *PoolStats) GetGroupBy() string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return ""
+}
+
+func (x *PoolStats) GetGroupStats() map[string]*TaskTypeStats {
+	if x != nil {
+		return x.GroupStats
+	}
+	return nil
+}
+
+func (x *PoolStats) GetThroughputPerSec() float64 {
+	if x != nil {
+		return x.ThroughputPerSec
+	}
+	return 0
+}
+
+func (x *PoolStats) GetDrainEtaMs() float64 {
+	if x != nil {
+		return x.DrainEtaMs
+	}
+	return 0
+}
+
+func (x *PoolStats) GetDrainEtaByTypeMs() map[string]float64 {
+	if x != nil {
+		return x.DrainEtaByTypeMs
+	}
+	return nil
+}
+
+func (x *PoolStats) GetAdmission() *AdmissionStats {
+	if x != nil {
+		return x.Admission
+	}
+	return nil
+}
+
+func (x *PoolStats) GetUptime() *durationpb.Duration {
+	if x != nil {
+		return x.Uptime
+	}
+	return nil
+}
+
+func (x *PoolStats) GetLastUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUpdated
+	}
+	return nil
+}
+
+func (x *PoolStats) GetAverageAgeMs() float64 {
+	if x != nil {
+		return x.AverageAgeMs
+	}
+	return 0
+}
+
+func (x *PoolStats) GetMaxAgeMs() float64 {
+	if x != nil {
+		return x.MaxAgeMs
+	}
+	return 0
+}
+
+func (x *PoolStats) GetHeldTasks() int64 {
+	if x != nil {
+		return x.HeldTasks
+	}
+	return 0
+}
+
+func (x *PoolStats) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+var File_workerpool_v1_workerpool_proto protoreflect.FileDescriptor
+
+const file_workerpool_v1_workerpool_proto_rawDesc = "" +
+	"\n" +
+	"\x1eworkerpool/v1/workerpool.proto\x12\rworkerpool.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xfc\x05\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x120\n" +
+	"\apayload\x18\x04 \x01(\v2\x16.google.protobuf.ValueR\apayload\x127\n" +
+	"\x06labels\x18\x05 \x03(\v2\x1f.workerpool.v1.Task.LabelsEntryR\x06labels\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\x05R\bpriority\x12\x1c\n" +
+	"\tsheddable\x18\a \x01(\bR\tsheddable\x126\n" +
+	"\bdeadline\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\bdeadline\x12#\n" +
+	"\rattempt_count\x18\t \x01(\x05R\fattemptCount\x12\x1f\n" +
+	"\vmax_retries\x18\n" +
+	" \x01(\x05R\n" +
+	"maxRetries\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\v \x01(\tR\tlastError\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12?\n" +
+	"\rfirst_attempt\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\ffirstAttempt\x12=\n" +
+	"\bselector\x18\x0e \x03(\v2!.workerpool.v1.Task.SelectorEntryR\bselector\x123\n" +
+	"\atimeout\x18\x0f \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12\x12\n" +
+	"\x04hops\x18\x10 \x03(\tR\x04hops\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a;\n" +
+	"\rSelectorEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"W\n" +
+	"\tTaskError\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x1c\n" +
+	"\tretryable\x18\x03 \x01(\bR\tretryable\"\xbb\x06\n" +
+	"\n" +
+	"TaskResult\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\x03R\x06taskId\x12\x1b\n" +
+	"\ttask_name\x18\x02 \x01(\tR\btaskName\x12\x1b\n" +
+	"\ttask_type\x18\x03 \x01(\tR\btaskType\x12=\n" +
+	"\x06labels\x18\x04 \x03(\v2%.workerpool.v1.TaskResult.LabelsEntryR\x06labels\x12\x18\n" +
+	"\asuccess\x18\x05 \x01(\bR\asuccess\x12.\n" +
+	"\x05error\x18\x06 \x01(\v2\x18.workerpool.v1.TaskErrorR\x05error\x125\n" +
+	"\bduration\x18\a \x01(\v2\x19.google.protobuf.DurationR\bduration\x12@\n" +
+	"\x0etotal_duration\x18\b \x01(\v2\x19.google.protobuf.DurationR\rtotalDuration\x12\x1b\n" +
+	"\tworker_id\x18\t \x01(\x03R\bworkerId\x129\n" +
+	"\n" +
+	"start_time\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12#\n" +
+	"\rattempt_count\x18\f \x01(\x05R\fattemptCount\x12\x19\n" +
+	"\bis_final\x18\r \x01(\bR\aisFinal\x12\x16\n" +
+	"\x06output\x18\x0e \x01(\tR\x06output\x12\x18\n" +
+	"\avariant\x18\x0f \x01(\tR\avariant\x129\n" +
+	"\n" +
+	"created_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12+\n" +
+	"\x03age\x18\x11 \x01(\v2\x19.google.protobuf.DurationR\x03age\x124\n" +
+	"\x06budget\x18\x12 \x01(\v2\x1c.workerpool.v1.LatencyBudgetR\x06budget\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xbf\x04\n" +
+	"\rLatencyBudget\x126\n" +
+	"\bdeadline\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\bdeadline\x121\n" +
+	"\x06budget\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x06budget\x12B\n" +
+	"\x0fattempt_timeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x0eattemptTimeout\x125\n" +
+	"\bconsumed\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\bconsumed\x12/\n" +
+	"\x05queue\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\x05queue\x129\n" +
+	"\n" +
+	"processing\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"processing\x128\n" +
+	"\n" +
+	"retry_wait\x18\a \x01(\v2\x19.google.protobuf.DurationR\tretryWait\x122\n" +
+	"\x06phases\x18\b \x03(\v2\x1a.workerpool.v1.BudgetPhaseR\x06phases\x12\x1a\n" +
+	"\bexceeded\x18\t \x01(\bR\bexceeded\x12\x19\n" +
+	"\bblown_at\x18\n" +
+	" \x01(\tR\ablownAt\x127\n" +
+	"\tremaining\x18\v \x01(\v2\x19.google.protobuf.DurationR\tremaining\"t\n" +
+	"\vBudgetPhase\x12\x14\n" +
+	"\x05phase\x18\x01 \x01(\tR\x05phase\x12\x18\n" +
+	"\aattempt\x18\x02 \x01(\x05R\aattempt\x125\n" +
+	"\bduration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bduration\"\xb3\x01\n" +
+	"\rTaskTypeStats\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x03R\x05total\x12\x1c\n" +
+	"\tsucceeded\x18\x02 \x01(\x03R\tsucceeded\x12\x16\n" +
+	"\x06failed\x18\x03 \x01(\x03R\x06failed\x12\x18\n" +
+	"\aretried\x18\x04 \x01(\x03R\aretried\x12\x1e\n" +
+	"\vavg_time_ms\x18\x05 \x01(\x01R\tavgTimeMs\x12\x1c\n" +
+	"\n" +
+	"avg_age_ms\x18\x06 \x01(\x01R\bavgAgeMs\"\xa4\x01\n" +
+	"\n" +
+	"LabelStats\x12=\n" +
+	"\x06values\x18\x01 \x03(\v2%.workerpool.v1.LabelStats.ValuesEntryR\x06values\x1aW\n" +
+	"\vValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
+	"\x05value\x18\x02 \x01(\v2\x1c.workerpool.v1.TaskTypeStatsR\x05value:\x028\x01\"x\n" +
+	"\x0eAdmissionStats\x12\x1a\n" +
+	"\brejected\x18\x01 \x01(\x03R\brejected\x12\x12\n" +
+	"\x04shed\x18\x02 \x01(\x03R\x04shed\x12\x1a\n" +
+	"\bdegraded\x18\x03 \x01(\x03R\bdegraded\x12\x1a\n" +
+	"\bdeferred\x18\x04 \x01(\x03R\bdeferred\"\xc0\f\n" +
+	"\tPoolStats\x12\x1f\n" +
+	"\vtotal_tasks\x18\x01 \x01(\x03R\n" +
+	"totalTasks\x12'\n" +
+	"\x0fcompleted_tasks\x18\x02 \x01(\x03R\x0ecompletedTasks\x12!\n" +
+	"\ffailed_tasks\x18\x03 \x01(\x03R\vfailedTasks\x12!\n" +
+	"\factive_tasks\x18\x04 \x01(\x03R\vactiveTasks\x12!\n" +
+	"\fqueued_tasks\x18\x05 \x01(\x03R\vqueuedTasks\x12%\n" +
+	"\x0eretrying_tasks\x18\x06 \x01(\x03R\rretryingTasks\x12%\n" +
+	"\x0edeferred_tasks\x18\a \x01(\x03R\rdeferredTasks\x12!\n" +
+	"\fdead_letters\x18\b \x01(\x03R\vdeadLetters\x12#\n" +
+	"\rtotal_workers\x18\t \x01(\x05R\ftotalWorkers\x12%\n" +
+	"\x0eactive_workers\x18\n" +
+	" \x01(\x05R\ractiveWorkers\x12!\n" +
+	"\fidle_workers\x18\v \x01(\x05R\vidleWorkers\x12&\n" +
+	"\x0faverage_time_ms\x18\f \x01(\x01R\raverageTimeMs\x12\x1e\n" +
+	"\vmin_time_ms\x18\r \x01(\x01R\tminTimeMs\x12\x1e\n" +
+	"\vmax_time_ms\x18\x0e \x01(\x01R\tmaxTimeMs\x12S\n" +
+	"\x0ftask_type_stats\x18\x0f \x03(\v2+.workerpool.v1.PoolStats.TaskTypeStatsEntryR\rtaskTypeStats\x12I\n" +
+	"\vlabel_stats\x18\x10 \x03(\v2(.workerpool.v1.PoolStats.LabelStatsEntryR\n" +
+	"labelStats\x12\x19\n" +
+	"\bgroup_by\x18\x11 \x01(\tR\agroupBy\x12I\n" +
+	"\vgroup_stats\x18\x12 \x03(\v2(.workerpool.v1.PoolStats.GroupStatsEntryR\n" +
+	"groupStats\x12,\n" +
+	"\x12throughput_per_sec\x18\x13 \x01(\x01R\x10throughputPerSec\x12 \n" +
+	"\fdrain_eta_ms\x18\x14 \x01(\x01R\n" +
+	"drainEtaMs\x12^\n" +
+	"\x14drain_eta_by_type_ms\x18\x15 \x03(\v2..workerpool.v1.PoolStats.DrainEtaByTypeMsEntryR\x10drainEtaByTypeMs\x12;\n" +
+	"\tadmission\x18\x16 \x01(\v2\x1d.workerpool.v1.AdmissionStatsR\tadmission\x121\n" +
+	"\x06uptime\x18\x17 \x01(\v2\x19.google.protobuf.DurationR\x06uptime\x12=\n" +
+	"\flast_updated\x18\x18 \x01(\v2\x1a.google.protobuf.TimestampR\vlastUpdated\x12$\n" +
+	"\x0eaverage_age_ms\x18\x19 \x01(\x01R\faverageAgeMs\x12\x1c\n" +
+	"\n" +
+	"max_age_ms\x18\x1a \x01(\x01R\bmaxAgeMs\x12\x1d\n" +
+	"\n" +
+	"held_tasks\x18\x1b \x01(\x03R\theldTasks\x12\x14\n" +
+	"\x05state\x18\x1c \x01(\tR\x05state\x1a^\n" +
+	"\x12TaskTypeStatsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
+	"\x05value\x18\x02 \x01(\v2\x1c.workerpool.v1.TaskTypeStatsR\x05value:\x028\x01\x1aX\n" +
+	"\x0fLabelStatsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
+	"\x05value\x18\x02 \x01(\v2\x19.workerpool.v1.LabelStatsR\x05value:\x028\x01\x1a[\n" +
+	"\x0fGroupStatsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
+	"\x05value\x18\x02 \x01(\v2\x1c.workerpool.v1.TaskTypeStatsR\x05value:\x028\x01\x1aC\n" +
+	"\x15DrainEtaByTypeMsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01B@Z>github.com/hizzuu/worker-example/pkg/workerpoolpb;workerpoolpbb\x06proto3"
+
+var (
+	file_workerpool_v1_workerpool_proto_rawDescOnce sync.Once
+	file_workerpool_v1_workerpool_proto_rawDescData []byte
+)
+
+func file_workerpool_v1_workerpool_proto_rawDescGZIP() []byte {
+	file_workerpool_v1_workerpool_proto_rawDescOnce.Do(func() {
+		file_workerpool_v1_workerpool_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_workerpool_v1_workerpool_proto_rawDesc), len(file_workerpool_v1_workerpool_proto_rawDesc)))
+	})
+	return file_workerpool_v1_workerpool_proto_rawDescData
+}
+
+var file_workerpool_v1_workerpool_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_workerpool_v1_workerpool_proto_goTypes = []any{
+	(*Task)(nil),                  // 0: workerpool.v1.Task
+	(*TaskError)(nil),             // 1: workerpool.v1.TaskError
+	(*TaskResult)(nil),            // 2: workerpool.v1.TaskResult
+	(*LatencyBudget)(nil),         // 3: workerpool.v1.LatencyBudget
+	(*BudgetPhase)(nil),           // 4: workerpool.v1.BudgetPhase
+	(*TaskTypeStats)(nil),         // 5: workerpool.v1.TaskTypeStats
+	(*LabelStats)(nil),            // 6: workerpool.v1.LabelStats
+	(*AdmissionStats)(nil),        // 7: workerpool.v1.AdmissionStats
+	(*PoolStats)(nil),             // 8: workerpool.v1.PoolStats
+	nil,                           // 9: workerpool.v1.Task.LabelsEntry
+	nil,                           // 10: workerpool.v1.Task.SelectorEntry
+	nil,                           // 11: workerpool.v1.TaskResult.LabelsEntry
+	nil,                           // 12: workerpool.v1.LabelStats.ValuesEntry
+	nil,                           // 13: workerpool.v1.PoolStats.TaskTypeStatsEntry
+	nil,                           // 14: workerpool.v1.PoolStats.LabelStatsEntry
+	nil,                           // 15: workerpool.v1.PoolStats.GroupStatsEntry
+	nil,                           // 16: workerpool.v1.PoolStats.DrainEtaByTypeMsEntry
+	(*structpb.Value)(nil),        // 17: google.protobuf.Value
+	(*timestamppb.Timestamp)(nil), // 18: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 19: google.protobuf.Duration
+}
+var file_workerpool_v1_workerpool_proto_depIdxs = []int32{
+	17, // 0: workerpool.v1.Task.payload:type_name -> google.protobuf.Value
+	9,  // 1: workerpool.v1.Task.labels:type_name -> workerpool.v1.Task.LabelsEntry
+	18, // 2: workerpool.v1.Task.deadline:type_name -> google.protobuf.Timestamp
+	18, // 3: workerpool.v1.Task.created_at:type_name -> google.protobuf.Timestamp
+	18, // 4: workerpool.v1.Task.first_attempt:type_name -> google.protobuf.Timestamp
+	10, // 5: workerpool.v1.Task.selector:type_name -> workerpool.v1.Task.SelectorEntry
+	19, // 6: workerpool.v1.Task.timeout:type_name -> google.protobuf.Duration
+	11, // 7: workerpool.v1.TaskResult.labels:type_name -> workerpool.v1.TaskResult.LabelsEntry
+	1,  // 8: workerpool.v1.TaskResult.error:type_name -> workerpool.v1.TaskError
+	19, // 9: workerpool.v1.TaskResult.duration:type_name -> google.protobuf.Duration
+	19, // 10: workerpool.v1.TaskResult.total_duration:type_name -> google.protobuf.Duration
+	18, // 11: workerpool.v1.TaskResult.start_time:type_name -> google.protobuf.Timestamp
+	18, // 12: workerpool.v1.TaskResult.end_time:type_name -> google.protobuf.Timestamp
+	18, // 13: workerpool.v1.TaskResult.created_at:type_name -> google.protobuf.Timestamp
+	19, // 14: workerpool.v1.TaskResult.age:type_name -> google.protobuf.Duration
+	3,  // 15: workerpool.v1.TaskResult.budget:type_name -> workerpool.v1.LatencyBudget
+	18, // 16: workerpool.v1.LatencyBudget.deadline:type_name -> google.protobuf.Timestamp
+	19, // 17: workerpool.v1.LatencyBudget.budget:type_name -> google.protobuf.Duration
+	19, // 18: workerpool.v1.LatencyBudget.attempt_timeout:type_name -> google.protobuf.Duration
+	19, // 19: workerpool.v1.LatencyBudget.consumed:type_name -> google.protobuf.Duration
+	19, // 20: workerpool.v1.LatencyBudget.queue:type_name -> google.protobuf.Duration
+	19, // 21: workerpool.v1.LatencyBudget.processing:type_name -> google.protobuf.Duration
+	19, // 22: workerpool.v1.LatencyBudget.retry_wait:type_name -> google.protobuf.Duration
+	4,  // 23: workerpool.v1.LatencyBudget.phases:type_name -> workerpool.v1.BudgetPhase
+	19, // 24: workerpool.v1.LatencyBudget.remaining:type_name -> google.protobuf.Duration
+	19, // 25: workerpool.v1.BudgetPhase.duration:type_name -> google.protobuf.Duration
+	12, // 26: workerpool.v1.LabelStats.values:type_name -> workerpool.v1.LabelStats.ValuesEntry
+	13, // 27: workerpool.v1.PoolStats.task_type_stats:type_name -> workerpool.v1.PoolStats.TaskTypeStatsEntry
+	14, // 28: workerpool.v1.PoolStats.label_stats:type_name -> workerpool.v1.PoolStats.LabelStatsEntry
+	15, // 29: workerpool.v1.PoolStats.group_stats:type_name -> workerpool.v1.PoolStats.GroupStatsEntry
+	16, // 30: workerpool.v1.PoolStats.drain_eta_by_type_ms:type_name -> workerpool.v1.PoolStats.DrainEtaByTypeMsEntry
+	7,  // 31: workerpool.v1.PoolStats.admission:type_name -> workerpool.v1.AdmissionStats
+	19, // 32: workerpool.v1.PoolStats.uptime:type_name -> google.protobuf.Duration
+	18, // 33: workerpool.v1.PoolStats.last_updated:type_name -> google.protobuf.Timestamp
+	5,  // 34: workerpool.v1.LabelStats.ValuesEntry.value:type_name -> workerpool.v1.TaskTypeStats
+	5,  // 35: workerpool.v1.PoolStats.TaskTypeStatsEntry.value:type_name -> workerpool.v1.TaskTypeStats
+	6,  // 36: workerpool.v1.PoolStats.LabelStatsEntry.value:type_name -> workerpool.v1.LabelStats
+	5,  // 37: workerpool.v1.PoolStats.GroupStatsEntry.value:type_name -> workerpool.v1.TaskTypeStats
+	38, // [38:38] is the sub-list for method output_type
+	38, // [38:38] is the sub-list for method input_type
+	38, // [38:38] is the sub-list for extension type_name
+	38, // [38:38] is the sub-list for extension extendee
+	0,  // [0:38] is the sub-list for field type_name
+}
+
+func init() { file_workerpool_v1_workerpool_proto_init() }
+func file_workerpool_v1_workerpool_proto_init() {
+	if File_workerpool_v1_workerpool_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_workerpool_v1_workerpool_proto_rawDesc), len(file_workerpool_v1_workerpool_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_workerpool_v1_workerpool_proto_goTypes,
+		DependencyIndexes: file_workerpool_v1_workerpool_proto_depIdxs,
+		MessageInfos:      file_workerpool_v1_workerpool_proto_msgTypes,
+	}.Build()
+	File_workerpool_v1_workerpool_proto = out.File
+	file_workerpool_v1_workerpool_proto_goTypes = nil
+	file_workerpool_v1_workerpool_proto_depIdxs = nil
+}